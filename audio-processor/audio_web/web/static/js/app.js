@@ -1,5 +1,91 @@
 const { createApp } = Vue;
 
+// 超过该大小的文件分块上传
+const CHUNKED_UPLOAD_THRESHOLD = 32 * 1024 * 1024;
+// 单块连续失败的最大重试次数
+const MAX_CHUNK_RETRIES = 5;
+
+// 服务启用认证时使用的API密钥或令牌，保存在本地
+const TOKEN_KEY = 'asrApiToken';
+
+function apiToken() {
+    return localStorage.getItem(TOKEN_KEY) || '';
+}
+
+// 请求接口，携带已保存的令牌；返回 401 时提示输入令牌后重试一次
+async function apiFetch(url, options = {}) {
+    const send = () => {
+        const headers = Object.assign({}, options.headers);
+        if (apiToken()) {
+            headers['Authorization'] = 'Bearer ' + apiToken();
+        }
+        return fetch(url, Object.assign({}, options, { headers }));
+    };
+    const resp = await send();
+    if (resp.status === 401 && promptToken()) {
+        return send();
+    }
+    return resp;
+}
+
+// 读取以 SSE 返回的摘要：delta 事件追加文本，done 事件返回完整摘要，error 事件抛出错误。
+// 不支持流式读取的浏览器按普通 JSON 响应处理
+async function readSummaryStream(response, onDelta) {
+    const contentType = response.headers.get('Content-Type') || '';
+    if (!contentType.includes('text/event-stream') || !response.body) {
+        const data = await response.json();
+        return data.summary;
+    }
+    const reader = response.body.getReader();
+    const decoder = new TextDecoder();
+    let buffer = '';
+    for (;;) {
+        const { value, done } = await reader.read();
+        if (done) {
+            break;
+        }
+        buffer += decoder.decode(value, { stream: true });
+        let end;
+        while ((end = buffer.indexOf('\n\n')) >= 0) {
+            const block = buffer.slice(0, end);
+            buffer = buffer.slice(end + 2);
+            let name = 'message';
+            let data = '';
+            block.split('\n').forEach(line => {
+                if (line.startsWith('event:')) name = line.slice(6).trim();
+                if (line.startsWith('data:')) data += line.slice(5).trim();
+            });
+            if (!data) {
+                continue;
+            }
+            const payload = JSON.parse(data);
+            if (name === 'delta') {
+                onDelta(payload.text);
+            } else if (name === 'done') {
+                return payload.summary;
+            } else if (name === 'error') {
+                throw new Error(payload.error);
+            }
+        }
+    }
+    throw new Error('摘要生成中断');
+}
+
+// 提示输入令牌，输入后返回 true
+function promptToken() {
+    const token = window.prompt('服务需要认证，请输入API密钥或令牌');
+    if (!token) {
+        return false;
+    }
+    localStorage.setItem(TOKEN_KEY, token.trim());
+    return true;
+}
+
+// EventSource 无法设置请求头，令牌放在查询参数中
+function withToken(url) {
+    return apiToken() ? url + '?token=' + encodeURIComponent(apiToken()) : url;
+}
+
 createApp({
     data() {
         return {
@@ -9,6 +95,7 @@ createApp({
             // 文件相关
             selectedFile: null,
             isDragging: false,
+            sourceUrl: '',
             
             // 进度相关
             progress: 0,
@@ -17,6 +104,7 @@ createApp({
             // 结果相关
             segments: [],
             transcriptionText: '',
+            outputFiles: [],
             
             // 总结相关
             showSummary: false,
@@ -35,7 +123,15 @@ createApp({
             errorMessage: '',
             
             // XHR 请求
-            xhr: null
+            xhr: null,
+            
+            // 分块上传
+            uploadId: null,
+            uploadCancelled: false,
+            
+            // 任务进度推送与轮询
+            eventSource: null,
+            pollTimer: null
         }
     },
     
@@ -89,7 +185,7 @@ createApp({
                 const file = files[0];
                 
                 // 检查文件类型
-                const validTypes = ['.mp3', '.wav', '.mp4', '.mov', '.avi', '.mkv', '.flv', '.wmv', '.m4a'];
+                const validTypes = ['.mp3', '.wav', '.m4a', '.flac', '.ogg', '.opus', '.aac', '.mp4', '.mov', '.avi', '.mkv', '.flv', '.wmv', '.webm', '.ts'];
                 const fileExtension = file.name.substring(file.name.lastIndexOf('.')).toLowerCase();
                 
                 if (!validTypes.some(type => fileExtension.endsWith(type))) {
@@ -97,12 +193,6 @@ createApp({
                     return;
                 }
                 
-                // 检查文件大小（限制为500MB）
-                if (file.size > 500 * 1024 * 1024) {
-                    this.showError('文件过大，请选择小于500MB的文件');
-                    return;
-                }
-                
                 this.selectedFile = file;
             }
         },
@@ -119,6 +209,16 @@ createApp({
             this.progress = 0;
             this.statusMessage = '准备上传...';
             
+            // 大文件分块上传，网络中断后可以继续
+            if (this.selectedFile.size > CHUNKED_UPLOAD_THRESHOLD) {
+                this.uploadInChunks(this.selectedFile).catch((e) => {
+                    if (!this.uploadCancelled) {
+                        this.showError('上传失败: ' + e.message);
+                    }
+                });
+                return;
+            }
+            
             // 准备表单数据
             const formData = new FormData();
             formData.append('file', this.selectedFile);
@@ -143,17 +243,17 @@ createApp({
             // 处理结果
             this.xhr.onreadystatechange = () => {
                 if (this.xhr.readyState === 4) {
-                    if (this.xhr.status === 200) {
+                    if (this.xhr.status === 202) {
                         try {
-                            const response = JSON.parse(this.xhr.responseText);
-                            if (response.success) {
-                                this.handleResponse(response);
-                            } else {
-                                this.showError(response.error_message || '处理失败，请重试');
-                            }
+                            const job = JSON.parse(this.xhr.responseText);
+                            this.statusMessage = '文件已上传，排队等待处理...';
+                            this.watchJob(job.id);
                         } catch (e) {
                             this.showError('解析响应失败: ' + e.message);
                         }
+                    } else if (this.xhr.status === 401) {
+                        promptToken();
+                        this.showError('需要认证，请输入API密钥后重试');
                     } else if (this.xhr.status === 0) {
                         // 请求被中止，不显示错误
                         console.log('请求已取消');
@@ -174,15 +274,206 @@ createApp({
             });
             
             // 发送请求
-            this.xhr.open('POST', '/upload', true);
-            this.xhr.timeout = 600000; // 10分钟超时
+            this.xhr.open('POST', '/api/jobs', true);
+            if (apiToken()) {
+                this.xhr.setRequestHeader('Authorization', 'Bearer ' + apiToken());
+            }
+            this.xhr.timeout = 600000; // 10分钟超时，只覆盖上传
             this.xhr.send(formData);
         },
         
+        // 提交视频链接，由服务器用 yt-dlp 下载音频后处理
+        async submitUrl() {
+            const sourceUrl = this.sourceUrl.trim();
+            if (!sourceUrl) {
+                return;
+            }
+            this.currentView = 'progress';
+            this.progress = 0;
+            this.statusMessage = '正在提交链接...';
+            try {
+                const resp = await apiFetch('/api/jobs', {
+                    method: 'POST',
+                    headers: { 'Content-Type': 'application/json' },
+                    body: JSON.stringify({ source_url: sourceUrl })
+                });
+                const job = await resp.json();
+                if (!resp.ok) {
+                    throw new Error(job.error || resp.status);
+                }
+                this.statusMessage = '排队等待下载...';
+                this.watchJob(job.id);
+            } catch (e) {
+                this.showError('提交链接失败: ' + e.message);
+            }
+        },
+        
+        // 分块上传：每块失败后查询服务器已接收的偏移，从该处重试
+        async uploadInChunks(file) {
+            this.uploadCancelled = false;
+            const created = await apiFetch('/api/uploads', {
+                method: 'POST',
+                headers: { 'Content-Type': 'application/json' },
+                body: JSON.stringify({ file_name: file.name, size: file.size })
+            });
+            const session = await created.json();
+            if (!created.ok) {
+                throw new Error(session.error || created.status);
+            }
+            this.uploadId = session.id;
+            
+            let offset = session.offset;
+            let failures = 0;
+            while (offset < file.size) {
+                if (this.uploadCancelled) {
+                    return;
+                }
+                try {
+                    const resp = await apiFetch('/api/uploads/' + session.id, {
+                        method: 'PATCH',
+                        headers: { 'Upload-Offset': String(offset) },
+                        body: file.slice(offset, offset + session.chunk_size)
+                    });
+                    // 409 表示偏移不一致，按服务器返回的偏移继续
+                    if (!resp.ok && resp.status !== 409) {
+                        const data = await resp.json().catch(() => ({}));
+                        throw new Error(data.error || resp.status);
+                    }
+                    offset = Number(resp.headers.get('Upload-Offset'));
+                    failures = 0;
+                } catch (e) {
+                    if (++failures > MAX_CHUNK_RETRIES) {
+                        throw e;
+                    }
+                    this.statusMessage = '网络异常，' + failures + ' 秒后重试...';
+                    await new Promise(resolve => setTimeout(resolve, failures * 1000));
+                    try {
+                        const resp = await apiFetch('/api/uploads/' + session.id);
+                        if (resp.ok) {
+                            offset = Number(resp.headers.get('Upload-Offset'));
+                        }
+                    } catch (ignored) {
+                        // 仍然无法连接，下一轮继续重试
+                    }
+                }
+                this.progress = offset / file.size * 100;
+                this.statusMessage = '正在上传文件... ' + Math.round(this.progress) + '%';
+            }
+            
+            const done = await apiFetch('/api/uploads/' + session.id + '/complete', { method: 'POST' });
+            const job = await done.json();
+            if (!done.ok) {
+                throw new Error(job.error || done.status);
+            }
+            this.uploadId = null;
+            this.statusMessage = '文件已上传，排队等待处理...';
+            this.watchJob(job.id);
+        },
+        
+        // 通过 Server-Sent Events 接收处理进度，不支持或连接关闭时改为轮询
+        watchJob(jobId) {
+            if (!window.EventSource) {
+                this.pollJob(jobId);
+                return;
+            }
+            this.progress = 0;
+            this.eventSource = new EventSource(withToken('/api/jobs/' + jobId + '/events'));
+            this.eventSource.addEventListener('progress', (e) => {
+                this.showJobProgress(JSON.parse(e.data));
+            });
+            this.eventSource.addEventListener('done', (e) => {
+                this.closeEventSource();
+                this.handleJob(JSON.parse(e.data));
+            });
+            this.eventSource.onerror = () => {
+                // 连接中断时浏览器会自动重连，彻底关闭后改为轮询
+                if (this.eventSource && this.eventSource.readyState === EventSource.CLOSED) {
+                    this.closeEventSource();
+                    this.pollJob(jobId);
+                }
+            };
+        },
+        
+        closeEventSource() {
+            if (this.eventSource) {
+                this.eventSource.close();
+                this.eventSource = null;
+            }
+        },
+        
+        // 显示任务的处理进度
+        showJobProgress(job) {
+            if (job.status === 'queued') {
+                this.statusMessage = '排队等待处理...';
+                return;
+            }
+            this.progress = job.progress || 0;
+            if (job.stage === 'download') {
+                this.statusMessage = '正在下载音频... ' + this.progress + '%';
+                return;
+            }
+            this.statusMessage = job.message || '正在分析处理...';
+        },
+        
+        // 任务结束后显示结果或错误
+        handleJob(job) {
+            if (job.status === 'completed') {
+                this.handleResponse(job.result);
+                this.loadOutputFiles(job.id);
+            } else {
+                this.showError(job.error || (job.result && job.result.error_message) || '处理失败，请重试');
+            }
+        },
+        
+        // 获取任务生成的字幕、文本等输出文件，用于下载
+        async loadOutputFiles(jobId) {
+            this.outputFiles = [];
+            try {
+                const resp = await apiFetch('/api/jobs/' + jobId + '/files');
+                if (resp.ok) {
+                    this.outputFiles = (await resp.json()).filter(file => file.url);
+                }
+            } catch (e) {
+                console.warn('获取输出文件失败:', e);
+            }
+        },
+        
+        fileUrl(file) {
+            return withToken(file.url);
+        },
+        
+        // 轮询任务状态，处理结束后显示结果
+        pollJob(jobId) {
+            this.pollTimer = setTimeout(async () => {
+                try {
+                    const resp = await apiFetch('/api/jobs/' + jobId);
+                    if (!resp.ok) {
+                        throw new Error(resp.status + ' ' + resp.statusText);
+                    }
+                    const job = await resp.json();
+                    if (job.status === 'completed' || job.status === 'failed') {
+                        this.handleJob(job);
+                    } else {
+                        this.showJobProgress(job);
+                        this.pollJob(jobId);
+                    }
+                } catch (e) {
+                    this.showError('查询任务状态失败: ' + e.message);
+                }
+            }, 2000);
+        },
+        
         cancelUpload() {
             if (this.xhr && this.xhr.readyState < 4) {
                 this.xhr.abort();
             }
+            if (this.uploadId) {
+                this.uploadCancelled = true;
+                apiFetch('/api/uploads/' + this.uploadId, { method: 'DELETE' });
+                this.uploadId = null;
+            }
+            clearTimeout(this.pollTimer);
+            this.closeEventSource();
             this.resetForm();
         },
         
@@ -274,11 +565,13 @@ createApp({
                 requestData.prompt = customPrompt;
             }
             
-            // 发送请求
-            fetch('/api/summarize', {
+            // 发送请求，摘要以 SSE 逐段返回，边生成边显示
+            this.summary = '';
+            apiFetch('/api/summarize', {
                 method: 'POST',
                 headers: {
-                    'Content-Type': 'application/json'
+                    'Content-Type': 'application/json',
+                    'Accept': 'text/event-stream'
                 },
                 body: JSON.stringify(requestData)
             })
@@ -286,13 +579,16 @@ createApp({
                 if (!response.ok) {
                     throw new Error('服务器响应错误: ' + response.status);
                 }
-                return response.json();
+                return readSummaryStream(response, text => {
+                    this.summaryLoading = false;
+                    this.summary += text;
+                });
             })
-            .then(data => {
-                // 显示总结结果
+            .then(summary => {
+                // 显示完整的总结结果
                 this.summaryLoading = false;
-                if (data.summary) {
-                    this.summary = data.summary;
+                if (summary) {
+                    this.summary = summary;
                 } else {
                     throw new Error('返回数据格式错误');
                 }
@@ -422,8 +718,10 @@ createApp({
         // 表单重置
         resetForm() {
             this.selectedFile = null;
+            this.sourceUrl = '';
             this.progress = 0;
             this.segments = [];
+            this.outputFiles = [];
             this.showSummary = false;
             this.summary = '';
             this.customPrompt = '';
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/audit"
+)
+
+// auditOptions audit 命令的选项
+type auditOptions struct {
+	file   string
+	since  time.Duration
+	action string
+	limit  int
+}
+
+func newAuditCommand() *cobra.Command {
+	var opts auditOptions
+	cmd := &cobra.Command{
+		Use:   "audit",
+		Short: "查看删除/移动/覆盖操作的审计日志",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return exit(runAudit(&opts))
+		},
+	}
+	cmd.Flags().StringVar(&opts.file, "file", "", "审计日志路径，优先于配置文件")
+	cmd.Flags().DurationVar(&opts.since, "since", 0, "只显示最近一段时间内的记录，如 24h")
+	cmd.Flags().StringVar(&opts.action, "action", "", "只显示指定操作 (delete, trash, purge, move, overwrite)")
+	cmd.Flags().IntVar(&opts.limit, "limit", 50, "最多显示的记录数，0 表示不限制")
+	cmd.RegisterFlagCompletionFunc("action", cobra.FixedCompletions(
+		[]string{"delete", "trash", "purge", "move", "overwrite"}, cobra.ShellCompDirectiveNoFileComp))
+	return cmd
+}
+
+// runAudit 实现 `asr audit` 子命令，查看删除/移动/覆盖操作的审计日志
+func runAudit(opts *auditOptions) int {
+	path := opts.file
+	if path == "" {
+		config, err := loadConfig(configFile)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		path = config.AuditLogPath()
+	}
+
+	filter := audit.Filter{Action: opts.action, Limit: opts.limit}
+	if opts.since > 0 {
+		filter.Since = time.Now().Add(-opts.since)
+	}
+
+	entries, err := audit.ReadEntries(path, filter)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return 1
+	}
+
+	if len(entries) == 0 {
+		fmt.Printf("没有审计记录 (%s)\n", path)
+		return 0
+	}
+
+	fmt.Printf("审计日志: %s，共 %d 条\n\n", path, len(entries))
+	for _, e := range entries {
+		line := fmt.Sprintf("%s  %-9s  %-8s  %s", e.Time.Format("2006-01-02 15:04:05"), e.Action, e.Initiator, e.Path)
+		if e.Target != "" {
+			line += " -> " + e.Target
+		}
+		fmt.Println(line)
+		if e.Reason != "" {
+			fmt.Printf("    原因: %s\n", e.Reason)
+		}
+		if e.Error != "" {
+			fmt.Printf("    错误: %s\n", e.Error)
+		}
+	}
+	return 0
+}
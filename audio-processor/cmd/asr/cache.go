@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/asr"
+)
+
+func newCacheCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cache",
+		Short: "管理ASR识别结果缓存",
+	}
+	cmd.AddCommand(&cobra.Command{
+		Use:   "clear",
+		Short: "清空ASR识别结果缓存",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return exit(runClearCache(configFile))
+		},
+	})
+	return cmd
+}
+
+// runClearCache 清空ASR识别结果缓存
+func runClearCache(configPath string) int {
+	config, err := loadCommandConfig(configPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	// 即使配置中关闭了缓存，也清理之前留下的缓存文件
+	cache := asr.NewCache(asr.CacheDir(config), 0, 0)
+	count, size, err := cache.Size()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	removed, err := cache.Clear()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "清空缓存失败: %v\n", err)
+		return 1
+	}
+	fmt.Printf("已清空缓存 %s：删除 %d/%d 个条目，释放 %.1f MB\n",
+		cache.Dir(), removed, count, float64(size)/(1024*1024))
+	return 0
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/asr"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/audit"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/retention"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/trash"
+)
+
+// cleanupOptions cleanup 命令的选项
+type cleanupOptions struct {
+	uploadDir string
+	tempDir   string
+	outputDir string
+	dryRun    bool
+}
+
+func newCleanupCommand() *cobra.Command {
+	var opts cleanupOptions
+	cmd := &cobra.Command{
+		Use:   "cleanup",
+		Short: "按保留策略立即清理一次",
+		Long:  "按配置 retention 中各类目录（uploads、temp、outputs、cache）的保留时间与大小上限清理文件，目录默认与 asr serve 相同",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return exit(runCleanup(&opts))
+		},
+	}
+	cmd.Flags().StringVar(&opts.uploadDir, "upload-dir", "./uploads", "上传文件目录")
+	cmd.Flags().StringVar(&opts.tempDir, "temp-dir", "./temp", "临时文件目录")
+	cmd.Flags().StringVar(&opts.outputDir, "output-dir", "", "输出目录，默认使用配置中的 output_folder")
+	cmd.Flags().BoolVar(&opts.dryRun, "dry-run", false, "只列出将要清理的数量与大小，不删除文件")
+	cmd.MarkFlagDirname("upload-dir")
+	cmd.MarkFlagDirname("temp-dir")
+	cmd.MarkFlagDirname("output-dir")
+	return cmd
+}
+
+// runCleanup 实现 `asr cleanup` 子命令，按配置的保留策略立即清理一次
+func runCleanup(opts *cleanupOptions) int {
+	config, err := loadCommandConfig(configFile)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	outputDir := opts.outputDir
+	if outputDir == "" {
+		outputDir = config.OutputFolder
+	}
+	audit.SetDefault(audit.NewLogger(config.AuditLogPath()))
+
+	cleaner := &retention.Cleaner{Trash: trash.FromConfig(config), DryRun: opts.dryRun}
+	reports, err := cleaner.Run(config, retention.Dirs{
+		Uploads: opts.uploadDir,
+		Temp:    opts.tempDir,
+		Outputs: outputDir,
+		Cache:   asr.CacheDir(config),
+	})
+	if len(reports) == 0 && err == nil {
+		fmt.Println("没有配置保留策略 (retention)，未清理任何文件")
+		return 0
+	}
+
+	action := "清理"
+	if opts.dryRun {
+		action = "将清理"
+	}
+	for _, report := range reports {
+		fmt.Printf("%-8s %s（%s）\n", report.Class, report.Dir, report.Policy)
+		fmt.Printf("         共 %d 项，%s %d 项，释放 %.1f MB，剩余 %.1f MB\n",
+			report.Items, action, report.Removed, mb(report.Freed), mb(report.Remaining))
+		if report.Errors > 0 {
+			fmt.Printf("         %d 个文件删除失败\n", report.Errors)
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "清理失败: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// mb 将字节数换算为 MB
+func mb(size int64) float64 {
+	return float64(size) / (1024 * 1024)
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/models"
+)
+
+func newConfigCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "创建与迁移配置文件",
+	}
+
+	var opts initOptions
+	initCmd := &cobra.Command{
+		Use:   "init",
+		Short: "交互式生成带注释的配置文件",
+		Long: "逐项询问媒体目录、输出目录、识别语言与导出格式，在 --config（默认 config.yaml）写入带注释的配置文件。\n" +
+			"扩展名为 .json 时写入完整的 JSON 配置。标准输入不是终端或指定 --yes 时不提问，全部使用默认值",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.interactive = !opts.yes && isTerminal(os.Stdin)
+			return exit(runConfigInit(commandConfigPath("config.yaml"), &opts, os.Stdin, os.Stdout))
+		},
+	}
+	initCmd.Flags().BoolVar(&opts.force, "force", false, "覆盖已存在的配置文件")
+	initCmd.Flags().BoolVarP(&opts.yes, "yes", "y", false, "不提问，全部使用默认值")
+	cmd.AddCommand(initCmd)
+
+	var dryRun bool
+	migrate := &cobra.Command{
+		Use:   "migrate",
+		Short: "检查配置文件版本并写回迁移结果",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return exit(runConfigMigrate(commandConfigPath("config.json"), dryRun))
+		},
+	}
+	migrate.Flags().BoolVar(&dryRun, "dry-run", false, "只显示需要迁移的内容，不修改文件")
+	cmd.AddCommand(migrate)
+	return cmd
+}
+
+// commandConfigPath 返回要读写的配置文件，未指定 --config 且当前目录下没有配置文件时为 fallback
+func commandConfigPath(fallback string) string {
+	if configFile == "" {
+		return fallback
+	}
+	return configFile
+}
+
+// runConfigMigrate 实现 `asr config migrate` 子命令，检查配置文件版本并写回迁移结果
+func runConfigMigrate(configPath string, dryRun bool) int {
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "读取配置文件失败: %v\n", err)
+		return 1
+	}
+	decoded, err := models.DecodeConfigFile(configPath, data)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	migrated, warnings, err := models.MigrateConfigData(decoded)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if len(warnings) == 0 {
+		fmt.Printf("配置文件已是最新格式 (版本 %d): %s\n", models.ConfigSchemaVersion, configPath)
+		return 0
+	}
+
+	for _, warning := range warnings {
+		fmt.Printf("- %s\n", warning)
+	}
+	if dryRun {
+		return 0
+	}
+
+	out, err := models.EncodeConfigFile(configPath, migrated)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	backup := configPath + ".bak"
+	if err := os.WriteFile(backup, data, 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "备份配置文件失败: %v\n", err)
+		return 1
+	}
+	if err := os.WriteFile(configPath, out, 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "写入配置文件失败: %v\n", err)
+		return 1
+	}
+
+	fmt.Printf("\n已更新配置文件 %s，原文件备份为 %s\n", configPath, backup)
+	if models.IsYAMLConfig(configPath) {
+		fmt.Println("YAML 中的注释未保留，可从备份中找回")
+	}
+	return 0
+}
@@ -0,0 +1,231 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/models"
+)
+
+// initOptions config init 命令的选项
+type initOptions struct {
+	force       bool
+	yes         bool
+	interactive bool // 是否逐项提问，标准输入不是终端时为 false
+}
+
+// configFormats 配置中可开关的导出格式（export_<格式>），文本文稿总是导出
+var configFormats = []string{"srt", "vtt", "lrc", "ass", "json", "md", "docx", "pdf"}
+
+// formatField 返回导出格式对应的配置项
+func formatField(config *models.Config, format string) *bool {
+	switch format {
+	case "srt":
+		return &config.ExportSRT
+	case "vtt":
+		return &config.ExportVTT
+	case "lrc":
+		return &config.ExportLRC
+	case "ass":
+		return &config.ExportASS
+	case "json":
+		return &config.ExportJSON
+	case "md":
+		return &config.ExportMD
+	case "docx":
+		return &config.ExportDOCX
+	case "pdf":
+		return &config.ExportPDF
+	}
+	return nil
+}
+
+// starterConfig 带注释的 YAML 配置模板，只包含常用的配置项
+var starterConfig = template.Must(template.New("config").Funcs(template.FuncMap{"quote": strconv.Quote}).Parse(
+	`# asr 配置文件，由 asr config init 生成
+# 只列出常用的配置项，其余使用默认值；其他配置项按 JSON 配置中的字段名添加，如 export_chapters: true
+schema_version: {{.SchemaVersion}}
+
+# 待处理的音频与视频所在目录，asr watch 监听该目录
+media_folder: {{quote .MediaFolder}}
+# 文稿等输出的目录，字幕文件与视频放在一起
+output_folder: {{quote .OutputFolder}}
+# 临时文件目录，为空时使用系统临时目录
+temp_dir: {{quote .TempDir}}
+
+# 音频语言 (auto: 自动检测, 或 zh、en 等语言代码)
+language: {{quote .Language}}
+# 是否处理视频文件（提取其中的音频）
+process_video: {{.ProcessVideo}}
+# 同时处理的文件数
+max_workers: {{.MaxWorkers}}
+
+# 识别服务，依次尝试
+use_jianying_first: {{.UseJianyingFirst}}
+use_kuaishou: {{.UseKuaishou}}
+use_bcut: {{.UseBcut}}
+
+# 导出格式，文本文稿 (.txt) 总是导出。JSON 带完整时间戳，asr export 据此补充其他格式
+export_srt: {{.ExportSRT}}
+export_vtt: {{.ExportVTT}}
+export_lrc: {{.ExportLRC}}
+export_ass: {{.ExportASS}}
+export_json: {{.ExportJSON}}
+export_md: {{.ExportMD}}
+export_docx: {{.ExportDOCX}}
+export_pdf: {{.ExportPDF}}
+# 在文本与文档中包含时间戳
+include_timestamps: {{.IncludeTimestamps}}
+# 字幕导入剪辑软件的预设 (空: 通用, jianying: 额外生成剪映草稿, premiere: SRT使用UTF-8 BOM与CRLF换行)
+subtitle_preset: {{quote .SubtitlePreset}}
+`))
+
+// runConfigInit 实现 `asr config init` 子命令，询问常用配置项后写入配置文件
+func runConfigInit(configPath string, opts *initOptions, in io.Reader, out io.Writer) int {
+	if _, err := os.Stat(configPath); err == nil && !opts.force {
+		fmt.Fprintf(os.Stderr, "配置文件 %s 已存在，使用 --force 覆盖\n", configPath)
+		return 1
+	}
+
+	config := models.NewDefaultConfig()
+	config.MediaFolder, config.OutputFolder = starterFolders()
+	if opts.interactive {
+		if err := askStarterConfig(config, bufio.NewReader(in), out); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	}
+	if err := config.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "配置无效: %v\n", err)
+		return 1
+	}
+
+	if err := writeStarterConfig(config, configPath); err != nil {
+		fmt.Fprintf(os.Stderr, "写入配置文件失败: %v\n", err)
+		return 1
+	}
+	fmt.Fprintf(out, "已写入配置文件 %s\n", configPath)
+	hint := ""
+	if !isDefaultConfigFile(configPath) {
+		hint = " --config " + configPath
+	}
+	fmt.Fprintf(out, "运行 asr process%s 处理 %s 中的文件，或 asr watch%s 持续监听\n", hint, config.MediaFolder, hint)
+	return 0
+}
+
+// writeStarterConfig 写入配置，YAML 使用带注释的模板，JSON 写入全部配置项
+func writeStarterConfig(config *models.Config, path string) error {
+	if !models.IsYAMLConfig(path) {
+		return config.SaveToFile(path)
+	}
+	var buf bytes.Buffer
+	config.SchemaVersion = models.ConfigSchemaVersion
+	if err := starterConfig.Execute(&buf, config); err != nil {
+		return err
+	}
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+	return os.WriteFile(path, buf.Bytes(), 0644)
+}
+
+// starterFolders 返回向导建议的媒体目录与输出目录：存在用户下载目录时监听下载目录，
+// 否则使用当前目录下的 media 与 output。返回绝对路径，配置文件在其他目录运行时仍然有效
+func starterFolders() (string, string) {
+	media, output := "media", "output"
+	if home, err := os.UserHomeDir(); err == nil {
+		if info, err := os.Stat(filepath.Join(home, "Downloads")); err == nil && info.IsDir() {
+			media = filepath.Join(home, "Downloads")
+		}
+	}
+	if abs, err := filepath.Abs(media); err == nil {
+		media = abs
+	}
+	if abs, err := filepath.Abs(output); err == nil {
+		output = abs
+	}
+	return media, output
+}
+
+// askStarterConfig 逐项询问常用配置项，直接回车使用方括号中的默认值
+func askStarterConfig(config *models.Config, in *bufio.Reader, out io.Writer) error {
+	ask := func(question, value string) (string, error) {
+		fmt.Fprintf(out, "%s [%s]: ", question, value)
+		line, err := in.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return "", fmt.Errorf("读取输入失败: %w", err)
+		}
+		if line = strings.TrimSpace(line); line != "" {
+			return line, nil
+		}
+		if err == io.EOF {
+			fmt.Fprintln(out)
+		}
+		return value, nil
+	}
+
+	var err error
+	if config.MediaFolder, err = ask("媒体目录", config.MediaFolder); err != nil {
+		return err
+	}
+	if config.OutputFolder, err = ask("输出目录", config.OutputFolder); err != nil {
+		return err
+	}
+	if config.Language, err = ask("音频语言 (auto 为自动检测，或 zh、en 等语言代码)", config.Language); err != nil {
+		return err
+	}
+
+	var current []string
+	for _, format := range configFormats {
+		if *formatField(config, format) {
+			current = append(current, format)
+		}
+	}
+	question := fmt.Sprintf("导出格式，逗号分隔 (%s)，文本文稿总是导出", strings.Join(configFormats, ", "))
+	for {
+		answer, err := ask(question, strings.Join(current, ","))
+		if err != nil {
+			return err
+		}
+		selected, err := parseStarterFormats(answer)
+		if err != nil {
+			fmt.Fprintln(out, err)
+			continue
+		}
+		for _, format := range configFormats {
+			*formatField(config, format) = selected[format]
+		}
+		return nil
+	}
+}
+
+// parseStarterFormats 解析逗号分隔的导出格式，"none" 表示只导出文本
+func parseStarterFormats(value string) (map[string]bool, error) {
+	selected := make(map[string]bool)
+	for _, name := range strings.Split(value, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" || name == "none" {
+			continue
+		}
+		if formatField(&models.Config{}, name) == nil {
+			return nil, fmt.Errorf("不支持的导出格式: %s", name)
+		}
+		selected[name] = true
+	}
+	return selected, nil
+}
+
+// isTerminal 判断文件是否为终端
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
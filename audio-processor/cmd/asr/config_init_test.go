@@ -0,0 +1,66 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigInit(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.yaml")
+	media, output := filepath.Join(dir, "media"), filepath.Join(dir, "output")
+
+	// 第一次输入的格式无效，应重新询问
+	answers := strings.Join([]string{media, output, "en", "srt,foo", "srt, VTT"}, "\n") + "\n"
+	var out bytes.Buffer
+	code := runConfigInit(configPath, &initOptions{interactive: true}, strings.NewReader(answers), &out)
+	require.Equal(t, 0, code, out.String())
+	assert.Contains(t, out.String(), "不支持的导出格式: foo")
+
+	config := models.NewDefaultConfig()
+	require.NoError(t, config.LoadFromFile(configPath))
+	assert.Equal(t, media, config.MediaFolder)
+	assert.Equal(t, output, config.OutputFolder)
+	assert.Equal(t, "en", config.Language)
+	assert.True(t, config.ExportSRT)
+	assert.True(t, config.ExportVTT)
+	assert.False(t, config.ExportJSON)
+	assert.Equal(t, models.NewDefaultConfig().MaxWorkers, config.MaxWorkers)
+
+	// 模板中的配置项都应是当前的字段名
+	data, err := os.ReadFile(configPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "# 音频语言")
+	decoded, err := models.DecodeConfigFile(configPath, data)
+	require.NoError(t, err)
+	_, warnings, err := models.MigrateConfigData(decoded)
+	require.NoError(t, err)
+	assert.Empty(t, warnings)
+
+	// 已存在时需要 --force
+	assert.Equal(t, 1, runConfigInit(configPath, &initOptions{}, strings.NewReader(""), &out))
+	assert.Equal(t, 0, runConfigInit(configPath, &initOptions{force: true}, strings.NewReader(""), &out))
+}
+
+func TestConfigInitJSON(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.json")
+	answers := strings.Join([]string{filepath.Join(dir, "media"), filepath.Join(dir, "output"), "", "none"}, "\n") + "\n"
+	var out bytes.Buffer
+	require.Equal(t, 0, runConfigInit(configPath, &initOptions{interactive: true}, strings.NewReader(answers), &out))
+
+	data, err := os.ReadFile(configPath)
+	require.NoError(t, err)
+	var raw map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &raw))
+	assert.Equal(t, false, raw["export_srt"])
+	assert.Contains(t, raw, "max_retries")
+}
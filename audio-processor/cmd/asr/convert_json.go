@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/export"
+)
+
+func newConvertJSONCommand() *cobra.Command {
+	var legacy bool
+	cmd := &cobra.Command{
+		Use:   "convert-json [文件 ...]",
+		Short: "在新旧JSON导出格式之间转换",
+		Long: "默认将 *_json.txt（版本 1）转换为 .json（当前版本）并更新输出清单；\n" +
+			"--legacy 时将 .json 转换回 *_json.txt，供仍读取旧格式的工具使用。\n" +
+			"未指定文件时转换输出目录下的全部文件，原文件保留不变",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return exit(runConvertJSON(args, legacy))
+		},
+	}
+	cmd.Flags().BoolVar(&legacy, "legacy", false, "将 .json 转换为旧版 *_json.txt")
+	return cmd
+}
+
+// runConvertJSON 实现 `asr convert-json` 子命令，转换指定的文件或输出目录下的全部文件
+func runConvertJSON(files []string, legacy bool) int {
+	config, err := loadCommandConfig(configFile)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	if len(files) == 0 {
+		if files, err = findJSONExports(config.OutputFolder, legacy); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	}
+	if len(files) == 0 {
+		fmt.Printf("没有需要转换的文件 (%s)\n", config.OutputFolder)
+		return 0
+	}
+
+	failed := 0
+	for _, path := range files {
+		target, err := export.ConvertJSONFile(path, legacy)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			failed++
+			continue
+		}
+		fmt.Printf("%s -> %s\n", path, target)
+		if legacy {
+			continue
+		}
+		// 清单中的 json 输出仍指向旧文件时改为新文件
+		name := strings.TrimSuffix(filepath.Base(path), export.LegacyJSONSuffix)
+		manifestPath := export.ManifestPath(config.OutputFolder, name)
+		if manifest, err := export.LoadManifest(manifestPath); err == nil {
+			if entry, ok := manifest.Output("json"); ok && entry.Resolve(config.OutputFolder, config.MediaFolder) == path {
+				if _, err := export.LinkOutput(config, name, "json", target); err != nil {
+					fmt.Fprintf(os.Stderr, "更新输出清单失败: %v\n", err)
+				}
+			}
+		}
+	}
+	if failed > 0 {
+		return 1
+	}
+	return 0
+}
+
+// findJSONExports 查找输出目录下待转换的JSON导出：默认为 *_json.txt，legacy 时为带 schema_version 的 .json
+func findJSONExports(outputFolder string, legacy bool) ([]string, error) {
+	var files []string
+	err := filepath.Walk(outputFolder, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		if !legacy {
+			if strings.HasSuffix(path, export.LegacyJSONSuffix) {
+				files = append(files, path)
+			}
+			return nil
+		}
+		if filepath.Ext(path) != ".json" {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err == nil && strings.Contains(string(data), `"schema_version"`) {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("扫描输出目录失败: %w", err)
+	}
+	return files, nil
+}
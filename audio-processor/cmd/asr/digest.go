@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/digest"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/llm"
+)
+
+// digestExtensions 各输出格式的文件扩展名
+var digestExtensions = map[string]string{
+	"md":    ".md",
+	"html":  ".html",
+	"email": ".eml",
+}
+
+// digestOptions digest 命令的选项
+type digestOptions struct {
+	since  string
+	format string
+	output string
+	from   string
+	to     string
+}
+
+func newDigestCommand() *cobra.Command {
+	var opts digestOptions
+	cmd := &cobra.Command{
+		Use:   "digest",
+		Short: "汇总时间窗口内处理过的文件",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return exit(runDigest(&opts))
+		},
+	}
+	cmd.Flags().StringVar(&opts.since, "since", "7d", "时间窗口，如 7d、2w、36h")
+	cmd.Flags().StringVar(&opts.format, "format", "md", "输出格式 (md, html, email)")
+	cmd.Flags().StringVarP(&opts.output, "output", "o", "", "输出文件路径，- 表示标准输出，默认写入输出目录的 digests 子目录")
+	cmd.Flags().StringVar(&opts.from, "from", "", "邮件发件人（email 格式）")
+	cmd.Flags().StringVar(&opts.to, "to", "", "邮件收件人（email 格式）")
+	cmd.RegisterFlagCompletionFunc("format", cobra.FixedCompletions(
+		[]string{"md", "html", "email"}, cobra.ShellCompDirectiveNoFileComp))
+	return cmd
+}
+
+// runDigest 实现 `asr digest` 子命令，汇总时间窗口内处理过的文件
+func runDigest(opts *digestOptions) int {
+	ext, ok := digestExtensions[opts.format]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "不支持的输出格式: %s\n", opts.format)
+		return 2
+	}
+	window, err := digest.ParseSince(opts.since)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	config, err := loadCommandConfig(configFile)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	client, err := llm.FromConfig(config)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if client == nil {
+		fmt.Fprintf(os.Stderr, "未配置 %s 的API密钥（llm.api_key 或对应的环境变量），无法生成汇总\n", config.LLMProvider())
+		return 1
+	}
+
+	result, err := digest.Generate(client, config.OutputFolder, config.MediaFolder, time.Now().Add(-window))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	path := opts.output
+	if path == "" {
+		path = filepath.Join(config.OutputFolder, "digests", "digest-"+time.Now().Format("20060102")+ext)
+	}
+	baseDir := ""
+	if path != "-" {
+		baseDir = filepath.Dir(path)
+	}
+
+	var content string
+	switch opts.format {
+	case "html":
+		content = result.HTML(baseDir)
+	case "email":
+		content = result.Email(opts.from, opts.to)
+	default:
+		content = result.Markdown(baseDir)
+	}
+
+	if path == "-" {
+		fmt.Print(content)
+		return 0
+	}
+	if err := os.MkdirAll(baseDir, 0755); err != nil {
+		fmt.Fprintf(os.Stderr, "创建输出目录失败: %v\n", err)
+		return 1
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "写入汇总失败: %v\n", err)
+		return 1
+	}
+	fmt.Printf("已汇总 %d 个文件: %s\n", len(result.Items), path)
+	return 0
+}
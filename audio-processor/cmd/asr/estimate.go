@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/asr"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/audio"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/utils"
+)
+
+// runDryRun 扫描媒体目录并打印处理预估，不提取音频也不上传
+func runDryRun(configPath string) int {
+	config, err := loadCommandConfig(configPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if !utils.CheckFFmpeg() {
+		fmt.Fprintln(os.Stderr, "未检测到FFmpeg，无法获取媒体时长")
+		return 1
+	}
+
+	selector := asr.NewASRSelector()
+	selector.RegisterFromConfig(config, asr.DefaultCreators())
+	// 读取已持久化的统计，用于计算当日剩余配额
+	if err := selector.SetStatsFile(filepath.Join(config.OutputFolder, "asr_service_stats.json")); err != nil {
+		utils.Warn("%v", err)
+	}
+
+	processor := audio.NewBatchProcessor(config.MediaFolder, config.OutputFolder, config.TempDir, nil, config)
+	processor.SetASRSelector(selector)
+
+	estimate, err := processor.Estimate(audio.DefaultEstimateOptions())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "预估失败: %v\n", err)
+		return 1
+	}
+	printEstimate(estimate)
+	return 0
+}
+
+// printEstimate 打印预估结果
+func printEstimate(estimate *audio.Estimate) {
+	fmt.Printf("媒体文件: %d 个", len(estimate.Files))
+	if estimate.Failed > 0 {
+		fmt.Printf("（%d 个无法获取时长，未计入）", estimate.Failed)
+	}
+	fmt.Println()
+	for _, file := range estimate.Files {
+		if file.Err != nil {
+			fmt.Printf("  %-40s %v\n", filepath.Base(file.Path), file.Err)
+			continue
+		}
+		fmt.Printf("  %-40s %10s  %d 次请求\n", filepath.Base(file.Path),
+			utils.FormatTimeDuration(float64(file.Duration)), file.Requests)
+	}
+
+	fmt.Printf("音频总时长: %.2f 小时\n", float64(estimate.TotalDuration)/3600)
+	fmt.Printf("识别请求: %d 次（%d 个文件分段识别，共 %d 个片段）\n",
+		estimate.Requests, estimate.ChunkedFiles, estimate.Chunks)
+
+	if len(estimate.Services) == 0 {
+		fmt.Println("没有可用的ASR服务")
+	}
+	for _, service := range estimate.Services {
+		quota := "不限"
+		if remaining := service.QuotaRemaining(); remaining >= 0 {
+			quota = fmt.Sprintf("剩余 %d/%d", remaining, service.DailyQuota)
+		}
+		fmt.Printf("  %-10s %d 次请求，今日配额 %s", service.Name, service.Requests, quota)
+		if service.ExceedsQuota() {
+			fmt.Print("，超出配额")
+		}
+		fmt.Println()
+	}
+
+	fmt.Printf("预计耗时: 约 %s（并发 %d）\n",
+		estimate.WallClock.Round(time.Minute), estimate.Concurrency)
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/asr"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/export"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/models"
+)
+
+// exportFunc 用识别段落导出一种格式，返回输出文件路径
+type exportFunc func(p *asr.ASRProcessor, segments []models.DataSegment, filename string) (string, error)
+
+// exportFormats export 命令支持的格式
+var exportFormats = map[string]exportFunc{
+	"srt": func(p *asr.ASRProcessor, segments []models.DataSegment, filename string) (string, error) {
+		return p.SRTExporter.ExportSRT(segments, filename, nil)
+	},
+	"vtt": func(p *asr.ASRProcessor, segments []models.DataSegment, filename string) (string, error) {
+		return p.VTTExporter.ExportVTT(segments, filename, nil)
+	},
+	"lrc": func(p *asr.ASRProcessor, segments []models.DataSegment, filename string) (string, error) {
+		return p.LRCExporter.ExportLRC(segments, filename, nil)
+	},
+	"ass": func(p *asr.ASRProcessor, segments []models.DataSegment, filename string) (string, error) {
+		return p.ASSExporter.ExportASS(segments, filename, nil)
+	},
+	"docx": func(p *asr.ASRProcessor, segments []models.DataSegment, filename string) (string, error) {
+		return p.DOCXExporter.ExportDOCX(segments, filename, nil)
+	},
+	"pdf": func(p *asr.ASRProcessor, segments []models.DataSegment, filename string) (string, error) {
+		return p.PDFExporter.ExportPDF(segments, filename, nil)
+	},
+	"jianying": func(p *asr.ASRProcessor, segments []models.DataSegment, filename string) (string, error) {
+		return p.JianyingExporter.ExportJianying(segments, filename, nil)
+	},
+}
+
+// exportFormatNames 返回支持的格式，按名称排序
+func exportFormatNames() []string {
+	names := make([]string, 0, len(exportFormats))
+	for name := range exportFormats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func newExportCommand() *cobra.Command {
+	var formats []string
+	cmd := &cobra.Command{
+		Use:   "export <文件名> [...]",
+		Short: "用已有的JSON导出重新生成字幕与文档",
+		Long: "读取文件输出清单中的JSON导出，按 --format 重新生成字幕与文档并更新输出清单，不重新识别。\n" +
+			"可用于补充识别时未启用的格式，或修改字幕样式、预设后重新导出",
+		Args: cobra.MinimumNArgs(1),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			config, err := loadCommandConfig(configFile)
+			if err != nil {
+				return nil, cobra.ShellCompDirectiveError
+			}
+			manifests, err := export.ListManifests(config.OutputFolder)
+			if err != nil {
+				return nil, cobra.ShellCompDirectiveError
+			}
+			names := make([]string, 0, len(manifests))
+			for _, manifest := range manifests {
+				names = append(names, manifest.Name)
+			}
+			return names, cobra.ShellCompDirectiveNoFileComp
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			for _, format := range formats {
+				if _, ok := exportFormats[format]; !ok {
+					return fmt.Errorf("不支持的格式: %s（可用: %s）", format, strings.Join(exportFormatNames(), ", "))
+				}
+			}
+			return exit(runExport(args, formats))
+		},
+	}
+	cmd.Flags().StringSliceVar(&formats, "format", []string{"srt"}, "导出的格式，逗号分隔 ("+strings.Join(exportFormatNames(), ", ")+")")
+	cmd.RegisterFlagCompletionFunc("format", cobra.FixedCompletions(exportFormatNames(), cobra.ShellCompDirectiveNoFileComp))
+	return cmd
+}
+
+// runExport 实现 `asr export` 子命令，逐个文件重新导出指定格式
+func runExport(names, formats []string) int {
+	config, err := loadCommandConfig(configFile)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	processor := asr.NewASRProcessor(config)
+
+	failed := 0
+	for _, name := range names {
+		if err := exportItem(config, processor, name, formats); err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", name, err)
+			failed++
+		}
+	}
+	if failed > 0 {
+		return 1
+	}
+	return 0
+}
+
+// exportItem 读取文件的JSON导出并生成各格式，新输出写入输出清单
+func exportItem(config *models.Config, processor *asr.ASRProcessor, name string, formats []string) error {
+	manifest, err := export.LoadManifest(export.ManifestPath(config.OutputFolder, name))
+	if err != nil {
+		return err
+	}
+	entry, ok := manifest.Output("json")
+	if !ok {
+		return fmt.Errorf("没有JSON导出，请启用 export_json 后重新识别")
+	}
+	jsonPath := entry.Resolve(config.OutputFolder, config.MediaFolder)
+	data, err := os.ReadFile(jsonPath)
+	if err != nil {
+		return fmt.Errorf("读取JSON导出失败: %w", err)
+	}
+	transcript, err := export.ParseTranscript(data)
+	if err != nil {
+		return err
+	}
+	segments := transcript.DataSegments()
+
+	// 输出按源文件命名，清单中没有源媒体时用清单名称代替
+	filename := filepath.Join(config.MediaFolder, manifest.Name+filepath.Ext(jsonPath))
+	processor.Namer.Source = ""
+	if media, ok := manifest.Output("media"); ok {
+		filename = media.Resolve(config.OutputFolder, config.MediaFolder)
+		processor.Namer.Source = filename
+	}
+
+	for _, format := range formats {
+		path, err := exportFormats[format](processor, segments, filename)
+		if err != nil {
+			return fmt.Errorf("导出 %s 失败: %w", format, err)
+		}
+		if _, err := export.LinkOutput(config, filename, format, path); err != nil {
+			return fmt.Errorf("更新输出清单失败: %w", err)
+		}
+		fmt.Printf("%s  %-8s %s\n", manifest.Name, format, path)
+	}
+	return nil
+}
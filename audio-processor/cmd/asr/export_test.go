@@ -0,0 +1,53 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/export"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportCommand(t *testing.T) {
+	dir := t.TempDir()
+	config := models.NewDefaultConfig()
+	config.MediaFolder = filepath.Join(dir, "media")
+	config.OutputFolder = filepath.Join(dir, "output")
+	config.TempDir = filepath.Join(dir, "temp")
+	configPath := filepath.Join(dir, "config.json")
+	require.NoError(t, config.SaveToFile(configPath))
+
+	media := filepath.Join(config.MediaFolder, "demo.mp4")
+	segments := []models.DataSegment{{Text: "你好", StartTime: 0, EndTime: 1.5}, {Text: "再见", StartTime: 2, EndTime: 3}}
+	jsonPath, err := export.NewJSONExporter(config.OutputFolder).ExportJSON(segments, media, nil)
+	require.NoError(t, err)
+	_, err = export.WriteManifest(config, media, map[string]string{"json": jsonPath})
+	require.NoError(t, err)
+
+	root := newRootCommand()
+	root.SetArgs([]string{"export", "demo", "--config", configPath, "--format", "srt,lrc"})
+	require.NoError(t, root.Execute())
+
+	manifest, err := export.LoadManifest(export.ManifestPath(config.OutputFolder, media))
+	require.NoError(t, err)
+	for _, format := range []string{"json", "srt", "lrc"} {
+		entry, ok := manifest.Output(format)
+		require.True(t, ok, format)
+		_, err := os.Stat(entry.Resolve(config.OutputFolder, config.MediaFolder))
+		assert.NoError(t, err, format)
+	}
+	srt, err := os.ReadFile(filepath.Join(config.MediaFolder, "demo.srt"))
+	require.NoError(t, err)
+	assert.Contains(t, string(srt), "00:00:02,000 --> 00:00:03,000")
+
+	root = newRootCommand()
+	root.SetArgs([]string{"export", "demo", "--config", configPath, "--format", "mp3"})
+	assert.ErrorContains(t, root.Execute(), "不支持的格式")
+
+	root = newRootCommand()
+	root.SetArgs([]string{"export", "missing", "--config", configPath})
+	assert.Equal(t, exitCode(1), root.Execute())
+}
@@ -0,0 +1,201 @@
+// asr 音频处理工具的命令行入口：批处理（process）、监听媒体目录（watch）、Web服务（serve）、管道中识别（transcribe），
+// 以及导出、缓存、配置、状态备份等管理命令。全局选项 --config、--set、--progress、--log-level、--log-file 对所有子命令有效，
+// 配置文件可以是 JSON 或 YAML，`asr config init` 交互式生成带注释的配置文件。
+// 配置项的优先级从高到低为：子命令选项与 --set、ASR_ 开头的环境变量、配置文件、默认值。
+// `asr completion bash|zsh|fish|powershell` 生成 shell 补全脚本
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/internal/ui"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/models"
+)
+
+// 全局选项
+var (
+	configFile   string
+	logLevel     string
+	logFile      string
+	configSets   []string // --set 指定的 key=value
+	progressMode string   // --progress
+)
+
+// 进度输出方式
+const (
+	progressBar  = "bar"  // 终端进度条
+	progressJSON = "json" // 标准输出中每行一个 JSON 进度事件
+)
+
+// configEnv 未指定 --config 时读取的配置文件环境变量
+const configEnv = "ASR_CONFIG"
+
+// defaultConfigFiles 未指定 --config 与 ASR_CONFIG 时在当前目录依次查找的配置文件
+var defaultConfigFiles = []string{"config.yaml", "config.yml", "config.json"}
+
+// resolveConfigFile 确定配置文件：--config 优先，其次为环境变量 ASR_CONFIG，再次为当前目录下的默认配置文件，
+// 都没有时为空，使用默认配置
+func resolveConfigFile() {
+	if configFile != "" {
+		return
+	}
+	if path := os.Getenv(configEnv); path != "" {
+		configFile = path
+		return
+	}
+	for _, name := range defaultConfigFiles {
+		if _, err := os.Stat(name); err == nil {
+			configFile = name
+			return
+		}
+	}
+}
+
+// isDefaultConfigFile 判断未指定 --config 时是否会读取该配置文件
+func isDefaultConfigFile(path string) bool {
+	if os.Getenv(configEnv) != "" {
+		return false
+	}
+	for _, name := range defaultConfigFiles {
+		if _, err := os.Stat(name); err == nil {
+			return filepath.Clean(path) == name
+		}
+	}
+	return false
+}
+
+// loadConfig 生成子命令使用的配置：默认值，依次被配置文件、环境变量 ASR_<配置项> 与 --set 覆盖
+func loadConfig(path string) (*models.Config, error) {
+	config := models.NewDefaultConfig()
+	if path != "" {
+		if err := config.LoadFromFile(path); err != nil {
+			return nil, fmt.Errorf("加载配置失败: %w", err)
+		}
+	}
+
+	overridden, err := config.ApplyEnv(os.LookupEnv)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range configSets {
+		key, value, ok := strings.Cut(item, "=")
+		if !ok {
+			return nil, fmt.Errorf("--set %s: 应为 配置项=值", item)
+		}
+		if err := config.Set(strings.TrimSpace(key), value); err != nil {
+			return nil, fmt.Errorf("--set %s: %w", item, err)
+		}
+	}
+	if len(overridden) > 0 || len(configSets) > 0 {
+		if err := config.Validate(); err != nil {
+			return nil, fmt.Errorf("配置无效: %w", err)
+		}
+	}
+	return config, nil
+}
+
+// newProgressManager 按 --progress 创建进度管理器。json 时进度事件写到标准输出，日志等其他输出改到标准错误；
+// 返回 nil 时使用终端进度条
+func newProgressManager() *ui.ProgressManager {
+	if progressMode != progressJSON {
+		return nil
+	}
+	return ui.NewSinkProgressManager(ui.NewJSONProgressSink(redirectStdout()))
+}
+
+// exitCode 子命令以非 0 退出码结束，错误信息已由子命令输出
+type exitCode int
+
+func (e exitCode) Error() string {
+	return fmt.Sprintf("退出码 %d", int(e))
+}
+
+// exit 将子命令返回的退出码转换为 RunE 的返回值
+func exit(code int) error {
+	if code == 0 {
+		return nil
+	}
+	return exitCode(code)
+}
+
+// newRootCommand 创建根命令并注册全部子命令
+func newRootCommand() *cobra.Command {
+	root := &cobra.Command{
+		Use:   "asr",
+		Short: "音频处理工具：提取媒体文件中的音频并识别为字幕与文稿",
+		Long: "音频处理工具：提取媒体文件中的音频并识别为字幕与文稿。\n\n" +
+			"配置项的优先级从高到低为：\n" +
+			"  1. 子命令选项（如 --preset）与 --set 配置项=值\n" +
+			"  2. 环境变量 ASR_<配置项>，如 ASR_MEDIA_FOLDER、ASR_MAX_WORKERS；asr_ 开头的配置项不重复前缀，如 ASR_SERVICE\n" +
+			"  3. 配置文件（--config、ASR_CONFIG 或当前目录下的 config.yaml、config.yml、config.json）\n" +
+			"  4. 默认值\n\n" +
+			"字符串直接书写，字符串列表可用逗号分隔，其余类型使用 JSON，如 true、4、{\"bcut\":{\"weight\":2}}",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if progressMode != progressBar && progressMode != progressJSON {
+				return fmt.Errorf("--progress 必须为 %s 或 %s", progressBar, progressJSON)
+			}
+			resolveConfigFile()
+			return nil
+		},
+	}
+	root.PersistentFlags().StringVar(&configFile, "config", "", "配置文件路径（JSON 或 YAML），默认读取环境变量 ASR_CONFIG 或当前目录下的 config.yaml、config.yml、config.json")
+	root.PersistentFlags().StringArrayVar(&configSets, "set", nil, "覆盖配置项，格式为 配置项=值（如 max_workers=2），可重复指定")
+	root.PersistentFlags().StringVar(&progressMode, "progress", progressBar, "进度输出方式：bar（终端进度条）、json（标准输出中每行一个 JSON 进度事件，包含阶段、文件、百分比与预计剩余秒数，日志输出到标准错误）")
+	root.PersistentFlags().StringVar(&logLevel, "log-level", "info", "日志级别 (debug, info, warn, error)")
+	root.PersistentFlags().StringVar(&logFile, "log-file", "", "日志文件路径")
+	root.RegisterFlagCompletionFunc("progress", cobra.FixedCompletions(
+		[]string{progressBar, progressJSON}, cobra.ShellCompDirectiveNoFileComp))
+	root.RegisterFlagCompletionFunc("log-level", cobra.FixedCompletions(
+		[]string{"debug", "info", "warn", "error"}, cobra.ShellCompDirectiveNoFileComp))
+	root.MarkPersistentFlagFilename("config", "yaml", "yml", "json")
+
+	root.AddGroup(
+		&cobra.Group{ID: "run", Title: "处理命令:"},
+		&cobra.Group{ID: "manage", Title: "管理命令:"},
+	)
+	for _, cmd := range []*cobra.Command{newProcessCommand(), newTranscribeCommand(), newWatchCommand(), newServeCommand()} {
+		cmd.GroupID = "run"
+		root.AddCommand(cmd)
+	}
+	for _, cmd := range []*cobra.Command{
+		newExportCommand(),
+		newOutputsCommand(),
+		newConvertJSONCommand(),
+		newTagCommand(),
+		newSearchCommand(),
+		newDigestCommand(),
+		newRemoteCommand(),
+		newCacheCommand(),
+		newCleanupCommand(),
+		newConfigCommand(),
+		newStateCommand(),
+		newStatsCommand(),
+		newAuditCommand(),
+	} {
+		cmd.GroupID = "manage"
+		root.AddCommand(cmd)
+	}
+	return root
+}
+
+func main() {
+	err := newRootCommand().Execute()
+	if err == nil {
+		return
+	}
+	var code exitCode
+	if errors.As(err, &code) {
+		os.Exit(int(code))
+	}
+	// 参数错误
+	fmt.Fprintf(os.Stderr, "%v\n运行 asr --help 查看用法\n", err)
+	os.Exit(2)
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadConfigPrecedence(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.yaml")
+	data := "media_folder: " + filepath.Join(dir, "file") + "\nmax_workers: 6\nlanguage: en\n"
+	require.NoError(t, os.WriteFile(configPath, []byte(data), 0644))
+
+	t.Setenv("ASR_MAX_WORKERS", "3")
+	t.Setenv("ASR_LANGUAGE", "ja")
+	configSets = []string{"language=zh"}
+	defer func() { configSets = nil }()
+
+	config, err := loadConfig(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "file"), config.MediaFolder)
+	assert.Equal(t, 3, config.MaxWorkers)
+	assert.Equal(t, "zh", config.Language)
+
+	configSets = []string{"language"}
+	_, err = loadConfig(configPath)
+	assert.Error(t, err)
+
+	configSets = nil
+	t.Setenv("ASR_MAX_WORKERS", "0")
+	_, err = loadConfig(configPath)
+	assert.Error(t, err)
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/export"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/models"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/storage"
+)
+
+func newOutputsCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "outputs",
+		Short: "列出或上传各文件的输出",
+	}
+	var tag string
+	for _, command := range []string{"list", "push"} {
+		command := command
+		short := "按输出清单列出各文件的输出"
+		if command == "push" {
+			short = "将输出上传到 output_storage 配置的远程存储"
+		}
+		sub := &cobra.Command{
+			Use:   command + " [文件名]",
+			Short: short,
+			Args:  cobra.MaximumNArgs(1),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				name := ""
+				if len(args) > 0 {
+					name = args[0]
+				}
+				return exit(runOutputs(command, name, tag))
+			},
+		}
+		sub.Flags().StringVar(&tag, "tag", "", "只处理带有该标签的文件")
+		cmd.AddCommand(sub)
+	}
+	return cmd
+}
+
+// runOutputs 实现 `asr outputs list|push` 子命令：按输出清单列出各文件的输出，
+// 或将输出上传到 output_storage 配置的远程存储
+func runOutputs(command, name, tag string) int {
+	config, err := loadCommandConfig(configFile)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	var manifests []*export.OutputManifest
+	if name != "" {
+		manifest, err := export.LoadManifest(export.ManifestPath(config.OutputFolder, name))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		manifests = append(manifests, manifest)
+	} else if manifests, err = export.ListManifests(config.OutputFolder); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if manifests, err = export.ApplyTags(manifests, config.OutputFolder, tag); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	if len(manifests) == 0 {
+		fmt.Printf("没有输出清单 (%s)\n", config.OutputFolder)
+		return 0
+	}
+	if command == "push" {
+		return pushOutputs(config, manifests)
+	}
+	for _, manifest := range manifests {
+		fmt.Printf("%s  (%s)\n", manifest.Name, manifest.CreatedAt)
+		if len(manifest.Tags) > 0 {
+			fmt.Printf("  标签: %s\n", strings.Join(manifest.Tags, ", "))
+		}
+		for _, entry := range manifest.Outputs {
+			path := entry.Resolve(config.OutputFolder, config.MediaFolder)
+			hash := entry.SHA256
+			if len(hash) > 12 {
+				hash = hash[:12]
+			}
+			status := ""
+			if _, err := os.Stat(path); err != nil {
+				status = "  [缺失]"
+			}
+			fmt.Printf("  %-8s %10d  %s  %s%s\n", entry.Type, entry.Size, hash, path, status)
+		}
+	}
+	return 0
+}
+
+// pushOutputs 将各文件的输出与清单上传到远程存储，用于补传上传失败的文件或迁移已有的输出
+func pushOutputs(config *models.Config, manifests []*export.OutputManifest) int {
+	remote, err := storage.FromConfig(config)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if remote == nil {
+		fmt.Fprintln(os.Stderr, "未配置远程存储（output_storage 为 local）")
+		return 2
+	}
+
+	failed := 0
+	for _, manifest := range manifests {
+		uploaded, err := storage.PushManifest(context.Background(), remote, config, manifest)
+		if err != nil {
+			failed++
+			fmt.Fprintf(os.Stderr, "%s: %v\n", manifest.Name, err)
+			continue
+		}
+		fmt.Printf("%s  已上传 %d 个文件\n", manifest.Name, uploaded)
+	}
+	fmt.Printf("完成: %d 个文件上传到 %s，%d 个失败\n", len(manifests)-failed, remote.Name(), failed)
+	if failed > 0 {
+		return 1
+	}
+	return 0
+}
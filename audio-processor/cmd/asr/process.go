@@ -0,0 +1,341 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/internal/controller"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/audio"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/models"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/utils"
+)
+
+// mediaFlags process 与 watch 共用的处理选项，只覆盖命令行中显式指定的项
+type mediaFlags struct {
+	preset     string
+	debugAddr  string
+	audioTrack int
+	clipStart  string
+	clipEnd    string
+	formats    map[string]*bool // 导出格式开关，如 --srt、--json=false
+}
+
+// register 将选项注册到命令
+func (f *mediaFlags) register(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&f.preset, "preset", "", "字幕导入剪辑软件的预设 (jianying, premiere)，覆盖配置中的 subtitle_preset")
+	cmd.Flags().StringVar(&f.debugAddr, "debug-addr", "", "pprof 与自检数据的HTTP地址（如 127.0.0.1:6060），覆盖配置中的 debug_addr")
+	cmd.Flags().IntVar(&f.audioTrack, "audio-track", 0, "提取的音频流序号（从 0 开始，-1 表示全部），覆盖配置中的 audio_track")
+	cmd.Flags().StringVar(&f.clipStart, "start", "", "只识别该时间之后的部分（秒数或 hh:mm:ss），覆盖配置中的 clip_start")
+	cmd.Flags().StringVar(&f.clipEnd, "end", "", "只识别到该时间为止（秒数或 hh:mm:ss），覆盖配置中的 clip_end")
+	f.formats = make(map[string]*bool, len(configFormats))
+	for _, format := range configFormats {
+		f.formats[format] = cmd.Flags().Bool(format, false, fmt.Sprintf("导出 %s，覆盖配置中的 export_%s", strings.ToUpper(format), format))
+	}
+	cmd.RegisterFlagCompletionFunc("preset", cobra.FixedCompletions(
+		[]string{"jianying", "premiere"}, cobra.ShellCompDirectiveNoFileComp))
+}
+
+// apply 用命令行中显式指定的选项覆盖配置
+func (f *mediaFlags) apply(cmd *cobra.Command, config *models.Config) error {
+	flags := cmd.Flags()
+	if flags.Changed("preset") {
+		config.SubtitlePreset = f.preset
+	}
+	if flags.Changed("debug-addr") {
+		config.DebugAddr = f.debugAddr
+	}
+	if flags.Changed("audio-track") {
+		config.AudioTrack = f.audioTrack
+	}
+	if flags.Changed("start") {
+		config.ClipStart = f.clipStart
+	}
+	if flags.Changed("end") {
+		config.ClipEnd = f.clipEnd
+	}
+	for format, enabled := range f.formats {
+		if flags.Changed(format) {
+			*formatField(config, format) = *enabled
+		}
+	}
+	return config.Validate()
+}
+
+// newController 创建处理器控制器并应用命令行选项，检查依赖。调用方负责 Cleanup
+func newController(cmd *cobra.Command, flags *mediaFlags) (*controller.ProcessorController, int) {
+	// 先重定向标准输出再初始化日志
+	progress := newProgressManager()
+	config, err := loadCommandConfig(configFile)
+	if err != nil {
+		fmt.Println(err)
+		return nil, 1
+	}
+	pc, err := controller.NewProcessorControllerWithConfig(config, progress)
+	if err != nil {
+		fmt.Printf("初始化控制器失败: %v\n", err)
+		return nil, 1
+	}
+	if err := flags.apply(cmd, pc.Config); err != nil {
+		pc.Cleanup()
+		fmt.Printf("命令行选项无效: %v\n", err)
+		return nil, 2
+	}
+
+	printWelcome()
+	if !checkDependencies() {
+		pc.Cleanup()
+		utils.Error("缺少必要的依赖项，无法继续")
+		return nil, 1
+	}
+	return pc, 0
+}
+
+func newProcessCommand() *cobra.Command {
+	var flags mediaFlags
+	var dryRun bool
+	var sourceURL string
+	cmd := &cobra.Command{
+		Use:   "process [文件 ...]",
+		Short: "处理媒体目录中的文件或指定的文件后退出",
+		Long: "处理配置中 media_folder 下尚未处理的媒体文件，或用 --url 下载并处理单个视频链接，完成后退出。\n" +
+			"指定文件或通配符（如 \"*.mp4\"）时只处理这些文件，不扫描媒体目录；标准输出只输出生成的文件路径，\n" +
+			"每行一个，日志与进度输出到标准错误，便于脚本使用，如:\n\n" +
+			"  asr process lecture.mp4 \"records/*.mp3\" --srt --json",
+		Args: cobra.ArbitraryArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) > 0 && (dryRun || sourceURL != "") {
+				return fmt.Errorf("指定文件时不能使用 --dry-run 或 --url")
+			}
+			if dryRun {
+				return exit(runDryRun(configFile))
+			}
+			if len(args) > 0 {
+				return exit(runProcessFiles(cmd, &flags, args))
+			}
+			return exit(runProcess(cmd, &flags, sourceURL))
+		},
+	}
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "仅预估音频总时长、识别请求数、配额消耗与耗时，不执行处理")
+	cmd.Flags().StringVar(&sourceURL, "url", "", "用 yt-dlp 下载视频链接（YouTube、哔哩哔哩、抖音等）中的音频后识别，只处理该链接")
+	flags.register(cmd)
+	return cmd
+}
+
+// runProcess 处理媒体目录或指定的链接
+func runProcess(cmd *cobra.Command, flags *mediaFlags, sourceURL string) int {
+	pc, code := newController(cmd, flags)
+	if pc == nil {
+		return code
+	}
+	defer pc.Cleanup()
+
+	var results []audio.BatchResult
+	var err error
+	if sourceURL != "" {
+		results, err = pc.ProcessURL(sourceURL)
+		if err != nil {
+			utils.Error("处理链接失败: %v", err)
+			return 1
+		}
+	} else {
+		results, err = pc.ProcessMedia()
+		if err != nil {
+			utils.Error("处理媒体文件失败: %v", err)
+			return 1
+		}
+	}
+	if pc.Config.ExportSRT && len(results) > 0 {
+		pc.RunASRService(results)
+	}
+
+	color.Green("\n所有处理任务已完成!")
+	return 0
+}
+
+// runProcessFiles 只处理命令行中指定的文件，标准输出中每行输出一个生成的文件路径
+func runProcessFiles(cmd *cobra.Command, flags *mediaFlags, args []string) int {
+	stdout := redirectStdout()
+	pc, code := newController(cmd, flags)
+	if pc == nil {
+		return code
+	}
+	defer pc.Cleanup()
+
+	files, err := expandMediaArgs(args, pc.BatchProcessor.IsSupportedFile)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	results := pc.ProcessFiles(files)
+
+	// 按命令行中的顺序输出
+	order := make(map[string]int, len(files))
+	for i, file := range files {
+		order[file] = i
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return order[results[i].FilePath] < order[results[j].FilePath]
+	})
+	// JSON 进度的结束事件中已包含输出文件，不再单独输出路径
+	if progressMode == progressJSON {
+		stdout = nil
+	}
+	if failed := printResultPaths(stdout, results); failed > 0 {
+		return 1
+	}
+	return 0
+}
+
+// printResultPaths 输出每个文件生成的文件路径，失败或未识别的文件提示到标准错误，返回失败的文件数。
+// w 为空时只提示失败的文件
+func printResultPaths(w io.Writer, results []audio.BatchResult) int {
+	failed := 0
+	for _, result := range results {
+		if !result.Success {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", result.FilePath, result.Error)
+			failed++
+			continue
+		}
+		if result.Status != "" {
+			fmt.Fprintf(os.Stderr, "%s: %s，未生成文稿\n", result.FilePath, result.Status)
+		}
+		if w == nil {
+			continue
+		}
+		formats := make([]string, 0, len(result.OutputFiles))
+		for format := range result.OutputFiles {
+			formats = append(formats, format)
+		}
+		sort.Strings(formats)
+		for _, format := range formats {
+			fmt.Fprintln(w, result.OutputFiles[format])
+		}
+	}
+	return failed
+}
+
+// expandMediaArgs 展开命令行中的文件与通配符，返回去重后的绝对路径。
+// 通配符只匹配 supported 接受的媒体文件，直接指定的文件原样处理
+func expandMediaArgs(args []string, supported func(string) bool) ([]string, error) {
+	var files []string
+	seen := make(map[string]bool)
+	for _, arg := range args {
+		matches := []string{arg}
+		pattern := strings.ContainsAny(arg, "*?[")
+		if pattern {
+			var err error
+			if matches, err = filepath.Glob(arg); err != nil {
+				return nil, fmt.Errorf("通配符 %s 无效: %w", arg, err)
+			}
+		}
+
+		found := false
+		for _, match := range matches {
+			info, err := os.Stat(match)
+			if err != nil {
+				return nil, fmt.Errorf("无法读取文件: %w", err)
+			}
+			if info.IsDir() {
+				if pattern {
+					continue
+				}
+				return nil, fmt.Errorf("%s 是目录，请指定其中的文件或使用通配符", match)
+			}
+			if pattern && !supported(match) {
+				continue
+			}
+			found = true
+			if abs, err := filepath.Abs(match); err == nil {
+				match = abs
+			}
+			if !seen[match] {
+				seen[match] = true
+				files = append(files, match)
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("没有与 %s 匹配的媒体文件", arg)
+		}
+	}
+	return files, nil
+}
+
+// originalStdout 重定向之前的标准输出，见 redirectStdout
+var originalStdout *os.File
+
+// redirectStdout 将日志、进度与提示改为输出到标准错误，返回原来的标准输出，
+// 供标准输出只输出结果的场景使用。需在初始化日志与进度条之前调用，重复调用返回同一个标准输出
+func redirectStdout() *os.File {
+	if originalStdout == nil {
+		originalStdout = os.Stdout
+		os.Stdout = os.Stderr
+		color.Output = color.Error
+	}
+	return originalStdout
+}
+
+func newWatchCommand() *cobra.Command {
+	var flags mediaFlags
+	var processNow bool
+	cmd := &cobra.Command{
+		Use:   "watch",
+		Short: "监听媒体目录，处理新加入的文件",
+		Long: "持续监听 media_folder（或 watch_profiles 中的各目录），文件写入完成后自动处理，按 Ctrl+C 退出。\n" +
+			"配置了 watch_schedule 时只在时间窗口内处理，--process-now 让正在运行的实例立即处理排队的文件",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if processNow {
+				return exit(runProcessNow(configFile))
+			}
+			return exit(runWatch(cmd, &flags))
+		},
+	}
+	cmd.Flags().BoolVar(&processNow, "process-now", false, "让正在运行的监听模式立即处理等待时间窗口（watch_schedule）的文件后退出，通过 watch_status_addr 通知")
+	flags.register(cmd)
+	return cmd
+}
+
+// runWatch 运行监听模式直到收到退出信号
+func runWatch(cmd *cobra.Command, flags *mediaFlags) int {
+	pc, code := newController(cmd, flags)
+	if pc == nil {
+		return code
+	}
+	defer pc.Cleanup()
+
+	if err := pc.StartWatchMode(); err != nil {
+		utils.Error("监控模式运行失败: %v", err)
+		return 1
+	}
+	return 0
+}
+
+func printWelcome() {
+	// 使用彩色输出打印欢迎信息
+	fmt.Println()
+	color.Cyan("================================")
+	color.Cyan("   音频处理工具 - Go 实现版本   ")
+	color.Cyan("================================")
+	fmt.Println()
+}
+
+func checkDependencies() bool {
+	fmt.Print("检查系统依赖... ")
+
+	// 检查ffmpeg
+	if !utils.CheckFFmpeg() {
+		color.Red("失败")
+		utils.Error("未检测到FFmpeg，请确保FFmpeg已安装并添加到系统路径")
+		return false
+	}
+
+	color.Green("通过")
+	return true
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/audio"
+)
+
+func TestExpandMediaArgs(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.mp4", "b.mp3", "notes.txt"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), nil, 0644))
+	}
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "sub.mp4"), 0755))
+	supported := func(path string) bool { return !strings.HasSuffix(path, ".txt") }
+
+	// 通配符跳过目录与不支持的文件，重复的文件只处理一次
+	files, err := expandMediaArgs([]string{filepath.Join(dir, "b.mp3"), filepath.Join(dir, "*")}, supported)
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "b.mp3"), filepath.Join(dir, "a.mp4")}, files)
+
+	// 直接指定的文件不检查格式
+	files, err = expandMediaArgs([]string{filepath.Join(dir, "notes.txt")}, supported)
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "notes.txt")}, files)
+
+	_, err = expandMediaArgs([]string{filepath.Join(dir, "*.wav")}, supported)
+	assert.Error(t, err)
+	_, err = expandMediaArgs([]string{filepath.Join(dir, "missing.mp4")}, supported)
+	assert.Error(t, err)
+	_, err = expandMediaArgs([]string{filepath.Join(dir, "sub.mp4")}, supported)
+	assert.Error(t, err)
+}
+
+func TestPrintResultPaths(t *testing.T) {
+	results := []audio.BatchResult{
+		{FilePath: "a.mp4", Success: true, OutputFiles: map[string]string{"srt": "a.srt", "json": "a.json"}},
+		{FilePath: "b.mp4", Success: false, Error: errors.New("识别失败")},
+		{FilePath: "c.mp4", Success: true, Status: audio.StatusNoAudio},
+	}
+	var out bytes.Buffer
+	assert.Equal(t, 1, printResultPaths(&out, results))
+	assert.Equal(t, "a.json\na.srt\n", out.String())
+}
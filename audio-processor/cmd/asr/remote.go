@@ -0,0 +1,273 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/internal/watcher"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/audio"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/client"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/export"
+)
+
+// remoteRun 在收到中断信号时取消的上下文中执行远程操作，token 不为空时随请求携带
+func remoteRun(server, token string, run func(ctx context.Context, c *client.Client) error) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	c := client.New(server)
+	c.Token = token
+	if err := run(ctx, c); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exit(1)
+	}
+	return nil
+}
+
+// newRemoteCommand 实现 `asr remote` 子命令，通过HTTP接口使用另一台机器上的 asr
+func newRemoteCommand() *cobra.Command {
+	server := os.Getenv("AUDIOPROC_SERVER")
+	if server == "" {
+		server = client.DefaultServer
+	}
+	var token string
+	cmd := &cobra.Command{
+		Use:   "remote",
+		Short: "通过HTTP接口使用另一台机器上的 asr",
+		Long: "通过HTTP接口使用另一台机器上的 asr。--server 默认读取环境变量 AUDIOPROC_SERVER，未设置时为 " + client.DefaultServer + "；\n" +
+			"服务器启用认证（web_auth_mode）时用 --token 传入API密钥或 JWT，默认读取环境变量 AUDIOPROC_TOKEN",
+	}
+	cmd.PersistentFlags().StringVar(&server, "server", server, "远程实例地址")
+	cmd.PersistentFlags().StringVar(&token, "token", os.Getenv("AUDIOPROC_TOKEN"), "远程实例的API密钥或 JWT")
+
+	var submitDir string
+	var submitInterval time.Duration
+	submit := &cobra.Command{
+		Use:   "submit <文件> [...]",
+		Short: "上传到 audio_web 识别，可同时下载结果",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return remoteRun(server, token, func(ctx context.Context, c *client.Client) error {
+				return remoteSubmit(ctx, c, args, submitDir, submitInterval)
+			})
+		},
+	}
+	submit.Flags().StringVarP(&submitDir, "output", "o", "", "下载目录，为空表示不下载")
+	submit.MarkFlagDirname("output")
+	submit.Flags().DurationVar(&submitInterval, "interval", 2*time.Second, "等待识别时查询任务的间隔")
+
+	var wait bool
+	var interval time.Duration
+	enqueue := &cobra.Command{
+		Use:   "enqueue <服务器上的路径>",
+		Short: "加入监听模式的处理队列",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return remoteRun(server, token, func(ctx context.Context, c *client.Client) error {
+				return remoteEnqueue(ctx, c, args[0], wait, interval)
+			})
+		},
+	}
+	enqueue.Flags().BoolVar(&wait, "wait", false, "加入队列后等待处理结束")
+	enqueue.Flags().DurationVar(&interval, "interval", 5*time.Second, "等待时查询队列的间隔")
+
+	status := &cobra.Command{
+		Use:   "status",
+		Short: "查看监听队列",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return remoteRun(server, token, remoteStatus)
+		},
+	}
+
+	var tag string
+	list := &cobra.Command{
+		Use:   "list",
+		Short: "列出服务器上的识别结果",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return remoteRun(server, token, func(ctx context.Context, c *client.Client) error {
+				return remoteList(ctx, c, tag)
+			})
+		},
+	}
+	list.Flags().StringVar(&tag, "tag", "", "只列出带有该标签的文件")
+
+	var downloadDir string
+	download := &cobra.Command{
+		Use:   "download <文件名> [格式 ...]",
+		Short: "下载识别结果，默认下载全部格式",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return remoteRun(server, token, func(ctx context.Context, c *client.Client) error {
+				return remoteDownload(ctx, c, args[0], args[1:], downloadDir)
+			})
+		},
+	}
+	download.Flags().StringVarP(&downloadDir, "output", "o", ".", "下载目录")
+	download.MarkFlagDirname("output")
+
+	cmd.AddCommand(submit, enqueue, status, list, download)
+	return cmd
+}
+
+// remoteSubmit 逐个上传文件提交为任务并等待识别结束，dir 不为空时下载每个文件的全部输出
+func remoteSubmit(ctx context.Context, c *client.Client, files []string, dir string, interval time.Duration) error {
+	failed := 0
+	for _, path := range files {
+		fmt.Printf("上传 %s ...\n", path)
+		job, err := c.Submit(ctx, path)
+		if err == nil {
+			fmt.Printf("  已提交任务 %s，等待识别 ...\n", job.ID)
+			job, err = c.WaitJob(ctx, job.ID, interval)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "  失败: %v\n", err)
+			failed++
+			continue
+		}
+		result := job.Result
+		if job.Status != audio.WebJobCompleted || result == nil || !result.Success {
+			message := job.Error
+			if message == "" && result != nil {
+				message = result.ErrorMessage
+			}
+			fmt.Fprintf(os.Stderr, "  失败: %s\n", message)
+			failed++
+			continue
+		}
+		fmt.Printf("  完成，用时 %v\n", job.FinishedAt.Sub(job.StartedAt).Round(time.Second))
+		if result.Manifest == nil {
+			continue
+		}
+		if dir == "" {
+			fmt.Printf("  输出: %s（使用 asr remote download %s 下载）\n", outputTypes(result.Manifest), result.Manifest.Name)
+			continue
+		}
+		if err := downloadOutputs(ctx, c, result.Manifest, nil, dir); err != nil {
+			fmt.Fprintf(os.Stderr, "  %v\n", err)
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d 个文件失败", failed)
+	}
+	return nil
+}
+
+// remoteEnqueue 将服务器上的文件加入处理队列，wait 时等待处理结束
+func remoteEnqueue(ctx context.Context, c *client.Client, serverPath string, wait bool, interval time.Duration) error {
+	// 入队前记录该文件上一次处理结束的时间，等待时只接受之后的结果
+	var before *watcher.QueueSnapshot
+	if wait {
+		var err error
+		if before, err = c.Queue(ctx); err != nil {
+			return err
+		}
+	}
+
+	path, err := c.Enqueue(ctx, serverPath)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("已加入队列: %s\n", path)
+	if !wait {
+		return nil
+	}
+
+	entry, err := c.Wait(ctx, path, client.LastFinished(before, path), interval)
+	if err != nil {
+		return err
+	}
+	if entry.State != watcher.QueueCompleted {
+		return fmt.Errorf("处理失败 (%s): %s", entry.State, entry.Message)
+	}
+	fmt.Printf("处理完成: %s\n", entry.Name)
+	return nil
+}
+
+// remoteStatus 打印监听队列
+func remoteStatus(ctx context.Context, c *client.Client) error {
+	snapshot, err := c.Queue(ctx)
+	if err != nil {
+		return err
+	}
+	fmt.Print(snapshot.Format())
+	return nil
+}
+
+// remoteList 列出服务器上的识别结果
+func remoteList(ctx context.Context, c *client.Client, tag string) error {
+	manifests, err := c.Outputs(ctx, tag)
+	if err != nil {
+		return err
+	}
+	if len(manifests) == 0 {
+		fmt.Println("没有识别结果")
+		return nil
+	}
+	for _, manifest := range manifests {
+		line := fmt.Sprintf("%s  (%s)  %s", manifest.Name, manifest.CreatedAt, outputTypes(manifest))
+		if len(manifest.Tags) > 0 {
+			line += "  #" + strings.Join(manifest.Tags, " #")
+		}
+		fmt.Println(line)
+	}
+	return nil
+}
+
+// remoteDownload 下载文件的指定格式，types 为空时下载全部
+func remoteDownload(ctx context.Context, c *client.Client, name string, types []string, dir string) error {
+	manifest, err := c.Manifest(ctx, export.ItemName(name))
+	if err != nil {
+		return err
+	}
+	return downloadOutputs(ctx, c, manifest, types, dir)
+}
+
+// downloadOutputs 下载清单中的输出，types 为空时下载全部。源媒体与归档文件较大，只在明确指定时下载
+func downloadOutputs(ctx context.Context, c *client.Client, manifest *export.OutputManifest, types []string, dir string) error {
+	wanted := make(map[string]bool)
+	for _, fileType := range types {
+		wanted[fileType] = true
+	}
+	all := len(wanted) == 0
+	for _, entry := range manifest.Outputs {
+		if !all && !wanted[entry.Type] {
+			continue
+		}
+		if all && (entry.Type == "media" || entry.Type == "archive") {
+			continue
+		}
+		delete(wanted, entry.Type)
+		path, err := c.Download(ctx, manifest.Name, entry, dir)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("  已下载 %s\n", path)
+	}
+	if len(wanted) > 0 {
+		missing := make([]string, 0, len(wanted))
+		for fileType := range wanted {
+			missing = append(missing, fileType)
+		}
+		sort.Strings(missing)
+		return fmt.Errorf("%s 没有 %s 格式的输出", manifest.Name, strings.Join(missing, "、"))
+	}
+	return nil
+}
+
+// outputTypes 返回清单中的输出格式列表
+func outputTypes(manifest *export.OutputManifest) string {
+	types := make([]string, 0, len(manifest.Outputs))
+	for _, entry := range manifest.Outputs {
+		types = append(types, entry.Type)
+	}
+	return strings.Join(types, ", ")
+}
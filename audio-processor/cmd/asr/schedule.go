@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"time"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/client"
+)
+
+// runProcessNow 通过 watch_status_addr 让正在运行的监听模式立即处理等待时间窗口的文件，
+// 配置了 watch_profiles 时通知每个监控目录
+func runProcessNow(configPath string) int {
+	config, err := loadCommandConfig(configPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if config.WatchStatusAddr == "" {
+		fmt.Fprintln(os.Stderr, "未配置 watch_status_addr，无法通知正在运行的监听模式")
+		return 1
+	}
+
+	profiles := []string{""}
+	if len(config.WatchProfiles) > 0 {
+		profiles = profiles[:0]
+		for _, profile := range config.WatchProfiles {
+			profiles = append(profiles, profile.Name)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	c := client.New(statusURL(config.WatchStatusAddr))
+	failed := false
+	for _, profile := range profiles {
+		label := profile
+		if label == "" {
+			label = config.MediaFolder
+		}
+		status, err := c.ProcessNow(ctx, profile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", label, err)
+			failed = true
+			continue
+		}
+		fmt.Printf("%s: 立即处理 %d 个等待时间窗口的文件\n", label, status.Released)
+	}
+	if failed {
+		return 1
+	}
+	return 0
+}
+
+// statusURL 将监听地址（如 :8090、0.0.0.0:8090）转换为本机访问的地址
+func statusURL(addr string) string {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "http://" + addr
+	}
+	if host == "" || host == "0.0.0.0" || host == "::" {
+		host = "localhost"
+	}
+	return "http://" + net.JoinHostPort(host, port)
+}
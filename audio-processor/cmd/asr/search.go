@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/search"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/utils"
+)
+
+// searchOptions search 命令的选项
+type searchOptions struct {
+	limit   int
+	perFile int
+	rebuild bool
+}
+
+func newSearchCommand() *cobra.Command {
+	var opts searchOptions
+	cmd := &cobra.Command{
+		Use:   "search <查询词> [...]",
+		Short: "在全部文稿中查找关键词",
+		Long:  "在输出目录的全部文稿中查找关键词，列出命中的文件、段落与时间点。查询词以空白分隔，段落需包含全部查询词",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return exit(runSearch(args, &opts))
+		},
+	}
+	cmd.Flags().IntVar(&opts.limit, "limit", 20, "最多列出的文件数，0 表示不限")
+	cmd.Flags().IntVar(&opts.perFile, "per-file", 5, "每个文件最多列出的段落数，0 表示不限")
+	cmd.Flags().BoolVar(&opts.rebuild, "rebuild", false, "删除现有索引后重建")
+	return cmd
+}
+
+// runSearch 实现 `asr search` 子命令，在输出目录的全部文稿中查找关键词
+func runSearch(terms []string, opts *searchOptions) int {
+	config, err := loadCommandConfig(configFile)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if opts.rebuild {
+		if err := os.Remove(search.IndexPath(config.OutputFolder)); err != nil && !os.IsNotExist(err) {
+			fmt.Fprintf(os.Stderr, "删除索引失败: %v\n", err)
+			return 1
+		}
+	}
+	index, err := search.Build(config.OutputFolder, config.MediaFolder)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	query := strings.Join(terms, " ")
+	results := index.Search(query, search.Options{Limit: opts.limit, PerFile: opts.perFile})
+	if len(results) == 0 {
+		fmt.Printf("没有找到 \"%s\"（已索引 %d 个文件）\n", query, len(index.Docs))
+		return 0
+	}
+	for _, result := range results {
+		fmt.Printf("%s  (%d 处，%s)\n", result.Name, result.Total, result.Type)
+		for _, hit := range result.Hits {
+			text := hit.Text
+			if hit.Speaker != "" {
+				text = hit.Speaker + ": " + text
+			}
+			if hit.Start >= 0 {
+				fmt.Printf("  [%s] %s\n", utils.FormatTime(hit.Start), text)
+			} else {
+				fmt.Printf("  %s\n", text)
+			}
+		}
+	}
+	return 0
+}
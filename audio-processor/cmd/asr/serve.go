@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/internal/controller"
+	"github.com/ccp-p/asr-media-cli/audio-processor/internal/server"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/utils"
+)
+
+// serveOptions serve 命令的选项
+type serveOptions struct {
+	features  string
+	debugAddr string
+	server.Options
+}
+
+func newServeCommand() *cobra.Command {
+	var opts serveOptions
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "启动Web服务",
+		Long: "统一的Web服务：通过 --features 组合上传页面（ui）、识别结果与备注接口（notes）、\n" +
+			"页面保存接口（save）与媒体目录监听（watch），取代分别启动 audio_web、webserver 与监听模式",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return exit(runServe(cmd, &opts))
+		},
+	}
+	flags := cmd.Flags()
+	flags.StringVar(&opts.Addr, "addr", ":8080", "监听地址")
+	flags.StringVar(&opts.features, "features", "ui,notes", "启用的功能，逗号分隔：ui（上传页面与任务接口）、notes（识别结果、标签备注与搜索接口）、save（页面保存接口 /save）、watch（媒体目录监听），all 为全部")
+	flags.StringVar(&opts.UploadDir, "upload-dir", "./uploads", "上传文件存储目录")
+	flags.StringVar(&opts.TempDir, "temp-dir", "./temp", "临时文件目录")
+	flags.StringVar(&opts.OutputDir, "output-dir", "./output", "输出文件目录")
+	flags.StringVar(&opts.WebRoot, "web-root", "./web", "页面目录，包含 index.html 与 static")
+	flags.StringVar(&opts.SaveFile, "save-file", "saved_dom_data.html", "save 功能追加写入的文件")
+	flags.StringVar(&opts.VolcesAPIKey, "volces-api-key", "", "Volces API密钥，用于摘要接口")
+	flags.StringVar(&opts.debugAddr, "debug-addr", "", "pprof 与自检数据的HTTP地址（如 127.0.0.1:6060），覆盖配置中的 debug_addr")
+	cmd.MarkFlagDirname("upload-dir")
+	cmd.MarkFlagDirname("temp-dir")
+	cmd.MarkFlagDirname("output-dir")
+	cmd.MarkFlagDirname("web-root")
+	cmd.RegisterFlagCompletionFunc("features", cobra.FixedCompletions(
+		[]string{"ui", "notes", "save", "watch", "all"}, cobra.ShellCompDirectiveNoFileComp))
+	return cmd
+}
+
+// runServe 运行Web服务直到收到退出信号
+func runServe(cmd *cobra.Command, opts *serveOptions) int {
+	enabled, err := server.ParseFeatures(opts.features)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "参数 --features 无效: %v\n", err)
+		return 2
+	}
+	options := opts.Options
+	options.Features = enabled
+
+	// 只启用 save 时不需要加载配置与检查 ffmpeg
+	var pc *controller.ProcessorController
+	if len(enabled) > 1 || enabled[0] != server.FeatureSave {
+		config, err := loadCommandConfig(configFile)
+		if err != nil {
+			fmt.Println(err)
+			return 1
+		}
+		pc, err = controller.NewProcessorControllerWithConfig(config, nil)
+		if err != nil {
+			fmt.Printf("初始化控制器失败: %v\n", err)
+			return 1
+		}
+		defer pc.Cleanup()
+		if cmd.Flags().Changed("debug-addr") {
+			pc.Config.DebugAddr = opts.debugAddr
+		}
+		if !utils.CheckFFmpeg() {
+			utils.Error("未检测到FFmpeg，请确保FFmpeg已安装并添加到系统路径")
+			return 1
+		}
+	} else {
+		utils.InitLogger(logLevel, logFile)
+	}
+
+	srv, err := server.New(pc, options)
+	if err != nil {
+		utils.Error("%v", err)
+		return 1
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if err := srv.Run(ctx); err != nil {
+		utils.Error("%v", err)
+		return 1
+	}
+	return 0
+}
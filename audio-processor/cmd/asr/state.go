@@ -0,0 +1,132 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/asr"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/audit"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/models"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/state"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/trash"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/utils"
+)
+
+func newStateCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "state",
+		Short: "备份与迁移应用状态",
+	}
+	cmd.AddCommand(&cobra.Command{
+		Use:   "export <state.tar.gz>",
+		Short: "将配置、缓存、统计等状态导出为状态包",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return exit(runStateExport(args[0]))
+		},
+	})
+
+	var keepConfig bool
+	restore := &cobra.Command{
+		Use:   "restore <state.tar.gz>",
+		Short: "从状态包恢复状态，状态包中的配置恢复到 --config（默认 config.json）",
+		Args:  cobra.ExactArgs(1),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			return []string{"gz"}, cobra.ShellCompDirectiveFilterFileExt
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return exit(runStateRestore(args[0], keepConfig))
+		},
+	}
+	restore.Flags().BoolVar(&keepConfig, "keep-config", false, "保留本机配置，不恢复状态包中的配置文件")
+	cmd.AddCommand(restore)
+	return cmd
+}
+
+// loadCommandConfig 加载子命令使用的配置并初始化日志，未指定配置文件时使用默认配置
+func loadCommandConfig(path string) (*models.Config, error) {
+	config, err := loadConfig(path)
+	if err != nil {
+		return nil, err
+	}
+	utils.InitLogger(logLevel, logFile)
+	utils.ConfigureHTTPClient(config.HTTPClientOptions())
+	return config, nil
+}
+
+// runStateExport 实现 `asr state export` 子命令
+func runStateExport(archive string) int {
+	config, err := loadCommandConfig(configFile)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	items := state.Items(config, state.Locations{ConfigFile: configFile, CacheDir: asr.CacheDir(config)})
+	exported, err := state.Export(archive, items)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "导出状态失败: %v\n", err)
+		return 1
+	}
+
+	fmt.Printf("已导出 %d 项状态到 %s\n", len(exported), archive)
+	for _, item := range exported {
+		fmt.Printf("  - %s (%s)\n", item.Name, item.Path)
+	}
+	return 0
+}
+
+// runStateRestore 实现 `asr state restore` 子命令
+func runStateRestore(archive string, keepConfig bool) int {
+	configPath := commandConfigPath("config.json")
+
+	manifest, err := state.ReadManifest(archive)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "读取状态包失败: %v\n", err)
+		return 1
+	}
+	fmt.Printf("状态包创建于 %s (%s)，共 %d 项\n",
+		manifest.CreatedAt.Format("2006-01-02 15:04:05"), manifest.Hostname, len(manifest.Items))
+
+	// 先恢复配置，再按恢复后的配置确定其他状态的位置
+	if !keepConfig {
+		current, err := loadCommandConfig("")
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		if _, statErr := os.Stat(configPath); statErr == nil {
+			if current, err = loadCommandConfig(configPath); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return 1
+			}
+		}
+		audit.SetDefault(audit.NewLogger(current.AuditLogPath()))
+		if _, err := state.RestoreConfig(archive, configPath, trash.FromConfig(current)); err != nil {
+			fmt.Fprintf(os.Stderr, "恢复配置失败: %v\n", err)
+			return 1
+		}
+	}
+
+	config := models.NewDefaultConfig()
+	if _, statErr := os.Stat(configPath); statErr == nil {
+		if config, err = loadCommandConfig(configPath); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	}
+	audit.SetDefault(audit.NewLogger(config.AuditLogPath()))
+
+	// 配置文件已单独处理，这里只恢复其余状态
+	items := state.Items(config, state.Locations{CacheDir: asr.CacheDir(config)})
+	restored, err := state.Restore(archive, items, trash.FromConfig(config))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "恢复状态失败: %v\n", err)
+		return 1
+	}
+
+	fmt.Printf("已恢复 %d 个文件，被覆盖的文件已移入回收站\n", restored)
+	return 0
+}
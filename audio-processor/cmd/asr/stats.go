@@ -0,0 +1,151 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/store"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/usage"
+)
+
+// statsOptions stats 命令的选项
+type statsOptions struct {
+	file     string
+	months   int
+	llmUsage bool
+	days     int
+	jobs     int
+}
+
+func newStatsCommand() *cobra.Command {
+	var opts statsOptions
+	cmd := &cobra.Command{
+		Use:   "stats",
+		Short: "按月汇总本地使用量统计",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return exit(runStats(&opts))
+		},
+	}
+	cmd.Flags().StringVar(&opts.file, "file", "", "统计文件路径，优先于配置文件")
+	cmd.Flags().IntVar(&opts.months, "months", 6, "显示最近的月份数，0 表示全部")
+	cmd.Flags().BoolVar(&opts.llmUsage, "llm", false, "显示处理记录数据库中的大模型 token 用量")
+	cmd.Flags().IntVar(&opts.days, "days", 30, "与 --llm 一起使用，显示最近的天数")
+	cmd.Flags().IntVar(&opts.jobs, "jobs", 10, "与 --llm 一起使用，显示用量最多的任务数，0 表示不显示")
+	return cmd
+}
+
+// runStats 实现 `asr stats` 子命令，按月汇总本地使用量统计；--llm 时按天与按任务汇总大模型 token 用量
+func runStats(opts *statsOptions) int {
+	if opts.llmUsage {
+		config, err := loadCommandConfig(configFile)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		return printLLMUsage(store.Path(config.OutputFolder), opts.days, opts.jobs)
+	}
+
+	path := opts.file
+	if path == "" {
+		config, err := loadCommandConfig(configFile)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		path = config.UsageStatsPath()
+	}
+
+	stats, err := usage.Load(path)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	keys := stats.MonthKeys()
+	if len(keys) == 0 {
+		fmt.Printf("没有使用量记录 (%s)\n", path)
+		return 0
+	}
+	if opts.months > 0 && len(keys) > opts.months {
+		keys = keys[:opts.months]
+	}
+
+	fmt.Printf("使用量统计: %s\n", path)
+	for _, key := range keys {
+		month := stats.Months[key]
+		fmt.Printf("\n%s  文件 %d 个，失败 %d 个，识别 %.1f 分钟\n", key, month.Files, month.Failures, month.Minutes())
+
+		names := make([]string, 0, len(month.Services))
+		for name := range month.Services {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			service := month.Services[name]
+			fmt.Printf("  %-12s 文件 %4d  失败 %4d  %8.1f 分钟\n", name, service.Files, service.Failures, service.AudioSeconds/60)
+		}
+	}
+	return 0
+}
+
+// printLLMUsage 按天与按任务输出最近 days 天的大模型 token 用量
+func printLLMUsage(path string, days, jobs int) int {
+	if _, err := os.Stat(path); err != nil {
+		fmt.Printf("没有大模型用量记录 (%s)\n", path)
+		return 0
+	}
+	db, err := store.Open(path)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	defer db.Close()
+
+	since := time.Now().AddDate(0, 0, 1-days)
+	byDay, err := db.LLMUsageByDay(since)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if len(byDay) == 0 {
+		fmt.Printf("最近 %d 天没有大模型用量记录 (%s)\n", days, path)
+		return 0
+	}
+
+	fmt.Printf("大模型用量: %s（最近 %d 天）\n\n", path, days)
+	var total store.LLMUsageTotal
+	for _, row := range byDay {
+		fmt.Printf("%s  %-32s 请求 %5d  输入 %10d  输出 %9d tokens\n",
+			row.Key, row.Provider+"/"+row.Model, row.Requests, row.PromptTokens, row.CompletionTokens)
+		total.Requests += row.Requests
+		total.PromptTokens += row.PromptTokens
+		total.CompletionTokens += row.CompletionTokens
+	}
+	fmt.Printf("\n合计: 请求 %d 次，输入 %d tokens，输出 %d tokens\n", total.Requests, total.PromptTokens, total.CompletionTokens)
+
+	if jobs <= 0 {
+		return 0
+	}
+	byJob, err := db.LLMUsageByJob(since)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if len(byJob) > jobs {
+		byJob = byJob[:jobs]
+	}
+	if len(byJob) > 0 {
+		fmt.Printf("\n用量最多的 %d 个任务:\n", len(byJob))
+	}
+	for _, row := range byJob {
+		fmt.Printf("  %-40s %-24s 请求 %4d  %10d tokens\n",
+			filepath.Base(row.Key), row.Provider+"/"+row.Model, row.Requests, row.PromptTokens+row.CompletionTokens)
+	}
+	return 0
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/export"
+)
+
+func newTagCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "tag <文件> [+标签 ...] [-标签 ...] [--note 备注]",
+		Short: "为已处理的文件添加或删除标签与备注",
+		Long: "为已处理的文件添加或删除标签与备注，如:\n\n" +
+			"  asr tag <文件> +course +golang -draft --note 备注\n\n" +
+			"没有任何修改时显示文件当前的标签与备注",
+		// 标签以 +/- 开头，与选项的写法冲突，因此手动解析参数
+		DisableFlagParsing: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			for _, arg := range args {
+				if arg == "-h" || arg == "--help" {
+					return cmd.Help()
+				}
+			}
+			return exit(runTag(args))
+		},
+	}
+}
+
+// runTag 实现 `asr tag` 子命令，为已处理的文件添加或删除标签与备注
+func runTag(args []string) int {
+	usage := func() {
+		fmt.Fprintln(os.Stderr, "用法:")
+		fmt.Fprintln(os.Stderr, "  asr tag <文件> [+标签 ...] [-标签 ...] [--note 备注]")
+	}
+
+	// 全局选项也需手动解析，可出现在任意位置
+	name := ""
+	var update export.MetaUpdate
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch arg {
+		case "--config", "--set", "--note", "--log-level", "--log-file", "-note":
+			if i+1 >= len(args) {
+				fmt.Fprintf(os.Stderr, "%s 缺少参数值\n", arg)
+				return 2
+			}
+			i++
+			switch arg {
+			case "--config":
+				configFile = args[i]
+			case "--set":
+				configSets = append(configSets, args[i])
+			case "--log-level":
+				logLevel = args[i]
+			case "--log-file":
+				logFile = args[i]
+			default:
+				note := args[i]
+				update.Note = &note
+			}
+			continue
+		}
+		switch {
+		case strings.HasPrefix(arg, "+") && len(arg) > 1:
+			update.Add = append(update.Add, arg[1:])
+		case strings.HasPrefix(arg, "-") && len(arg) > 1:
+			update.Remove = append(update.Remove, arg[1:])
+		case name == "":
+			name = arg
+		default:
+			usage()
+			return 2
+		}
+	}
+	if name == "" {
+		usage()
+		return 2
+	}
+
+	config, err := loadCommandConfig(configFile)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	store := export.OpenTagStore(config.OutputFolder)
+	var meta export.ItemMeta
+	if len(update.Add) == 0 && len(update.Remove) == 0 && update.Note == nil {
+		meta, err = store.Get(name)
+	} else {
+		if meta, err = store.Update(name, update); err == nil {
+			err = export.SyncFrontmatter(config, name)
+		}
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	fmt.Println(export.ItemName(name))
+	if meta.Empty() {
+		fmt.Println("  (没有标签)")
+		return 0
+	}
+	if len(meta.Tags) > 0 {
+		fmt.Printf("  标签: %s\n", strings.Join(meta.Tags, ", "))
+	}
+	if meta.Note != "" {
+		fmt.Printf("  备注: %s\n", meta.Note)
+	}
+	return 0
+}
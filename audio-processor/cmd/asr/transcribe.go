@@ -0,0 +1,163 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/asr"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/audio"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/utils"
+)
+
+// transcribeFormats transcribe 可输出的格式：文本文稿与配置中可开关的导出格式
+func transcribeFormats() []string {
+	return append([]string{"txt"}, configFormats...)
+}
+
+func newTranscribeCommand() *cobra.Command {
+	var format, inputFormat string
+	cmd := &cobra.Command{
+		Use:   "transcribe <文件|->",
+		Short: "识别单个文件或标准输入，结果写到标准输出",
+		Long: "识别一个媒体文件，- 表示从标准输入读取，识别结果按 --format 写到标准输出，日志与进度输出到标准错误。\n" +
+			"输出写在临时目录中，不写入媒体目录与输出目录，便于在管道中使用，如:\n\n" +
+			"  cat audio.mp3 | asr transcribe --format=json - > audio.json",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			supported := false
+			for _, name := range transcribeFormats() {
+				supported = supported || name == format
+			}
+			if !supported {
+				return fmt.Errorf("不支持的格式: %s（可用: %s）", format, strings.Join(transcribeFormats(), ", "))
+			}
+			if progressMode == progressJSON {
+				return fmt.Errorf("transcribe 的标准输出用于识别结果，不能使用 --progress=%s", progressJSON)
+			}
+			return exit(runTranscribe(args[0], format, inputFormat))
+		},
+	}
+	cmd.Flags().StringVar(&format, "format", "txt", "输出格式 ("+strings.Join(transcribeFormats(), ", ")+")")
+	cmd.Flags().StringVar(&inputFormat, "input-format", "", "输入的格式（扩展名，如 mp3、mp4），默认标准输入按内容判断、文件按扩展名判断")
+	cmd.RegisterFlagCompletionFunc("format", cobra.FixedCompletions(transcribeFormats(), cobra.ShellCompDirectiveNoFileComp))
+	return cmd
+}
+
+// runTranscribe 实现 `asr transcribe` 子命令，识别结果写到标准输出
+func runTranscribe(input, format, inputFormat string) int {
+	stdout := redirectStdout()
+	config, err := loadCommandConfig(configFile)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if !utils.CheckFFmpeg() {
+		fmt.Fprintln(os.Stderr, "未检测到FFmpeg，请确保FFmpeg已安装并添加到系统路径")
+		return 1
+	}
+
+	reader, name, err := openTranscribeInput(input, inputFormat, os.Stdin)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	if closer, ok := reader.(io.Closer); ok {
+		defer closer.Close()
+	}
+
+	dir, err := os.MkdirTemp("", "asr-transcribe-")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "创建临时目录失败: %v\n", err)
+		return 1
+	}
+	defer os.RemoveAll(dir)
+
+	// 只导出需要的格式，输入与输出都放在临时目录中
+	local := *config
+	local.MediaFolder = filepath.Join(dir, "media")
+	local.OutputFolder = filepath.Join(dir, "output")
+	for _, name := range configFormats {
+		*formatField(&local, name) = name == format
+	}
+	web := audio.NewWebProcessor(local.MediaFolder, filepath.Join(dir, "temp"), local.OutputFolder, &local)
+	selector := asr.NewASRSelector()
+	selector.RegisterFromConfig(&local, asr.DefaultCreators())
+	web.Processor.SetASRSelector(selector)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	web.Processor.SetContext(ctx)
+
+	result, err := web.ProcessUploadedFile(reader, name)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if result.Status != "" {
+		fmt.Fprintf(os.Stderr, "%s，未生成文稿\n", result.Status)
+		return 0
+	}
+	path, ok := result.OutputFiles[format]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "未生成 %s 输出\n", format)
+		return 1
+	}
+	if err := copyFileTo(stdout, path); err != nil {
+		fmt.Fprintf(os.Stderr, "输出识别结果失败: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// openTranscribeInput 打开要识别的输入，返回内容与用于判断格式的文件名。
+// input 为 - 时读取 stdin，未指定 inputFormat 时按内容判断格式
+func openTranscribeInput(input, inputFormat string, stdin *os.File) (io.Reader, string, error) {
+	ext := ""
+	if inputFormat != "" {
+		ext = "." + strings.TrimPrefix(strings.ToLower(inputFormat), ".")
+	}
+
+	if input != "-" {
+		file, err := os.Open(input)
+		if err != nil {
+			return nil, "", fmt.Errorf("无法读取文件: %w", err)
+		}
+		name := filepath.Base(input)
+		if ext != "" {
+			name = strings.TrimSuffix(name, filepath.Ext(name)) + ext
+		}
+		return file, name, nil
+	}
+
+	if isTerminal(stdin) {
+		return nil, "", fmt.Errorf("标准输入是终端，请通过管道传入媒体内容，如 cat audio.mp3 | asr transcribe -")
+	}
+	reader := bufio.NewReaderSize(stdin, 4096)
+	if ext == "" {
+		// 内容不足时 Peek 返回已读到的部分
+		header, _ := reader.Peek(512)
+		if ext = audio.SniffExtension(header); ext == "" {
+			return nil, "", fmt.Errorf("无法判断标准输入的格式，请用 --input-format 指定（如 mp3、mp4）")
+		}
+	}
+	return reader, "stdin" + ext, nil
+}
+
+// copyFileTo 将文件内容写到 w
+func copyFileTo(w io.Writer, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = io.Copy(w, file)
+	return err
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pipeWith 返回读取 data 的管道，模拟 cat file | asr transcribe -
+func pipeWith(t *testing.T, data []byte) *os.File {
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	go func() {
+		w.Write(data)
+		w.Close()
+	}()
+	t.Cleanup(func() { r.Close() })
+	return r
+}
+
+func TestOpenTranscribeInput(t *testing.T) {
+	data := []byte("ID3\x04\x00rest of the file")
+	reader, name, err := openTranscribeInput("-", "", pipeWith(t, data))
+	require.NoError(t, err)
+	assert.Equal(t, "stdin.mp3", name)
+	// 判断格式读取的文件头不丢失
+	content, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Equal(t, data, content)
+
+	_, _, err = openTranscribeInput("-", "", pipeWith(t, []byte("plain text")))
+	assert.Error(t, err)
+
+	_, name, err = openTranscribeInput("-", ".MKV", pipeWith(t, []byte("plain text")))
+	require.NoError(t, err)
+	assert.Equal(t, "stdin.mkv", name)
+
+	path := filepath.Join(t.TempDir(), "talk.bin")
+	require.NoError(t, os.WriteFile(path, data, 0644))
+	reader, name, err = openTranscribeInput(path, "mp3", nil)
+	require.NoError(t, err)
+	reader.(io.Closer).Close()
+	assert.Equal(t, "talk.mp3", name)
+
+	_, _, err = openTranscribeInput(filepath.Join(t.TempDir(), "missing.mp3"), "", nil)
+	assert.Error(t, err)
+}
+
+func TestTranscribeRejectsUnknownFormat(t *testing.T) {
+	root := newRootCommand()
+	root.SetArgs([]string{"transcribe", "--format", "wav", "-"})
+	err := root.Execute()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "不支持的格式")
+}
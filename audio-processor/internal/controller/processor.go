@@ -3,7 +3,7 @@ package controller
 import (
 	"context"
 	"fmt"
-	"io/ioutil"
+	"net/http"
 	"os"
 	"os/signal"
 	"path/filepath"
@@ -18,8 +18,20 @@ import (
 	"github.com/ccp-p/asr-media-cli/audio-processor/internal/watcher"
 	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/asr"
 	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/audio"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/audit"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/export"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/llm"
 	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/models"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/notify"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/schedule"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/search"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/selfcheck"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/store"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/tempdir"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/usage"
 	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/utils"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/webauth"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/ytdlp"
 )
 
 // ProcessorController 处理器控制器，协调各个组件工作
@@ -50,6 +62,7 @@ type ProcessorController struct {
     }
     
     // 资源管理
+    TempManager   *tempdir.Manager
     TempDir       string
     cleanup       []func() // 清理函数列表
     mu            sync.Mutex
@@ -59,48 +72,73 @@ func (pc *ProcessorController) batchProgressCallback(current, total int, filenam
 	if result == nil {
 		fmt.Printf("\n[%d/%d] 开始处理: %s\n", current, total, filename)
 	} else {
-		if result.Success {
+		if result.Success && result.Status != "" {
+			color.Yellow("\n[%d/%d] %s，已跳过识别: %s", current, total, result.Status, filename)
+		} else if result.Success {
 			color.Green("\n[%d/%d] 处理成功: %s", current, total, filename)
 			fmt.Printf("输出文件: %s\n", result.OutputPath)
 			fmt.Printf("处理用时: %s\n", utils.FormatTimeDuration(result.ProcessTime.Seconds()))
 		} else {
-			color.Red("\n[%d/%d] 处理失败: %s - %v", current, total, filename, result.Error)
+			color.Red("\n[%d/%d] 处理失败: %s - [%s] %v", current, total, filename, result.ErrorKind, result.Error)
+			if result.LogPath != "" {
+				fmt.Printf("处理日志: %s\n", result.LogPath)
+			}
 		}
 	}
 }
 
 // NewProcessorController 创建处理器控制器
 func NewProcessorController(configFile string, logLevel string, logFile string) (*ProcessorController, error) {
+    // 初始化日志
+    if err := utils.InitLogger(logLevel, logFile); err != nil {
+        return nil, fmt.Errorf("初始化日志失败: %v", err)
+    }
+    // 加载配置
+    config := models.NewDefaultConfig()
+    if configFile != "" {
+        if err := config.LoadFromFile(configFile); err != nil {
+            utils.Warn("配置加载失败: %v，将使用默认配置", err)
+            config = models.NewDefaultConfig()
+        }
+    }
+    return NewProcessorControllerWithConfig(config, nil)
+}
+
+// NewProcessorControllerWithConfig 使用已加载的配置创建处理器控制器，调用方负责初始化日志。
+// progress 为空时绘制终端进度条
+func NewProcessorControllerWithConfig(config *models.Config, progress *ui.ProgressManager) (*ProcessorController, error) {
     // 创建上下文，支持取消
     ctx, cancel := context.WithCancel(context.Background())
     
     // 初始化控制器
     pc := &ProcessorController{
-        Config:         models.NewDefaultConfig(),
+        Config:         config,
         ctx:            ctx,
         cancelFunc:     cancel,
     }
     
-    // 初始化日志
-    if err := utils.InitLogger(logLevel, logFile); err != nil {
-        return nil, fmt.Errorf("初始化日志失败: %v", err)
-    }
-        // 日志初始化后再创建ProgressManager
-    pc.ProgressManager = ui.NewProgressManager()
-    // 加载配置
-    if configFile != "" {
-        if err := pc.Config.LoadFromFile(configFile); err != nil {
-            utils.Warn("配置加载失败: %v，将使用默认配置", err)
-        }
+    // 日志初始化后再创建ProgressManager
+    pc.ProgressManager = progress
+    if pc.ProgressManager == nil {
+        pc.ProgressManager = ui.NewProgressManager()
     }
+    utils.ConfigureHTTPClient(pc.Config.HTTPClientOptions())
     
-    // 创建临时目录
-    tempDir, err := ioutil.TempDir("", "audio-processor")
+    // 创建临时目录，先清理之前异常退出时遗留的目录
+    tempManager, err := tempdir.FromConfig(pc.Config)
     if err != nil {
-        return nil, fmt.Errorf("创建临时目录失败: %v", err)
+        return nil, err
+    }
+    if _, err := tempManager.RecoverStale(); err != nil {
+        utils.Warn("%v", err)
     }
-    pc.TempDir = tempDir
-    pc.addCleanup(func() { os.RemoveAll(tempDir) })
+    sessionDir, err := tempManager.Allocate("session")
+    if err != nil {
+        return nil, err
+    }
+    pc.TempManager = tempManager
+    pc.TempDir = sessionDir.Dir
+    pc.addCleanup(tempManager.Cleanup)
     
     // 初始化组件
     pc.initComponents()
@@ -113,6 +151,27 @@ func NewProcessorController(configFile string, logLevel string, logFile string)
 
 // 初始化所有组件
 func (pc *ProcessorController) initComponents() {
+    // 记录删除、移动、覆盖等操作
+    audit.SetDefault(audit.NewLogger(pc.Config.AuditLogPath()))
+
+    // 本地使用量统计
+    if pc.Config.UsageStats {
+        usage.SetDefault(usage.NewRecorder(pc.Config.UsageStatsPath()))
+    }
+
+    // 大模型 token 用量写入处理记录数据库，供 stats -llm 与 /metrics 查看
+    if pc.Config.RecordsStore != "json" {
+        if db, err := store.Open(store.Path(pc.Config.OutputFolder)); err != nil {
+            utils.Warn("打开处理记录数据库失败: %v，不保存大模型用量", err)
+        } else {
+            llm.SetUsageRecorder(usage.NewLLMRecorder(db, pc.Config.LLMDailyTokenBudget).Record)
+            pc.addCleanup(func() {
+                llm.SetUsageRecorder(nil)
+                db.Close()
+            })
+        }
+    }
+
     // 初始化批处理器
     pc.BatchProcessor = audio.NewBatchProcessor(
         pc.Config.MediaFolder, 
@@ -122,15 +181,31 @@ func (pc *ProcessorController) initComponents() {
         pc.Config,
     )
     pc.BatchProcessor.SetProgressManager(pc.ProgressManager)
+    pc.BatchProcessor.SetTempManager(pc.TempManager)
     pc.BatchProcessor.SetContext(pc.ctx) // 设置上下文
+    if _, err := pc.BatchProcessor.Trash.Purge(); err != nil {
+        utils.Warn("清理回收目录失败: %v", err)
+    }
     // 初始化ASR选择器
     pc.ASRSelector = asr.NewASRSelector()
     pc.BatchProcessor.SetASRSelector(pc.ASRSelector)
     pc.registerASRServices()
+
+    // 服务健康度持久化，重启后保留熔断状态
+    policy := asr.DefaultHealthPolicy()
+    policy.HalfLife = time.Duration(pc.Config.ASRHealthHalfLife * float64(time.Hour))
+    policy.ProbeInterval = time.Duration(pc.Config.ASRProbeInterval * float64(time.Second))
+    pc.ASRSelector.SetHealthPolicy(policy)
+    pc.ASRSelector.SetCache(asr.CacheFromConfig(pc.Config))
+    if err := pc.ASRSelector.SetStatsFile(filepath.Join(pc.Config.OutputFolder, "asr_service_stats.json")); err != nil {
+        utils.Warn("%v", err)
+    }
+    // 服务响应格式异常时，原始响应保存在处理日志旁，便于排查上游接口变化
+    asr.SetDiagnosticsDir(filepath.Join(pc.Config.OutputFolder, audio.LogDirName, "asr_responses"))
     
     // 启动片段监控
     pc.ProgressManager.CreateProgressBar("segments_monitor", 100, "片段监控", "等待处理开始...")
-    stopMonitoring := watcher.StartSegmentMonitoring(pc.TempDir, pc.ProgressManager)
+    stopMonitoring := watcher.StartSegmentMonitoring(pc.ProgressManager)
     pc.SegmentMonitor = stopMonitoring
     pc.addCleanup(stopMonitoring)
 }
@@ -138,6 +213,9 @@ func (pc *ProcessorController) initComponents() {
 // 处理媒体文件
 func (pc *ProcessorController) ProcessMedia() ([]audio.BatchResult, error) {
     pc.Stats.StartTime = time.Now()
+
+    // 归档之前运行中识别完成且已过安全延迟的文件
+    pc.BatchProcessor.ArchivePending()
     
     // 处理所有文件
     results, err := pc.BatchProcessor.ProcessVideoFiles()
@@ -151,21 +229,156 @@ func (pc *ProcessorController) ProcessMedia() ([]audio.BatchResult, error) {
     return results, nil
 }
 
+// ProcessFiles 处理命令行中指定的文件，不扫描媒体目录
+func (pc *ProcessorController) ProcessFiles(paths []string) []audio.BatchResult {
+    pc.Stats.StartTime = time.Now()
+    results := pc.BatchProcessor.ProcessFiles(paths)
+    pc.updateStats(results)
+    return results
+}
+
+// ProcessURL 用 yt-dlp 下载链接中的音频到媒体目录，再按普通媒体文件处理
+func (pc *ProcessorController) ProcessURL(sourceURL string) ([]audio.BatchResult, error) {
+    pc.Stats.StartTime = time.Now()
+    if err := os.MkdirAll(pc.Config.MediaFolder, 0755); err != nil {
+        return nil, fmt.Errorf("创建媒体目录失败: %w", err)
+    }
+
+    // 先下载到媒体目录下的临时目录，完成后再移入，避免半成品被当作媒体文件
+    dir, err := os.MkdirTemp(pc.Config.MediaFolder, ".download-")
+    if err != nil {
+        return nil, fmt.Errorf("创建下载目录失败: %w", err)
+    }
+    defer os.RemoveAll(dir)
+
+    downloader := ytdlp.New(pc.Config.YtDlpPath, pc.Config.YtDlpFormat, pc.Config.YtDlpArgs)
+    if !downloader.Available() {
+        return nil, fmt.Errorf("未找到 %s，请先安装 yt-dlp 或在配置中设置 yt_dlp_path", downloader.Program)
+    }
+    pc.ProgressManager.CreateProgressBar("download", 100, "下载音频", "")
+    downloader.Progress = func(percent float64, message string) {
+        pc.ProgressManager.UpdateProgressBar("download", int(percent), message)
+    }
+    utils.Info("正在下载: %s", sourceURL)
+    downloaded, err := downloader.Download(pc.ctx, sourceURL, dir)
+    if err != nil {
+        pc.ProgressManager.CompleteProgressBar("download", "下载失败")
+        return nil, err
+    }
+    pc.ProgressManager.CompleteProgressBar("download", "下载完成")
+
+    mediaPath := filepath.Join(pc.Config.MediaFolder, filepath.Base(downloaded))
+    if err := os.Rename(downloaded, mediaPath); err != nil {
+        return nil, fmt.Errorf("移动下载的文件失败: %w", err)
+    }
+    utils.Info("已下载到 %s", mediaPath)
+
+    results := []audio.BatchResult{pc.BatchProcessor.ProcessSingleFile(mediaPath)}
+    pc.updateStats(results)
+    return results, nil
+}
+
 func (pc *ProcessorController) StartWatchMode() error {
+    // 每个监控目录一个批处理器与监控器，未配置 watch_profiles 时只监控 media_folder
+    targets := []watchTarget{{config: pc.Config, processor: pc.BatchProcessor}}
+    if len(pc.Config.WatchProfiles) > 0 {
+        targets = targets[:0]
+        for _, profile := range pc.Config.WatchProfiles {
+            config, err := pc.Config.ProfileConfig(profile)
+            if err != nil {
+                return fmt.Errorf("监控目录 %s 的配置无效: %w", profile.Name, err)
+            }
+            targets = append(targets, watchTarget{name: profile.Name, config: config, processor: pc.newProfileProcessor(config)})
+        }
+    }
+    for i := range targets {
+        if err := pc.startMediaMonitor(&targets[i]); err != nil {
+            return err
+        }
+    }
+
+    // 启动队列状态接口
+    if pc.Config.WatchStatusAddr != "" {
+        if err := pc.startWatchStatusServer(targets); err != nil {
+            return err
+        }
+    }
+
+    // 长时间运行，定期自检并按需开启 pprof
+    pc.StartDiagnostics()
+
+    for _, target := range targets {
+        // 定期归档识别完成且已过安全延迟的文件
+        if target.config.ArchiveMode != "" && target.config.ArchiveMode != audio.ArchiveOff {
+            go pc.runArchiveLoop(target.processor)
+        }
+
+        // 首选ASR服务恢复后重新识别由备用服务识别的文件
+        if target.config.AutoReprocess {
+            go pc.runReprocessLoop(target.processor, time.Duration(target.config.ReprocessInterval*float64(time.Minute)))
+        }
+    }
+    
+    utils.Info("监控已启动，按Ctrl+C退出...")
+    
+    // 等待终止信号
+    return pc.waitForTermination()
+}
+
+// watchTarget 监听模式下的一个监控目录
+type watchTarget struct {
+    name      string // watch_profiles 中的名称，只监控 media_folder 时为空
+    config    *models.Config
+    processor *audio.BatchProcessor
+    monitor   *watcher.FolderMonitor
+}
+
+// apiPrefix 返回监控目录的监听接口路径前缀
+func (t watchTarget) apiPrefix() string {
+    if t.name == "" {
+        return "/api/"
+    }
+    return "/api/" + t.name + "/"
+}
+
+// newProfileProcessor 为监控目录创建批处理器，识别服务、进度显示、临时目录与上下文和全局批处理器共用
+func (pc *ProcessorController) newProfileProcessor(config *models.Config) *audio.BatchProcessor {
+    processor := audio.NewBatchProcessor(
+        config.MediaFolder,
+        config.OutputFolder,
+        pc.TempDir,
+        pc.batchProgressCallback,
+        config,
+    )
+    processor.SetProgressManager(pc.ProgressManager)
+    processor.SetTempManager(pc.TempManager)
+    processor.SetContext(pc.ctx)
+    processor.SetASRSelector(pc.ASRSelector)
+    processor.MaxConcurrency = pc.BatchProcessor.MaxConcurrency
+    return processor
+}
+
+// startMediaMonitor 按监控目录的配置启动媒体目录与下载目录的监控
+func (pc *ProcessorController) startMediaMonitor(target *watchTarget) error {
+    config, processor := target.config, target.processor
+
     // 确保目录存在
-    os.MkdirAll(pc.Config.OutputFolder, 0755)
-    os.MkdirAll(pc.Config.MediaFolder, 0755)
+    os.MkdirAll(config.OutputFolder, 0755)
+    os.MkdirAll(config.MediaFolder, 0755)
+    if target.name != "" {
+        utils.Info("监控目录 %s: %s -> %s", target.name, config.MediaFolder, config.OutputFolder)
+    }
     
     // 创建处理器适配器，并添加文件重命名处理
-    processorAdapter := adapters.NewBatchProcessorAdapter(pc.BatchProcessor)
+    processorAdapter := adapters.NewBatchProcessorAdapter(processor)
     processorAdapter.SetRenameHandler(func(oldPath, newPath string) {
-        pc.BatchProcessor.UpdateProcessedRecordOnRename(oldPath, newPath)
+        processor.UpdateProcessedRecordOnRename(oldPath, newPath)
     })
     
     // 监控下载目录
     stopDownloadMonitor, err := watcher.StartFolderMonitoring(
-        pc.Config.OutputFolder, 
-        pc.Config.MediaFolder,
+        config.OutputFolder, 
+        config.MediaFolder,
     )
     if err != nil {
         return err
@@ -173,34 +386,163 @@ func (pc *ProcessorController) StartWatchMode() error {
     pc.addCleanup(stopDownloadMonitor)
     
     // 监控媒体目录
-    stopMediaMonitor, err := watcher.StartMediaFolderMonitoring(
-        pc.Config.MediaFolder, 
+    mediaMonitor, err := watcher.NewMediaFolderMonitor(
+        config.MediaFolder, 
         processorAdapter, 
         pc.ProgressManager,
     )
     if err != nil {
-        return err
+        return fmt.Errorf("创建媒体文件夹监控器失败: %w", err)
+    }
+    mediaMonitor.SetRetryPolicy(
+        config.WatchMaxAttempts,
+        time.Duration(config.WatchRetryDelay*float64(time.Second)),
+        config.QuarantineFolder,
+    )
+    mediaMonitor.SetQueueReportInterval(time.Duration(config.WatchQueueInterval * float64(time.Second)))
+    mediaMonitor.SetScope(config.WatchRecursive, config.WatchInclude, config.WatchExclude)
+    mediaMonitor.SetPolling(config.WatchPoll, time.Duration(config.WatchPollInterval*float64(time.Second)))
+    mediaMonitor.SetStabilityPolicy(watcher.StabilityPolicy{
+        Checks:    config.WatchStableChecks,
+        Interval:  time.Duration(config.WatchStableInterval * float64(time.Second)),
+        Timeout:   time.Duration(config.WatchStableTimeout * float64(time.Second)),
+        Exclusive: config.WatchExclusiveCheck,
+    })
+    mediaMonitor.SetSuccessAction(watcher.SuccessAction{
+        Mode:   config.WatchAfterSuccess,
+        Dir:    config.WatchDoneFolder,
+        Prefix: config.WatchDonePrefix,
+        Trash:  processor.Trash,
+    })
+    mediaMonitor.SetNotifier(notify.FromConfig(config))
+    window, err := schedule.Parse(config.WatchSchedule)
+    if err != nil {
+        return fmt.Errorf("处理时间窗口无效: %w", err)
+    }
+    if window != nil {
+        utils.Info("只在以下时间窗口内处理文件: %s", window)
+    }
+    mediaMonitor.SetSchedule(window)
+    // 未处理完的文件写入输出目录，重启后继续处理
+    if err := mediaMonitor.SetQueueFile(filepath.Join(config.OutputFolder, watcher.QueueFileName)); err != nil {
+        utils.Warn("%v，重启后只处理扫描到的未处理文件", err)
+    }
+    processor.SetFileProgressCallback(mediaMonitor.UpdateProgress)
+    if err := mediaMonitor.Start(); err != nil {
+        return fmt.Errorf("启动媒体文件夹监控器失败: %w", err)
+    }
+    pc.addCleanup(mediaMonitor.Stop)
+    target.monitor = mediaMonitor
+    return nil
+}
+
+// 监听模式下检查待归档文件的间隔
+const archiveCheckInterval = 10 * time.Minute
+
+// runArchiveLoop 定期检查并归档到期的文件
+func (pc *ProcessorController) runArchiveLoop(processor *audio.BatchProcessor) {
+    ticker := time.NewTicker(archiveCheckInterval)
+    defer ticker.Stop()
+    for {
+        processor.ArchivePending()
+        select {
+        case <-pc.ctx.Done():
+            return
+        case <-ticker.C:
+        }
+    }
+}
+
+// runReprocessLoop 定期检查首选ASR服务是否可用，可用时重新识别备用服务的结果
+func (pc *ProcessorController) runReprocessLoop(processor *audio.BatchProcessor, interval time.Duration) {
+    ticker := time.NewTicker(interval)
+    defer ticker.Stop()
+    for {
+        select {
+        case <-pc.ctx.Done():
+            return
+        case <-ticker.C:
+            processor.ReprocessFallbacks()
+        }
+    }
+}
+
+// startWatchStatusServer 启动监听模式HTTP接口：查询队列状态、手动加入文件、暂停/恢复处理与调整并发数。
+// 配置了 watch_profiles 时各监控目录的接口位于 /api/<名称>/ 下。
+// 配置了 web_auth_mode 时全部接口与Web服务一样需要携带凭据
+func (pc *ProcessorController) startWatchStatusServer(targets []watchTarget) error {
+    auth, err := webauth.FromConfig(pc.Config)
+    if err != nil {
+        return fmt.Errorf("初始化监听接口认证失败: %w", err)
+    }
+    if auth == nil {
+        utils.Warn("监听接口未启用认证（web_auth_mode），请勿将 watch_status_addr 暴露到本机以外")
+    }
+
+    mux := http.NewServeMux()
+    for _, target := range targets {
+        prefix := target.apiPrefix()
+        config := target.config
+        mux.Handle(prefix+"queue", target.monitor.QueueHandler())
+        mux.Handle(prefix+"enqueue", target.monitor.EnqueueHandler())
+        mux.Handle(prefix+"logs/", audio.LogHandler(config.OutputFolder, prefix+"logs/"))
+        mux.Handle(prefix+"outputs/", export.ManifestHandler(config.OutputFolder, config.MediaFolder, prefix+"outputs/"))
+        mux.Handle(prefix+"tags/", export.TagsHandler(config, prefix+"tags/"))
+        mux.Handle(prefix+"search", search.Handler(config.OutputFolder, config.MediaFolder))
+        mux.Handle(prefix+"batch", audio.BatchControlHandler(target.processor))
+        mux.Handle(prefix+"schedule", target.monitor.ScheduleHandler())
+    }
+
+    server := &http.Server{
+        Addr:    pc.Config.WatchStatusAddr,
+        Handler: auth.Middleware(mux),
+    }
+    go func() {
+        if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+            utils.Error("监听接口启动失败: %v", err)
+        }
+    }()
+    pc.addCleanup(func() {
+        ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+        defer cancel()
+        server.Shutdown(ctx)
+    })
+
+    for _, target := range targets {
+        prefix := target.apiPrefix()
+        utils.Info("监听接口已启动: http://%s%squeue (GET), %senqueue (POST), %slogs/<文件> (GET), %soutputs/[<文件>[/<格式>]] (GET), %stags/[<文件>] (GET/POST), %ssearch?q=<查询词> (GET), %sbatch (GET/POST), %sschedule (GET/POST)",
+            pc.Config.WatchStatusAddr, prefix, prefix, prefix, prefix, prefix, prefix, prefix, prefix)
+    }
+    return nil
+}
+
+// StartDiagnostics 启动长时间运行模式的自检：定期记录 goroutine 数与堆内存，
+// 配置了 debug_addr 时同时启动 pprof 接口。监听模式与Web服务启动时调用
+func (pc *ProcessorController) StartDiagnostics() {
+    monitor := selfcheck.FromConfig(pc.Config)
+    if monitor != nil {
+        go monitor.Run(pc.ctx)
+    }
+    if pc.Config.DebugAddr != "" {
+        pc.addCleanup(selfcheck.StartDebugServer(pc.Config.DebugAddr, monitor))
     }
-    pc.addCleanup(stopMediaMonitor)
-    
-    utils.Info("监控已启动，按Ctrl+C退出...")
-    
-    // 等待终止信号
-    return pc.waitForTermination()
 }
 
 func (pc *ProcessorController) RunASRService(results []audio.BatchResult) {
     // 对每个成功处理的文件进行ASR识别
     for _, result := range results {
-        if !result.Success {
-            continue // 跳过处理失败的文件
+        if !result.Success || result.Status != "" {
+            continue // 跳过处理失败或已跳过识别（无音频、音乐）的文件
         }
 
         // 获取处理后的文件路径
         audioPath := result.OutputPath
         
         // 执行识别
-        ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+        if pc.ctx.Err() != nil {
+            break
+        }
+        ctx, cancel := context.WithTimeout(pc.ctx, 5*time.Minute)
         defer cancel()
         
         utils.Info("开始识别文件: %s", audioPath)
@@ -212,7 +554,7 @@ func (pc *ProcessorController) RunASRService(results []audio.BatchResult) {
 		}
 
         segments, serviceName, outputFiles, err := pc.ASRSelector.RunWithService(
-            ctx, audioPath, pc.Config.ASRService, false, pc.Config, progressCallback)
+            ctx, audioPath, pc.Config.ASRService, pc.Config.ASRCache, pc.Config, progressCallback)
         
         if err != nil {
             utils.Error("识别失败: %v", err)
@@ -276,21 +618,9 @@ func (pc *ProcessorController) Cleanup() {
     utils.DisableTerminalProgress()
 }
 
-// 注册ASR服务
+// 注册ASR服务，启用状态、权重、超时与重试次数来自配置中的 asr_services
 func (pc *ProcessorController) registerASRServices() {
-    // pc.ASRSelector.RegisterService("kuaishou", 
-    //     func(audioPath string, useCache bool) (asr.ASRService, error) {
-    //         return asr.NewKuaiShouASR(audioPath, useCache)
-    //     }, 
-    //     10,
-    // )
-    
-    pc.ASRSelector.RegisterService("bcut", 
-        func(audioPath string, useCache bool) (asr.ASRService, error) {
-            return asr.NewBcutASR(audioPath, useCache)
-        }, 
-        30,
-    )
+    pc.ASRSelector.RegisterFromConfig(pc.Config, asr.DefaultCreators())
 }
 
 // 设置中断处理
@@ -301,7 +631,7 @@ func (pc *ProcessorController) setupSignalHandlers() {
     go func() {
         <-c
         utils.Info("接收到中断信号，正在停止...")
-        pc.cancelFunc() // 取消上下文
+        pc.cancelFunc() // 取消上下文，终止正在执行的 ffmpeg 与上传并清理未完成的输出
     }()
 }
 
@@ -333,7 +663,7 @@ func (pc *ProcessorController) ProcessAudioWithASR(audioPath string) error {
     utils.Info("开始对文件进行语音识别: %s", filepath.Base(audioPath))
     
     // 创建进度条ID
-    barID := "asr_" + filepath.Base(audioPath)
+    barID := "asr_" + audio.FileTag(audioPath)
     pc.ProgressManager.CreateProgressBar(barID, 100, "ASR识别 "+filepath.Base(audioPath), "准备中...")
     
     // 进度回调
@@ -350,7 +680,7 @@ func (pc *ProcessorController) ProcessAudioWithASR(audioPath string) error {
         ctx, 
         audioPath, 
         pc.Config.ASRService, 
-        false,
+        pc.Config.ASRCache,
         pc.Config,
         progressCallback,
     )
@@ -0,0 +1,110 @@
+// Package integration 端到端集成测试的支撑代码：不访问网络的模拟ASR服务与固定的识别结果。
+// 测试本身使用 integration 构建标签，需要 ffmpeg 与 ffprobe，运行方式：
+//
+//	go test -tags integration ./internal/integration/
+package integration
+
+import (
+	"context"
+	"sync"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/asr"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/models"
+)
+
+// MockServiceName 模拟ASR服务注册到选择器时使用的名称
+const MockServiceName = "mock"
+
+// MockASR 模拟ASR服务，对任何音频都返回同一组段落，并记录被识别过的音频
+type MockASR struct {
+	Segments []models.DataSegment
+
+	mu    sync.Mutex
+	calls []string
+}
+
+// NewMockASR 创建返回 segments 的模拟ASR服务
+func NewMockASR(segments []models.DataSegment) *MockASR {
+	return &MockASR{Segments: segments}
+}
+
+// Creator 返回可注册到 ASRSelector 的创建函数
+func (m *MockASR) Creator() asr.ServiceCreator {
+	return func(audioPath string, useCache bool) (asr.ASRService, error) {
+		m.mu.Lock()
+		m.calls = append(m.calls, audioPath)
+		m.mu.Unlock()
+		return &mockService{segments: m.Segments}, nil
+	}
+}
+
+// Calls 返回已识别的音频路径
+func (m *MockASR) Calls() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]string(nil), m.calls...)
+}
+
+// mockService 一次识别请求
+type mockService struct {
+	segments []models.DataSegment
+}
+
+// GetResult 返回段落的副本，避免导出过程中的修改影响后续请求
+func (s *mockService) GetResult(ctx context.Context, callback asr.ProgressCallback) ([]models.DataSegment, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if callback != nil {
+		callback(100, "模拟识别完成")
+	}
+	result := make([]models.DataSegment, len(s.segments))
+	for i, segment := range s.segments {
+		result[i] = segment
+		result[i].Words = append([]models.WordTiming(nil), segment.Words...)
+	}
+	return result, nil
+}
+
+// ScriptedSegments 固定的识别结果：两位说话人，带逐字时间戳，段落间有长短不一的停顿，可划分出多个章节
+func ScriptedSegments() []models.DataSegment {
+	lines := []struct {
+		text       string
+		start, end float64
+		speaker    string
+	}{
+		{"大家好，欢迎收听本期节目。", 0.2, 2.0, "A"},
+		{"今天我们聊聊语音识别。", 2.2, 3.8, "A"},
+		{"第一部分介绍基本原理。", 5.5, 7.0, "B"},
+		{"第二部分讨论字幕导出。", 9.0, 10.6, "A"},
+		{"感谢收听，下期再见。", 10.8, 11.8, "B"},
+	}
+	segments := make([]models.DataSegment, 0, len(lines))
+	for _, line := range lines {
+		segments = append(segments, models.DataSegment{
+			Text:       line.text,
+			StartTime:  line.start,
+			EndTime:    line.end,
+			Confidence: 0.95,
+			Speaker:    line.speaker,
+			Words:      charTimings(line.text, line.start, line.end),
+		})
+	}
+	return segments
+}
+
+// charTimings 将段落时长平均分配给每个字
+func charTimings(text string, start, end float64) []models.WordTiming {
+	runes := []rune(text)
+	step := (end - start) / float64(len(runes))
+	words := make([]models.WordTiming, len(runes))
+	for i, r := range runes {
+		words[i] = models.WordTiming{
+			Text:       string(r),
+			StartTime:  start + float64(i)*step,
+			EndTime:    start + float64(i+1)*step,
+			Confidence: 0.95,
+		}
+	}
+	return words
+}
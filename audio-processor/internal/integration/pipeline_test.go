@@ -0,0 +1,237 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/analysis"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/asr"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/audio"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/export"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/models"
+)
+
+// requireFFmpeg 没有 ffmpeg/ffprobe 或设置了 SKIP_FFMPEG_TESTS=1 时跳过测试
+func requireFFmpeg(t *testing.T) {
+	t.Helper()
+	if os.Getenv("SKIP_FFMPEG_TESTS") == "1" {
+		t.Skip("跳过需要ffmpeg的测试")
+	}
+	for _, tool := range []string{"ffmpeg", "ffprobe"} {
+		if _, err := exec.LookPath(tool); err != nil {
+			t.Skipf("未找到 %s，跳过集成测试", tool)
+		}
+	}
+}
+
+// generateVideo 用 ffmpeg 生成 seconds 秒的小视频：160x120 黑色画面与 440Hz 正弦音。
+// 只使用 ffmpeg 内置的编码器，不依赖 libx264
+func generateVideo(t *testing.T, path string, seconds int) {
+	t.Helper()
+	duration := strconv.Itoa(seconds)
+	cmd := exec.Command("ffmpeg", "-hide_banner", "-loglevel", "error", "-y",
+		"-f", "lavfi", "-i", "sine=frequency=440:duration="+duration,
+		"-f", "lavfi", "-i", "color=c=black:s=160x120:d="+duration,
+		"-shortest", "-c:v", "mpeg4", "-c:a", "aac", path)
+	output, err := cmd.CombinedOutput()
+	require.NoError(t, err, "生成测试视频失败: %s", output)
+}
+
+// pipelineConfig 启用全部导出格式的配置，不配置大模型，识别结果不缓存
+func pipelineConfig(t *testing.T, mediaDir, outputDir, tempDir string) *models.Config {
+	t.Helper()
+	t.Setenv("VOLCES_API_KEY", "")
+
+	config := models.NewDefaultConfig()
+	config.MediaFolder = mediaDir
+	config.OutputFolder = outputDir
+	config.TempDir = tempDir
+	config.ASRService = MockServiceName
+	config.ASRCache = false
+	config.ShowProgress = false
+	// 正弦音可能被判定为音乐，不做检测
+	config.MusicGate = "off"
+	config.MaxWorkers = 1
+	config.ExportSRT = true
+	config.ExportJSON = true
+	config.ExportMD = true
+	config.ExportChapters = true
+	config.ChapterMinLength = 2
+	config.ExportAnalysis = true
+	config.ExportLRC = true
+	config.ExportASS = true
+	config.ExportDOCX = true
+	config.ExportPDF = true
+	config.ExportVTT = true
+	config.ExportPlayer = true
+	config.SubtitlePreset = export.PresetJianying
+	config.BatchManifest = true
+	require.NoError(t, config.Validate())
+	return config
+}
+
+// readOutput 读取清单中某个格式的输出文件
+func readOutput(t *testing.T, config *models.Config, manifest *export.OutputManifest, fileType string) string {
+	t.Helper()
+	entry, ok := manifest.Output(fileType)
+	require.True(t, ok, "清单中缺少 %s 输出", fileType)
+	data, err := os.ReadFile(entry.Resolve(config.OutputFolder, config.MediaFolder))
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(data)), entry.Size, "%s 的大小与清单不一致", fileType)
+	return string(data)
+}
+
+// TestPipelineAllExporters 生成视频，经过提取音频、模拟识别与全部导出器，检查各个产物的内容
+func TestPipelineAllExporters(t *testing.T) {
+	requireFFmpeg(t)
+
+	root := t.TempDir()
+	mediaDir := filepath.Join(root, "media")
+	outputDir := filepath.Join(root, "output")
+	tempDir := filepath.Join(root, "temp")
+	for _, dir := range []string{mediaDir, outputDir, tempDir} {
+		require.NoError(t, os.MkdirAll(dir, 0755))
+	}
+	mediaPath := filepath.Join(mediaDir, "lecture.mp4")
+	generateVideo(t, mediaPath, 12)
+
+	config := pipelineConfig(t, mediaDir, outputDir, tempDir)
+	mock := NewMockASR(ScriptedSegments())
+	selector := asr.NewASRSelector()
+	selector.RegisterService(MockServiceName, mock.Creator(), 1)
+
+	processor := audio.NewBatchProcessor(mediaDir, outputDir, tempDir, nil, config)
+	processor.SetASRSelector(selector)
+	processor.SetContext(context.Background())
+
+	results, err := processor.ProcessVideoFiles()
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	result := results[0]
+	require.True(t, result.Success, "处理失败: %v", result.Error)
+	assert.Equal(t, MockServiceName, result.Service)
+	assert.Len(t, mock.Calls(), 1, "模拟ASR服务应只被调用一次")
+
+	// 输出清单列出全部格式
+	manifest, err := export.LoadManifest(export.ManifestPath(outputDir, mediaPath))
+	require.NoError(t, err)
+	assert.Equal(t, "lecture", manifest.Name)
+	for _, fileType := range []string{"txt", "md", "srt", "jianying", "chapters", "analysis", "json", "lrc", "ass", "docx", "pdf", "vtt", "player"} {
+		_, ok := manifest.Output(fileType)
+		assert.True(t, ok, "清单中缺少 %s 输出", fileType)
+	}
+
+	t.Run("txt", func(t *testing.T) {
+		text := readOutput(t, config, manifest, "txt")
+		assert.Contains(t, text, "大家好，欢迎收听本期节目")
+		assert.Contains(t, text, "感谢收听，下期再见")
+	})
+
+	t.Run("srt", func(t *testing.T) {
+		srt := readOutput(t, config, manifest, "srt")
+		assert.True(t, strings.HasPrefix(srt, "1\n00:00:00,200 --> "), "SRT 开头不正确: %q", srt)
+		assert.Contains(t, srt, "第二部分讨论字幕导出")
+	})
+
+	t.Run("vtt", func(t *testing.T) {
+		vtt := readOutput(t, config, manifest, "vtt")
+		assert.True(t, strings.HasPrefix(vtt, "WEBVTT"))
+		assert.Contains(t, vtt, "00:00:05.500 --> ")
+	})
+
+	t.Run("lrc", func(t *testing.T) {
+		assert.Contains(t, readOutput(t, config, manifest, "lrc"), "[00:05.50]")
+	})
+
+	t.Run("ass", func(t *testing.T) {
+		ass := readOutput(t, config, manifest, "ass")
+		assert.Contains(t, ass, "[Script Info]")
+		assert.Contains(t, ass, "Dialogue:")
+	})
+
+	t.Run("json", func(t *testing.T) {
+		var transcript export.TranscriptResult
+		require.NoError(t, json.Unmarshal([]byte(readOutput(t, config, manifest, "json")), &transcript))
+		assert.Equal(t, export.JSONSchemaVersion, transcript.SchemaVersion)
+		require.Len(t, transcript.Segments, len(ScriptedSegments()))
+		assert.Equal(t, "A", transcript.Segments[0].Speaker)
+		require.Len(t, transcript.Chapters, 3)
+		assert.Equal(t, 0.0, transcript.Chapters[0].Start)
+		assert.Equal(t, 5.5, transcript.Chapters[1].Start)
+		assert.Equal(t, "第二部分讨论字幕导出", transcript.Chapters[2].Title)
+		require.NotNil(t, transcript.Analysis)
+		assert.Equal(t, analysis.MethodTFIDF, transcript.Analysis.Method)
+		assert.Contains(t, transcript.Analysis.Keywords, "收听")
+	})
+
+	t.Run("analysis index", func(t *testing.T) {
+		index, err := analysis.LoadIndex(audio.AnalysisIndexPath(outputDir))
+		require.NoError(t, err)
+		assert.Equal(t, mediaPath, index.Files["lecture"].Source)
+		assert.Equal(t, []string{"lecture"}, index.Search("收听"))
+	})
+
+	t.Run("chapters", func(t *testing.T) {
+		assert.Equal(t, "0:00 大家好\n0:05 第一部分介绍基本原理\n0:09 第二部分讨论字幕导出\n",
+			readOutput(t, config, manifest, "chapters"))
+	})
+
+	t.Run("jianying", func(t *testing.T) {
+		var draft struct {
+			Tracks []struct {
+				Type     string            `json:"type"`
+				Segments []json.RawMessage `json:"segments"`
+			} `json:"tracks"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(readOutput(t, config, manifest, "jianying")), &draft))
+		require.Len(t, draft.Tracks, 1)
+		assert.Equal(t, "text", draft.Tracks[0].Type)
+		assert.NotEmpty(t, draft.Tracks[0].Segments)
+	})
+
+	t.Run("documents", func(t *testing.T) {
+		assert.True(t, strings.HasPrefix(readOutput(t, config, manifest, "docx"), "PK"), "DOCX 应为 zip 文件")
+		assert.True(t, strings.HasPrefix(readOutput(t, config, manifest, "pdf"), "%PDF-"))
+	})
+
+	t.Run("player", func(t *testing.T) {
+		player := readOutput(t, config, manifest, "player")
+		assert.Contains(t, player, "<video")
+	})
+
+	t.Run("md with tags", func(t *testing.T) {
+		md := readOutput(t, config, manifest, "md")
+		assert.Contains(t, md, "感谢收听，下期再见")
+
+		_, err := export.OpenTagStore(outputDir).Update(mediaPath, export.MetaUpdate{Add: []string{"demo"}})
+		require.NoError(t, err)
+		require.NoError(t, export.SyncFrontmatter(config, mediaPath))
+
+		// 重新读取清单，Markdown 的校验和随 YAML 头更新
+		manifest, err := export.LoadManifest(export.ManifestPath(outputDir, mediaPath))
+		require.NoError(t, err)
+		md = readOutput(t, config, manifest, "md")
+		assert.True(t, strings.HasPrefix(md, "---\n"), "Markdown 应以 YAML 头开始: %q", md)
+		assert.Contains(t, md, `"demo"`)
+		assert.Contains(t, md, "感谢收听，下期再见")
+	})
+
+	t.Run("batch manifest", func(t *testing.T) {
+		data, err := os.ReadFile(filepath.Join(outputDir, audio.BatchManifestDir, "latest.json"))
+		require.NoError(t, err)
+		assert.Contains(t, string(data), "lecture.mp4")
+	})
+
+	assert.True(t, processor.IsRecognizedFile(mediaPath), "处理完成后应记录为已识别")
+}
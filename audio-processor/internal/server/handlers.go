@@ -0,0 +1,159 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/ask"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/audio"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/utils"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/webauth"
+)
+
+// homeHandler 返回上传页面
+func (s *Server) homeHandler(w http.ResponseWriter, r *http.Request) {
+	http.ServeFile(w, r, filepath.Join(s.options.WebRoot, "index.html"))
+}
+
+// uploadHandler 上传后同步处理，处理完成后返回结果
+func (s *Server) uploadHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	// 解析表单
+	if err := r.ParseMultipartForm(32 << 20); err != nil { // 32MB
+		sendErrorResponse(w, "无法解析表单", http.StatusBadRequest)
+		return
+	}
+
+	// 获取上传的文件
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		sendErrorResponse(w, "获取上传文件失败", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	utils.Info("接收到文件上传: %s, 大小: %d bytes", header.Filename, header.Size)
+
+	result, err := s.Web.ForUser(webauth.User(r.Context())).ProcessUploadedFile(file, header.Filename)
+	if err != nil {
+		sendErrorResponse(w, fmt.Sprintf("处理文件失败: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(result)
+}
+
+// summarizeHandler 为文稿生成摘要，Accept: text/event-stream 或 ?stream=1 时以 SSE 逐段返回
+func (s *Server) summarizeHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	// 检查API客户端是否配置
+	if s.summarizer == nil {
+		sendErrorResponse(w, "未配置API密钥，无法使用总结功能", http.StatusServiceUnavailable)
+		return
+	}
+
+	var request struct {
+		Text string `json:"text"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		sendErrorResponse(w, "解析请求失败", http.StatusBadRequest)
+		return
+	}
+	if request.Text == "" {
+		sendErrorResponse(w, "文本内容为空", http.StatusBadRequest)
+		return
+	}
+
+	// 长文稿等待完整回复容易超时，页面请求流式返回时边生成边推送
+	if audio.WantsSummaryStream(r) {
+		if _, err := audio.StreamSummary(w, r, s.summarizer, request.Text, nil); err != nil {
+			utils.Error("生成总结失败: %v", err)
+		}
+		return
+	}
+
+	summary, err := s.summarizer.GenerateSummary(request.Text)
+	if err != nil {
+		utils.Error("生成总结失败: %v", err)
+		sendErrorResponse(w, fmt.Sprintf("生成总结失败: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{
+		"summary": summary,
+	})
+}
+
+// askHandler 针对文稿提问：请求体 {"transcript": "<名称>", "question": "..."}，名称见 GET /api/transcripts。
+// 返回回答、回答中引用的片段与检索到的片段
+func (s *Server) askHandler(w http.ResponseWriter, r *http.Request) {
+	if s.asker == nil {
+		sendErrorResponse(w, "未配置API密钥，无法使用文稿问答功能", http.StatusServiceUnavailable)
+		return
+	}
+
+	var request struct {
+		Transcript string `json:"transcript"`
+		Question   string `json:"question"`
+	}
+	if err := json.NewDecoder(io.LimitReader(r.Body, 1<<20)).Decode(&request); err != nil {
+		sendErrorResponse(w, "解析请求失败", http.StatusBadRequest)
+		return
+	}
+	if request.Transcript == "" || strings.TrimSpace(request.Question) == "" {
+		sendErrorResponse(w, "需要 transcript 与 question", http.StatusBadRequest)
+		return
+	}
+
+	web := s.Web.ForUser(webauth.User(r.Context()))
+	detail, err := web.Transcript(request.Transcript)
+	if errors.Is(err, audio.ErrTranscriptNotFound) {
+		sendErrorResponse(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		sendErrorResponse(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	segments := make([]ask.Segment, len(detail.Segments))
+	for i, segment := range detail.Segments {
+		segments[i] = ask.Segment{Start: segment.Start, End: segment.End, Text: segment.Text, Speaker: segment.Speaker}
+	}
+
+	var cache ask.Cache
+	if db := web.Processor.Store(); db != nil {
+		cache = db
+	}
+	answer, err := s.asker.Ask(r.Context(), cache, detail.Name, segments, request.Question)
+	if err != nil {
+		utils.Error("回答 %s 的问题失败: %v", detail.Name, err)
+		sendErrorResponse(w, fmt.Sprintf("回答问题失败: %v", err), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(answer)
+}
+
+// healthCheckHandler 健康检查
+func healthCheckHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]bool{"status": true})
+}
+
+// sendErrorResponse 以 {"error": "..."} 返回错误
+func sendErrorResponse(w http.ResponseWriter, message string, statusCode int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(map[string]string{
+		"error": message,
+	})
+}
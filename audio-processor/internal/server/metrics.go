@@ -0,0 +1,50 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/llm"
+)
+
+// llmMetrics 大模型用量指标：名称、说明与取值
+var llmMetrics = []struct {
+	name  string
+	help  string
+	value func(llm.Usage) int
+}{
+	{"audioproc_llm_requests_total", "大模型请求数", func(u llm.Usage) int { return u.Requests }},
+	{"audioproc_llm_prompt_tokens_total", "大模型输入 token 数", func(u llm.Usage) int { return u.PromptTokens }},
+	{"audioproc_llm_completion_tokens_total", "大模型输出 token 数", func(u llm.Usage) int { return u.CompletionTokens }},
+}
+
+// metricsHandler 以 Prometheus 文本格式返回进程启动以来各服务商与模型的大模型用量与可用状态，
+// 按天与按任务的历史用量见 `asr stats --llm`
+func metricsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	totals := llm.UsageTotals()
+	for _, metric := range llmMetrics {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n", metric.name, metric.help, metric.name)
+		for _, total := range totals {
+			fmt.Fprintf(w, "%s{provider=\"%s\",model=\"%s\"} %d\n",
+				metric.name, labelValue(total.Provider), labelValue(total.Model), metric.value(total.Usage))
+		}
+	}
+
+	// 配置了备用大模型时各服务商是否可用（未熔断）
+	fmt.Fprintf(w, "# HELP audioproc_llm_provider_up 大模型服务商是否可用\n# TYPE audioproc_llm_provider_up gauge\n")
+	for _, health := range llm.ProvidersHealth() {
+		up := 0
+		if health.Available {
+			up = 1
+		}
+		fmt.Fprintf(w, "audioproc_llm_provider_up{provider=\"%s\",model=\"%s\"} %d\n",
+			labelValue(health.Provider), labelValue(health.Model), up)
+	}
+}
+
+// labelValue 转义 Prometheus 标签值中的反斜杠、引号与换行
+func labelValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
@@ -0,0 +1,77 @@
+package server
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/utils"
+)
+
+// 单次保存的请求体上限
+const maxSaveBody = 64 << 20
+
+// saveSeparator 追加在每块数据之后，便于阅读
+const saveSeparator = "\n\n<!-- === 数据块结束 === -->\n\n"
+
+// corsMiddleware 添加基本的 CORS 响应头，允许所有来源的 POST 请求，并直接响应预检请求
+func corsMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+		if r.Method == http.MethodOptions {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// saveHandler 将 POST 的请求体（页面 DOM）追加到 path，空请求体不写入
+func saveHandler(path string) http.Handler {
+	var mu sync.Mutex
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "只允许 POST 方法", http.StatusMethodNotAllowed)
+			return
+		}
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxSaveBody))
+		if err != nil {
+			utils.Warn("读取保存请求失败: %v", err)
+			http.Error(w, "读取请求体失败", http.StatusBadRequest)
+			return
+		}
+		if len(body) == 0 {
+			fmt.Fprintln(w, "收到空数据。")
+			return
+		}
+
+		// 多个请求同时追加时保持数据块完整
+		mu.Lock()
+		err = appendFile(path, body)
+		mu.Unlock()
+		if err != nil {
+			utils.Error("保存数据到 %s 失败: %v", path, err)
+			http.Error(w, "写入文件失败", http.StatusInternalServerError)
+			return
+		}
+		utils.Info("成功追加 %d 字节到 %s", len(body), path)
+		fmt.Fprintln(w, "数据接收并保存成功。")
+	})
+}
+
+// appendFile 将数据与分隔符追加到文件末尾，文件不存在时创建
+func appendFile(path string, data []byte) error {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("打开文件失败: %w", err)
+	}
+	if _, err := file.Write(append(data, saveSeparator...)); err != nil {
+		file.Close()
+		return fmt.Errorf("写入文件失败: %w", err)
+	}
+	return file.Close()
+}
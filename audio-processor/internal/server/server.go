@@ -0,0 +1,354 @@
+// Package server 组装Web服务：上传页面与任务接口、识别结果与备注接口、页面保存接口与监听模式
+// 按功能开关组合，audio_web、asr serve 与 webserver 共用同一套路由、中间件与优雅停止流程
+package server
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/internal/controller"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/ask"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/audio"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/export"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/llm"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/search"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/summary"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/utils"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/webauth"
+	"github.com/gorilla/mux"
+)
+
+// 可启用的功能
+const (
+	FeatureUI    = "ui"    // 上传页面、异步任务、分块上传、文件下载与摘要接口
+	FeatureNotes = "notes" // 识别结果库、标签与备注、全文搜索接口
+	FeatureSave  = "save"  // 页面 DOM 保存接口 /save，供浏览器脚本调用
+	FeatureWatch = "watch" // 同时运行媒体目录监听模式（状态接口见配置 watch_status_addr）
+)
+
+// AllFeatures 全部功能，按启动顺序排列
+var AllFeatures = []string{FeatureUI, FeatureNotes, FeatureSave, FeatureWatch}
+
+// Options 服务的地址、目录与启用的功能
+type Options struct {
+	Addr         string
+	Features     []string
+	UploadDir    string
+	TempDir      string
+	OutputDir    string
+	WebRoot      string // 页面目录，包含 index.html 与 static
+	SaveFile     string // save 功能追加写入的文件
+	VolcesAPIKey string // 火山方舟API密钥，覆盖配置中的 volces_api_key
+}
+
+// ParseFeatures 解析逗号分隔的功能列表，"all" 表示全部
+func ParseFeatures(value string) ([]string, error) {
+	var features []string
+	seen := make(map[string]bool)
+	for _, name := range strings.Split(value, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" || seen[name] {
+			continue
+		}
+		if name == "all" {
+			return append([]string(nil), AllFeatures...), nil
+		}
+		known := false
+		for _, feature := range AllFeatures {
+			known = known || feature == name
+		}
+		if !known {
+			return nil, fmt.Errorf("未知的功能: %s（可选 %s 或 all）", name, strings.Join(AllFeatures, "、"))
+		}
+		seen[name] = true
+		features = append(features, name)
+	}
+	if len(features) == 0 {
+		return nil, fmt.Errorf("至少需要启用一个功能")
+	}
+	return features, nil
+}
+
+// Server 按功能组合的Web服务
+type Server struct {
+	Controller *controller.ProcessorController // save 以外的功能需要
+	Web        *audio.WebProcessor             // ui 或 notes 启用时创建
+	Jobs       *audio.WebJobQueue              // ui 启用时创建
+	Uploads    *audio.UploadStore              // ui 启用时创建
+
+	options          Options
+	features         map[string]bool
+	auth             *webauth.Authenticator
+	summarizer       *summary.Summarizer // 长文稿分段摘要后合并
+	asker            *ask.Asker          // 文稿问答，未配置大模型时为 nil
+	cancelProcessing context.CancelFunc
+}
+
+// New 按选项创建服务，只启用 save 时 pc 可以为 nil
+func New(pc *controller.ProcessorController, options Options) (*Server, error) {
+	s := &Server{Controller: pc, options: options, features: make(map[string]bool)}
+	for _, feature := range options.Features {
+		s.features[feature] = true
+	}
+	if s.options.WebRoot == "" {
+		s.options.WebRoot = "./web"
+	}
+	if s.options.SaveFile == "" {
+		s.options.SaveFile = "saved_dom_data.html"
+	}
+	if !s.Enabled(FeatureUI) && !s.Enabled(FeatureNotes) && !s.Enabled(FeatureWatch) {
+		return s, nil
+	}
+	if pc == nil {
+		return nil, fmt.Errorf("%s 以外的功能需要处理器控制器", FeatureSave)
+	}
+
+	var err error
+	if s.Enabled(FeatureUI) || s.Enabled(FeatureNotes) {
+		// 启用认证后每个用户只能看到自己的上传、任务与输出
+		s.auth, err = webauth.FromConfig(pc.Config)
+		if err != nil {
+			return nil, fmt.Errorf("初始化接口认证失败: %w", err)
+		}
+		if s.auth == nil {
+			utils.Warn("未启用接口认证（web_auth_mode），请勿将服务暴露到本机以外")
+		} else {
+			utils.Info("已启用接口认证: %s", pc.Config.WebAuthMode)
+		}
+
+		for _, dir := range []string{options.UploadDir, options.TempDir, options.OutputDir} {
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				return nil, fmt.Errorf("创建目录失败 %s: %w", dir, err)
+			}
+		}
+		s.Web = audio.NewWebProcessor(options.UploadDir, options.TempDir, options.OutputDir, pc.Config)
+		s.Web.Processor.SetASRSelector(pc.ASRSelector)
+		// 任务使用单独的上下文：停止服务时先等待任务完成，超时后才取消
+		var processCtx context.Context
+		processCtx, s.cancelProcessing = context.WithCancel(context.Background())
+		s.Web.Processor.SetContext(processCtx)
+	}
+
+	if s.Enabled(FeatureUI) {
+		// 上传后立即返回任务ID，由工作协程在后台处理
+		s.Jobs, err = audio.NewWebJobQueue(s.Web, filepath.Join(s.Web.Config.OutputFolder, audio.WebJobsFileName))
+		if err != nil {
+			return nil, fmt.Errorf("初始化任务队列失败: %w", err)
+		}
+		s.Uploads = audio.NewUploadStore(s.Web)
+		// 命令行提供的火山方舟密钥优先于配置
+		if options.VolcesAPIKey != "" {
+			pc.Config.VolcesAPIKey = options.VolcesAPIKey
+		}
+		client, err := llm.FromConfig(pc.Config)
+		if err != nil {
+			return nil, fmt.Errorf("初始化大模型客户端失败: %w", err)
+		}
+		if client != nil {
+			s.summarizer = summary.New(client, summary.OptionsFromConfig(pc.Config))
+			utils.Info("已初始化大模型客户端: %s", client.Name())
+			embedder, err := llm.EmbedderFromConfig(pc.Config)
+			if err != nil {
+				return nil, fmt.Errorf("初始化向量模型失败: %w", err)
+			}
+			if embedder != nil {
+				utils.Info("文稿问答使用向量模型 %s 检索", embedder.Name())
+			}
+			s.asker = ask.New(client, embedder, ask.OptionsFromConfig(pc.Config))
+		} else {
+			utils.Warn("未配置大模型API密钥（llm.api_key），意见总结与文稿问答功能将不可用")
+		}
+	}
+	return s, nil
+}
+
+// Enabled 功能是否启用
+func (s *Server) Enabled(feature string) bool {
+	return s.features[feature]
+}
+
+// Handler 返回已启用功能的路由
+func (s *Server) Handler() http.Handler {
+	router := mux.NewRouter()
+	router.HandleFunc("/health", healthCheckHandler).Methods("GET")
+	// 大模型用量指标，只包含各模型的累计数，不含文件名
+	router.HandleFunc("/metrics", metricsHandler).Methods("GET")
+
+	// 页面、健康检查与指标不需要认证，其余接口经过认证中间件
+	auth := s.auth.Middleware
+
+	if s.Enabled(FeatureUI) {
+		// 静态文件服务
+		static := filepath.Join(s.options.WebRoot, "static")
+		router.PathPrefix("/static/").Handler(http.StripPrefix("/static/", http.FileServer(http.Dir(static))))
+		router.HandleFunc("/", s.homeHandler).Methods("GET")
+		// 上传后同步等待处理完成，保留给旧客户端，新客户端使用 /api/jobs
+		router.Handle("/upload", auth(http.HandlerFunc(s.uploadHandler))).Methods("POST")
+		// 异步任务：POST 上传并提交，GET /api/jobs/{id} 查询状态与结果，/api/jobs/{id}/events 推送处理进度
+		router.PathPrefix("/api/jobs").Handler(auth(audio.WebJobsHandler(s.Jobs, "/api/jobs")))
+		// 任务输出文件下载，文件ID 见 GET /api/jobs/{id}/files
+		router.PathPrefix("/api/files").Handler(auth(audio.WebFilesHandler(s.Jobs, "/api/files")))
+		// 大文件分块上传，断线后按已接收的偏移继续，上传完成后提交为任务
+		router.PathPrefix("/api/uploads").Handler(auth(audio.UploadsHandler(s.Uploads, s.Jobs, "/api/uploads")))
+		router.Handle("/api/summarize", auth(http.HandlerFunc(s.summarizeHandler))).Methods("POST")
+		// 已识别文稿的列表与段落，按保存的文稿重新生成摘要
+		var summarizer audio.Summarizer
+		if s.summarizer != nil {
+			summarizer = s.summarizer
+		}
+		router.PathPrefix("/api/transcripts").Handler(auth(audio.TranscriptsHandler(s.Web, summarizer, "/api/transcripts")))
+		// 针对单个文稿提问，回答注明依据的时间
+		router.Handle("/api/ask", auth(http.HandlerFunc(s.askHandler))).Methods("POST")
+	}
+
+	if s.Enabled(FeatureNotes) {
+		// 识别结果库与下载，按各文件的输出清单返回，启用认证时只包含当前用户的输出
+		router.PathPrefix("/api/outputs/").Handler(auth(webauth.PerUser(func(user string) http.Handler {
+			config := s.Web.ForUser(user).Config
+			return export.ManifestHandler(config.OutputFolder, config.MediaFolder, "/api/outputs/")
+		})))
+		// 文件的标签与备注
+		router.PathPrefix("/api/tags/").Handler(auth(webauth.PerUser(func(user string) http.Handler {
+			return export.TagsHandler(s.Web.ForUser(user).Config, "/api/tags/")
+		})))
+		// 全部文稿的全文搜索
+		router.Handle("/api/search", auth(webauth.PerUser(func(user string) http.Handler {
+			config := s.Web.ForUser(user).Config
+			return search.Handler(config.OutputFolder, config.MediaFolder)
+		}))).Methods("GET")
+	}
+
+	if s.Enabled(FeatureSave) {
+		// 浏览器脚本跨域提交，不经过认证
+		router.Handle("/save", corsMiddleware(saveHandler(s.options.SaveFile)))
+	}
+	return router
+}
+
+// Run 启动已启用的功能并监听，ctx 结束后优雅停止：不再接受新连接并等待进行中的请求，
+// 停止领取任务并等待正在处理的任务，超过 web_shutdown_timeout 后中止任务（下次启动时重新处理）。
+// 停止过程中再次收到停止信号时立即退出
+func (s *Server) Run(ctx context.Context) error {
+	if s.Jobs != nil {
+		s.Jobs.Start(s.Web.Processor.MaxConcurrency)
+	}
+	if s.Web != nil {
+		go s.cleanupLoop(ctx)
+	}
+	watchDone := make(chan struct{})
+	if s.Enabled(FeatureWatch) {
+		// 监听模式在控制器的上下文结束（收到停止信号）时返回
+		go func() {
+			defer close(watchDone)
+			if err := s.Controller.StartWatchMode(); err != nil {
+				utils.Error("监控模式运行失败: %v", err)
+			}
+		}()
+	} else {
+		close(watchDone)
+		if s.Controller != nil {
+			// 长时间运行，定期自检并按需开启 pprof（监听模式自行启动）
+			s.Controller.StartDiagnostics()
+		}
+	}
+
+	// 请求的上下文在停止服务时取消，进度推送（SSE）等长连接随之结束
+	baseCtx, cancelRequests := context.WithCancel(context.Background())
+	server := &http.Server{
+		Addr:         s.options.Addr,
+		Handler:      s.Handler(),
+		ReadTimeout:  15 * time.Minute,
+		WriteTimeout: 15 * time.Minute,
+		BaseContext:  func(net.Listener) context.Context { return baseCtx },
+	}
+	server.RegisterOnShutdown(cancelRequests)
+
+	utils.Info("启动Web服务器，监听地址: %s，功能: %s", s.options.Addr, strings.Join(s.options.Features, ", "))
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- server.ListenAndServe()
+	}()
+	select {
+	case err := <-serverErr:
+		if s.cancelProcessing != nil {
+			s.cancelProcessing()
+		}
+		return fmt.Errorf("启动服务器失败: %w", err)
+	case <-ctx.Done():
+	}
+
+	s.shutdown(server)
+	<-watchDone
+	return nil
+}
+
+// shutdown 按 web_shutdown_timeout 等待请求与任务完成，超时后中止任务
+func (s *Server) shutdown(server *http.Server) {
+	timeout := 10 * time.Second
+	if s.Controller != nil {
+		timeout = time.Duration(s.Controller.Config.WebShutdownTimeout * float64(time.Second))
+	}
+	utils.Info("收到停止信号，正在停止服务（最多等待 %s）...", timeout)
+	go func() {
+		c := make(chan os.Signal, 1)
+		signal.Notify(c, os.Interrupt, syscall.SIGTERM)
+		<-c
+		utils.Warn("再次收到停止信号，立即退出")
+		os.Exit(1)
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		utils.Warn("等待请求完成超时: %v", err)
+	}
+	if s.Jobs != nil {
+		if err := s.Jobs.Shutdown(ctx); err != nil {
+			utils.Warn("等待任务完成超时，中止正在处理的任务，下次启动时重新处理")
+			s.cancelProcessing()
+			s.Jobs.Close()
+		}
+	}
+	if s.cancelProcessing != nil {
+		s.cancelProcessing()
+	}
+	utils.Info("服务已停止")
+}
+
+// cleanupLoop 每隔 retention_interval 小时按保留策略清理文件与未完成的分块上传，直到 ctx 结束。
+// 间隔为 0 时不定期清理，可使用 `asr cleanup` 手动清理
+func (s *Server) cleanupLoop(ctx context.Context) {
+	interval := time.Duration(s.Web.Config.RetentionInterval * float64(time.Hour))
+	if interval <= 0 {
+		utils.Info("未设置 retention_interval，不定期清理过期文件")
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		utils.Info("开始清理过期文件...")
+		if err := s.Web.CleanupOldFiles(); err != nil {
+			utils.Error("清理文件失败: %v", err)
+		}
+		if s.Uploads != nil {
+			expiry := time.Duration(s.Web.Config.WebUploadExpiry * float64(time.Hour))
+			if _, err := s.Uploads.Cleanup(expiry); err != nil {
+				utils.Error("清理分块上传失败: %v", err)
+			}
+		}
+	}
+}
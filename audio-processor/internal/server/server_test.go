@@ -0,0 +1,65 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFeatures(t *testing.T) {
+	features, err := ParseFeatures(" UI, notes,ui ,")
+	require.NoError(t, err)
+	assert.Equal(t, []string{FeatureUI, FeatureNotes}, features)
+
+	features, err = ParseFeatures("save,all")
+	require.NoError(t, err)
+	assert.Equal(t, AllFeatures, features)
+
+	_, err = ParseFeatures("ui,api")
+	assert.Error(t, err)
+	_, err = ParseFeatures(" , ")
+	assert.Error(t, err)
+}
+
+func TestNewRequiresController(t *testing.T) {
+	_, err := New(nil, Options{Features: []string{FeatureSave, FeatureUI}})
+	assert.Error(t, err)
+}
+
+// TestSaveOnly 测试只启用 save 时其他接口不注册，/save 追加写入文件
+func TestSaveOnly(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "saved.html")
+	srv, err := New(nil, Options{Features: []string{FeatureSave}, SaveFile: path})
+	require.NoError(t, err)
+	handler := srv.Handler()
+
+	serve := func(method, target, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(method, target, strings.NewReader(body)))
+		return rec
+	}
+
+	assert.Equal(t, http.StatusOK, serve(http.MethodGet, "/health", "").Code)
+	assert.Contains(t, serve(http.MethodGet, "/metrics", "").Body.String(), "# TYPE audioproc_llm_requests_total counter")
+	assert.Equal(t, http.StatusNotFound, serve(http.MethodGet, "/api/jobs", "").Code, "未启用 ui")
+	assert.Equal(t, http.StatusNotFound, serve(http.MethodGet, "/api/search?q=a", "").Code, "未启用 notes")
+
+	rec := serve(http.MethodOptions, "/save", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "*", rec.Header().Get("Access-Control-Allow-Origin"))
+
+	assert.Equal(t, http.StatusOK, serve(http.MethodPost, "/save", "<div>1</div>").Code)
+	assert.Equal(t, http.StatusOK, serve(http.MethodPost, "/save", "").Code)
+	assert.Equal(t, http.StatusOK, serve(http.MethodPost, "/save", "<div>2</div>").Code)
+	assert.Equal(t, http.StatusMethodNotAllowed, serve(http.MethodGet, "/save", "").Code)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "<div>1</div>"+saveSeparator+"<div>2</div>"+saveSeparator, string(data))
+}
@@ -3,16 +3,47 @@ package ui
 import (
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/utils"
 )
 
-// ProgressManager 管理多个进度条
+// 订阅通道的缓冲大小
+const subscriberBuffer = 32
+
+// ProgressEvent 单个文件处理进度的一次通知，ID 为源文件路径
+type ProgressEvent struct {
+	ID      string    `json:"id"`
+	Stage   string    `json:"stage,omitempty"` // 当前处理阶段，如 extract、asr
+	Percent int       `json:"percent"`
+	Message string    `json:"message,omitempty"`
+	Done    bool      `json:"done,omitempty"` // 文件处理已结束
+	Error   string    `json:"error,omitempty"` // 结束时的失败原因
+
+	Outputs map[string]string `json:"outputs,omitempty"` // 结束时的输出文件（格式 -> 路径）
+	Time    time.Time         `json:"time"`
+}
+
+// progressSubscriber 进度订阅者，id 为空时接收全部文件的进度
+type progressSubscriber struct {
+	id string
+	ch chan ProgressEvent
+}
+
+// ProgressManager 管理多个进度条，并向订阅者（如Web接口）发送文件处理进度
 type ProgressManager struct {
 	progressBars map[string]*ProgressBar
 	mutex        sync.Mutex
 	enabled      bool
 	terminal     *TerminalManager
+
+	subMutex    sync.Mutex
+	subscribers map[int]*progressSubscriber
+	nextSub     int
+
+	// sink 不为空时进度条与文件进度发送给 sink，不绘制终端进度条
+	sink       ProgressSink
+	fileStarts map[string]time.Time // 文件第一次上报进度的时间，用于估计剩余时间
 }
 
 // 在初始化时启用终端进度条模式
@@ -24,6 +55,125 @@ func NewProgressManager() *ProgressManager {
 		progressBars: make(map[string]*ProgressBar),
 		terminal:     GetTerminalManager(),
 		enabled:      true,
+		subscribers:  make(map[int]*progressSubscriber),
+	}
+}
+
+// NewHeadlessProgressManager 创建不绘制终端进度条的进度管理器，只向订阅者发送进度，用于Web服务
+func NewHeadlessProgressManager() *ProgressManager {
+	return &ProgressManager{
+		progressBars: make(map[string]*ProgressBar),
+		subscribers:  make(map[int]*progressSubscriber),
+	}
+}
+
+// NewSinkProgressManager 创建将进度发送给 sink 的进度管理器，不绘制终端进度条，
+// 如 --progress=json 时输出 JSON 进度事件
+func NewSinkProgressManager(sink ProgressSink) *ProgressManager {
+	return &ProgressManager{
+		progressBars: make(map[string]*ProgressBar),
+		subscribers:  make(map[int]*progressSubscriber),
+		sink:         sink,
+		fileStarts:   make(map[string]time.Time),
+	}
+}
+
+// emitFile 将文件进度发送给 sink，调用方需持有 subMutex
+func (pm *ProgressManager) emitFile(event ProgressEvent) {
+	start, ok := pm.fileStarts[event.ID]
+	if !ok {
+		start = event.Time
+		pm.fileStarts[event.ID] = start
+	}
+	if event.Done {
+		delete(pm.fileStarts, event.ID)
+	}
+	pm.sink.Emit(ProgressUpdate{
+		Type:    UpdateFile,
+		File:    event.ID,
+		Stage:   event.Stage,
+		Percent: event.Percent,
+		ETA:     estimateRemaining(start, event.Percent, event.Time),
+		Message: event.Message,
+		Done:    event.Done,
+		Error:   event.Error,
+		Outputs: event.Outputs,
+		Time:    event.Time,
+	})
+}
+
+// emitTask 将进度条的状态发送给 sink
+func (pm *ProgressManager) emitTask(id string, bar *ProgressBar, done bool) {
+	percent := 100
+	if bar.Total > 0 && !done {
+		percent = bar.Current * 100 / bar.Total
+	}
+	now := time.Now()
+	pm.sink.Emit(ProgressUpdate{
+		Type:    UpdateTask,
+		Task:    id,
+		Title:   bar.Prefix,
+		Percent: percent,
+		ETA:     estimateRemaining(bar.StartTime, percent, now),
+		Message: bar.Suffix,
+		Done:    done,
+		Time:    now,
+	})
+}
+
+// Subscribe 订阅 id 的处理进度（id 为空时订阅全部文件），返回接收通道与取消订阅的函数。
+// 通道缓冲已满时丢弃最早的一条，订阅者只应依赖最新的进度
+func (pm *ProgressManager) Subscribe(id string) (<-chan ProgressEvent, func()) {
+	pm.subMutex.Lock()
+	defer pm.subMutex.Unlock()
+
+	if pm.subscribers == nil {
+		pm.subscribers = make(map[int]*progressSubscriber)
+	}
+	key := pm.nextSub
+	pm.nextSub++
+	sub := &progressSubscriber{id: id, ch: make(chan ProgressEvent, subscriberBuffer)}
+	pm.subscribers[key] = sub
+
+	var once sync.Once
+	return sub.ch, func() {
+		once.Do(func() {
+			pm.subMutex.Lock()
+			delete(pm.subscribers, key)
+			close(sub.ch)
+			pm.subMutex.Unlock()
+		})
+	}
+}
+
+// Publish 向订阅了该文件的订阅者发送进度，不影响终端进度条
+func (pm *ProgressManager) Publish(event ProgressEvent) {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	pm.subMutex.Lock()
+	defer pm.subMutex.Unlock()
+
+	if pm.sink != nil {
+		pm.emitFile(event)
+	}
+	for _, sub := range pm.subscribers {
+		if sub.id != "" && sub.id != event.ID {
+			continue
+		}
+		select {
+		case sub.ch <- event:
+		default:
+			// 缓冲已满，丢弃最早的进度保留最新的
+			select {
+			case <-sub.ch:
+			default:
+			}
+			select {
+			case sub.ch <- event:
+			default:
+			}
+		}
 	}
 }
 
@@ -32,6 +182,13 @@ func (pm *ProgressManager) CreateProgressBar(id string, total int, prefix string
 	pm.mutex.Lock()
 	defer pm.mutex.Unlock()
 
+	if pm.sink != nil {
+		bar := NewProgressBar(total, prefix, suffix)
+		pm.progressBars[id] = bar
+		pm.emitTask(id, bar, false)
+		return bar
+	}
+
 	// 如果已经存在同名进度条，先完成它
 	if bar, exists := pm.progressBars[id]; exists {
 		bar.Complete("已被替换")
@@ -57,13 +214,33 @@ func (pm *ProgressManager) GetProgressBar(id string) *ProgressBar {
 
 // 在 UpdateProgressBar 方法中使用终端管理器
 func (pm *ProgressManager) UpdateProgressBar(id string, progress int, message string) {
+	if pm.sink != nil {
+		pm.mutex.Lock()
+		defer pm.mutex.Unlock()
+		bar, exists := pm.progressBars[id]
+		if !exists {
+			bar = NewProgressBar(100, "", message)
+			pm.progressBars[id] = bar
+		}
+		// 只记录进度，不绘制
+		bar.Current = min(max(progress, 0), bar.Total)
+		if message != "" {
+			bar.Suffix = message
+		}
+		pm.emitTask(id, bar, false)
+		return
+	}
+	if !pm.enabled {
+		return
+	}
+
 	pm.mutex.Lock()
 	defer pm.mutex.Unlock()
 
-	// 使用终端管理器更新进度
+	// 使用终端管理器更新进度，已持有锁，不能调用 CreateProgressBar
 	bar, exists := pm.progressBars[id]
 	if !exists {
-		bar = pm.CreateProgressBar(id, 100, "", message)
+		bar = NewProgressBar(100, "", message)
 		pm.progressBars[id] = bar
 	}
 
@@ -72,9 +249,21 @@ func (pm *ProgressManager) UpdateProgressBar(id string, progress int, message st
 	pm.terminal.UpdateProgress(fmt.Sprintf("%s | %s", bar.String(), message))
 }
 
-// CompleteProgressBar 完成进度条
 // CompleteProgressBar 完成进度条
 func (pm *ProgressManager) CompleteProgressBar(id string, suffix string) {
+    if pm.sink != nil {
+        pm.mutex.Lock()
+        bar, exists := pm.progressBars[id]
+        delete(pm.progressBars, id)
+        pm.mutex.Unlock()
+        if exists {
+            if suffix != "" {
+                bar.Suffix = suffix
+            }
+            pm.emitTask(id, bar, true)
+        }
+        return
+    }
     if !pm.enabled {
         return
     }
@@ -0,0 +1,66 @@
+package ui
+
+import (
+	"encoding/json"
+	"io"
+	"math"
+	"sync"
+	"time"
+)
+
+// 进度更新的类型
+const (
+	UpdateFile = "file" // 单个文件的处理进度
+	UpdateTask = "task" // 进度条，如总体进度、下载
+)
+
+// ProgressUpdate 发送给 ProgressSink 的一次进度更新
+type ProgressUpdate struct {
+	Type    string            `json:"type"`              // file 或 task
+	File    string            `json:"file,omitempty"`    // 源文件路径，仅 file
+	Task    string            `json:"task,omitempty"`    // 进度条ID，仅 task
+	Title   string            `json:"title,omitempty"`   // 进度条标题，仅 task
+	Stage   string            `json:"stage,omitempty"`   // 当前处理阶段，如 extract、asr
+	Percent int               `json:"percent"`           // 0-100
+	ETA     float64           `json:"eta,omitempty"`     // 预计剩余秒数，无法估计时省略
+	Message string            `json:"message,omitempty"` // 进度说明
+	Done    bool              `json:"done,omitempty"`    // 已结束
+	Error   string            `json:"error,omitempty"`   // 文件处理失败的原因
+	Outputs map[string]string `json:"outputs,omitempty"` // 文件处理完成后的输出文件（格式 -> 路径）
+	Time    time.Time         `json:"time"`
+}
+
+// ProgressSink 接收进度更新，设置后取代终端进度条。需要支持并发调用
+type ProgressSink interface {
+	Emit(update ProgressUpdate)
+}
+
+// JSONProgressSink 将进度更新写为换行分隔的 JSON，供包装命令行的其他界面读取
+type JSONProgressSink struct {
+	mu      sync.Mutex
+	encoder *json.Encoder
+}
+
+// NewJSONProgressSink 创建写入 w 的 JSON 进度输出
+func NewJSONProgressSink(w io.Writer) *JSONProgressSink {
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	return &JSONProgressSink{encoder: encoder}
+}
+
+// Emit 写入一行进度，写入失败时忽略
+func (s *JSONProgressSink) Emit(update ProgressUpdate) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.encoder.Encode(update)
+}
+
+// estimateRemaining 按开始以来的用时与进度估计剩余秒数，保留一位小数。进度为 0 或已完成时返回 0
+func estimateRemaining(start time.Time, percent int, now time.Time) float64 {
+	if percent <= 0 || percent >= 100 || start.IsZero() {
+		return 0
+	}
+	elapsed := now.Sub(start).Seconds()
+	remaining := elapsed * float64(100-percent) / float64(percent)
+	return math.Round(remaining*10) / 10
+}
@@ -2,6 +2,7 @@ package ui
 
 import (
 	"bytes"
+	"encoding/json"
 	"io"
 	"os"
 	"strings"
@@ -163,3 +164,102 @@ func TestDrawWithTimers(t *testing.T) {
 		t.Error("进度条输出中未包含时间信息")
 	}
 }
+
+func TestProgressManagerSubscribe(t *testing.T) {
+	pm := NewHeadlessProgressManager()
+	all, cancelAll := pm.Subscribe("")
+	one, cancelOne := pm.Subscribe("a.mp4")
+
+	// 不绘制终端进度条，未创建的进度条也可以更新
+	output := captureOutput(func() {
+		pm.UpdateProgressBar("file_a", 50, "提取音频")
+	})
+	if output != "" {
+		t.Errorf("无终端模式不应输出进度条: %q", output)
+	}
+
+	pm.Publish(ProgressEvent{ID: "a.mp4", Stage: "asr", Percent: 40, Message: "语音识别"})
+	pm.Publish(ProgressEvent{ID: "b.mp4", Percent: 10})
+
+	event := <-one
+	if event.ID != "a.mp4" || event.Stage != "asr" || event.Percent != 40 || event.Time.IsZero() {
+		t.Errorf("订阅单个文件收到的进度不正确: %+v", event)
+	}
+	if len(one) != 0 {
+		t.Errorf("不应收到其他文件的进度")
+	}
+	if first, second := <-all, <-all; first.ID != "a.mp4" || second.ID != "b.mp4" {
+		t.Errorf("订阅全部文件应按顺序收到进度: %s, %s", first.ID, second.ID)
+	}
+
+	// 缓冲已满时保留最新的进度
+	for i := 0; i <= subscriberBuffer*2; i++ {
+		pm.Publish(ProgressEvent{ID: "a.mp4", Percent: i})
+	}
+	var last ProgressEvent
+	for len(one) > 0 {
+		last = <-one
+	}
+	if last.Percent != subscriberBuffer*2 {
+		t.Errorf("最后收到的进度应为最新的一次: %d", last.Percent)
+	}
+
+	cancelOne()
+	cancelOne()
+	if _, ok := <-one; ok {
+		t.Errorf("取消订阅后通道应关闭")
+	}
+	for len(all) > 0 {
+		<-all
+	}
+	pm.Publish(ProgressEvent{ID: "a.mp4", Done: true})
+	if event := <-all; !event.Done {
+		t.Errorf("其他订阅者应继续收到进度")
+	}
+	cancelAll()
+}
+
+func TestJSONProgressSink(t *testing.T) {
+	var buf bytes.Buffer
+	pm := NewSinkProgressManager(NewJSONProgressSink(&buf))
+
+	start := time.Now()
+	output := captureOutput(func() {
+		pm.CreateProgressBar("batch_overall", 4, "总体进度", "0/4")
+		pm.UpdateProgressBar("batch_overall", 1, "1/4")
+		pm.CompleteProgressBar("batch_overall", "完成")
+		pm.Publish(ProgressEvent{ID: "a.mp4", Stage: "extract", Percent: 0, Time: start})
+		pm.Publish(ProgressEvent{ID: "a.mp4", Stage: "asr", Percent: 25, Time: start.Add(10 * time.Second)})
+		pm.Publish(ProgressEvent{ID: "a.mp4", Percent: 100, Done: true, Outputs: map[string]string{"srt": "a.srt"}})
+	})
+	if output != "" {
+		t.Errorf("输出到 sink 时不应绘制终端进度条: %q", output)
+	}
+
+	var updates []ProgressUpdate
+	decoder := json.NewDecoder(&buf)
+	for decoder.More() {
+		var update ProgressUpdate
+		if err := decoder.Decode(&update); err != nil {
+			t.Fatalf("进度应为每行一个 JSON: %v", err)
+		}
+		updates = append(updates, update)
+	}
+	if len(updates) != 6 {
+		t.Fatalf("应输出 6 条进度，实际 %d 条", len(updates))
+	}
+
+	if u := updates[1]; u.Type != UpdateTask || u.Task != "batch_overall" || u.Title != "总体进度" || u.Percent != 25 || u.Message != "1/4" {
+		t.Errorf("进度条更新不正确: %+v", u)
+	}
+	if u := updates[2]; !u.Done || u.Percent != 100 || u.Message != "完成" {
+		t.Errorf("进度条完成不正确: %+v", u)
+	}
+	// 用时 10 秒完成 25%，预计还需 30 秒
+	if u := updates[4]; u.Type != UpdateFile || u.File != "a.mp4" || u.Stage != "asr" || u.ETA != 30 {
+		t.Errorf("文件进度不正确: %+v", u)
+	}
+	if u := updates[5]; !u.Done || u.ETA != 0 || u.Outputs["srt"] != "a.srt" {
+		t.Errorf("文件完成不正确: %+v", u)
+	}
+}
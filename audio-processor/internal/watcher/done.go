@@ -0,0 +1,106 @@
+package watcher
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/trash"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/utils"
+)
+
+// watch_after_success 取值
+const (
+	AfterSuccessKeep   = "keep"   // 保留原文件
+	AfterSuccessMove   = "move"   // 移入归档目录
+	AfterSuccessRename = "rename" // 文件名添加前缀，留在原目录
+	AfterSuccessDelete = "delete" // 按 trash_mode 删除
+)
+
+// SuccessAction 处理成功后对原媒体文件的操作
+type SuccessAction struct {
+	Mode   string       // AfterSuccess* 之一，为空时保留原文件
+	Dir    string       // move 的目标目录，为空时使用监控目录下的 archived
+	Prefix string       // rename 添加的前缀
+	Trash  *trash.Trash // delete 使用的回收站，nil 时直接删除
+}
+
+// recordRenamer 移动原文件后同步更新处理记录，由 adapters.BatchProcessorAdapter 实现
+type recordRenamer interface {
+	HandleRename(oldPath, newPath string)
+}
+
+// SetSuccessAction 设置处理成功后对原文件的操作，应在 Start 之前调用
+func (m *FolderMonitor) SetSuccessAction(action SuccessAction) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if action.Dir == "" {
+		action.Dir = filepath.Join(m.folderPath, "archived")
+	}
+	m.success = action
+}
+
+// afterSuccess 按 SuccessAction 移动、重命名或删除处理成功的原文件，返回写入队列记录的说明，保留原文件时为空。
+// 不在监控目录或监控范围内的文件只记录日志，不做任何操作
+func (m *FolderMonitor) afterSuccess(path string) (string, error) {
+	m.mutex.Lock()
+	action := m.success
+	m.mutex.Unlock()
+
+	if action.Mode == "" || action.Mode == AfterSuccessKeep {
+		return "", nil
+	}
+	// 只操作监控目录内、监控范围内的文件，其余文件（如通过其他途径处理的文件）保持不动
+	if !m.scope.includeFile(filepath.Clean(path)) {
+		utils.Warn("文件不在监控范围内，跳过处理成功后的操作: %s", path)
+		return "不在监控范围内，已保留原文件", nil
+	}
+
+	switch action.Mode {
+	case AfterSuccessMove:
+		if err := os.MkdirAll(action.Dir, 0755); err != nil {
+			return "", fmt.Errorf("创建归档目录失败: %w", err)
+		}
+		target := availablePath(filepath.Join(action.Dir, filepath.Base(path)))
+		if err := m.moveProcessed(path, target, "处理成功，移入归档目录"); err != nil {
+			return "", err
+		}
+		return "已移入 " + target, nil
+	case AfterSuccessRename:
+		target := availablePath(filepath.Join(filepath.Dir(path), action.Prefix+filepath.Base(path)))
+		if err := m.moveProcessed(path, target, "处理成功，添加前缀"); err != nil {
+			return "", err
+		}
+		return "已重命名为 " + filepath.Base(target), nil
+	case AfterSuccessDelete:
+		target, err := action.Trash.Remove(path, "watch", "处理成功，删除原文件")
+		if err != nil {
+			return "", fmt.Errorf("删除原文件失败: %w", err)
+		}
+		if target != "" {
+			return "原文件已移至 " + target, nil
+		}
+		return "原文件已删除", nil
+	}
+	return "", nil
+}
+
+// moveProcessed 移动处理成功的文件并更新处理记录。新路径预先标记为已处理，
+// 留在监控范围内时（如重命名）不会被当作新文件再次处理
+func (m *FolderMonitor) moveProcessed(path, target, reason string) error {
+	m.mutex.Lock()
+	m.processedFiles[target] = true
+	m.mutex.Unlock()
+
+	if err := renameFile(path, target, reason); err != nil {
+		m.mutex.Lock()
+		delete(m.processedFiles, target)
+		m.mutex.Unlock()
+		return fmt.Errorf("移动原文件失败: %w", err)
+	}
+	if renamer, ok := m.processor.(recordRenamer); ok {
+		renamer.HandleRename(path, target)
+	}
+	utils.Info("已处理的文件已移动: %s -> %s", path, target)
+	return nil
+}
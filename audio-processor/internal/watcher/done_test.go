@@ -0,0 +1,141 @@
+package watcher
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/trash"
+)
+
+// renamingProcessor 总是处理成功，记录处理次数与移动原文件后的处理记录更新
+type renamingProcessor struct {
+	countingProcessor
+	mu      sync.Mutex
+	renames map[string]string
+}
+
+func (p *renamingProcessor) HandleRename(oldPath, newPath string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.renames[oldPath] = newPath
+}
+
+func (p *renamingProcessor) renamed(oldPath string) string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.renames[oldPath]
+}
+
+// TestSuccessAction 测试处理成功后按配置重命名、移动或删除原文件，移动后不会再次处理
+func TestSuccessAction(t *testing.T) {
+	cases := []struct {
+		mode   string
+		target func(mediaDir, trashDir string) string // 处理后原文件所在位置，为空表示已不存在
+	}{
+		{AfterSuccessKeep, func(mediaDir, _ string) string { return filepath.Join(mediaDir, "a.mp4") }},
+		{AfterSuccessRename, func(mediaDir, _ string) string { return filepath.Join(mediaDir, "done_a.mp4") }},
+		{AfterSuccessMove, func(mediaDir, _ string) string { return filepath.Join(mediaDir, "archived", "a.mp4") }},
+		{AfterSuccessDelete, func(_, trashDir string) string { return "" }},
+	}
+	for _, c := range cases {
+		t.Run(c.mode, func(t *testing.T) {
+			mediaDir := t.TempDir()
+			trashDir := filepath.Join(t.TempDir(), ".trash")
+			source := filepath.Join(mediaDir, "a.mp4")
+
+			processor := &renamingProcessor{renames: make(map[string]string)}
+			monitor, err := NewMediaFolderMonitor(mediaDir, processor, nil)
+			if err != nil {
+				t.Fatalf("创建监控器失败: %v", err)
+			}
+			monitor.stability = StabilityPolicy{}
+			monitor.debounceTime = 10 * time.Millisecond
+			monitor.SetQueueReportInterval(0)
+			monitor.SetScope(true, nil, nil)
+			monitor.SetSuccessAction(SuccessAction{
+				Mode:   c.mode,
+				Prefix: "done_",
+				Trash:  trash.New(trash.ModeFolder, trashDir, 0),
+			})
+			if err := monitor.Start(); err != nil {
+				t.Fatalf("启动监控器失败: %v", err)
+			}
+			defer monitor.Stop()
+
+			if err := os.WriteFile(source, []byte("video"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			deadline := time.Now().Add(3 * time.Second)
+			for time.Now().Before(deadline) && len(monitor.Snapshot().Recent) == 0 {
+				time.Sleep(10 * time.Millisecond)
+			}
+			// 留出移动后的文件被检测到的时间
+			time.Sleep(100 * time.Millisecond)
+
+			if calls := processor.callCount(); calls != 1 {
+				t.Fatalf("期望处理 1 次，实际 %d 次", calls)
+			}
+			target := c.target(mediaDir, trashDir)
+			if target == "" {
+				if _, err := os.Stat(source); !os.IsNotExist(err) {
+					t.Fatal("原文件应已删除")
+				}
+				if matches, _ := filepath.Glob(filepath.Join(trashDir, "*a.mp4")); len(matches) != 1 {
+					t.Fatalf("原文件应移入回收目录，实际: %v", matches)
+				}
+				return
+			}
+			if _, err := os.Stat(target); err != nil {
+				t.Fatalf("原文件应位于 %s: %v", target, err)
+			}
+			if target != source && processor.renamed(source) != target {
+				t.Fatalf("应更新处理记录中的路径，实际: %q", processor.renamed(source))
+			}
+		})
+	}
+}
+
+// TestSuccessActionOutsideFolder 测试监控目录外、被排除的文件处理成功后不会被删除或移动
+func TestSuccessActionOutsideFolder(t *testing.T) {
+	mediaDir := t.TempDir()
+	trashDir := filepath.Join(t.TempDir(), ".trash")
+	outside := filepath.Join(t.TempDir(), "a.mp4")
+	excluded := filepath.Join(mediaDir, "skip.mp4")
+	for _, path := range []string{outside, excluded} {
+		if err := os.WriteFile(path, []byte("video"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	monitor, err := NewMediaFolderMonitor(mediaDir, &countingProcessor{}, nil)
+	if err != nil {
+		t.Fatalf("创建监控器失败: %v", err)
+	}
+	defer monitor.watcher.Close()
+	monitor.SetScope(false, nil, []string{"skip.*"})
+
+	for _, mode := range []string{AfterSuccessDelete, AfterSuccessMove, AfterSuccessRename} {
+		monitor.SetSuccessAction(SuccessAction{
+			Mode:   mode,
+			Prefix: "done_",
+			Trash:  trash.New(trash.ModeFolder, trashDir, 0),
+		})
+		for _, path := range []string{outside, excluded} {
+			if _, err := monitor.afterSuccess(path); err != nil {
+				t.Fatalf("%s: 不应返回错误: %v", mode, err)
+			}
+			if _, err := os.Stat(path); err != nil {
+				t.Fatalf("%s: 范围外的文件应保持不动: %v", mode, err)
+			}
+		}
+	}
+	if _, err := os.Stat(trashDir); !os.IsNotExist(err) {
+		t.Fatal("不应有文件移入回收目录")
+	}
+	if _, err := os.Stat(filepath.Join(mediaDir, "archived")); !os.IsNotExist(err) {
+		t.Fatal("不应创建归档目录")
+	}
+}
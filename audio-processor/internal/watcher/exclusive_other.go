@@ -0,0 +1,14 @@
+//go:build !unix && !windows
+
+package watcher
+
+import "os"
+
+// exclusiveOpen 当前平台无法检测其他程序是否仍在写入，只检查文件可以打开
+func exclusiveOpen(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	return file.Close()
+}
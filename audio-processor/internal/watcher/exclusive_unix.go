@@ -0,0 +1,22 @@
+//go:build unix
+
+package watcher
+
+import (
+	"os"
+	"syscall"
+)
+
+// exclusiveOpen 尝试对文件加独占锁，其他程序持有锁（如加锁写入的下载工具）时失败。
+// 类 Unix 系统没有强制锁，不加锁写入的程序检测不到，仍依赖大小不变的检查
+func exclusiveOpen(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	if err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		return err
+	}
+	return syscall.Flock(int(file.Fd()), syscall.LOCK_UN)
+}
@@ -0,0 +1,46 @@
+//go:build unix
+
+package watcher
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// TestExclusiveOpen 测试其他程序持有文件锁时继续等待，释放后返回
+func TestExclusiveOpen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "a.mp4")
+	if err := os.WriteFile(path, []byte("video"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	writer, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer writer.Close()
+	fd := int(writer.Fd())
+	if err := syscall.Flock(fd, syscall.LOCK_EX); err != nil {
+		t.Fatal(err)
+	}
+	if err := exclusiveOpen(path); err == nil {
+		t.Fatal("文件被锁定时应失败")
+	}
+
+	unlocked := make(chan struct{})
+	time.AfterFunc(100*time.Millisecond, func() {
+		syscall.Flock(fd, syscall.LOCK_UN)
+		close(unlocked)
+	})
+	defer func() { <-unlocked }()
+	start := time.Now()
+	policy := StabilityPolicy{Checks: 1, Interval: 20 * time.Millisecond, Exclusive: true}
+	if err := waitStable(path, policy, nil); err != nil {
+		t.Fatalf("等待写入完成失败: %v", err)
+	}
+	if time.Since(start) < 100*time.Millisecond {
+		t.Fatal("释放锁之前不应返回")
+	}
+}
@@ -0,0 +1,18 @@
+//go:build windows
+
+package watcher
+
+import "syscall"
+
+// exclusiveOpen 以不共享的方式打开文件，其他程序仍打开着文件（如正在下载）时失败
+func exclusiveOpen(path string) error {
+	name, err := syscall.UTF16PtrFromString(path)
+	if err != nil {
+		return err
+	}
+	handle, err := syscall.CreateFile(name, syscall.GENERIC_READ, 0, nil, syscall.OPEN_EXISTING, syscall.FILE_ATTRIBUTE_NORMAL, 0)
+	if err != nil {
+		return err
+	}
+	return syscall.CloseHandle(handle)
+}
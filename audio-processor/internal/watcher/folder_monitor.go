@@ -1,6 +1,7 @@
 package watcher
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -10,10 +11,20 @@ import (
 
 	"github.com/ccp-p/asr-media-cli/audio-processor/internal/adapters"
 	"github.com/ccp-p/asr-media-cli/audio-processor/internal/ui"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/audio"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/audit"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/notify"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/schedule"
 	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/utils"
 	"github.com/fsnotify/fsnotify"
 )
 
+// ErrAlreadyQueued 文件已在等待或处理中
+var ErrAlreadyQueued = errors.New("文件已在处理队列中")
+
+// ErrOutOfScope 文件不在监控目录内，或被监控范围的 include/exclude 规则排除
+var ErrOutOfScope = errors.New("文件不在监控范围内")
+
 // FileEventHandler 是处理文件事件的接口
 type FileEventHandler interface {
 	OnFileCreated(filePath string)
@@ -34,13 +45,46 @@ type FolderMonitor struct {
 	mutex          sync.Mutex
 	stopChan       chan struct{}
 	progressManager *ui.ProgressManager
+
+	// 重试与隔离
+	stability     StabilityPolicy  // 判断文件写入完成的策略
+	maxAttempts   int              // 单个文件的最大尝试次数
+	retryDelay    time.Duration    // 重试基础延迟，按指数退避
+	quarantineDir string           // 多次失败后的隔离目录
+	attempts      map[string]int   // 文件 -> 已尝试次数
+
+	// 处理成功后对原文件的操作
+	success SuccessAction
+
+	// 处理成功或最终失败时的通知，为 nil 时不通知
+	notifier *notify.Dispatcher
+
+	// 队列视图
+	queue          *ProcessingQueue
+	reportInterval time.Duration // 定期打印队列的间隔，0 表示不打印
+
+	// 持久化的待处理文件，为 nil 时重启后只靠扫描文件夹发现未处理的文件
+	journal *queueJournal
+
+	// 监控范围：是否包含子目录与文件的包含、排除模式
+	scope watchScope
+
+	// 轮询：定期扫描监控范围代替 fsnotify 事件，watcher 为 nil 时总是轮询
+	poll         bool
+	pollInterval time.Duration
+
+	// 允许开始处理的时间窗口，为 nil 时不限制；release 在 ProcessNow 时关闭，放行等待窗口的文件
+	window   *schedule.Schedule
+	release  chan struct{}
 }
 
 // NewFolderMonitor 创建新的文件夹监控器
 func NewFolderMonitor(folderPath string, extensions []string, handler FileEventHandler, debounceTime time.Duration) (*FolderMonitor, error) {
+	// 无法使用系统文件监控（如 inotify 实例数已达上限）时改为定期扫描
 	watcher, err := fsnotify.NewWatcher()
 	if err != nil {
-		return nil, fmt.Errorf("创建文件监控器失败: %w", err)
+		utils.Warn("创建文件监控器失败: %v，改为定期扫描文件夹", err)
+		watcher = nil
 	}
 
 	monitor := &FolderMonitor{
@@ -52,6 +96,18 @@ func NewFolderMonitor(folderPath string, extensions []string, handler FileEventH
 		pendingFiles:   make(map[string]*time.Timer),
 		processedFiles: make(map[string]bool),
 		stopChan:       make(chan struct{}),
+		release:        make(chan struct{}),
+		stability:      DefaultStabilityPolicy(),
+		maxAttempts:    3,
+		retryDelay:     30 * time.Second,
+		quarantineDir:  filepath.Join(folderPath, "quarantine"),
+		attempts:       make(map[string]int),
+		success:        SuccessAction{Mode: AfterSuccessKeep, Dir: filepath.Join(folderPath, "archived")},
+		queue:          NewProcessingQueue(),
+		reportInterval: 30 * time.Second,
+		scope:          watchScope{root: folderPath},
+		poll:           watcher == nil,
+		pollInterval:   10 * time.Second,
 	}
 
 	return monitor, nil
@@ -59,11 +115,8 @@ func NewFolderMonitor(folderPath string, extensions []string, handler FileEventH
 
 // NewMediaFolderMonitor 创建媒体文件夹监控器
 func NewMediaFolderMonitor(folderPath string, processor adapters.MediaProcessor, progressManager *ui.ProgressManager) (*FolderMonitor, error) {
-	// 定义支持的媒体文件扩展名
-	extensions := []string{
-		".mp3", ".wav", ".m4a", ".flac", ".ogg", ".aac", // 音频文件
-		".mp4", ".mov", ".avi", ".mkv", ".wmv", ".flv",  // 视频文件
-	}
+	// 支持的音频、视频与 HLS 播放列表
+	extensions := audio.SupportedExtensions()
 	
 	// 创建处理器
 	handler := &MediaFileHandler{
@@ -88,6 +141,89 @@ func (m *FolderMonitor) SetProgressManager(manager *ui.ProgressManager) {
 	m.progressManager = manager
 }
 
+// SetRetryPolicy 设置失败重试策略
+// maxAttempts 为单个文件的最大尝试次数，baseDelay 为第一次重试前的等待时间（之后按指数翻倍），
+// quarantineDir 为超过次数后文件被移入的目录，为空时保持默认值
+func (m *FolderMonitor) SetRetryPolicy(maxAttempts int, baseDelay time.Duration, quarantineDir string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if maxAttempts > 0 {
+		m.maxAttempts = maxAttempts
+	}
+	if baseDelay >= 0 {
+		m.retryDelay = baseDelay
+	}
+	if quarantineDir != "" {
+		m.quarantineDir = quarantineDir
+	}
+}
+
+// SetScope 设置监控范围：recursive 为 true 时同时监控子目录（之后新建的子目录自动加入），
+// include 非空时只处理匹配的文件，exclude 匹配的文件与目录被跳过。隐藏目录与隔离目录总是跳过。
+// 应在 Start 之前调用
+func (m *FolderMonitor) SetScope(recursive bool, include, exclude []string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.scope.recursive = recursive
+	m.scope.include = include
+	m.scope.exclude = exclude
+}
+
+// SetStabilityPolicy 设置判断文件写入完成的策略，应在 Start 之前调用
+func (m *FolderMonitor) SetStabilityPolicy(policy StabilityPolicy) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.stability = policy
+}
+
+// SetPolling 设置是否定期扫描监控范围代替系统文件事件，用于事件不可靠的网络驱动器（SMB/NFS）。
+// interval 为扫描间隔，不大于 0 时保持默认值。即使未启用，Start 时注册系统文件监控失败也会改为扫描。
+// 应在 Start 之前调用
+func (m *FolderMonitor) SetPolling(enabled bool, interval time.Duration) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.poll = enabled || m.watcher == nil
+	if interval > 0 {
+		m.pollInterval = interval
+	}
+}
+
+// SetQueueFile 将检测到但尚未处理完成的文件保存到 path，并读取上次保存的记录。
+// Start 时先恢复这些文件（保留已失败的次数），再扫描文件夹处理其余未处理的文件。应在 Start 之前调用
+func (m *FolderMonitor) SetQueueFile(path string) error {
+	journal, err := loadQueueJournal(path)
+	if err != nil {
+		return err
+	}
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.journal = journal
+	return nil
+}
+
+// SetNotifier 设置文件处理成功或多次重试后仍失败时的通知，应在 Start 之前调用
+func (m *FolderMonitor) SetNotifier(notifier *notify.Dispatcher) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.notifier = notifier
+}
+
+// SetQueueReportInterval 设置定期打印队列视图的间隔，0 表示不打印
+func (m *FolderMonitor) SetQueueReportInterval(interval time.Duration) {
+	m.reportInterval = interval
+}
+
+// Snapshot 返回当前的处理队列视图
+func (m *FolderMonitor) Snapshot() QueueSnapshot {
+	return m.queue.Snapshot()
+}
+
+// UpdateProgress 更新文件的处理进度，可作为批处理器的文件进度回调
+func (m *FolderMonitor) UpdateProgress(filePath string, percent int, message string) {
+	m.queue.UpdateProgress(filePath, percent, message)
+}
+
 // Start 开始监控文件夹
 func (m *FolderMonitor) Start() error {
 	// 确保文件夹存在
@@ -95,45 +231,161 @@ func (m *FolderMonitor) Start() error {
 		return fmt.Errorf("创建文件夹失败: %w", err)
 	}
 
-	// 添加要监控的文件夹
-	if err := m.watcher.Add(m.folderPath); err != nil {
-		return fmt.Errorf("添加监控文件夹失败: %w", err)
+	// 添加要监控的文件夹，递归监控时包括全部子目录
+	m.mutex.Lock()
+	m.scope.skipDirs = []string{m.quarantineDir}
+	if m.success.Mode == AfterSuccessMove {
+		m.scope.skipDirs = append(m.scope.skipDirs, m.success.Dir)
+	}
+	m.mutex.Unlock()
+	if !m.poll {
+		// 网络驱动器等不支持系统文件监控的目录改为定期扫描
+		if err := m.scope.walk(m.addWatch); err != nil {
+			utils.Warn("添加监控文件夹失败: %v，改为每 %s 扫描一次", err, m.pollInterval)
+			m.poll = true
+		}
 	}
 
 	// 启动监控协程
-	go m.watchLoop()
+	if m.poll {
+		if m.watcher != nil {
+			m.watcher.Close()
+			m.watcher = nil
+		}
+		go m.pollLoop()
+	} else {
+		go m.watchLoop()
+	}
+
+	// 定期打印队列视图
+	if m.processor != nil && m.reportInterval > 0 {
+		go m.reportLoop()
+	}
 	
 	// 处理已存在的文件
 	if m.processor != nil {
 		go m.processExistingFiles()
 	}
 
-	utils.Info("开始监控文件夹: %s", m.folderPath)
+	mode := ""
+	if m.poll {
+		mode = fmt.Sprintf(" (每 %s 扫描一次)", m.pollInterval)
+	}
+	if m.scope.recursive {
+		utils.Info("开始监控文件夹及其子目录: %s%s", m.folderPath, mode)
+	} else {
+		utils.Info("开始监控文件夹: %s%s", m.folderPath, mode)
+	}
+	return nil
+}
+
+// addWatch 将目录加入监控
+func (m *FolderMonitor) addWatch(dir string) error {
+	if err := m.watcher.Add(dir); err != nil {
+		if dir == m.folderPath {
+			return err
+		}
+		utils.Warn("添加监控子目录失败 %s: %v", dir, err)
+		return nil
+	}
+	if dir != m.folderPath {
+		utils.Debug("监控子目录: %s", dir)
+	}
 	return nil
 }
 
-// processExistingFiles 处理文件夹中已存在的文件
+// addTree 将新建的子目录及其下的目录加入监控，并处理其中已有的文件（监控建立前复制进来的文件不会产生事件）
+func (m *FolderMonitor) addTree(dir string) {
+	if !m.scope.watchDir(dir) {
+		return
+	}
+	var files []string
+	err := filepath.WalkDir(dir, func(p string, entry os.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if entry.IsDir() {
+			if !m.scope.watchDir(p) {
+				return filepath.SkipDir
+			}
+			return m.addWatch(p)
+		}
+		if m.isTargetFile(p) {
+			files = append(files, p)
+		}
+		return nil
+	})
+	if err != nil {
+		utils.Warn("监控新目录失败 %s: %v", dir, err)
+	}
+	for _, file := range files {
+		m.schedule(file)
+	}
+}
+
+// processExistingFiles 先恢复上次退出时未处理完的文件，再扫描文件夹，处理不在处理记录中的文件
 func (m *FolderMonitor) processExistingFiles() {
-	entries, err := os.ReadDir(m.folderPath)
+	var mediaFiles []string
+	queued := make(map[string]bool)
+	for _, entry := range m.journal.list() {
+		if !m.isMediaFile(entry.Path) {
+			utils.Info("上次未处理完的文件已不存在，跳过: %s", entry.Path)
+			m.journal.remove(entry.Path)
+			continue
+		}
+		// 处理成功后、移除记录前退出的文件无需重新处理，手动加入的除外
+		if !entry.Force && m.processor != nil && m.processor.IsRecognizedFile(entry.Path) {
+			m.journal.remove(entry.Path)
+			continue
+		}
+		if entry.Attempts > 0 {
+			m.mutex.Lock()
+			m.attempts[entry.Path] = entry.Attempts
+			m.mutex.Unlock()
+		}
+		queued[entry.Path] = true
+		mediaFiles = append(mediaFiles, entry.Path)
+	}
+	if len(mediaFiles) > 0 {
+		utils.Info("恢复了 %d 个上次未处理完的文件", len(mediaFiles))
+	}
+
+	var scanned []string
+	err := m.scope.walk(func(dir string) error {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			if dir == m.folderPath {
+				return err
+			}
+			utils.Warn("读取子目录失败 %s: %v", dir, err)
+			return nil
+		}
+		for _, entry := range entries {
+			if entry.IsDir() {
+				continue
+			}
+			filePath := filepath.Join(dir, entry.Name())
+			if m.isTargetFile(filePath) && !queued[filePath] {
+				scanned = append(scanned, filePath)
+			}
+		}
+		return nil
+	})
 	if err != nil {
 		utils.Error("读取文件夹失败: %v", err)
 		return
 	}
 	
-	var mediaFiles []string
-	for _, entry := range entries {
-		if entry.IsDir() {
+	utils.Info("找到 %d 个现有媒体文件", len(scanned))
+	for _, filePath := range scanned {
+		// 检查是否已处理
+		if m.processor != nil && m.processor.IsRecognizedFile(filePath) {
+			utils.Info("跳过已处理的文件: %s", filepath.Base(filePath))
 			continue
 		}
-		
-		filePath := filepath.Join(m.folderPath, entry.Name())
-		if m.isTargetFile(filePath) {
-			mediaFiles = append(mediaFiles, filePath)
-		}
+		mediaFiles = append(mediaFiles, filePath)
 	}
 	
-	utils.Info("找到 %d 个现有媒体文件", len(mediaFiles))
-	
 	// 创建进度条
 	if m.progressManager != nil {
 		m.progressManager.CreateProgressBar("existing_files", len(mediaFiles), 
@@ -142,12 +394,6 @@ func (m *FolderMonitor) processExistingFiles() {
 	
 	// 处理文件
 	for i, filePath := range mediaFiles {
-		// 检查是否已处理
-		if m.processor != nil && m.processor.IsRecognizedFile(filePath) {
-			utils.Info("跳过已处理的文件: %s", filepath.Base(filePath))
-			continue
-		}
-		
 		// 更新进度
 		if m.progressManager != nil {
 			m.progressManager.UpdateProgressBar("existing_files", i+1,
@@ -167,10 +413,12 @@ func (m *FolderMonitor) processExistingFiles() {
 // Stop 停止监控
 func (m *FolderMonitor) Stop() {
 	close(m.stopChan)
-	m.watcher.Close()
+	if m.watcher != nil {
+		m.watcher.Close()
+	}
 	utils.Info("停止监控文件夹: %s", m.folderPath)
 
-	// 取消所有待处理的文件定时器
+	// 取消所有待处理的文件定时器（包括等待重试的文件）
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
 	for _, timer := range m.pendingFiles {
@@ -198,6 +446,28 @@ func (m *FolderMonitor) watchLoop() {
 	}
 }
 
+// reportLoop 定期打印队列视图，队列无变化且没有处理中的文件时不重复打印
+func (m *FolderMonitor) reportLoop() {
+	ticker := time.NewTicker(m.reportInterval)
+	defer ticker.Stop()
+
+	lastVersion := 0
+	for {
+		select {
+		case <-m.stopChan:
+			return
+		case <-ticker.C:
+			changed, version := m.queue.changedSince(lastVersion)
+			snapshot := m.queue.Snapshot()
+			if !changed && len(snapshot.Processing) == 0 {
+				continue
+			}
+			lastVersion = version
+			ui.GetTerminalManager().PrintMsg("%s", snapshot.Format())
+		}
+	}
+}
+
 // 处理文件事件
 func (m *FolderMonitor) handleFileEvent(event fsnotify.Event) {
 	// 只处理创建和修改事件
@@ -206,10 +476,20 @@ func (m *FolderMonitor) handleFileEvent(event fsnotify.Event) {
 	}
 
 	filePath := event.Name
+	if event.Op&fsnotify.Create != 0 && m.scope.recursive {
+		if info, err := os.Stat(filePath); err == nil && info.IsDir() {
+			m.addTree(filePath)
+			return
+		}
+	}
 	if !m.isTargetFile(filePath) {
 		return
 	}
+	m.schedule(filePath)
+}
 
+// schedule 在防抖时间内没有新的变化后处理文件
+func (m *FolderMonitor) schedule(filePath string) {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
 
@@ -220,33 +500,91 @@ func (m *FolderMonitor) handleFileEvent(event fsnotify.Event) {
 
 	// 创建新的定时器
 	m.pendingFiles[filePath] = time.AfterFunc(m.debounceTime, func() {
-		// 增加对 MP3 文件的额外延迟，确保 FFmpeg 写入完成
-		if strings.ToLower(filepath.Ext(filePath)) == ".mp3" {
-			utils.Debug("检测到 MP3 文件，增加额外延迟: %s", filePath)
-			time.Sleep(5 * time.Second) // 额外等待 5 秒
-		}
 		m.processFile(filePath)
 	})
 
+	// 防抖期间退出时，重启后仍会处理
+	if m.processor != nil && !m.processedFiles[filePath] {
+		m.journal.add(filePath, false)
+	}
+
 	utils.Debug("检测到文件变化: %s", filePath)
 }
 
-// 判断是否为目标文件类型
+// Enqueue 手动将已存在的文件加入处理队列，无需重新复制或修改文件。
+// 相对路径按监控目录解析，监控目录外或不在监控范围内的文件返回 ErrOutOfScope；
+// 已处理过的文件会被重新处理，等待重试的文件会立即处理。返回实际入队的文件路径。
+func (m *FolderMonitor) Enqueue(filePath string) (string, error) {
+	if m.processor == nil {
+		return "", fmt.Errorf("监控器未设置媒体处理器")
+	}
+	if strings.TrimSpace(filePath) == "" {
+		return "", fmt.Errorf("文件路径不能为空")
+	}
+
+	if !filepath.IsAbs(filePath) {
+		filePath = filepath.Join(m.folderPath, filePath)
+	}
+	filePath = filepath.Clean(filePath)
+
+	// 只接受监控目录内的文件，不能借接口处理任意路径
+	if _, ok := m.scope.relative(filePath); !ok {
+		return "", fmt.Errorf("%w: %s", ErrOutOfScope, filePath)
+	}
+	if _, err := os.Stat(filePath); err != nil {
+		return "", fmt.Errorf("文件不存在: %s", filePath)
+	}
+	if !m.isMediaFile(filePath) {
+		return "", fmt.Errorf("不支持的文件类型: %s", filePath)
+	}
+	if !m.scope.includeFile(filePath) {
+		return "", fmt.Errorf("%w: %s", ErrOutOfScope, filePath)
+	}
+
+	if state, exists := m.queue.State(filePath); exists && state != QueueRetrying {
+		return "", fmt.Errorf("%w: %s", ErrAlreadyQueued, filePath)
+	}
+
+	m.mutex.Lock()
+	if timer, exists := m.pendingFiles[filePath]; exists {
+		timer.Stop()
+		delete(m.pendingFiles, filePath)
+	}
+	delete(m.processedFiles, filePath)
+	m.mutex.Unlock()
+
+	m.journal.add(filePath, true)
+	utils.Info("手动加入处理队列: %s", filePath)
+	m.processFile(filePath)
+
+	return filePath, nil
+}
+
+// 判断是否为监控范围内的目标文件
 func (m *FolderMonitor) isTargetFile(filePath string) bool {
+	return m.isMediaFile(filePath) && m.scope.includeFile(filePath)
+}
+
+// 判断是否为目标文件类型
+func (m *FolderMonitor) isMediaFile(filePath string) bool {
 	// 检查是否为常规文件
 	fileInfo, err := os.Stat(filePath)
 	if err != nil || fileInfo.IsDir() {
 		return false
 	}
+	return m.isMediaName(filepath.Base(filePath))
+}
 
+// 按文件名判断是否为目标文件类型（非隐藏文件且扩展名匹配）
+func (m *FolderMonitor) isMediaName(basename string) bool {
 	// 检查是否为隐藏文件
-	basename := filepath.Base(filePath)
 	if strings.HasPrefix(basename, ".") {
 		return false
 	}
 
+
 	// 检查扩展名
-	ext := strings.ToLower(filepath.Ext(filePath))
+	ext := strings.ToLower(filepath.Ext(basename))
 	for _, targetExt := range m.fileExtensions {
 		if ext == targetExt {
 			return true
@@ -272,6 +610,8 @@ func (m *FolderMonitor) processFile(filePath string) {
 	// 检查文件是否仍然存在
 	if _, err := os.Stat(filePath); os.IsNotExist(err) {
 		utils.Warn("文件已不存在，跳过处理: %s", filePath)
+		m.queue.Remove(filePath)
+		m.journal.remove(filePath)
 		return
 	}
 
@@ -279,6 +619,8 @@ func (m *FolderMonitor) processFile(filePath string) {
 	
 	// 使用处理器处理文件
 	if m.processor != nil {
+		m.journal.add(filePath, false)
+		m.queue.MarkWaiting(filePath)
 		go func(path string) {
 			// 创建唯一的处理ID
 			processID := fmt.Sprintf("process-%s-%s", 
@@ -286,27 +628,54 @@ func (m *FolderMonitor) processFile(filePath string) {
 				utils.GenerateRandomString(6))
 				
 			utils.Info("[%s] 开始处理文件: %s", processID, path)
+
+			// 不在允许处理的时间窗口内时排队等待，停止时保留持久化的记录，重启后继续处理
+			if err := m.waitWindow(path); err != nil {
+				m.queue.Remove(path)
+				return
+			}
 			
-			// 等待文件写入完成
-			time.Sleep(2 * time.Second)
-			
-			// 再次检查文件是否存在，防止处理过程中被删除
-			if _, err := os.Stat(path); os.IsNotExist(err) {
-				utils.Warn("[%s] 文件已不存在，跳过处理: %s", processID, path)
+			// 等待文件写入完成：大小连续多次不变，必要时确认没有其他程序占用
+			if err := waitStable(path, m.stability, m.stopChan); err != nil {
+				switch {
+				case errors.Is(err, os.ErrNotExist):
+					// 文件在等待期间被删除，如下载工具删除的临时文件
+					utils.Warn("[%s] 文件已不存在，跳过处理: %s", processID, path)
+					m.clearAttempts(path)
+					m.queue.Remove(path)
+					m.journal.remove(path)
+				case errors.Is(err, errStopped):
+					// 保留持久化的记录，重启后继续处理
+					m.queue.Remove(path)
+				default:
+					utils.Warn("[%s] %v: %s", processID, err, path)
+					m.handleFailure(path)
+				}
 				return
 			}
 			
-			// 检查文件大小是否为0
+			// 检查文件大小是否为0，可能仍在复制中
 			fileInfo, err := os.Stat(path)
 			if err == nil && fileInfo.Size() == 0 {
-				utils.Warn("[%s] 文件大小为0，跳过处理: %s", processID, path)
+				utils.Warn("[%s] 文件大小为0，可能仍在写入: %s", processID, path)
+				m.handleFailure(path)
 				return
 			}
 			
 			if m.processor.ProcessFile(path) {
 				utils.Info("[%s] 文件处理成功: %s", processID, path)
+				m.clearAttempts(path)
+				m.journal.remove(path)
+				message, err := m.afterSuccess(path)
+				if err != nil {
+					utils.Warn("[%s] %v: %s", processID, err, path)
+					message = err.Error()
+				}
+				m.queue.MarkFinished(path, QueueCompleted, message)
+				m.notifier.Send(notify.NewEvent(notify.EventCompleted, path, m.folderPath, message))
 			} else {
 				utils.Error("[%s] 文件处理失败: %s", processID, path)
+				m.handleFailure(path)
 			}
 		}(filePath)
 		return
@@ -318,6 +687,57 @@ func (m *FolderMonitor) processFile(filePath string) {
 	}
 }
 
+// handleFailure 记录一次失败，未超过次数时按指数退避安排重试，否则隔离文件
+func (m *FolderMonitor) handleFailure(filePath string) {
+	m.mutex.Lock()
+	m.attempts[filePath]++
+	attempt := m.attempts[filePath]
+
+	if attempt < m.maxAttempts {
+		delay := m.retryDelay * time.Duration(1<<uint(attempt-1))
+		// 允许再次处理
+		delete(m.processedFiles, filePath)
+		m.pendingFiles[filePath] = time.AfterFunc(delay, func() {
+			m.processFile(filePath)
+		})
+		m.mutex.Unlock()
+		m.journal.setAttempts(filePath, attempt)
+
+		m.queue.MarkRetrying(filePath, time.Now().Add(delay),
+			fmt.Sprintf("第 %d/%d 次处理失败", attempt, m.maxAttempts))
+		utils.Warn("文件处理失败 (第 %d/%d 次)，%s 后重试: %s",
+			attempt, m.maxAttempts, delay, filepath.Base(filePath))
+		return
+	}
+
+	delete(m.attempts, filePath)
+	quarantineDir := m.quarantineDir
+	m.mutex.Unlock()
+	m.journal.remove(filePath)
+
+	// 超过最大尝试次数，移入隔离目录，保持已处理标记避免再次触发
+	target, err := moveToFolder(filePath, quarantineDir,
+		fmt.Sprintf("连续 %d 次处理失败，移入隔离目录", m.maxAttempts))
+	if err != nil {
+		utils.Error("隔离文件失败 %s: %v", filePath, err)
+		m.queue.MarkFinished(filePath, QueueFailed, fmt.Sprintf("隔离失败: %v", err))
+		m.notifier.Send(notify.NewEvent(notify.EventFailed, filePath, m.folderPath,
+			fmt.Sprintf("连续 %d 次处理失败，隔离失败: %v", m.maxAttempts, err)))
+		return
+	}
+	m.queue.MarkFinished(filePath, QueueQuarantined, target)
+	m.notifier.Send(notify.NewEvent(notify.EventFailed, filePath, m.folderPath,
+		fmt.Sprintf("连续 %d 次处理失败，已移入隔离目录 %s", m.maxAttempts, target)))
+	utils.Error("文件连续 %d 次处理失败，已移入隔离目录: %s", m.maxAttempts, target)
+}
+
+// clearAttempts 清除文件的失败计数
+func (m *FolderMonitor) clearAttempts(filePath string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	delete(m.attempts, filePath)
+}
+
 // MediaFileHandler 实现媒体文件处理
 type MediaFileHandler struct {
 	processor adapters.MediaProcessor
@@ -387,31 +807,63 @@ func (h *FileMovementHandler) OnFileDeleted(filePath string) {
 
 // moveFile 将文件移动到目标文件夹
 func (h *FileMovementHandler) moveFile(sourcePath string) {
-	filename := filepath.Base(sourcePath)
-	targetPath := filepath.Join(h.targetFolder, filename)
-	
-	// 如果目标文件已存在，添加时间戳
-	if _, err := os.Stat(targetPath); err == nil {
-		ext := filepath.Ext(filename)
-		name := filename[:len(filename)-len(ext)]
-		timestamp := time.Now().Format("20060102150405")
-		newFilename := fmt.Sprintf("%s_%s%s", name, timestamp, ext)
-		targetPath = filepath.Join(h.targetFolder, newFilename)
-	}
-	// 移动文件
-	if err := os.Rename(sourcePath, targetPath); err != nil {
-		utils.Error("移动文件失败 %s -> %s: %v", sourcePath, targetPath, err)
+	targetPath, err := moveToFolder(sourcePath, h.targetFolder, "新文件移入媒体目录")
+	if err != nil {
+		utils.Error("移动文件失败 %s -> %s: %v", sourcePath, h.targetFolder, err)
 		return
 	}
 
 	utils.Info("文件已移动: %s -> %s", sourcePath, targetPath)
 }
 
+// moveToFolder 将文件移动到目标文件夹，目标已存在同名文件时添加时间戳，返回新路径。
+// 移动操作会连同 reason 一起写入审计日志。
+func moveToFolder(sourcePath, targetFolder, reason string) (string, error) {
+	if err := os.MkdirAll(targetFolder, 0755); err != nil {
+		return "", fmt.Errorf("创建目标文件夹失败: %w", err)
+	}
+
+	targetPath := availablePath(filepath.Join(targetFolder, filepath.Base(sourcePath)))
+	if err := renameFile(sourcePath, targetPath, reason); err != nil {
+		return "", err
+	}
+	return targetPath, nil
+}
+
+// availablePath 目标已存在同名文件时在文件名后添加时间戳
+func availablePath(targetPath string) string {
+	if _, err := os.Stat(targetPath); err != nil {
+		return targetPath
+	}
+	filename := filepath.Base(targetPath)
+	ext := filepath.Ext(filename)
+	name := filename[:len(filename)-len(ext)]
+	timestamp := time.Now().Format("20060102150405")
+	return filepath.Join(filepath.Dir(targetPath), fmt.Sprintf("%s_%s%s", name, timestamp, ext))
+}
+
+// renameFile 移动文件并连同 reason 一起写入审计日志
+func renameFile(sourcePath, targetPath, reason string) error {
+	entry := audit.Entry{
+		Action:    audit.ActionMove,
+		Path:      sourcePath,
+		Target:    targetPath,
+		Reason:    reason,
+		Initiator: "watch",
+	}
+	err := os.Rename(sourcePath, targetPath)
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	audit.Record(entry)
+	return err
+}
+
 // StartFolderMonitoring 开始监控文件夹并移动文件
 func StartFolderMonitoring(sourceFolder, targetFolder string) (func(), error) {
 	handler := NewFileMovementHandler(targetFolder)
 	
-	extensions := []string{".mp4", ".mp3", ".wav", ".m4a", ".mov", ".avi", ".mkv", ".flv"}
+	extensions := audio.SupportedExtensions()
 	monitor, err := NewFolderMonitor(sourceFolder, extensions, handler, 5*time.Second)
 	if err != nil {
 		return nil, err
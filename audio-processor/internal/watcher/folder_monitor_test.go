@@ -1,9 +1,16 @@
 package watcher
 
 import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestOnFileModified(t *testing.T) {
@@ -135,3 +142,170 @@ func TestMoveFile(t *testing.T) {
 		t.Fatal("未能找到带时间戳的文件")
 	}
 }
+
+// failingProcessor 总是处理失败的处理器，用于测试重试逻辑
+type failingProcessor struct {
+	mu    sync.Mutex
+	calls int
+}
+
+func (p *failingProcessor) ProcessFile(filePath string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.calls++
+	return false
+}
+
+func (p *failingProcessor) IsRecognizedFile(filePath string) bool {
+	return false
+}
+
+func (p *failingProcessor) callCount() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.calls
+}
+
+func TestRetryThenQuarantine(t *testing.T) {
+	mediaDir, err := os.MkdirTemp("", "test-media")
+	if err != nil {
+		t.Fatalf("无法创建临时目录: %v", err)
+	}
+	defer os.RemoveAll(mediaDir)
+
+	processor := &failingProcessor{}
+	monitor, err := NewMediaFolderMonitor(mediaDir, processor, nil)
+	if err != nil {
+		t.Fatalf("创建监控器失败: %v", err)
+	}
+	defer monitor.watcher.Close()
+
+	quarantineDir := filepath.Join(mediaDir, "quarantine")
+	monitor.stability = StabilityPolicy{}
+	monitor.SetRetryPolicy(3, 10*time.Millisecond, quarantineDir)
+
+	testFile := filepath.Join(mediaDir, "broken.mp4")
+	if err := os.WriteFile(testFile, []byte("not a video"), 0644); err != nil {
+		t.Fatalf("无法创建测试文件: %v", err)
+	}
+
+	monitor.processFile(testFile)
+
+	// 等待重试与隔离完成
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if _, err := os.Stat(filepath.Join(quarantineDir, "broken.mp4")); err == nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if calls := processor.callCount(); calls != 3 {
+		t.Fatalf("应该尝试 3 次，实际 %d 次", calls)
+	}
+	if _, err := os.Stat(filepath.Join(quarantineDir, "broken.mp4")); err != nil {
+		t.Fatalf("文件应该被移入隔离目录: %v", err)
+	}
+	if _, err := os.Stat(testFile); !os.IsNotExist(err) {
+		t.Fatal("原文件应该已被移走")
+	}
+}
+
+// countingProcessor 总是处理成功的处理器，记录处理次数
+type countingProcessor struct {
+	mu    sync.Mutex
+	calls int
+}
+
+func (p *countingProcessor) ProcessFile(filePath string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.calls++
+	return true
+}
+
+func (p *countingProcessor) IsRecognizedFile(filePath string) bool {
+	return false
+}
+
+func (p *countingProcessor) callCount() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.calls
+}
+
+func TestEnqueueHandler(t *testing.T) {
+	mediaDir := t.TempDir()
+
+	processor := &countingProcessor{}
+	monitor, err := NewMediaFolderMonitor(mediaDir, processor, nil)
+	if err != nil {
+		t.Fatalf("创建监控器失败: %v", err)
+	}
+	defer monitor.watcher.Close()
+	monitor.stability = StabilityPolicy{}
+
+	if err := os.WriteFile(filepath.Join(mediaDir, "talk.mp3"), []byte("audio"), 0644); err != nil {
+		t.Fatalf("无法创建测试文件: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(mediaDir, "notes.txt"), []byte("text"), 0644); err != nil {
+		t.Fatalf("无法创建测试文件: %v", err)
+	}
+
+	handler := monitor.EnqueueHandler()
+	post := func(body string) int {
+		req := httptest.NewRequest(http.MethodPost, "/api/enqueue", strings.NewReader(body))
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	// 相对路径按监控目录解析
+	if code := post(`{"path": "talk.mp3"}`); code != http.StatusAccepted {
+		t.Fatalf("期望状态码202，实际: %d", code)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) && processor.callCount() == 0 {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if processor.callCount() != 1 {
+		t.Fatalf("期望文件被处理1次，实际: %d", processor.callCount())
+	}
+
+	// 已处理过的文件可以再次手动入队
+	if code := post(`{"path": "talk.mp3"}`); code != http.StatusAccepted {
+		t.Fatalf("再次入队期望状态码202，实际: %d", code)
+	}
+
+	if code := post(`{"path": "notes.txt"}`); code != http.StatusBadRequest {
+		t.Fatalf("不支持的文件类型期望状态码400，实际: %d", code)
+	}
+	if code := post(`{"path": "missing.mp3"}`); code != http.StatusBadRequest {
+		t.Fatalf("不存在的文件期望状态码400，实际: %d", code)
+	}
+
+	// 监控目录外的文件，无论绝对路径还是 .. 开头的相对路径都拒绝
+	outside := filepath.Join(t.TempDir(), "outside.mp3")
+	if err := os.WriteFile(outside, []byte("audio"), 0644); err != nil {
+		t.Fatalf("无法创建测试文件: %v", err)
+	}
+	body, _ := json.Marshal(map[string]string{"path": outside})
+	if code := post(string(body)); code != http.StatusBadRequest {
+		t.Fatalf("监控目录外的文件期望状态码400，实际: %d", code)
+	}
+	rel, _ := filepath.Rel(mediaDir, outside)
+	body, _ = json.Marshal(map[string]string{"path": rel})
+	if code := post(string(body)); code != http.StatusBadRequest {
+		t.Fatalf("监控目录外的相对路径期望状态码400，实际: %d", code)
+	}
+
+	// 被排除规则排除的文件同样拒绝
+	monitor.SetScope(false, nil, []string{"*.mp3"})
+	if _, err := monitor.Enqueue("talk.mp3"); !errors.Is(err, ErrOutOfScope) {
+		t.Fatalf("被排除的文件期望返回 ErrOutOfScope，实际: %v", err)
+	}
+	if processor.callCount() > 2 {
+		t.Fatalf("范围外的文件不应被处理，实际处理次数: %d", processor.callCount())
+	}
+}
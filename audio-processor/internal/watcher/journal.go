@@ -0,0 +1,150 @@
+package watcher
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/utils"
+)
+
+// QueueFileName 监听模式待处理文件在输出目录中的文件名
+const QueueFileName = "watch_queue.json"
+
+// journalEntry 已检测到但尚未处理完成的文件
+type journalEntry struct {
+	Path     string    `json:"path"`
+	Attempts int       `json:"attempts,omitempty"` // 已失败的次数，重启后继续计数
+	Force    bool      `json:"force,omitempty"`    // 手动加入的文件，已处理过也重新处理
+	QueuedAt time.Time `json:"queued_at"`
+}
+
+// queueJournal 将检测到但尚未处理完成的文件写入 JSON 文件，
+// 处理过程中退出或崩溃后，下次启动时由 processExistingFiles 恢复。为 nil 时各方法不做任何事
+type queueJournal struct {
+	mu      sync.Mutex
+	path    string
+	entries map[string]*journalEntry
+}
+
+// loadQueueJournal 读取 path 中保存的待处理文件，文件不存在时返回空记录
+func loadQueueJournal(path string) (*queueJournal, error) {
+	j := &queueJournal{path: path, entries: make(map[string]*journalEntry)}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return j, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取监听队列失败: %w", err)
+	}
+	var entries []journalEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("解析监听队列失败: %w", err)
+	}
+	for i := range entries {
+		j.entries[entries[i].Path] = &entries[i]
+	}
+	return j, nil
+}
+
+// add 记录检测到的文件，已记录时只更新 force
+func (j *queueJournal) add(path string, force bool) {
+	if j == nil {
+		return
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if entry, exists := j.entries[path]; exists {
+		if !force || entry.Force {
+			return
+		}
+		entry.Force = true
+	} else {
+		j.entries[path] = &journalEntry{Path: path, Force: force, QueuedAt: time.Now()}
+	}
+	j.saveLocked()
+}
+
+// setAttempts 记录文件已失败的次数
+func (j *queueJournal) setAttempts(path string, attempts int) {
+	if j == nil {
+		return
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	entry, exists := j.entries[path]
+	if !exists {
+		entry = &journalEntry{Path: path, QueuedAt: time.Now()}
+		j.entries[path] = entry
+	}
+	entry.Attempts = attempts
+	j.saveLocked()
+}
+
+// remove 文件处理结束（成功、隔离或已被删除）后移除记录
+func (j *queueJournal) remove(path string) {
+	if j == nil {
+		return
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if _, exists := j.entries[path]; !exists {
+		return
+	}
+	delete(j.entries, path)
+	j.saveLocked()
+}
+
+// list 按检测时间返回全部记录
+func (j *queueJournal) list() []journalEntry {
+	if j == nil {
+		return nil
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.sortedLocked()
+}
+
+// sortedLocked 按检测时间（相同时按路径）返回全部记录，调用方需持有锁
+func (j *queueJournal) sortedLocked() []journalEntry {
+	entries := make([]journalEntry, 0, len(j.entries))
+	for _, entry := range j.entries {
+		entries = append(entries, *entry)
+	}
+	sort.Slice(entries, func(a, b int) bool {
+		if !entries[a].QueuedAt.Equal(entries[b].QueuedAt) {
+			return entries[a].QueuedAt.Before(entries[b].QueuedAt)
+		}
+		return entries[a].Path < entries[b].Path
+	})
+	return entries
+}
+
+// saveLocked 写入临时文件后替换记录文件，失败只记录警告，调用方需持有锁
+func (j *queueJournal) saveLocked() {
+	data, err := json.MarshalIndent(j.sortedLocked(), "", "  ")
+	if err != nil {
+		utils.Warn("编码监听队列失败: %v", err)
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(j.path), 0755); err != nil {
+		utils.Warn("创建输出目录失败: %v", err)
+		return
+	}
+	tmp := j.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		utils.Warn("写入监听队列失败: %v", err)
+		return
+	}
+	if err := os.Rename(tmp, j.path); err != nil {
+		os.Remove(tmp)
+		utils.Warn("写入监听队列失败: %v", err)
+	}
+}
@@ -0,0 +1,153 @@
+package watcher
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestQueueJournal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out", QueueFileName)
+	journal, err := loadQueueJournal(path)
+	if err != nil {
+		t.Fatalf("读取监听队列失败: %v", err)
+	}
+	journal.add("/media/a.mp4", false)
+	journal.add("/media/b.mp4", false)
+	journal.add("/media/b.mp4", true)
+	journal.setAttempts("/media/a.mp4", 2)
+	journal.add("/media/c.mp4", false)
+	journal.remove("/media/c.mp4")
+
+	reloaded, err := loadQueueJournal(path)
+	if err != nil {
+		t.Fatalf("读取监听队列失败: %v", err)
+	}
+	entries := reloaded.list()
+	if len(entries) != 2 {
+		t.Fatalf("期望2条记录，实际: %+v", entries)
+	}
+	if entries[0].Path != "/media/a.mp4" || entries[0].Attempts != 2 || entries[0].Force {
+		t.Fatalf("a.mp4 的记录不正确: %+v", entries[0])
+	}
+	if entries[1].Path != "/media/b.mp4" || !entries[1].Force {
+		t.Fatalf("b.mp4 应标记为手动加入: %+v", entries[1])
+	}
+
+	if err := os.WriteFile(path, []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadQueueJournal(path); err == nil {
+		t.Fatal("记录文件损坏时应返回错误")
+	}
+
+	// 未设置记录文件时各方法不做任何事
+	var disabled *queueJournal
+	disabled.add("/media/a.mp4", false)
+	disabled.remove("/media/a.mp4")
+	if len(disabled.list()) != 0 {
+		t.Fatal("未设置记录文件时没有记录")
+	}
+}
+
+// recordingProcessor 记录各文件的处理次数，recognized 中的文件视为已处理，fail 中的文件处理失败
+type recordingProcessor struct {
+	mu         sync.Mutex
+	calls      map[string]int
+	recognized map[string]bool
+	fail       map[string]bool
+}
+
+func (p *recordingProcessor) ProcessFile(filePath string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.calls[filepath.Base(filePath)]++
+	return !p.fail[filepath.Base(filePath)]
+}
+
+func (p *recordingProcessor) IsRecognizedFile(filePath string) bool {
+	return p.recognized[filepath.Base(filePath)]
+}
+
+func (p *recordingProcessor) callCounts() map[string]int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	counts := make(map[string]int, len(p.calls))
+	for name, n := range p.calls {
+		counts[name] = n
+	}
+	return counts
+}
+
+// TestRestoreQueue 测试启动时恢复上次未处理完的文件（保留失败次数），并扫描文件夹处理其余未处理的文件
+func TestRestoreQueue(t *testing.T) {
+	mediaDir := t.TempDir()
+	queueFile := filepath.Join(t.TempDir(), QueueFileName)
+	for _, name := range []string{"retry.mp4", "done.mp4", "forced.mp4", "new.mp4", "old.mp4"} {
+		if err := os.WriteFile(filepath.Join(mediaDir, name), []byte("video"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	saved := []journalEntry{
+		{Path: filepath.Join(mediaDir, "retry.mp4"), Attempts: 2, QueuedAt: time.Now()},
+		{Path: filepath.Join(mediaDir, "gone.mp4"), QueuedAt: time.Now()},
+		{Path: filepath.Join(mediaDir, "done.mp4"), QueuedAt: time.Now()},
+		{Path: filepath.Join(mediaDir, "forced.mp4"), Force: true, QueuedAt: time.Now()},
+	}
+	data, _ := json.Marshal(saved)
+	if err := os.WriteFile(queueFile, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	processor := &recordingProcessor{
+		calls:      make(map[string]int),
+		recognized: map[string]bool{"done.mp4": true, "forced.mp4": true, "old.mp4": true},
+		fail:       map[string]bool{"retry.mp4": true},
+	}
+	monitor, err := NewMediaFolderMonitor(mediaDir, processor, nil)
+	if err != nil {
+		t.Fatalf("创建监控器失败: %v", err)
+	}
+	monitor.stability = StabilityPolicy{}
+	monitor.SetQueueReportInterval(0)
+	monitor.SetRetryPolicy(3, 10*time.Millisecond, filepath.Join(t.TempDir(), "quarantine"))
+	if err := monitor.SetQueueFile(queueFile); err != nil {
+		t.Fatalf("读取监听队列失败: %v", err)
+	}
+	if err := monitor.Start(); err != nil {
+		t.Fatalf("启动监控器失败: %v", err)
+	}
+	defer monitor.Stop()
+
+	// 之前已失败 2 次的文件再失败 1 次即被隔离
+	deadline := time.Now().Add(3 * time.Second)
+	for time.Now().Before(deadline) {
+		if _, err := os.Stat(filepath.Join(mediaDir, "retry.mp4")); os.IsNotExist(err) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	want := map[string]int{"retry.mp4": 1, "forced.mp4": 1, "new.mp4": 1}
+	calls := processor.callCounts()
+	if len(calls) != len(want) {
+		t.Fatalf("期望处理 %v，实际 %v", want, calls)
+	}
+	for name, n := range want {
+		if calls[name] != n {
+			t.Fatalf("期望处理 %v，实际 %v", want, calls)
+		}
+	}
+
+	reloaded, err := loadQueueJournal(queueFile)
+	if err != nil {
+		t.Fatalf("读取监听队列失败: %v", err)
+	}
+	if entries := reloaded.list(); len(entries) != 0 {
+		t.Fatalf("处理结束后不应保留记录，实际: %+v", entries)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/ccp-p/asr-media-cli/audio-processor/internal/ui"
 	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/audio"
 	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/models"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/tempdir"
 	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/utils"
 )
 
@@ -22,11 +23,16 @@ type MediaWatcher struct {
 
 // NewMediaWatcher 创建媒体文件监控器
 func NewMediaWatcher(config *models.Config, progressManager *ui.ProgressManager) (*MediaWatcher, error) {
+	tempRoot := config.TempDir
+	if tempRoot == "" {
+		tempRoot = tempdir.DefaultRoot()
+	}
+
 	// 创建处理器适配器
 	processor := audio.NewBatchProcessor(
 		config.MediaFolder,
 		config.OutputFolder,
-		filepath.Join(config.TempDir, "watch_temp"),
+		filepath.Join(tempRoot, "watch_temp"),
 		nil, // 不使用回调，依赖进度条系统
 		config,
 	)
@@ -95,7 +101,7 @@ func NewWatchManager(config *models.Config, progressManager *ui.ProgressManager)
 		return nil, err
 	}
 	
-	segmentMonitor := NewSegmentProgressMonitor(config.TempDir, progressManager)
+	segmentMonitor := NewSegmentProgressMonitor(progressManager)
 	
 	return &WatchManager{
 		mediaWatcher:    mediaWatcher,
@@ -2,99 +2,126 @@ package watcher
 
 import (
 	"fmt"
-	"os"
 	"path/filepath"
-	"time"
+	"sync"
 
 	"github.com/ccp-p/asr-media-cli/audio-processor/internal/ui"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/audio"
 	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/utils"
 )
 
-// SegmentProgressMonitor 监控片段处理进度
+// 片段监控进度条ID
+const segmentMonitorProgressID = "segments_monitor"
+
+// segmentFileProgress 单个文件的分割进度
+type segmentFileProgress struct {
+	completed int
+	total     int
+}
+
+// SegmentProgressMonitor 订阅音频提取器发布的分割事件，汇总所有正在分割的文件的片段进度
 type SegmentProgressMonitor struct {
-	TempDir        string
 	ProgressManager *ui.ProgressManager
-	StopChan       chan struct{}
-	Interval       time.Duration
+
+	mu          sync.Mutex
+	files       map[string]*segmentFileProgress // 正在分割的文件
+	exported    int                             // 已完成文件导出的片段数
+	unsubscribe func()
 }
 
 // NewSegmentProgressMonitor 创建新的片段进度监控器
-func NewSegmentProgressMonitor(tempDir string, progressManager *ui.ProgressManager) *SegmentProgressMonitor {
+func NewSegmentProgressMonitor(progressManager *ui.ProgressManager) *SegmentProgressMonitor {
 	return &SegmentProgressMonitor{
-		TempDir:        tempDir,
 		ProgressManager: progressManager,
-		StopChan:       make(chan struct{}),
-		Interval:       2 * time.Second, // 默认2秒检查一次
+		files:           make(map[string]*segmentFileProgress),
 	}
 }
 
 // Start 开始监控
 func (m *SegmentProgressMonitor) Start() {
-	go m.monitorRoutine()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.unsubscribe == nil {
+		m.unsubscribe = audio.SubscribeSegmentEvents(m.handleEvent)
+	}
 }
 
 // Stop 停止监控
 func (m *SegmentProgressMonitor) Stop() {
-	close(m.StopChan)
+	m.mu.Lock()
+	unsubscribe := m.unsubscribe
+	m.unsubscribe = nil
+	m.mu.Unlock()
+
+	if unsubscribe != nil {
+		unsubscribe()
+	}
 }
 
-// monitorRoutine 监控协程
-func (m *SegmentProgressMonitor) monitorRoutine() {
-	ticker := time.NewTicker(m.Interval)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-ticker.C:
-			m.checkSegments()
-		case <-m.StopChan:
-			return
-		}
+// Progress 返回正在分割的文件数，以及这些文件已导出与预计的片段总数
+func (m *SegmentProgressMonitor) Progress() (active, completed, total int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.progressLocked()
+}
+
+// progressLocked 汇总各文件进度，调用方需持有锁
+func (m *SegmentProgressMonitor) progressLocked() (active, completed, total int) {
+	for _, file := range m.files {
+		completed += file.completed
+		total += file.total
 	}
+	return len(m.files), completed, total
 }
 
-// checkSegments 检查片段处理状态
-func (m *SegmentProgressMonitor) checkSegments() {
-	if m.ProgressManager == nil {
-		return
+// handleEvent 处理分割事件并刷新监控进度条
+func (m *SegmentProgressMonitor) handleEvent(event audio.SegmentEvent) {
+	m.mu.Lock()
+	switch event.Type {
+	case audio.SegmentSplitStarted:
+		m.files[event.File] = &segmentFileProgress{total: event.Total}
+	case audio.SegmentExported:
+		file, ok := m.files[event.File]
+		if !ok {
+			file = &segmentFileProgress{}
+			m.files[event.File] = file
+		}
+		file.completed = event.Completed
+		file.total = event.Total
+	case audio.SegmentSplitFinished:
+		delete(m.files, event.File)
+		m.exported += event.Completed
+	case audio.SegmentSplitFailed:
+		delete(m.files, event.File)
+		utils.Debug("片段监控: %s 分割失败: %v", filepath.Base(event.File), event.Err)
 	}
+	active, completed, total := m.progressLocked()
+	exported := m.exported
+	m.mu.Unlock()
 
-	// 创建监控进度条
-	progressID := "segments_monitor"
-	
-	// 检查临时目录中的片段文件
-	segmentsDir := filepath.Join(m.TempDir, "segments")
-	
-	// 确保目录存在
-	if _, err := os.Stat(segmentsDir); os.IsNotExist(err) {
+	if m.ProgressManager == nil {
 		return
 	}
-	
-	// 获取目录下的所有文件
-	files, err := os.ReadDir(segmentsDir)
-	if err != nil {
-		utils.Debug("监控临时片段目录失败: %v", err)
-		return
+	if m.ProgressManager.GetProgressBar(segmentMonitorProgressID) == nil {
+		m.ProgressManager.CreateProgressBar(segmentMonitorProgressID, 100, "片段监控", "等待处理开始...")
 	}
-	
-	// 按类型统计文件
-	wavCount := 0
-	for _, file := range files {
-		if !file.IsDir() && filepath.Ext(file.Name()) == ".wav" {
-			wavCount++
-		}
+
+	if active == 0 {
+		m.ProgressManager.UpdateProgressBar(segmentMonitorProgressID, 100,
+			fmt.Sprintf("已生成 %d 个音频片段", exported))
+		return
 	}
-	
-	// 只有当有片段时才更新进度条
-	if wavCount > 0 {
-		m.ProgressManager.UpdateProgressBar(progressID, wavCount, 
-			fmt.Sprintf("已生成 %d 个音频片段", wavCount))
+	percent := 0
+	if total > 0 {
+		percent = completed * 100 / total
 	}
+	m.ProgressManager.UpdateProgressBar(segmentMonitorProgressID, percent,
+		fmt.Sprintf("%d 个文件分割中，已生成 %d/%d 个片段", active, completed, total))
 }
 
-// StartMonitoring 便捷函数：开始监控并返回停止函数
-func StartSegmentMonitoring(tempDir string, progressManager *ui.ProgressManager) func() {
-	monitor := NewSegmentProgressMonitor(tempDir, progressManager)
+// StartSegmentMonitoring 便捷函数：开始监控并返回停止函数
+func StartSegmentMonitoring(progressManager *ui.ProgressManager) func() {
+	monitor := NewSegmentProgressMonitor(progressManager)
 	monitor.Start()
 	return monitor.Stop
 }
@@ -0,0 +1,33 @@
+package watcher
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/audio"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSegmentProgressMonitorConcurrentFiles(t *testing.T) {
+	m := NewSegmentProgressMonitor(nil)
+
+	m.handleEvent(audio.SegmentEvent{Type: audio.SegmentSplitStarted, File: "a.mp3", Total: 4})
+	m.handleEvent(audio.SegmentEvent{Type: audio.SegmentSplitStarted, File: "b.mp3", Total: 6})
+	m.handleEvent(audio.SegmentEvent{Type: audio.SegmentExported, File: "a.mp3", Completed: 1, Total: 4})
+	m.handleEvent(audio.SegmentEvent{Type: audio.SegmentExported, File: "b.mp3", Completed: 1, Total: 6})
+	m.handleEvent(audio.SegmentEvent{Type: audio.SegmentExported, File: "a.mp3", Completed: 2, Total: 4})
+
+	active, completed, total := m.Progress()
+	assert.Equal(t, 2, active)
+	assert.Equal(t, 3, completed)
+	assert.Equal(t, 10, total)
+
+	m.handleEvent(audio.SegmentEvent{Type: audio.SegmentSplitFinished, File: "a.mp3", Completed: 4, Total: 4})
+	m.handleEvent(audio.SegmentEvent{Type: audio.SegmentSplitFailed, File: "b.mp3", Total: 6, Err: errors.New("ffmpeg")})
+
+	active, completed, total = m.Progress()
+	assert.Equal(t, 0, active)
+	assert.Equal(t, 0, completed)
+	assert.Equal(t, 0, total)
+	assert.Equal(t, 4, m.exported)
+}
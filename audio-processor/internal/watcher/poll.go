@@ -0,0 +1,79 @@
+package watcher
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/utils"
+)
+
+// fileState 轮询时记录的文件状态
+type fileState struct {
+	size    int64
+	modTime int64 // 修改时间（纳秒）
+}
+
+// pollLoop 定期扫描监控范围，按文件大小与修改时间找出新增或变化的文件，
+// 用于 fsnotify 收不到事件的网络驱动器。启动时已有的文件由 processExistingFiles 处理
+func (m *FolderMonitor) pollLoop() {
+	known, err := m.scanFiles()
+	if err != nil {
+		utils.Warn("扫描文件夹失败: %v", err)
+	}
+	ticker := time.NewTicker(m.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.stopChan:
+			return
+		case <-ticker.C:
+		}
+
+		current, err := m.scanFiles()
+		if err != nil {
+			// 网络断开时保留上次的结果，恢复后不会把全部文件当作新文件
+			utils.Debug("扫描文件夹失败: %v", err)
+			continue
+		}
+		for path, state := range current {
+			if previous, exists := known[path]; exists && previous == state {
+				continue
+			}
+			if m.scope.includeFile(path) {
+				m.schedule(path)
+			}
+		}
+		known = current
+	}
+}
+
+// scanFiles 返回监控范围内各媒体文件的状态，无法读取监控目录时返回错误
+func (m *FolderMonitor) scanFiles() (map[string]fileState, error) {
+	files := make(map[string]fileState)
+	err := m.scope.walk(func(dir string) error {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			if dir == m.folderPath {
+				return err
+			}
+			return nil
+		}
+		for _, entry := range entries {
+			if !entry.Type().IsRegular() || !m.isMediaName(entry.Name()) {
+				continue
+			}
+			info, err := entry.Info()
+			if err != nil {
+				continue
+			}
+			files[filepath.Join(dir, entry.Name())] = fileState{size: info.Size(), modTime: info.ModTime().UnixNano()}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
@@ -0,0 +1,71 @@
+package watcher
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestPollingMonitor 测试定期扫描发现新文件（包括新建子目录中的文件），以及注册系统文件监控失败时自动改为扫描
+func TestPollingMonitor(t *testing.T) {
+	cases := []struct {
+		name  string
+		setup func(m *FolderMonitor)
+	}{
+		{"poll", func(m *FolderMonitor) { m.SetPolling(true, 20*time.Millisecond) }},
+		{"fallback", func(m *FolderMonitor) {
+			m.SetPolling(false, 20*time.Millisecond)
+			// 关闭后再注册目录会失败
+			m.watcher.Close()
+		}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			mediaDir := t.TempDir()
+			processor := &recordingProcessor{calls: make(map[string]int)}
+			monitor, err := NewMediaFolderMonitor(mediaDir, processor, nil)
+			if err != nil {
+				t.Fatalf("创建监控器失败: %v", err)
+			}
+			monitor.stability = StabilityPolicy{}
+			monitor.debounceTime = 10 * time.Millisecond
+			monitor.SetQueueReportInterval(0)
+			monitor.SetScope(true, nil, nil)
+			c.setup(monitor)
+			if err := monitor.Start(); err != nil {
+				t.Fatalf("启动监控器失败: %v", err)
+			}
+			defer monitor.Stop()
+			if !monitor.poll || monitor.watcher != nil {
+				t.Fatal("应改为定期扫描")
+			}
+
+			// 等待第一次扫描完成，之后的文件才是新文件
+			time.Sleep(50 * time.Millisecond)
+			if err := os.MkdirAll(filepath.Join(mediaDir, "sub"), 0755); err != nil {
+				t.Fatal(err)
+			}
+			for _, name := range []string{"a.mp4", filepath.Join("sub", "b.mp3"), "notes.txt"} {
+				if err := os.WriteFile(filepath.Join(mediaDir, name), []byte("media"), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			deadline := time.Now().Add(3 * time.Second)
+			for time.Now().Before(deadline) && len(processor.callCounts()) < 2 {
+				time.Sleep(10 * time.Millisecond)
+			}
+			// 已处理的文件再次变化不会重复处理
+			if err := os.WriteFile(filepath.Join(mediaDir, "a.mp4"), []byte("media, changed"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			time.Sleep(100 * time.Millisecond)
+
+			counts := processor.callCounts()
+			if len(counts) != 2 || counts["a.mp4"] != 1 || counts["b.mp3"] != 1 {
+				t.Fatalf("期望 a.mp4 与 b.mp3 各处理 1 次，实际 %v", counts)
+			}
+		})
+	}
+}
@@ -0,0 +1,319 @@
+package watcher
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// QueueState 监听队列中文件的状态
+type QueueState string
+
+const (
+	QueueWaiting     QueueState = "waiting"     // 等待处理（包括等待处理槽位）
+	QueueScheduled   QueueState = "scheduled"   // 等待允许处理的时间窗口
+	QueueRetrying    QueueState = "retrying"    // 失败后等待重试
+	QueueProcessing  QueueState = "processing"  // 正在处理
+	QueueCompleted   QueueState = "completed"   // 处理成功
+	QueueFailed      QueueState = "failed"      // 处理失败
+	QueueQuarantined QueueState = "quarantined" // 多次失败后已隔离
+)
+
+// 最近完成/失败记录的保留数量
+const maxRecentEntries = 20
+
+// QueueEntry 队列中单个文件的状态
+type QueueEntry struct {
+	Path        string     `json:"path"`
+	Name        string     `json:"name"`
+	State       QueueState `json:"state"`
+	Attempt     int        `json:"attempt"`
+	Progress    int        `json:"progress"`
+	Message     string     `json:"message,omitempty"`
+	QueuedAt    time.Time  `json:"queued_at"`
+	StartedAt   time.Time  `json:"started_at,omitempty"`
+	FinishedAt  time.Time  `json:"finished_at,omitempty"`
+	NextAttempt time.Time  `json:"next_attempt,omitempty"`
+}
+
+// QueueSnapshot 某一时刻的队列视图
+type QueueSnapshot struct {
+	Waiting    []QueueEntry `json:"waiting"`
+	Processing []QueueEntry `json:"processing"`
+	Recent     []QueueEntry `json:"recent"`
+	Time       time.Time    `json:"time"`
+}
+
+// ProcessingQueue 记录监听模式下各文件的处理状态，供终端打印和HTTP接口展示
+type ProcessingQueue struct {
+	mu      sync.Mutex
+	active  map[string]*QueueEntry
+	recent  []QueueEntry
+	version int
+}
+
+// NewProcessingQueue 创建处理队列
+func NewProcessingQueue() *ProcessingQueue {
+	return &ProcessingQueue{
+		active: make(map[string]*QueueEntry),
+	}
+}
+
+// MarkWaiting 标记文件进入等待队列
+func (q *ProcessingQueue) MarkWaiting(path string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	entry, exists := q.active[path]
+	if !exists {
+		entry = &QueueEntry{
+			Path:     path,
+			Name:     filepath.Base(path),
+			QueuedAt: time.Now(),
+		}
+		q.active[path] = entry
+	}
+	if entry.State == QueueWaiting {
+		return
+	}
+	// 时间窗口开始后继续等待处理，不算一次新的尝试
+	if entry.State != QueueScheduled {
+		entry.Attempt++
+	}
+	entry.State = QueueWaiting
+	entry.Progress = 0
+	entry.Message = ""
+	entry.NextAttempt = time.Time{}
+	q.version++
+}
+
+// UpdateProgress 更新文件处理进度，首次收到进度时文件转为处理中
+func (q *ProcessingQueue) UpdateProgress(path string, percent int, message string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	entry, exists := q.active[path]
+	if !exists {
+		return
+	}
+	if entry.State != QueueProcessing {
+		entry.State = QueueProcessing
+		entry.StartedAt = time.Now()
+		q.version++
+	}
+	entry.Progress = percent
+	entry.Message = message
+}
+
+// MarkScheduled 标记文件等待允许处理的时间窗口，start 为窗口开始时间
+func (q *ProcessingQueue) MarkScheduled(path string, start time.Time) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	entry, exists := q.active[path]
+	if !exists {
+		return
+	}
+	entry.State = QueueScheduled
+	entry.NextAttempt = start
+	entry.Message = "等待处理时间窗口"
+	q.version++
+}
+
+// MarkRetrying 标记文件处理失败并等待重试
+func (q *ProcessingQueue) MarkRetrying(path string, next time.Time, message string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	entry, exists := q.active[path]
+	if !exists {
+		return
+	}
+	entry.State = QueueRetrying
+	entry.NextAttempt = next
+	entry.Message = message
+	q.version++
+}
+
+// MarkFinished 标记文件处理结束，移入最近记录
+func (q *ProcessingQueue) MarkFinished(path string, state QueueState, message string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	entry, exists := q.active[path]
+	if !exists {
+		entry = &QueueEntry{Path: path, Name: filepath.Base(path), QueuedAt: time.Now()}
+	}
+	delete(q.active, path)
+
+	entry.State = state
+	entry.Message = message
+	entry.FinishedAt = time.Now()
+	if state == QueueCompleted {
+		entry.Progress = 100
+	}
+
+	q.recent = append([]QueueEntry{*entry}, q.recent...)
+	if len(q.recent) > maxRecentEntries {
+		q.recent = q.recent[:maxRecentEntries]
+	}
+	q.version++
+}
+
+// Remove 从队列中移除文件（例如文件已被删除）
+func (q *ProcessingQueue) Remove(path string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if _, exists := q.active[path]; exists {
+		delete(q.active, path)
+		q.version++
+	}
+}
+
+// State 返回文件在队列中的当前状态，不在队列中时返回 false
+func (q *ProcessingQueue) State(path string) (QueueState, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	entry, exists := q.active[path]
+	if !exists {
+		return "", false
+	}
+	return entry.State, true
+}
+
+// Snapshot 返回当前队列视图
+func (q *ProcessingQueue) Snapshot() QueueSnapshot {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	snapshot := QueueSnapshot{
+		Waiting:    make([]QueueEntry, 0),
+		Processing: make([]QueueEntry, 0),
+		Recent:     append([]QueueEntry{}, q.recent...),
+		Time:       time.Now(),
+	}
+	for _, entry := range q.active {
+		if entry.State == QueueProcessing {
+			snapshot.Processing = append(snapshot.Processing, *entry)
+		} else {
+			snapshot.Waiting = append(snapshot.Waiting, *entry)
+		}
+	}
+
+	sort.Slice(snapshot.Waiting, func(i, j int) bool {
+		return snapshot.Waiting[i].QueuedAt.Before(snapshot.Waiting[j].QueuedAt)
+	})
+	sort.Slice(snapshot.Processing, func(i, j int) bool {
+		return snapshot.Processing[i].StartedAt.Before(snapshot.Processing[j].StartedAt)
+	})
+
+	return snapshot
+}
+
+// changedSince 返回队列自指定版本后是否有变化，以及当前版本号
+func (q *ProcessingQueue) changedSince(version int) (bool, int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.version != version, q.version
+}
+
+// Format 将队列视图格式化为终端可读的文本
+func (s QueueSnapshot) Format() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "===== 监听队列 %s =====\n", s.Time.Format("15:04:05"))
+	fmt.Fprintf(&b, "处理中 (%d):\n", len(s.Processing))
+	for _, e := range s.Processing {
+		fmt.Fprintf(&b, "  - %s %3d%% %s (已用时 %s)\n",
+			e.Name, e.Progress, e.Message, s.Time.Sub(e.StartedAt).Round(time.Second))
+	}
+
+	fmt.Fprintf(&b, "等待中 (%d):\n", len(s.Waiting))
+	for _, e := range s.Waiting {
+		switch e.State {
+		case QueueRetrying:
+			fmt.Fprintf(&b, "  - %s 第 %d 次失败，%s 后重试\n",
+				e.Name, e.Attempt, e.NextAttempt.Sub(s.Time).Round(time.Second))
+		case QueueScheduled:
+			fmt.Fprintf(&b, "  - %s 等待处理时间窗口，%s 开始\n", e.Name, e.NextAttempt.Format("01-02 15:04"))
+		default:
+			fmt.Fprintf(&b, "  - %s (已等待 %s)\n", e.Name, s.Time.Sub(e.QueuedAt).Round(time.Second))
+		}
+	}
+
+	fmt.Fprintf(&b, "最近完成 (%d):\n", len(s.Recent))
+	for _, e := range s.Recent {
+		status := "成功"
+		switch e.State {
+		case QueueFailed:
+			status = "失败"
+		case QueueQuarantined:
+			status = "已隔离"
+		}
+		line := fmt.Sprintf("  - [%s] %s %s", e.FinishedAt.Format("15:04:05"), status, e.Name)
+		if e.Message != "" {
+			line += ": " + e.Message
+		}
+		b.WriteString(line + "\n")
+	}
+
+	return b.String()
+}
+
+// enqueueRequest 手动入队请求
+type enqueueRequest struct {
+	Path string `json:"path"`
+}
+
+// EnqueueHandler 返回手动将文件加入处理队列的HTTP处理器，请求体为 {"path": "..."}
+func (m *FolderMonitor) EnqueueHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "仅支持POST请求", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var req enqueueRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, fmt.Sprintf("解析请求失败: %v", err), http.StatusBadRequest)
+			return
+		}
+
+		path, err := m.Enqueue(req.Path)
+		if err != nil {
+			status := http.StatusBadRequest
+			if errors.Is(err, ErrAlreadyQueued) {
+				status = http.StatusConflict
+			}
+			http.Error(w, err.Error(), status)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(map[string]string{
+			"path":   path,
+			"status": string(QueueWaiting),
+		})
+	})
+}
+
+// QueueHandler 返回以JSON输出队列视图的HTTP处理器，用于仪表盘展示
+func (m *FolderMonitor) QueueHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "仅支持GET请求", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		json.NewEncoder(w).Encode(m.queue.Snapshot())
+	})
+}
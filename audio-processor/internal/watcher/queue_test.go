@@ -0,0 +1,43 @@
+package watcher
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProcessingQueueLifecycle(t *testing.T) {
+	q := NewProcessingQueue()
+
+	q.MarkWaiting("/media/a.mp4")
+	q.MarkWaiting("/media/b.mp4")
+
+	snapshot := q.Snapshot()
+	if len(snapshot.Waiting) != 2 || len(snapshot.Processing) != 0 {
+		t.Fatalf("期望2个等待文件，实际 等待=%d 处理中=%d", len(snapshot.Waiting), len(snapshot.Processing))
+	}
+
+	// 收到进度后转为处理中
+	q.UpdateProgress("/media/a.mp4", 40, "语音识别")
+	snapshot = q.Snapshot()
+	if len(snapshot.Processing) != 1 || snapshot.Processing[0].Progress != 40 {
+		t.Fatalf("期望a.mp4处理中且进度为40，实际: %+v", snapshot.Processing)
+	}
+
+	q.MarkFinished("/media/a.mp4", QueueCompleted, "")
+	q.MarkRetrying("/media/b.mp4", time.Now().Add(time.Minute), "第 1/3 次处理失败")
+
+	snapshot = q.Snapshot()
+	if len(snapshot.Processing) != 0 || len(snapshot.Waiting) != 1 {
+		t.Fatalf("期望仅剩1个等待重试的文件，实际 等待=%d 处理中=%d", len(snapshot.Waiting), len(snapshot.Processing))
+	}
+	if snapshot.Waiting[0].State != QueueRetrying {
+		t.Fatalf("期望b.mp4处于重试状态，实际: %s", snapshot.Waiting[0].State)
+	}
+	if len(snapshot.Recent) != 1 || snapshot.Recent[0].State != QueueCompleted || snapshot.Recent[0].Progress != 100 {
+		t.Fatalf("最近完成记录不正确: %+v", snapshot.Recent)
+	}
+	if !strings.Contains(snapshot.Format(), "a.mp4") {
+		t.Fatal("队列文本视图应包含已完成的文件")
+	}
+}
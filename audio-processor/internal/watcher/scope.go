@@ -0,0 +1,137 @@
+package watcher
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// watchScope 决定监控哪些子目录与文件：include 为空时包含全部文件，exclude 匹配的文件与目录被跳过，
+// 隐藏目录总是跳过。模式为不含 / 的通配符时匹配文件或目录名，含 / 时匹配相对监控目录的路径，
+// ** 匹配任意层目录（如 courses/**/*.mp4）
+type watchScope struct {
+	root      string
+	recursive bool
+	include   []string
+	exclude   []string
+	skipDirs  []string // 总是跳过的目录（如隔离目录），绝对路径
+}
+
+// relative 返回相对监控目录、以 / 分隔的路径，不在监控目录下时返回 false
+func (s *watchScope) relative(filePath string) (string, bool) {
+	rel, err := filepath.Rel(s.root, filePath)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return filepath.ToSlash(rel), true
+}
+
+// watchDir 判断是否监控目录，根目录总是监控
+func (s *watchScope) watchDir(dir string) bool {
+	rel, ok := s.relative(dir)
+	if !ok {
+		return false
+	}
+	if rel == "." {
+		return true
+	}
+	if !s.recursive {
+		return false
+	}
+	if absDir, err := filepath.Abs(dir); err == nil {
+		for _, skip := range s.skipDirs {
+			if absSkip, err := filepath.Abs(skip); err == nil && absSkip == absDir {
+				return false
+			}
+		}
+	}
+	for _, name := range strings.Split(rel, "/") {
+		if strings.HasPrefix(name, ".") {
+			return false
+		}
+	}
+	return !matchAny(s.exclude, rel)
+}
+
+// includeFile 判断文件是否在监控范围内：所在目录被监控、未被排除，设置了 include 时需要匹配
+func (s *watchScope) includeFile(filePath string) bool {
+	rel, ok := s.relative(filePath)
+	if !ok || !s.watchDir(filepath.Dir(filePath)) {
+		return false
+	}
+	if matchAny(s.exclude, rel) {
+		return false
+	}
+	return len(s.include) == 0 || matchAny(s.include, rel)
+}
+
+// walk 遍历监控范围内的目录，对每个目录调用 fn（包括根目录）
+func (s *watchScope) walk(fn func(dir string) error) error {
+	return filepath.WalkDir(s.root, func(p string, entry os.DirEntry, err error) error {
+		if err != nil {
+			if p == s.root {
+				return err
+			}
+			// 无法读取的子目录跳过
+			return filepath.SkipDir
+		}
+		if !entry.IsDir() {
+			return nil
+		}
+		if !s.watchDir(p) {
+			return filepath.SkipDir
+		}
+		return fn(p)
+	})
+}
+
+// matchAny 判断相对路径是否匹配任一模式
+func matchAny(patterns []string, rel string) bool {
+	for _, pattern := range patterns {
+		if matchPattern(pattern, rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchPattern 不含 / 的模式匹配路径中任一层的名称，含 / 的模式匹配整个相对路径
+func matchPattern(pattern, rel string) bool {
+	pattern = strings.Trim(filepath.ToSlash(pattern), "/")
+	if pattern == "" {
+		return false
+	}
+	parts := strings.Split(rel, "/")
+	if !strings.Contains(pattern, "/") {
+		for _, name := range parts {
+			if ok, _ := path.Match(pattern, name); ok {
+				return true
+			}
+		}
+		return false
+	}
+	return matchSegments(strings.Split(pattern, "/"), parts)
+}
+
+// matchSegments 逐层匹配路径，** 匹配零或多层
+func matchSegments(pattern, parts []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(parts); i++ {
+				if matchSegments(pattern[1:], parts[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(parts) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], parts[0]); !ok {
+			return false
+		}
+		pattern, parts = pattern[1:], parts[1:]
+	}
+	return len(parts) == 0
+}
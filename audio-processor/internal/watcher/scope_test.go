@@ -0,0 +1,107 @@
+package watcher
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestMatchPattern(t *testing.T) {
+	cases := []struct {
+		pattern string
+		rel     string
+		want    bool
+	}{
+		{"temp", "temp", true},
+		{"temp", "a/temp/b.mp4", true},
+		{"temp", "a/template/b.mp4", false},
+		{"*.part", "a/b.mp4.part", true},
+		{"courses/*.mp4", "courses/a.mp4", true},
+		{"courses/*.mp4", "courses/x/a.mp4", false},
+		{"courses/**/*.mp4", "courses/a.mp4", true},
+		{"courses/**/*.mp4", "courses/x/y/a.mp4", true},
+		{"**/dest", "a/b/dest", true},
+		{"", "a.mp4", false},
+	}
+	for _, c := range cases {
+		if got := matchPattern(c.pattern, c.rel); got != c.want {
+			t.Errorf("matchPattern(%q, %q) = %v, 期望 %v", c.pattern, c.rel, got, c.want)
+		}
+	}
+}
+
+func TestWatchScope(t *testing.T) {
+	root := filepath.Join("media")
+	scope := watchScope{root: root, exclude: []string{"dest", "temp"}, skipDirs: []string{filepath.Join(root, "quarantine")}}
+	if !scope.watchDir(root) {
+		t.Fatal("根目录总是监控")
+	}
+	if scope.watchDir(filepath.Join(root, "sub")) || scope.includeFile(filepath.Join(root, "sub", "a.mp4")) {
+		t.Fatal("未启用递归时不包含子目录")
+	}
+
+	scope.recursive = true
+	for _, dir := range []string{"sub/dest", "temp", ".cache", "quarantine", "../other"} {
+		if scope.watchDir(filepath.Join(root, filepath.FromSlash(dir))) {
+			t.Errorf("目录 %s 应被跳过", dir)
+		}
+	}
+	if !scope.includeFile(filepath.Join(root, "sub", "deep", "a.mp4")) {
+		t.Fatal("递归时包含子目录中的文件")
+	}
+
+	scope.include = []string{"lectures/**/*.mp4"}
+	if !scope.includeFile(filepath.Join(root, "lectures", "w1", "a.mp4")) || scope.includeFile(filepath.Join(root, "music", "a.mp4")) {
+		t.Fatal("设置 include 时只包含匹配的文件")
+	}
+}
+
+// TestRecursiveMonitor 测试递归监控时处理子目录中已有的文件与之后新建的子目录，跳过排除的目录
+func TestRecursiveMonitor(t *testing.T) {
+	mediaDir := t.TempDir()
+	for _, dir := range []string{"old", "temp"} {
+		if err := os.MkdirAll(filepath.Join(mediaDir, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(mediaDir, dir, "a.mp4"), []byte("video"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	processor := &countingProcessor{}
+	monitor, err := NewMediaFolderMonitor(mediaDir, processor, nil)
+	if err != nil {
+		t.Fatalf("创建监控器失败: %v", err)
+	}
+	monitor.stability = StabilityPolicy{}
+	monitor.debounceTime = 10 * time.Millisecond
+	monitor.SetQueueReportInterval(0)
+	monitor.SetScope(true, nil, []string{"temp"})
+	if err := monitor.Start(); err != nil {
+		t.Fatalf("启动监控器失败: %v", err)
+	}
+	defer monitor.Stop()
+
+	waitCalls := func(want int) {
+		deadline := time.Now().Add(3 * time.Second)
+		for time.Now().Before(deadline) && processor.callCount() < want {
+			time.Sleep(10 * time.Millisecond)
+		}
+		time.Sleep(50 * time.Millisecond)
+		if calls := processor.callCount(); calls != want {
+			t.Fatalf("期望处理 %d 次，实际 %d 次", want, calls)
+		}
+	}
+	waitCalls(1)
+
+	// 新建的子目录自动加入监控，其中已有的文件也会处理
+	newDir := filepath.Join(mediaDir, "new", "deep")
+	if err := os.MkdirAll(newDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(newDir, "b.mp4"), []byte("video"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	waitCalls(2)
+}
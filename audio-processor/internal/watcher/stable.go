@@ -0,0 +1,72 @@
+package watcher
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/utils"
+)
+
+// errStopped 监控已停止，不再等待文件写入完成
+var errStopped = errors.New("监控已停止")
+
+// StabilityPolicy 判断文件写入完成的策略：大小与修改时间连续 Checks 次检查不变，
+// 且（启用 Exclusive 时）可以独占打开，才认为下载或复制已完成
+type StabilityPolicy struct {
+	Checks    int           // 连续不变的检查次数，0 表示不等待
+	Interval  time.Duration // 检查间隔
+	Timeout   time.Duration // 最长等待时间，0 表示不限制
+	Exclusive bool          // 是否尝试独占打开文件，其他程序仍在写入时继续等待
+}
+
+// DefaultStabilityPolicy 返回默认的写入完成判断策略
+func DefaultStabilityPolicy() StabilityPolicy {
+	return StabilityPolicy{Checks: 3, Interval: 2 * time.Second}
+}
+
+// waitStable 等待文件写入完成。文件被删除时返回 os.ErrNotExist，stop 关闭时返回 errStopped，
+// 超过 Timeout 仍在变化时返回错误
+func waitStable(path string, policy StabilityPolicy, stop <-chan struct{}) error {
+	start := time.Now()
+	var last os.FileInfo
+	unchanged := 0
+	for {
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		if last != nil && info.Size() == last.Size() && info.ModTime().Equal(last.ModTime()) {
+			unchanged++
+		} else if last != nil {
+			unchanged = 0
+		}
+		last = info
+
+		if unchanged >= policy.Checks {
+			if !policy.Exclusive {
+				return nil
+			}
+			err := exclusiveOpen(path)
+			if err == nil {
+				return nil
+			}
+			if errors.Is(err, os.ErrNotExist) {
+				return err
+			}
+			utils.Debug("文件仍被其他程序占用: %s: %v", path, err)
+		}
+
+		if policy.Timeout > 0 && time.Since(start) >= policy.Timeout {
+			return fmt.Errorf("等待 %v 后文件仍在写入", policy.Timeout)
+		}
+		timer := time.NewTimer(policy.Interval)
+		select {
+		case <-stop:
+			timer.Stop()
+			return errStopped
+		case <-timer.C:
+		}
+	}
+}
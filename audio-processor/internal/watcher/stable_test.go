@@ -0,0 +1,64 @@
+package watcher
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestWaitStable 测试文件仍在写入时继续等待，停止写入后连续多次不变才返回
+func TestWaitStable(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "a.mp4")
+	if err := os.WriteFile(path, []byte("1"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	// 写入 200ms 后停止
+	go func() {
+		for i := 0; i < 20; i++ {
+			file.Write([]byte("x"))
+			time.Sleep(10 * time.Millisecond)
+		}
+	}()
+
+	start := time.Now()
+	policy := StabilityPolicy{Checks: 3, Interval: 30 * time.Millisecond}
+	if err := waitStable(path, policy, nil); err != nil {
+		t.Fatalf("等待写入完成失败: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+		t.Fatalf("文件仍在写入时不应返回，只等待了 %v", elapsed)
+	}
+	if info, _ := os.Stat(path); info.Size() != 21 {
+		t.Fatalf("返回时文件应已写完，大小 %d", info.Size())
+	}
+}
+
+func TestWaitStableErrors(t *testing.T) {
+	dir := t.TempDir()
+	if err := waitStable(filepath.Join(dir, "missing.mp4"), DefaultStabilityPolicy(), nil); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("期望文件不存在的错误，实际: %v", err)
+	}
+
+	path := filepath.Join(dir, "a.mp4")
+	if err := os.WriteFile(path, []byte("1"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	stop := make(chan struct{})
+	close(stop)
+	if err := waitStable(path, StabilityPolicy{Checks: 3, Interval: time.Hour}, stop); !errors.Is(err, errStopped) {
+		t.Fatalf("期望停止错误，实际: %v", err)
+	}
+
+	// 一直被占用时超时
+	if err := waitStable(path, StabilityPolicy{Checks: 100, Interval: 10 * time.Millisecond, Timeout: 50 * time.Millisecond}, nil); err == nil {
+		t.Fatal("超时应返回错误")
+	}
+}
@@ -0,0 +1,138 @@
+package watcher
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/schedule"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/utils"
+)
+
+// ScheduleStatus 处理时间窗口的状态
+type ScheduleStatus struct {
+	Schedule  []string  `json:"schedule"`             // 允许处理的时间窗口，为空表示不限制
+	Active    bool      `json:"active"`               // 当前是否在时间窗口内
+	NextStart time.Time `json:"next_start,omitempty"` // 不在窗口内时下一个窗口的开始时间
+	Scheduled int       `json:"scheduled"`            // 正在等待时间窗口的文件数
+	Released  int       `json:"released,omitempty"`   // process_now 立即放行的文件数
+}
+
+// SetSchedule 设置允许开始处理的时间窗口，为 nil 时不限制。窗口外检测到的文件照常排队，
+// 在队列中显示为等待时间窗口，窗口开始后再处理。应在 Start 之前调用
+func (m *FolderMonitor) SetSchedule(s *schedule.Schedule) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.window = s
+}
+
+// ProcessNow 立即处理正在等待时间窗口的文件，之后检测到的文件仍按时间窗口处理。返回放行的文件数
+func (m *FolderMonitor) ProcessNow() int {
+	released := m.scheduledCount()
+	m.mutex.Lock()
+	close(m.release)
+	m.release = make(chan struct{})
+	m.mutex.Unlock()
+	utils.Info("立即处理 %d 个等待时间窗口的文件", released)
+	return released
+}
+
+// ScheduleStatus 返回处理时间窗口的状态
+func (m *FolderMonitor) ScheduleStatus() ScheduleStatus {
+	m.mutex.Lock()
+	s := m.window
+	m.mutex.Unlock()
+
+	now := time.Now()
+	status := ScheduleStatus{
+		Schedule:  s.Specs(),
+		Active:    s.Active(now),
+		Scheduled: m.scheduledCount(),
+	}
+	if !status.Active {
+		status.NextStart = s.Next(now)
+	}
+	return status
+}
+
+// scheduledCount 返回正在等待时间窗口的文件数
+func (m *FolderMonitor) scheduledCount() int {
+	count := 0
+	for _, entry := range m.queue.Snapshot().Waiting {
+		if entry.State == QueueScheduled {
+			count++
+		}
+	}
+	return count
+}
+
+// waitWindow 不在允许处理的时间窗口内时等待窗口开始或 ProcessNow 放行，监控停止时返回 errStopped
+func (m *FolderMonitor) waitWindow(path string) error {
+	scheduled := false
+	for {
+		m.mutex.Lock()
+		s, release := m.window, m.release
+		m.mutex.Unlock()
+
+		now := time.Now()
+		if s.Active(now) {
+			break
+		}
+		next := s.Next(now)
+		if !scheduled {
+			utils.Info("不在处理时间窗口内 (%s)，%s 开始处理: %s", s, next.Format("01-02 15:04"), path)
+			scheduled = true
+		}
+		m.queue.MarkScheduled(path, next)
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-m.stopChan:
+			timer.Stop()
+			return errStopped
+		case <-release:
+			timer.Stop()
+			m.queue.MarkWaiting(path)
+			return nil
+		case <-timer.C:
+		}
+	}
+	if scheduled {
+		m.queue.MarkWaiting(path)
+	}
+	return nil
+}
+
+// scheduleRequest 处理时间窗口控制请求
+type scheduleRequest struct {
+	Action string `json:"action"` // process_now
+}
+
+// ScheduleHandler 返回处理时间窗口接口：GET 查询状态，POST {"action":"process_now"} 立即处理等待时间窗口的文件
+func (m *FolderMonitor) ScheduleHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		released := 0
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPost:
+			var req scheduleRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				http.Error(w, fmt.Sprintf("解析请求失败: %v", err), http.StatusBadRequest)
+				return
+			}
+			if req.Action != "process_now" {
+				http.Error(w, fmt.Sprintf("未知操作: %s", req.Action), http.StatusBadRequest)
+				return
+			}
+			released = m.ProcessNow()
+		default:
+			http.Error(w, "仅支持GET与POST请求", http.StatusMethodNotAllowed)
+			return
+		}
+		status := m.ScheduleStatus()
+		status.Released = released
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		json.NewEncoder(w).Encode(status)
+	})
+}
@@ -0,0 +1,101 @@
+package watcher
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/schedule"
+)
+
+// TestScheduleWindow 测试时间窗口外检测到的文件排队等待，process_now 只放行当时正在等待的文件
+func TestScheduleWindow(t *testing.T) {
+	// 两小时后开始的窗口，测试期间不会进入
+	now := time.Now()
+	spec := now.Add(2*time.Hour).Format("15:04") + "-" + now.Add(3*time.Hour).Format("15:04")
+	window, err := schedule.Parse([]string{spec})
+	if err != nil {
+		t.Fatalf("解析时间窗口失败: %v", err)
+	}
+
+	mediaDir := t.TempDir()
+	processor := &recordingProcessor{calls: make(map[string]int)}
+	monitor, err := NewMediaFolderMonitor(mediaDir, processor, nil)
+	if err != nil {
+		t.Fatalf("创建监控器失败: %v", err)
+	}
+	monitor.stability = StabilityPolicy{}
+	monitor.debounceTime = 10 * time.Millisecond
+	monitor.SetQueueReportInterval(0)
+	monitor.SetSchedule(window)
+	if err := monitor.Start(); err != nil {
+		t.Fatalf("启动监控器失败: %v", err)
+	}
+	defer monitor.Stop()
+	server := httptest.NewServer(monitor.ScheduleHandler())
+	defer server.Close()
+
+	waitScheduled := func(n int) {
+		t.Helper()
+		deadline := time.Now().Add(3 * time.Second)
+		for time.Now().Before(deadline) && monitor.scheduledCount() < n {
+			time.Sleep(10 * time.Millisecond)
+		}
+		if count := monitor.scheduledCount(); count != n {
+			t.Fatalf("期望 %d 个文件等待时间窗口，实际 %d 个", n, count)
+		}
+	}
+
+	if err := os.WriteFile(filepath.Join(mediaDir, "a.mp4"), []byte("video"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	waitScheduled(1)
+	if counts := processor.callCounts(); len(counts) != 0 {
+		t.Fatalf("时间窗口外不应处理文件，实际 %v", counts)
+	}
+	if waiting := monitor.Snapshot().Waiting; len(waiting) != 1 || !waiting[0].NextAttempt.Equal(window.Next(now)) {
+		t.Fatalf("队列中应显示窗口开始时间，实际 %+v", waiting)
+	}
+
+	var status ScheduleStatus
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	json.NewDecoder(resp.Body).Decode(&status)
+	resp.Body.Close()
+	if status.Active || status.Scheduled != 1 || status.NextStart.IsZero() {
+		t.Fatalf("时间窗口状态不正确: %+v", status)
+	}
+
+	resp, err = http.Post(server.URL, "application/json", strings.NewReader(`{"action":"process_now"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	json.NewDecoder(resp.Body).Decode(&status)
+	resp.Body.Close()
+	if status.Released != 1 {
+		t.Fatalf("期望放行 1 个文件，实际 %d 个", status.Released)
+	}
+	deadline := time.Now().Add(3 * time.Second)
+	for time.Now().Before(deadline) && processor.callCounts()["a.mp4"] == 0 {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if calls := processor.callCounts()["a.mp4"]; calls != 1 {
+		t.Fatalf("放行后应处理 1 次，实际 %d 次", calls)
+	}
+
+	// 之后检测到的文件仍等待时间窗口
+	if err := os.WriteFile(filepath.Join(mediaDir, "b.mp4"), []byte("video"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	waitScheduled(1)
+	if calls := processor.callCounts()["b.mp4"]; calls != 0 {
+		t.Fatalf("放行后新检测到的文件不应立即处理，实际处理 %d 次", calls)
+	}
+}
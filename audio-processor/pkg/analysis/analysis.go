@@ -0,0 +1,327 @@
+// Package analysis 从文稿中提取关键词、命名实体与主题标签，用于建立可检索的资料库。
+// 配置了大模型时由大模型提取，否则按 TF-IDF 在本地提取关键词（本地方式不识别命名实体）
+package analysis
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/models"
+)
+
+// 提取方式
+const (
+	MethodLLM   = "llm"
+	MethodTFIDF = "tfidf"
+)
+
+// 本地提取时作为主题标签的关键词数量
+const localTopics = 3
+
+// 提交给大模型的文稿上限（字符）
+const maxPromptChars = 100000
+
+// analysisPrompt 提取关键词与实体的系统提示
+const analysisPrompt = "你是一个资料整理员。下面是一段音视频的识别文稿，请提取用于检索的信息，" +
+	"只返回如下格式的JSON，不要输出其他内容：\n" +
+	`{"keywords": ["关键词"], "entities": [{"text": "名称", "type": "person|organization|location|product|other"}], "topics": ["主题标签"]}` + "\n" +
+	"关键词不超过 %d 个，按重要性排序；实体只保留文稿中明确出现的人名、机构、地点、产品等；主题标签 1 到 5 个，用简短的词语概括内容所属领域。"
+
+// LLM 提取关键词的大模型接口
+type LLM interface {
+	Chat(systemPrompt, content string) (string, error)
+}
+
+// Entity 命名实体
+type Entity struct {
+	Text string `json:"text"`
+	Type string `json:"type"` // person、organization、location、product 或 other
+}
+
+// Result 一个文件的分析结果
+type Result struct {
+	Method   string   `json:"method"` // MethodLLM 或 MethodTFIDF
+	Keywords []string `json:"keywords"`
+	Entities []Entity `json:"entities,omitempty"`
+	Topics   []string `json:"topics"`
+}
+
+// Options 分析选项
+type Options struct {
+	MaxKeywords int // 关键词数量上限
+}
+
+// OptionsFromConfig 从配置读取分析选项
+func OptionsFromConfig(config *models.Config) Options {
+	return Options{MaxKeywords: config.AnalysisKeywords}
+}
+
+// Analyze 分析文稿。llm 为 nil 或调用失败时在本地按 TF-IDF 提取关键词，
+// 返回的 error 只用于提示大模型失败的原因
+func Analyze(segments []models.DataSegment, llm LLM, opts Options) (*Result, error) {
+	texts := usableTexts(segments)
+	if len(texts) == 0 {
+		return nil, nil
+	}
+	if opts.MaxKeywords <= 0 {
+		opts.MaxKeywords = 10
+	}
+
+	var llmErr error
+	if llm != nil {
+		result, err := requestAnalysis(llm, texts, opts)
+		if err == nil {
+			return result, nil
+		}
+		llmErr = err
+	}
+	keywords := TFIDFKeywords(texts, opts.MaxKeywords)
+	topics := keywords
+	if len(topics) > localTopics {
+		topics = topics[:localTopics]
+	}
+	return &Result{
+		Method:   MethodTFIDF,
+		Keywords: keywords,
+		Topics:   append([]string{}, topics...),
+	}, llmErr
+}
+
+// usableTexts 返回有文字的段落文本
+func usableTexts(segments []models.DataSegment) []string {
+	var texts []string
+	for _, segment := range segments {
+		text := strings.TrimSpace(segment.Text)
+		if text == "" || text == "[无法识别的音频片段]" {
+			continue
+		}
+		texts = append(texts, text)
+	}
+	return texts
+}
+
+// requestAnalysis 请求大模型提取关键词、实体与主题
+func requestAnalysis(llm LLM, texts []string, opts Options) (*Result, error) {
+	content := strings.Join(texts, "\n")
+	if runes := []rune(content); len(runes) > maxPromptChars {
+		content = string(runes[:maxPromptChars])
+	}
+	reply, err := llm.Chat(fmt.Sprintf(analysisPrompt, opts.MaxKeywords), content)
+	if err != nil {
+		return nil, fmt.Errorf("大模型提取关键词失败: %w", err)
+	}
+	result, err := ParseReply(reply)
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Keywords) > opts.MaxKeywords {
+		result.Keywords = result.Keywords[:opts.MaxKeywords]
+	}
+	if len(result.Keywords) == 0 {
+		return nil, fmt.Errorf("大模型未返回关键词")
+	}
+	return result, nil
+}
+
+// ParseReply 解析大模型返回的JSON，容忍代码块包裹与前后的说明文字，去掉空项与重复项
+func ParseReply(reply string) (*Result, error) {
+	start := strings.Index(reply, "{")
+	end := strings.LastIndex(reply, "}")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("大模型未返回JSON: %q", reply)
+	}
+	var result Result
+	if err := json.Unmarshal([]byte(reply[start:end+1]), &result); err != nil {
+		return nil, fmt.Errorf("解析关键词失败: %w", err)
+	}
+	result.Method = MethodLLM
+	result.Keywords = dedupe(result.Keywords)
+	result.Topics = dedupe(result.Topics)
+
+	seen := make(map[string]bool)
+	entities := result.Entities[:0]
+	for _, entity := range result.Entities {
+		entity.Text = strings.TrimSpace(entity.Text)
+		entity.Type = normalizeType(entity.Type)
+		key := strings.ToLower(entity.Text)
+		if entity.Text == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		entities = append(entities, entity)
+	}
+	result.Entities = entities
+	return &result, nil
+}
+
+// normalizeType 将实体类型归一为已知类型
+func normalizeType(kind string) string {
+	kind = strings.ToLower(strings.TrimSpace(kind))
+	switch kind {
+	case "person", "organization", "location", "product":
+		return kind
+	}
+	return "other"
+}
+
+// dedupe 去掉空白项与重复项（不区分大小写），保持顺序
+func dedupe(items []string) []string {
+	seen := make(map[string]bool)
+	result := []string{}
+	for _, item := range items {
+		item = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(item), "#"))
+		key := strings.ToLower(item)
+		if item == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		result = append(result, item)
+	}
+	return result
+}
+
+// 常见的虚词，含有这些字的中文词组不作为关键词
+const stopChars = "的了是在我你他她它们这那有和与就不也都而及着过吧呢吗啊呀哦嗯个一么什怎会要说到把被让给很还又再才从对"
+
+// 常见的英文停用词
+var stopWords = map[string]bool{
+	"the": true, "and": true, "for": true, "that": true, "this": true, "with": true, "you": true,
+	"are": true, "was": true, "were": true, "have": true, "has": true, "had": true, "but": true,
+	"not": true, "what": true, "all": true, "can": true, "will": true, "just": true, "from": true,
+	"they": true, "there": true, "their": true, "about": true, "would": true, "which": true,
+	"when": true, "your": true, "our": true, "its": true, "into": true, "than": true, "then": true,
+	"them": true, "some": true, "also": true, "been": true, "more": true, "very": true, "like": true,
+	"okay": true, "yeah": true, "really": true, "know": true, "think": true, "going": true,
+}
+
+// terms 切分一段文字：英文按单词（至少 3 个字母），中文取连续汉字中长度 2 到 4 的词组
+func terms(text string) []string {
+	var result []string
+	var word, han []rune
+	flushWord := func() {
+		if len(word) >= 3 {
+			if w := strings.ToLower(string(word)); !stopWords[w] {
+				result = append(result, w)
+			}
+		}
+		word = word[:0]
+	}
+	flushHan := func() {
+		for n := 2; n <= 4; n++ {
+			for i := 0; i+n <= len(han); i++ {
+				gram := han[i : i+n]
+				if !strings.ContainsAny(string(gram), stopChars) {
+					result = append(result, string(gram))
+				}
+			}
+		}
+		han = han[:0]
+	}
+	for _, r := range text {
+		switch {
+		case unicode.Is(unicode.Han, r):
+			flushWord()
+			han = append(han, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r) && len(word) > 0:
+			flushHan()
+			word = append(word, r)
+		default:
+			flushWord()
+			flushHan()
+		}
+	}
+	flushWord()
+	flushHan()
+	return result
+}
+
+// TFIDFKeywords 按 TF-IDF 提取关键词：每段文字作为一篇文档，词频高且只集中在部分段落的词得分高。
+// 中文词组至少出现两次，被更长词组完全覆盖（出现次数相同）的短词组不再单独作为关键词
+func TFIDFKeywords(texts []string, limit int) []string {
+	tf := make(map[string]int)
+	df := make(map[string]int)
+	for _, text := range texts {
+		seen := make(map[string]bool)
+		for _, term := range terms(text) {
+			tf[term]++
+			if !seen[term] {
+				seen[term] = true
+				df[term]++
+			}
+		}
+	}
+
+	type scored struct {
+		term  string
+		score float64
+	}
+	var candidates []scored
+	n := float64(len(texts))
+	extended := extensions(tf)
+	for term, count := range tf {
+		if isHan(term) && count < 2 {
+			continue
+		}
+		if extended[term] >= count {
+			continue
+		}
+		idf := math.Log((n+1)/float64(df[term]+1)) + 1
+		// 较长的中文词组信息量更大，按字数略微加权
+		weight := 1.0
+		if isHan(term) {
+			weight += 0.25 * float64(len([]rune(term))-2)
+		}
+		candidates = append(candidates, scored{term, float64(count) * idf * weight})
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].score != candidates[j].score {
+			return candidates[i].score > candidates[j].score
+		}
+		return candidates[i].term < candidates[j].term
+	})
+
+	var keywords []string
+	for _, c := range candidates {
+		if len(keywords) >= limit {
+			break
+		}
+		overlap := false
+		for _, k := range keywords {
+			if strings.Contains(k, c.term) || strings.Contains(c.term, k) {
+				overlap = true
+				break
+			}
+		}
+		if !overlap {
+			keywords = append(keywords, c.term)
+		}
+	}
+	return keywords
+}
+
+// extensions 返回每个中文词组在比它多一个字的词组中的最大出现次数
+func extensions(tf map[string]int) map[string]int {
+	result := make(map[string]int)
+	for term, count := range tf {
+		runes := []rune(term)
+		if !isHan(term) || len(runes) < 3 {
+			continue
+		}
+		for _, sub := range []string{string(runes[1:]), string(runes[:len(runes)-1])} {
+			result[sub] = max(result[sub], count)
+		}
+	}
+	return result
+}
+
+// isHan 是否为中文词组
+func isHan(term string) bool {
+	for _, r := range term {
+		return unicode.Is(unicode.Han, r)
+	}
+	return false
+}
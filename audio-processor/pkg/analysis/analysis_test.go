@@ -0,0 +1,114 @@
+package analysis
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeLLM 返回固定回复
+type fakeLLM struct {
+	reply string
+	err   error
+}
+
+func (f fakeLLM) Chat(systemPrompt, content string) (string, error) {
+	return f.reply, f.err
+}
+
+func testSegments() []models.DataSegment {
+	texts := []string{
+		"今天我们来聊聊语音识别。",
+		"语音识别的准确率取决于音频质量。",
+		"[无法识别的音频片段]",
+		"字幕导出支持多种格式，语音识别之后就可以导出字幕。",
+		"最后介绍 Whisper 模型和 Whisper 的部署。",
+	}
+	segments := make([]models.DataSegment, len(texts))
+	for i, text := range texts {
+		segments[i] = models.DataSegment{Text: text, StartTime: float64(i * 5), EndTime: float64(i*5 + 4)}
+	}
+	return segments
+}
+
+func TestTFIDFKeywords(t *testing.T) {
+	keywords := TFIDFKeywords(usableTexts(testSegments()), 3)
+	require.Len(t, keywords, 3)
+	// 重复出现的长词组优先，其中的短词组不再单独出现
+	assert.Equal(t, "语音识别", keywords[0])
+	assert.Contains(t, keywords, "whisper")
+	assert.Contains(t, keywords, "字幕")
+	for _, keyword := range keywords {
+		assert.NotContains(t, []string{"语音", "识别", "音识"}, keyword)
+	}
+}
+
+func TestAnalyzeLocal(t *testing.T) {
+	result, err := Analyze(testSegments(), nil, Options{MaxKeywords: 5})
+	require.NoError(t, err)
+	assert.Equal(t, MethodTFIDF, result.Method)
+	assert.NotEmpty(t, result.Keywords)
+	assert.LessOrEqual(t, len(result.Keywords), 5)
+	assert.Equal(t, result.Keywords[:localTopics], result.Topics)
+	assert.Empty(t, result.Entities)
+
+	result, err = Analyze(nil, nil, Options{})
+	assert.NoError(t, err)
+	assert.Nil(t, result)
+}
+
+func TestAnalyzeLLM(t *testing.T) {
+	reply := "```json\n" + `{"keywords": ["语音识别", " 字幕 ", "语音识别", "Whisper"], ` +
+		`"entities": [{"text": "Whisper", "type": "Product"}, {"text": "OpenAI", "type": "company"}, {"text": " ", "type": "person"}], ` +
+		`"topics": ["#人工智能", "视频制作"]}` + "\n```"
+	result, err := Analyze(testSegments(), fakeLLM{reply: reply}, Options{MaxKeywords: 2})
+	require.NoError(t, err)
+	assert.Equal(t, &Result{
+		Method:   MethodLLM,
+		Keywords: []string{"语音识别", "字幕"},
+		Entities: []Entity{{Text: "Whisper", Type: "product"}, {Text: "OpenAI", Type: "other"}},
+		Topics:   []string{"人工智能", "视频制作"},
+	}, result)
+}
+
+func TestAnalyzeLLMFallback(t *testing.T) {
+	result, err := Analyze(testSegments(), fakeLLM{err: errors.New("超时")}, Options{MaxKeywords: 5})
+	assert.ErrorContains(t, err, "超时")
+	require.NotNil(t, result)
+	assert.Equal(t, MethodTFIDF, result.Method)
+
+	result, err = Analyze(testSegments(), fakeLLM{reply: "无法提取"}, Options{MaxKeywords: 5})
+	assert.Error(t, err)
+	assert.Equal(t, MethodTFIDF, result.Method)
+}
+
+func TestUpdateIndex(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "batches", IndexFileName)
+
+	require.NoError(t, UpdateIndex(path, map[string]*Result{
+		"/media/a.mp4": {Method: MethodLLM, Keywords: []string{"语音识别", "Whisper"}, Entities: []Entity{{Text: "OpenAI", Type: "organization"}}, Topics: []string{"AI"}},
+		"/media/b.mp3": {Method: MethodTFIDF, Keywords: []string{"whisper", "字幕"}, Topics: []string{"字幕"}},
+	}))
+	index, err := LoadIndex(path)
+	require.NoError(t, err)
+	assert.Len(t, index.Files, 2)
+	assert.Equal(t, "/media/a.mp4", index.Files["a"].Source)
+	assert.Equal(t, []string{"a", "b"}, index.Keywords["whisper"])
+	assert.Equal(t, []string{"a"}, index.Search("OpenAI"))
+	assert.Equal(t, []string{"b"}, index.Search("字幕"))
+
+	// 重新分析的文件替换旧结果
+	require.NoError(t, UpdateIndex(path, map[string]*Result{
+		"/media/a.mp4": {Method: MethodTFIDF, Keywords: []string{"直播"}, Topics: []string{"直播"}},
+	}))
+	index, err = LoadIndex(path)
+	require.NoError(t, err)
+	assert.Len(t, index.Files, 2)
+	assert.Equal(t, []string{"b"}, index.Keywords["whisper"])
+	assert.Empty(t, index.Search("OpenAI"))
+	assert.Equal(t, []string{"a"}, index.Search("直播"))
+}
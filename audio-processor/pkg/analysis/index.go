@@ -0,0 +1,162 @@
+package analysis
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// IndexFileName 汇总索引的文件名，位于输出目录的 batches 子目录
+const IndexFileName = "tags.json"
+
+// indexMu 串行化索引的读写，批处理与监听模式可能同时更新
+var indexMu sync.Mutex
+
+// IndexedFile 索引中的一个文件
+type IndexedFile struct {
+	Source    string `json:"source"`     // 源文件路径
+	UpdatedAt string `json:"updated_at"` // 分析时间
+	Result
+}
+
+// Index 全部文件的关键词、实体与主题汇总。Keywords、Entities、Topics 为倒排表（词 -> 文件名），
+// 每次更新时由 Files 重新生成
+type Index struct {
+	UpdatedAt string                 `json:"updated_at"`
+	Files     map[string]IndexedFile `json:"files"` // 文件名（不含扩展名） -> 分析结果
+	Keywords  map[string][]string    `json:"keywords"`
+	Entities  map[string][]string    `json:"entities"`
+	Topics    map[string][]string    `json:"topics"`
+}
+
+// FileName 返回源文件在索引中的名称，与输出清单的名称一致
+func FileName(source string) string {
+	baseName := filepath.Base(source)
+	return strings.TrimSuffix(baseName, filepath.Ext(baseName))
+}
+
+// LoadIndex 读取索引，文件不存在时返回空索引
+func LoadIndex(path string) (*Index, error) {
+	index := &Index{Files: make(map[string]IndexedFile)}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return index, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取关键词索引失败: %w", err)
+	}
+	if err := json.Unmarshal(data, index); err != nil {
+		return nil, fmt.Errorf("解析关键词索引失败: %w", err)
+	}
+	if index.Files == nil {
+		index.Files = make(map[string]IndexedFile)
+	}
+	return index, nil
+}
+
+// LoadResult 读取单个文件的分析结果（*_analysis.json）
+func LoadResult(path string) (*Result, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取分析结果失败: %w", err)
+	}
+	var result Result
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("解析分析结果失败: %w", err)
+	}
+	return &result, nil
+}
+
+// UpdateIndex 将 results（源文件路径 -> 分析结果）合并到 path 处的索引，同名文件的旧结果被替换
+func UpdateIndex(path string, results map[string]*Result) error {
+	if len(results) == 0 {
+		return nil
+	}
+	indexMu.Lock()
+	defer indexMu.Unlock()
+
+	index, err := LoadIndex(path)
+	if err != nil {
+		return err
+	}
+	now := time.Now().Format("2006-01-02 15:04:05")
+	for source, result := range results {
+		index.Files[FileName(source)] = IndexedFile{Source: source, UpdatedAt: now, Result: *result}
+	}
+	index.UpdatedAt = now
+	index.rebuild()
+
+	data, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return fmt.Errorf("编码关键词索引失败: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("创建索引目录失败: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("写入关键词索引失败: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("写入关键词索引失败: %w", err)
+	}
+	return nil
+}
+
+// rebuild 由 Files 重新生成倒排表，词统一为小写，文件名排序
+func (index *Index) rebuild() {
+	index.Keywords = make(map[string][]string)
+	index.Entities = make(map[string][]string)
+	index.Topics = make(map[string][]string)
+	add := func(table map[string][]string, term, name string) {
+		term = strings.ToLower(strings.TrimSpace(term))
+		if term == "" {
+			return
+		}
+		for _, existing := range table[term] {
+			if existing == name {
+				return
+			}
+		}
+		table[term] = append(table[term], name)
+	}
+	for name, file := range index.Files {
+		for _, keyword := range file.Keywords {
+			add(index.Keywords, keyword, name)
+		}
+		for _, entity := range file.Entities {
+			add(index.Entities, entity.Text, name)
+		}
+		for _, topic := range file.Topics {
+			add(index.Topics, topic, name)
+		}
+	}
+	for _, table := range []map[string][]string{index.Keywords, index.Entities, index.Topics} {
+		for _, names := range table {
+			sort.Strings(names)
+		}
+	}
+}
+
+// Search 返回关键词、实体或主题与 term 相同（不区分大小写）的文件名
+func (index *Index) Search(term string) []string {
+	term = strings.ToLower(strings.TrimSpace(term))
+	seen := make(map[string]bool)
+	var names []string
+	for _, table := range []map[string][]string{index.Keywords, index.Entities, index.Topics} {
+		for _, name := range table[term] {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,357 @@
+// Package ask 基于单个文稿回答问题：将文稿分成带时间的片段，检索与问题相关的片段
+// （配置了向量模型时按向量相似度，否则按关键词），交给大模型回答并以 [mm:ss] 注明依据的时间。
+// 片段向量按任务（文稿名称）缓存在处理记录数据库中，同一文稿再次提问时不再重新计算
+package ask
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/llm"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/models"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/search"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/store"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/utils"
+)
+
+// askPrompt 回答问题的系统提示
+const askPrompt = "你是文稿问答助手。请只根据给出的文稿片段回答问题，每条依据后用方括号注明片段开头的时间，如 [01:23]。" +
+	"文稿中没有相关内容时直接说明无法从文稿中找到答案，不要编造。"
+
+// passageChars 每个片段的最大字符数，相邻的识别段落合并到不超过该长度
+const passageChars = 300
+
+// embedBatch 每次请求向量模型的片段数
+const embedBatch = 64
+
+// defaultTopK 未配置时交给大模型的片段数
+const defaultTopK = 8
+
+// 检索方式
+const (
+	RetrievalEmbedding = "embedding"
+	RetrievalKeyword   = "keyword"
+)
+
+// Segment 文稿中的一段，没有时间戳（文本文稿）时 Start 与 End 为 -1
+type Segment struct {
+	Start   float64
+	End     float64
+	Text    string
+	Speaker string
+}
+
+// Passage 检索的片段：相邻段落合并后的文本与时间范围
+type Passage struct {
+	Start float64 `json:"start"`
+	End   float64 `json:"end"`
+	Text  string  `json:"text"`
+}
+
+// Answer 问题的回答
+type Answer struct {
+	Answer    string    `json:"answer"`
+	Citations []Passage `json:"citations"` // 回答中以时间引用的片段
+	Sources   []Passage `json:"sources"`   // 检索到并交给大模型的片段，按时间排序
+	Retrieval string    `json:"retrieval"` // RetrievalEmbedding 或 RetrievalKeyword
+}
+
+// Cache 片段向量的缓存，由 store.Store 实现
+type Cache interface {
+	Embeddings(job, model string) ([]store.Embedding, error)
+	SaveEmbeddings(job, model string, embeddings []store.Embedding) error
+}
+
+// Options 问答选项
+type Options struct {
+	TopK int // 交给大模型的片段数
+}
+
+// OptionsFromConfig 从配置读取问答选项
+func OptionsFromConfig(config *models.Config) Options {
+	return Options{TopK: config.AskTopK}
+}
+
+// LLM 回答问题的大模型接口，由 llm.LLMClient 实现
+type LLM interface {
+	Chat(systemPrompt, content string) (string, error)
+}
+
+// Asker 回答文稿问题
+type Asker struct {
+	client   LLM
+	embedder llm.Embedder // 为 nil 时按关键词检索
+	opts     Options
+}
+
+// New 创建问答器，embedder 为 nil 时按关键词检索
+func New(client LLM, embedder llm.Embedder, opts Options) *Asker {
+	if opts.TopK <= 0 {
+		opts.TopK = defaultTopK
+	}
+	return &Asker{client: client, embedder: embedder, opts: opts}
+}
+
+// Ask 检索文稿 job 中与问题相关的片段并回答。cache 为 nil 时每次重新计算片段向量；
+// 向量模型请求失败时改用关键词检索
+func (a *Asker) Ask(ctx context.Context, cache Cache, job string, segments []Segment, question string) (*Answer, error) {
+	question = strings.TrimSpace(question)
+	if question == "" {
+		return nil, fmt.Errorf("问题为空")
+	}
+	passages := Passages(segments)
+	if len(passages) == 0 {
+		return nil, fmt.Errorf("文稿内容为空")
+	}
+
+	answer := &Answer{Retrieval: RetrievalKeyword}
+	var selected []int
+	if a.embedder != nil {
+		var err error
+		selected, err = a.searchEmbeddings(ctx, cache, job, passages, question)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			utils.Warn("按向量检索 %s 失败，改用关键词检索: %v", job, err)
+		} else {
+			answer.Retrieval = RetrievalEmbedding
+		}
+	}
+	if answer.Retrieval == RetrievalKeyword {
+		selected = searchKeywords(passages, question, a.opts.TopK)
+	}
+	sort.Ints(selected)
+
+	var content strings.Builder
+	content.WriteString("文稿片段：\n")
+	answer.Sources = make([]Passage, 0, len(selected))
+	for _, i := range selected {
+		answer.Sources = append(answer.Sources, passages[i])
+		fmt.Fprintf(&content, "%s %s\n", timeLabel(passages[i].Start), passages[i].Text)
+	}
+	fmt.Fprintf(&content, "\n问题：%s", question)
+
+	reply, err := a.client.Chat(askPrompt, content.String())
+	if err != nil {
+		return nil, fmt.Errorf("生成回答失败: %w", err)
+	}
+	answer.Answer = strings.TrimSpace(reply)
+	answer.Citations = citations(answer.Answer, answer.Sources)
+	return answer, nil
+}
+
+// Passages 将相邻的段落合并为不超过 passageChars 字符的片段，跳过空段与无法识别的片段。
+// 说话人变化时另起一段，合并后的文本保留说话人
+func Passages(segments []Segment) []Passage {
+	var passages []Passage
+	var current *Passage
+	size, speaker := 0, ""
+	for _, segment := range segments {
+		text := strings.TrimSpace(segment.Text)
+		if text == "" || text == "[无法识别的音频片段]" {
+			continue
+		}
+		if segment.Speaker != "" {
+			text = segment.Speaker + ": " + text
+		}
+		n := len([]rune(text))
+		if current != nil && (size+n > passageChars || segment.Speaker != speaker) {
+			passages = append(passages, *current)
+			current = nil
+		}
+		if current == nil {
+			current = &Passage{Start: segment.Start, End: segment.End, Text: text}
+			size, speaker = n, segment.Speaker
+			continue
+		}
+		current.Text += " " + text
+		current.End = segment.End
+		size += n
+	}
+	if current != nil {
+		passages = append(passages, *current)
+	}
+	return passages
+}
+
+// searchEmbeddings 返回与问题向量最相似的 TopK 个片段
+func (a *Asker) searchEmbeddings(ctx context.Context, cache Cache, job string, passages []Passage, question string) ([]int, error) {
+	vectors, err := a.passageVectors(ctx, cache, job, passages)
+	if err != nil {
+		return nil, err
+	}
+	query, err := a.embedder.Embed(ctx, []string{question})
+	if err != nil {
+		return nil, fmt.Errorf("计算问题向量失败: %w", err)
+	}
+	scores := make([]float64, len(vectors))
+	for i, vector := range vectors {
+		scores[i] = cosine(query[0], vector)
+	}
+	return topK(scores, a.opts.TopK, false), nil
+}
+
+// passageVectors 返回各片段的向量：缓存中的片段与当前文稿一致时直接使用，否则重新计算并保存
+func (a *Asker) passageVectors(ctx context.Context, cache Cache, job string, passages []Passage) ([][]float32, error) {
+	model := a.embedder.Name()
+	if cache != nil {
+		cached, err := cache.Embeddings(job, model)
+		if err != nil {
+			utils.Warn("%v", err)
+		}
+		if sameTexts(cached, passages) {
+			vectors := make([][]float32, len(cached))
+			for i, embedding := range cached {
+				vectors[i] = embedding.Vector
+			}
+			return vectors, nil
+		}
+	}
+
+	utils.Info("使用 %s 计算 %s 的 %d 个片段向量", model, job, len(passages))
+	vectors := make([][]float32, 0, len(passages))
+	for start := 0; start < len(passages); start += embedBatch {
+		end := start + embedBatch
+		if end > len(passages) {
+			end = len(passages)
+		}
+		texts := make([]string, 0, end-start)
+		for _, passage := range passages[start:end] {
+			texts = append(texts, passage.Text)
+		}
+		batch, err := a.embedder.Embed(ctx, texts)
+		if err != nil {
+			return nil, fmt.Errorf("计算片段向量失败: %w", err)
+		}
+		vectors = append(vectors, batch...)
+	}
+
+	if cache != nil {
+		embeddings := make([]store.Embedding, len(passages))
+		for i, passage := range passages {
+			embeddings[i] = store.Embedding{Start: passage.Start, End: passage.End, Text: passage.Text, Vector: vectors[i]}
+		}
+		if err := cache.SaveEmbeddings(job, model, embeddings); err != nil {
+			utils.Warn("%v", err)
+		}
+	}
+	return vectors, nil
+}
+
+// sameTexts 判断缓存的片段与当前文稿的片段是否一致，文稿重新识别后需要重新计算
+func sameTexts(cached []store.Embedding, passages []Passage) bool {
+	if len(cached) != len(passages) {
+		return false
+	}
+	for i := range cached {
+		if cached[i].Text != passages[i].Text {
+			return false
+		}
+	}
+	return true
+}
+
+// searchKeywords 按问题中的词在片段中出现的种类数排序，返回得分最高的 k 个片段，
+// 都不匹配时返回文稿开头的片段
+func searchKeywords(passages []Passage, question string, k int) []int {
+	terms := make(map[string]bool)
+	for _, token := range search.Tokens(question) {
+		terms[token] = true
+	}
+	scores := make([]float64, len(passages))
+	for i, passage := range passages {
+		seen := make(map[string]bool)
+		for _, token := range search.Tokens(passage.Text) {
+			if terms[token] && !seen[token] {
+				seen[token] = true
+				// 多字的词比单字更能说明相关
+				scores[i] += float64(len([]rune(token)))
+			}
+		}
+	}
+	return topK(scores, k, true)
+}
+
+// topK 返回分数最高的 k 个下标，分数相同时靠前的优先。positive 为 true 时只返回分数大于 0 的，
+// 都不大于 0 时返回前 k 个
+func topK(scores []float64, k int, positive bool) []int {
+	indexes := make([]int, 0, len(scores))
+	for i, score := range scores {
+		if !positive || score > 0 {
+			indexes = append(indexes, i)
+		}
+	}
+	if len(indexes) == 0 {
+		for i := range scores {
+			indexes = append(indexes, i)
+		}
+	}
+	sort.SliceStable(indexes, func(i, j int) bool {
+		return scores[indexes[i]] > scores[indexes[j]]
+	})
+	if len(indexes) > k {
+		indexes = indexes[:k]
+	}
+	return indexes
+}
+
+// cosine 返回两个向量的余弦相似度，长度不同或为零向量时返回 0
+func cosine(a, b []float32) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / math.Sqrt(normA*normB)
+}
+
+// timeLabel 返回片段的时间标签，没有时间戳（文本文稿）时为占位符
+func timeLabel(start float64) string {
+	if start < 0 {
+		return "[--:--]"
+	}
+	return "[" + utils.FormatTime(start) + "]"
+}
+
+// citationPattern 匹配回答中的 [mm:ss] 或 [hh:mm:ss]，同一对括号中可以有多个时间
+var citationPattern = regexp.MustCompile(`\[(\d{1,2}:\d{2}(?::\d{2})?(?:\s*[,，、]\s*\d{1,2}:\d{2}(?::\d{2})?)*)\]`)
+
+// citationSeparator 同一对括号中多个时间的分隔符
+var citationSeparator = regexp.MustCompile(`\s*[,，、]\s*`)
+
+// citations 返回回答中以时间引用的片段，按引用的先后顺序去重
+func citations(answer string, sources []Passage) []Passage {
+	cited := []Passage{}
+	seen := make(map[int]bool)
+	for _, match := range citationPattern.FindAllStringSubmatch(answer, -1) {
+		for _, label := range citationSeparator.Split(match[1], -1) {
+			seconds, err := utils.ParseTimestamp(label)
+			if err != nil {
+				continue
+			}
+			for i, source := range sources {
+				// 标签按秒取整，落在片段的时间范围内即视为引用该片段
+				if source.Start >= 0 && seconds >= math.Floor(source.Start) && seconds <= source.End {
+					if !seen[i] {
+						seen[i] = true
+						cited = append(cited, source)
+					}
+					break
+				}
+			}
+		}
+	}
+	return cited
+}
@@ -0,0 +1,117 @@
+package ask
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeLLM 记录收到的文稿片段，返回固定回答
+type fakeLLM struct {
+	reply   string
+	content string
+}
+
+func (f *fakeLLM) Chat(systemPrompt, content string) (string, error) {
+	f.content = content
+	return f.reply, nil
+}
+
+// fakeEmbedder 按文本是否包含“预算”返回二维向量，记录请求的文本数
+type fakeEmbedder struct {
+	err   error
+	texts int
+}
+
+func (f *fakeEmbedder) Name() string { return "fake/e" }
+
+func (f *fakeEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	f.texts += len(texts)
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		if strings.Contains(text, "预算") {
+			vectors[i] = []float32{1, 0}
+		} else {
+			vectors[i] = []float32{0, 1}
+		}
+	}
+	return vectors, nil
+}
+
+var testSegments = []Segment{
+	{Start: 0, End: 5, Text: "大家好，今天开会", Speaker: "A"},
+	{Start: 5, End: 10, Text: "先说一下进度", Speaker: "A"},
+	{Start: 65, End: 70, Text: "明年的预算增加两成", Speaker: "B"},
+	{Start: 70, End: 72, Text: "[无法识别的音频片段]"},
+	{Start: 130, End: 140, Text: "下周五之前提交方案", Speaker: "A"},
+}
+
+func TestPassages(t *testing.T) {
+	passages := Passages(testSegments)
+	require.Len(t, passages, 3, "说话人变化时另起一段，跳过无法识别的片段")
+	assert.Equal(t, Passage{Start: 0, End: 10, Text: "A: 大家好，今天开会 A: 先说一下进度"}, passages[0])
+	assert.Equal(t, "B: 明年的预算增加两成", passages[1].Text)
+
+	long := []Segment{{Start: 0, End: 1, Text: strings.Repeat("长", passageChars)}, {Start: 1, End: 2, Text: "短"}}
+	assert.Len(t, Passages(long), 2)
+}
+
+// TestAskKeyword 测试未配置向量模型时按关键词检索，并解析回答中引用的时间
+func TestAskKeyword(t *testing.T) {
+	client := &fakeLLM{reply: "预算增加两成 [01:05]，方案下周五提交 [02:10、00:00]。"}
+	asker := New(client, nil, Options{TopK: 2})
+	answer, err := asker.Ask(context.Background(), nil, "会议", testSegments, "明年预算是多少，方案什么时候提交？")
+	require.NoError(t, err)
+	assert.Equal(t, RetrievalKeyword, answer.Retrieval)
+	require.Len(t, answer.Sources, 2, "只返回匹配的片段")
+	assert.Equal(t, 65.0, answer.Sources[0].Start, "按时间排序")
+	assert.Equal(t, 130.0, answer.Sources[1].Start)
+	assert.Contains(t, client.content, "[01:05] B: 明年的预算增加两成")
+	assert.Contains(t, client.content, "问题：明年预算是多少")
+
+	require.Len(t, answer.Citations, 2, "[00:00] 不在检索到的片段中")
+	assert.Equal(t, 65.0, answer.Citations[0].Start)
+	assert.Equal(t, 130.0, answer.Citations[1].Start)
+
+	_, err = asker.Ask(context.Background(), nil, "会议", testSegments, " ")
+	assert.Error(t, err)
+}
+
+// TestAskEmbedding 测试按向量检索，片段向量按任务缓存，文稿变化后重新计算
+func TestAskEmbedding(t *testing.T) {
+	db, err := store.Open(filepath.Join(t.TempDir(), store.FileName))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	embedder := &fakeEmbedder{}
+	asker := New(&fakeLLM{reply: "增加两成 [01:05]"}, embedder, Options{TopK: 1})
+	answer, err := asker.Ask(context.Background(), db, "会议", testSegments, "预算")
+	require.NoError(t, err)
+	assert.Equal(t, RetrievalEmbedding, answer.Retrieval)
+	require.Len(t, answer.Sources, 1)
+	assert.Equal(t, 65.0, answer.Sources[0].Start)
+	assert.Equal(t, 4, embedder.texts, "3 个片段与问题")
+
+	_, err = asker.Ask(context.Background(), db, "会议", testSegments, "预算")
+	require.NoError(t, err)
+	assert.Equal(t, 5, embedder.texts, "片段向量来自缓存，只计算问题")
+
+	_, err = asker.Ask(context.Background(), db, "会议", testSegments[:3], "预算")
+	require.NoError(t, err)
+	assert.Equal(t, 8, embedder.texts, "文稿变化后重新计算")
+
+	// 向量模型不可用时改用关键词检索
+	embedder.err = errors.New("503")
+	answer, err = asker.Ask(context.Background(), db, "会议", testSegments, "预算")
+	require.NoError(t, err)
+	assert.Equal(t, RetrievalKeyword, answer.Retrieval)
+}
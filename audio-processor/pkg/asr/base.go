@@ -1,11 +1,12 @@
 package asr
 
 import (
+	"context"
 	"fmt"
 	"hash/crc32"
 	"io/ioutil"
 	"os"
-	"path/filepath"
+	"time"
 
 	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/models"
 	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/utils"
@@ -18,6 +19,7 @@ type BaseASR struct {
 	CRC32      uint32 // CRC32校验值
 	CRC32Hex   string // 文件CRC32校验和（十六进制）
 	UseCache   bool   // 是否使用缓存
+	Cache      *Cache // 识别结果缓存，由选择器注入
 }
 
 // NewBaseASR 创建一个新的BaseASR实例
@@ -60,42 +62,43 @@ func (b *BaseASR) calculateCRC32() {
 	utils.Debug("计算的CRC32校验和: %s", b.CRC32Hex)
 }
 
+// DefaultCacheDir 识别结果缓存目录
+const DefaultCacheDir = "./cache"
+
+// SetCache 设置识别结果缓存
+func (b *BaseASR) SetCache(cache *Cache) {
+	b.Cache = cache
+}
+
 // GetCacheKey 获取缓存键名
 func (b *BaseASR) GetCacheKey(prefix string) string {
 	return fmt.Sprintf("%s-%s", prefix, b.CRC32Hex)
 }
 
 // LoadFromCache 从缓存加载识别结果
-func (b *BaseASR) LoadFromCache(cacheDir, cacheKey string) ([]models.DataSegment, bool) {
+func (b *BaseASR) LoadFromCache(cacheKey string) ([]models.DataSegment, bool) {
 	if !b.UseCache {
 		return nil, false
 	}
-
-	cacheFilePath := filepath.Join(cacheDir, cacheKey)
-	if _, err := os.Stat(cacheFilePath); os.IsNotExist(err) {
-		utils.Debug("缓存文件不存在: %s", cacheFilePath)
-		return nil, false
-	}
-
-	// TODO: 实现从文件加载缓存的逻辑
-	// 这里简化处理，实际中需要实现JSON解析等
-
-	return nil, false
+	return b.Cache.Get(cacheKey)
 }
 
 // SaveToCache 保存识别结果到缓存
-func (b *BaseASR) SaveToCache(cacheDir, cacheKey string, segments []models.DataSegment) error {
+func (b *BaseASR) SaveToCache(cacheKey string, segments []models.DataSegment) error {
 	if !b.UseCache {
 		return nil
 	}
+	return b.Cache.Put(cacheKey, segments)
+}
 
-	// 确保缓存目录存在
-	if err := os.MkdirAll(cacheDir, 0755); err != nil {
-		return fmt.Errorf("创建缓存目录失败: %w", err)
+// sleepContext 等待 d，上下文取消时立即返回其错误
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
 	}
-
-	// TODO: 实现保存缓存到文件的逻辑
-	// 这里简化处理，实际中需要实现JSON序列化等
-
-	return nil
 }
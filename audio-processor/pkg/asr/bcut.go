@@ -44,6 +44,118 @@ type BcutASR struct {
 	downloadURL  string
 }
 
+// bcutResponse 必剪接口的通用响应，data 按接口解析
+type bcutResponse struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data"`
+}
+
+func (r *bcutResponse) validate() error {
+	if r.Code == 0 && len(r.Data) == 0 {
+		return fmt.Errorf("缺少字段 data")
+	}
+	return nil
+}
+
+// bcutUploadData 申请上传接口返回的数据
+type bcutUploadData struct {
+	InBossKey  string   `json:"in_boss_key"`
+	ResourceID string   `json:"resource_id"`
+	UploadID   string   `json:"upload_id"`
+	PerSize    int      `json:"per_size"`
+	UploadURLs []string `json:"upload_urls"`
+}
+
+func (d *bcutUploadData) validate() error {
+	if err := missingFields(map[string]bool{
+		"data.in_boss_key": d.InBossKey != "",
+		"data.resource_id": d.ResourceID != "",
+		"data.upload_id":   d.UploadID != "",
+		"data.upload_urls": len(d.UploadURLs) > 0,
+	}); err != nil {
+		return err
+	}
+	if d.PerSize <= 0 {
+		return fmt.Errorf("data.per_size 应大于0，实际为 %d", d.PerSize)
+	}
+	return nil
+}
+
+// bcutCommitData 提交上传接口返回的数据
+type bcutCommitData struct {
+	DownloadURL string `json:"download_url"`
+}
+
+func (d *bcutCommitData) validate() error {
+	return missingFields(map[string]bool{"data.download_url": d.DownloadURL != ""})
+}
+
+// bcutTaskData 创建任务接口返回的数据
+type bcutTaskData struct {
+	TaskID string `json:"task_id"`
+}
+
+func (d *bcutTaskData) validate() error {
+	return missingFields(map[string]bool{"data.task_id": d.TaskID != ""})
+}
+
+// bcutQueryData 查询结果接口返回的数据，state 为 4 时 result 为识别结果的JSON字符串
+type bcutQueryData struct {
+	State  *int   `json:"state"`
+	Result string `json:"result"`
+}
+
+func (d *bcutQueryData) validate() error {
+	return missingFields(map[string]bool{"data.state": d.State != nil})
+}
+
+// bcutResult 识别结果，时间单位为毫秒
+type bcutResult struct {
+	Utterances []bcutUtterance `json:"utterances"`
+}
+
+// bcutUtterance 识别结果中的一句
+type bcutUtterance struct {
+	Transcript string     `json:"transcript"`
+	StartTime  float64    `json:"start_time"`
+	EndTime    float64    `json:"end_time"`
+	Confidence float64    `json:"confidence"`
+	Words      []bcutWord `json:"words"`
+}
+
+// bcutWord 识别结果中的一个字词
+type bcutWord struct {
+	Label      string  `json:"label"`
+	StartTime  float64 `json:"start_time"`
+	EndTime    float64 `json:"end_time"`
+	Confidence float64 `json:"confidence"`
+}
+
+func (r *bcutResult) validate() error {
+	if r.Utterances == nil {
+		return fmt.Errorf("缺少字段 utterances")
+	}
+	for i, u := range r.Utterances {
+		if u.EndTime < u.StartTime {
+			return fmt.Errorf("utterances[%d] 的结束时间 %.0f 早于开始时间 %.0f", i, u.EndTime, u.StartTime)
+		}
+	}
+	return nil
+}
+
+// decodeBcutData 解析必剪接口响应，code 非0时返回接口给出的错误信息，data 按 v 的结构解析并校验
+func decodeBcutData(endpoint string, body []byte, v validator) error {
+	var resp bcutResponse
+	if err := decodeResponse("bcut", endpoint, body, &resp); err != nil {
+		return err
+	}
+	if resp.Code != 0 {
+		return fmt.Errorf("接口 %s 返回错误 (code=%d): %s", endpoint, resp.Code, resp.Message)
+	}
+	return decodeResponse("bcut", endpoint, resp.Data, v)
+}
+
 // NewBcutASR 创建必剪ASR实例
 func NewBcutASR(audioPath string, useCache bool) (ASRService, error) {
 	baseASR, err := NewBaseASR(audioPath, useCache)
@@ -66,7 +178,7 @@ func (b *BcutASR) GetResult(ctx context.Context, callback ProgressCallback) ([]m
 	// 检查是否有缓存
 	cacheKey := b.GetCacheKey("BcutASR")
 	if b.UseCache {
-		if segments, ok := b.LoadFromCache("./cache", cacheKey); ok {
+		if segments, ok := b.LoadFromCache(cacheKey); ok {
 			utils.Info("[%s] 从缓存加载必剪ASR结果", instanceID)
 			// 确保即使从缓存加载也调用最终回调
 			if callback != nil {
@@ -84,7 +196,7 @@ func (b *BcutASR) GetResult(ctx context.Context, callback ProgressCallback) ([]m
 	}
 	utils.Info("[%s] 开始上传...", instanceID)
 	// 上传文件
-	if err := b.upload(); err != nil {
+	if err := b.upload(ctx); err != nil {
 		utils.Error("[%s] 上传失败: %v", instanceID, err)
 		return nil, fmt.Errorf("必剪ASR上传失败: %w", err)
 	}
@@ -96,7 +208,7 @@ func (b *BcutASR) GetResult(ctx context.Context, callback ProgressCallback) ([]m
 	}
 	utils.Info("[%s] 开始创建任务...", instanceID)
 	// 创建任务
-	if err := b.createTask(); err != nil {
+	if err := b.createTask(ctx); err != nil {
 		utils.Error("[%s] 创建任务失败: %v", instanceID, err)
 		return nil, fmt.Errorf("必剪ASR创建任务失败: %w", err)
 	}
@@ -128,7 +240,7 @@ func (b *BcutASR) GetResult(ctx context.Context, callback ProgressCallback) ([]m
 	// 缓存结果
 	if b.UseCache && len(segments) > 0 {
 		utils.Info("[%s] 开始缓存结果...", instanceID)
-		if err := b.SaveToCache("./cache", cacheKey, segments); err != nil {
+		if err := b.SaveToCache(cacheKey, segments); err != nil {
 			utils.Warn("[%s] 保存必剪ASR结果到缓存失败: %v", instanceID, err)
 		} else {
 			utils.Info("[%s] 缓存结果成功", instanceID)
@@ -140,19 +252,19 @@ func (b *BcutASR) GetResult(ctx context.Context, callback ProgressCallback) ([]m
 }
 
 // upload 上传文件
-func (b *BcutASR) upload() error {
+func (b *BcutASR) upload(ctx context.Context) error {
 	// 申请上传
-	if err := b.requestUpload(); err != nil {
+	if err := b.requestUpload(ctx); err != nil {
 		return err
 	}
 
 	// 上传分片
-	if err := b.uploadParts(); err != nil {
+	if err := b.uploadParts(ctx); err != nil {
 		return err
 	}
 
 	// 提交上传
-	if err := b.commitUpload(); err != nil {
+	if err := b.commitUpload(ctx); err != nil {
 		return err
 	}
 
@@ -160,7 +272,7 @@ func (b *BcutASR) upload() error {
 }
 
 // requestUpload 申请上传
-func (b *BcutASR) requestUpload() error {
+func (b *BcutASR) requestUpload(ctx context.Context) error {
 	payload := map[string]interface{}{
 		"type":             2,
 		"name":             "audio.mp3",
@@ -174,7 +286,7 @@ func (b *BcutASR) requestUpload() error {
 		return fmt.Errorf("JSON编码失败: %w", err)
 	}
 
-	req, err := http.NewRequest("POST", API_REQ_UPLOAD, bytes.NewBuffer(jsonPayload))
+	req, err := http.NewRequestWithContext(ctx, "POST", API_REQ_UPLOAD, bytes.NewBuffer(jsonPayload))
 	if err != nil {
 		return fmt.Errorf("创建HTTP请求失败: %w", err)
 	}
@@ -182,8 +294,7 @@ func (b *BcutASR) requestUpload() error {
 	req.Header.Set("User-Agent", "Bilibili/1.0.0 (https://www.bilibili.com)")
 	req.Header.Set("Content-Type", "application/json")
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := utils.DefaultHTTPClient().Do(req)
 	if err != nil {
 		return fmt.Errorf("发送HTTP请求失败: %w", err)
 	}
@@ -194,29 +305,28 @@ func (b *BcutASR) requestUpload() error {
 		return fmt.Errorf("读取响应失败: %w", err)
 	}
 
-	var result map[string]interface{}
-	if err := json.Unmarshal(body, &result); err != nil {
-		return fmt.Errorf("解析JSON响应失败: %w", err)
+	var data bcutUploadData
+	if err := decodeBcutData("resource/create", body, &data); err != nil {
+		return err
 	}
 
-	// 提取响应数据
-	data, ok := result["data"].(map[string]interface{})
-	if !ok {
-		return fmt.Errorf("响应格式错误")
-	}
+	b.inBossKey = data.InBossKey
+	b.resourceID = data.ResourceID
+	b.uploadID = data.UploadID
+	b.perSize = data.PerSize
+	b.uploadURLs = data.UploadURLs
+	b.clips = len(b.uploadURLs)
 
-	b.inBossKey = data["in_boss_key"].(string)
-	b.resourceID = data["resource_id"].(string)
-	b.uploadID = data["upload_id"].(string)
-	b.perSize = int(data["per_size"].(float64))
-	
-	uploadURLsIface := data["upload_urls"].([]interface{})
-	b.uploadURLs = make([]string, len(uploadURLsIface))
-	for i, url := range uploadURLsIface {
-		b.uploadURLs[i] = url.(string)
+	// 分片数量不足以容纳整个文件时，上传会静默丢失数据
+	if b.clips*b.perSize < len(b.FileBinary) {
+		return &SchemaError{
+			Service:  "bcut",
+			Endpoint: "resource/create",
+			Problem: fmt.Sprintf("%d 个分片 × 分片大小 %d 字节不足以上传 %d 字节的文件",
+				b.clips, b.perSize, len(b.FileBinary)),
+			DumpPath: dumpPayload("bcut", "resource/create", body),
+		}
 	}
-	
-	b.clips = len(b.uploadURLs)
 
 	utils.Info("申请上传成功, 总计大小%dKB, %d分片, 分片大小%dKB: %s", 
 		len(b.FileBinary)/1024, b.clips, b.perSize/1024, b.inBossKey)
@@ -225,7 +335,7 @@ func (b *BcutASR) requestUpload() error {
 }
 
 // uploadParts 上传分片
-func (b *BcutASR) uploadParts() error {
+func (b *BcutASR) uploadParts(ctx context.Context) error {
 	b.etags = make([]string, b.clips)
 	
 	for i := 0; i < b.clips; i++ {
@@ -237,7 +347,7 @@ func (b *BcutASR) uploadParts() error {
 		
 		utils.Info("开始上传分片%d: %d-%d", i, startRange, endRange)
 		
-		req, err := http.NewRequest("PUT", b.uploadURLs[i], bytes.NewBuffer(b.FileBinary[startRange:endRange]))
+		req, err := http.NewRequestWithContext(ctx, "PUT", b.uploadURLs[i], bytes.NewBuffer(b.FileBinary[startRange:endRange]))
 		if err != nil {
 			return fmt.Errorf("创建HTTP请求失败: %w", err)
 		}
@@ -245,8 +355,7 @@ func (b *BcutASR) uploadParts() error {
 		req.Header.Set("User-Agent", "Bilibili/1.0.0 (https://www.bilibili.com)")
 		req.Header.Set("Content-Type", "application/octet-stream")
 		
-		client := &http.Client{}
-		resp, err := client.Do(req)
+		resp, err := utils.DefaultHTTPClient().Do(req)
 		if err != nil {
 			return fmt.Errorf("发送HTTP请求失败: %w", err)
 		}
@@ -277,7 +386,7 @@ func (b *BcutASR) uploadParts() error {
 }
 
 // commitUpload 提交上传
-func (b *BcutASR) commitUpload() error {
+func (b *BcutASR) commitUpload(ctx context.Context) error {
 	payload := map[string]interface{}{
 		"InBossKey":  b.inBossKey,
 		"ResourceId": b.resourceID,
@@ -291,7 +400,7 @@ func (b *BcutASR) commitUpload() error {
 		return fmt.Errorf("JSON编码失败: %w", err)
 	}
 
-	req, err := http.NewRequest("POST", API_COMMIT_UPLOAD, bytes.NewBuffer(jsonPayload))
+	req, err := http.NewRequestWithContext(ctx, "POST", API_COMMIT_UPLOAD, bytes.NewBuffer(jsonPayload))
 	if err != nil {
 		return fmt.Errorf("创建HTTP请求失败: %w", err)
 	}
@@ -299,8 +408,7 @@ func (b *BcutASR) commitUpload() error {
 	req.Header.Set("User-Agent", "Bilibili/1.0.0 (https://www.bilibili.com)")
 	req.Header.Set("Content-Type", "application/json")
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := utils.DefaultHTTPClient().Do(req)
 	if err != nil {
 		return fmt.Errorf("发送HTTP请求失败: %w", err)
 	}
@@ -311,18 +419,13 @@ func (b *BcutASR) commitUpload() error {
 		return fmt.Errorf("读取响应失败: %w", err)
 	}
 
-	var result map[string]interface{}
-	if err := json.Unmarshal(body, &result); err != nil {
-		return fmt.Errorf("解析JSON响应失败: %w", err)
-	}
-
 	// 提取下载URL
-	data, ok := result["data"].(map[string]interface{})
-	if !ok {
-		return fmt.Errorf("响应格式错误")
+	var data bcutCommitData
+	if err := decodeBcutData("resource/create/complete", body, &data); err != nil {
+		return err
 	}
 
-	b.downloadURL = data["download_url"].(string)
+	b.downloadURL = data.DownloadURL
 	utils.Info("提交成功，获取下载URL: %s", b.downloadURL)
 
 	return nil
@@ -341,7 +444,7 @@ func (b *BcutASR) buildEtags() string {
 }
 
 // createTask 创建任务
-func (b *BcutASR) createTask() error {
+func (b *BcutASR) createTask(ctx context.Context) error {
 	payload := map[string]interface{}{
 		"resource": b.downloadURL,
 		"model_id": "8",
@@ -352,7 +455,7 @@ func (b *BcutASR) createTask() error {
 		return fmt.Errorf("JSON编码失败: %w", err)
 	}
 
-	req, err := http.NewRequest("POST", API_CREATE_TASK, bytes.NewBuffer(jsonPayload))
+	req, err := http.NewRequestWithContext(ctx, "POST", API_CREATE_TASK, bytes.NewBuffer(jsonPayload))
 	if err != nil {
 		return fmt.Errorf("创建HTTP请求失败: %w", err)
 	}
@@ -360,8 +463,7 @@ func (b *BcutASR) createTask() error {
 	req.Header.Set("User-Agent", "Bilibili/1.0.0 (https://www.bilibili.com)")
 	req.Header.Set("Content-Type", "application/json")
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := utils.DefaultHTTPClient().Do(req)
 	if err != nil {
 		return fmt.Errorf("发送HTTP请求失败: %w", err)
 	}
@@ -372,28 +474,21 @@ func (b *BcutASR) createTask() error {
 		return fmt.Errorf("读取响应失败: %w", err)
 	}
 
-	var result map[string]interface{}
-	if err := json.Unmarshal(body, &result); err != nil {
-		return fmt.Errorf("解析JSON响应失败: %w", err)
-	}
-
 	// 提取任务ID
-	data, ok := result["data"].(map[string]interface{})
-	if !ok {
-		return fmt.Errorf("响应格式错误")
+	var data bcutTaskData
+	if err := decodeBcutData("task", body, &data); err != nil {
+		return err
 	}
 
-	b.taskID = data["task_id"].(string)
+	b.taskID = data.TaskID
 	utils.Info("任务已创建: %s", b.taskID)
 
 	return nil
 }
 
 // queryResult 查询结果
-func (b *BcutASR) queryResult(ctx context.Context, callback ProgressCallback) (map[string]interface{}, error) {
-	client := &http.Client{
-		Timeout: time.Second * 30, // 设置HTTP请求超时
-	}
+func (b *BcutASR) queryResult(ctx context.Context, callback ProgressCallback) (*bcutResult, error) {
+	client := utils.DefaultHTTPClient().WithTimeout(30 * time.Second) // 单次查询超时
 	
 	instanceID := utils.GenerateRandomString(6)
 	utils.Info("[BcutASR-%s] 开始轮询查询任务: %s", instanceID, b.taskID)
@@ -409,7 +504,7 @@ func (b *BcutASR) queryResult(ctx context.Context, callback ProgressCallback) (m
 		}
 
 		url := fmt.Sprintf("%s?model_id=%s&task_id=%s", API_QUERY_RESULT, "7", b.taskID)
-		req, err := http.NewRequest("GET", url, nil)
+		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 		if err != nil {
 			return nil, fmt.Errorf("创建HTTP请求失败: %w", err)
 		}
@@ -420,7 +515,9 @@ func (b *BcutASR) queryResult(ctx context.Context, callback ProgressCallback) (m
 		resp, err := client.Do(req)
 		if err != nil {
 			utils.Warn("[BcutASR-%s] 第 %d 次查询请求失败: %v，将重试", instanceID, i, err)
-			time.Sleep(time.Second * 2)
+			if err := sleepContext(ctx, time.Second*2); err != nil {
+				return nil, err
+			}
 			continue
 		}
 
@@ -429,47 +526,42 @@ func (b *BcutASR) queryResult(ctx context.Context, callback ProgressCallback) (m
 		
 		if err != nil {
 			utils.Warn("[BcutASR-%s] 第 %d 次查询读取响应失败: %v，将重试", instanceID, i, err)
-			time.Sleep(time.Second * 2)
+			if err := sleepContext(ctx, time.Second*2); err != nil {
+				return nil, err
+			}
 			continue
 		}
 
-		var result map[string]interface{}
-		if err := json.Unmarshal(body, &result); err != nil {
-			utils.Warn("[BcutASR-%s] 第 %d 次查询JSON解析失败: %v，将重试", instanceID, i, err)
-			time.Sleep(time.Second * 2)
+		// 网关偶尔返回非JSON的错误页，重试即可；JSON结构不符合预期说明接口已变化，直接失败
+		if !json.Valid(body) {
+			utils.Warn("[BcutASR-%s] 第 %d 次查询响应不是有效的JSON，将重试", instanceID, i)
+			if err := sleepContext(ctx, time.Second*2); err != nil {
+				return nil, err
+			}
 			continue
 		}
 
 		// 提取任务状态
-		data, ok := result["data"].(map[string]interface{})
-		if !ok {
-			utils.Warn("[BcutASR-%s] 第 %d 次查询响应格式错误，将重试", instanceID, i)
-			time.Sleep(time.Second * 2)
-			continue
-		}
-
-		state, ok := data["state"].(float64)
-		if !ok {
-			utils.Warn("[BcutASR-%s] 第 %d 次查询状态字段缺失，将重试", instanceID, i)
-			time.Sleep(time.Second * 2)
-			continue
+		var data bcutQueryData
+		if err := decodeBcutData("task/result", body, &data); err != nil {
+			return nil, err
 		}
+		state := *data.State
 		
 		utils.Debug("[BcutASR-%s] 第 %d 次查询，任务状态: %v", instanceID, i, state)
 
 		if state == 4 { // 任务完成
-			resultStr, ok := data["result"].(string)
-			if !ok || resultStr == "" {
+			if data.Result == "" {
 				utils.Warn("[BcutASR-%s] 任务完成但结果为空", instanceID)
 				return nil, fmt.Errorf("任务完成但结果为空")
 			}
 
-			var resultData map[string]interface{}
-			if err := json.Unmarshal([]byte(resultStr), &resultData); err != nil {
-				return nil, fmt.Errorf("解析结果失败: %w", err)
+			var resultData bcutResult
+			if err := decodeResponse("bcut", "task/result.result", []byte(data.Result), &resultData); err != nil {
+				return nil, err
 			}
 			utils.Info("[BcutASR-%s] 任务结果查询成功，第 %d 次查询", instanceID, i)
-			return resultData, nil
+			return &resultData, nil
 		} else if state == 3 { // 任务失败
 			utils.Error("[BcutASR-%s] 任务处理失败，状态码: %v", instanceID, state)
 			return nil, fmt.Errorf("任务处理失败，状态: %v", state)
@@ -492,7 +584,9 @@ func (b *BcutASR) queryResult(ctx context.Context, callback ProgressCallback) (m
 		if i > 50 {
 			sleepDuration = time.Second * 3
 		}
-		time.Sleep(sleepDuration)
+		if err := sleepContext(ctx, sleepDuration); err != nil {
+			return nil, err
+		}
 	}
 
 	utils.Error("[BcutASR-%s] 查询超时，500次尝试后仍未完成", instanceID)
@@ -500,36 +594,57 @@ func (b *BcutASR) queryResult(ctx context.Context, callback ProgressCallback) (m
 }
 
 // makeSegments 处理识别结果
-func (b *BcutASR) makeSegments(result map[string]interface{}) []models.DataSegment {
-	segments := []models.DataSegment{}
+func (b *BcutASR) makeSegments(result *bcutResult) []models.DataSegment {
+	segments := make([]models.DataSegment, 0, len(result.Utterances))
+
+	for _, utterance := range result.Utterances {
+		// 转换为秒，基于实际测试结果的校正公式
+		// 经验公式：API时间值/1000 + 偏移量(0.105秒)
+		startTime := utterance.StartTime/1000.0 + 0.105
+		endTime := utterance.EndTime/1000.0 + 0.105
 
-	utterances, ok := result["utterances"].([]interface{})
-	if !ok {
-		utils.Warn("解析B站ASR结果失败: 未找到utterances数组")
-		return segments
+		segments = append(segments, models.DataSegment{
+			Text:       utterance.Transcript,
+			StartTime:  startTime,
+			EndTime:    endTime,
+			Confidence: normalizeConfidence(utterance.Confidence),
+			Words:      b.makeWords(utterance),
+		})
 	}
 
-	for _, u := range utterances {
-		utterance, ok := u.(map[string]interface{})
-		if !ok {
-			continue
-		}
+	return segments
+}
 
-		text, _ := utterance["transcript"].(string)
-        startTimeRaw, _ := utterance["start_time"].(float64)
-        endTimeRaw, _ := utterance["end_time"].(float64)
+// makeWords 解析utterance中的逐字时间戳，时间校正方式与段落一致
+func (b *BcutASR) makeWords(utterance bcutUtterance) []models.WordTiming {
+	if len(utterance.Words) == 0 {
+		return nil
+	}
 
-        // 转换为秒，基于实际测试结果的校正公式
-        // 经验公式：API时间值/1000 + 偏移量(0.105秒)
-        startTime := startTimeRaw/1000.0 + 0.105
-        endTime := endTimeRaw/1000.0 + 0.105
+	words := make([]models.WordTiming, 0, len(utterance.Words))
+	for _, word := range utterance.Words {
+		if word.Label == "" {
+			continue
+		}
 
-		segments = append(segments, models.DataSegment{
-			Text:      text,
-			StartTime: startTime,
-			EndTime:   endTime,
+		words = append(words, models.WordTiming{
+			Text:       word.Label,
+			StartTime:  word.StartTime/1000.0 + 0.105,
+			EndTime:    word.EndTime/1000.0 + 0.105,
+			Confidence: normalizeConfidence(word.Confidence),
 		})
 	}
 
-	return segments
+	return words
+}
+
+// normalizeConfidence 将置信度统一到0-1区间，部分接口返回的是百分制
+func normalizeConfidence(confidence float64) float64 {
+	if confidence > 1 {
+		confidence = confidence / 100
+	}
+	if confidence < 0 || confidence > 1 {
+		return 0
+	}
+	return confidence
 }
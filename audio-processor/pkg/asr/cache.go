@@ -0,0 +1,289 @@
+package asr
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/models"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/utils"
+)
+
+// 缓存文件后缀
+const cacheExt = ".json"
+
+// Cache 识别结果缓存，所有ASR服务共用。
+// 条目在最近一次使用后超过有效期即失效；总大小超过上限时删除最久未使用的条目。nil 缓存不做任何事。
+type Cache struct {
+	dir      string
+	maxBytes int64         // 总大小上限，0 表示不限制
+	ttl      time.Duration // 有效期，0 表示永不过期
+
+	mu sync.Mutex
+}
+
+// cacheableService 支持识别结果缓存的服务
+type cacheableService interface {
+	SetCache(cache *Cache)
+}
+
+// SetCache 设置所有服务共用的识别结果缓存，nil 表示不缓存
+func (s *ASRSelector) SetCache(cache *Cache) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cache = cache
+}
+
+// Cache 返回识别结果缓存
+func (s *ASRSelector) Cache() *Cache {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.cache
+}
+
+// NewCache 创建识别结果缓存，dir 为空时使用 DefaultCacheDir
+func NewCache(dir string, maxBytes int64, ttl time.Duration) *Cache {
+	if dir == "" {
+		dir = DefaultCacheDir
+	}
+	return &Cache{dir: dir, maxBytes: maxBytes, ttl: ttl}
+}
+
+// CacheDir 返回配置中的缓存目录
+func CacheDir(config *models.Config) string {
+	if config.ASRCacheDir != "" {
+		return config.ASRCacheDir
+	}
+	return DefaultCacheDir
+}
+
+// CacheFromConfig 按配置创建缓存，未启用缓存时返回 nil
+func CacheFromConfig(config *models.Config) *Cache {
+	if !config.ASRCache {
+		return nil
+	}
+	return NewCache(
+		CacheDir(config),
+		int64(config.ASRCacheMaxSizeMB)*1024*1024,
+		time.Duration(config.ASRCacheTTLDays*float64(24*time.Hour)),
+	)
+}
+
+// Dir 返回缓存目录
+func (c *Cache) Dir() string {
+	return c.dir
+}
+
+// path 返回缓存条目的文件路径
+func (c *Cache) path(key string) string {
+	return filepath.Join(c.dir, key+cacheExt)
+}
+
+// Get 读取缓存的识别结果，过期或损坏的条目会被删除
+func (c *Cache) Get(key string) ([]models.DataSegment, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	path := c.path(key)
+	info, err := os.Stat(path)
+	if err != nil {
+		utils.Debug("缓存未命中: %s", key)
+		return nil, false
+	}
+	if c.expired(info.ModTime(), time.Now()) {
+		utils.Debug("缓存已过期: %s", key)
+		os.Remove(path)
+		return nil, false
+	}
+
+	var segments []models.DataSegment
+	data, err := os.ReadFile(path)
+	if err == nil {
+		err = json.Unmarshal(data, &segments)
+	}
+	if err != nil {
+		utils.Warn("读取缓存失败，将重新识别: %v", err)
+		os.Remove(path)
+		return nil, false
+	}
+
+	// 更新修改时间，用于按最近使用淘汰
+	now := time.Now()
+	os.Chtimes(path, now, now)
+	return segments, true
+}
+
+// Put 保存识别结果，写入后按有效期与大小上限淘汰旧条目
+func (c *Cache) Put(key string, segments []models.DataSegment) error {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := os.MkdirAll(c.dir, 0755); err != nil {
+		return fmt.Errorf("创建缓存目录失败: %w", err)
+	}
+	data, err := json.Marshal(segments)
+	if err != nil {
+		return fmt.Errorf("序列化识别结果失败: %w", err)
+	}
+
+	// 先写临时文件再重命名，避免中断时留下不完整的条目
+	path := c.path(key)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("写入缓存失败: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("写入缓存失败: %w", err)
+	}
+
+	if _, err := c.evictLocked(); err != nil {
+		utils.Warn("%v", err)
+	}
+	return nil
+}
+
+// Evict 删除过期条目，并在超过大小上限时删除最久未使用的条目，返回删除的条目数
+func (c *Cache) Evict() (int, error) {
+	if c == nil {
+		return 0, nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.evictLocked()
+}
+
+// Clear 删除所有缓存条目，返回删除的条目数
+func (c *Cache) Clear() (int, error) {
+	if c == nil {
+		return 0, nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entries, err := c.entriesLocked()
+	if err != nil {
+		return 0, err
+	}
+	removed := 0
+	for _, entry := range entries {
+		if err := os.Remove(entry.path); err != nil && !os.IsNotExist(err) {
+			return removed, fmt.Errorf("删除缓存失败: %w", err)
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+// Size 返回缓存条目数与总大小（字节）
+func (c *Cache) Size() (int, int64, error) {
+	if c == nil {
+		return 0, 0, nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entries, err := c.entriesLocked()
+	if err != nil {
+		return 0, 0, err
+	}
+	var total int64
+	for _, entry := range entries {
+		total += entry.size
+	}
+	return len(entries), total, nil
+}
+
+// cacheEntry 缓存目录中的一个条目
+type cacheEntry struct {
+	path    string
+	size    int64
+	modTime time.Time
+}
+
+// entriesLocked 列出缓存条目，按最近使用时间从旧到新排序，调用方需持有锁
+func (c *Cache) entriesLocked() ([]cacheEntry, error) {
+	files, err := os.ReadDir(c.dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取缓存目录失败: %w", err)
+	}
+
+	entries := make([]cacheEntry, 0, len(files))
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), cacheExt) {
+			continue
+		}
+		info, err := file.Info()
+		if err != nil {
+			continue
+		}
+		entries = append(entries, cacheEntry{
+			path:    filepath.Join(c.dir, file.Name()),
+			size:    info.Size(),
+			modTime: info.ModTime(),
+		})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].modTime.Before(entries[j].modTime)
+	})
+	return entries, nil
+}
+
+// evictLocked 执行淘汰，调用方需持有锁
+func (c *Cache) evictLocked() (int, error) {
+	entries, err := c.entriesLocked()
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	removed := 0
+	var total int64
+	kept := entries[:0]
+	for _, entry := range entries {
+		if c.expired(entry.modTime, now) {
+			if err := os.Remove(entry.path); err == nil {
+				removed++
+			}
+			continue
+		}
+		total += entry.size
+		kept = append(kept, entry)
+	}
+
+	if c.maxBytes > 0 {
+		for _, entry := range kept {
+			if total <= c.maxBytes {
+				break
+			}
+			if err := os.Remove(entry.path); err == nil {
+				total -= entry.size
+				removed++
+			}
+		}
+	}
+
+	if removed > 0 {
+		utils.Debug("已淘汰 %d 个ASR缓存条目", removed)
+	}
+	return removed, nil
+}
+
+// expired 判断条目是否已过期
+func (c *Cache) expired(lastUsed, now time.Time) bool {
+	return c.ttl > 0 && now.Sub(lastUsed) > c.ttl
+}
@@ -0,0 +1,67 @@
+package asr
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/models"
+)
+
+func TestCacheGetPut(t *testing.T) {
+	cache := NewCache(t.TempDir(), 0, 0)
+	segments := []models.DataSegment{{Text: "你好", StartTime: 0, EndTime: 1.5}}
+
+	_, ok := cache.Get("BcutASR-0000")
+	assert.False(t, ok)
+
+	require.NoError(t, cache.Put("BcutASR-0000", segments))
+	got, ok := cache.Get("BcutASR-0000")
+	require.True(t, ok)
+	assert.Equal(t, segments, got)
+
+	// nil 缓存不做任何事
+	var disabled *Cache
+	assert.NoError(t, disabled.Put("key", segments))
+	_, ok = disabled.Get("key")
+	assert.False(t, ok)
+}
+
+func TestCacheEviction(t *testing.T) {
+	dir := t.TempDir()
+	cache := NewCache(dir, 0, time.Hour)
+	segments := []models.DataSegment{{Text: "测试"}}
+
+	require.NoError(t, cache.Put("old", segments))
+	old := time.Now().Add(-2 * time.Hour)
+	require.NoError(t, os.Chtimes(filepath.Join(dir, "old.json"), old, old))
+	_, ok := cache.Get("old")
+	assert.False(t, ok, "过期条目不应命中")
+	assert.NoFileExists(t, filepath.Join(dir, "old.json"))
+
+	// 超过大小上限时删除最久未使用的条目
+	require.NoError(t, cache.Put("a", segments))
+	info, err := os.Stat(filepath.Join(dir, "a.json"))
+	require.NoError(t, err)
+	cache.maxBytes = info.Size() * 2
+
+	earlier := time.Now().Add(-time.Minute)
+	require.NoError(t, os.Chtimes(filepath.Join(dir, "a.json"), earlier, earlier))
+	require.NoError(t, cache.Put("b", segments))
+	require.NoError(t, cache.Put("c", segments))
+
+	assert.NoFileExists(t, filepath.Join(dir, "a.json"))
+	assert.FileExists(t, filepath.Join(dir, "b.json"))
+	assert.FileExists(t, filepath.Join(dir, "c.json"))
+
+	removed, err := cache.Clear()
+	require.NoError(t, err)
+	assert.Equal(t, 2, removed)
+	count, _, err := cache.Size()
+	require.NoError(t, err)
+	assert.Equal(t, 0, count)
+}
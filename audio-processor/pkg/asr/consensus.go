@@ -0,0 +1,202 @@
+package asr
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/models"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/utils"
+)
+
+// ConsensusServiceName 共识模式的服务名称，同一音频并发交给多个服务识别后合并结果
+const ConsensusServiceName = "consensus"
+
+// consensusCandidate 单个服务在共识模式下的识别结果
+type consensusCandidate struct {
+	Service  string
+	Segments []models.DataSegment
+	Err      error
+}
+
+// availableServiceNames 返回当前可选且支持指定语言的服务名称（含熔断到期可试探的服务），按注册顺序排列
+func (s *ASRSelector) availableServiceNames(language string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	names := make([]string, 0, len(s.serviceList))
+	for _, name := range s.serviceList {
+		if s.isSelectableLocked(name, now) && s.supportsLanguageLocked(name, language) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// recognizeConsensus 将同一音频并发发送给所有可用服务，对齐结果后按时间窗口择优合并
+func (s *ASRSelector) recognizeConsensus(ctx context.Context, audioPath string, useCache bool, callback ProgressCallback) ([]models.DataSegment, string, error) {
+	names := s.availableServiceNames(LanguageFromContext(ctx))
+	if len(names) == 0 {
+		return nil, "", fmt.Errorf("没有可用的ASR服务")
+	}
+	if len(names) == 1 {
+		utils.Warn("共识模式仅有一个可用服务 %s，退化为单服务识别", names[0])
+		return s.Recognize(ctx, audioPath, names[0], useCache, callback)
+	}
+
+	utils.Info("共识模式: 使用 %d 个服务识别 %s: %s", len(names), audioPath, strings.Join(names, ", "))
+
+	candidates := make([]consensusCandidate, len(names))
+	var progressMu sync.Mutex
+	progress := make([]int, len(names))
+
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		go func(index int, serviceName string) {
+			defer wg.Done()
+
+			// 汇总各服务进度，取平均值上报
+			serviceCallback := func(percent int, message string) {
+				if callback == nil {
+					return
+				}
+				progressMu.Lock()
+				progress[index] = percent
+				total := 0
+				for _, p := range progress {
+					total += p
+				}
+				progressMu.Unlock()
+				callback(total/len(progress), fmt.Sprintf("[%s] %s", serviceName, message))
+			}
+
+			segments, _, err := s.Recognize(ctx, audioPath, serviceName, useCache, serviceCallback)
+			candidates[index] = consensusCandidate{Service: serviceName, Segments: segments, Err: err}
+		}(i, name)
+	}
+	wg.Wait()
+
+	succeeded := make([]consensusCandidate, 0, len(candidates))
+	for _, c := range candidates {
+		if c.Err != nil {
+			utils.Warn("共识模式: 服务 %s 识别失败: %v", c.Service, c.Err)
+			continue
+		}
+		if len(c.Segments) == 0 {
+			utils.Warn("共识模式: 服务 %s 识别结果为空", c.Service)
+			continue
+		}
+		succeeded = append(succeeded, c)
+	}
+
+	if len(succeeded) == 0 {
+		return nil, ConsensusServiceName, fmt.Errorf("共识模式下所有ASR服务均识别失败")
+	}
+
+	used := make([]string, 0, len(succeeded))
+	for _, c := range succeeded {
+		used = append(used, c.Service)
+	}
+
+	merged := mergeConsensusSegments(succeeded)
+	utils.Info("共识模式: 合并 %d 个服务结果，得到 %d 段文本", len(succeeded), len(merged))
+
+	return merged, ConsensusServiceName + "(" + strings.Join(used, "+") + ")", nil
+}
+
+// consensusItem 带来源服务信息的识别片段
+type consensusItem struct {
+	service string
+	segment models.DataSegment
+}
+
+// mergeConsensusSegments 对齐多个服务的识别结果并合并。
+// 所有片段按开始时间排序后，时间上互相重叠的片段归为同一时间窗口；
+// 每个窗口内选择置信度最高（无置信度时文本最长）的服务的片段作为该窗口的结果。
+func mergeConsensusSegments(candidates []consensusCandidate) []models.DataSegment {
+	items := make([]consensusItem, 0)
+	for _, c := range candidates {
+		for _, seg := range c.Segments {
+			items = append(items, consensusItem{service: c.Service, segment: seg})
+		}
+	}
+	if len(items) == 0 {
+		return nil
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].segment.StartTime < items[j].segment.StartTime
+	})
+
+	merged := make([]models.DataSegment, 0, len(items))
+	windowStart := 0
+	windowEnd := items[0].segment.EndTime
+
+	for i := 1; i <= len(items); i++ {
+		if i < len(items) && items[i].segment.StartTime < windowEnd {
+			if items[i].segment.EndTime > windowEnd {
+				windowEnd = items[i].segment.EndTime
+			}
+			continue
+		}
+
+		merged = append(merged, pickConsensusWindow(items[windowStart:i], candidates)...)
+
+		if i < len(items) {
+			windowStart = i
+			windowEnd = items[i].segment.EndTime
+		}
+	}
+
+	return merged
+}
+
+// pickConsensusWindow 在一个时间窗口内选出最优的服务，返回该服务在窗口内的片段。
+// 窗口内各服务都提供了置信度时选择平均置信度最高的服务，否则选择文本最长的服务；
+// 结果相同时按服务在候选列表中的顺序优先。
+func pickConsensusWindow(window []consensusItem, candidates []consensusCandidate) []models.DataSegment {
+	textLen := make(map[string]int)
+	confSum := make(map[string]float64)
+	confCount := make(map[string]int)
+	allConfident := true
+	for _, item := range window {
+		textLen[item.service] += utf8.RuneCountInString(strings.TrimSpace(item.segment.Text))
+		if item.segment.Confidence > 0 {
+			confSum[item.service] += item.segment.Confidence
+			confCount[item.service]++
+		} else {
+			allConfident = false
+		}
+	}
+
+	best := ""
+	bestScore := -1.0
+	for _, c := range candidates {
+		n, ok := textLen[c.Service]
+		if !ok {
+			continue
+		}
+		score := float64(n)
+		if allConfident {
+			score = confSum[c.Service] / float64(confCount[c.Service])
+		}
+		if score > bestScore {
+			best = c.Service
+			bestScore = score
+		}
+	}
+
+	segments := make([]models.DataSegment, 0, len(window))
+	for _, item := range window {
+		if item.service == best {
+			segments = append(segments, item.segment)
+		}
+	}
+	return segments
+}
@@ -0,0 +1,60 @@
+package asr
+
+import (
+	"testing"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeConsensusSegments(t *testing.T) {
+	candidates := []consensusCandidate{
+		{
+			Service: "bcut",
+			Segments: []models.DataSegment{
+				{Text: "大家好", StartTime: 0, EndTime: 2},
+				{Text: "今天我们聊一聊语音识别", StartTime: 2.5, EndTime: 5},
+			},
+		},
+		{
+			Service: "kuaishou",
+			Segments: []models.DataSegment{
+				{Text: "大家好我是主持人", StartTime: 0.1, EndTime: 2.1},
+				{Text: "今天聊", StartTime: 2.6, EndTime: 5.2},
+				{Text: "谢谢", StartTime: 8, EndTime: 9},
+			},
+		},
+	}
+
+	merged := mergeConsensusSegments(candidates)
+
+	assert.Equal(t, []models.DataSegment{
+		{Text: "大家好我是主持人", StartTime: 0.1, EndTime: 2.1},
+		{Text: "今天我们聊一聊语音识别", StartTime: 2.5, EndTime: 5},
+		{Text: "谢谢", StartTime: 8, EndTime: 9},
+	}, merged)
+}
+
+func TestMergeConsensusSegmentsTiePrefersFirstCandidate(t *testing.T) {
+	candidates := []consensusCandidate{
+		{Service: "bcut", Segments: []models.DataSegment{{Text: "你好", StartTime: 0, EndTime: 1}}},
+		{Service: "kuaishou", Segments: []models.DataSegment{{Text: "您好", StartTime: 0, EndTime: 1}}},
+	}
+
+	merged := mergeConsensusSegments(candidates)
+
+	assert.Len(t, merged, 1)
+	assert.Equal(t, "你好", merged[0].Text)
+}
+
+func TestMergeConsensusSegmentsPrefersConfidence(t *testing.T) {
+	candidates := []consensusCandidate{
+		{Service: "bcut", Segments: []models.DataSegment{{Text: "今天天气很好啊", StartTime: 0, EndTime: 2, Confidence: 0.6}}},
+		{Service: "kuaishou", Segments: []models.DataSegment{{Text: "今天天气好", StartTime: 0, EndTime: 2, Confidence: 0.9}}},
+	}
+
+	merged := mergeConsensusSegments(candidates)
+
+	assert.Len(t, merged, 1)
+	assert.Equal(t, "今天天气好", merged[0].Text)
+}
@@ -0,0 +1,118 @@
+package asr
+
+import (
+	"sort"
+	"time"
+)
+
+// ServiceEstimate 预计分配给某个服务的请求数及其配额情况
+type ServiceEstimate struct {
+	Name              string
+	Requests          int // 预计请求数
+	QuotaUsed         int // 当日已用请求数
+	DailyQuota        int // 每日配额，0 表示不限制
+	RequestsPerMinute int // 每分钟请求数上限，0 表示不限制
+}
+
+// QuotaRemaining 返回当日剩余配额，不限制时返回 -1
+func (e ServiceEstimate) QuotaRemaining() int {
+	if e.DailyQuota <= 0 {
+		return -1
+	}
+	if remaining := e.DailyQuota - e.QuotaUsed; remaining > 0 {
+		return remaining
+	}
+	return 0
+}
+
+// ExceedsQuota 判断预计请求数是否超过当日剩余配额
+func (e ServiceEstimate) ExceedsQuota() bool {
+	remaining := e.QuotaRemaining()
+	return remaining >= 0 && e.Requests > remaining
+}
+
+// MinDuration 返回受每分钟请求数限制时发完所有请求至少需要的时间
+func (e ServiceEstimate) MinDuration() time.Duration {
+	if e.RequestsPerMinute <= 0 || e.Requests <= e.RequestsPerMinute {
+		return 0
+	}
+	return time.Duration(float64(e.Requests-e.RequestsPerMinute) / float64(e.RequestsPerMinute) * float64(time.Minute))
+}
+
+// EstimateRequests 按选择策略估算 requests 次识别请求在各服务间的分配，不发起任何请求。
+// serviceName 为 auto 时按策略分配（加权随机按权重，轮询平均分配），
+// 为共识模式时每个服务都会收到全部请求，否则全部分配给指定服务。
+func (s *ASRSelector) EstimateRequests(serviceName string, requests int) []ServiceEstimate {
+	var shares map[string]int
+	switch serviceName {
+	case "auto":
+		shares = s.autoShares(requests)
+	case ConsensusServiceName:
+		shares = make(map[string]int)
+		for _, name := range s.availableServiceNames("") {
+			shares[name] = requests
+		}
+	default:
+		shares = map[string]int{serviceName: requests}
+	}
+
+	estimates := make([]ServiceEstimate, 0, len(shares))
+	for name, count := range shares {
+		used, quota := s.QuotaStatus(name)
+		estimates = append(estimates, ServiceEstimate{
+			Name:              name,
+			Requests:          count,
+			QuotaUsed:         used,
+			DailyQuota:        quota,
+			RequestsPerMinute: s.serviceOptions(name).RequestsPerMinute,
+		})
+	}
+	sort.Slice(estimates, func(i, j int) bool {
+		return estimates[i].Name < estimates[j].Name
+	})
+	return estimates
+}
+
+// autoShares 按自动选择策略将请求分配到可用服务，余数按最大余额法分配
+func (s *ASRSelector) autoShares(requests int) map[string]int {
+	names := s.availableServiceNames("")
+
+	s.mu.RLock()
+	strategy := s.strategy
+	weights := make([]int, len(names))
+	total := 0
+	for i, name := range names {
+		weights[i] = 1
+		if strategy != "round_robin" {
+			weights[i] = s.weights[name]
+		}
+		total += weights[i]
+	}
+	s.mu.RUnlock()
+
+	shares := make(map[string]int)
+	if total == 0 {
+		return shares
+	}
+
+	type remainder struct {
+		name  string
+		value int
+	}
+	remainders := make([]remainder, 0, len(names))
+	assigned := 0
+	for i, name := range names {
+		count := requests * weights[i] / total
+		shares[name] = count
+		assigned += count
+		remainders = append(remainders, remainder{name, requests * weights[i] % total})
+	}
+	sort.SliceStable(remainders, func(i, j int) bool {
+		return remainders[i].value > remainders[j].value
+	})
+	for i := 0; assigned < requests; i++ {
+		shares[remainders[i].name]++
+		assigned++
+	}
+	return shares
+}
@@ -0,0 +1,45 @@
+package asr
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEstimateRequests(t *testing.T) {
+	creator := func(audioPath string, useCache bool) (ASRService, error) { return nil, nil }
+	selector := NewASRSelector()
+	selector.RegisterService("bcut", creator, 30)
+	selector.RegisterService("kuaishou", creator, 10)
+	selector.SetServiceOptions("bcut", ServiceOptions{Timeout: time.Minute, DailyQuota: 50, RequestsPerMinute: 10})
+
+	// 加权随机按权重分配
+	estimates := selector.EstimateRequests("auto", 101)
+	require.Len(t, estimates, 2)
+	assert.Equal(t, "bcut", estimates[0].Name)
+	assert.Equal(t, 76, estimates[0].Requests)
+	assert.Equal(t, 25, estimates[1].Requests)
+	assert.True(t, estimates[0].ExceedsQuota())
+	assert.False(t, estimates[1].ExceedsQuota())
+	assert.Equal(t, -1, estimates[1].QuotaRemaining())
+	assert.Equal(t, time.Duration(6.6*float64(time.Minute)), estimates[0].MinDuration())
+
+	// 轮询平均分配
+	selector.SetStrategy("round_robin")
+	estimates = selector.EstimateRequests("auto", 10)
+	assert.Equal(t, 5, estimates[0].Requests)
+	assert.Equal(t, 5, estimates[1].Requests)
+
+	// 共识模式下每个服务都收到全部请求
+	estimates = selector.EstimateRequests(ConsensusServiceName, 10)
+	assert.Equal(t, 10, estimates[0].Requests)
+	assert.Equal(t, 10, estimates[1].Requests)
+
+	// 指定服务
+	estimates = selector.EstimateRequests("kuaishou", 10)
+	require.Len(t, estimates, 1)
+	assert.Equal(t, "kuaishou", estimates[0].Name)
+	assert.Equal(t, 10, estimates[0].Requests)
+}
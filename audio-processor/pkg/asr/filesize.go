@@ -0,0 +1,33 @@
+package asr
+
+// defaultServiceMaxFileSize 各服务可上传的最大文件（字节），配置中未指定 max_file_size_mb 时使用
+var defaultServiceMaxFileSize = map[string]int64{
+	"bcut":     100 << 20,
+	"kuaishou": 20 << 20,
+}
+
+// maxFileSizeLocked 返回服务可上传的最大文件，0 表示不限制，调用方需持有锁
+func (s *ASRSelector) maxFileSizeLocked(name string) int64 {
+	if options, ok := s.options[name]; ok && options.MaxFileSize > 0 {
+		return options.MaxFileSize
+	}
+	return defaultServiceMaxFileSize[name]
+}
+
+// MaxFileSize 返回使用 serviceName 识别时可上传的最大文件（字节），0 表示不限制。
+// 自动选择或共识模式下可能使用任一已注册的服务，返回其中最小的上限
+func (s *ASRSelector) MaxFileSize(serviceName string) int64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if serviceName != "auto" && serviceName != ConsensusServiceName {
+		return s.maxFileSizeLocked(serviceName)
+	}
+
+	var limit int64
+	for _, name := range s.serviceList {
+		if size := s.maxFileSizeLocked(name); size > 0 && (limit == 0 || size < limit) {
+			limit = size
+		}
+	}
+	return limit
+}
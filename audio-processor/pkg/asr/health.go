@@ -0,0 +1,167 @@
+package asr
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"time"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/utils"
+)
+
+// HealthPolicy 服务健康度策略
+type HealthPolicy struct {
+	HalfLife         time.Duration // 成功率衰减半衰期，越久以前的调用结果权重越低
+	FailureThreshold int           // 连续失败多少次后熔断
+	MinSuccessRate   float64       // 衰减后成功率低于该值时熔断
+	MinSamples       float64       // 计算成功率所需的最少（衰减后）样本数
+	ProbeInterval    time.Duration // 熔断后多久允许一次试探调用
+}
+
+// DefaultHealthPolicy 返回默认的健康度策略
+func DefaultHealthPolicy() HealthPolicy {
+	return HealthPolicy{
+		HalfLife:         24 * time.Hour,
+		FailureThreshold: 3,
+		MinSuccessRate:   0.2,
+		MinSamples:       5,
+		ProbeInterval:    10 * time.Minute,
+	}
+}
+
+// SetHealthPolicy 设置服务健康度策略
+func (s *ASRSelector) SetHealthPolicy(policy HealthPolicy) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.policy = policy
+}
+
+// SetStatsFile 设置服务统计的持久化文件，并加载其中已有的统计数据。
+// 只有已注册服务的统计会被恢复，因此应在注册服务之后调用。
+func (s *ASRSelector) SetStatsFile(path string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.statsFile = path
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("读取ASR服务统计失败: %w", err)
+	}
+
+	var saved map[string]*ServiceStats
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return fmt.Errorf("解析ASR服务统计失败: %w", err)
+	}
+
+	for name, stat := range saved {
+		if _, exists := s.stats[name]; !exists || stat == nil {
+			continue
+		}
+		s.stats[name] = stat
+		if !stat.Available {
+			utils.Warn("ASR服务 %s 处于熔断状态，%s 后允许试探调用",
+				name, stat.OpenUntil.Format("2006-01-02 15:04:05"))
+		}
+	}
+
+	utils.Info("已加载ASR服务统计: %s", path)
+	return nil
+}
+
+// saveStatsLocked 保存服务统计到持久化文件，调用方需持有锁
+func (s *ASRSelector) saveStatsLocked() {
+	if s.statsFile == "" {
+		return
+	}
+	if err := utils.SaveJSONFile(s.statsFile, s.stats); err != nil {
+		utils.Warn("保存ASR服务统计失败: %v", err)
+	}
+}
+
+// IsSelectable 判断已注册的服务当前是否可被选择（健康且有剩余请求额度）
+func (s *ASRSelector) IsSelectable(name string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.services[name]; !ok {
+		return false
+	}
+	return s.isSelectableLocked(name, time.Now())
+}
+
+// isSelectableLocked 判断服务当前是否可被选择：健康且有剩余请求额度，调用方需持有写锁
+func (s *ASRSelector) isSelectableLocked(name string, now time.Time) bool {
+	return s.isHealthyLocked(name, now) && s.hasQuotaLocked(name, now)
+}
+
+// isHealthyLocked 判断服务是否健康：未熔断，或熔断已到期允许试探
+func (s *ASRSelector) isHealthyLocked(name string, now time.Time) bool {
+	stat, exists := s.stats[name]
+	if !exists {
+		return false
+	}
+	return stat.Available || !now.Before(stat.OpenUntil)
+}
+
+// decay 按半衰期衰减历史成功/调用分数
+func (stat *ServiceStats) decay(now time.Time, halfLife time.Duration) {
+	if !stat.LastUpdated.IsZero() && halfLife > 0 {
+		elapsed := now.Sub(stat.LastUpdated)
+		if elapsed > 0 {
+			factor := math.Pow(0.5, float64(elapsed)/float64(halfLife))
+			stat.SuccessScore *= factor
+			stat.TotalScore *= factor
+		}
+	}
+	stat.LastUpdated = now
+}
+
+// successRate 返回衰减后的成功率，没有调用记录时返回1
+func (stat *ServiceStats) successRate() float64 {
+	if stat.TotalScore <= 0 {
+		return 1
+	}
+	return stat.SuccessScore / stat.TotalScore
+}
+
+// record 记录一次调用结果并更新熔断状态，返回状态变化描述（无变化时为空）
+func (stat *ServiceStats) record(success bool, now time.Time, policy HealthPolicy) string {
+	stat.decay(now, policy.HalfLife)
+
+	stat.TotalCount++
+	stat.TotalScore++
+	if success {
+		stat.SuccessCount++
+		stat.SuccessScore++
+		stat.ConsecutiveFailures = 0
+		if !stat.Available {
+			stat.Available = true
+			stat.OpenUntil = time.Time{}
+			return "恢复可用"
+		}
+		return ""
+	}
+
+	stat.ConsecutiveFailures++
+
+	if !stat.Available {
+		// 试探调用失败，重新熔断
+		stat.OpenUntil = now.Add(policy.ProbeInterval)
+		return fmt.Sprintf("试探调用失败，继续熔断至 %s", stat.OpenUntil.Format("15:04:05"))
+	}
+
+	tooManyFailures := policy.FailureThreshold > 0 && stat.ConsecutiveFailures >= policy.FailureThreshold
+	lowSuccessRate := stat.TotalScore >= policy.MinSamples && stat.successRate() < policy.MinSuccessRate
+	if tooManyFailures || lowSuccessRate {
+		stat.Available = false
+		stat.OpenUntil = now.Add(policy.ProbeInterval)
+		return fmt.Sprintf("连续失败 %d 次，成功率 %.1f%%，熔断至 %s",
+			stat.ConsecutiveFailures, stat.successRate()*100, stat.OpenUntil.Format("15:04:05"))
+	}
+
+	return ""
+}
@@ -0,0 +1,64 @@
+package asr
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServiceStatsCircuitBreaker(t *testing.T) {
+	policy := DefaultHealthPolicy()
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	stat := &ServiceStats{Available: true}
+
+	for i := 0; i < policy.FailureThreshold; i++ {
+		stat.record(false, now, policy)
+	}
+	assert.False(t, stat.Available)
+	assert.Equal(t, now.Add(policy.ProbeInterval), stat.OpenUntil)
+
+	// 试探失败后继续熔断
+	probeTime := stat.OpenUntil
+	stat.record(false, probeTime, policy)
+	assert.False(t, stat.Available)
+	assert.Equal(t, probeTime.Add(policy.ProbeInterval), stat.OpenUntil)
+
+	// 试探成功后恢复
+	stat.record(true, stat.OpenUntil, policy)
+	assert.True(t, stat.Available)
+	assert.Equal(t, 0, stat.ConsecutiveFailures)
+}
+
+func TestServiceStatsDecay(t *testing.T) {
+	policy := DefaultHealthPolicy()
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	stat := &ServiceStats{Available: true, SuccessScore: 4, TotalScore: 8, LastUpdated: now}
+
+	stat.decay(now.Add(policy.HalfLife), policy.HalfLife)
+
+	assert.InDelta(t, 2, stat.SuccessScore, 1e-9)
+	assert.InDelta(t, 4, stat.TotalScore, 1e-9)
+}
+
+func TestSelectorPersistsStats(t *testing.T) {
+	statsFile := filepath.Join(t.TempDir(), "asr_service_stats.json")
+	creator := func(audioPath string, useCache bool) (ASRService, error) { return nil, nil }
+
+	selector := NewASRSelector()
+	selector.RegisterService("bcut", creator, 10)
+	assert.NoError(t, selector.SetStatsFile(statsFile))
+	for i := 0; i < 3; i++ {
+		selector.ReportResult("bcut", false)
+	}
+
+	// 重启后熔断状态仍然保留，服务不会被选择
+	restarted := NewASRSelector()
+	restarted.RegisterService("bcut", creator, 10)
+	assert.NoError(t, restarted.SetStatsFile(statsFile))
+
+	_, _, ok := restarted.SelectService("weighted_random")
+	assert.False(t, ok)
+	assert.Equal(t, 3, restarted.stats["bcut"].TotalCount)
+}
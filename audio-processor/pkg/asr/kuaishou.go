@@ -3,7 +3,6 @@ package asr
 import (
 	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"mime/multipart"
@@ -42,6 +41,18 @@ type KuaiShouResponse struct {
 	} `json:"data"`
 }
 
+func (r *KuaiShouResponse) validate() error {
+	if r.Data.Text == nil {
+		return fmt.Errorf("缺少字段 data.text")
+	}
+	for i, item := range r.Data.Text {
+		if item.EndTime < item.StartTime {
+			return fmt.Errorf("data.text[%d] 的结束时间 %.2f 早于开始时间 %.2f", i, item.EndTime, item.StartTime)
+		}
+	}
+	return nil
+}
+
 // GetResult 实现ASRService接口
 func (k *KuaiShouASR) GetResult(ctx context.Context, callback ProgressCallback) ([]models.DataSegment, error) {
 	instanceID := fmt.Sprintf("KuaiShouASR-%s", utils.GenerateRandomString(6))
@@ -50,7 +61,7 @@ func (k *KuaiShouASR) GetResult(ctx context.Context, callback ProgressCallback)
 	// 检查是否有缓存
 	cacheKey := k.GetCacheKey("KuaiShouASR")
 	if k.UseCache {
-		if segments, ok := k.LoadFromCache("./cache", cacheKey); ok {
+		if segments, ok := k.LoadFromCache(cacheKey); ok {
 			utils.Info("[%s] 从缓存加载快手ASR结果", instanceID)
 			if callback != nil {
 				callback(100, "识别完成 (缓存)")
@@ -105,7 +116,7 @@ func (k *KuaiShouASR) GetResult(ctx context.Context, callback ProgressCallback)
 
 	// 缓存结果
 	if k.UseCache && len(segments) > 0 {
-		if err := k.SaveToCache("./cache", cacheKey, segments); err != nil {
+		if err := k.SaveToCache(cacheKey, segments); err != nil {
 			utils.Warn("[%s] 保存快手ASR结果到缓存失败: %v", instanceID, err)
 		} else {
 			utils.Info("[%s] 结果已缓存", instanceID)
@@ -155,14 +166,9 @@ func (k *KuaiShouASR) submit(ctx context.Context) (*KuaiShouResponse, error) {
 	requestID := utils.GenerateRandomString(6)
 	utils.Info("KuaiShou-REQ-%s: 正在发送请求，文件大小=%dKB", requestID, len(k.FileBinary)/1024)
 
-	// 创建一个自定义的HTTP客户端，设置更合理的超时时间
-	client := &http.Client{
-		Timeout: 3 * time.Minute, // 设置超时时间
-	}
-	
 	// 发送请求并计时
 	startTime := time.Now()
-	resp, err := client.Do(req)
+	resp, err := utils.DefaultHTTPClient().Do(req)
 	requestDuration := time.Since(startTime)
 	utils.Info("KuaiShou-REQ-%s: 请求耗时 %.2f 秒", requestID, requestDuration.Seconds())
 	
@@ -194,17 +200,10 @@ func (k *KuaiShouASR) submit(ctx context.Context) (*KuaiShouResponse, error) {
 		return nil, fmt.Errorf("接收到空响应")
 	}
 
-	// 解析JSON
+	// 解析并校验JSON，结构不符合预期时保存原始响应
 	var result KuaiShouResponse
-	if err := json.Unmarshal(body, &result); err != nil {
-		utils.Error("解析响应JSON失败: %v, 原始数据: %s", err, string(body))
-		return nil, fmt.Errorf("解析JSON响应失败: %w", err)
-	}
-
-	// 检查解析后的结果
-	if result.Data.Text == nil {
-		utils.Error("快手ASR响应中没有文本数据")
-		return nil, fmt.Errorf("响应中没有文本数据")
+	if err := decodeResponse("kuaishou", "subtitle_generate", body, &result); err != nil {
+		return nil, err
 	}
 
 	utils.Info("成功解析快手ASR响应，文本段落数量: %d", len(result.Data.Text))
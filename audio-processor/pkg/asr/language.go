@@ -0,0 +1,91 @@
+package asr
+
+import (
+	"context"
+)
+
+// languageKey 上下文中音频语言的键
+type languageKey struct{}
+
+// defaultServiceLanguages 各服务默认支持的语言，配置中未指定 languages 时使用
+var defaultServiceLanguages = map[string][]string{
+	"bcut":     {"zh", "en"},
+	"kuaishou": {"zh"},
+}
+
+// WithLanguage 返回携带音频语言的上下文，自动选择服务时只会选择支持该语言的服务
+func WithLanguage(ctx context.Context, language string) context.Context {
+	if language == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, languageKey{}, language)
+}
+
+// sourceMediaKey 上下文中源媒体文件路径的键
+type sourceMediaKey struct{}
+
+// WithSourceMedia 在上下文中记录提取音频前的源媒体文件，用于生成需要引用源文件的输出（如播放页）
+func WithSourceMedia(ctx context.Context, path string) context.Context {
+	if path == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, sourceMediaKey{}, path)
+}
+
+// SourceMediaFromContext 返回上下文中的源媒体文件路径，未设置时为空
+func SourceMediaFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	path, _ := ctx.Value(sourceMediaKey{}).(string)
+	return path
+}
+
+// LanguageFromContext 返回上下文中的音频语言，未设置时为空
+func LanguageFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	language, _ := ctx.Value(languageKey{}).(string)
+	return language
+}
+
+// supportsLanguageLocked 判断服务是否支持指定语言，调用方需持有锁。
+// 语言为空或服务未声明支持的语言时视为支持。
+func (s *ASRSelector) supportsLanguageLocked(name, language string) bool {
+	if language == "" {
+		return true
+	}
+	languages := s.options[name].Languages
+	if len(languages) == 0 {
+		languages = defaultServiceLanguages[name]
+	}
+	if len(languages) == 0 {
+		return true
+	}
+	for _, l := range languages {
+		if l == language {
+			return true
+		}
+	}
+	return false
+}
+
+// SupportsLanguage 判断服务是否支持指定语言
+func (s *ASRSelector) SupportsLanguage(name, language string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.supportsLanguageLocked(name, language)
+}
+
+// hasServiceForLanguage 判断是否有已注册的服务支持指定语言
+func (s *ASRSelector) hasServiceForLanguage(language string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, name := range s.serviceList {
+		if s.supportsLanguageLocked(name, language) {
+			return true
+		}
+	}
+	return false
+}
@@ -8,8 +8,16 @@ import (
 	"strings"
 	"time"
 
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/analysis"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/audit"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/chapters"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/diarize"
 	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/export"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/llm"
 	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/models"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/summary"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/textproc"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/translate"
 	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/utils"
 )
 
@@ -18,6 +26,25 @@ type ASRProcessor struct {
 	Config      *models.Config
 	SRTExporter *export.SRTExporter
 	JSONExporter *export.JSONExporter
+	LRCExporter  *export.LRCExporter
+	ASSExporter  *export.ASSExporter
+	DOCXExporter *export.DOCXExporter
+	PDFExporter  *export.PDFExporter
+	VTTExporter  *export.VTTExporter
+	PlayerExporter *export.PlayerExporter
+	JianyingExporter *export.JianyingExporter // 剪映草稿，subtitle_preset 为 jianying 时导出
+	Outliner     export.OutlineLLM // 为Markdown生成标题、摘要与章节的大模型，未配置API密钥时为 nil
+	ChapterLLM   chapters.LLM      // 划分章节的大模型，未配置API密钥时为 nil，按停顿划分
+	ChaptersExporter *export.ChaptersExporter
+	AnalysisLLM  analysis.LLM      // 提取关键词与实体的大模型，未配置API密钥时为 nil，在本地按 TF-IDF 提取关键词
+	AnalysisExporter *export.AnalysisExporter
+	SummaryLLM   summary.LLM       // 生成摘要的大模型，未配置API密钥时为 nil，不生成摘要
+	SummaryExporter *export.SummaryExporter
+	Translator   translate.Translator // 翻译服务，未设置 translate_to 时为 nil
+	LLMMeter     *llm.Meter           // 累计本任务的大模型用量，未配置大模型时为 nil
+	Diarizer     diarize.Diarizer // 说话人分离，未启用时为 nil
+	TextPipeline *textproc.Pipeline // 导出前的文本后处理，未配置时为 nil
+	Namer        *export.Namer      // 输出文件命名规则，各导出器共用
 }
 // ProgressCallback 是进度回调函数，用于通知识别过程的进度
 type ProgressCallback func(percent int, message string)
@@ -31,10 +58,105 @@ type ASRService interface {
 // NewASRProcessor 创建新的ASR处理器
 func NewASRProcessor(config *models.Config) *ASRProcessor {
 	output:=config.MediaFolder
+	diarizer, err := diarize.FromConfig(config)
+	if err != nil {
+		utils.Warn("初始化说话人分离失败: %v", err)
+	}
+	pipeline, err := textproc.FromConfig(config)
+	if err != nil {
+		utils.Warn("初始化文本后处理失败: %v", err)
+	}
+	namer, err := export.NewNamer(config.OutputTemplate)
+	if err != nil {
+		utils.Warn("%v，使用默认命名规则", err)
+		namer, _ = export.NewNamer("")
+	}
+	srtExporter := export.NewSRTExporter(output)
+	srtExporter.Options = export.SRTOptionsFromConfig(config)
+	srtExporter.ApplyPreset(config.SubtitlePreset)
+	jianyingExporter := export.NewJianyingExporter(output)
+	jianyingExporter.Options = srtExporter.Options
+	assExporter := export.NewASSExporter(output)
+	if config.ExportASS {
+		assExporter.Style = export.ASSStyleFromConfig(config)
+		assExporter.Karaoke = config.ASSKaraoke
+	}
+	documentOptions := export.DocumentOptions{
+		IncludeTimestamps: config.IncludeTimestamps,
+		IncludeSpeakers:   config.DocumentSpeakers,
+	}
+	docxExporter := export.NewDOCXExporter(config.OutputFolder)
+	docxExporter.Options = documentOptions
+	pdfExporter := export.NewPDFExporter(config.OutputFolder)
+	pdfExporter.Options = documentOptions
+	vttExporter := export.NewVTTExporter(output)
+	vttExporter.Options = srtExporter.Options
+	playerExporter := export.NewPlayerExporter(config.OutputFolder, config.MediaFolder)
+	playerExporter.Options = srtExporter.Options
+	// 字幕需与视频放在一起才能被播放器自动加载，不使用命名模板
+	docxExporter.Namer = namer
+	pdfExporter.Namer = namer
+	jsonExporter := export.NewJSONExporter(config.OutputFolder)
+	jsonExporter.Namer = namer
+	lrcExporter := export.NewLRCExporter(config.OutputFolder)
+	lrcExporter.Namer = namer
+	// 配置了大模型时，Markdown结构、章节划分与关键词提取使用大模型
+	var outliner export.OutlineLLM
+	var chapterLLM chapters.LLM
+	var analysisLLM analysis.LLM
+	var summaryLLM summary.LLM
+	var meter *llm.Meter
+	client, err := llm.FromConfig(config)
+	if err != nil {
+		utils.Warn("初始化大模型客户端失败: %v", err)
+	} else if client != nil {
+		meter = client.Meter()
+		if config.ExportMD && config.MDStructure {
+			outliner = client
+		}
+		if config.ExportChapters {
+			chapterLLM = client
+		}
+		if config.ExportAnalysis {
+			analysisLLM = client
+		}
+		if config.ExportSummary {
+			summaryLLM = client
+		}
+	}
+	translator, err := translate.FromConfig(config, client)
+	if err != nil {
+		utils.Warn("初始化翻译服务失败: %v", err)
+	}
+	chaptersExporter := export.NewChaptersExporter(config.OutputFolder)
+	chaptersExporter.Namer = namer
+	analysisExporter := export.NewAnalysisExporter(config.OutputFolder)
+	analysisExporter.Namer = namer
+	summaryExporter := export.NewSummaryExporter(config.OutputFolder)
+	summaryExporter.Namer = namer
 	return &ASRProcessor{
 		Config:      config,
-		SRTExporter: export.NewSRTExporter(output),
-		JSONExporter: export.NewJSONExporter(config.OutputFolder),
+		Outliner:    outliner,
+		ChapterLLM:  chapterLLM,
+		ChaptersExporter: chaptersExporter,
+		AnalysisLLM: analysisLLM,
+		AnalysisExporter: analysisExporter,
+		SummaryLLM:  summaryLLM,
+		SummaryExporter: summaryExporter,
+		Translator:  translator,
+		LLMMeter:    meter,
+		SRTExporter: srtExporter,
+		JSONExporter: jsonExporter,
+		LRCExporter:  lrcExporter,
+		ASSExporter:  assExporter,
+		DOCXExporter: docxExporter,
+		PDFExporter:  pdfExporter,
+		VTTExporter:  vttExporter,
+		PlayerExporter: playerExporter,
+		JianyingExporter: jianyingExporter,
+		Diarizer:     diarizer,
+		TextPipeline: pipeline,
+		Namer:        namer,
 	}
 }
 
@@ -42,12 +164,39 @@ func NewASRProcessor(config *models.Config) *ASRProcessor {
 func (p *ASRProcessor) ProcessResults(ctx context.Context, segments []models.DataSegment, audioPath string, partNum *int) (map[string]string, error) {
 	outputFiles := make(map[string]string)
 	
+	// 记录音频语言：优先使用检测结果，其次使用配置中指定的语言
+	language := LanguageFromContext(ctx)
+	if language == "" && p.Config.Language != "auto" {
+		language = p.Config.Language
+	}
+	p.JSONExporter.Language = language
+	
+	// 命名模板中的 {{.Folder}} 取源媒体所在目录
+	p.Namer.Source = SourceMediaFromContext(ctx)
+	
+	// 大模型用量按源文件汇总
+	job := p.Namer.Source
+	if job == "" {
+		job = audioPath
+	}
+	p.LLMMeter.SetJob(job)
+	before := p.LLMMeter.Usage()
+	
+	// 如果启用，先标注每段的说话人
+	segments = diarize.Apply(ctx, p.Diarizer, segments, audioPath)
+	
+	// 导出前的文本后处理（标点、简繁转换、敏感词、自定义替换）
+	segments = p.TextPipeline.ApplySegments(segments)
+	
 	// 1. 处理文本输出
-	textPath, err := p.generateTextOutput(segments, audioPath, partNum)
+	textPath, mdPath, err := p.generateTextOutput(segments, audioPath, partNum)
 	if err != nil {
 		return nil, err
 	}
 	outputFiles["txt"] = textPath
+	if mdPath != "" {
+		outputFiles["md"] = mdPath
+	}
 	
 	// 2. 如果配置指定，生成SRT字幕文件
 	if p.Config.ExportSRT && len(segments) > 0 {
@@ -58,6 +207,73 @@ func (p *ASRProcessor) ProcessResults(ctx context.Context, segments []models.Dat
 			outputFiles["srt"] = srtPath
 		}
 	}
+	// 导入剪映时额外生成剪映草稿，与SRT放在一起
+	if p.Config.SubtitlePreset == export.PresetJianying && len(segments) > 0 {
+		draftPath, err := p.JianyingExporter.ExportJianying(segments, audioPath, partNum)
+		if err != nil {
+			utils.Warn("导出剪映草稿失败: %v", err)
+		} else {
+			outputFiles["jianying"] = draftPath
+		}
+	}
+	// 整个文件翻译为 translate_to 指定的语言，保留每段的时间，导出译文或双语字幕
+	if p.Translator != nil && partNum == nil && len(segments) > 0 {
+		translated, err := translate.Segments(ctx, p.Translator, segments, translate.OptionsFromConfig(p.Config))
+		if err != nil {
+			utils.Warn("翻译失败: %v", err)
+		} else if translatedPath, err := p.SRTExporter.ExportTranslatedSRT(segments, translated, audioPath, nil, p.Config.TranslateTo, p.Config.TranslateBilingual); err != nil {
+			utils.Warn("导出译文字幕失败: %v", err)
+		} else {
+			outputFiles["translation"] = translatedPath
+		}
+	}
+	// 整个文件划分章节，导出YouTube格式的章节并写入JSON导出
+	p.JSONExporter.Chapters = nil
+	if p.Config.ExportChapters && partNum == nil && len(segments) > 0 {
+		list, err := chapters.Detect(segments, p.ChapterLLM, chapters.OptionsFromConfig(p.Config))
+		if err != nil {
+			utils.Warn("%v，按停顿划分章节", err)
+		}
+		if len(list) > 0 {
+			p.JSONExporter.Chapters = list
+			chaptersPath, err := p.ChaptersExporter.ExportChapters(list, audioPath)
+			if err != nil {
+				utils.Warn("导出章节失败: %v", err)
+			} else {
+				outputFiles["chapters"] = chaptersPath
+			}
+		}
+	}
+	// 整个文件提取关键词、实体与主题，导出 *_analysis.json 并写入JSON导出
+	p.JSONExporter.Analysis = nil
+	if p.Config.ExportAnalysis && partNum == nil && len(segments) > 0 {
+		result, err := analysis.Analyze(segments, p.AnalysisLLM, analysis.OptionsFromConfig(p.Config))
+		if err != nil {
+			utils.Warn("%v，在本地提取关键词", err)
+		}
+		if result != nil {
+			p.JSONExporter.Analysis = result
+			analysisPath, err := p.AnalysisExporter.ExportAnalysis(result, audioPath)
+			if err != nil {
+				utils.Warn("导出关键词失败: %v", err)
+			} else {
+				outputFiles["analysis"] = analysisPath
+			}
+		}
+	}
+	// 整个文件生成摘要（summary_minutes 时为包含待办事项与问答的会议纪要），文稿超出分段长度时分段整理后合并
+	if p.Config.ExportSummary && p.SummaryLLM != nil && partNum == nil && len(segments) > 0 {
+		if text := summary.Text(segments); text != "" {
+			result, err := p.generateSummary(text)
+			if err != nil {
+				utils.Warn("生成摘要失败: %v", err)
+			} else if summaryPath, err := p.SummaryExporter.ExportSummary(result, audioPath); err != nil {
+				utils.Warn("导出摘要失败: %v", err)
+			} else {
+				outputFiles["summary"] = summaryPath
+			}
+		}
+	}
 	// 3、 如果配置指定，生成JSON格式的文本文件
 	if p.Config.ExportJSON && len(segments) > 0 {
 		jsonPath, err := p.JSONExporter.ExportJSON(segments, audioPath, partNum)
@@ -67,12 +283,98 @@ func (p *ASRProcessor) ProcessResults(ctx context.Context, segments []models.Dat
 			outputFiles["json"] = jsonPath
 		}
 	}
+	// 4、 如果配置指定，生成LRC歌词文件（有逐词时间戳时为卡拉OK格式）
+	if p.Config.ExportLRC && len(segments) > 0 {
+		lrcPath, err := p.LRCExporter.ExportLRC(segments, audioPath, partNum)
+		if err != nil {
+			utils.Warn("导出LRC文件失败: %v", err)
+		} else {
+			outputFiles["lrc"] = lrcPath
+		}
+	}
+	// 5、 如果配置指定，生成ASS字幕文件（有逐词时间戳时可逐词高亮）
+	if p.Config.ExportASS && len(segments) > 0 {
+		assPath, err := p.ASSExporter.ExportASS(segments, audioPath, partNum)
+		if err != nil {
+			utils.Warn("导出ASS字幕失败: %v", err)
+		} else {
+			outputFiles["ass"] = assPath
+		}
+	}
+	// 6、 如果配置指定，生成便于分享的Word与PDF文档
+	if p.Config.ExportDOCX && len(segments) > 0 {
+		docxPath, err := p.DOCXExporter.ExportDOCX(segments, audioPath, partNum)
+		if err != nil {
+			utils.Warn("导出DOCX文档失败: %v", err)
+		} else {
+			outputFiles["docx"] = docxPath
+		}
+	}
+	if p.Config.ExportPDF && len(segments) > 0 {
+		pdfPath, err := p.PDFExporter.ExportPDF(segments, audioPath, partNum)
+		if err != nil {
+			utils.Warn("导出PDF文档失败: %v", err)
+		} else {
+			outputFiles["pdf"] = pdfPath
+		}
+	}
+	// 7、 如果配置指定，生成浏览器字幕与可离线打开的播放页（播放页需要VTT字幕与源媒体）
+	if (p.Config.ExportVTT || p.Config.ExportPlayer) && len(segments) > 0 {
+		vttPath, err := p.VTTExporter.ExportVTT(segments, audioPath, partNum)
+		if err != nil {
+			utils.Warn("导出VTT字幕失败: %v", err)
+		} else {
+			outputFiles["vtt"] = vttPath
+		}
+	}
+	if source := SourceMediaFromContext(ctx); p.Config.ExportPlayer && partNum == nil && source != "" && len(segments) > 0 {
+		playerPath, err := p.PlayerExporter.ExportPlayer(segments, audioPath, source, outputFiles["vtt"])
+		if err != nil {
+			utils.Warn("生成播放页失败: %v", err)
+		} else {
+			outputFiles["player"] = playerPath
+			// 源媒体位于媒体目录下时写入清单，通过接口访问播放页时可直接播放
+			if p.PlayerExporter.ServesMedia(source) {
+				outputFiles["media"] = source
+			}
+		}
+	}
+	if used := p.LLMMeter.Usage(); used.Requests > before.Requests {
+		utils.Info("大模型用量: %d 次请求，输入 %d tokens，输出 %d tokens",
+			used.Requests-before.Requests, used.PromptTokens-before.PromptTokens, used.CompletionTokens-before.CompletionTokens)
+	}
+	// 8、 记录整个文件的全部输出，供Web界面、下载接口与 outputs 命令读取
+	if partNum == nil {
+		manifestPath, err := export.WriteManifest(p.Config, audioPath, outputFiles)
+		if err != nil {
+			utils.Warn("生成输出清单失败: %v", err)
+		} else {
+			outputFiles["manifest"] = manifestPath
+			// 重新识别时写入用户已添加的标签
+			if err := export.SyncFrontmatter(p.Config, audioPath); err != nil {
+				utils.Warn("写入Markdown标签失败: %v", err)
+			}
+		}
+	}
 	
 	return outputFiles, nil
 }
 
-// generateTextOutput 生成文本输出
-func (p *ASRProcessor) generateTextOutput(segments []models.DataSegment, audioPath string, partNum *int) (string, error) {
+// generateSummary 生成摘要，summary_minutes 时整理为包含待办事项与问答的会议纪要
+func (p *ASRProcessor) generateSummary(text string) (*summary.Minutes, error) {
+	opts := summary.OptionsFromConfig(p.Config)
+	if p.Config.SummaryMinutes {
+		return summary.GenerateMinutes(p.SummaryLLM, text, opts)
+	}
+	result, err := summary.Summarize(p.SummaryLLM, text, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &summary.Minutes{Summary: result}, nil
+}
+
+// generateTextOutput 生成文本输出，返回文本文件与Markdown文件（未生成时为空）的路径
+func (p *ASRProcessor) generateTextOutput(segments []models.DataSegment, audioPath string, partNum *int) (string, string, error) {
 	var outputText strings.Builder
 	
 	// 1. 准备文件头信息
@@ -94,40 +396,49 @@ func (p *ASRProcessor) generateTextOutput(segments []models.DataSegment, audioPa
 		// 简单合并所有文本段落
 		for _, segment := range segments {
 			if segment.Text != "" && segment.Text != "[无法识别的音频片段]" {
+				if segment.Speaker != "" {
+					outputText.WriteString(segment.Speaker + ": ")
+				}
 				outputText.WriteString(segment.Text)
 				outputText.WriteString("\n\n")
 			}
 		}
 	}
 	
-	// 3. 确定输出路径
-	var outputFile string
+	// 3. 按命名模板确定输出路径
+	outputFile, err := p.Namer.Path(p.Config.OutputFolder, audioPath, partNum, "txt", "")
+	if err != nil {
+		return "", "", err
+	}
 	var outputMdFile string
-	if partNum != nil {
-		outputSubfolder := filepath.Join(p.Config.OutputFolder, baseName)
-		if err := os.MkdirAll(outputSubfolder, 0755); err != nil {
-			return "", fmt.Errorf("创建子目录失败: %w", err)
-		}
-		outputFile = filepath.Join(outputSubfolder, fmt.Sprintf("%s_part%d.txt", baseName, *partNum))
-	} else {
-		outputFile = filepath.Join(p.Config.OutputFolder, fmt.Sprintf("%s.txt", baseName))
-		if(p.Config.ExportMD){
-		  outputMdFile = filepath.Join(p.Config.OutputFolder, fmt.Sprintf("%s.md", baseName))
+	if partNum == nil && p.Config.ExportMD {
+		if outputMdFile, err = p.Namer.Path(p.Config.OutputFolder, audioPath, nil, "md", ""); err != nil {
+			return "", "", err
 		}
 	}
 
 	if outputMdFile != "" {
-		// 4. 写入Markdown文件
-		if err := os.WriteFile(outputMdFile, []byte(outputText.String()), 0644); err != nil {
-			return "", fmt.Errorf("写入Markdown文件失败: %w", err)
+		// 4. 写入Markdown文件，能生成结构时按章节排版，否则与文本文件相同
+		mdContent := outputText.String()
+		if p.Outliner != nil {
+			if outline, err := export.RequestOutline(p.Outliner, segments); err != nil {
+				utils.Warn("%v，Markdown使用原始文稿", err)
+			} else {
+				mdContent = export.RenderOutlineMarkdown(outline, segments, baseName)
+			}
+		}
+		audit.RecordOverwrite(outputMdFile, "export", "重新生成Markdown文件")
+		if err := os.WriteFile(outputMdFile, []byte(mdContent), 0644); err != nil {
+			return "", "", fmt.Errorf("写入Markdown文件失败: %w", err)
 		}
 	}
 	// 4. 写入文件
+	audit.RecordOverwrite(outputFile, "export", "重新生成文本文件")
 	if err := os.WriteFile(outputFile, []byte(outputText.String()), 0644); err != nil {
-		return "", fmt.Errorf("写入文本文件失败: %w", err)
+		return "", "", fmt.Errorf("写入文本文件失败: %w", err)
 	}
 	
-	return outputFile, nil
+	return outputFile, outputMdFile, nil
 }
 
 // formatSegmentText 格式化文本段落
@@ -141,6 +452,9 @@ func (p *ASRProcessor) formatSegmentText(segments []models.DataSegment, includeT
 		
 		// 处理文本
 		processedText := p.processSegmentText(segment.Text)
+		if segment.Speaker != "" {
+			processedText = segment.Speaker + ": " + processedText
+		}
 		
 		// 添加时间戳（如果需要）
 		if includeTimestamps {
@@ -157,24 +471,7 @@ func (p *ASRProcessor) formatSegmentText(segments []models.DataSegment, includeT
 	return strings.Join(formattedSegments, "\n\n")
 }
 
-// processSegmentText 处理文本片段
+// processSegmentText 处理文本片段：合并多余空白，汉字间的停顿空格改为逗号，并补充句末标点
 func (p *ASRProcessor) processSegmentText(text string) string {
-	// 替换多个空格为一个
-	text = strings.Join(strings.Fields(text), " ")
-	
-	// 中文内容中替换空格为逗号
-	// 这需要正则表达式来实现，但简化起见，这里只是示例
-	// 实际实现应该使用正则表达式匹配汉字之间的空格
-	
-	// 确保句尾有标点
-	lastChar := ""
-	if len(text) > 0 {
-		lastChar = text[len(text)-1:]
-	}
-	if lastChar != "。" && lastChar != "！" && lastChar != "？" && 
-	   lastChar != "." && lastChar != "!" && lastChar != "?" {
-		text += "。" // 添加句号
-	}
-	
-	return text
+	return textproc.Punctuation{}.Process(text)
 }
@@ -0,0 +1,160 @@
+package asr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/utils"
+)
+
+// ErrQuotaExhausted 服务的每日配额已用完
+var ErrQuotaExhausted = errors.New("ASR服务每日配额已用完")
+
+// 每日配额按本地日期重置
+const quotaDateLayout = "2006-01-02"
+
+// resetQuota 跨天时清零当日用量
+func (stat *ServiceStats) resetQuota(now time.Time) {
+	if today := now.Format(quotaDateLayout); stat.QuotaDate != today {
+		stat.QuotaDate = today
+		stat.QuotaUsed = 0
+	}
+}
+
+// quotaExhaustedLocked 判断服务当日配额是否已用完，调用方需持有锁
+func (s *ASRSelector) quotaExhaustedLocked(name string, now time.Time) bool {
+	options, ok := s.options[name]
+	stat, exists := s.stats[name]
+	if !ok || !exists || options.DailyQuota <= 0 {
+		return false
+	}
+	stat.resetQuota(now)
+	return stat.QuotaUsed >= options.DailyQuota
+}
+
+// quotaWaitLocked 返回服务还需等待多久才能发起请求，调用方需持有锁。
+// 每日配额用完时返回 false。
+func (s *ASRSelector) quotaWaitLocked(name string, now time.Time) (time.Duration, bool) {
+	if s.quotaExhaustedLocked(name, now) {
+		return 0, false
+	}
+	if limiter, ok := s.limiters[name]; ok {
+		return limiter.Wait(now), true
+	}
+	return 0, true
+}
+
+// hasQuotaLocked 判断服务当前是否可以立即发起请求，调用方需持有锁
+func (s *ASRSelector) hasQuotaLocked(name string, now time.Time) bool {
+	wait, ok := s.quotaWaitLocked(name, now)
+	return ok && wait == 0
+}
+
+// consumeQuotaLocked 扣除一个令牌并累计当日用量，调用方需持有锁
+func (s *ASRSelector) consumeQuotaLocked(name string, now time.Time) {
+	if limiter, ok := s.limiters[name]; ok {
+		limiter.Take(now)
+	}
+	if stat, exists := s.stats[name]; exists {
+		stat.resetQuota(now)
+		stat.QuotaUsed++
+		if s.options[name].DailyQuota > 0 {
+			s.saveStatsLocked()
+		}
+	}
+}
+
+// acquireQuota 为指定服务获取一次请求额度：超过每分钟请求数时等待令牌，
+// 每日配额用完时返回 ErrQuotaExhausted
+func (s *ASRSelector) acquireQuota(ctx context.Context, name string) error {
+	for {
+		s.mu.Lock()
+		now := time.Now()
+		wait, ok := s.quotaWaitLocked(name, now)
+		if !ok {
+			s.mu.Unlock()
+			return fmt.Errorf("%s: %w", name, ErrQuotaExhausted)
+		}
+		if wait == 0 {
+			s.consumeQuotaLocked(name, now)
+			s.mu.Unlock()
+			return nil
+		}
+		s.mu.Unlock()
+
+		utils.Debug("ASR服务 %s 达到每分钟请求上限，等待 %s", name, wait.Round(time.Millisecond))
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+// selectWithQuota 按策略选择一个有剩余额度的服务并扣除额度。
+// 所有可用服务都只是暂时限流时等待最早可用的令牌，而不是直接失败。
+func (s *ASRSelector) selectWithQuota(ctx context.Context, strategy string) (string, ServiceCreator, error) {
+	language := LanguageFromContext(ctx)
+	for {
+		name, creator, ok := s.selectService(strategy, language)
+		if ok {
+			s.mu.Lock()
+			s.consumeQuotaLocked(name, time.Now())
+			s.mu.Unlock()
+			return name, creator, nil
+		}
+
+		wait, ok := s.nextQuotaWait(language)
+		if !ok {
+			if language != "" {
+				return "", nil, fmt.Errorf("没有支持语言 %s 的可用ASR服务", language)
+			}
+			return "", nil, fmt.Errorf("没有可用的ASR服务")
+		}
+
+		utils.Info("所有ASR服务均达到每分钟请求上限，等待 %s", wait.Round(time.Millisecond))
+		select {
+		case <-ctx.Done():
+			return "", nil, ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+// nextQuotaWait 返回支持指定语言、健康且当日配额未用完的服务中最早获得令牌的等待时间，没有这样的服务时返回 false
+func (s *ASRSelector) nextQuotaWait(language string) (time.Duration, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	var shortest time.Duration
+	found := false
+	for _, name := range s.serviceList {
+		if !s.isHealthyLocked(name, now) || !s.supportsLanguageLocked(name, language) {
+			continue
+		}
+		wait, ok := s.quotaWaitLocked(name, now)
+		if !ok || wait == 0 {
+			continue
+		}
+		if !found || wait < shortest {
+			shortest = wait
+			found = true
+		}
+	}
+	return shortest, found
+}
+
+// QuotaStatus 返回服务当日已用请求数与每日配额（0 表示不限制）
+func (s *ASRSelector) QuotaStatus(name string) (used int, quota int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if stat, exists := s.stats[name]; exists {
+		stat.resetQuota(time.Now())
+		used = stat.QuotaUsed
+	}
+	return used, s.options[name].DailyQuota
+}
@@ -0,0 +1,65 @@
+package asr
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelectorSkipsExhaustedQuota(t *testing.T) {
+	creator := func(audioPath string, useCache bool) (ASRService, error) { return nil, nil }
+	selector := NewASRSelector()
+	selector.RegisterService("bcut", creator, 10)
+	selector.RegisterService("kuaishou", creator, 10)
+	selector.SetServiceOptions("bcut", ServiceOptions{Timeout: time.Minute, DailyQuota: 2})
+
+	ctx := context.Background()
+	require.NoError(t, selector.acquireQuota(ctx, "bcut"))
+	require.NoError(t, selector.acquireQuota(ctx, "bcut"))
+
+	err := selector.acquireQuota(ctx, "bcut")
+	assert.True(t, errors.Is(err, ErrQuotaExhausted))
+
+	// 配额用完的服务不再被自动选择
+	for i := 0; i < 10; i++ {
+		name, _, err := selector.selectWithQuota(ctx, "round_robin")
+		require.NoError(t, err)
+		assert.Equal(t, "kuaishou", name)
+	}
+
+	used, quota := selector.QuotaStatus("bcut")
+	assert.Equal(t, 2, used)
+	assert.Equal(t, 2, quota)
+
+	// 跨天后配额重置
+	selector.stats["bcut"].QuotaDate = time.Now().AddDate(0, 0, -1).Format(quotaDateLayout)
+	used, _ = selector.QuotaStatus("bcut")
+	assert.Equal(t, 0, used)
+}
+
+func TestSelectWithQuotaWaitsForRateLimit(t *testing.T) {
+	creator := func(audioPath string, useCache bool) (ASRService, error) { return nil, nil }
+	selector := NewASRSelector()
+	selector.RegisterService("bcut", creator, 10)
+	selector.SetServiceOptions("bcut", ServiceOptions{Timeout: time.Minute, RequestsPerMinute: 600})
+	// 用完当前的令牌
+	for i := 0; i < 600; i++ {
+		selector.limiters["bcut"].Take(time.Now())
+	}
+
+	start := time.Now()
+	name, _, err := selector.selectWithQuota(context.Background(), "weighted_random")
+	require.NoError(t, err)
+	assert.Equal(t, "bcut", name)
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+
+	// 限流等待可被取消
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, _, err = selector.selectWithQuota(ctx, "weighted_random")
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,143 @@
+package asr
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/utils"
+)
+
+// ErrSchemaMismatch 服务响应的格式与预期不符，通常说明上游接口发生了变化，重试无法解决
+var ErrSchemaMismatch = errors.New("ASR服务响应格式不符合预期")
+
+// 保存的原始响应上限，避免异常的大响应占满磁盘
+const maxDiagnosticBytes = 64 * 1024
+
+var (
+	diagnosticsMu  sync.RWMutex
+	diagnosticsDir = filepath.Join(os.TempDir(), "asr-media-cli", "diagnostics")
+)
+
+// SetDiagnosticsDir 设置保存异常响应的目录，为空时不保存
+func SetDiagnosticsDir(dir string) {
+	diagnosticsMu.Lock()
+	defer diagnosticsMu.Unlock()
+	diagnosticsDir = dir
+}
+
+// SchemaError 服务响应无法按预期结构解析或校验失败
+type SchemaError struct {
+	Service  string // 服务名称
+	Endpoint string // 接口名称
+	Problem  string // 不符合预期之处
+	DumpPath string // 原始响应的保存路径，未保存时为空
+}
+
+// Error 返回包含接口、问题与原始响应位置的信息
+func (e *SchemaError) Error() string {
+	msg := fmt.Sprintf("%s 接口 %s 响应格式不符合预期: %s", e.Service, e.Endpoint, e.Problem)
+	if e.DumpPath != "" {
+		msg += fmt.Sprintf("（原始响应已保存到 %s，上游接口可能已变化）", e.DumpPath)
+	}
+	return msg
+}
+
+// Unwrap 使 errors.Is(err, ErrSchemaMismatch) 成立
+func (e *SchemaError) Unwrap() error {
+	return ErrSchemaMismatch
+}
+
+// validator 解析后需要校验必填字段的响应结构
+type validator interface {
+	validate() error
+}
+
+// decodeResponse 将响应解析到 v 并校验，失败时保存原始响应并返回 *SchemaError
+func decodeResponse(service, endpoint string, body []byte, v validator) error {
+	var problem string
+	if err := json.Unmarshal(body, v); err != nil {
+		problem = describeDecodeError(err)
+	} else if err := v.validate(); err != nil {
+		problem = err.Error()
+	} else {
+		return nil
+	}
+
+	schemaErr := &SchemaError{
+		Service:  service,
+		Endpoint: endpoint,
+		Problem:  problem,
+		DumpPath: dumpPayload(service, endpoint, body),
+	}
+	utils.Error("%v", schemaErr)
+	return schemaErr
+}
+
+// describeDecodeError 将 JSON 解析错误转换为指出具体字段的描述
+func describeDecodeError(err error) string {
+	var typeErr *json.UnmarshalTypeError
+	var syntaxErr *json.SyntaxError
+	switch {
+	case errors.As(err, &typeErr):
+		field := typeErr.Field
+		if field == "" {
+			field = "(根)"
+		}
+		return fmt.Sprintf("字段 %s 应为 %s，实际为 %s", field, typeErr.Type, typeErr.Value)
+	case errors.As(err, &syntaxErr):
+		return fmt.Sprintf("不是有效的JSON（位置 %d）: %v", syntaxErr.Offset, err)
+	}
+	return err.Error()
+}
+
+// missingFields 返回值为空的必填字段组成的错误，全部非空时返回 nil
+func missingFields(fields map[string]bool) error {
+	var missing []string
+	for name, present := range fields {
+		if !present {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	sort.Strings(missing)
+	return fmt.Errorf("缺少字段 %s", strings.Join(missing, ", "))
+}
+
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9_-]+`)
+
+// dumpPayload 保存异常的原始响应，便于对照上游接口的变化，返回保存路径，失败时返回空
+func dumpPayload(service, endpoint string, body []byte) string {
+	diagnosticsMu.RLock()
+	dir := diagnosticsDir
+	diagnosticsMu.RUnlock()
+	if dir == "" {
+		return ""
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		utils.Warn("创建诊断目录失败: %v", err)
+		return ""
+	}
+	if len(body) > maxDiagnosticBytes {
+		body = body[:maxDiagnosticBytes]
+	}
+	name := fmt.Sprintf("%s-%s-%s.json", service,
+		strings.Trim(unsafeFileChars.ReplaceAllString(endpoint, "_"), "_"),
+		time.Now().Format("20060102-150405.000"))
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, body, 0644); err != nil {
+		utils.Warn("保存异常响应失败: %v", err)
+		return ""
+	}
+	return path
+}
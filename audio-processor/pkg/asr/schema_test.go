@@ -0,0 +1,76 @@
+package asr
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeBcutData(t *testing.T) {
+	dir := t.TempDir()
+	SetDiagnosticsDir(dir)
+	defer SetDiagnosticsDir("")
+
+	var task bcutTaskData
+	require.NoError(t, decodeBcutData("task", []byte(`{"code":0,"data":{"task_id":"abc"}}`), &task))
+	assert.Equal(t, "abc", task.TaskID)
+
+	// 接口返回的错误不是格式问题
+	err := decodeBcutData("task", []byte(`{"code":-400,"message":"请求错误"}`), &task)
+	require.Error(t, err)
+	assert.False(t, errors.Is(err, ErrSchemaMismatch))
+	assert.Contains(t, err.Error(), "请求错误")
+
+	// 字段类型变化
+	body := []byte(`{"code":0,"data":{"task_id":123}}`)
+	err = decodeBcutData("task", body, &bcutTaskData{})
+	var schemaErr *SchemaError
+	require.True(t, errors.As(err, &schemaErr))
+	assert.True(t, errors.Is(err, ErrSchemaMismatch))
+	assert.Contains(t, schemaErr.Problem, "task_id")
+	assert.True(t, strings.HasPrefix(schemaErr.DumpPath, dir))
+	dumped, readErr := os.ReadFile(schemaErr.DumpPath)
+	require.NoError(t, readErr)
+	assert.Equal(t, `{"task_id":123}`, string(dumped))
+
+	// 缺少必填字段
+	err = decodeBcutData("resource/create", []byte(`{"code":0,"data":{"per_size":1024}}`), &bcutUploadData{})
+	require.True(t, errors.As(err, &schemaErr))
+	assert.Equal(t, "缺少字段 data.in_boss_key, data.resource_id, data.upload_id, data.upload_urls", schemaErr.Problem)
+}
+
+func TestBcutMakeSegments(t *testing.T) {
+	var result bcutResult
+	require.NoError(t, decodeResponse("bcut", "task/result.result", []byte(`{"utterances":[
+		{"transcript":"你好","start_time":1000,"end_time":2000,"confidence":95,
+		 "words":[{"label":"你","start_time":1000,"end_time":1500},{"label":"","start_time":1500,"end_time":1600}]}
+	]}`), &result))
+
+	segments := (&BcutASR{}).makeSegments(&result)
+	require.Len(t, segments, 1)
+	assert.Equal(t, "你好", segments[0].Text)
+	assert.InDelta(t, 1.105, segments[0].StartTime, 1e-9)
+	assert.InDelta(t, 0.95, segments[0].Confidence, 1e-9)
+	assert.Len(t, segments[0].Words, 1)
+
+	SetDiagnosticsDir("")
+	err := decodeResponse("bcut", "task/result.result", []byte(`{"segments":[]}`), &bcutResult{})
+	assert.True(t, errors.Is(err, ErrSchemaMismatch))
+}
+
+func TestKuaiShouResponseValidate(t *testing.T) {
+	SetDiagnosticsDir("")
+	var resp KuaiShouResponse
+	require.NoError(t, decodeResponse("kuaishou", "subtitle_generate",
+		[]byte(`{"data":{"text":[{"text":"hi","start_time":0.5,"end_time":1.2}]}}`), &resp))
+	assert.Len(t, resp.Data.Text, 1)
+
+	err := decodeResponse("kuaishou", "subtitle_generate", []byte(`{"data":{"result":[]}}`), &KuaiShouResponse{})
+	var schemaErr *SchemaError
+	require.True(t, errors.As(err, &schemaErr))
+	assert.Equal(t, "缺少字段 data.text", schemaErr.Problem)
+}
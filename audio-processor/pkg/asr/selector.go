@@ -2,6 +2,7 @@ package asr
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math/rand"
 	"os"
@@ -15,11 +16,18 @@ import (
 // ServiceCreator 是创建ASR服务实例的函数类型
 type ServiceCreator func(audioPath string, useCache bool) (ASRService, error)
 
-// ServiceStats 服务统计数据
+// ServiceStats 服务统计数据，会持久化到统计文件中
 type ServiceStats struct {
-	SuccessCount int
-	TotalCount   int
-	Available    bool
+	SuccessCount        int       `json:"success_count"`        // 累计成功次数
+	TotalCount          int       `json:"total_count"`          // 累计调用次数
+	Available           bool      `json:"available"`            // 是否可用（false 表示已熔断）
+	SuccessScore        float64   `json:"success_score"`        // 按时间衰减后的成功分数
+	TotalScore          float64   `json:"total_score"`          // 按时间衰减后的调用分数
+	ConsecutiveFailures int       `json:"consecutive_failures"` // 连续失败次数
+	OpenUntil           time.Time `json:"open_until"`           // 熔断截止时间，之后允许试探调用
+	LastUpdated         time.Time `json:"last_updated"`         // 最近一次更新时间
+	QuotaDate           string    `json:"quota_date,omitempty"` // 当日用量对应的日期
+	QuotaUsed           int       `json:"quota_used"`           // 当日已发起的请求数
 }
 
 // ASRSelector 语音服务选择器，负责在多个ASR服务之间进行负载均衡
@@ -31,6 +39,12 @@ type ASRSelector struct {
 	stats           map[string]*ServiceStats    // 统计信息
 	roundRobinIndex int                         // 轮询索引
 	serviceList     []string                    // 服务名称列表，用于轮询
+	strategy        string                      // 自动选择策略
+	options         map[string]ServiceOptions   // 各服务的调用参数
+	limiters        map[string]*utils.RateLimiter     // 各服务的每分钟请求限流器
+	policy          HealthPolicy                // 健康度与熔断策略
+	statsFile       string                      // 统计持久化文件，为空时不持久化
+	cache           *Cache                      // 识别结果缓存，注入到支持缓存的服务中
 }
 
 // NewASRSelector 创建新的ASR服务选择器
@@ -43,6 +57,10 @@ func NewASRSelector() *ASRSelector {
 		stats:           make(map[string]*ServiceStats),
 		roundRobinIndex: 0,
 		serviceList:     make([]string, 0),
+		strategy:        "weighted_random",
+		options:         make(map[string]ServiceOptions),
+		limiters:        make(map[string]*utils.RateLimiter),
+		policy:          DefaultHealthPolicy(),
 	}
 }
 
@@ -70,24 +88,25 @@ func (s *ASRSelector) ReportResult(serviceName string, success bool) {
 	defer s.mu.Unlock()
 
 	if stat, exists := s.stats[serviceName]; exists {
-		if success {
-			stat.SuccessCount++
-		}
-		stat.TotalCount++
-
-		// 更新服务可用性
-		if !success && stat.TotalCount > 5 && float64(stat.SuccessCount)/float64(stat.TotalCount) < 0.2 {
-			stat.Available = false
-			utils.Warn("ASR服务 %s 成功率过低，临时禁用", serviceName)
-		} else if success && !stat.Available {
-			stat.Available = true
-			utils.Info("ASR服务 %s 恢复可用", serviceName)
+		// 更新统计与熔断状态
+		if change := stat.record(success, time.Now(), s.policy); change != "" {
+			if stat.Available {
+				utils.Info("ASR服务 %s %s", serviceName, change)
+			} else {
+				utils.Warn("ASR服务 %s %s", serviceName, change)
+			}
 		}
+		s.saveStatsLocked()
 	}
 }
 
 // SelectService 根据策略选择一个ASR服务
 func (s *ASRSelector) SelectService(strategy string) (string, ServiceCreator, bool) {
+	return s.selectService(strategy, "")
+}
+
+// selectService 根据策略在支持指定语言的服务中选择一个，语言为空时不限制
+func (s *ASRSelector) selectService(strategy, language string) (string, ServiceCreator, bool) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -98,18 +117,19 @@ func (s *ASRSelector) SelectService(strategy string) (string, ServiceCreator, bo
 	// 根据策略选择服务
 	switch strategy {
 	case "round_robin":
-		return s.selectByRoundRobin()
+		return s.selectByRoundRobin(language)
 	default: // weighted_random
-		return s.selectByWeightedRandom()
+		return s.selectByWeightedRandom(language)
 	}
 }
 
 // selectByRoundRobin 使用轮询策略选择服务
-func (s *ASRSelector) selectByRoundRobin() (string, ServiceCreator, bool) {
+func (s *ASRSelector) selectByRoundRobin(language string) (string, ServiceCreator, bool) {
 	// 过滤出可用的服务
+	now := time.Now()
 	availableServices := make([]string, 0)
 	for _, name := range s.serviceList {
-		if s.stats[name].Available {
+		if s.isSelectableLocked(name, now) && s.supportsLanguageLocked(name, language) {
 			availableServices = append(availableServices, name)
 		}
 	}
@@ -126,11 +146,12 @@ func (s *ASRSelector) selectByRoundRobin() (string, ServiceCreator, bool) {
 }
 
 // selectByWeightedRandom 使用加权随机策略选择服务
-func (s *ASRSelector) selectByWeightedRandom() (string, ServiceCreator, bool) {
+func (s *ASRSelector) selectByWeightedRandom(language string) (string, ServiceCreator, bool) {
 	// 计算可用服务的总权重
+	now := time.Now()
 	totalWeight := 0
 	for name, weight := range s.weights {
-		if s.stats[name].Available {
+		if s.isSelectableLocked(name, now) && s.supportsLanguageLocked(name, language) {
 			totalWeight += weight
 		}
 	}
@@ -144,7 +165,7 @@ func (s *ASRSelector) selectByWeightedRandom() (string, ServiceCreator, bool) {
 	r := rand.Intn(totalWeight)
 	cumWeight := 0
 	for name, weight := range s.weights {
-		if s.stats[name].Available {
+		if s.isSelectableLocked(name, now) && s.supportsLanguageLocked(name, language) {
 			cumWeight += weight
 			if r < cumWeight {
 				s.counters[name]++
@@ -155,7 +176,7 @@ func (s *ASRSelector) selectByWeightedRandom() (string, ServiceCreator, bool) {
 
 	// 默认情况，返回第一个可用服务
 	for name := range s.weights {
-		if s.stats[name].Available {
+		if s.isSelectableLocked(name, now) && s.supportsLanguageLocked(name, language) {
 			s.counters[name]++
 			return name, s.services[name], true
 		}
@@ -173,7 +194,7 @@ func (s *ASRSelector) GetStats() map[string]map[string]interface{} {
 	for name, stat := range s.stats {
 		successRate := 0.0
 		if stat.TotalCount > 0 {
-			successRate = float64(stat.SuccessCount) / float64(stat.TotalCount) * 100
+			successRate = stat.successRate() * 100
 		}
 
 		result[name] = map[string]interface{}{
@@ -182,6 +203,16 @@ func (s *ASRSelector) GetStats() map[string]map[string]interface{} {
 			"available":    stat.Available,
 			"weight":       s.weights[name],
 		}
+		if !stat.Available {
+			result[name]["open_until"] = stat.OpenUntil.Format("2006-01-02 15:04:05")
+		}
+		if quota := s.options[name].DailyQuota; quota > 0 {
+			used := 0
+			if stat.QuotaDate == time.Now().Format(quotaDateLayout) {
+				used = stat.QuotaUsed
+			}
+			result[name]["quota"] = fmt.Sprintf("%d/%d", used, quota)
+		}
 	}
 
 	return result
@@ -189,22 +220,68 @@ func (s *ASRSelector) GetStats() map[string]map[string]interface{} {
 
 // RunWithService 使用指定服务或自动选择服务来执行ASR任务，并处理结果
 func (s *ASRSelector) RunWithService(ctx context.Context, audioPath string, serviceName string, useCache bool, config *models.Config, callback ProgressCallback) ([]models.DataSegment, string, map[string]string, error) {
+	segments, selectedName, err := s.Recognize(ctx, audioPath, serviceName, useCache, callback)
+	if err != nil {
+		return nil, selectedName, nil, err
+	}
+	
+	// 处理识别结果
+	var outputFiles map[string]string
+	if len(segments) > 0 && config != nil {
+		// 初始化ASR处理器
+		processor := NewASRProcessor(config)
+		outputFiles, err = processor.ProcessResults(ctx, segments, audioPath, nil)
+		if err != nil {
+			utils.Warn("处理ASR结果失败: %v", err)
+		} else {
+			utils.Info("ASR结果处理完成，生成文件: %v", outputFiles)
+		}
+	} else if len(segments) == 0 {
+		utils.Warn("ASR识别结果为空: %s", audioPath)
+	}
+	
+	return segments, selectedName, outputFiles, err
+}
+
+// Recognize 使用指定服务或自动选择服务执行识别，仅返回识别结果，不生成输出文件
+func (s *ASRSelector) Recognize(ctx context.Context, audioPath string, serviceName string, useCache bool, callback ProgressCallback) ([]models.DataSegment, string, error) {
 	var service ASRService
 	var err error
 	var selectedName string
 	var creator ServiceCreator
 	var ok bool
 	
+	if serviceName == ConsensusServiceName {
+		return s.recognizeConsensus(ctx, audioPath, useCache, callback)
+	}
+
 	// 创建一个带有唯一ID的日志前缀
 	requestID := fmt.Sprintf("ASRREQ-%s", utils.GenerateRandomString(6))
 	utils.Info("[%s] 开始处理ASR请求: %s, 服务: %s", requestID, audioPath, serviceName)
 	
+	// 自动选择时已在选择服务的同时扣除了首次请求的额度
+	reserved := false
 	if serviceName == "auto" {
-		// 自动选择服务
-		selectedName, creator, ok = s.SelectService("weighted_random")
-		if !ok {
-			return nil, "", nil, fmt.Errorf("没有可用的ASR服务")
+		// 自动选择服务，跳过限流中或当日配额已用完的服务
+		s.mu.RLock()
+		strategy := s.strategy
+		s.mu.RUnlock()
+		selectedName, creator, err = s.selectWithQuota(ctx, strategy)
+		if err != nil {
+			return nil, "", err
+		}
+		reserved = true
+	} else if language := LanguageFromContext(ctx); !s.SupportsLanguage(serviceName, language) && s.hasServiceForLanguage(language) {
+		// 指定的服务不支持音频语言，改为在支持该语言的服务中自动选择
+		utils.Warn("[%s] ASR服务 %s 不支持语言 %s，改为自动选择", requestID, serviceName, language)
+		s.mu.RLock()
+		strategy := s.strategy
+		s.mu.RUnlock()
+		selectedName, creator, err = s.selectWithQuota(ctx, strategy)
+		if err != nil {
+			return nil, "", err
 		}
+		reserved = true
 	} else {
 		// 使用指定的服务
 		s.mu.RLock()
@@ -212,7 +289,7 @@ func (s *ASRSelector) RunWithService(ctx context.Context, audioPath string, serv
 		s.mu.RUnlock()
 		
 		if !ok {
-			return nil, "", nil, fmt.Errorf("未知的ASR服务: %s", serviceName)
+			return nil, "", fmt.Errorf("未知的ASR服务: %s", serviceName)
 		}
 		selectedName = serviceName
 	}
@@ -222,19 +299,19 @@ func (s *ASRSelector) RunWithService(ctx context.Context, audioPath string, serv
 	// 添加文件验证
 	if _, err := os.Stat(audioPath); os.IsNotExist(err) {
 		utils.Error("[%s] 音频文件不存在: %s", requestID, audioPath)
-		return nil, selectedName, nil, fmt.Errorf("音频文件不存在: %s", audioPath)
+		return nil, selectedName, fmt.Errorf("音频文件不存在: %s", audioPath)
 	}
 	
 	// 确保文件大小不为零
 	fileInfo, err := os.Stat(audioPath)
 	if err != nil {
 		utils.Error("[%s] 无法获取文件信息: %v", requestID, err)
-		return nil, selectedName, nil, fmt.Errorf("无法获取文件信息: %w", err)
+		return nil, selectedName, fmt.Errorf("无法获取文件信息: %w", err)
 	}
 	
 	if fileInfo.Size() == 0 {
 		utils.Error("[%s] 音频文件大小为零: %s", requestID, audioPath)
-		return nil, selectedName, nil, fmt.Errorf("音频文件大小为零: %s", audioPath)
+		return nil, selectedName, fmt.Errorf("音频文件大小为零: %s", audioPath)
 	}
 	
 	utils.Info("[%s] 文件验证通过: %s (大小: %.2f MB)", requestID, audioPath, float64(fileInfo.Size())/(1024*1024))
@@ -243,7 +320,10 @@ func (s *ASRSelector) RunWithService(ctx context.Context, audioPath string, serv
 	service, err = creator(audioPath, useCache)
 	if err != nil {
 		utils.Error("[%s] 创建ASR服务失败: %v", requestID, err)
-		return nil, selectedName, nil, fmt.Errorf("创建ASR服务失败: %w", err)
+		return nil, selectedName, fmt.Errorf("创建ASR服务失败: %w", err)
+	}
+	if cacheable, ok := service.(cacheableService); ok && useCache {
+		cacheable.SetCache(s.Cache())
 	}
 
 	// 包装进度回调以添加请求ID
@@ -258,18 +338,27 @@ func (s *ASRSelector) RunWithService(ctx context.Context, audioPath string, serv
 	utils.Info("[%s] 开始执行ASR识别...", requestID)
 	var segments []models.DataSegment
 	var retryCount int = 0
-	const maxRetries = 2
+	options := s.serviceOptions(selectedName)
+	maxRetries := options.MaxRetries
 	
 	for retryCount <= maxRetries {
+		// 每次请求都需要额度，限流时等待令牌，当日配额用完则不再请求
+		if !reserved {
+			if err = s.acquireQuota(ctx, selectedName); err != nil {
+				break
+			}
+		}
+		reserved = false
+
 		// 创建一个子上下文，确保每次重试都有新的超时
-		taskCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+		taskCtx, cancel := context.WithTimeout(ctx, options.Timeout)
 		
 		// 执行任务
 		segments, err = service.GetResult(taskCtx, wrappedCallback)
 		cancel() // 不论是否成功，都释放上下文
 		
-		// 如果成功或达到最大重试次数，退出循环
-		if err == nil || retryCount >= maxRetries {
+		// 如果成功、达到最大重试次数、响应格式不符合预期（重试无法解决）或已取消，退出循环
+		if err == nil || retryCount >= maxRetries || errors.Is(err, ErrSchemaMismatch) || ctx.Err() != nil {
 			break
 		}
 		
@@ -282,34 +371,31 @@ func (s *ASRSelector) RunWithService(ctx context.Context, audioPath string, serv
 		}
 		
 		// 等待一段时间后重试
-		time.Sleep(time.Second * 2)
+		if sleepErr := sleepContext(ctx, time.Second*2); sleepErr != nil {
+			err = sleepErr
+			break
+		}
 	}
 	
-	// 报告结果
+	// 报告结果，请求前配额就已用完的不计入服务健康度
+	if errors.Is(err, ErrQuotaExhausted) && retryCount == 0 {
+		utils.Warn("[%s] %v", requestID, err)
+		return nil, selectedName, err
+	}
+	// 取消的请求同样不计入服务健康度
+	if err != nil && ctx.Err() != nil {
+		utils.Warn("[%s] ASR识别已取消: %v", requestID, err)
+		return nil, selectedName, err
+	}
 	success := err == nil && len(segments) > 0
 	s.ReportResult(selectedName, success)
 	
 	if err != nil {
 		utils.Error("[%s] ASR识别最终失败: %v", requestID, err)
-		return nil, selectedName, nil, err
+		return nil, selectedName, err
 	}
 	
 	utils.Info("[%s] ASR识别完成，获取 %d 段文本", requestID, len(segments))
 	
-	// 处理识别结果
-	var outputFiles map[string]string
-	if len(segments) > 0 && config != nil {
-		// 初始化ASR处理器
-		processor := NewASRProcessor(config)
-		outputFiles, err = processor.ProcessResults(ctx, segments, audioPath, nil)
-		if err != nil {
-			utils.Warn("[%s] 处理ASR结果失败: %v", requestID, err)
-		} else {
-			utils.Info("[%s] ASR结果处理完成，生成文件: %v", requestID, outputFiles)
-		}
-	} else if len(segments) == 0 {
-		utils.Warn("[%s] ASR识别结果为空", requestID)
-	}
-	
-	return segments, selectedName, outputFiles, err
-}
+	return segments, selectedName, nil
+}
\ No newline at end of file
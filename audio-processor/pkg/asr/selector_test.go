@@ -0,0 +1,58 @@
+package asr
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/models"
+)
+
+// serviceFunc 以函数实现的测试用ASR服务
+type serviceFunc func(ctx context.Context) ([]models.DataSegment, error)
+
+func (f serviceFunc) GetResult(ctx context.Context, callback ProgressCallback) ([]models.DataSegment, error) {
+	return f(ctx)
+}
+
+// TestRecognizeStopsOnCancel 测试识别被取消后不再重试，也不计入服务健康度
+func TestRecognizeStopsOnCancel(t *testing.T) {
+	audioPath := filepath.Join(t.TempDir(), "a.mp3")
+	require.NoError(t, os.WriteFile(audioPath, []byte("audio"), 0644))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	creator := func(audioPath string, useCache bool) (ASRService, error) {
+		return serviceFunc(func(ctx context.Context) ([]models.DataSegment, error) {
+			calls++
+			cancel()
+			return nil, ctx.Err()
+		}), nil
+	}
+	selector := NewASRSelector()
+	selector.RegisterService("bcut", creator, 10)
+	selector.SetServiceOptions("bcut", ServiceOptions{Timeout: time.Minute, MaxRetries: 3})
+
+	start := time.Now()
+	_, _, err := selector.Recognize(ctx, audioPath, "bcut", false, nil)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 1, calls)
+	assert.Less(t, time.Since(start), time.Second, "取消后不应等待重试间隔")
+	assert.Equal(t, 0, selector.stats["bcut"].TotalCount)
+}
+
+// TestSleepContext 测试等待期间取消立即返回
+func TestSleepContext(t *testing.T) {
+	assert.NoError(t, sleepContext(context.Background(), time.Millisecond))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	start := time.Now()
+	assert.ErrorIs(t, sleepContext(ctx, time.Minute), context.Canceled)
+	assert.Less(t, time.Since(start), time.Second)
+}
@@ -0,0 +1,114 @@
+package asr
+
+import (
+	"sort"
+	"time"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/models"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/utils"
+)
+
+// ServiceOptions 单个服务的调用参数
+type ServiceOptions struct {
+	Timeout    time.Duration // 单次识别超时
+	MaxRetries int           // 失败后的重试次数
+
+	RequestsPerMinute int // 每分钟最多请求数，0 表示不限制
+	DailyQuota        int // 每日最多请求数，0 表示不限制
+
+	Languages []string // 支持的语言代码，为空时使用内置默认值
+
+	MaxFileSize int64 // 可上传的最大文件（字节），0 表示使用内置默认值
+}
+
+// DefaultServiceOptions 返回默认的服务调用参数
+func DefaultServiceOptions() ServiceOptions {
+	return ServiceOptions{
+		Timeout:    5 * time.Minute,
+		MaxRetries: 2,
+	}
+}
+
+// SetStrategy 设置自动选择服务时使用的策略 (weighted_random, round_robin)
+func (s *ASRSelector) SetStrategy(strategy string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.strategy = strategy
+}
+
+// SetServiceOptions 设置服务的调用参数
+func (s *ASRSelector) SetServiceOptions(name string, options ServiceOptions) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.options[name] = options
+
+	if options.RequestsPerMinute > 0 {
+		s.limiters[name] = utils.NewRateLimiter(options.RequestsPerMinute, time.Now())
+	} else {
+		delete(s.limiters, name)
+	}
+}
+
+// serviceOptions 返回服务的调用参数，未设置时使用默认值
+func (s *ASRSelector) serviceOptions(name string) ServiceOptions {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if options, ok := s.options[name]; ok {
+		return options
+	}
+	return DefaultServiceOptions()
+}
+
+// DefaultCreators 返回内置ASR服务的创建函数，键为配置中的服务名称
+func DefaultCreators() map[string]ServiceCreator {
+	return map[string]ServiceCreator{
+		"kuaishou": func(audioPath string, useCache bool) (ASRService, error) {
+			return NewKuaiShouASR(audioPath, useCache)
+		},
+		"bcut": func(audioPath string, useCache bool) (ASRService, error) {
+			return NewBcutASR(audioPath, useCache)
+		},
+	}
+}
+
+// RegisterFromConfig 按配置中的 asr_services 注册服务。
+// creators 提供各服务名称对应的创建函数；未启用或没有创建函数的服务会被跳过。
+func (s *ASRSelector) RegisterFromConfig(config *models.Config, creators map[string]ServiceCreator) {
+	if config.ASRStrategy != "" {
+		s.SetStrategy(config.ASRStrategy)
+	}
+
+	// 按名称排序，保证轮询顺序稳定
+	names := make([]string, 0, len(config.ASRServices))
+	for name := range config.ASRServices {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		serviceConfig := config.ASRServices[name]
+		if !serviceConfig.IsEnabled() {
+			utils.Info("ASR服务 %s 已在配置中禁用", name)
+			continue
+		}
+
+		creator, ok := creators[name]
+		if !ok {
+			utils.Warn("配置中的ASR服务 %s 不存在，已忽略", name)
+			continue
+		}
+
+		options := DefaultServiceOptions()
+		if serviceConfig.Timeout > 0 {
+			options.Timeout = time.Duration(serviceConfig.Timeout * float64(time.Second))
+		}
+		options.MaxRetries = serviceConfig.MaxRetries
+		options.RequestsPerMinute = serviceConfig.RequestsPerMinute
+		options.DailyQuota = serviceConfig.DailyQuota
+		options.Languages = serviceConfig.Languages
+		options.MaxFileSize = int64(serviceConfig.MaxFileSizeMB * (1 << 20))
+
+		s.RegisterService(name, creator, serviceConfig.Weight)
+		s.SetServiceOptions(name, options)
+	}
+}
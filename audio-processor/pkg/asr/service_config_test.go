@@ -0,0 +1,75 @@
+package asr
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegisterFromConfig(t *testing.T) {
+	disabled := false
+	config := models.NewDefaultConfig()
+	config.ASRStrategy = "round_robin"
+	config.ASRServices = map[string]models.ASRServiceConfig{
+		"bcut":     {Weight: 50, Timeout: 60, MaxRetries: 1},
+		"kuaishou": {Enabled: &disabled, Weight: 10},
+		"unknown":  {Weight: 10},
+	}
+
+	creator := func(audioPath string, useCache bool) (ASRService, error) { return nil, nil }
+	selector := NewASRSelector()
+	selector.RegisterFromConfig(config, map[string]ServiceCreator{
+		"bcut":     creator,
+		"kuaishou": creator,
+	})
+
+	assert.Equal(t, []string{"bcut"}, selector.serviceList)
+	assert.Equal(t, 50, selector.weights["bcut"])
+	assert.Equal(t, "round_robin", selector.strategy)
+	assert.Equal(t, ServiceOptions{Timeout: time.Minute, MaxRetries: 1}, selector.serviceOptions("bcut"))
+
+	// 未配置的服务使用默认参数
+	assert.Equal(t, DefaultServiceOptions(), selector.serviceOptions("kuaishou"))
+}
+
+func TestSelectServiceByLanguage(t *testing.T) {
+	creator := func(audioPath string, useCache bool) (ASRService, error) { return nil, nil }
+	selector := NewASRSelector()
+	selector.RegisterService("bcut", creator, 10)
+	selector.RegisterService("kuaishou", creator, 10)
+	selector.SetServiceOptions("kuaishou", ServiceOptions{Timeout: time.Minute, Languages: []string{"ja"}})
+
+	ctx := WithLanguage(context.Background(), "en")
+	for i := 0; i < 10; i++ {
+		name, _, err := selector.selectWithQuota(ctx, "round_robin")
+		assert.NoError(t, err)
+		assert.Equal(t, "bcut", name)
+	}
+
+	// 配置中声明的语言覆盖内置默认值
+	name, _, err := selector.selectWithQuota(WithLanguage(context.Background(), "ja"), "weighted_random")
+	assert.NoError(t, err)
+	assert.Equal(t, "kuaishou", name)
+
+	_, _, err = selector.selectWithQuota(WithLanguage(context.Background(), "fr"), "weighted_random")
+	assert.Error(t, err)
+}
+
+func TestMaxFileSize(t *testing.T) {
+	creator := func(audioPath string, useCache bool) (ASRService, error) { return nil, nil }
+	selector := NewASRSelector()
+	selector.RegisterService("bcut", creator, 10)
+	selector.RegisterService("kuaishou", creator, 10)
+
+	assert.Equal(t, int64(100<<20), selector.MaxFileSize("bcut"))
+	// 自动选择时取所有服务中最小的上限
+	assert.Equal(t, int64(20<<20), selector.MaxFileSize("auto"))
+	assert.Zero(t, selector.MaxFileSize("custom"))
+
+	// 配置中的上限覆盖内置默认值
+	selector.SetServiceOptions("bcut", ServiceOptions{MaxFileSize: 5 << 20})
+	assert.Equal(t, int64(5<<20), selector.MaxFileSize(ConsensusServiceName))
+}
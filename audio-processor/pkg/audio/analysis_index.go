@@ -0,0 +1,42 @@
+package audio
+
+import (
+	"path/filepath"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/analysis"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/utils"
+)
+
+// AnalysisIndexPath 返回关键词索引的路径：<outputDir>/batches/tags.json
+func AnalysisIndexPath(outputDir string) string {
+	return filepath.Join(outputDir, BatchManifestDir, analysis.IndexFileName)
+}
+
+// updateAnalysisIndex 将本次处理的文件的关键词、实体与主题汇总到关键词索引
+func (p *BatchProcessor) updateAnalysisIndex(results []BatchResult) {
+	if p.config == nil || !p.config.ExportAnalysis {
+		return
+	}
+	collected := make(map[string]*analysis.Result)
+	for _, result := range results {
+		path := result.OutputFiles["analysis"]
+		if !result.Success || path == "" {
+			continue
+		}
+		item, err := analysis.LoadResult(path)
+		if err != nil {
+			utils.Warn("%v", err)
+			continue
+		}
+		collected[result.FilePath] = item
+	}
+	if len(collected) == 0 {
+		return
+	}
+	indexPath := AnalysisIndexPath(p.OutputDir)
+	if err := analysis.UpdateIndex(indexPath, collected); err != nil {
+		utils.Warn("更新关键词索引失败: %v", err)
+		return
+	}
+	utils.Info("关键词索引已更新: %s（%d 个文件）", indexPath, len(collected))
+}
@@ -0,0 +1,208 @@
+package audio
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/export"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/ffmpeg"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/utils"
+)
+
+// archive_mode 取值
+const (
+	ArchiveOff   = "off"   // 不归档
+	ArchiveHEVC  = "hevc"  // 视频重新编码为 H.265
+	ArchiveAudio = "audio" // 丢弃视频，只保留音频
+)
+
+// 归档文件与原文件时长允许的最大差异（秒），超出时认为转码不完整，保留原文件
+const archiveDurationTolerance = 2
+
+// ArchiveCandidates 返回识别完成已超过安全延迟、尚未归档且仍然存在的视频文件
+func (p *BatchProcessor) ArchiveCandidates(now time.Time) []string {
+	delay := time.Duration(p.config.ArchiveDelay * float64(time.Hour))
+
+	p.recordsMu.RLock()
+	defer p.recordsMu.RUnlock()
+
+	var files []string
+	for path, record := range p.processedRecords {
+		if !record.Completed || record.Status != "" || record.ArchivedTime != "" || !p.isVideoFile(path) {
+			continue
+		}
+		processed, err := time.ParseInLocation("2006-01-02 15:04:05", record.LastProcessedTime, time.Local)
+		if err != nil || now.Sub(processed) < delay {
+			continue
+		}
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+		files = append(files, path)
+	}
+	sort.Strings(files)
+	return files
+}
+
+// ArchivePending 按 archive_mode 转码已到期的原文件以节省空间，返回归档的文件数
+func (p *BatchProcessor) ArchivePending() int {
+	if p.config.ArchiveMode == "" || p.config.ArchiveMode == ArchiveOff {
+		return 0
+	}
+
+	ctx := p.baseContext()
+	archived := 0
+	for _, filePath := range p.ArchiveCandidates(time.Now()) {
+		if ctx.Err() != nil {
+			break
+		}
+		p.acquireWorker()
+		err := p.archiveFile(ctx, filePath)
+		p.releaseWorker()
+		if err != nil {
+			utils.Warn("归档失败，保留原文件 %s: %v", filePath, err)
+			continue
+		}
+		archived++
+	}
+	return archived
+}
+
+// archiveFile 转码单个文件。先在原文件旁写入临时文件并校验时长，
+// 确认归档文件更小后将原文件移入回收站，再把归档文件放到原位置
+func (p *BatchProcessor) archiveFile(ctx context.Context, filePath string) error {
+	target := p.archivePath(filePath)
+	if target != filePath {
+		if _, err := os.Stat(target); err == nil {
+			return fmt.Errorf("归档文件路径已被占用: %s", target)
+		}
+	}
+	tmpPath := filepath.Join(filepath.Dir(filePath), "."+filepath.Base(target)+".archiving.tmp")
+	defer os.Remove(tmpPath)
+
+	utils.Info("开始归档 %s (%s)", filepath.Base(filePath), p.config.ArchiveMode)
+	start := time.Now()
+	if err := p.Extractor.runFFmpeg(ctx, ffmpeg.FFmpeg(p.archiveArgs(filePath, tmpPath)...)); err != nil {
+		return fmt.Errorf("转码失败: %w", err)
+	}
+
+	if err := p.verifyArchive(ctx, filePath, tmpPath); err != nil {
+		return err
+	}
+
+	original, err := os.Stat(filePath)
+	if err != nil {
+		return fmt.Errorf("读取原文件失败: %w", err)
+	}
+	archive, err := os.Stat(tmpPath)
+	if err != nil {
+		return fmt.Errorf("读取归档文件失败: %w", err)
+	}
+	if archive.Size() >= original.Size() {
+		utils.Info("归档后文件未变小 (%s -> %s)，保留原文件: %s",
+			utils.FormatFileSize(original.Size()), utils.FormatFileSize(archive.Size()), filepath.Base(filePath))
+		p.markArchived(filePath, filePath)
+		return nil
+	}
+
+	// 先更新记录，避免监听模式把放回的归档文件当作新文件处理
+	previous := p.markArchived(filePath, target)
+	if _, err := p.Trash.Remove(filePath, "archive", "已转码为节省空间的归档文件"); err != nil {
+		p.restoreRecord(filePath, target, previous)
+		return fmt.Errorf("移除原文件失败: %w", err)
+	}
+	if err := os.Rename(tmpPath, target); err != nil {
+		return fmt.Errorf("放置归档文件失败（原文件在回收站中）: %w", err)
+	}
+
+	for _, fileType := range []string{"archive", "media"} {
+		if _, err := export.LinkOutput(p.config, target, fileType, target); err != nil {
+			utils.Warn("更新输出清单失败: %v", err)
+		}
+	}
+
+	utils.Info("已归档 %s: %s -> %s，耗时 %s", filepath.Base(target),
+		utils.FormatFileSize(original.Size()), utils.FormatFileSize(archive.Size()), time.Since(start).Round(time.Second))
+	return nil
+}
+
+// archivePath 返回归档文件的路径：H.265 归档为 mp4，仅保留音频时为 m4a
+func (p *BatchProcessor) archivePath(filePath string) string {
+	ext := ".mp4"
+	if p.config.ArchiveMode == ArchiveAudio {
+		ext = ".m4a"
+	}
+	return strings.TrimSuffix(filePath, filepath.Ext(filePath)) + ext
+}
+
+// archiveArgs 返回转码的 ffmpeg 参数
+func (p *BatchProcessor) archiveArgs(input, output string) []string {
+	args := []string{"-y", "-v", "error", "-i", input}
+	if p.config.ArchiveMode == ArchiveAudio {
+		args = append(args, "-map", "0:a:0", "-vn")
+	} else {
+		args = append(args,
+			"-map", "0:v:0", "-map", "0:a?",
+			"-c:v", "libx265", "-crf", strconv.Itoa(p.config.ArchiveCRF), "-preset", "medium",
+			"-tag:v", "hvc1",
+		)
+	}
+	args = append(args, "-c:a", "aac", "-b:a", p.config.ArchiveAudioBitrate, "-movflags", "+faststart", "-f", "mp4", output)
+	return args
+}
+
+// verifyArchive 校验归档文件的时长与原文件一致
+func (p *BatchProcessor) verifyArchive(ctx context.Context, original, archive string) error {
+	want, err := p.Extractor.getAudioDuration(ctx, original)
+	if err != nil {
+		return fmt.Errorf("获取原文件时长失败: %w", err)
+	}
+	got, err := p.Extractor.getAudioDuration(ctx, archive)
+	if err != nil {
+		return fmt.Errorf("获取归档文件时长失败: %w", err)
+	}
+	if math.Abs(float64(got-want)) > archiveDurationTolerance {
+		return fmt.Errorf("归档文件时长 %d 秒与原文件 %d 秒不一致", got, want)
+	}
+	return nil
+}
+
+// markArchived 记录归档时间，文件路径变化时将记录移到新路径，返回修改前的记录
+func (p *BatchProcessor) markArchived(filePath, target string) ProcessedRecord {
+	p.recordsMu.Lock()
+	key := filepath.Clean(filePath)
+	previous := p.processedRecords[key]
+	record := previous
+	record.ArchivedTime = time.Now().Format("2006-01-02 15:04:05")
+	if target != filePath {
+		record.ArchivedFrom = filepath.Base(filePath)
+		record.Filename = filepath.Base(target)
+		p.deleteRecord(key)
+	}
+	p.setRecord(filepath.Clean(target), record)
+	p.recordsMu.Unlock()
+
+	if err := p.saveProcessedRecords(); err != nil {
+		utils.Warn("保存处理记录失败: %v", err)
+	}
+	return previous
+}
+
+// restoreRecord 归档中止时恢复原文件的记录
+func (p *BatchProcessor) restoreRecord(filePath, target string, previous ProcessedRecord) {
+	p.recordsMu.Lock()
+	p.deleteRecord(filepath.Clean(target))
+	p.setRecord(filepath.Clean(filePath), previous)
+	p.recordsMu.Unlock()
+
+	if err := p.saveProcessedRecords(); err != nil {
+		utils.Warn("保存处理记录失败: %v", err)
+	}
+}
@@ -0,0 +1,63 @@
+package audio
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/models"
+)
+
+// TestArchiveCandidates 测试只选择已过安全延迟、未归档且仍然存在的视频文件
+func TestArchiveCandidates(t *testing.T) {
+	dir := t.TempDir()
+	due := filepath.Join(dir, "due.mp4")
+	recent := filepath.Join(dir, "recent.mp4")
+	archived := filepath.Join(dir, "archived.mp4")
+	audioFile := filepath.Join(dir, "podcast.mp3")
+	for _, path := range []string{due, recent, archived, audioFile} {
+		require.NoError(t, os.WriteFile(path, []byte("x"), 0644))
+	}
+
+	now := time.Date(2024, 5, 2, 12, 0, 0, 0, time.Local)
+	old := now.Add(-25 * time.Hour).Format("2006-01-02 15:04:05")
+	config := models.NewDefaultConfig()
+	config.ArchiveDelay = 24
+	processor := &BatchProcessor{
+		config:          config,
+		VideoExtensions: []string{".mp4", ".mov"},
+		processedRecords: map[string]ProcessedRecord{
+			due:                            {Completed: true, LastProcessedTime: old},
+			recent:                         {Completed: true, LastProcessedTime: now.Add(-time.Hour).Format("2006-01-02 15:04:05")},
+			archived:                       {Completed: true, LastProcessedTime: old, ArchivedTime: old},
+			audioFile:                      {Completed: true, LastProcessedTime: old},
+			filepath.Join(dir, "gone.mp4"): {Completed: true, LastProcessedTime: old},
+		},
+	}
+
+	assert.Equal(t, []string{due}, processor.ArchiveCandidates(now))
+}
+
+// TestArchiveArgs 测试不同归档方式的输出路径与转码参数
+func TestArchiveArgs(t *testing.T) {
+	config := models.NewDefaultConfig()
+	config.ArchiveMode = ArchiveHEVC
+	processor := &BatchProcessor{config: config}
+
+	assert.Equal(t, filepath.Join("videos", "demo.mp4"), processor.archivePath(filepath.Join("videos", "demo.mkv")))
+	args := processor.archiveArgs("demo.mkv", "out.tmp")
+	assert.Contains(t, args, "libx265")
+	assert.Contains(t, args, "28")
+	assert.Equal(t, "out.tmp", args[len(args)-1])
+
+	config.ArchiveMode = ArchiveAudio
+	assert.Equal(t, filepath.Join("videos", "demo.m4a"), processor.archivePath(filepath.Join("videos", "demo.mkv")))
+	args = processor.archiveArgs("demo.mkv", "out.tmp")
+	assert.Contains(t, args, "-vn")
+	assert.NotContains(t, args, "libx265")
+	assert.Contains(t, args, "96k")
+}
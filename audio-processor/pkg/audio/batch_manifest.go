@@ -0,0 +1,157 @@
+package audio
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BatchManifestDir 批次清单所在的目录名，位于输出目录下
+const BatchManifestDir = "batches"
+
+// BatchManifest 一次批处理的结果清单
+type BatchManifest struct {
+	StartedAt  string              `json:"started_at"`
+	FinishedAt string              `json:"finished_at"`
+	Total      int                 `json:"total"`
+	Succeeded  int                 `json:"succeeded"`
+	Failed     int                 `json:"failed"`
+	Files      []BatchManifestFile `json:"files"`
+}
+
+// BatchManifestFile 批次中的一个输入文件
+type BatchManifestFile struct {
+	File           string            `json:"file"`
+	Success        bool              `json:"success"`
+	Status         string            `json:"status,omitempty"`  // 成功但未识别时的状态，如 "无音频"
+	Service        string            `json:"service,omitempty"` // 完成识别的ASR服务
+	Duration       int               `json:"duration_seconds"`
+	ProcessSeconds float64           `json:"process_seconds"`
+	Outputs        map[string]string `json:"outputs,omitempty"` // 格式 -> 路径
+	Error          string            `json:"error,omitempty"`
+	ErrorKind      string            `json:"error_kind,omitempty"`
+	LogPath        string            `json:"log_path,omitempty"`
+}
+
+// NewBatchManifest 根据批处理结果生成清单，文件按路径排序
+func NewBatchManifest(results []BatchResult, startedAt, finishedAt time.Time) BatchManifest {
+	manifest := BatchManifest{
+		StartedAt:  startedAt.Format("2006-01-02 15:04:05"),
+		FinishedAt: finishedAt.Format("2006-01-02 15:04:05"),
+		Total:      len(results),
+		Files:      make([]BatchManifestFile, 0, len(results)),
+	}
+	for _, result := range results {
+		file := BatchManifestFile{
+			File:           result.FilePath,
+			Success:        result.Success,
+			Status:         result.Status,
+			Service:        result.Service,
+			Duration:       result.Duration,
+			ProcessSeconds: result.ProcessTime.Round(time.Millisecond).Seconds(),
+			Outputs:        result.OutputFiles,
+			ErrorKind:      result.ErrorKind,
+			LogPath:        result.LogPath,
+		}
+		if result.Error != nil {
+			file.Error = result.Error.Error()
+		}
+		if result.Success {
+			manifest.Succeeded++
+		} else {
+			manifest.Failed++
+		}
+		manifest.Files = append(manifest.Files, file)
+	}
+	sort.Slice(manifest.Files, func(i, j int) bool { return manifest.Files[i].File < manifest.Files[j].File })
+	return manifest
+}
+
+// WriteBatchManifest 将批次清单写入 <outputDir>/batches/batch-<时间>.json 与同名 .csv，
+// 并更新 latest.json 指向最近一次批处理，返回 JSON 清单的路径
+func WriteBatchManifest(outputDir string, results []BatchResult, startedAt time.Time) (string, error) {
+	manifest := NewBatchManifest(results, startedAt, time.Now())
+
+	dir := filepath.Join(outputDir, BatchManifestDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("创建批次清单目录失败: %w", err)
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("编码批次清单失败: %w", err)
+	}
+	base := filepath.Join(dir, "batch-"+startedAt.Format("20060102-150405"))
+	jsonPath := base + ".json"
+	if err := os.WriteFile(jsonPath, data, 0644); err != nil {
+		return "", fmt.Errorf("写入批次清单失败: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "latest.json"), data, 0644); err != nil {
+		return "", fmt.Errorf("写入批次清单失败: %w", err)
+	}
+	if err := writeBatchCSV(base+".csv", manifest); err != nil {
+		return "", err
+	}
+	return jsonPath, nil
+}
+
+// batchCSVHeader CSV 清单的列，输出文件合并为 "格式=路径" 并以分号分隔
+var batchCSVHeader = []string{
+	"file", "success", "status", "service", "duration_seconds", "process_seconds",
+	"outputs", "error_kind", "error", "log_path",
+}
+
+// writeBatchCSV 以 CSV 格式写入批次清单，带 UTF-8 BOM 以便表格软件正确显示中文
+func writeBatchCSV(path string, manifest BatchManifest) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("创建CSV清单失败: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.WriteString("\ufeff"); err != nil {
+		return fmt.Errorf("写入CSV清单失败: %w", err)
+	}
+	writer := csv.NewWriter(file)
+	writer.Write(batchCSVHeader)
+	for _, f := range manifest.Files {
+		writer.Write([]string{
+			f.File,
+			strconv.FormatBool(f.Success),
+			f.Status,
+			f.Service,
+			strconv.Itoa(f.Duration),
+			strconv.FormatFloat(f.ProcessSeconds, 'f', 3, 64),
+			joinOutputs(f.Outputs),
+			f.ErrorKind,
+			f.Error,
+			f.LogPath,
+		})
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("写入CSV清单失败: %w", err)
+	}
+	return nil
+}
+
+// joinOutputs 将输出文件按格式排序后合并为 "格式=路径;..."
+func joinOutputs(outputs map[string]string) string {
+	types := make([]string, 0, len(outputs))
+	for fileType := range outputs {
+		types = append(types, fileType)
+	}
+	sort.Strings(types)
+
+	parts := make([]string, 0, len(types))
+	for _, fileType := range types {
+		parts = append(parts, fileType+"="+outputs[fileType])
+	}
+	return strings.Join(parts, ";")
+}
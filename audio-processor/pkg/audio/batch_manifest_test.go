@@ -0,0 +1,61 @@
+package audio
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestWriteBatchManifest 测试批次清单按文件排序记录结果，并同时生成 JSON、CSV 与 latest.json
+func TestWriteBatchManifest(t *testing.T) {
+	dir := t.TempDir()
+	results := []BatchResult{
+		{
+			FilePath:    "/media/b.mp4",
+			Success:     false,
+			Error:       errors.New("识别失败, 请重试"),
+			ErrorKind:   ErrorKindASR,
+			ProcessTime: 1500 * time.Millisecond,
+		},
+		{
+			FilePath:    "/media/a.mp4",
+			Success:     true,
+			Service:     "bcut",
+			Duration:    90,
+			OutputFiles: map[string]string{"txt": "/out/a.txt", "srt": "/media/a.srt"},
+			ProcessTime: 3 * time.Second,
+		},
+	}
+	startedAt := time.Date(2024, 5, 2, 12, 0, 0, 0, time.Local)
+
+	jsonPath, err := WriteBatchManifest(dir, results, startedAt)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, BatchManifestDir, "batch-20240502-120000.json"), jsonPath)
+
+	data, err := os.ReadFile(jsonPath)
+	require.NoError(t, err)
+	var manifest BatchManifest
+	require.NoError(t, json.Unmarshal(data, &manifest))
+	assert.Equal(t, 2, manifest.Total)
+	assert.Equal(t, 1, manifest.Succeeded)
+	assert.Equal(t, 1, manifest.Failed)
+	require.Len(t, manifest.Files, 2)
+	assert.Equal(t, "/media/a.mp4", manifest.Files[0].File)
+	assert.Equal(t, 90, manifest.Files[0].Duration)
+	assert.Equal(t, "识别失败, 请重试", manifest.Files[1].Error)
+	assert.FileExists(t, filepath.Join(dir, BatchManifestDir, "latest.json"))
+
+	csvData, err := os.ReadFile(filepath.Join(dir, BatchManifestDir, "batch-20240502-120000.csv"))
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimPrefix(string(csvData), "\ufeff"), "\n")
+	assert.Equal(t, strings.Join(batchCSVHeader, ","), lines[0])
+	assert.Contains(t, lines[1], "srt=/media/a.srt;txt=/out/a.txt")
+	assert.Contains(t, lines[2], `"识别失败, 请重试"`)
+}
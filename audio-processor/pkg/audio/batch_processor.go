@@ -8,11 +8,20 @@ import (
 	"path/filepath"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/ccp-p/asr-media-cli/audio-processor/internal/ui"
 	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/asr"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/diskspace"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/export"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/langdetect"
 	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/models"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/storage"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/store"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/tempdir"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/retention"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/trash"
 	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/utils"
 	"github.com/google/uuid"
 )
@@ -23,12 +32,28 @@ type BatchResult struct {
 	Success     bool
 	OutputPath  string
 	Error       error
+	ErrorKind   string // 失败类型，见 ErrorKind 常量
+	Status      string // 成功但未识别时的状态，如 StatusNoAudio、StatusMusic
+	Service     string // 完成识别的ASR服务
+	LogPath     string // 失败时该文件的处理日志路径
 	ProcessTime time.Duration
+	Duration    int               // 音频时长（秒），未能获取时为 0
+	OutputFiles map[string]string // 生成的输出文件（格式 -> 路径）
+
+	log          *FileLog       // 处理过程中的文件日志
+	contentHash  string         // 源文件的内容哈希，未计算时为空
+	duplicateOf  string         // 内容相同、已识别过的文件
+	asrService   string         // 指定使用的ASR服务，为空时使用配置中的 asr_service
+	exportConfig *models.Config // 导出结果使用的配置，为空时使用处理器的配置
+	clipOffset   float64        // 只识别部分时间范围时的起始时间（秒），识别结果的时间按此平移回源文件的时间
 }
 
 // BatchProgressCallback 批处理进度回调
 type BatchProgressCallback func(current, total int, filename string, result *BatchResult)
 
+// FileProgressCallback 单个文件的处理进度回调，filePath 为源文件路径
+type FileProgressCallback func(filePath string, percent int, message string)
+
 // ProcessedRecord 表示已处理文件的记录
 type ProcessedRecord struct {
 	LastProcessedTime string            `json:"last_processed_time"`
@@ -36,14 +61,30 @@ type ProcessedRecord struct {
 	Filename          string            `json:"filename"`
 	TotalDuration     float64           `json:"total_duration"`
 	TotalParts        int               `json:"total_parts,omitempty"`
+	Status            string            `json:"status,omitempty"` // 完成但未识别时的状态，如 "无音频"
+	Service           string            `json:"service,omitempty"`          // 完成识别的ASR服务
+	ReplacedService   string            `json:"replaced_service,omitempty"` // 重新识别前使用的备用服务
+	ReprocessedTime   string            `json:"reprocessed_time,omitempty"` // 使用首选服务重新识别的时间
+	ArchivedTime      string            `json:"archived_time,omitempty"`    // 转码归档的时间
+	ArchivedFrom      string            `json:"archived_from,omitempty"`    // 归档前的原文件名，归档后文件未变小而保留原文件时为空
+	ContentHash       string            `json:"content_hash,omitempty"`     // 文件大小与部分内容的哈希，用于识别重复文件
+	DuplicateOf       string            `json:"duplicate_of,omitempty"`     // 内容相同、已识别过的文件，本文件未重新识别
 	Parts             map[string]Part   `json:"parts,omitempty"`
 }
 
 // Part 表示文件处理的一部分
 type Part struct {
-	Completed      bool   `json:"completed"`
-	OutputFile     string `json:"output_file"`
-	CompletedTime  string `json:"completed_time"`
+	Completed      bool    `json:"completed"`
+	OutputFile     string  `json:"output_file"`
+	CompletedTime  string  `json:"completed_time"`
+	StartTime      float64 `json:"start_time"`        // 分段在原始音频中的起止时间（秒），用于校验能否恢复
+	EndTime        float64 `json:"end_time"`
+	Service        string  `json:"service,omitempty"` // 识别该分段的ASR服务
+}
+
+// interrupted 判断是否为中断（或失败）的分段识别，重新处理时可从已完成的分段继续
+func (r ProcessedRecord) interrupted() bool {
+	return !r.Completed && r.TotalParts > 0
 }
 
 // BatchProcessor 批量处理器
@@ -53,14 +94,36 @@ type BatchProcessor struct {
 	TempDir            string
 	MaxConcurrency     int
 	VideoExtensions    []string
+	AudioExtensions    []string
 	Extractor          *AudioExtractor
 	ProgressCallback   BatchProgressCallback
+	FileProgressCallback FileProgressCallback
 	config             *models.Config
 	ProgressManager    *ui.ProgressManager
 	ASRSelector        *asr.ASRSelector
+	Trash              *trash.Trash // 删除用户文件时使用的回收站
+	LanguageDetector   langdetect.Detector // 语言检测器，未启用时为 nil
+	TempManager        *tempdir.Manager    // 为每个文件分配独立的临时目录
+	DiskGuard          *diskspace.Guard    // 磁盘可用空间不足时暂停提取与切分，未启用时为 nil
+	Storage            storage.Storage     // 输出的远程存储，只写本地输出目录时为 nil
 	ctx                context.Context
 	processedRecordFile string
 	processedRecords    map[string]ProcessedRecord
+	recordsMu           sync.RWMutex
+	store               *store.Store    // 处理记录数据库，使用 JSON 文件保存记录时为 nil
+	dirtyRecords        map[string]bool // 尚未写入数据库的记录，true 表示修改，false 表示删除
+
+	// 单个文件的处理流水线，未设置时使用内置阶段与配置中的自定义阶段
+	Pipeline     *Pipeline
+	pipelineOnce sync.Once
+
+	// 批处理与监听模式共享的处理槽位，限制同时运行的处理流程数量
+	workers    *workerPool
+	workerOnce sync.Once
+
+	// 当前批次的等待队列，没有进行中的批处理时为 nil
+	queue   *jobQueue
+	queueMu sync.Mutex
 }
 
 // SetASRSelector
@@ -68,11 +131,68 @@ func (p *BatchProcessor) SetASRSelector(selector *asr.ASRSelector) {
 	p.ASRSelector = selector
 }
 
-// SetContext 设置上下文
+// SetFileProgressCallback 设置单个文件的处理进度回调
+func (p *BatchProcessor) SetFileProgressCallback(callback FileProgressCallback) {
+	p.FileProgressCallback = callback
+}
+
+// reportFileProgress 上报单个文件的处理进度，同时发送给进度管理器的订阅者
+func (p *BatchProcessor) reportFileProgress(filePath, stage string, percent int, message string) {
+	if p.FileProgressCallback != nil {
+		p.FileProgressCallback(filePath, percent, message)
+	}
+	if p.ProgressManager != nil {
+		p.ProgressManager.Publish(ui.ProgressEvent{ID: filePath, Stage: stage, Percent: percent, Message: message})
+	}
+}
+
+// reportFileDone 通知进度管理器的订阅者文件处理已结束，附带输出文件或失败原因
+func (p *BatchProcessor) reportFileDone(result *BatchResult) {
+	if p.ProgressManager == nil {
+		return
+	}
+	event := ui.ProgressEvent{ID: result.FilePath, Percent: 100, Message: result.Status, Done: true, Outputs: result.OutputFiles}
+	if !result.Success && result.Error != nil {
+		event.Error = result.Error.Error()
+	}
+	p.ProgressManager.Publish(event)
+}
+
+// SetTrash 设置删除文件时使用的回收站
+func (p *BatchProcessor) SetTrash(bin *trash.Trash) {
+	p.Trash = bin
+}
+
+// SetTempManager 设置临时目录管理器
+func (p *BatchProcessor) SetTempManager(manager *tempdir.Manager) {
+	p.TempManager = manager
+}
+
+// allocateTempJob 为单个文件的处理分配临时目录
+func (p *BatchProcessor) allocateTempJob(name string) (*tempdir.Job, error) {
+	if p.TempManager == nil {
+		manager, err := tempdir.NewManager(p.TempDir)
+		if err != nil {
+			return nil, err
+		}
+		p.TempManager = manager
+	}
+	return p.TempManager.Allocate(name)
+}
+
+// SetContext 设置上下文，取消后正在执行的 ffmpeg 与上传会被终止，不再开始新的文件
 func (p *BatchProcessor) SetContext(ctx context.Context) {
 	p.ctx = ctx
 }
 
+// baseContext 返回设置的上下文，未设置时返回 context.Background()
+func (p *BatchProcessor) baseContext() context.Context {
+	if p.ctx == nil {
+		return context.Background()
+	}
+	return p.ctx
+}
+
 // NewBatchProcessor 创建批处理器
 func NewBatchProcessor(mediaDir, outputDir, tempDir string, callback BatchProgressCallback, config *models.Config) *BatchProcessor {
 	// 确保目录存在
@@ -87,15 +207,41 @@ func NewBatchProcessor(mediaDir, outputDir, tempDir string, callback BatchProgre
 		OutputDir:          outputDir,
 		TempDir:            tempDir,
 		MaxConcurrency:     4, // 默认并发数
-		VideoExtensions:    []string{".mp4", ".mov", ".avi", ".mkv", ".flv", ".wmv"},
+		VideoExtensions:    append([]string{}, DefaultVideoExtensions...),
+		AudioExtensions:    append([]string{}, DefaultAudioExtensions...),
 		Extractor:          NewAudioExtractor(tempSegmentsDir, nil, config),
 		config:             config,
 		ProgressCallback:   callback,
 		processedRecordFile: filepath.Join(outputDir, "processed_records.json"),
 		processedRecords:    make(map[string]ProcessedRecord),
+		Trash:               trash.FromConfig(config),
+		DiskGuard:           diskspace.FromConfig(config, tempDir, outputDir),
+	}
+
+	// 清理之前异常退出时遗留的临时目录
+	if manager, err := tempdir.NewManager(tempDir); err != nil {
+		utils.Warn("%v", err)
+	} else {
+		if _, err := manager.RecoverStale(); err != nil {
+			utils.Warn("%v", err)
+		}
+		processor.TempManager = manager
+	}
+
+	detector, err := langdetect.FromConfig(config)
+	if err != nil {
+		utils.Warn("初始化语言检测失败: %v", err)
 	}
+	processor.LanguageDetector = detector
+
+	remote, err := storage.FromConfig(config)
+	if err != nil {
+		utils.Warn("初始化输出存储失败: %v, 只写入本地输出目录", err)
+	}
+	processor.Storage = remote
 
 	// 加载处理记录
+	processor.openRecordStore()
 	processor.loadProcessedRecords()
 
 	return processor
@@ -103,6 +249,10 @@ func NewBatchProcessor(mediaDir, outputDir, tempDir string, callback BatchProgre
 
 // loadProcessedRecords 从文件加载处理记录
 func (p *BatchProcessor) loadProcessedRecords() {
+	if p.store != nil {
+		p.loadRecordsFromStore()
+		return
+	}
 	data, err := utils.LoadJSONFile(p.processedRecordFile, make(map[string]ProcessedRecord))
 	if err != nil {
 		utils.Warn("加载处理记录失败: %v, 将使用空记录", err)
@@ -123,6 +273,14 @@ func (p *BatchProcessor) loadProcessedRecords() {
 
 				// 解析时间
 				processed.LastProcessedTime = utils.GetStringValue(recordMap, "last_processed_time", "")
+				processed.Status = utils.GetStringValue(recordMap, "status", "")
+				processed.Service = utils.GetStringValue(recordMap, "service", "")
+				processed.ReplacedService = utils.GetStringValue(recordMap, "replaced_service", "")
+				processed.ReprocessedTime = utils.GetStringValue(recordMap, "reprocessed_time", "")
+				processed.ArchivedTime = utils.GetStringValue(recordMap, "archived_time", "")
+				processed.ArchivedFrom = utils.GetStringValue(recordMap, "archived_from", "")
+				processed.ContentHash = utils.GetStringValue(recordMap, "content_hash", "")
+				processed.DuplicateOf = utils.GetStringValue(recordMap, "duplicate_of", "")
 
 				// 解析parts
 				if partsData, ok := recordMap["parts"].(map[string]interface{}); ok {
@@ -133,6 +291,9 @@ func (p *BatchProcessor) loadProcessedRecords() {
 								Completed:     utils.GetBoolValue(partMap, "completed", false),
 								OutputFile:    utils.GetStringValue(partMap, "output_file", ""),
 								CompletedTime: utils.GetStringValue(partMap, "completed_time", ""),
+								StartTime:     utils.GetFloat64Value(partMap, "start_time", 0),
+								EndTime:       utils.GetFloat64Value(partMap, "end_time", 0),
+								Service:       utils.GetStringValue(partMap, "service", ""),
 							}
 							processed.Parts[partKey] = part
 						}
@@ -152,7 +313,12 @@ func (p *BatchProcessor) loadProcessedRecords() {
 
 // saveProcessedRecords 保存处理记录到文件
 func (p *BatchProcessor) saveProcessedRecords() error {
+	if p.store != nil {
+		return p.saveRecordsToStore()
+	}
+	p.recordsMu.RLock()
 	err := utils.SaveJSONFile(p.processedRecordFile, p.processedRecords)
+	p.recordsMu.RUnlock()
 	if err != nil {
 		utils.Error("保存处理记录失败: %v", err)
 		return fmt.Errorf("保存处理记录失败: %w", err)
@@ -174,10 +340,16 @@ func (p *BatchProcessor) ProcessVideoFiles() ([]BatchResult, error) {
 	if err != nil {
 		return nil, err
 	}
+	return p.ProcessFiles(files), nil
+}
 
+// ProcessFiles 作为一个批次处理指定的文件，不扫描媒体目录。结果按完成顺序返回
+func (p *BatchProcessor) ProcessFiles(files []string) []BatchResult {
 	if len(files) == 0 {
-		return []BatchResult{}, nil
+		return []BatchResult{}
 	}
+	batchStart := time.Now()
+	p.checkDiskSpace()
 
 	// 创建总进度条
 	if p.ProgressManager != nil {
@@ -188,24 +360,41 @@ func (p *BatchProcessor) ProcessVideoFiles() ([]BatchResult, error) {
 	// 创建结果通道
 	results := make(chan BatchResult, len(files))
 
+	// 按优先级排列等待处理的文件，槽位空闲时才取出下一个，运行中置顶的文件也能优先处理
+	queue := newJobQueue(files, p.queueOrder(), p.queuePinned())
+	p.setBatchQueue(queue)
+	defer p.setBatchQueue(nil)
+
 	// 使用协程池处理文件
 	var wg sync.WaitGroup
-	sem := make(chan struct{}, p.MaxConcurrency) // 信号量限制并发
-
-	for i, filePath := range files {
+	var completed int32
+
+	ctx := p.baseContext()
+	for started := 1; ; started++ {
+		p.acquireWorker() // 获取处理槽位
+		// 取消后不再开始新的文件，已开始的文件会终止并清理未完成的输出
+		if ctx.Err() != nil {
+			p.releaseWorker()
+			utils.Warn("处理已取消，剩余 %d 个文件未处理", len(queue.pending()))
+			break
+		}
+		job, ok := queue.pop()
+		if !ok {
+			p.releaseWorker()
+			break
+		}
 		wg.Add(1)
-		sem <- struct{}{} // 获取信号量
 
 		go func(index int, path string) {
 			defer wg.Done()
-			defer func() { <-sem }() // 释放信号量
+			defer p.releaseWorker() // 释放处理槽位
 
 			filename := filepath.Base(path)
 			startTime := time.Now()
 
 			// 通知处理开始
 			if p.ProgressCallback != nil {
-				p.ProgressCallback(index+1, len(files), filename, nil)
+				p.ProgressCallback(index, len(files), filename, nil)
 			}
 
 			// 处理单个文件
@@ -214,17 +403,18 @@ func (p *BatchProcessor) ProcessVideoFiles() ([]BatchResult, error) {
 
 			// 通知处理结束
 			if p.ProgressCallback != nil {
-				p.ProgressCallback(index+1, len(files), filename, &result)
+				p.ProgressCallback(index, len(files), filename, &result)
 			}
 
 			// 更新总进度条
 			if p.ProgressManager != nil {
-				p.ProgressManager.UpdateProgressBar("batch_overall", index+1,
-					fmt.Sprintf("%d/%d 文件已处理", index+1, len(files)))
+				done := int(atomic.AddInt32(&completed, 1))
+				p.ProgressManager.UpdateProgressBar("batch_overall", done,
+					fmt.Sprintf("%d/%d 文件已处理", done, len(files)))
 			}
 
 			results <- result
-		}(i, filePath)
+		}(started, job.path)
 	}
 
 	// 等待所有文件处理完成
@@ -252,15 +442,42 @@ func (p *BatchProcessor) ProcessVideoFiles() ([]BatchResult, error) {
 		utils.Warn("保存处理记录失败: %v", err)
 	}
 
-	return allResults, nil
+	p.recordBatch(allResults, batchStart)
+
+	// 汇总本批次的关键词，建立可检索的资料库
+	p.updateAnalysisIndex(allResults)
+
+	// 生成本批次的清单，供下游程序读取处理结果
+	if p.config != nil && p.config.BatchManifest {
+		if jsonPath, err := WriteBatchManifest(p.OutputDir, allResults, batchStart); err != nil {
+			utils.Warn("生成批次清单失败: %v", err)
+		} else {
+			utils.Info("批次清单已保存: %s", jsonPath)
+		}
+	}
+
+	return allResults
 }
 
-// ProcessSingleFile 处理单个文件
+// acquireWorker 获取一个处理槽位，批处理和监听模式触发的处理共享同一个并发上限
+func (p *BatchProcessor) acquireWorker() {
+	p.workerPool().acquire()
+}
+
+// releaseWorker 释放处理槽位
+func (p *BatchProcessor) releaseWorker() {
+	p.workerPool().release()
+}
+
+// ProcessSingleFile 处理单个文件，在处理槽位空闲前会阻塞等待
 func (p *BatchProcessor) ProcessSingleFile(filePath string) BatchResult {
+	p.acquireWorker()
 	result := p.processSingleFile(filePath)
+	p.releaseWorker()
 
 	// 更新处理记录
 	p.updateProcessedRecord(filePath, &result)
+	p.updateAnalysisIndex([]BatchResult{result})
 
 	return result
 }
@@ -277,6 +494,11 @@ func (p *BatchProcessor) IsRecognizedFile(filePath string) bool {
 		return true
 	}
 
+	// 使用命名模板时文稿不一定位于 <文件名>.txt，检查输出清单
+	if _, err := os.Stat(export.ManifestPath(p.OutputDir, filePath)); err == nil {
+		return true
+	}
+
 	// 方法2: 检查part目录
 	partDir := filepath.Join(p.OutputDir, baseName)
 	if _, err := os.Stat(partDir); err == nil {
@@ -293,15 +515,21 @@ func (p *BatchProcessor) IsRecognizedFile(filePath string) bool {
 		}
 	}
 
-	// 方法3: 检查处理记录
+	p.recordsMu.RLock()
+	defer p.recordsMu.RUnlock()
+
+	// 方法3: 检查处理记录，中断的分段识别需要继续处理
 	normalizedPath := filepath.Clean(filePath)
-	if _, exists := p.processedRecords[normalizedPath]; exists {
-		return true
+	if record, exists := p.processedRecords[normalizedPath]; exists {
+		return !record.interrupted()
 	}
 
 	// 方法4: 检查处理记录中是否有同名文件
 	fileBaseName := filepath.Base(filePath)
 	for recordPath, record := range p.processedRecords {
+		if record.interrupted() {
+			continue
+		}
 		if filepath.Base(recordPath) == fileBaseName || record.Filename == fileBaseName {
 			return true
 		}
@@ -314,6 +542,15 @@ func (p *BatchProcessor) IsRecognizedFile(filePath string) bool {
 func (p *BatchProcessor) updateProcessedRecord(filePath string, result *BatchResult) {
 	normalizedPath := filepath.Clean(filePath)
 
+	// 记录内容哈希，之后出现的副本不再重新识别
+	hash := result.contentHash
+	if result.Success && hash == "" {
+		if computed, err := FileHash(filePath); err == nil {
+			hash = computed
+		}
+	}
+
+	p.recordsMu.Lock()
 	// 获取或创建记录
 	record, exists := p.processedRecords[normalizedPath]
 	if !exists {
@@ -325,13 +562,24 @@ func (p *BatchProcessor) updateProcessedRecord(filePath string, result *BatchRes
 	// 更新记录
 	record.LastProcessedTime = time.Now().Format("2006-01-02 15:04:05")
 	record.Completed = result.Success
+	record.Status = result.Status
+
+	// 成功后不再需要分段进度
+	if result.Success {
+		record.TotalParts = 0
+		record.Parts = nil
+		record.Service = result.Service
+		record.ContentHash = hash
+		record.DuplicateOf = result.duplicateOf
+	}
 
 	if result.Success && result.OutputPath != "" {
 		// 可以添加更多信息，如处理时长等
 	}
 
 	// 保存回记录表
-	p.processedRecords[normalizedPath] = record
+	p.setRecord(normalizedPath, record)
+	p.recordsMu.Unlock()
 
 	// 保存到文件
 	if err := p.saveProcessedRecords(); err != nil {
@@ -345,15 +593,19 @@ func (p *BatchProcessor) UpdateProcessedRecordOnRename(oldPath, newPath string)
 	newNormalized := filepath.Clean(newPath)
 
 	// 检查旧路径是否在记录中
-	if record, exists := p.processedRecords[oldNormalized]; exists {
+	p.recordsMu.Lock()
+	record, exists := p.processedRecords[oldNormalized]
+	if exists {
 		// 删除旧记录，添加新记录
-		delete(p.processedRecords, oldNormalized)
-		p.processedRecords[newNormalized] = record
+		p.deleteRecord(oldNormalized)
 
 		// 更新文件名
 		record.Filename = filepath.Base(newPath)
-		p.processedRecords[newNormalized] = record
+		p.setRecord(newNormalized, record)
+	}
+	p.recordsMu.Unlock()
 
+	if exists {
 		// 保存更新后的记录
 		if err := p.saveProcessedRecords(); err != nil {
 			utils.Warn("保存处理记录失败: %v", err)
@@ -363,146 +615,38 @@ func (p *BatchProcessor) UpdateProcessedRecordOnRename(oldPath, newPath string)
 	}
 }
 
-// 处理单个文件 - 主控制流程
+// 处理单个文件 - 按处理流水线依次执行各阶段
 func (p *BatchProcessor) processSingleFile(filePath string) BatchResult {
-	// 第一步：提取音频
-	result := p.extractAudioFromFile(filePath)
-
-	// 如果音频提取成功且需要执行ASR处理
-	if result.Success  {
-		p.PerformASROnAudio(&result)
-	}
-
+	result := BatchResult{FilePath: filePath}
+	p.pipeline().Run(p.newFileJob(&result))
+	result.LogPath = result.log.Finish(&result)
+	p.reportFileDone(&result)
 	return result
 }
 
-// performASROnAudio 对提取的音频执行ASR处理并返回识别结果
+// PerformASROnAudio 对提取的音频执行流水线中提取之后的阶段（识别、导出与清理），返回识别结果
 func (p *BatchProcessor) PerformASROnAudio(result *BatchResult) ([]models.DataSegment, map[string]string, error) {
     if result == nil || !result.Success || result.OutputPath == "" {
         return nil, nil, fmt.Errorf("无效的处理结果或音频路径")
     }
 
-    audioPath := result.OutputPath
-    filename := filepath.Base(result.FilePath)
-    fileID := filename[:len(filename)-len(filepath.Ext(filename))]
-
-    // 更新进度条
-    if p.ProgressManager != nil {
-        p.ProgressManager.UpdateProgressBar("file_"+fileID, 85, "执行语音识别...")
-    }
-
-    utils.Info("开始对文件进行语音识别: %s (路径: %s)", filepath.Base(audioPath), audioPath)
-
-    // 检查文件是否存在
-    fileInfo, err := os.Stat(audioPath)
-    if os.IsNotExist(err) {
-        utils.Error("音频文件不存在: %s", audioPath)
-        if p.ProgressManager != nil {
-            p.ProgressManager.CompleteProgressBar("file_"+fileID, "失败：文件不存在")
-        }
-        return nil, nil, fmt.Errorf("音频文件不存在: %s", audioPath)
-    }
-    
-    // 检查文件大小
-    if fileInfo.Size() == 0 {
-        utils.Error("音频文件大小为0: %s", audioPath)
-        if p.ProgressManager != nil {
-            p.ProgressManager.CompleteProgressBar("file_"+fileID, "失败：文件大小为0")
-        }
-        return nil, nil, fmt.Errorf("音频文件大小为0: %s", audioPath)
-    }
-
-    // 创建进度条ID
-    barID := "asr_" + filepath.Base(audioPath)
-    if p.ProgressManager != nil {
-        p.ProgressManager.CreateProgressBar(barID, 100, "ASR识别 "+filepath.Base(audioPath), "准备中...")
-    }
-
-    // 进度回调
-    progressCallback := func(percent int, message string) {
-        if p.ProgressManager != nil {
-            p.ProgressManager.UpdateProgressBar(barID, percent, message)
-        }
-        utils.Debug("ASR进度 [%d%%]: %s", percent, message)
-    }
-
-    // 创建上下文，带有超时控制
-    ctx, cancel := context.WithTimeout(p.ctx, 150*time.Minute) // 增加超时时间
-    defer cancel()
-
-    // 执行ASR识别，添加重试机制
-    utils.Info("使用ASR服务: %s", p.config.ASRService)
-    segments, serviceName, outputFiles, err := p.ASRSelector.RunWithService(
-        ctx,
-        audioPath,
-        p.config.ASRService,
-        false,
-        p.config,
-        progressCallback,
-    )
-    
-    if err != nil {
-        // 更多详细的错误信息
-        utils.Error("ASR识别失败: %v (文件: %s, 服务: %s)", err, audioPath, serviceName)
-        if p.ProgressManager != nil {
-            p.ProgressManager.CompleteProgressBar(barID, "识别失败: "+err.Error())
-        }
-        
-        // 即使识别失败，我们也标记文件为已处理，避免反复处理
-        result.Success = false
-        result.Error = err
+    job := p.newFileJob(result)
+    if err := p.pipeline().RunAfter(StageExtract, job); err != nil {
         return nil, nil, err
     }
-
-    // 检查识别结果
-    if len(segments) == 0 {
-        utils.Warn("ASR识别未返回任何文本段落，可能是音频中没有语音内容或识别失败")
-        if p.ProgressManager != nil {
-            p.ProgressManager.CompleteProgressBar(barID, "识别完成但未找到文本")
-        }
-    } else {
-        if p.ProgressManager != nil {
-            p.ProgressManager.CompleteProgressBar(barID, "识别成功，共"+fmt.Sprintf("%d", len(segments))+"段文本")
-        }
-    }
-
-    // 输出结果信息
-    if len(outputFiles) > 0 {
-        utils.Info("生成的字幕文件:")
-        for fileType, filePath := range outputFiles {
-            utils.Info("- %s: %s", fileType, filepath.Base(filePath))
-        }
-    } else {
-        utils.Warn("未生成任何输出文件")
-    }
-
-    utils.Info("文件 %s 识别完成，共 %d 段文本", filepath.Base(audioPath), len(segments))
-
-    // 完成文件进度条
-    if p.ProgressManager != nil {
-        p.ProgressManager.CompleteProgressBar("file_"+fileID, "处理完成")
-    }
-    
-    // 清理临时文件
-    if result.Success && strings.ToLower(filepath.Ext(audioPath)) == ".mp3" {
-        utils.Info("识别完成，删除提取的MP3文件: %s", audioPath)
-        if err := os.Remove(audioPath); err != nil {
-            utils.Warn("无法删除MP3文件: %v", err)
-        } 
-    }
-    
-    return segments, outputFiles, nil
+    return job.Segments, job.OutputFiles, nil
 }
 
 // extractAudioFromFile 从文件中提取音频
-func (p *BatchProcessor) extractAudioFromFile(filePath string) BatchResult {
+func (p *BatchProcessor) extractAudioFromFile(ctx context.Context, filePath string) BatchResult {
 	result := BatchResult{
 		FilePath: filePath,
 		Success:  false,
 	}
 
 	filename := filepath.Base(filePath)
-	fileID := filename[:len(filename)-len(filepath.Ext(filename))]
+	fileID := FileTag(filePath)
+	result.log = p.openFileLog(filePath)
 
 	// 创建文件进度条
 	if p.ProgressManager != nil {
@@ -518,67 +662,103 @@ func (p *BatchProcessor) extractAudioFromFile(filePath string) BatchResult {
 	// 设置提取器的回调
 	p.Extractor.ProgressCallback = segmentCallback
 
-	// 检查文件类型
-	ext := filepath.Ext(filePath)
-	lowerExt := strings.ToLower(ext)
-	isVideo := false
-	for _, videoExt := range p.VideoExtensions {
-		// lowercase扩展名以进行比较
-		lowerVideoExt := strings.ToLower(videoExt)
-    
-	    if lowerVideoExt == lowerExt {
-			isVideo = true
-			break
-		}
-	}
-
+	ext := mediaExt(filePath)
+	selection := p.mediaSelection(filePath)
 	var audioPath string
 	var err error
 
-	// 根据文件类型处理
-	if isVideo {
-		// 从视频提取音频
+	// 根据文件类型处理：视频、HLS、需要转码或截取时间范围的音频先提取为 MP3，其余音频直接识别
+	switch {
+	case IsHLS(filePath) || p.isVideoFile(filePath) || (p.isAudioFile(filePath) && (needsTranscode(filePath) || selection.Clipped())):
+		source := filePath
+		if IsHLS(filePath) {
+			if p.ProgressManager != nil {
+				p.ProgressManager.UpdateProgressBar("file_"+fileID, 10, "下载 HLS 分片中")
+			}
+			hlsDir, err := os.MkdirTemp(p.TempDir, "hls-")
+			if err != nil {
+				result.setError(fmt.Errorf("创建临时目录失败: %w", err), ErrorKindExtract)
+				return result
+			}
+			defer os.RemoveAll(hlsDir)
+
+			source, err = p.Extractor.DownloadHLS(ctx, filePath, hlsDir)
+			if err != nil {
+				if p.ProgressManager != nil {
+					p.ProgressManager.CompleteProgressBar("file_"+fileID, fmt.Sprintf("失败: %v", err))
+				}
+				result.setError(err, ErrorKindExtract)
+				result.log.Printf("%v", result.Error)
+				result.log.printFFmpegOutput(err)
+				return result
+			}
+		}
+
+		// 提取音频（需要转码的音频同样转为 MP3）
 		if p.ProgressManager != nil {
 			p.ProgressManager.UpdateProgressBar("file_"+fileID, 20, "提取音频中")
 		}
 
-		audioPath, _, err = p.Extractor.ExtractAudioFromVideo(filePath, p.OutputDir)
+		// 文件进度条的 20%-80% 与文件处理进度的 5%-25% 对应提取进度
+		lastReported := -1
+		onProgress := func(progress ExtractProgress) {
+			if p.ProgressManager != nil {
+				p.ProgressManager.UpdateProgressBar("file_"+fileID, 20+int(progress.Percent*0.6), progress.String())
+			}
+			if percent := 5 + int(progress.Percent/5); percent != lastReported {
+				lastReported = percent
+				p.reportFileProgress(filePath, StageExtract, percent, "提取音频: "+progress.String())
+			}
+		}
+		audioPath, _, err = p.Extractor.ExtractAudioRange(ctx, source, p.OutputDir, selection, onProgress)
 		if err != nil {
 			if p.ProgressManager != nil {
 				p.ProgressManager.CompleteProgressBar("file_"+fileID, fmt.Sprintf("失败: %v", err))
 			}
 
-			result.Error = fmt.Errorf("从视频提取音频失败: %w", err)
+			result.setError(fmt.Errorf("提取音频失败: %w", err), ErrorKindExtract)
+			result.log.Printf("%v", result.Error)
+			result.log.printFFmpegOutput(err)
 			return result
 		}
 
 		if p.ProgressManager != nil {
 			p.ProgressManager.UpdateProgressBar("file_"+fileID, 80, "音频提取完成")
 		}
-	} else if ext == ".mp3" || ext == ".wav" || ext == ".m4a" {
+		result.clipOffset = selection.Start
+	case p.isAudioFile(filePath):
 		// 直接使用音频文件
 		audioPath = filePath
 
 		if p.ProgressManager != nil {
 			p.ProgressManager.UpdateProgressBar("file_"+fileID, 50, "处理音频文件")
 		}
-	} else {
+	default:
 		if p.ProgressManager != nil {
 			p.ProgressManager.CompleteProgressBar("file_"+fileID, fmt.Sprintf("不支持的格式: %s", ext))
 		}
 
-		result.Error = fmt.Errorf("不支持的文件格式: %s", ext)
+		result.setError(fmt.Errorf("不支持的文件格式: %s", ext), ErrorKindInput)
 		return result
 	}
 
 	// 输出路径
 	result.OutputPath = audioPath
 	result.Success = true
+	result.log.Printf("音频: %s", audioPath)
 
 	// 注意：不在这里完成进度条，因为可能还有ASR处理
 	return result
 }
 
+// mediaSelection 返回文件使用的音轨与时间范围，未设置配置时提取第一条音频流的全部内容
+func (p *BatchProcessor) mediaSelection(filePath string) models.MediaSelection {
+	if p.config == nil {
+		return models.MediaSelection{}
+	}
+	return p.config.MediaSelection(filePath)
+}
+
 // 扫描媒体目录
 func (p *BatchProcessor) scanMediaDirectory() ([]string, error) {
 	var files []string
@@ -597,23 +777,7 @@ func (p *BatchProcessor) scanMediaDirectory() ([]string, error) {
 		if entry.IsDir() {
 			continue
 		}
-		ext := filepath.Ext(entry.Name())
-		isSupported := false
-
-		// 检查是否为支持的视频格式
-		for _, videoExt := range p.VideoExtensions {
-			if ext == videoExt {
-				isSupported = true
-				break
-			}
-		}
-
-		// 添加音频格式
-		if ext == ".mp3" || ext == ".wav" {
-			isSupported = true
-		}
-
-		if isSupported {
+		if p.IsSupportedFile(entry.Name()) {
 			files = append(files, filepath.Join(p.MediaDir, entry.Name()))
 		}
 	}
@@ -630,6 +794,10 @@ type WebResult struct {
     Segments     []models.DataSegment `json:"segments,omitempty"`
     OutputFiles  map[string]string `json:"output_files,omitempty"`
     ProcessTime  time.Duration    `json:"process_time_ms"`
+    ErrorKind    string           `json:"error_kind,omitempty"` // 失败类型
+    LogFile      string           `json:"log_file,omitempty"`   // 失败时的处理日志文件名，可通过 LogHandler 下载
+    Status       string           `json:"status,omitempty"`     // 成功但未识别时的状态，如 "无音频"
+    Manifest     *export.OutputManifest `json:"manifest,omitempty"` // 输出清单，包含各输出文件的大小与校验和
 }
 
 // WebProcessor Web处理器
@@ -640,6 +808,10 @@ type WebProcessor struct {
     Processor   *BatchProcessor
     MaxFileSize int64 // 最大文件大小（字节）
     Config      *models.Config
+
+    // 启用认证时各用户独立的处理器，见 ForUser
+    users   map[string]*WebProcessor
+    usersMu sync.Mutex
 }
 
 // NewWebProcessor 创建Web处理器
@@ -649,10 +821,11 @@ func NewWebProcessor(uploadDir, tempDir, outputDir string, config *models.Config
     os.MkdirAll(tempDir, 0755)
     os.MkdirAll(outputDir, 0755)
 
-    // 创建批处理器
+    // 创建批处理器，不绘制终端进度条，处理进度通过订阅发送给Web接口
     processor := NewBatchProcessor("", outputDir, tempDir, nil, config)
+    processor.SetProgressManager(ui.NewHeadlessProgressManager())
 
-    return &WebProcessor{
+    web := &WebProcessor{
         UploadDir:   uploadDir,
         TempDir:     tempDir,
         OutputDir:   outputDir,
@@ -660,150 +833,163 @@ func NewWebProcessor(uploadDir, tempDir, outputDir string, config *models.Config
         MaxFileSize: 1024 * 1024 * 512, // 默认512MB
         Config:      config,
     }
+    if config != nil && config.WebMaxUploadMB > 0 {
+        web.MaxFileSize = int64(config.WebMaxUploadMB) << 20
+    }
+    return web
 }
 
-// ProcessUploadedFile 处理上传的文件
+// ProcessUploadedFile 保存上传的文件并同步处理，处理完成后才返回
 func (w *WebProcessor) ProcessUploadedFile(file io.Reader, filename string) (*WebResult, error) {
     startTime := time.Now()
-    
-    // 生成唯一的文件名
-    uniqueID := uuid.New().String()
-    fileExt := filepath.Ext(filename)
-    uniqueFilename := fmt.Sprintf("%s%s", uniqueID, fileExt)
-    
-    // 创建文件保存路径
-    filePath := filepath.Join(w.UploadDir, uniqueFilename)
-    
-    // 创建临时文件
-    tempFile, err := os.Create(filePath)
+    filePath, err := w.SaveUpload(file, filename)
     if err != nil {
         return &WebResult{
             Success:      false,
-            ErrorMessage: fmt.Sprintf("创建文件失败: %v", err),
+            ErrorMessage: err.Error(),
             ProcessTime:  time.Since(startTime),
         }, err
     }
+    result, err := w.ProcessFile(filePath)
+    result.ProcessTime = time.Since(startTime)
+    return result, err
+}
+
+// SaveUpload 以唯一文件名将上传的文件保存到上传目录，返回保存的路径。不支持的格式不保存
+func (w *WebProcessor) SaveUpload(file io.Reader, filename string) (string, error) {
+    // 检查文件类型
+    ext := strings.ToLower(filepath.Ext(filename))
+    if !w.Processor.IsSupportedFile(filename) {
+        return "", fmt.Errorf("不支持的文件格式: %s", ext)
+    }
+
+    // 生成唯一的文件名
+    uniqueFilename := uuid.New().String() + ext
+    filePath := filepath.Join(w.UploadDir, uniqueFilename)
+
+    tempFile, err := os.Create(filePath)
+    if err != nil {
+        return "", fmt.Errorf("创建文件失败: %w", err)
+    }
     defer tempFile.Close()
-    
+
     // 写入文件内容
-    _, err = io.Copy(tempFile, file)
-    if err != nil {
+    if _, err := io.Copy(tempFile, file); err != nil {
+        tempFile.Close()
         os.Remove(filePath) // 清理临时文件
-        return &WebResult{
-            Success:      false,
-            ErrorMessage: fmt.Sprintf("保存文件失败: %v", err),
-            ProcessTime:  time.Since(startTime),
-        }, err
+        return "", fmt.Errorf("保存文件失败: %w", err)
     }
-    
-    // 关闭文件以确保内容已完全写入
-    tempFile.Close()
-    
-    // 检查文件类型
-    ext := strings.ToLower(filepath.Ext(filename))
-    isSupported := false
-    
-    // 检查视频格式
-    for _, videoExt := range w.Processor.VideoExtensions {
-        if strings.ToLower(videoExt) == ext {
-            isSupported = true
-            break
-        }
-    }
-    
-    // 检查音频格式
-    if ext == ".mp3" || ext == ".wav" || ext == ".m4a" {
-        isSupported = true
+    if err := tempFile.Close(); err != nil {
+        os.Remove(filePath)
+        return "", fmt.Errorf("保存文件失败: %w", err)
     }
+    return filePath, nil
+}
+
+// ProcessFile 处理已保存在上传目录中的文件，处理结束后删除该文件
+func (w *WebProcessor) ProcessFile(filePath string) (*WebResult, error) {
+    startTime := time.Now()
+
+    // 使用处理器的上下文，多个文件可能同时处理，不在这里修改
+    ctx := w.Processor.baseContext()
     
-    if !isSupported {
-        os.Remove(filePath) // 清理临时文件
+    // 不包含音频或近乎静音的文件无需识别
+    if reason := w.Processor.detectNoAudio(ctx, filePath); reason != "" {
+        w.Processor.Trash.Remove(filePath, "web", "文件无音频，清理上传文件")
+        result := w.Processor.noAudioResult(filePath, reason)
         return &WebResult{
-            Success:      false,
-            ErrorMessage: fmt.Sprintf("不支持的文件格式: %s", ext),
-            ProcessTime:  time.Since(startTime),
-        }, fmt.Errorf("不支持的文件格式: %s", ext)
+            Success:     true,
+            Status:      result.Status,
+            ProcessTime: time.Since(startTime),
+        }, nil
     }
     
-    // 设置上下文
-    ctx := context.Background()
-    w.Processor.SetContext(ctx)
-    
     // 第一步：提取音频
-    result := w.Processor.extractAudioFromFile(filePath)
+    result := w.Processor.extractAudioFromFile(ctx, filePath)
     
     if !result.Success {
-        os.Remove(filePath) // 清理上传的文件
+        // 服务停止导致的中断保留上传的文件，任务在下次启动时重新处理
+        if ctx.Err() == nil {
+            w.Processor.Trash.Remove(filePath, "web", "音频提取失败，清理上传文件") // 清理上传的文件
+        }
         return &WebResult{
             Success:      false,
             ErrorMessage: fmt.Sprintf("提取音频失败: %v", result.Error),
             ProcessTime:  time.Since(startTime),
+            ErrorKind:    result.ErrorKind,
+            LogFile:      logFileName(result.log.Finish(&result)),
         }, result.Error
     }
     
+    // 以音乐为主的音频按配置跳过识别
+    if w.Processor.checkMusic(ctx, &result) {
+        result.log.Finish(&result)
+        w.Processor.Trash.Remove(filePath, "web", "音乐文件跳过识别，清理上传文件")
+        return &WebResult{
+            Success:     true,
+            Status:      result.Status,
+            ProcessTime: time.Since(startTime),
+        }, nil
+    }
+    
     // 第二步：执行ASR识别
     segments, outputFiles, err := w.Processor.PerformASROnAudio(&result)
+    logPath := result.log.Finish(&result)
     
-    // 清理临时文件
-    os.Remove(filePath) // 删除上传的原始文件
+    // 清理临时文件，中断时保留（已识别的分段记录在检查点中，重新处理时跳过）
+    if err == nil || ctx.Err() == nil {
+        w.Processor.Trash.Remove(filePath, "web", "识别完成，清理上传文件") // 删除上传的原始文件
+    }
     
     if err != nil {
         return &WebResult{
             Success:      false,
             ErrorMessage: fmt.Sprintf("语音识别失败: %v", err),
             ProcessTime:  time.Since(startTime),
+            ErrorKind:    result.ErrorKind,
+            LogFile:      logFileName(logPath),
         }, err
     }
     
+    w.Processor.updateAnalysisIndex([]BatchResult{result})
+
     // 返回结果
-    return &WebResult{
+    webResult := &WebResult{
         Success:     true,
         Segments:    segments,
         OutputFiles: outputFiles,
         ProcessTime: time.Since(startTime),
-    }, nil
-}
-
-// CleanupOldFiles 清理旧文件
-func (w *WebProcessor) CleanupOldFiles(maxAge time.Duration) error {
-    // 清理上传目录
-    if err := cleanupDir(w.UploadDir, maxAge); err != nil {
-        return err
     }
-    
-    // 清理临时目录
-    if err := cleanupDir(w.TempDir, maxAge); err != nil {
-        return err
+    if manifestPath := outputFiles["manifest"]; manifestPath != "" {
+        if manifest, err := export.LoadManifest(manifestPath); err == nil {
+            webResult.Manifest = manifest
+        }
     }
-    
-    return nil
+    return webResult, nil
 }
 
-// cleanupDir 清理指定目录中超过最大存活时间的文件
-func cleanupDir(dir string, maxAge time.Duration) error {
-    entries, err := os.ReadDir(dir)
+// CleanupOldFiles 按保留策略（配置 retention）清理上传、临时、输出目录与识别结果缓存，
+// 包括各用户的目录，然后清理回收目录中过期的文件
+func (w *WebProcessor) CleanupOldFiles() error {
+    cleaner := &retention.Cleaner{Trash: w.Processor.Trash}
+    reports, err := cleaner.Run(w.Config, retention.Dirs{
+        Uploads: w.UploadDir,
+        Temp:    w.TempDir,
+        Outputs: w.OutputDir,
+        Cache:   asr.CacheDir(w.Config),
+    })
+    for _, report := range reports {
+        if report.Removed > 0 {
+            utils.Info("已清理 %s 中的 %d 项，释放 %.1f MB", report.Dir, report.Removed, float64(report.Freed)/(1024*1024))
+        }
+    }
     if err != nil {
         return err
     }
     
-    now := time.Now()
-    
-    for _, entry := range entries {
-        if entry.IsDir() {
-            continue
-        }
-        
-        info, err := entry.Info()
-        if err != nil {
-            continue
-        }
-        
-        // 检查文件是否过期
-        if now.Sub(info.ModTime()) > maxAge {
-            filePath := filepath.Join(dir, entry.Name())
-            os.Remove(filePath)
-            utils.Info("已清理过期文件: %s", filePath)
-        }
+    // 清理回收目录中过期的文件
+    if _, err := w.Processor.Trash.Purge(); err != nil {
+        utils.Warn("清理回收目录失败: %v", err)
     }
     
     return nil
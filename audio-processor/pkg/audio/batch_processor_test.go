@@ -68,7 +68,8 @@ func TestScanMediaDirectory(t *testing.T) {
 	assert.NoError(t, err)
 	
 	// 创建批处理器
-	processor := NewBatchProcessor(mediaDir, "output", "temp", nil, config)
+	workDir := t.TempDir()
+	processor := NewBatchProcessor(mediaDir, filepath.Join(workDir, "output"), filepath.Join(workDir, "temp"), nil, config)
 	
 	// 扫描目录
 	files, err := processor.scanMediaDirectory()
@@ -0,0 +1,270 @@
+package audio
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/asr"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/models"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/tempdir"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/utils"
+)
+
+// chunkResult 表示单个音频分段的识别结果
+type chunkResult struct {
+	Index    int
+	Offset   float64
+	Segments []models.DataSegment
+	Service  string
+	Err      error
+}
+
+// shouldChunk 判断音频是否需要分段识别
+func (p *BatchProcessor) shouldChunk(duration int) bool {
+	if p.config == nil || p.config.MaxPartTime <= 0 {
+		return false
+	}
+	return duration > p.config.MaxPartTime*60
+}
+
+// runChunkedASR 将长音频切分为 SegmentLength 长度的片段，分别识别后合并结果。
+// 每个分段完成后立即保存结果，进程中断后重新处理 sourcePath 时只识别剩余的分段。
+func (p *BatchProcessor) runChunkedASR(ctx context.Context, sourcePath, audioPath string, duration int, service string, job *tempdir.Job, callback asr.ProgressCallback) ([]models.DataSegment, string, error) {
+	filename := filepath.Base(audioPath)
+	utils.Info("音频 %s 时长 %s，超过 %d 分钟，将分段识别",
+		filename, utils.FormatTimeDuration(float64(duration)), p.config.MaxPartTime)
+
+	if callback != nil {
+		callback(5, "切分音频...")
+	}
+
+	// 切分出的片段需要的空间与音频时长成正比，空间不足时等待
+	if err := p.waitForDiskSpace(ctx, sourcePath, uint64(duration)*segmentBytesPerSecond); err != nil {
+		return nil, "", err
+	}
+
+	// 片段写入本文件独占的临时目录，避免同名文件并发处理时互相覆盖
+	segmentsDir, err := job.MkdirAll("segments")
+	if err != nil {
+		return nil, "", err
+	}
+	chunks, err := p.Extractor.SplitAudioFileTo(ctx, audioPath, p.config.SegmentLength, segmentsDir)
+	if err != nil {
+		return nil, "", fmt.Errorf("切分音频失败: %w", err)
+	}
+	if len(chunks) == 0 {
+		return nil, "", fmt.Errorf("切分音频未产生任何片段")
+	}
+
+	// 片段识别成功后立即删除，其余的在结束时（含失败）删除
+	defer func() {
+		for _, chunk := range chunks {
+			removeChunk(chunk)
+		}
+	}()
+
+	concurrency := p.config.ChunkConcurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	results := make([]chunkResult, len(chunks))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	completed := 0
+
+	// 恢复之前中断时已完成的分段
+	resumed := p.loadCompletedChunks(sourcePath, chunks)
+	if len(resumed) > 0 {
+		utils.Info("恢复 %s 之前已识别的 %d/%d 个分段", filepath.Base(sourcePath), len(resumed), len(chunks))
+	}
+
+	for i, chunk := range chunks {
+		if result, ok := resumed[chunk.Index]; ok {
+			results[i] = result
+			completed++
+			removeChunk(chunk)
+			continue
+		}
+
+		wg.Add(1)
+		sem <- struct{}{}
+
+		go func(i int, chunk AudioSegment) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			// 自动模式下每个分段单独选择服务，从而分摊到多个服务上
+			segments, service, err := p.ASRSelector.Recognize(ctx, chunk.OutputPath, service, p.config.ASRCache, nil)
+			results[i] = chunkResult{
+				Index:    chunk.Index,
+				Offset:   chunk.StartTime,
+				Segments: segments,
+				Service:  service,
+				Err:      err,
+			}
+			if err == nil {
+				p.saveCompletedChunk(sourcePath, len(chunks), chunk, results[i])
+				removeChunk(chunk)
+			}
+
+			mu.Lock()
+			completed++
+			done := completed
+			mu.Unlock()
+
+			if callback != nil {
+				percent := 10 + done*85/len(chunks)
+				callback(percent, fmt.Sprintf("已识别 %d/%d 个分段", done, len(chunks)))
+			}
+		}(i, chunk)
+	}
+
+	wg.Wait()
+
+	// 任意分段失败则整体失败，避免输出缺段的文本
+	services := make(map[string]bool)
+	for _, result := range results {
+		if result.Err != nil {
+			return nil, result.Service, fmt.Errorf("第 %d 个分段识别失败: %w", result.Index+1, result.Err)
+		}
+		services[result.Service] = true
+	}
+
+	serviceNames := make([]string, 0, len(services))
+	for name := range services {
+		serviceNames = append(serviceNames, name)
+	}
+	sort.Strings(serviceNames)
+	serviceLabel := fmt.Sprint(serviceNames)
+	if len(serviceNames) == 1 {
+		serviceLabel = serviceNames[0]
+	}
+
+	return mergeChunkSegments(results), serviceLabel, nil
+}
+
+// removeChunk 删除已不再需要的临时片段
+func removeChunk(chunk AudioSegment) {
+	if err := os.Remove(chunk.OutputPath); err != nil && !os.IsNotExist(err) {
+		utils.Debug("删除临时片段失败: %v", err)
+	}
+}
+
+// mergeChunkSegments 合并各分段的识别结果，并把时间戳修正为相对原始音频的偏移
+func mergeChunkSegments(results []chunkResult) []models.DataSegment {
+	sorted := make([]chunkResult, len(results))
+	copy(sorted, results)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Index < sorted[j].Index
+	})
+
+	var merged []models.DataSegment
+	for _, result := range sorted {
+		for _, segment := range result.Segments {
+			merged = append(merged, segment.Shift(result.Offset))
+		}
+	}
+	return merged
+}
+
+// 分段识别中间结果的保存目录（位于输出目录下）
+const partialDirName = ".partial"
+
+// partialResult 单个分段已完成的识别结果
+type partialResult struct {
+	Service  string               `json:"service"`
+	Segments []models.DataSegment `json:"segments"`
+}
+
+// partialDir 返回源文件分段识别中间结果的保存目录
+func (p *BatchProcessor) partialDir(sourcePath string) string {
+	return filepath.Join(p.OutputDir, partialDirName, FileTag(sourcePath))
+}
+
+// partKey 返回分段在处理记录中的键
+func partKey(index int) string {
+	return fmt.Sprintf("part_%03d", index+1)
+}
+
+// saveCompletedChunk 保存单个分段的识别结果，并在处理记录中标记该分段已完成
+func (p *BatchProcessor) saveCompletedChunk(sourcePath string, total int, chunk AudioSegment, result chunkResult) {
+	path := filepath.Join(p.partialDir(sourcePath), partKey(chunk.Index)+".json")
+	if err := utils.SaveJSONFile(path, partialResult{Service: result.Service, Segments: result.Segments}); err != nil {
+		utils.Warn("保存分段识别结果失败: %v", err)
+		return
+	}
+
+	key := filepath.Clean(sourcePath)
+	p.recordsMu.Lock()
+	record, exists := p.processedRecords[key]
+	if !exists {
+		record = ProcessedRecord{Filename: filepath.Base(sourcePath)}
+	}
+	if record.TotalParts != total || record.Parts == nil {
+		record.TotalParts = total
+		record.Parts = make(map[string]Part)
+	}
+	record.Parts[partKey(chunk.Index)] = Part{
+		Completed:     true,
+		OutputFile:    path,
+		CompletedTime: time.Now().Format("2006-01-02 15:04:05"),
+		StartTime:     chunk.StartTime,
+		EndTime:       chunk.EndTime,
+		Service:       result.Service,
+	}
+	p.setRecord(key, record)
+	p.recordsMu.Unlock()
+
+	if err := p.saveProcessedRecords(); err != nil {
+		utils.Warn("%v", err)
+	}
+}
+
+// loadCompletedChunks 读取之前已完成的分段结果。分段数量或时间范围与本次切分不一致时不使用
+func (p *BatchProcessor) loadCompletedChunks(sourcePath string, chunks []AudioSegment) map[int]chunkResult {
+	p.recordsMu.RLock()
+	record, exists := p.processedRecords[filepath.Clean(sourcePath)]
+	p.recordsMu.RUnlock()
+	if !exists || record.TotalParts != len(chunks) || len(record.Parts) == 0 {
+		return nil
+	}
+
+	resumed := make(map[int]chunkResult)
+	for _, chunk := range chunks {
+		part, ok := record.Parts[partKey(chunk.Index)]
+		if !ok || !part.Completed || part.StartTime != chunk.StartTime || part.EndTime != chunk.EndTime {
+			continue
+		}
+		data, err := os.ReadFile(part.OutputFile)
+		if err != nil {
+			continue
+		}
+		var saved partialResult
+		if err := json.Unmarshal(data, &saved); err != nil {
+			utils.Warn("分段识别结果已损坏，将重新识别: %v", err)
+			continue
+		}
+		resumed[chunk.Index] = chunkResult{
+			Index:    chunk.Index,
+			Offset:   chunk.StartTime,
+			Segments: saved.Segments,
+			Service:  saved.Service,
+		}
+	}
+	return resumed
+}
+
+// clearPartialResults 删除源文件的分段中间结果
+func (p *BatchProcessor) clearPartialResults(sourcePath string) {
+	if err := os.RemoveAll(p.partialDir(sourcePath)); err != nil {
+		utils.Debug("删除分段中间结果失败: %v", err)
+	}
+}
@@ -0,0 +1,107 @@
+package audio
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestMergeChunkSegments 测试分段结果按顺序合并并修正时间偏移
+func TestMergeChunkSegments(t *testing.T) {
+	results := []chunkResult{
+		{
+			Index:  1,
+			Offset: 30,
+			Segments: []models.DataSegment{
+				{Text: "第二段", StartTime: 1, EndTime: 4},
+			},
+		},
+		{
+			Index:  0,
+			Offset: 0,
+			Segments: []models.DataSegment{
+				{Text: "第一段", StartTime: 0.5, EndTime: 3},
+				{Text: "第一段续", StartTime: 3, EndTime: 29},
+			},
+		},
+	}
+
+	merged := mergeChunkSegments(results)
+
+	assert.Equal(t, 3, len(merged))
+	assert.Equal(t, "第一段", merged[0].Text)
+	assert.Equal(t, "第一段续", merged[1].Text)
+	assert.Equal(t, "第二段", merged[2].Text)
+	assert.Equal(t, 31.0, merged[2].StartTime)
+	assert.Equal(t, 34.0, merged[2].EndTime)
+
+	// 原始结果不应被修改
+	assert.Equal(t, 1.0, results[0].Segments[0].StartTime)
+}
+
+// TestShouldChunk 测试分段阈值判断
+func TestShouldChunk(t *testing.T) {
+	config := models.NewDefaultConfig()
+	config.MaxPartTime = 20
+	processor := &BatchProcessor{config: config}
+
+	assert.False(t, processor.shouldChunk(20*60))
+	assert.True(t, processor.shouldChunk(20*60+1))
+
+	// MaxPartTime 为 0 时不分段
+	config.MaxPartTime = 0
+	assert.False(t, processor.shouldChunk(3*3600))
+}
+
+// TestResumeCompletedChunks 测试中断后从已完成的分段继续
+func TestResumeCompletedChunks(t *testing.T) {
+	outputDir := t.TempDir()
+	processor := &BatchProcessor{
+		OutputDir:           outputDir,
+		processedRecordFile: filepath.Join(outputDir, "processed_records.json"),
+		processedRecords:    make(map[string]ProcessedRecord),
+	}
+	source := filepath.Join("media", "lecture.mp4")
+	chunks := []AudioSegment{
+		{Index: 0, StartTime: 0, EndTime: 30},
+		{Index: 1, StartTime: 30, EndTime: 60},
+		{Index: 2, StartTime: 60, EndTime: 75},
+	}
+
+	processor.saveCompletedChunk(source, len(chunks), chunks[1], chunkResult{
+		Index:    1,
+		Service:  "bcut",
+		Segments: []models.DataSegment{{Text: "第二段", StartTime: 1, EndTime: 4}},
+	})
+
+	// 未完成的文件需要继续处理
+	assert.False(t, processor.IsRecognizedFile(source))
+
+	resumed := processor.loadCompletedChunks(source, chunks)
+	assert.Len(t, resumed, 1)
+	assert.Equal(t, "第二段", resumed[1].Segments[0].Text)
+	assert.Equal(t, 30.0, resumed[1].Offset)
+	assert.Equal(t, "bcut", resumed[1].Service)
+
+	// 切分方式改变后不再使用之前的结果
+	assert.Empty(t, processor.loadCompletedChunks(source, chunks[:2]))
+
+	// 处理成功后清除分段进度
+	processor.updateProcessedRecord(source, &BatchResult{FilePath: source, Success: true})
+	assert.True(t, processor.IsRecognizedFile(source))
+	assert.Empty(t, processor.loadCompletedChunks(source, chunks))
+}
+
+// TestEstimateRequests 测试按分段阈值估算识别请求数
+func TestEstimateRequests(t *testing.T) {
+	config := models.NewDefaultConfig()
+	config.MaxPartTime = 20
+	config.SegmentLength = 300
+	processor := &BatchProcessor{config: config}
+
+	assert.Equal(t, 1, processor.estimateRequests(20*60))
+	assert.Equal(t, 5, processor.estimateRequests(20*60+1))
+	assert.Equal(t, 6, processor.estimateRequests(30*60))
+}
@@ -0,0 +1,233 @@
+package audio
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cespare/xxhash/v2"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/export"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/trash"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/utils"
+)
+
+// StatusDuplicate 文件内容与已识别的文件相同、未重新识别时的结果状态
+const StatusDuplicate = "重复"
+
+// dedup 取值
+const (
+	DedupOff     = "off"     // 照常识别
+	DedupSkip    = "skip"    // 只记录为重复，不生成输出
+	DedupSymlink = "symlink" // 以符号链接指向已有输出
+	DedupCopy    = "copy"    // 复制已有输出
+)
+
+// 计算哈希时读取的块大小：不超过 3 块的文件读取全部内容，否则读取开头、中间与结尾各一块
+const hashChunkSize = 1 << 20
+
+// 与源文件绑定、不能在重复文件之间共用的输出
+var unsharedOutputs = map[string]bool{"media": true, "archive": true, "subbed": true}
+
+// FileHash 返回文件的快速哈希："<大小>-<xxhash>"。大文件只读取开头、中间与结尾各 1MB，
+// 重新下载或改名的副本哈希相同
+func FileHash(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("读取文件失败: %w", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return "", fmt.Errorf("读取文件失败: %w", err)
+	}
+	size := info.Size()
+	digest := xxhash.New()
+	if size <= 3*hashChunkSize {
+		if _, err := io.Copy(digest, file); err != nil {
+			return "", fmt.Errorf("读取文件失败: %w", err)
+		}
+	} else {
+		for _, offset := range []int64{0, size/2 - hashChunkSize/2, size - hashChunkSize} {
+			if _, err := io.Copy(digest, io.NewSectionReader(file, offset, hashChunkSize)); err != nil {
+				return "", fmt.Errorf("读取文件失败: %w", err)
+			}
+		}
+	}
+	return fmt.Sprintf("%d-%016x", size, digest.Sum64()), nil
+}
+
+// dedupMode 返回配置的重复文件处理方式
+func (p *BatchProcessor) dedupMode() string {
+	if p.config == nil || p.config.Dedup == "" {
+		return DedupOff
+	}
+	return p.config.Dedup
+}
+
+// findDuplicate 计算文件的哈希，并查找内容相同且已识别完成的其他文件，没有时 original 为空。
+// 空文件不参与去重
+func (p *BatchProcessor) findDuplicate(filePath string) (hash, original string) {
+	info, err := os.Stat(filePath)
+	if err != nil || info.Size() == 0 {
+		return "", ""
+	}
+	hash, err = FileHash(filePath)
+	if err != nil {
+		utils.Debug("计算文件哈希失败: %v", err)
+		return "", ""
+	}
+
+	key := filepath.Clean(filePath)
+	p.recordsMu.RLock()
+	defer p.recordsMu.RUnlock()
+	for path, record := range p.processedRecords {
+		if path == key || record.ContentHash != hash || !record.Completed {
+			continue
+		}
+		if record.Status != "" && record.Status != StatusDuplicate {
+			continue
+		}
+		candidate := path
+		if record.DuplicateOf != "" {
+			candidate = record.DuplicateOf
+		}
+		// 有多个候选时取路径最小的，结果与遍历顺序无关
+		if candidate != key && (original == "" || candidate < original) {
+			original = candidate
+		}
+	}
+	return hash, original
+}
+
+// duplicateResult 返回重复文件的处理结果，按配置链接或复制已识别文件的输出
+func (p *BatchProcessor) duplicateResult(filePath, hash, original string) BatchResult {
+	utils.Info("%s 与已识别的 %s 内容相同，跳过识别", filePath, original)
+	result := BatchResult{
+		FilePath:    filePath,
+		Success:     true,
+		Status:      StatusDuplicate,
+		contentHash: hash,
+		duplicateOf: original,
+	}
+	if mode := p.dedupMode(); mode == DedupSymlink || mode == DedupCopy {
+		outputs, err := p.reuseOutputs(filePath, original, mode)
+		if err != nil {
+			utils.Warn("复用 %s 的输出失败: %v", filepath.Base(original), err)
+		} else {
+			result.OutputFiles = outputs
+			p.publishOutputs(p.baseContext(), p.config, filePath, nil)
+		}
+	}
+	p.reportFileProgress(filePath, StageProbe, 100, StatusDuplicate)
+	return result
+}
+
+// reuseOutputs 将 original 的输出以 filePath 的文件名链接或复制一份，并为 filePath 生成输出清单
+func (p *BatchProcessor) reuseOutputs(filePath, original, mode string) (map[string]string, error) {
+	manifest, err := export.LoadManifest(export.ManifestPath(p.config.OutputFolder, original))
+	if err != nil {
+		return nil, err
+	}
+	newName := strings.TrimSuffix(filepath.Base(filePath), filepath.Ext(filePath))
+
+	outputs := make(map[string]string)
+	for _, entry := range manifest.Outputs {
+		if unsharedOutputs[entry.Type] {
+			continue
+		}
+		src := entry.Resolve(p.config.OutputFolder, p.config.MediaFolder)
+		dst := renameOutput(src, manifest.Name, newName)
+		if dst != src {
+			if err := linkOutput(src, dst, mode, p.Trash); err != nil {
+				return nil, err
+			}
+		}
+		outputs[entry.Type] = dst
+	}
+	if _, err := export.WriteManifest(p.config, filePath, outputs); err != nil {
+		return nil, err
+	}
+	return outputs, nil
+}
+
+// renameOutput 将输出路径中以 oldName 命名的目录与文件名前缀替换为 newName
+func renameOutput(path, oldName, newName string) string {
+	dir, base := filepath.Split(path)
+	dir = filepath.Clean(dir)
+	if filepath.Base(dir) == oldName {
+		dir = filepath.Join(filepath.Dir(dir), newName)
+	}
+	if strings.HasPrefix(base, oldName) {
+		base = newName + strings.TrimPrefix(base, oldName)
+	}
+	return filepath.Join(dir, base)
+}
+
+// linkOutput 以符号链接或复制的方式在 dst 放置 src，已存在的 dst 按 replaceOutput 移除
+func linkOutput(src, dst, mode string, bin *trash.Trash) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return fmt.Errorf("创建输出目录失败: %w", err)
+	}
+	if err := replaceOutput(src, dst, bin); err != nil {
+		return err
+	}
+	if mode == DedupSymlink {
+		abs, err := filepath.Abs(src)
+		if err != nil {
+			return fmt.Errorf("链接输出文件失败: %w", err)
+		}
+		if err := os.Symlink(abs, dst); err != nil {
+			return fmt.Errorf("链接输出文件失败: %w", err)
+		}
+		return nil
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("读取输出文件失败: %w", err)
+	}
+	defer in.Close()
+	out, err := os.Create(dst)
+	if err != nil {
+		return fmt.Errorf("复制输出文件失败: %w", err)
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return fmt.Errorf("复制输出文件失败: %w", err)
+	}
+	if err := out.Close(); err != nil {
+		return fmt.Errorf("复制输出文件失败: %w", err)
+	}
+	return nil
+}
+
+// replaceOutput 移除 dst 处已存在的文件：指向 src 的链接与失效的链接直接删除，
+// 其余文件（如之前复制的输出或用户的文件）移入回收站并记录审计日志
+func replaceOutput(src, dst string, bin *trash.Trash) error {
+	info, err := os.Lstat(dst)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("替换输出文件失败: %w", err)
+	}
+
+	if info.Mode()&os.ModeSymlink != 0 {
+		target, _ := os.Readlink(dst)
+		abs, _ := filepath.Abs(src)
+		if _, statErr := os.Stat(dst); target == abs || os.IsNotExist(statErr) {
+			if err := os.Remove(dst); err != nil {
+				return fmt.Errorf("替换输出文件失败: %w", err)
+			}
+			return nil
+		}
+	}
+	if _, err := bin.Remove(dst, "dedup", "复用重复文件的输出，替换已存在的输出文件"); err != nil {
+		return fmt.Errorf("替换输出文件失败: %w", err)
+	}
+	return nil
+}
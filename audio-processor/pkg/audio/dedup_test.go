@@ -0,0 +1,154 @@
+package audio
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/export"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/models"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/trash"
+)
+
+// TestFileHash 测试内容相同的文件哈希相同，大文件只比较开头、中间与结尾
+func TestFileHash(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, data []byte) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, data, 0644))
+		return path
+	}
+
+	a, err := FileHash(write("a.mp4", []byte("same content")))
+	require.NoError(t, err)
+	b, err := FileHash(write("b.mp4", []byte("same content")))
+	require.NoError(t, err)
+	c, err := FileHash(write("c.mp4", []byte("other content")))
+	require.NoError(t, err)
+	assert.Equal(t, a, b)
+	assert.NotEqual(t, a, c)
+	assert.Regexp(t, `^12-[0-9a-f]{16}$`, a)
+
+	large := bytes.Repeat([]byte{1}, 4*hashChunkSize)
+	first, err := FileHash(write("large1.mp4", large))
+	require.NoError(t, err)
+	// 修改未被采样的区域，哈希不变
+	large[hashChunkSize+10] = 2
+	second, err := FileHash(write("large2.mp4", large))
+	require.NoError(t, err)
+	assert.Equal(t, first, second)
+	// 修改开头，哈希改变
+	large[0] = 2
+	third, err := FileHash(write("large3.mp4", large))
+	require.NoError(t, err)
+	assert.NotEqual(t, first, third)
+
+	_, err = FileHash(filepath.Join(dir, "missing.mp4"))
+	assert.Error(t, err)
+}
+
+// TestRenameOutput 测试按新文件名替换输出目录与文件名前缀
+func TestRenameOutput(t *testing.T) {
+	assert.Equal(t, filepath.Join("out", "new.txt"), renameOutput(filepath.Join("out", "old.txt"), "old", "new"))
+	assert.Equal(t, filepath.Join("out", "new", "player.html"), renameOutput(filepath.Join("out", "old", "player.html"), "old", "new"))
+	assert.Equal(t, filepath.Join("out", "new_analysis.json"), renameOutput(filepath.Join("out", "old_analysis.json"), "old", "new"))
+	assert.Equal(t, filepath.Join("out", "other.txt"), renameOutput(filepath.Join("out", "other.txt"), "old", "new"))
+}
+
+// TestDuplicateSkipsRecognition 测试内容相同的副本不再识别，并复制或链接原文件的输出
+func TestDuplicateSkipsRecognition(t *testing.T) {
+	for _, mode := range []string{DedupCopy, DedupSymlink, DedupSkip} {
+		t.Run(mode, func(t *testing.T) {
+			root := t.TempDir()
+			config := models.NewDefaultConfig()
+			config.MediaFolder = filepath.Join(root, "media")
+			config.OutputFolder = filepath.Join(root, "output")
+			config.SkipNoAudio = false
+			config.Dedup = mode
+			require.NoError(t, os.MkdirAll(config.MediaFolder, 0755))
+
+			original := filepath.Join(config.MediaFolder, "lecture.mp4")
+			copyPath := filepath.Join(config.MediaFolder, "lecture (1).mp4")
+			for _, path := range []string{original, copyPath} {
+				require.NoError(t, os.WriteFile(path, []byte("video bytes"), 0644))
+			}
+			txt := filepath.Join(config.OutputFolder, "lecture.txt")
+			require.NoError(t, os.MkdirAll(config.OutputFolder, 0755))
+			require.NoError(t, os.WriteFile(txt, []byte("大家好"), 0644))
+			_, err := export.WriteManifest(config, original, map[string]string{"txt": txt, "media": original})
+			require.NoError(t, err)
+
+			processor := NewBatchProcessor(config.MediaFolder, config.OutputFolder, filepath.Join(root, "temp"), nil, config)
+			defer processor.Close()
+			processor.updateProcessedRecord(original, &BatchResult{FilePath: original, Success: true, Service: "bcut"})
+
+			result := processor.processSingleFile(copyPath)
+			require.True(t, result.Success)
+			assert.Equal(t, StatusDuplicate, result.Status)
+			processor.updateProcessedRecord(copyPath, &result)
+			record := processor.processedRecords[copyPath]
+			assert.Equal(t, original, record.DuplicateOf)
+			assert.Equal(t, processor.processedRecords[original].ContentHash, record.ContentHash)
+
+			copied := filepath.Join(config.OutputFolder, "lecture (1).txt")
+			if mode == DedupSkip {
+				assert.Empty(t, result.OutputFiles)
+				assert.NoFileExists(t, copied)
+				return
+			}
+			assert.Equal(t, map[string]string{"txt": copied}, result.OutputFiles)
+			data, err := os.ReadFile(copied)
+			require.NoError(t, err)
+			assert.Equal(t, "大家好", string(data))
+			info, err := os.Lstat(copied)
+			require.NoError(t, err)
+			assert.Equal(t, mode == DedupSymlink, info.Mode()&os.ModeSymlink != 0)
+
+			manifest, err := export.LoadManifest(export.ManifestPath(config.OutputFolder, copyPath))
+			require.NoError(t, err)
+			_, hasMedia := manifest.Output("media")
+			assert.False(t, hasMedia, "源文件不应在副本之间共用")
+		})
+	}
+}
+
+// TestDuplicateOff 测试关闭去重时不查找重复文件
+func TestDuplicateOff(t *testing.T) {
+	config := models.NewDefaultConfig()
+	config.Dedup = DedupOff
+	processor := &BatchProcessor{config: config}
+	assert.Equal(t, DedupOff, processor.dedupMode())
+	processor.config = nil
+	assert.Equal(t, DedupOff, processor.dedupMode())
+}
+
+// TestLinkOutputReplace 测试替换已存在的输出：其他文件移入回收站，指向源文件的链接直接替换
+func TestLinkOutputReplace(t *testing.T) {
+	root := t.TempDir()
+	trashDir := filepath.Join(root, ".trash")
+	bin := trash.New(trash.ModeFolder, trashDir, 0)
+	src := filepath.Join(root, "lecture.txt")
+	dst := filepath.Join(root, "copy", "lecture (1).txt")
+	require.NoError(t, os.WriteFile(src, []byte("大家好"), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Dir(dst), 0755))
+	require.NoError(t, os.WriteFile(dst, []byte("用户的笔记"), 0644))
+
+	require.NoError(t, linkOutput(src, dst, DedupSymlink, bin))
+	data, err := os.ReadFile(dst)
+	require.NoError(t, err)
+	assert.Equal(t, "大家好", string(data))
+	trashed, _ := filepath.Glob(filepath.Join(trashDir, "*lecture (1).txt"))
+	require.Len(t, trashed, 1, "被替换的文件应移入回收站")
+	data, err = os.ReadFile(trashed[0])
+	require.NoError(t, err)
+	assert.Equal(t, "用户的笔记", string(data))
+
+	// 再次链接时已是指向源文件的链接，不再移入回收站
+	require.NoError(t, linkOutput(src, dst, DedupSymlink, bin))
+	trashed, _ = filepath.Glob(filepath.Join(trashDir, "*lecture (1).txt"))
+	assert.Len(t, trashed, 1)
+}
@@ -0,0 +1,29 @@
+package audio
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/utils"
+)
+
+// 切分出的 WAV 片段（16kHz 单声道 16 位）每秒音频占用的字节数，用于预估切分所需的空间
+const segmentBytesPerSecond = 16000 * 2
+
+// checkDiskSpace 批处理开始前检查磁盘空间，不足时提示之后的文件会等待空间释放
+func (p *BatchProcessor) checkDiskSpace() {
+	if err := p.DiskGuard.Check(0); err != nil {
+		utils.Warn("%v，文件将在空间释放后开始处理", err)
+	}
+}
+
+// waitForDiskSpace 在磁盘可用空间足够写入 need 字节前阻塞，等待期间上报文件进度
+func (p *BatchProcessor) waitForDiskSpace(ctx context.Context, filePath string, need uint64) error {
+	err := p.DiskGuard.Wait(ctx, need, func(err error) {
+		p.reportFileProgress(filePath, "", 0, "等待磁盘空间")
+	})
+	if err != nil {
+		return fmt.Errorf("等待磁盘空间时取消: %w", err)
+	}
+	return nil
+}
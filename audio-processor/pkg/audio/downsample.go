@@ -0,0 +1,81 @@
+package audio
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/ffmpeg"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/utils"
+)
+
+// 压缩音频的码率（kbps）：第一遍使用的码率，以及按上限计算码率时的下限
+const (
+	downsampleBitrate    = 32
+	minDownsampleBitrate = 8
+)
+
+// downsampleOversized 待识别的音频超过ASR服务的文件大小上限时，压缩为 16kHz 单声道低码率音频再识别。
+// 第一遍按固定码率压缩，仍然超过上限时按时长计算目标码率再压缩一遍；
+// 压缩后的音频写在本文件的临时目录，流水线结束时删除，原音频保持不变
+func (p *BatchProcessor) downsampleOversized(job *FileJob, service string) error {
+	if p.config == nil || !p.config.DownsampleOversized || p.ASRSelector == nil {
+		return nil
+	}
+	limit := p.ASRSelector.MaxFileSize(service)
+	info, err := os.Stat(job.AudioPath)
+	if limit <= 0 || err != nil || info.Size() <= limit {
+		return nil
+	}
+
+	utils.Info("音频 %s 为 %s，超过ASR服务的上限 %s，压缩后再识别",
+		filepath.Base(job.AudioPath), utils.FormatFileSize(info.Size()), utils.FormatFileSize(limit))
+	dir, err := os.MkdirTemp(p.TempDir, "stage-downsample-")
+	if err != nil {
+		return fmt.Errorf("创建临时目录失败: %w", err)
+	}
+	job.Defer(func() { os.RemoveAll(dir) })
+	base := strings.TrimSuffix(filepath.Base(job.AudioPath), filepath.Ext(job.AudioPath))
+	output := filepath.Join(dir, base+"."+p.config.DownsampleFormat)
+
+	size, err := p.downsampleTo(job, output, downsampleBitrate)
+	if err != nil {
+		return err
+	}
+	if size > limit && job.Duration > 0 {
+		// 目标码率预留 5% 给容器开销
+		bitrate := int(math.Floor(float64(limit) * 8 / float64(job.Duration) / 1000 * 0.95))
+		if bitrate < minDownsampleBitrate {
+			bitrate = minDownsampleBitrate
+		}
+		if bitrate < downsampleBitrate {
+			utils.Info("压缩后为 %s，仍超过上限，按 %dkbps 重新压缩", utils.FormatFileSize(size), bitrate)
+			if size, err = p.downsampleTo(job, output, bitrate); err != nil {
+				return err
+			}
+		}
+	}
+	if size > limit {
+		utils.Warn("压缩后的音频 %s 仍超过上限 %s，识别可能失败", utils.FormatFileSize(size), utils.FormatFileSize(limit))
+	}
+	job.Result.log.Printf("音频超过上限 %s，已压缩为 %s", utils.FormatFileSize(limit), utils.FormatFileSize(size))
+	job.AudioPath = output
+	return nil
+}
+
+// downsampleTo 以 bitrate kbps 压缩 job.AudioPath 到 output，返回压缩后的大小
+func (p *BatchProcessor) downsampleTo(job *FileJob, output string, bitrate int) (int64, error) {
+	if err := p.Extractor.runFFmpeg(job.Context(), ffmpeg.Downsample(job.AudioPath, output, bitrate)); err != nil {
+		job.Result.setError(fmt.Errorf("压缩音频失败: %w", err), ErrorKindExtract)
+		job.Result.log.printFFmpegOutput(err)
+		return 0, job.Result.Error
+	}
+	info, err := os.Stat(output)
+	if err != nil {
+		job.Result.setError(fmt.Errorf("压缩后的音频不存在: %w", err), ErrorKindExtract)
+		return 0, job.Result.Error
+	}
+	return info.Size(), nil
+}
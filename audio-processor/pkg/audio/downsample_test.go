@@ -0,0 +1,53 @@
+package audio
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/asr"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/ffmpeg"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/models"
+)
+
+// TestDownsampleOversized 测试音频超过服务上限时先按固定码率压缩，仍然超过时按上限计算码率再压缩
+func TestDownsampleOversized(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "talk.mp3")
+	require.NoError(t, os.WriteFile(input, make([]byte, 1000), 0644))
+
+	var bitrates []string
+	extractor := &AudioExtractor{}
+	extractor.SetFFmpegRunner(ffmpeg.RunnerFunc(func(ctx context.Context, cmd *ffmpeg.Command) (string, error) {
+		// 输出大小与码率成正比：每 kbps 10 字节
+		bitrate := cmd.Args[len(cmd.Args)-3]
+		bitrates = append(bitrates, bitrate)
+		kbps, err := strconv.Atoi(strings.TrimSuffix(bitrate, "k"))
+		require.NoError(t, err)
+		return "", os.WriteFile(cmd.Args[len(cmd.Args)-1], make([]byte, kbps*10), 0644)
+	}))
+	selector := asr.NewASRSelector()
+	selector.SetServiceOptions("bcut", asr.ServiceOptions{MaxFileSize: 200})
+	processor := &BatchProcessor{TempDir: dir, config: models.NewDefaultConfig(), Extractor: extractor, ASRSelector: selector}
+
+	job := &FileJob{Processor: processor, Result: &BatchResult{FilePath: input, OutputPath: input}, AudioPath: input, Duration: 100}
+	require.NoError(t, processor.downsampleOversized(job, "bcut"))
+	assert.Equal(t, []string{"32k", "8k"}, bitrates)
+	assert.Equal(t, "talk.mp3", filepath.Base(job.AudioPath))
+	assert.NotEqual(t, input, job.AudioPath)
+	assert.FileExists(t, input)
+
+	// 未超过上限时不压缩
+	bitrates = nil
+	selector.SetServiceOptions("bcut", asr.ServiceOptions{MaxFileSize: 2000})
+	job = &FileJob{Processor: processor, Result: &BatchResult{FilePath: input, OutputPath: input}, AudioPath: input, Duration: 100}
+	require.NoError(t, processor.downsampleOversized(job, "bcut"))
+	assert.Empty(t, bitrates)
+	assert.Equal(t, input, job.AudioPath)
+}
@@ -0,0 +1,126 @@
+package audio
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/asr"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/utils"
+)
+
+// EstimateOptions 预估处理耗时使用的参数
+type EstimateOptions struct {
+	RealtimeFactor  float64       // 提取与识别耗时相对音频时长的比例
+	RequestOverhead time.Duration // 每次识别请求的固定开销（上传、排队、轮询结果）
+}
+
+// DefaultEstimateOptions 返回默认的预估参数
+func DefaultEstimateOptions() EstimateOptions {
+	return EstimateOptions{
+		RealtimeFactor:  0.1,
+		RequestOverhead: 20 * time.Second,
+	}
+}
+
+// FileEstimate 单个文件的预估结果
+type FileEstimate struct {
+	Path     string
+	Duration int   // 音频时长（秒）
+	Requests int   // 识别请求数，分段识别时为片段数
+	Err      error // 获取时长失败的原因
+}
+
+// Estimate 批量处理的预估结果
+type Estimate struct {
+	Files         []FileEstimate
+	TotalDuration int                   // 音频总时长（秒）
+	Requests      int                   // 识别请求总数
+	ChunkedFiles  int                   // 需要分段识别的文件数
+	Chunks        int                   // 分段识别产生的片段数
+	Failed        int                   // 无法获取时长的文件数
+	Services      []asr.ServiceEstimate // 各服务预计承担的请求
+	Concurrency   int                   // 同时处理的文件数
+	WallClock     time.Duration         // 预计总耗时
+}
+
+// Estimate 扫描媒体目录并用 ffprobe 获取时长，预估总时长、识别请求数、各服务配额消耗与总耗时。
+// 不会提取音频或调用任何ASR服务。
+func (p *BatchProcessor) Estimate(options EstimateOptions) (*Estimate, error) {
+	files, err := p.scanMediaDirectory()
+	if err != nil {
+		return nil, err
+	}
+
+	concurrency := p.MaxConcurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	estimate := &Estimate{Concurrency: concurrency}
+
+	var totalWork, longest time.Duration
+	for _, path := range files {
+		file := FileEstimate{Path: path}
+		duration, err := p.Extractor.getAudioDuration(p.baseContext(), path)
+		if err != nil {
+			utils.Warn("获取时长失败 %s: %v", path, err)
+			file.Err = fmt.Errorf("获取时长失败: %w", err)
+			estimate.Failed++
+			estimate.Files = append(estimate.Files, file)
+			continue
+		}
+
+		file.Duration = duration
+		file.Requests = p.estimateRequests(duration)
+		if p.shouldChunk(duration) {
+			estimate.ChunkedFiles++
+			estimate.Chunks += file.Requests
+		}
+		estimate.Files = append(estimate.Files, file)
+		estimate.TotalDuration += duration
+		estimate.Requests += file.Requests
+
+		work := p.estimateFileTime(file, options)
+		totalWork += work
+		if work > longest {
+			longest = work
+		}
+	}
+
+	if p.ASRSelector != nil {
+		estimate.Services = p.ASRSelector.EstimateRequests(p.config.ASRService, estimate.Requests)
+	}
+
+	// 总耗时取并发处理所需时间、最长单个文件与限流下发完请求所需时间中的最大值
+	estimate.WallClock = totalWork / time.Duration(concurrency)
+	if longest > estimate.WallClock {
+		estimate.WallClock = longest
+	}
+	for _, service := range estimate.Services {
+		if limit := service.MinDuration(); limit > estimate.WallClock {
+			estimate.WallClock = limit
+		}
+	}
+	return estimate, nil
+}
+
+// estimateRequests 返回识别指定时长的音频需要的请求数
+func (p *BatchProcessor) estimateRequests(duration int) int {
+	if !p.shouldChunk(duration) || p.config.SegmentLength <= 0 {
+		return 1
+	}
+	return (duration + p.config.SegmentLength - 1) / p.config.SegmentLength
+}
+
+// estimateFileTime 预估处理单个文件的耗时，分段识别时片段按 ChunkConcurrency 并发
+func (p *BatchProcessor) estimateFileTime(file FileEstimate, options EstimateOptions) time.Duration {
+	audio := time.Duration(float64(file.Duration) * options.RealtimeFactor * float64(time.Second))
+	overhead := time.Duration(file.Requests) * options.RequestOverhead
+	if file.Requests > 1 && p.config.ChunkConcurrency > 1 {
+		parallel := p.config.ChunkConcurrency
+		if parallel > file.Requests {
+			parallel = file.Requests
+		}
+		return (audio + overhead) / time.Duration(parallel)
+	}
+	return audio + overhead
+}
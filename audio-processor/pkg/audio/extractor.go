@@ -1,13 +1,17 @@
 package audio
 
 import (
+	"context"
 	"fmt"
+	"math"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"sync"
+	"time"
 
 	"github.com/ccp-p/asr-media-cli/audio-processor/internal/ui"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/audit"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/ffmpeg"
 	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/models"
 	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/utils"
 )
@@ -21,13 +25,18 @@ type AudioExtractor struct {
 	ProgressCallback ProgressCallback
 	ProgressManager  *ui.ProgressManager
 	concurrencyLimit int
+	runner           ffmpeg.Runner // 执行 ffmpeg/ffprobe 的方式，为 nil 时直接执行命令
+	audioFilter      string        // 提取音频时应用的预处理滤镜链
+	splitOnSilence   bool          // 分割时是否在静音处切分
+	silenceDB        float64       // 判定为静音的音量阈值（dB）
 }
 
 // AudioSegment 表示一个音频片段
 type AudioSegment struct {
 	Index      int
-	StartTime  int
-	EndTime    int
+	StartTime  float64 // 在原始音频中的起止时间（秒）
+	EndTime    float64
+	InputPath  string
 	OutputPath string
 }
 
@@ -40,6 +49,9 @@ func NewAudioExtractor(tempSegmentsDir string, callback ProgressCallback,config
 		TempSegmentsDir: tempSegmentsDir,
 		ProgressCallback: callback,
 		concurrencyLimit: config.MaxWorkers, // 默认并发数
+		audioFilter:      audioFilterChain(config),
+		splitOnSilence:   config.SplitOnSilence,
+		silenceDB:        config.SplitSilenceDB,
 	}
 }
 
@@ -50,13 +62,70 @@ func (e *AudioExtractor) SetConcurrencyLimit(limit int) {
 	}
 }
 
+// SetSilenceSplit 设置分割时是否在音量低于 noiseDB 的静音处切分
+func (e *AudioExtractor) SetSilenceSplit(enabled bool, noiseDB float64) {
+	e.splitOnSilence = enabled
+	if noiseDB < 0 {
+		e.silenceDB = noiseDB
+	}
+}
+
 // SetProgressManager 设置进度管理器
 func (e *AudioExtractor) SetProgressManager(manager *ui.ProgressManager) {
 	e.ProgressManager = manager
 }
 
-// ExtractAudioFromVideo 从视频文件提取音频
-func (e *AudioExtractor) ExtractAudioFromVideo(videoPath, outputFolder string) (string, bool, error) {
+// ExtractProgress 提取音频的实际进度，来自 ffmpeg 的 -progress 输出
+type ExtractProgress struct {
+	Percent   float64       // 完成百分比（0-100），视频时长未知时为 0
+	Processed time.Duration // 已提取的媒体时长
+	Total     time.Duration // 视频时长，未知时为 0
+	Speed     float64       // 相对实时的提取速度，未知时为 0
+	ETA       time.Duration // 按当前速度估计的剩余时间，未知时为 0
+}
+
+// newExtractProgress 根据 ffmpeg 报告的进度计算百分比与剩余时间
+func newExtractProgress(p ffmpeg.Progress, total time.Duration) ExtractProgress {
+	progress := ExtractProgress{
+		Percent:   p.Percent(total),
+		Processed: p.OutTime,
+		Total:     total,
+		Speed:     p.Speed,
+	}
+	if total > p.OutTime && p.Speed > 0 {
+		progress.ETA = time.Duration(float64(total-p.OutTime) / p.Speed)
+	}
+	return progress
+}
+
+// String 返回用于进度条的说明，如 "已提取 01:30/10:00 12.5x 剩余 00:41"
+func (p ExtractProgress) String() string {
+	msg := "已提取 " + utils.FormatTime(p.Processed.Seconds())
+	if p.Total > 0 {
+		msg += "/" + utils.FormatTime(p.Total.Seconds())
+	}
+	if p.Speed > 0 {
+		msg += fmt.Sprintf(" %.1fx", p.Speed)
+	}
+	if p.ETA > 0 {
+		msg += " 剩余 " + utils.FormatTime(p.ETA.Seconds())
+	}
+	return msg
+}
+
+// ExtractAudioFromVideo 从视频文件提取音频，ctx 取消时终止 ffmpeg 并删除未写完的音频
+func (e *AudioExtractor) ExtractAudioFromVideo(ctx context.Context, videoPath, outputFolder string) (string, bool, error) {
+	return e.ExtractAudioWithProgress(ctx, videoPath, outputFolder, nil)
+}
+
+// ExtractAudioWithProgress 从视频文件提取第一条音频流，按 ffmpeg 报告的实际进度更新进度条并回调 onProgress（可为 nil）。
+// 百分比按 ffprobe 获取的视频时长计算，获取失败时只显示已提取的时长
+func (e *AudioExtractor) ExtractAudioWithProgress(ctx context.Context, videoPath, outputFolder string, onProgress func(ExtractProgress)) (string, bool, error) {
+	return e.ExtractAudioRange(ctx, videoPath, outputFolder, models.MediaSelection{}, onProgress)
+}
+
+// ExtractAudioRange 按 selection 选择的音轨与时间范围提取音频，进度的计算方式同 ExtractAudioWithProgress
+func (e *AudioExtractor) ExtractAudioRange(ctx context.Context, videoPath, outputFolder string, selection models.MediaSelection, onProgress func(ExtractProgress)) (string, bool, error) {
 	videoFilename := filepath.Base(videoPath)
 	baseName := videoFilename[:len(videoFilename)-len(filepath.Ext(videoFilename))]
 	audioPath := filepath.Join(outputFolder, baseName+".mp3")
@@ -68,7 +137,7 @@ func (e *AudioExtractor) ExtractAudioFromVideo(videoPath, outputFolder string) (
 	}
 	
 	// 准备进度条ID
-	progressID := fmt.Sprintf("extract_%s", baseName)
+	progressID := fmt.Sprintf("extract_%s", FileTag(videoPath))
 	
 	// 创建进度条（如果有进度管理器）
 	if e.ProgressManager != nil {
@@ -81,24 +150,49 @@ func (e *AudioExtractor) ExtractAudioFromVideo(videoPath, outputFolder string) (
 	}
 	
 	// 使用FFmpeg提取音频
-	cmd := exec.Command(
-		"ffmpeg",
-		"-i", videoPath,
-		"-q:a", "0",
-		"-map", "a",
-		audioPath,
-		"-y", // 覆盖已存在的文件
-	)
+	audit.RecordOverwrite(audioPath, "extract", "重新提取音频覆盖已有文件")
+	cmd := ffmpeg.ExtractAudio(videoPath, audioPath, ffmpeg.ExtractOptions{
+		Filter: e.audioFilter,
+		Track:  selection.AudioTrack,
+		Start:  selection.Start,
+		End:    selection.End,
+	})
 	
 	utils.Info("正在从视频提取音频: %s", videoFilename)
-	
-	// 更新进度条状态
-	if e.ProgressManager != nil {
-		e.ProgressManager.UpdateProgressBar(progressID, 30, "正在提取")
+	if selection.Clipped() {
+		utils.Info("只提取 %s 至 %s 的音频", utils.FormatTime(selection.Start), clipEndLabel(selection.End))
+	}
+
+	// 视频时长用于计算百分比，获取失败不影响提取；只提取部分时间范围时按范围的长度计算
+	var total time.Duration
+	if seconds, err := ffmpeg.Duration(ctx, e.ffmpegRunner(), videoPath); err == nil {
+		if selection.End > 0 && selection.End < seconds {
+			seconds = selection.End
+		}
+		total = time.Duration((seconds - selection.Start) * float64(time.Second))
+		if total < 0 {
+			total = 0
+		}
+	} else {
+		utils.Debug("获取视频时长失败，提取进度只显示已提取时长: %v", err)
+	}
+	cmd.Progress = func(p ffmpeg.Progress) {
+		progress := newExtractProgress(p, total)
+		if e.ProgressManager != nil {
+			e.ProgressManager.UpdateProgressBar(progressID, int(progress.Percent), progress.String())
+		}
+		if onProgress != nil {
+			onProgress(progress)
+		}
 	}
 	
-	err := cmd.Run()
+	err := e.runFFmpeg(ctx, cmd)
 	if err != nil {
+		// 未写完的音频会在下次处理时被当作已提取的音频，需要删除
+		if removeErr := os.Remove(audioPath); removeErr != nil && !os.IsNotExist(removeErr) {
+			utils.Warn("删除未完成的音频失败: %v", removeErr)
+		}
+
 		// 更新失败状态
 		if e.ProgressManager != nil {
 			e.ProgressManager.CompleteProgressBar(progressID, fmt.Sprintf("失败: %v", err))
@@ -137,25 +231,55 @@ func (e *AudioExtractor) ExtractAudioFromVideo(videoPath, outputFolder string) (
 	return audioPath, true, nil
 }
 
-// SplitAudioFile 将音频文件分割为较小片段，支持并发处理
-func (e *AudioExtractor) SplitAudioFile(inputPath string, segmentLength int) ([]string, error) {
+// clipEndLabel 返回时间范围结束时间的说明，0 表示到结尾
+func clipEndLabel(end float64) string {
+	if end <= 0 {
+		return "结尾"
+	}
+	return utils.FormatTime(end)
+}
+
+// DownloadHLS 下载 HLS 播放列表（本地文件或 URL）的分片并拼接为 outputDir 下的 .ts 文件，返回其路径
+func (e *AudioExtractor) DownloadHLS(ctx context.Context, playlist, outputDir string) (string, error) {
+	output := filepath.Join(outputDir, mediaBaseName(playlist)+".ts")
+	utils.Info("正在下载 HLS 分片: %s", playlist)
+
+	cmd := ffmpeg.DownloadHLS(playlist, output)
+	cmd.Progress = func(p ffmpeg.Progress) {
+		utils.Debug("已下载 %s (%d 字节)", utils.FormatTime(p.OutTime.Seconds()), p.TotalSize)
+	}
+	if err := e.runFFmpeg(ctx, cmd); err != nil {
+		os.Remove(output)
+		return "", fmt.Errorf("下载 HLS 分片失败: %w", err)
+	}
+	return output, nil
+}
+
+// SplitAudioFile 将音频文件分割为较小片段，支持并发处理，返回按顺序排列的片段信息
+func (e *AudioExtractor) SplitAudioFile(ctx context.Context, inputPath string, segmentLength int) ([]AudioSegment, error) {
+	return e.SplitAudioFileTo(ctx, inputPath, segmentLength, e.TempSegmentsDir)
+}
+
+// SplitAudioFileTo 将音频文件分割到指定目录，ctx 取消时不再导出剩余的片段
+func (e *AudioExtractor) SplitAudioFileTo(ctx context.Context, inputPath string, segmentLength int, outputDir string) ([]AudioSegment, error) {
 	filename := filepath.Base(inputPath)
-	baseName := filename[:len(filename)-len(filepath.Ext(filename))]
 	utils.Info("正在分割 %s 为小片段...", filename)
 	
 	// 获取音频总时长
-	duration, err := e.getAudioDuration(inputPath)
+	duration, err := ffmpeg.Duration(ctx, e.ffmpegRunner(), inputPath)
 	if err != nil {
 		return nil, fmt.Errorf("获取音频时长失败: %w", err)
 	}
 	
-	utils.Info("音频总时长: %d秒", duration)
+	utils.Info("音频总时长: %.1f秒", duration)
 	
-	// 计算片段数量
-	expectedSegments := (duration + segmentLength - 1) / segmentLength
+	// 计算切分点与片段数量
+	bounds := e.segmentBounds(ctx, inputPath, duration, float64(segmentLength))
+	expectedSegments := len(bounds) - 1
 	
 	// 创建进度条
-	progressID := fmt.Sprintf("split_%s", baseName)
+	fileTag := FileTag(inputPath)
+	progressID := fmt.Sprintf("split_%s", fileTag)
 	if e.ProgressManager != nil {
 		e.ProgressManager.CreateProgressBar(progressID, expectedSegments, 
 			fmt.Sprintf("分割 %s", filename), fmt.Sprintf("准备分割 %d 个片段", expectedSegments))
@@ -165,10 +289,11 @@ func (e *AudioExtractor) SplitAudioFile(inputPath string, segmentLength int) ([]
 	if e.ProgressCallback != nil {
 		e.ProgressCallback(0, expectedSegments, "准备分割音频")
 	}
+	publishSegmentEvent(SegmentEvent{Type: SegmentSplitStarted, File: inputPath, Total: expectedSegments})
 	
 	// 创建工作通道
 	jobs := make(chan AudioSegment, expectedSegments)
-	results := make(chan string, expectedSegments)
+	results := make(chan AudioSegment, expectedSegments)
 	errors := make(chan error, expectedSegments)
 	progress := make(chan int, expectedSegments) // 进度通道
 	
@@ -179,13 +304,19 @@ func (e *AudioExtractor) SplitAudioFile(inputPath string, segmentLength int) ([]
 		workerCount = expectedSegments
 	}
 	
-	// 启动单独的进度更新协程
-	wg.Add(1)
+	// 启动单独的进度更新协程（不计入工作协程的 WaitGroup，否则 progress 永远不会被关闭）
+	progressDone := make(chan struct{})
 	go func() {
-		defer wg.Done()
+		defer close(progressDone)
 		completedCount := 0
 		for range progress {
 			completedCount++
+			publishSegmentEvent(SegmentEvent{
+				Type:      SegmentExported,
+				File:      inputPath,
+				Completed: completedCount,
+				Total:     expectedSegments,
+			})
 			// 更新进度条
 			if e.ProgressManager != nil {
 				e.ProgressManager.UpdateProgressBar(progressID, completedCount, 
@@ -204,28 +335,24 @@ func (e *AudioExtractor) SplitAudioFile(inputPath string, segmentLength int) ([]
 		wg.Add(1)
 		go func(id int) {
 			defer wg.Done()
-			e.segmentWorker(id, jobs, results, errors, progress)
+			e.segmentWorker(ctx, id, jobs, results, errors, progress)
 		}(w)
 	}
 	
 	// 创建任务
 	go func() {
-		baseFilename := filename[:len(filename)-len(filepath.Ext(filename))]
-		
 		for i := 0; i < expectedSegments; i++ {
-			startTime := i * segmentLength
-			endTime := (i + 1) * segmentLength
-			if endTime > duration {
-				endTime = duration
-			}
+			startTime := bounds[i]
+			endTime := bounds[i+1]
 			
-			outputFilename := fmt.Sprintf("%s_part%03d.wav", baseFilename, i+1)
-			outputPath := filepath.Join(e.TempSegmentsDir, outputFilename)
+			outputFilename := fmt.Sprintf("%s_part%03d.wav", fileTag, i+1)
+			outputPath := filepath.Join(outputDir, outputFilename)
 			
 			jobs <- AudioSegment{
 				Index:      i,
 				StartTime:  startTime,
 				EndTime:    endTime,
+				InputPath:  inputPath,
 				OutputPath: outputPath,
 			}
 		}
@@ -241,37 +368,44 @@ func (e *AudioExtractor) SplitAudioFile(inputPath string, segmentLength int) ([]
 	}()
 	
 	// 收集结果
-	segmentFiles := make([]string, 0, expectedSegments)
-	resultMap := make(map[int]string)
-	errorOccurred := false
+	segmentFiles := make([]AudioSegment, 0, expectedSegments)
+	resultMap := make(map[int]AudioSegment)
+	var firstErr error
 	
-	// 处理错误
+	// 处理错误，保留第一个错误以便报告失败原因
 	for err := range errors {
 		if err != nil {
-			errorOccurred = true
+			if firstErr == nil {
+				firstErr = err
+			}
 			utils.Error("分割音频时出错: %v", err)
 		}
 	}
 	
-	if errorOccurred {
+	// 等待进度协程处理完剩余的进度通知
+	<-progressDone
+
+	if firstErr != nil {
+		splitErr := fmt.Errorf("分割音频过程中发生错误: %w", firstErr)
+		publishSegmentEvent(SegmentEvent{Type: SegmentSplitFailed, File: inputPath, Total: expectedSegments, Err: splitErr})
+
 		// 完成进度条（出错状态）
 		if e.ProgressManager != nil {
 			e.ProgressManager.CompleteProgressBar(progressID, "分割失败")
 		}
 		
-		return nil, fmt.Errorf("分割音频过程中发生错误")
+		return nil, splitErr
 	}
 	
 	// 处理结果
-	for filename := range results {
-		parts := filepath.Base(filename)
-		resultMap[getSegmentIndex(parts)] = parts
+	for segment := range results {
+		resultMap[segment.Index] = segment
 	}
 	
 	// 按顺序组织结果
 	for i := 0; i < expectedSegments; i++ {
-		if filename, ok := resultMap[i]; ok {
-			segmentFiles = append(segmentFiles, filename)
+		if segment, ok := resultMap[i]; ok {
+			segmentFiles = append(segmentFiles, segment)
 		}
 	}
 	
@@ -280,6 +414,12 @@ func (e *AudioExtractor) SplitAudioFile(inputPath string, segmentLength int) ([]
 		e.ProgressManager.CompleteProgressBar(progressID, 
 			fmt.Sprintf("完成 - %d 个片段", len(segmentFiles)))
 	}
+	publishSegmentEvent(SegmentEvent{
+		Type:      SegmentSplitFinished,
+		File:      inputPath,
+		Completed: len(segmentFiles),
+		Total:     expectedSegments,
+	})
 	
 	// 完成进度
 	if e.ProgressCallback != nil {
@@ -294,58 +434,92 @@ func (e *AudioExtractor) SplitAudioFile(inputPath string, segmentLength int) ([]
 }
 
 // 工作协程函数，处理音频片段切分
-func (e *AudioExtractor) segmentWorker(id int, jobs <-chan AudioSegment, 
-	results chan<- string, errors chan<- error, progress chan<- int) {
+func (e *AudioExtractor) segmentWorker(ctx context.Context, id int, jobs <-chan AudioSegment, 
+	results chan<- AudioSegment, errors chan<- error, progress chan<- int) {
 	
 	for job := range jobs {
+		// 已取消时跳过剩余片段
+		if err := ctx.Err(); err != nil {
+			errors <- fmt.Errorf("片段 %d 导出已取消: %w", job.Index+1, err)
+			continue
+		}
+
 		// 使用FFmpeg切分音频
-		cmd := exec.Command(
-			"ffmpeg",
-			"-y",                                    // 覆盖输出文件
-			"-i", job.OutputPath,                    // 输入文件
-			"-ss", fmt.Sprintf("%d", job.StartTime), // 开始时间
-			"-to", fmt.Sprintf("%d", job.EndTime),   // 结束时间
-			"-ac", "1",                              // 单声道
-			"-ar", "16000",                          // 16kHz采样率
-			job.OutputPath,
-		)
-		
-		err := cmd.Run()
+		cmd := ffmpeg.ExtractSegment(job.InputPath, job.OutputPath, job.StartTime, job.EndTime)
+		err := e.runFFmpeg(ctx, cmd)
 		if err != nil {
+			os.Remove(job.OutputPath)
 			errors <- fmt.Errorf("片段 %d 导出失败: %w", job.Index+1, err)
 			continue
 		}
 		
 		utils.Debug("导出片段完成: %s", filepath.Base(job.OutputPath))
-		results <- job.OutputPath
+		results <- job
 		progress <- 1 // 通知进度更新
 	}
 }
 
 // 获取音频时长（秒）
-func (e *AudioExtractor) getAudioDuration(audioPath string) (int, error) {
-	cmd := exec.Command(
-		"ffprobe",
-		"-v", "error",
-		"-show_entries", "format=duration",
-		"-of", "default=noprint_wrappers=1:nokey=1",
-		audioPath,
-	)
-	
-	output, err := cmd.Output()
+func (e *AudioExtractor) getAudioDuration(ctx context.Context, audioPath string) (int, error) {
+	duration, err := ffmpeg.Duration(ctx, e.ffmpegRunner(), audioPath)
 	if err != nil {
 		return 0, err
 	}
-	
-	var duration float64
-	_, err = fmt.Sscanf(string(output), "%f", &duration)
-	if err != nil {
-		return 0, err
-	}
-	
 	return int(duration), nil
 }
 
+// 静音切分的参数：静音至少持续的时长（秒），以及切分点最多比片段长度提前的比例
+const (
+	minSplitSilence   = 0.3
+	splitSearchWindow = 0.25
+)
+
+// segmentBounds 返回各片段的边界（含 0 与总时长）。启用静音切分时先检测静音，
+// 检测失败则退回按固定长度切分
+func (e *AudioExtractor) segmentBounds(ctx context.Context, inputPath string, duration, segmentLength float64) []float64 {
+	var silences []ffmpeg.Silence
+	if e.splitOnSilence && duration > segmentLength {
+		detected, err := ffmpeg.DetectSilences(ctx, e.ffmpegRunner(), inputPath, e.silenceDB, minSplitSilence)
+		if err != nil {
+			utils.Warn("检测静音失败，按固定长度分割: %v", err)
+		} else {
+			utils.Debug("检测到 %d 处静音", len(detected))
+			silences = detected
+		}
+	}
+	return planCuts(duration, segmentLength, silences)
+}
+
+// planCuts 计算切分点：每个片段不超过 target 秒，优先在理想切分点之前 target/4 范围内、
+// 离理想切分点最近的静音处切分，范围内没有静音时在理想切分点切分
+func planCuts(duration, target float64, silences []ffmpeg.Silence) []float64 {
+	bounds := []float64{0}
+	if duration <= 0 || target <= 0 {
+		return bounds
+	}
+	last := 0.0
+	for duration-last > target {
+		ideal := last + target
+		cut := ideal
+		best := -1.0
+		for _, silence := range silences {
+			point := silence.Mid()
+			if silence.Start <= ideal && ideal <= silence.End {
+				point = ideal // 理想切分点本身就在静音中
+			}
+			if point > last && point >= ideal-target*splitSearchWindow && point <= ideal && point > best {
+				best = point
+			}
+		}
+		if best > 0 {
+			cut = math.Round(best*1000) / 1000
+		}
+		bounds = append(bounds, cut)
+		last = cut
+	}
+	return append(bounds, duration)
+}
+
 // 从文件名中提取片段索引
 func getSegmentIndex(filename string) int {
 	var index int
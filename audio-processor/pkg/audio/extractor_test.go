@@ -1,13 +1,17 @@
 package audio
 
 import (
+	"context"
+	"fmt"
 	"os"
 	"path/filepath"
 	"testing"
 	"time"
 
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/ffmpeg"
 	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/models"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // TestNewAudioExtractor 测试音频提取器的创建
@@ -79,7 +83,7 @@ func TestExtractAudioFromVideo(t *testing.T) {
 	// 由于没有真实的视频文件，下面的测试预期会失败
 	// 实际项目中，应该准备一个小的测试视频文件
 	extractor := NewAudioExtractor(tempDir, nil, config)
-	audioPath, extracted, err := extractor.ExtractAudioFromVideo(videoPath, tempDir)
+	audioPath, extracted, err := extractor.ExtractAudioFromVideo(context.Background(), videoPath, tempDir)
 	
 	// 这里应该失败，因为我们没有有效的视频文件
 	assert.Error(t, err)
@@ -117,3 +121,99 @@ func TestProgressCallback(t *testing.T) {
 		t.Fatal("回调函数没有在预期时间内被调用")
 	}
 }
+
+// TestFileTag 测试不同目录下的同名文件得到不同的标识
+func TestFileTag(t *testing.T) {
+	a := FileTag(filepath.Join("media", "a", "会议.mp4"))
+	b := FileTag(filepath.Join("media", "b", "会议.mp4"))
+
+	assert.NotEqual(t, a, b)
+	assert.Regexp(t, `^会议_[0-9a-f]{8}$`, a)
+	assert.Equal(t, a, FileTag(filepath.Join("media", "a", "会议.mp4")))
+}
+
+// TestExtractAudioProgress 测试按 ffmpeg 报告的进度与视频时长计算百分比和剩余时间
+func TestExtractAudioProgress(t *testing.T) {
+	extractor := &AudioExtractor{}
+	extractor.SetFFmpegRunner(ffmpeg.RunnerFunc(func(ctx context.Context, cmd *ffmpeg.Command) (string, error) {
+		if cmd.Program == "ffprobe" {
+			fmt.Fprintln(cmd.Stdout, "100.0")
+			return "", nil
+		}
+		require.NotNil(t, cmd.Progress)
+		cmd.Progress(ffmpeg.Progress{OutTime: 25 * time.Second, Speed: 5})
+		cmd.Progress(ffmpeg.Progress{OutTime: 100 * time.Second, Speed: 5, Done: true})
+		return "", os.WriteFile(cmd.Args[len(cmd.Args)-2], []byte("mp3"), 0644)
+	}))
+
+	var reports []ExtractProgress
+	audioPath, extracted, err := extractor.ExtractAudioWithProgress(context.Background(), "/media/talk.mp4", t.TempDir(),
+		func(p ExtractProgress) { reports = append(reports, p) })
+	require.NoError(t, err)
+	assert.True(t, extracted)
+	assert.Equal(t, "talk.mp3", filepath.Base(audioPath))
+
+	require.Len(t, reports, 2)
+	assert.Equal(t, 25.0, reports[0].Percent)
+	assert.Equal(t, 15*time.Second, reports[0].ETA)
+	assert.Equal(t, "已提取 00:25/01:40 5.0x 剩余 00:15", reports[0].String())
+	assert.Equal(t, 100.0, reports[1].Percent)
+	assert.Zero(t, reports[1].ETA)
+}
+
+// TestPlanCuts 测试优先在片段长度附近的静音处切分
+func TestPlanCuts(t *testing.T) {
+	// 没有静音时按固定长度切分
+	assert.Equal(t, []float64{0, 30, 60, 75.5}, planCuts(75.5, 30, nil))
+	assert.Equal(t, []float64{0, 20}, planCuts(20, 30, nil))
+	assert.Equal(t, []float64{0}, planCuts(0, 30, nil))
+
+	silences := []ffmpeg.Silence{
+		{Start: 10, End: 11},     // 距理想切分点太远
+		{Start: 26, End: 27},     // 在范围内
+		{Start: 28.2, End: 28.8}, // 在范围内且更近
+		{Start: 58, End: 62},     // 包含理想切分点
+		{Start: 89, End: 90},     // 超过片段长度
+	}
+	assert.Equal(t, []float64{0, 28.5, 58.5, 88.5, 100}, planCuts(100, 30, silences))
+}
+
+// TestSplitAudioOnSilence 测试分割时按检测到的静音生成片段
+func TestSplitAudioOnSilence(t *testing.T) {
+	config := models.NewDefaultConfig()
+	config.MaxWorkers = 2
+	extractor := NewAudioExtractor(t.TempDir(), nil, config)
+
+	var silenceArgs []string
+	extractor.SetFFmpegRunner(ffmpeg.RunnerFunc(func(ctx context.Context, cmd *ffmpeg.Command) (string, error) {
+		switch {
+		case cmd.Program == "ffprobe":
+			fmt.Fprintln(cmd.Stdout, "70.25")
+		case cmd.Stderr != nil:
+			silenceArgs = cmd.Args
+			fmt.Fprintln(cmd.Stderr, "[silencedetect @ 0x1] silence_start: 27.5")
+			fmt.Fprintln(cmd.Stderr, "[silencedetect @ 0x1] silence_end: 28.5 | silence_duration: 1")
+		default:
+			return "", os.WriteFile(cmd.Args[len(cmd.Args)-1], []byte("wav"), 0644)
+		}
+		return "", nil
+	}))
+
+	segments, err := extractor.SplitAudioFileTo(context.Background(), "/media/talk.mp3", 30, t.TempDir())
+	require.NoError(t, err)
+	assert.Contains(t, silenceArgs, "silencedetect=noise=-35dB:d=0.3")
+	require.Len(t, segments, 3)
+	assert.Equal(t, 28.0, segments[0].EndTime)
+	assert.Equal(t, 28.0, segments[1].StartTime)
+	assert.Equal(t, 58.0, segments[1].EndTime)
+	assert.Equal(t, 70.25, segments[2].EndTime)
+
+	// 关闭静音切分后按固定长度切分
+	silenceArgs = nil
+	extractor.SetSilenceSplit(false, -35)
+	segments, err = extractor.SplitAudioFileTo(context.Background(), "/media/talk.mp3", 30, t.TempDir())
+	require.NoError(t, err)
+	assert.Nil(t, silenceArgs)
+	require.Len(t, segments, 3)
+	assert.Equal(t, 30.0, segments[0].EndTime)
+}
@@ -0,0 +1,38 @@
+package audio
+
+import (
+	"context"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/ffmpeg"
+)
+
+// ffmpeg 常见失败原因，见 ffmpeg 包
+var (
+	ErrNoAudioStream = ffmpeg.ErrNoAudioStream
+	ErrCorruptMedia  = ffmpeg.ErrCorruptMedia
+)
+
+// FFmpegError ffmpeg/ffprobe 执行失败，携带 stderr 末尾的输出
+type FFmpegError = ffmpeg.Error
+
+// 处理日志中展示的 stderr 行数
+const logTailLines = 20
+
+// ffmpegRunner 返回执行 ffmpeg/ffprobe 的方式，未设置时直接执行命令
+func (e *AudioExtractor) ffmpegRunner() ffmpeg.Runner {
+	if e == nil || e.runner == nil {
+		return ffmpeg.DefaultRunner
+	}
+	return e.runner
+}
+
+// SetFFmpegRunner 设置执行 ffmpeg/ffprobe 的方式，测试时可替换为假实现
+func (e *AudioExtractor) SetFFmpegRunner(runner ffmpeg.Runner) {
+	e.runner = runner
+}
+
+// runFFmpeg 执行命令，失败时返回 *FFmpegError
+func (e *AudioExtractor) runFFmpeg(ctx context.Context, cmd *ffmpeg.Command) error {
+	_, err := e.ffmpegRunner().Run(ctx, cmd)
+	return err
+}
@@ -0,0 +1,76 @@
+package audio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/ffmpeg"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestFFmpegErrorCause 测试按 stderr 识别出的失败原因归类错误
+func TestFFmpegErrorCause(t *testing.T) {
+	exitErr := errors.New("exit status 1")
+
+	err := ffmpeg.NewError(exitErr, "Input #0, mov,mp4\nStream map '0:a' matches no streams.\nTo ignore this, add a trailing '?' to the map.\n")
+	assert.True(t, errors.Is(err, ErrNoAudioStream))
+	assert.Equal(t, ErrorKindNoAudio, classifyError(fmt.Errorf("从视频提取音频失败: %w", err), ErrorKindExtract))
+
+	err = ffmpeg.NewError(exitErr, "[mov,mp4,m4a,3gp,3g2,mj2 @ 0x1] moov atom not found\nbroken.mp4: Invalid data found when processing input\n")
+	assert.True(t, errors.Is(err, ErrCorruptMedia))
+	assert.Equal(t, ErrorKindCorrupt, classifyError(err, ErrorKindExtract))
+
+	err = ffmpeg.NewError(exitErr, "something else\n")
+	assert.Nil(t, err.Cause)
+	assert.Equal(t, ErrorKindExtract, classifyError(err, ErrorKindExtract))
+}
+
+// TestRunFFmpegCanceled 测试 ctx 取消时终止命令，错误可用 errors.Is 判断为取消
+func TestRunFFmpegCanceled(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("跳过需要sh的测试")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err := (&AudioExtractor{}).runFFmpeg(ctx, &ffmpeg.Command{Program: "sh", Args: []string{"-c", "exec sleep 10"}})
+
+	assert.Less(t, time.Since(start), 5*time.Second)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.Equal(t, ErrorKindTimeout, classifyError(err, ErrorKindExtract))
+}
+
+// TestExtractorFFmpegRunner 测试替换 Runner 后不调用真实的 ffprobe
+func TestExtractorFFmpegRunner(t *testing.T) {
+	var programs []string
+	extractor := &AudioExtractor{}
+	extractor.SetFFmpegRunner(ffmpeg.RunnerFunc(func(ctx context.Context, cmd *ffmpeg.Command) (string, error) {
+		programs = append(programs, cmd.Program)
+		switch cmd.Args[len(cmd.Args)-1] {
+		case "broken.mp4":
+			return "", ffmpeg.NewError(errors.New("exit status 1"), "broken.mp4: Invalid data found when processing input")
+		case "silent.mp4":
+			return "", nil
+		}
+		fmt.Fprintln(cmd.Stdout, "12.5")
+		return "", nil
+	}))
+
+	duration, err := extractor.getAudioDuration(context.Background(), "a.mp3")
+	require.NoError(t, err)
+	assert.Equal(t, 12, duration)
+
+	hasAudio, err := extractor.hasAudioStream(context.Background(), "silent.mp4")
+	require.NoError(t, err)
+	assert.False(t, hasAudio)
+
+	_, err = extractor.getAudioDuration(context.Background(), "broken.mp4")
+	assert.ErrorIs(t, err, ErrCorruptMedia)
+	assert.Equal(t, []string{"ffprobe", "ffprobe", "ffprobe"}, programs)
+}
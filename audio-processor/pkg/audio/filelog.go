@@ -0,0 +1,154 @@
+package audio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/asr"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/utils"
+)
+
+// 失败类型，用于在报告中区分失败原因
+const (
+	ErrorKindInput    = "input"    // 输入文件无效（不存在、为空、格式不支持）
+	ErrorKindExtract  = "extract"  // 提取音频失败
+	ErrorKindASR      = "asr"      // 语音识别或导出失败
+	ErrorKindQuota    = "quota"    // 所有ASR服务的配额已用完
+	ErrorKindTimeout  = "timeout"  // 处理超时
+	ErrorKindCanceled = "canceled" // 处理被取消
+	ErrorKindNoAudio  = "no_audio" // 文件不包含音频流
+	ErrorKindCorrupt  = "corrupt"  // 文件已损坏或格式无法识别
+	ErrorKindSchema   = "schema"   // ASR服务响应格式不符合预期，上游接口可能已变化
+)
+
+// LogDirName 输出目录下存放单个文件处理日志的子目录
+const LogDirName = "logs"
+
+// classifyError 根据错误内容确定失败类型，无法细分时使用 stage
+func classifyError(err error, stage string) string {
+	switch {
+	case errors.Is(err, context.Canceled):
+		return ErrorKindCanceled
+	case errors.Is(err, context.DeadlineExceeded):
+		return ErrorKindTimeout
+	case errors.Is(err, asr.ErrQuotaExhausted):
+		return ErrorKindQuota
+	case errors.Is(err, asr.ErrSchemaMismatch):
+		return ErrorKindSchema
+	case errors.Is(err, ErrNoAudioStream):
+		return ErrorKindNoAudio
+	case errors.Is(err, ErrCorruptMedia):
+		return ErrorKindCorrupt
+	}
+	return stage
+}
+
+// setError 标记结果失败并记录失败类型
+func (r *BatchResult) setError(err error, stage string) {
+	r.Success = false
+	r.Error = err
+	r.ErrorKind = classifyError(err, stage)
+}
+
+// FileLog 单个文件的处理日志，记录提取与识别过程中的关键信息，失败时附在报告中。
+// nil 日志不做任何事。
+type FileLog struct {
+	mu   sync.Mutex
+	path string
+	file *os.File
+}
+
+// openFileLog 为源文件创建处理日志，覆盖上一次处理留下的日志
+func (p *BatchProcessor) openFileLog(filePath string) *FileLog {
+	dir := filepath.Join(p.OutputDir, LogDirName)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		utils.Warn("创建日志目录失败: %v", err)
+		return nil
+	}
+	path := filepath.Join(dir, FileTag(filePath)+".log")
+	file, err := os.Create(path)
+	if err != nil {
+		utils.Warn("创建文件处理日志失败: %v", err)
+		return nil
+	}
+
+	l := &FileLog{path: path, file: file}
+	l.Printf("源文件: %s", filePath)
+	return l
+}
+
+// Printf 写入一行日志
+func (l *FileLog) Printf(format string, args ...interface{}) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.file == nil {
+		return
+	}
+	fmt.Fprintf(l.file, "%s %s\n", time.Now().Format("2006-01-02 15:04:05"), fmt.Sprintf(format, args...))
+}
+
+// printFFmpegOutput ffmpeg 执行失败时记录其 stderr 末尾的输出
+func (l *FileLog) printFFmpegOutput(err error) {
+	var ffmpegErr *FFmpegError
+	if !errors.As(err, &ffmpegErr) {
+		return
+	}
+	for _, line := range ffmpegErr.Tail(logTailLines) {
+		l.Printf("  ffmpeg: %s", line)
+	}
+}
+
+// Finish 关闭日志。处理成功时删除日志并返回空路径，失败时保留并返回日志路径
+func (l *FileLog) Finish(result *BatchResult) string {
+	if l == nil {
+		return ""
+	}
+	if result.Success {
+		l.Printf("处理成功")
+	} else {
+		l.Printf("处理失败 [%s]: %v", result.ErrorKind, result.Error)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.file != nil {
+		l.file.Close()
+		l.file = nil
+	}
+	if result.Success {
+		os.Remove(l.path)
+		return ""
+	}
+	return l.path
+}
+
+// logFileName 返回日志文件名，用于通过 LogHandler 下载
+func logFileName(path string) string {
+	if path == "" {
+		return ""
+	}
+	return filepath.Base(path)
+}
+
+// LogHandler 提供失败文件处理日志的下载，路径为 <prefix><日志文件名>
+func LogHandler(outputDir, prefix string) http.Handler {
+	dir := filepath.Join(outputDir, LogDirName)
+	return http.StripPrefix(prefix, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := filepath.Base(filepath.Clean("/" + r.URL.Path))
+		if filepath.Ext(name) != ".log" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		http.ServeFile(w, r, filepath.Join(dir, name))
+	}))
+}
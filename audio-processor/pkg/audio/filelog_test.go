@@ -0,0 +1,55 @@
+package audio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/asr"
+)
+
+func TestClassifyError(t *testing.T) {
+	assert.Equal(t, ErrorKindCanceled, classifyError(fmt.Errorf("识别失败: %w", context.Canceled), ErrorKindASR))
+	assert.Equal(t, ErrorKindTimeout, classifyError(context.DeadlineExceeded, ErrorKindASR))
+	assert.Equal(t, ErrorKindQuota, classifyError(fmt.Errorf("bcut: %w", asr.ErrQuotaExhausted), ErrorKindASR))
+	assert.Equal(t, ErrorKindExtract, classifyError(errors.New("ffmpeg"), ErrorKindExtract))
+}
+
+func TestFileLogKeptOnlyOnFailure(t *testing.T) {
+	p := &BatchProcessor{OutputDir: t.TempDir()}
+
+	ok := BatchResult{FilePath: "/media/a.mp4", Success: true}
+	ok.log = p.openFileLog(ok.FilePath)
+	ok.log.Printf("音频: a.mp3")
+	assert.Empty(t, ok.log.Finish(&ok))
+	assert.NoFileExists(t, ok.log.path)
+
+	failed := BatchResult{FilePath: "/media/b.mp4"}
+	failed.log = p.openFileLog(failed.FilePath)
+	failed.setError(errors.New("exit status 1"), ErrorKindExtract)
+	path := failed.log.Finish(&failed)
+	require.FileExists(t, path)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "源文件: /media/b.mp4")
+	assert.Contains(t, string(data), "处理失败 [extract]: exit status 1")
+
+	// 通过接口下载日志，不允许访问日志目录以外的文件
+	handler := LogHandler(p.OutputDir, "/api/logs/")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/logs/"+logFileName(path), nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "exit status 1")
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/logs/../processed_records.json", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
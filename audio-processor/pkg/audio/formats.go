@@ -0,0 +1,125 @@
+package audio
+
+import (
+	"bytes"
+	"net/url"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultAudioExtensions 默认支持的音频格式
+var DefaultAudioExtensions = []string{".mp3", ".wav", ".m4a", ".flac", ".ogg", ".opus", ".aac"}
+
+// DefaultVideoExtensions 默认支持的视频格式，识别前先提取音频
+var DefaultVideoExtensions = []string{".mp4", ".mov", ".avi", ".mkv", ".flv", ".wmv", ".webm", ".ts"}
+
+// HLSExtension HLS 播放列表的扩展名，分片下载拼接后再提取音频
+const HLSExtension = ".m3u8"
+
+// directAudioExtensions 可直接交给ASR服务识别的音频格式，其余音频格式先转码为 MP3
+var directAudioExtensions = []string{".mp3", ".wav", ".m4a"}
+
+// SupportedExtensions 返回默认支持的全部输入格式
+func SupportedExtensions() []string {
+	exts := append([]string{}, DefaultAudioExtensions...)
+	exts = append(exts, DefaultVideoExtensions...)
+	return append(exts, HLSExtension)
+}
+
+// mediaExt 返回小写的扩展名，URL 只取路径部分（忽略查询参数）
+func mediaExt(path string) string {
+	if IsRemoteMedia(path) {
+		if u, err := url.Parse(path); err == nil {
+			path = u.Path
+		}
+	}
+	return strings.ToLower(filepath.Ext(path))
+}
+
+// hasExtension 判断文件扩展名是否在列表中，不区分大小写
+func hasExtension(path string, exts []string) bool {
+	ext := mediaExt(path)
+	for _, candidate := range exts {
+		if strings.ToLower(candidate) == ext {
+			return true
+		}
+	}
+	return false
+}
+
+// IsRemoteMedia 判断输入是否为 http/https 地址
+func IsRemoteMedia(path string) bool {
+	lower := strings.ToLower(path)
+	return strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://")
+}
+
+// IsHLS 判断输入是否为 HLS 播放列表（本地文件或 URL）
+func IsHLS(path string) bool {
+	return mediaExt(path) == HLSExtension
+}
+
+// mediaBaseName 返回不含扩展名的文件名，URL 取路径的最后一段
+func mediaBaseName(path string) string {
+	if IsRemoteMedia(path) {
+		if u, err := url.Parse(path); err == nil {
+			path = u.Path
+		}
+	}
+	name := filepath.Base(filepath.FromSlash(path))
+	name = strings.TrimSuffix(name, filepath.Ext(name))
+	if name == "" || name == "." || name == string(filepath.Separator) {
+		return "stream"
+	}
+	return name
+}
+
+// isVideoFile 判断文件扩展名是否为视频
+func (p *BatchProcessor) isVideoFile(path string) bool {
+	return hasExtension(path, p.VideoExtensions)
+}
+
+// isAudioFile 判断文件扩展名是否为音频
+func (p *BatchProcessor) isAudioFile(path string) bool {
+	return hasExtension(path, p.AudioExtensions)
+}
+
+// IsSupportedFile 判断文件是否为支持的音频、视频或 HLS 播放列表
+func (p *BatchProcessor) IsSupportedFile(path string) bool {
+	return p.isAudioFile(path) || p.isVideoFile(path) || IsHLS(path)
+}
+
+// needsTranscode 判断音频是否需要先转码为 MP3 才能识别
+func needsTranscode(path string) bool {
+	return !hasExtension(path, directAudioExtensions)
+}
+
+// SniffExtension 按文件头判断媒体格式，返回对应的扩展名，用于没有文件名的输入（如标准输入）。
+// 无法判断时返回空字符串
+func SniffExtension(header []byte) string {
+	switch {
+	case bytes.HasPrefix(header, []byte("ID3")):
+		return ".mp3"
+	case bytes.HasPrefix(header, []byte("RIFF")) && len(header) >= 12 && string(header[8:12]) == "WAVE":
+		return ".wav"
+	case bytes.HasPrefix(header, []byte("fLaC")):
+		return ".flac"
+	case bytes.HasPrefix(header, []byte("OggS")):
+		return ".ogg"
+	case len(header) >= 12 && string(header[4:8]) == "ftyp":
+		if string(header[8:12]) == "M4A " {
+			return ".m4a"
+		}
+		return ".mp4"
+	case bytes.HasPrefix(header, []byte{0x1A, 0x45, 0xDF, 0xA3}):
+		return ".mkv" // Matroska 与 WebM
+	case bytes.HasPrefix(header, []byte("FLV")):
+		return ".flv"
+	case len(header) > 188 && header[0] == 0x47 && header[188] == 0x47:
+		return ".ts"
+	case len(header) >= 2 && header[0] == 0xFF && header[1]&0xF6 == 0xF0:
+		return ".aac" // ADTS
+	case len(header) >= 2 && header[0] == 0xFF && header[1]&0xE0 == 0xE0:
+		return ".mp3" // 没有 ID3 标签的 MPEG 音频帧
+	}
+	return ""
+}
@@ -0,0 +1,93 @@
+package audio
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/ffmpeg"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSupportedFormats 测试按扩展名判断输入格式
+func TestSupportedFormats(t *testing.T) {
+	processor := &BatchProcessor{VideoExtensions: DefaultVideoExtensions, AudioExtensions: DefaultAudioExtensions}
+
+	for _, name := range []string{"a.MP3", "a.flac", "a.opus", "a.webm", "a.ts", "live.m3u8", "https://cdn.example.com/live/index.m3u8?token=1"} {
+		assert.True(t, processor.IsSupportedFile(name), name)
+	}
+	assert.False(t, processor.IsSupportedFile("notes.txt"))
+	assert.True(t, processor.isVideoFile("clip.WEBM"))
+	assert.False(t, processor.isVideoFile("live.m3u8"))
+
+	assert.False(t, needsTranscode("a.m4a"))
+	assert.True(t, needsTranscode("a.opus"))
+
+	assert.True(t, IsHLS("https://cdn.example.com/live/index.m3u8?token=1"))
+	assert.Equal(t, "index", mediaBaseName("https://cdn.example.com/live/index.m3u8?token=1"))
+	assert.Equal(t, "stream", mediaBaseName("https://cdn.example.com/"))
+	assert.Equal(t, "talk", mediaBaseName(filepath.Join("media", "talk.m3u8")))
+}
+
+// TestSniffExtension 测试按文件头判断标准输入的格式
+func TestSniffExtension(t *testing.T) {
+	ts := make([]byte, 200)
+	ts[0], ts[188] = 0x47, 0x47
+	cases := map[string][]byte{
+		".mp3":  []byte("ID3\x04\x00"),
+		".wav":  []byte("RIFF\x24\x00\x00\x00WAVEfmt "),
+		".flac": []byte("fLaC\x00"),
+		".ogg":  []byte("OggS\x00\x02"),
+		".m4a":  []byte("\x00\x00\x00\x20ftypM4A \x00"),
+		".mp4":  []byte("\x00\x00\x00\x18ftypisom"),
+		".mkv":  {0x1A, 0x45, 0xDF, 0xA3, 0x01},
+		".flv":  []byte("FLV\x01"),
+		".ts":   ts,
+		".aac":  {0xFF, 0xF1, 0x50},
+		"":      []byte("hello world"),
+	}
+	for ext, header := range cases {
+		assert.Equal(t, ext, SniffExtension(header), ext)
+	}
+	assert.Equal(t, ".mp3", SniffExtension([]byte{0xFF, 0xFB, 0x90}))
+	assert.Equal(t, "", SniffExtension(nil))
+}
+
+// TestExtractAudioFromHLS 测试 HLS 先下载拼接分片，再提取音频
+func TestExtractAudioFromHLS(t *testing.T) {
+	dir := t.TempDir()
+	var programs [][]string
+	extractor := &AudioExtractor{}
+	extractor.SetFFmpegRunner(ffmpeg.RunnerFunc(func(ctx context.Context, cmd *ffmpeg.Command) (string, error) {
+		programs = append(programs, cmd.Args)
+		if cmd.Program == "ffprobe" {
+			return "", ffmpeg.NewError(os.ErrNotExist, "")
+		}
+		output := cmd.Args[len(cmd.Args)-1]
+		if output == "-y" {
+			output = cmd.Args[len(cmd.Args)-2]
+		}
+		return "", os.WriteFile(output, []byte("media"), 0644)
+	}))
+	processor := &BatchProcessor{
+		OutputDir:       dir,
+		TempDir:         dir,
+		VideoExtensions: DefaultVideoExtensions,
+		AudioExtensions: DefaultAudioExtensions,
+		Extractor:       extractor,
+	}
+
+	result := processor.extractAudioFromFile(context.Background(), "https://cdn.example.com/live/index.m3u8")
+	require.NoError(t, result.Error)
+	assert.Equal(t, filepath.Join(dir, "index.mp3"), result.OutputPath)
+	assert.Contains(t, programs[0], "-protocol_whitelist")
+	assert.Contains(t, programs[0], "https://cdn.example.com/live/index.m3u8")
+	assert.Equal(t, "index.ts", filepath.Base(programs[len(programs)-1][1]))
+
+	// 下载的分片在提取后删除
+	leftovers, err := filepath.Glob(filepath.Join(dir, "hls-*"))
+	require.NoError(t, err)
+	assert.Empty(t, leftovers)
+}
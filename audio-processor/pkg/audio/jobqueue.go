@@ -0,0 +1,376 @@
+package audio
+
+import (
+	"container/heap"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/utils"
+)
+
+// queue_order 取值
+const (
+	QueueOrderScan     = "scan"     // 按扫描顺序
+	QueueOrderSmallest = "smallest" // 小文件优先
+	QueueOrderNewest   = "newest"   // 最近修改的文件优先
+)
+
+// ErrNotQueued 文件不在当前批次的等待队列中
+var ErrNotQueued = errors.New("文件不在等待队列中")
+
+// workerPool 处理槽位：限制同时运行的处理流程数量。上限可在运行中调整，
+// 暂停后正在处理的文件继续完成，但不再开始新的处理
+type workerPool struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	limit   int
+	running int
+	paused  bool
+}
+
+// newWorkerPool 创建处理槽位，limit 小于 1 时按 1 处理
+func newWorkerPool(limit int) *workerPool {
+	if limit < 1 {
+		limit = 1
+	}
+	pool := &workerPool{limit: limit}
+	pool.cond = sync.NewCond(&pool.mu)
+	return pool
+}
+
+// acquire 获取一个槽位，暂停或槽位已满时阻塞等待
+func (w *workerPool) acquire() {
+	w.mu.Lock()
+	for w.paused || w.running >= w.limit {
+		w.cond.Wait()
+	}
+	w.running++
+	w.mu.Unlock()
+}
+
+// release 释放槽位
+func (w *workerPool) release() {
+	w.mu.Lock()
+	w.running--
+	w.mu.Unlock()
+	w.cond.Broadcast()
+}
+
+// setLimit 调整并发上限。调小时已在运行的处理不受影响，完成后不再补充
+func (w *workerPool) setLimit(limit int) {
+	w.mu.Lock()
+	w.limit = limit
+	w.mu.Unlock()
+	w.cond.Broadcast()
+}
+
+// setPaused 暂停或恢复开始新的处理
+func (w *workerPool) setPaused(paused bool) {
+	w.mu.Lock()
+	w.paused = paused
+	w.mu.Unlock()
+	w.cond.Broadcast()
+}
+
+// batchJob 批处理等待队列中的文件
+type batchJob struct {
+	path    string
+	seq     int   // 扫描顺序
+	size    int64 // 文件大小，读取失败时为 0
+	modTime time.Time
+	pinned  int // 置顶顺序，0 表示未置顶
+	index   int // 在堆中的位置
+}
+
+// jobHeap 按置顶与排序方式排列的文件堆
+type jobHeap struct {
+	jobs  []*batchJob
+	order string
+}
+
+func (h *jobHeap) Len() int { return len(h.jobs) }
+
+func (h *jobHeap) Less(i, j int) bool {
+	a, b := h.jobs[i], h.jobs[j]
+	// 置顶的文件优先，先置顶的先处理
+	if (a.pinned > 0) != (b.pinned > 0) {
+		return a.pinned > 0
+	}
+	if a.pinned != b.pinned {
+		return a.pinned < b.pinned
+	}
+	switch h.order {
+	case QueueOrderSmallest:
+		if a.size != b.size {
+			return a.size < b.size
+		}
+	case QueueOrderNewest:
+		if !a.modTime.Equal(b.modTime) {
+			return a.modTime.After(b.modTime)
+		}
+	}
+	return a.seq < b.seq
+}
+
+func (h *jobHeap) Swap(i, j int) {
+	h.jobs[i], h.jobs[j] = h.jobs[j], h.jobs[i]
+	h.jobs[i].index = i
+	h.jobs[j].index = j
+}
+
+func (h *jobHeap) Push(x interface{}) {
+	job := x.(*batchJob)
+	job.index = len(h.jobs)
+	h.jobs = append(h.jobs, job)
+}
+
+func (h *jobHeap) Pop() interface{} {
+	old := h.jobs
+	job := old[len(old)-1]
+	old[len(old)-1] = nil
+	h.jobs = old[:len(old)-1]
+	job.index = -1
+	return job
+}
+
+// jobQueue 批处理的等待队列，处理槽位空闲时取出优先级最高的文件
+type jobQueue struct {
+	mu     sync.Mutex
+	heap   jobHeap
+	byPath map[string]*batchJob
+	pinSeq int
+}
+
+// newJobQueue 按排序方式创建等待队列，文件名或路径匹配 pinned 中任一模式的文件置顶
+func newJobQueue(files []string, order string, pinned []string) *jobQueue {
+	q := &jobQueue{
+		heap:   jobHeap{order: order},
+		byPath: make(map[string]*batchJob, len(files)),
+	}
+	for i, path := range files {
+		job := &batchJob{path: path, seq: i}
+		if info, err := os.Stat(path); err == nil {
+			job.size = info.Size()
+			job.modTime = info.ModTime()
+		}
+		if matchesAny(path, pinned) {
+			q.pinSeq++
+			job.pinned = q.pinSeq
+		}
+		q.byPath[filepath.Clean(path)] = job
+		q.heap.Push(job)
+	}
+	heap.Init(&q.heap)
+	return q
+}
+
+// matchesAny 判断文件名或完整路径是否匹配任一通配符模式
+func matchesAny(path string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := filepath.Match(pattern, filepath.Base(path)); ok {
+			return true
+		}
+		if ok, _ := filepath.Match(pattern, path); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// pop 取出优先级最高的文件，队列为空时返回 false
+func (q *jobQueue) pop() (*batchJob, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.heap.Len() == 0 {
+		return nil, false
+	}
+	job := heap.Pop(&q.heap).(*batchJob)
+	delete(q.byPath, filepath.Clean(job.path))
+	return job, true
+}
+
+// pin 将等待中的文件置顶，排在已置顶的文件之后
+func (q *jobQueue) pin(path string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	job, exists := q.byPath[filepath.Clean(path)]
+	if !exists {
+		return ErrNotQueued
+	}
+	if job.pinned > 0 {
+		return nil
+	}
+	q.pinSeq++
+	job.pinned = q.pinSeq
+	heap.Fix(&q.heap, job.index)
+	return nil
+}
+
+// pending 按处理顺序返回等待中的文件
+func (q *jobQueue) pending() []string {
+	q.mu.Lock()
+	jobs := make([]*batchJob, len(q.heap.jobs))
+	copy(jobs, q.heap.jobs)
+	q.mu.Unlock()
+
+	sorted := &jobHeap{jobs: jobs, order: q.heap.order}
+	sort.Slice(jobs, sorted.Less)
+	paths := make([]string, len(jobs))
+	for i, job := range jobs {
+		paths[i] = job.path
+	}
+	return paths
+}
+
+// BatchQueueStatus 批处理队列的状态
+type BatchQueueStatus struct {
+	Paused      bool     `json:"paused"`
+	Concurrency int      `json:"concurrency"`
+	Running     int      `json:"running"`
+	Pending     []string `json:"pending"` // 按处理顺序排列的等待中文件，没有进行中的批处理时为空
+}
+
+// workerPool 返回批处理与监听模式共享的处理槽位
+func (p *BatchProcessor) workerPool() *workerPool {
+	p.workerOnce.Do(func() {
+		p.workers = newWorkerPool(p.MaxConcurrency)
+	})
+	return p.workers
+}
+
+// queueOrder 返回配置的批处理排序方式
+func (p *BatchProcessor) queueOrder() string {
+	if p.config == nil || p.config.QueueOrder == "" {
+		return QueueOrderScan
+	}
+	return p.config.QueueOrder
+}
+
+// queuePinned 返回配置中需要置顶的文件模式
+func (p *BatchProcessor) queuePinned() []string {
+	if p.config == nil {
+		return nil
+	}
+	return p.config.QueuePinned
+}
+
+// setBatchQueue 设置当前批次的等待队列，批处理结束时设为 nil
+func (p *BatchProcessor) setBatchQueue(queue *jobQueue) {
+	p.queueMu.Lock()
+	p.queue = queue
+	p.queueMu.Unlock()
+}
+
+// PauseBatch 暂停处理：正在处理的文件继续完成，不再开始新的文件（包括监听模式触发的处理）
+func (p *BatchProcessor) PauseBatch() {
+	p.workerPool().setPaused(true)
+	utils.Info("已暂停处理，正在处理的文件完成后不再开始新的文件")
+}
+
+// ResumeBatch 恢复暂停的处理
+func (p *BatchProcessor) ResumeBatch() {
+	p.workerPool().setPaused(false)
+	utils.Info("已恢复处理")
+}
+
+// SetConcurrency 调整同时处理的文件数量，运行中调整立即生效
+func (p *BatchProcessor) SetConcurrency(limit int) error {
+	if limit < 1 || limit > 64 {
+		return fmt.Errorf("并发数必须在1-64之间: %d", limit)
+	}
+	p.workerPool().setLimit(limit)
+	utils.Info("并发数已调整为 %d", limit)
+	return nil
+}
+
+// PinFile 将当前批次中等待处理的文件置顶，下一个空闲槽位优先处理
+func (p *BatchProcessor) PinFile(path string) error {
+	p.queueMu.Lock()
+	queue := p.queue
+	p.queueMu.Unlock()
+	if queue == nil {
+		return ErrNotQueued
+	}
+	if err := queue.pin(path); err != nil {
+		return err
+	}
+	utils.Info("已置顶: %s", filepath.Base(path))
+	return nil
+}
+
+// QueueStatus 返回处理槽位与当前批次等待队列的状态
+func (p *BatchProcessor) QueueStatus() BatchQueueStatus {
+	pool := p.workerPool()
+	pool.mu.Lock()
+	status := BatchQueueStatus{
+		Paused:      pool.paused,
+		Concurrency: pool.limit,
+		Running:     pool.running,
+	}
+	pool.mu.Unlock()
+
+	p.queueMu.Lock()
+	queue := p.queue
+	p.queueMu.Unlock()
+	if queue != nil {
+		status.Pending = queue.pending()
+	}
+	return status
+}
+
+// batchControlRequest 批处理控制请求，字段按需填写
+type batchControlRequest struct {
+	Action      string `json:"action"`      // pause 或 resume
+	Concurrency int    `json:"concurrency"` // 大于 0 时调整并发数
+	Pin         string `json:"pin"`         // 置顶的文件路径
+}
+
+// BatchControlHandler 返回批处理控制接口：GET 查询队列状态，
+// POST {"action":"pause|resume","concurrency":N,"pin":"路径"} 暂停/恢复、调整并发数或置顶文件
+func BatchControlHandler(p *BatchProcessor) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPost:
+			var req batchControlRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				http.Error(w, fmt.Sprintf("解析请求失败: %v", err), http.StatusBadRequest)
+				return
+			}
+			switch req.Action {
+			case "":
+			case "pause":
+				p.PauseBatch()
+			case "resume":
+				p.ResumeBatch()
+			default:
+				http.Error(w, fmt.Sprintf("未知操作: %s", req.Action), http.StatusBadRequest)
+				return
+			}
+			if req.Concurrency != 0 {
+				if err := p.SetConcurrency(req.Concurrency); err != nil {
+					http.Error(w, err.Error(), http.StatusBadRequest)
+					return
+				}
+			}
+			if req.Pin != "" {
+				if err := p.PinFile(req.Pin); err != nil {
+					http.Error(w, err.Error(), http.StatusNotFound)
+					return
+				}
+			}
+		default:
+			http.Error(w, "仅支持GET与POST请求", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		json.NewEncoder(w).Encode(p.QueueStatus())
+	})
+}
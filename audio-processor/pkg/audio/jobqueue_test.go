@@ -0,0 +1,122 @@
+package audio
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeQueueFiles 在临时目录创建指定大小的文件，修改时间依次递增
+func writeQueueFiles(t *testing.T, sizes map[string]int) []string {
+	t.Helper()
+	dir := t.TempDir()
+	base := time.Now().Add(-time.Hour)
+	var files []string
+	for i, name := range []string{"a.mp4", "b.mp4", "c.mp4"} {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, make([]byte, sizes[name]), 0644))
+		modTime := base.Add(time.Duration(i) * time.Minute)
+		require.NoError(t, os.Chtimes(path, modTime, modTime))
+		files = append(files, path)
+	}
+	return files
+}
+
+// drain 按处理顺序取出队列中的全部文件名
+func drain(q *jobQueue) []string {
+	var names []string
+	for {
+		job, ok := q.pop()
+		if !ok {
+			return names
+		}
+		names = append(names, filepath.Base(job.path))
+	}
+}
+
+// TestJobQueueOrder 测试各排序方式与置顶
+func TestJobQueueOrder(t *testing.T) {
+	files := writeQueueFiles(t, map[string]int{"a.mp4": 30, "b.mp4": 10, "c.mp4": 20})
+
+	assert.Equal(t, []string{"a.mp4", "b.mp4", "c.mp4"}, drain(newJobQueue(files, QueueOrderScan, nil)))
+	assert.Equal(t, []string{"b.mp4", "c.mp4", "a.mp4"}, drain(newJobQueue(files, QueueOrderSmallest, nil)))
+	assert.Equal(t, []string{"c.mp4", "b.mp4", "a.mp4"}, drain(newJobQueue(files, QueueOrderNewest, nil)))
+	assert.Equal(t, []string{"a.mp4", "b.mp4", "c.mp4"}, drain(newJobQueue(files, QueueOrderSmallest, []string{"a*"})))
+
+	q := newJobQueue(files, QueueOrderSmallest, nil)
+	require.NoError(t, q.pin(files[2]))
+	require.NoError(t, q.pin(files[0]))
+	assert.Equal(t, []string{files[2], files[0], files[1]}, q.pending())
+	assert.Equal(t, []string{"c.mp4", "a.mp4", "b.mp4"}, drain(q))
+	assert.ErrorIs(t, q.pin(files[0]), ErrNotQueued)
+}
+
+// TestWorkerPool 测试暂停、恢复与运行中调整并发上限
+func TestWorkerPool(t *testing.T) {
+	pool := newWorkerPool(1)
+	pool.acquire()
+
+	var acquired int32
+	go func() {
+		pool.acquire()
+		atomic.AddInt32(&acquired, 1)
+	}()
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, int32(0), atomic.LoadInt32(&acquired), "槽位已满时应等待")
+
+	pool.setLimit(2)
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(&acquired) == 1 }, time.Second, 5*time.Millisecond)
+
+	pool.release()
+	pool.setPaused(true)
+	go func() {
+		pool.acquire()
+		atomic.AddInt32(&acquired, 1)
+	}()
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&acquired), "暂停时不应开始新的处理")
+
+	pool.setPaused(false)
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(&acquired) == 2 }, time.Second, 5*time.Millisecond)
+}
+
+// TestBatchControlHandler 测试通过接口暂停处理、调整并发数与置顶文件
+func TestBatchControlHandler(t *testing.T) {
+	files := writeQueueFiles(t, map[string]int{"a.mp4": 1, "b.mp4": 1, "c.mp4": 1})
+	processor := &BatchProcessor{MaxConcurrency: 2}
+	processor.setBatchQueue(newJobQueue(files, QueueOrderScan, nil))
+	handler := BatchControlHandler(processor)
+
+	post := func(body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/batch", strings.NewReader(body)))
+		return rec
+	}
+
+	rec := post(`{"action":"pause","concurrency":3,"pin":"` + filepath.ToSlash(files[2]) + `"}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	status := processor.QueueStatus()
+	assert.True(t, status.Paused)
+	assert.Equal(t, 3, status.Concurrency)
+	assert.Equal(t, []string{files[2], files[0], files[1]}, status.Pending)
+
+	assert.Equal(t, http.StatusBadRequest, post(`{"concurrency":-1}`).Code)
+	assert.Equal(t, http.StatusBadRequest, post(`{"action":"stop"}`).Code)
+	assert.Equal(t, http.StatusNotFound, post(`{"pin":"/none.mp4"}`).Code)
+
+	assert.Equal(t, http.StatusOK, post(`{"action":"resume"}`).Code)
+	assert.False(t, processor.QueueStatus().Paused)
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/batch", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"concurrency":3`)
+}
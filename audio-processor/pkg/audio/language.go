@@ -0,0 +1,39 @@
+package audio
+
+import (
+	"context"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/asr"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/langdetect"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/utils"
+)
+
+// SetLanguageDetector 设置语言检测器，nil 表示不检测
+func (p *BatchProcessor) SetLanguageDetector(detector langdetect.Detector) {
+	p.LanguageDetector = detector
+}
+
+// withAudioLanguage 确定音频语言并写入上下文，供ASR服务路由与结果导出使用。
+// 配置指定了语言时直接使用；设置为 auto 且启用了检测时截取音频样本进行检测；
+// 检测失败时不限制语言，由服务自行处理。tempDir 用于存放截取的样本。
+func (p *BatchProcessor) withAudioLanguage(ctx context.Context, audioPath, tempDir string) context.Context {
+	if p.config.Language != langdetect.AutoLanguage {
+		return asr.WithLanguage(ctx, langdetect.Normalize(p.config.Language))
+	}
+	if p.LanguageDetector == nil {
+		return ctx
+	}
+
+	result, err := langdetect.DetectFile(ctx, p.LanguageDetector, audioPath, tempDir, p.config.LanguageSampleSeconds)
+	if err != nil {
+		utils.Warn("语言检测失败，将不按语言选择ASR服务: %v", err)
+		return ctx
+	}
+
+	if result.Probability > 0 {
+		utils.Info("检测到音频语言: %s (置信度 %.0f%%)", result.Language, result.Probability*100)
+	} else {
+		utils.Info("检测到音频语言: %s", result.Language)
+	}
+	return asr.WithLanguage(ctx, result.Language)
+}
@@ -0,0 +1,181 @@
+package audio
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/ffmpeg"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/utils"
+)
+
+// StatusMusic 音频以音乐为主且 music_gate 为 skip 时的结果状态，此时不调用ASR
+const StatusMusic = "音乐"
+
+// music_gate 取值
+const (
+	MusicGateOff  = "off"  // 不检测
+	MusicGateWarn = "warn" // 检测到音乐时警告，仍然识别
+	MusicGateSkip = "skip" // 检测到音乐时跳过识别
+)
+
+// 采样分析参数：在音频的开头、中间、结尾附近各取一段，按 20ms 分帧
+const (
+	musicSampleRate    = 16000
+	musicFrameSize     = musicSampleRate / 50
+	musicWindowSeconds = 20
+	musicWindows       = 3
+)
+
+// 判定为音乐的阈值
+const (
+	musicMaxLowEnergyRatio = 0.2
+	musicMaxZCRVariation   = 0.8
+)
+
+// AudioFeatures 用于区分语音与音乐的特征
+type AudioFeatures struct {
+	LowEnergyRatio float64 // 能量低于平均值一半的帧所占比例，语音因音节间停顿而偏高
+	ZCRVariation   float64 // 各帧过零率的变异系数，语音因清浊音交替而偏高
+}
+
+// IsMusic 判断音频是否以音乐为主：能量连续且过零率稳定
+func (f AudioFeatures) IsMusic() bool {
+	return f.LowEnergyRatio < musicMaxLowEnergyRatio && f.ZCRVariation < musicMaxZCRVariation
+}
+
+// String 返回特征的可读描述
+func (f AudioFeatures) String() string {
+	return fmt.Sprintf("低能量帧 %.0f%%，过零率变异 %.2f", f.LowEnergyRatio*100, f.ZCRVariation)
+}
+
+// ClassifyAudio 抽取若干段音频计算语音/音乐特征，各段结果按帧数加权平均
+func (e *AudioExtractor) ClassifyAudio(ctx context.Context, path string) (AudioFeatures, error) {
+	duration, err := e.getAudioDuration(ctx, path)
+	if err != nil {
+		return AudioFeatures{}, fmt.Errorf("获取音频时长失败: %w", err)
+	}
+
+	// 较短的音频整段分析
+	starts := []int{0}
+	length := duration + 1
+	if duration > musicWindowSeconds*musicWindows {
+		starts = starts[:0]
+		length = musicWindowSeconds
+		for i := 0; i < musicWindows; i++ {
+			starts = append(starts, (duration-musicWindowSeconds)*(2*i+1)/(2*musicWindows))
+		}
+	}
+
+	var total AudioFeatures
+	frames := 0
+	for _, start := range starts {
+		samples, err := e.readPCM(ctx, path, start, length)
+		if err != nil {
+			return AudioFeatures{}, err
+		}
+		features, n := analyzeSamples(samples)
+		total.LowEnergyRatio += features.LowEnergyRatio * float64(n)
+		total.ZCRVariation += features.ZCRVariation * float64(n)
+		frames += n
+	}
+	if frames == 0 {
+		return AudioFeatures{}, fmt.Errorf("音频过短，无法分析")
+	}
+	total.LowEnergyRatio /= float64(frames)
+	total.ZCRVariation /= float64(frames)
+	return total, nil
+}
+
+// readPCM 解码从 start 秒开始、最长 length 秒的音频为 16kHz 单声道 PCM
+func (e *AudioExtractor) readPCM(ctx context.Context, path string, start, length int) ([]int16, error) {
+	var output bytes.Buffer
+	cmd := ffmpeg.DecodePCM(path, float64(start), float64(length), musicSampleRate, &output)
+	if err := e.runFFmpeg(ctx, cmd); err != nil {
+		return nil, fmt.Errorf("解码音频失败: %w", err)
+	}
+
+	samples := make([]int16, output.Len()/2)
+	if err := binary.Read(&output, binary.LittleEndian, samples); err != nil {
+		return nil, fmt.Errorf("读取音频数据失败: %w", err)
+	}
+	return samples, nil
+}
+
+// analyzeSamples 按帧计算能量与过零率，返回特征与参与计算的帧数
+func analyzeSamples(samples []int16) (AudioFeatures, int) {
+	n := len(samples) / musicFrameSize
+	if n == 0 {
+		return AudioFeatures{}, 0
+	}
+
+	energies := make([]float64, n)
+	zcrs := make([]float64, n)
+	var meanEnergy, meanZCR float64
+	for i := 0; i < n; i++ {
+		frame := samples[i*musicFrameSize : (i+1)*musicFrameSize]
+		var sum float64
+		crossings := 0
+		for j, sample := range frame {
+			v := float64(sample)
+			sum += v * v
+			if j > 0 && (sample >= 0) != (frame[j-1] >= 0) {
+				crossings++
+			}
+		}
+		energies[i] = math.Sqrt(sum / float64(len(frame)))
+		zcrs[i] = float64(crossings) / float64(len(frame))
+		meanEnergy += energies[i]
+		meanZCR += zcrs[i]
+	}
+	meanEnergy /= float64(n)
+	meanZCR /= float64(n)
+
+	var features AudioFeatures
+	if meanEnergy == 0 {
+		// 完全静音，无法区分
+		return features, 0
+	}
+	low := 0
+	var variance float64
+	for i := 0; i < n; i++ {
+		if energies[i] < meanEnergy/2 {
+			low++
+		}
+		variance += (zcrs[i] - meanZCR) * (zcrs[i] - meanZCR)
+	}
+	features.LowEnergyRatio = float64(low) / float64(n)
+	if meanZCR > 0 {
+		features.ZCRVariation = math.Sqrt(variance/float64(n)) / meanZCR
+	}
+	return features, n
+}
+
+// checkMusic 按 music_gate 检测提取出的音频是否以音乐为主。
+// 需要跳过识别时将结果标记为 StatusMusic 并返回 true，检测失败时按正常流程识别。
+func (p *BatchProcessor) checkMusic(ctx context.Context, result *BatchResult) bool {
+	if p.config == nil || p.config.MusicGate == "" || p.config.MusicGate == MusicGateOff {
+		return false
+	}
+
+	features, err := p.Extractor.ClassifyAudio(ctx, result.OutputPath)
+	if err != nil {
+		utils.Warn("语音/音乐检测失败 %s: %v", result.FilePath, err)
+		return false
+	}
+	result.log.Printf("语音/音乐检测: %s", features)
+	if !features.IsMusic() {
+		return false
+	}
+
+	if p.config.MusicGate != MusicGateSkip {
+		utils.Warn("文件 %s 以音乐为主（%s），识别结果可能不可用", result.FilePath, features)
+		return false
+	}
+	utils.Info("跳过以音乐为主的文件 %s（%s）", result.FilePath, features)
+	result.Status = StatusMusic
+	p.reportFileProgress(result.FilePath, StageExtract, 100, StatusMusic)
+	return true
+}
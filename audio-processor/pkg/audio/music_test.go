@@ -0,0 +1,49 @@
+package audio
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// tone 生成指定频率与时长的正弦波
+func tone(freq float64, frames int, amplitude float64) []int16 {
+	samples := make([]int16, frames*musicFrameSize)
+	for i := range samples {
+		samples[i] = int16(amplitude * math.Sin(2*math.Pi*freq*float64(i)/musicSampleRate))
+	}
+	return samples
+}
+
+// TestAnalyzeSamplesMusic 测试持续稳定的音调被判定为音乐
+func TestAnalyzeSamplesMusic(t *testing.T) {
+	var samples []int16
+	for i := 0; i < 20; i++ {
+		samples = append(samples, tone(440+float64(i%4)*55, 10, 8000)...)
+	}
+
+	features, frames := analyzeSamples(samples)
+	assert.Equal(t, 200, frames)
+	assert.True(t, features.IsMusic(), features.String())
+}
+
+// TestAnalyzeSamplesSpeech 测试带停顿、清浊音交替的信号被判定为语音
+func TestAnalyzeSamplesSpeech(t *testing.T) {
+	var samples []int16
+	for i := 0; i < 20; i++ {
+		samples = append(samples, tone(150, 8, 8000)...)  // 浊音
+		samples = append(samples, tone(3000, 3, 2000)...) // 清音
+		samples = append(samples, tone(100, 6, 50)...)    // 停顿
+	}
+
+	features, _ := analyzeSamples(samples)
+	assert.False(t, features.IsMusic(), features.String())
+	assert.Greater(t, features.LowEnergyRatio, musicMaxLowEnergyRatio)
+}
+
+// TestAnalyzeSamplesSilence 测试静音不参与判定
+func TestAnalyzeSamplesSilence(t *testing.T) {
+	_, frames := analyzeSamples(make([]int16, 10*musicFrameSize))
+	assert.Equal(t, 0, frames)
+}
@@ -0,0 +1,106 @@
+package audio
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/audit"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/ffmpeg"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/models"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/utils"
+)
+
+// 软字幕封装的容器
+const (
+	muxMP4 = ".mp4"
+	muxMKV = ".mkv"
+)
+
+// muxSubtitles 将生成的字幕作为软字幕轨道封装进源视频（不重新编码），
+// 输出到输出目录下以文件名命名的子目录中，返回生成的 *_subbed 文件路径。
+// 源视频编码无法放入 MP4 时改用 MKV。
+func (p *BatchProcessor) muxSubtitles(ctx context.Context, videoPath string, outputFiles map[string]string, config *models.Config) (string, error) {
+	container := muxMP4
+	if strings.ToLower(filepath.Ext(videoPath)) == muxMKV {
+		container = muxMKV
+	}
+
+	subtitle := muxSubtitleFile(outputFiles, container)
+	if subtitle == "" {
+		return "", fmt.Errorf("没有可封装的SRT或ASS字幕")
+	}
+
+	path, err := p.muxInto(ctx, videoPath, subtitle, container, config.OutputFolder)
+	if err != nil && container == muxMP4 {
+		utils.Warn("封装为MP4失败，改用MKV: %v", err)
+		container = muxMKV
+		path, err = p.muxInto(ctx, videoPath, muxSubtitleFile(outputFiles, container), container, config.OutputFolder)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	utils.Info("已封装软字幕: %s", filepath.Base(path))
+	return path, nil
+}
+
+// muxInto 先写入同目录下的临时文件，完成后再替换为最终文件
+func (p *BatchProcessor) muxInto(ctx context.Context, videoPath, subtitle, container, outputFolder string) (string, error) {
+	path := subbedPath(outputFolder, videoPath, container)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", fmt.Errorf("创建输出目录失败: %w", err)
+	}
+	tmpPath := filepath.Join(filepath.Dir(path), "."+filepath.Base(path)+".muxing.tmp")
+	defer os.Remove(tmpPath)
+
+	if err := p.Extractor.runFFmpeg(ctx, ffmpeg.FFmpeg(muxArgs(videoPath, subtitle, container, tmpPath)...)); err != nil {
+		return "", fmt.Errorf("封装字幕失败: %w", err)
+	}
+
+	audit.RecordOverwrite(path, "export", "重新封装软字幕")
+	if err := os.Rename(tmpPath, path); err != nil {
+		return "", fmt.Errorf("保存封装文件失败: %w", err)
+	}
+	return path, nil
+}
+
+// muxSubtitleFile 选择要封装的字幕：MP4 的 mov_text 不支持样式，优先使用 SRT；MKV 优先保留 ASS 样式
+func muxSubtitleFile(outputFiles map[string]string, container string) string {
+	order := []string{"srt", "ass"}
+	if container == muxMKV {
+		order = []string{"ass", "srt"}
+	}
+	for _, fileType := range order {
+		if path := outputFiles[fileType]; path != "" {
+			return path
+		}
+	}
+	return ""
+}
+
+// subbedPath 返回封装文件的路径: <输出目录>/<文件名>/<文件名>_subbed.<容器>
+func subbedPath(outputFolder, videoPath, container string) string {
+	baseName := filepath.Base(videoPath)
+	baseName = strings.TrimSuffix(baseName, filepath.Ext(baseName))
+	return filepath.Join(outputFolder, baseName, baseName+"_subbed"+container)
+}
+
+// muxArgs 返回封装的 ffmpeg 参数：音视频直接复制，字幕作为新轨道加入
+func muxArgs(videoPath, subtitle, container, output string) []string {
+	args := []string{
+		"-y", "-v", "error",
+		"-i", videoPath,
+		"-i", subtitle,
+		"-map", "0:v?", "-map", "0:a?", "-map", "1:0",
+		"-c", "copy",
+	}
+	if container == muxMP4 {
+		args = append(args, "-c:s", "mov_text", "-movflags", "+faststart", "-f", "mp4")
+	} else {
+		args = append(args, "-c:s", "copy", "-f", "matroska")
+	}
+	return append(args, "-disposition:s:0", "default", output)
+}
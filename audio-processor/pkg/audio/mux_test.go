@@ -0,0 +1,32 @@
+package audio
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestMuxSubtitleFile 测试MP4优先封装SRT，MKV优先保留ASS样式
+func TestMuxSubtitleFile(t *testing.T) {
+	outputs := map[string]string{"srt": "demo.srt", "ass": "demo.ass", "txt": "demo.txt"}
+	assert.Equal(t, "demo.srt", muxSubtitleFile(outputs, muxMP4))
+	assert.Equal(t, "demo.ass", muxSubtitleFile(outputs, muxMKV))
+	assert.Equal(t, "demo.ass", muxSubtitleFile(map[string]string{"ass": "demo.ass"}, muxMP4))
+	assert.Empty(t, muxSubtitleFile(map[string]string{"txt": "demo.txt"}, muxMP4))
+}
+
+// TestMuxArgs 测试封装参数只复制音视频并按容器选择字幕编码
+func TestMuxArgs(t *testing.T) {
+	assert.Equal(t, filepath.Join("out", "demo", "demo_subbed.mp4"), subbedPath("out", filepath.Join("media", "demo.mov"), muxMP4))
+
+	args := muxArgs("demo.mov", "demo.srt", muxMP4, "out.tmp")
+	assert.Contains(t, args, "mov_text")
+	assert.Contains(t, args, "copy")
+	assert.NotContains(t, args, "libx264")
+	assert.Equal(t, "out.tmp", args[len(args)-1])
+
+	args = muxArgs("demo.mkv", "demo.ass", muxMKV, "out.tmp")
+	assert.NotContains(t, args, "mov_text")
+	assert.Contains(t, args, "matroska")
+}
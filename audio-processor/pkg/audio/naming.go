@@ -0,0 +1,20 @@
+package audio
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"path/filepath"
+	"strings"
+)
+
+// FileTag 返回文件的唯一标识："<文件名>_<路径哈希>"，用于临时片段命名与进度条ID。
+// 不同目录下的同名文件得到不同的标识，并发处理时不会互相覆盖。
+func FileTag(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	sum := sha1.Sum([]byte(path))
+	filename := filepath.Base(path)
+	baseName := strings.TrimSuffix(filename, filepath.Ext(filename))
+	return baseName + "_" + hex.EncodeToString(sum[:4])
+}
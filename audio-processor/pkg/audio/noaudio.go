@@ -0,0 +1,86 @@
+package audio
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/ffmpeg"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/utils"
+)
+
+// StatusNoAudio 文件不包含音频流或音频近乎静音时的结果状态，此时不提取也不识别
+const StatusNoAudio = "无音频"
+
+// volumedetect 滤镜输出中的最大音量
+var maxVolumePattern = regexp.MustCompile(`max_volume:\s*(-?(?:inf|[0-9.]+))\s*dB`)
+
+// hasAudioStream 使用 ffprobe 判断文件是否包含音频流
+func (e *AudioExtractor) hasAudioStream(ctx context.Context, path string) (bool, error) {
+	return ffmpeg.HasAudioStream(ctx, e.ffmpegRunner(), path)
+}
+
+// maxVolume 使用 volumedetect 滤镜获取第一条音频流的最大音量（dB），完全静音时为负无穷
+func (e *AudioExtractor) maxVolume(ctx context.Context, path string) (float64, error) {
+	stderr, err := e.ffmpegRunner().Run(ctx, ffmpeg.VolumeDetect(path))
+	if err != nil {
+		return 0, err
+	}
+	return parseMaxVolume(stderr)
+}
+
+// parseMaxVolume 从 volumedetect 的输出中解析最大音量
+func parseMaxVolume(output string) (float64, error) {
+	match := maxVolumePattern.FindStringSubmatch(output)
+	if match == nil {
+		return 0, fmt.Errorf("未找到音量统计")
+	}
+	return strconv.ParseFloat(match[1], 64)
+}
+
+// detectNoAudio 检查文件是否不包含音频流或近乎静音，返回原因。
+// 未启用检测、文件有声音或检测失败时返回空字符串，检测失败时按正常流程处理。
+func (p *BatchProcessor) detectNoAudio(ctx context.Context, filePath string) string {
+	if p.config == nil || !p.config.SkipNoAudio {
+		return ""
+	}
+	// 检测 HLS 需要下载全部分片，留给提取后的识别阶段处理
+	if IsHLS(filePath) {
+		return ""
+	}
+
+	hasAudio, err := p.Extractor.hasAudioStream(ctx, filePath)
+	if err != nil {
+		utils.Warn("检测音频流失败 %s: %v", filePath, err)
+		return ""
+	}
+	if !hasAudio {
+		return "不包含音频流"
+	}
+
+	volume, err := p.Extractor.maxVolume(ctx, filePath)
+	if err != nil {
+		utils.Warn("检测音量失败 %s: %v", filePath, err)
+		return ""
+	}
+	if volume <= p.config.SilenceThresholdDB {
+		if math.IsInf(volume, -1) {
+			return "音频完全静音"
+		}
+		return fmt.Sprintf("音频近乎静音（最大音量 %.1f dB）", volume)
+	}
+	return ""
+}
+
+// noAudioResult 返回无音频文件的处理结果：视为已完成，不提取也不识别
+func (p *BatchProcessor) noAudioResult(filePath, reason string) BatchResult {
+	utils.Info("跳过无音频文件 %s: %s", filePath, reason)
+	p.reportFileProgress(filePath, StageProbe, 100, StatusNoAudio)
+	return BatchResult{
+		FilePath: filePath,
+		Success:  true,
+		Status:   StatusNoAudio,
+	}
+}
@@ -0,0 +1,61 @@
+package audio
+
+import (
+	"context"
+	"math"
+	"path/filepath"
+	"testing"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParseMaxVolume 测试解析 volumedetect 输出
+func TestParseMaxVolume(t *testing.T) {
+	output := "[Parsed_volumedetect_0 @ 0x1] n_samples: 441000\n" +
+		"[Parsed_volumedetect_0 @ 0x1] mean_volume: -35.2 dB\n" +
+		"[Parsed_volumedetect_0 @ 0x1] max_volume: -12.5 dB\n"
+	volume, err := parseMaxVolume(output)
+	require.NoError(t, err)
+	assert.Equal(t, -12.5, volume)
+
+	volume, err = parseMaxVolume("[Parsed_volumedetect_0 @ 0x1] max_volume: -inf dB\n")
+	require.NoError(t, err)
+	assert.True(t, math.IsInf(volume, -1))
+
+	_, err = parseMaxVolume("no statistics")
+	assert.Error(t, err)
+}
+
+// TestNoAudioResultMarksCompleted 测试无音频文件被记录为已完成
+func TestNoAudioResultMarksCompleted(t *testing.T) {
+	outputDir := t.TempDir()
+	config := models.NewDefaultConfig()
+	config.SkipNoAudio = false
+	processor := &BatchProcessor{
+		OutputDir:           outputDir,
+		config:              config,
+		processedRecordFile: filepath.Join(outputDir, "processed_records.json"),
+		processedRecords:    make(map[string]ProcessedRecord),
+	}
+	source := filepath.Join("media", "silent.mp4")
+
+	// 未启用检测时不调用 ffprobe
+	assert.Equal(t, "", processor.detectNoAudio(context.Background(), source))
+
+	result := processor.noAudioResult(source, "不包含音频流")
+	assert.True(t, result.Success)
+	assert.Equal(t, StatusNoAudio, result.Status)
+
+	processor.updateProcessedRecord(source, &result)
+	assert.True(t, processor.IsRecognizedFile(source))
+
+	// 重新加载后保留状态
+	reloaded := &BatchProcessor{
+		processedRecordFile: processor.processedRecordFile,
+		processedRecords:    make(map[string]ProcessedRecord),
+	}
+	reloaded.loadProcessedRecords()
+	assert.Equal(t, StatusNoAudio, reloaded.processedRecords[filepath.Clean(source)].Status)
+}
@@ -0,0 +1,584 @@
+package audio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/asr"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/export"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/models"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/tempdir"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/usage"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/utils"
+)
+
+// 内置阶段，按执行顺序排列
+const (
+	StageProbe       = "probe"       // 检查是否无音频、是否与已识别的文件重复
+	StageExtract     = "extract"     // 提取音频，并按 music_gate 检测音乐
+	StageNormalize   = "normalize"   // 音频预处理（响度归一化、滤波、降噪），自定义的处理命令通常插入在此之后
+	StageSplit       = "split"       // 检查音频并获取时长，决定是否分段识别
+	StageASR         = "asr"         // 语音识别
+	StagePostProcess = "postprocess" // 检查识别结果并记录服务与使用量
+	StageExport      = "export"      // 导出字幕与文稿，按配置封装软字幕
+	StageCleanup     = "cleanup"     // 清理提取的音频
+)
+
+// Stage 处理流水线中的一个阶段。返回错误时流水线停止，未设置失败原因的错误按识别失败记录
+type Stage interface {
+	Name() string
+	Run(job *FileJob) error
+}
+
+// StageFunc 以函数实现的阶段
+type StageFunc struct {
+	StageName string
+	Fn        func(job *FileJob) error
+}
+
+// Name 返回阶段名称
+func (s StageFunc) Name() string { return s.StageName }
+
+// Run 执行阶段
+func (s StageFunc) Run(job *FileJob) error { return s.Fn(job) }
+
+// FileJob 一个文件在流水线中的处理状态
+type FileJob struct {
+	Processor *BatchProcessor
+	Result    *BatchResult
+	// AudioPath 送去识别的音频。初始为提取出的音频（Result.OutputPath），自定义阶段可以替换为处理后的文件；
+	// 导出的文件仍按 Result.OutputPath 命名
+	AudioPath    string
+	Duration     int  // 音频时长（秒），获取失败时为 0
+	Chunked      bool // 是否分段识别
+	Service      string
+	Segments     []models.DataSegment
+	OutputFiles  map[string]string
+	ExportConfig *models.Config
+	Ctx          context.Context // 本文件的上下文，取消时终止正在执行的命令与请求，识别阶段起附带超时与语言
+	TempJob      *tempdir.Job    // 本次识别独占的临时目录
+	// Done 为 true 时跳过后续阶段，如无音频、重复或以音乐为主的文件
+	Done bool
+
+	barID    string
+	deferred []func()
+}
+
+// Context 返回本文件的上下文，未设置时返回 context.Background()
+func (j *FileJob) Context() context.Context {
+	if j.Ctx == nil {
+		return context.Background()
+	}
+	return j.Ctx
+}
+
+// Defer 注册流水线结束时（含失败）执行的清理函数，按注册的相反顺序执行
+func (j *FileJob) Defer(fn func()) {
+	j.deferred = append(j.deferred, fn)
+}
+
+// finish 执行注册的清理函数
+func (j *FileJob) finish() {
+	for i := len(j.deferred) - 1; i >= 0; i-- {
+		j.deferred[i]()
+	}
+	j.deferred = nil
+}
+
+// Pipeline 按顺序执行的处理阶段
+type Pipeline struct {
+	stages []Stage
+}
+
+// NewPipeline 创建由 stages 组成的流水线
+func NewPipeline(stages ...Stage) *Pipeline {
+	return &Pipeline{stages: stages}
+}
+
+// Stages 返回各阶段的名称
+func (pl *Pipeline) Stages() []string {
+	names := make([]string, 0, len(pl.stages))
+	for _, stage := range pl.stages {
+		names = append(names, stage.Name())
+	}
+	return names
+}
+
+// index 返回阶段的位置，不存在时返回 -1
+func (pl *Pipeline) index(name string) int {
+	for i, stage := range pl.stages {
+		if stage.Name() == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// Insert 将 stage 插入到名为 after 的阶段之后
+func (pl *Pipeline) Insert(after string, stage Stage) error {
+	if pl.index(stage.Name()) >= 0 {
+		return fmt.Errorf("阶段 %s 已存在", stage.Name())
+	}
+	i := pl.index(after)
+	if i < 0 {
+		return fmt.Errorf("阶段 %s 不存在，可用的阶段: %s", after, strings.Join(pl.Stages(), ", "))
+	}
+	pl.stages = append(pl.stages[:i+1], append([]Stage{stage}, pl.stages[i+1:]...)...)
+	return nil
+}
+
+// Run 依次执行全部阶段
+func (pl *Pipeline) Run(job *FileJob) error {
+	return pl.run(job, 0)
+}
+
+// RunAfter 从名为 after 的阶段之后开始执行
+func (pl *Pipeline) RunAfter(after string, job *FileJob) error {
+	i := pl.index(after)
+	if i < 0 {
+		return fmt.Errorf("阶段 %s 不存在", after)
+	}
+	return pl.run(job, i+1)
+}
+
+// run 从第 start 个阶段开始执行，阶段失败、job.Done 或上下文取消时停止
+func (pl *Pipeline) run(job *FileJob, start int) error {
+	defer job.finish()
+	for _, stage := range pl.stages[start:] {
+		if job.Done {
+			return nil
+		}
+		if err := job.Context().Err(); err != nil {
+			err = fmt.Errorf("处理已取消: %w", err)
+			job.Result.setError(err, ErrorKindCanceled)
+			return err
+		}
+		if err := stage.Run(job); err != nil {
+			if job.Result.Success {
+				job.Processor.failASR(job, fmt.Errorf("%s 阶段失败: %w", stage.Name(), err))
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// defaultPipeline 返回内置阶段组成的流水线，并按配置插入自定义阶段
+func (p *BatchProcessor) defaultPipeline() *Pipeline {
+	pipeline := NewPipeline(
+		StageFunc{StageProbe, p.probeStage},
+		StageFunc{StageExtract, p.extractStage},
+		StageFunc{StageNormalize, p.normalizeStage},
+		StageFunc{StageSplit, p.splitStage},
+		StageFunc{StageASR, p.asrStage},
+		StageFunc{StagePostProcess, p.postProcessStage},
+		StageFunc{StageExport, p.exportStage},
+		StageFunc{StageCleanup, p.cleanupStage},
+	)
+	if p.config == nil {
+		return pipeline
+	}
+	for _, stageConfig := range p.config.PipelineStages {
+		if i := pipeline.index(stageConfig.After); i >= 0 && i >= pipeline.index(StageASR) {
+			utils.Error("自定义阶段 %s 处理音频，只能插入在识别之前", stageConfig.Name)
+			continue
+		}
+		stage, err := NewCommandStage(stageConfig.Name, stageConfig.Command)
+		if err == nil {
+			err = pipeline.Insert(stageConfig.After, stage)
+		}
+		if err != nil {
+			utils.Error("添加自定义阶段 %s 失败: %v", stageConfig.Name, err)
+		}
+	}
+	return pipeline
+}
+
+// SetPipeline 替换处理流水线，用于插入以代码实现的阶段
+func (p *BatchProcessor) SetPipeline(pipeline *Pipeline) {
+	p.Pipeline = pipeline
+}
+
+// pipeline 返回处理流水线，未设置时使用内置阶段
+func (p *BatchProcessor) pipeline() *Pipeline {
+	p.pipelineOnce.Do(func() {
+		if p.Pipeline == nil {
+			p.Pipeline = p.defaultPipeline()
+		}
+	})
+	return p.Pipeline
+}
+
+// newFileJob 创建处理 result 对应文件的任务
+func (p *BatchProcessor) newFileJob(result *BatchResult) *FileJob {
+	exportConfig := p.config
+	if result.exportConfig != nil {
+		exportConfig = result.exportConfig
+	}
+	ctx, cancel := context.WithCancel(p.baseContext())
+	job := &FileJob{
+		Processor:    p,
+		Result:       result,
+		AudioPath:    result.OutputPath,
+		ExportConfig: exportConfig,
+		Ctx:          ctx,
+	}
+	job.Defer(cancel)
+	return job
+}
+
+// probeStage 不包含音频、近乎静音或与已识别文件内容相同的文件不提取也不识别
+func (p *BatchProcessor) probeStage(job *FileJob) error {
+	filePath := job.Result.FilePath
+	if reason := p.detectNoAudio(job.Context(), filePath); reason != "" {
+		*job.Result = p.noAudioResult(filePath, reason)
+		job.Done = true
+		return nil
+	}
+
+	// 内容与已识别的文件相同（改名或重新下载的副本）时不再识别
+	if p.dedupMode() != DedupOff {
+		hash, original := p.findDuplicate(filePath)
+		if original != "" {
+			*job.Result = p.duplicateResult(filePath, hash, original)
+			job.Done = true
+			return nil
+		}
+		job.Result.contentHash = hash
+	}
+	return nil
+}
+
+// extractStage 提取音频，以音乐为主的音频按配置跳过识别
+func (p *BatchProcessor) extractStage(job *FileJob) error {
+	filePath := job.Result.FilePath
+	hash := job.Result.contentHash
+
+	// 磁盘空间不足时等待，避免音频写到一半失败
+	if err := p.waitForDiskSpace(job.Context(), filePath, 0); err != nil {
+		job.Result.setError(err, ErrorKindExtract)
+		return err
+	}
+
+	p.reportFileProgress(filePath, StageExtract, 5, "提取音频")
+	*job.Result = p.extractAudioFromFile(job.Context(), filePath)
+	job.Result.contentHash = hash
+	if !job.Result.Success {
+		usage.Record(usage.Event{Success: false})
+		return job.Result.Error
+	}
+	job.AudioPath = job.Result.OutputPath
+
+	if p.checkMusic(job.Context(), job.Result) {
+		job.Done = true
+	}
+	return nil
+}
+
+// splitStage 检查待识别的音频并获取时长，超过 max_part_time 的音频分段识别
+func (p *BatchProcessor) splitStage(job *FileJob) error {
+	result := job.Result
+	fileID := FileTag(result.FilePath)
+
+	// 更新进度条
+	if p.ProgressManager != nil {
+		p.ProgressManager.UpdateProgressBar("file_"+fileID, 85, "执行语音识别...")
+	}
+	utils.Info("开始对文件进行语音识别: %s (路径: %s)", filepath.Base(job.AudioPath), job.AudioPath)
+
+	// 检查文件是否存在
+	fileInfo, err := os.Stat(job.AudioPath)
+	if err != nil {
+		utils.Error("音频文件不存在: %s", job.AudioPath)
+		if p.ProgressManager != nil {
+			p.ProgressManager.CompleteProgressBar("file_"+fileID, "失败：文件不存在")
+		}
+		err := fmt.Errorf("音频文件不存在: %s", job.AudioPath)
+		result.setError(err, ErrorKindInput)
+		return err
+	}
+
+	// 检查文件大小
+	if fileInfo.Size() == 0 {
+		utils.Error("音频文件大小为0: %s", job.AudioPath)
+		if p.ProgressManager != nil {
+			p.ProgressManager.CompleteProgressBar("file_"+fileID, "失败：文件大小为0")
+		}
+		err := fmt.Errorf("音频文件大小为0: %s", job.AudioPath)
+		result.setError(err, ErrorKindInput)
+		return err
+	}
+
+	duration, err := p.Extractor.getAudioDuration(job.Context(), job.AudioPath)
+	if err != nil {
+		result.log.Printf("获取音频时长失败: %v", err)
+		result.log.printFFmpegOutput(err)
+		// 无音频或已损坏的文件无需再提交识别，避免消耗配额
+		if errors.Is(err, ErrNoAudioStream) || errors.Is(err, ErrCorruptMedia) {
+			err := fmt.Errorf("获取音频时长失败: %w", err)
+			if p.ProgressManager != nil {
+				p.ProgressManager.CompleteProgressBar("file_"+fileID, "识别失败: "+err.Error())
+			}
+			result.setError(err, ErrorKindASR)
+			usage.Record(usage.Event{Success: false})
+			return err
+		}
+		return nil
+	}
+	result.Duration = duration
+	job.Duration = duration
+	job.Chunked = p.shouldChunk(duration)
+	if !job.Chunked {
+		// 分段识别的片段都很小，只有整段识别的音频可能超过服务的文件大小上限
+		return p.downsampleOversized(job, p.asrServiceFor(result))
+	}
+	return nil
+}
+
+// asrServiceFor 返回识别该文件使用的服务，重新识别时使用指定的服务
+func (p *BatchProcessor) asrServiceFor(result *BatchResult) string {
+	if result.asrService != "" {
+		return result.asrService
+	}
+	return p.config.ASRService
+}
+
+// asrStage 识别音频，长音频分段识别
+func (p *BatchProcessor) asrStage(job *FileJob) error {
+	result := job.Result
+	log := result.log
+	p.reportFileProgress(result.FilePath, StageASR, 30, "语音识别")
+
+	// 创建进度条ID
+	job.barID = "asr_" + FileTag(job.AudioPath)
+	if p.ProgressManager != nil {
+		p.ProgressManager.CreateProgressBar(job.barID, 100, "ASR识别 "+filepath.Base(job.AudioPath), "准备中...")
+	}
+
+	// 进度回调
+	// 分段识别时回调会被并发调用
+	var lastMessage string
+	var lastMu sync.Mutex
+	progressCallback := func(percent int, message string) {
+		lastMu.Lock()
+		if message != lastMessage {
+			log.Printf("识别进度 [%d%%]: %s", percent, message)
+			lastMessage = message
+		}
+		lastMu.Unlock()
+		if p.ProgressManager != nil {
+			p.ProgressManager.UpdateProgressBar(job.barID, percent, message)
+		}
+		// 语音识别占文件整体进度的 30%-95%
+		p.reportFileProgress(result.FilePath, StageASR, 30+percent*65/100, "语音识别: "+message)
+		utils.Debug("ASR进度 [%d%%]: %s", percent, message)
+	}
+
+	// 创建上下文，带有超时控制
+	ctx, cancel := context.WithTimeout(job.Context(), 150*time.Minute)
+	job.Defer(cancel)
+
+	// 为本次识别分配独立的临时目录，结束（含取消、失败）时删除
+	tempJob, err := p.allocateTempJob(job.AudioPath)
+	if err != nil {
+		result.setError(err, ErrorKindASR)
+		return err
+	}
+	job.TempJob = tempJob
+	job.Defer(func() { tempJob.Release() })
+
+	// 确定音频语言，用于选择支持该语言的ASR服务
+	ctx = p.withAudioLanguage(ctx, job.AudioPath, tempJob.Dir)
+	ctx = asr.WithSourceMedia(ctx, result.FilePath)
+	job.Ctx = ctx
+
+	service := p.asrServiceFor(result)
+	utils.Info("使用ASR服务: %s", service)
+	log.Printf("开始识别: %s (ASR服务: %s)", job.AudioPath, service)
+	if language := asr.LanguageFromContext(ctx); language != "" {
+		log.Printf("音频语言: %s", language)
+	}
+
+	if job.Chunked {
+		job.Segments, job.Service, err = p.runChunkedASR(ctx, result.FilePath, job.AudioPath, job.Duration, service, tempJob, progressCallback)
+	} else {
+		job.Segments, job.Service, err = p.ASRSelector.Recognize(ctx, job.AudioPath, service, p.config.ASRCache, progressCallback)
+	}
+	if err != nil {
+		p.failASR(job, err)
+		return err
+	}
+	return nil
+}
+
+// failASR 记录识别或导出失败
+func (p *BatchProcessor) failASR(job *FileJob, err error) {
+	result := job.Result
+	utils.Error("ASR识别失败: %v (文件: %s, 服务: %s)", err, job.AudioPath, job.Service)
+	if p.ProgressManager != nil && job.barID != "" {
+		p.ProgressManager.CompleteProgressBar(job.barID, "识别失败: "+err.Error())
+	}
+
+	// 即使识别失败，我们也标记文件为已处理，避免反复处理
+	result.setError(err, ErrorKindASR)
+	result.log.Printf("识别失败 (服务: %s): %v", job.Service, err)
+	result.log.printFFmpegOutput(err)
+	usage.Record(usage.Event{Service: job.Service, Success: false})
+}
+
+// postProcessStage 检查识别结果，按配置保存识别段落
+func (p *BatchProcessor) postProcessStage(job *FileJob) error {
+	if len(job.Segments) == 0 {
+		utils.Warn("ASR识别未返回任何文本段落，可能是音频中没有语音内容或识别失败")
+		if p.ProgressManager != nil {
+			p.ProgressManager.CompleteProgressBar(job.barID, "识别完成但未找到文本")
+		}
+	} else if p.ProgressManager != nil {
+		p.ProgressManager.CompleteProgressBar(job.barID, "识别成功，共"+fmt.Sprintf("%d", len(job.Segments))+"段文本")
+	}
+	// 只识别了部分时间范围时，将时间平移回源文件的时间轴，字幕才能与源文件对齐
+	if offset := job.Result.clipOffset; offset > 0 {
+		for i := range job.Segments {
+			job.Segments[i] = job.Segments[i].Shift(offset)
+		}
+	}
+	job.Result.Service = job.Service
+	p.saveSegments(filepath.Clean(job.Result.FilePath), job.Segments)
+	return nil
+}
+
+// exportStage 导出识别结果，按配置将字幕作为软字幕封装进源视频，并生成缩略图与波形
+func (p *BatchProcessor) exportStage(job *FileJob) error {
+	result := job.Result
+	exportConfig := job.ExportConfig
+	p.reportFileProgress(result.FilePath, StageExport, 95, "导出结果")
+	if len(job.Segments) > 0 {
+		outputFiles, err := asr.NewASRProcessor(exportConfig).ProcessResults(job.Context(), job.Segments, result.OutputPath, nil)
+		if err != nil {
+			p.failASR(job, err)
+			return err
+		}
+		job.OutputFiles = outputFiles
+		utils.Info("ASR结果处理完成，生成文件: %v", outputFiles)
+	} else {
+		utils.Warn("ASR识别结果为空: %s", job.AudioPath)
+	}
+	if job.Chunked {
+		// 结果已导出，不再需要分段中间结果
+		p.clearPartialResults(result.FilePath)
+	}
+
+	if exportConfig.MuxSubtitles && len(job.Segments) > 0 && p.isVideoFile(result.FilePath) {
+		if subbedPath, err := p.muxSubtitles(job.Context(), result.FilePath, job.OutputFiles, exportConfig); err != nil {
+			utils.Warn("封装软字幕失败: %v", err)
+			result.log.Printf("封装软字幕失败: %v", err)
+			result.log.printFFmpegOutput(err)
+		} else {
+			job.OutputFiles["subbed"] = subbedPath
+			if _, err := export.LinkOutput(exportConfig, result.OutputPath, "subbed", subbedPath); err != nil {
+				utils.Warn("更新输出清单失败: %v", err)
+			}
+		}
+	}
+
+	if len(job.Segments) > 0 {
+		p.generatePreviews(job, exportConfig)
+	}
+	if len(job.OutputFiles) > 0 {
+		p.publishOutputs(job.Context(), exportConfig, result.OutputPath, result.log)
+	}
+
+	// 输出结果信息
+	if len(job.OutputFiles) > 0 {
+		utils.Info("生成的字幕文件:")
+		for fileType, filePath := range job.OutputFiles {
+			utils.Info("- %s: %s", fileType, filepath.Base(filePath))
+		}
+	} else {
+		utils.Warn("未生成任何输出文件")
+	}
+
+	utils.Info("文件 %s 识别完成，共 %d 段文本", filepath.Base(result.OutputPath), len(job.Segments))
+	result.log.Printf("识别完成 (服务: %s)，共 %d 段文本，输出 %d 个文件", job.Service, len(job.Segments), len(job.OutputFiles))
+
+	// 记录本地使用量统计
+	event := usage.Event{Service: job.Service, Success: true}
+	event.AudioSeconds = float64(job.Duration)
+	usage.Record(event)
+	result.OutputFiles = job.OutputFiles
+	return nil
+}
+
+// cleanupStage 完成进度并删除提取的MP3文件
+func (p *BatchProcessor) cleanupStage(job *FileJob) error {
+	result := job.Result
+	if p.ProgressManager != nil {
+		p.ProgressManager.CompleteProgressBar("file_"+FileTag(result.FilePath), "处理完成")
+	}
+
+	audioPath := result.OutputPath
+	if result.Success && strings.ToLower(filepath.Ext(audioPath)) == ".mp3" {
+		utils.Info("识别完成，删除提取的MP3文件: %s", audioPath)
+		if _, err := p.Trash.Remove(audioPath, "asr", "识别完成后清理MP3文件"); err != nil {
+			utils.Warn("无法删除MP3文件: %v", err)
+		}
+	}
+	return nil
+}
+
+// CommandStage 对待识别的音频执行外部命令的自定义阶段，如降噪
+type CommandStage struct {
+	StageName string
+	Command   string   // 可执行文件
+	Args      []string // 参数，{input}、{output}、{source} 会被替换
+}
+
+// NewCommandStage 根据命令行字符串创建自定义阶段，命令中必须包含 {output}
+func NewCommandStage(name, commandLine string) (*CommandStage, error) {
+	fields := strings.Fields(commandLine)
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("命令为空")
+	}
+	if !strings.Contains(commandLine, "{output}") {
+		return nil, fmt.Errorf("命令中缺少 {output} 占位符")
+	}
+	return &CommandStage{StageName: name, Command: fields[0], Args: fields[1:]}, nil
+}
+
+// Name 返回阶段名称
+func (s *CommandStage) Name() string { return s.StageName }
+
+// Run 执行命令，成功后以命令写入的音频作为待识别的音频。输出写在本文件的临时目录，流水线结束时删除
+func (s *CommandStage) Run(job *FileJob) error {
+	dir, err := os.MkdirTemp(job.Processor.TempDir, "stage-"+s.StageName+"-")
+	if err != nil {
+		return fmt.Errorf("创建临时目录失败: %w", err)
+	}
+	job.Defer(func() { os.RemoveAll(dir) })
+	output := filepath.Join(dir, filepath.Base(job.AudioPath))
+
+	replacer := strings.NewReplacer("{input}", job.AudioPath, "{output}", output, "{source}", job.Result.FilePath)
+	args := make([]string, len(s.Args))
+	for i, arg := range s.Args {
+		args[i] = replacer.Replace(arg)
+	}
+
+	utils.Info("执行自定义阶段 %s: %s", s.StageName, filepath.Base(job.Result.FilePath))
+	job.Result.log.Printf("自定义阶段 %s: %s %s", s.StageName, s.Command, strings.Join(args, " "))
+	out, err := exec.CommandContext(job.Context(), s.Command, args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("执行命令失败: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	if info, err := os.Stat(output); err != nil || info.Size() == 0 {
+		return fmt.Errorf("命令未生成输出音频: %s", output)
+	}
+	job.AudioPath = output
+	return nil
+}
@@ -0,0 +1,209 @@
+package audio
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/ffmpeg"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/models"
+)
+
+// recordStage 记录执行顺序的测试阶段
+func recordStage(name string, order *[]string, fn func(job *FileJob) error) Stage {
+	return StageFunc{name, func(job *FileJob) error {
+		*order = append(*order, name)
+		if fn != nil {
+			return fn(job)
+		}
+		return nil
+	}}
+}
+
+// TestPipelineInsertAndRun 测试插入阶段、从指定阶段之后执行，以及 Done 与清理函数
+func TestPipelineInsertAndRun(t *testing.T) {
+	var order []string
+	pipeline := NewPipeline(recordStage("a", &order, nil), recordStage("c", &order, nil))
+	require.NoError(t, pipeline.Insert("a", recordStage("b", &order, func(job *FileJob) error {
+		job.Defer(func() { order = append(order, "deferred") })
+		return nil
+	})))
+	assert.Equal(t, []string{"a", "b", "c"}, pipeline.Stages())
+	assert.Error(t, pipeline.Insert("missing", recordStage("d", &order, nil)))
+	assert.Error(t, pipeline.Insert("a", recordStage("c", &order, nil)), "阶段不能重名")
+
+	job := &FileJob{Result: &BatchResult{Success: true}}
+	require.NoError(t, pipeline.Run(job))
+	assert.Equal(t, []string{"a", "b", "c", "deferred"}, order)
+
+	order = nil
+	require.NoError(t, pipeline.RunAfter("a", &FileJob{Result: &BatchResult{Success: true}}))
+	assert.Equal(t, []string{"b", "c", "deferred"}, order)
+
+	// 设置 Done 后不再执行后续阶段
+	order = nil
+	done := NewPipeline(recordStage("a", &order, func(job *FileJob) error { job.Done = true; return nil }), recordStage("b", &order, nil))
+	require.NoError(t, done.Run(&FileJob{Result: &BatchResult{Success: true}}))
+	assert.Equal(t, []string{"a"}, order)
+}
+
+// TestPipelineStageError 测试阶段失败时停止执行并记录失败原因
+func TestPipelineStageError(t *testing.T) {
+	var order []string
+	pipeline := NewPipeline(
+		recordStage("a", &order, func(*FileJob) error { return errors.New("boom") }),
+		recordStage("b", &order, nil),
+	)
+	result := &BatchResult{FilePath: "a.mp4", Success: true}
+	err := pipeline.Run(&FileJob{Processor: &BatchProcessor{}, Result: result})
+	require.Error(t, err)
+	assert.Equal(t, []string{"a"}, order)
+	assert.False(t, result.Success)
+	assert.Contains(t, result.Error.Error(), "a 阶段失败")
+	assert.Equal(t, ErrorKindASR, result.ErrorKind)
+}
+
+// TestPipelineCanceled 测试上下文取消后不再执行后续阶段，结果标记为已取消
+func TestPipelineCanceled(t *testing.T) {
+	var order []string
+	ctx, cancel := context.WithCancel(context.Background())
+	pipeline := NewPipeline(
+		recordStage("a", &order, func(*FileJob) error { cancel(); return nil }),
+		recordStage("b", &order, nil),
+	)
+	result := &BatchResult{FilePath: "a.mp4", Success: true}
+	err := pipeline.Run(&FileJob{Processor: &BatchProcessor{}, Result: result, Ctx: ctx})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, []string{"a"}, order)
+	assert.False(t, result.Success)
+	assert.Equal(t, ErrorKindCanceled, result.ErrorKind)
+}
+
+// TestDefaultPipelineCustomStages 测试按配置插入自定义阶段，识别之后的位置被拒绝
+func TestDefaultPipelineCustomStages(t *testing.T) {
+	config := models.NewDefaultConfig()
+	config.PipelineStages = []models.PipelineStageConfig{
+		{Name: "denoise", After: StageExtract, Command: "ffmpeg -y -i {input} -af afftdn {output}"},
+		{Name: "gain", After: "denoise", Command: "sox {input} {output} gain 3"},
+		{Name: "late", After: StageExport, Command: "cp {input} {output}"},
+		{Name: "bad", After: StageNormalize, Command: "cp {input}"},
+	}
+	require.NoError(t, config.Validate())
+	processor := &BatchProcessor{config: config}
+	assert.Equal(t, []string{
+		StageProbe, StageExtract, "denoise", "gain", StageNormalize, StageSplit,
+		StageASR, StagePostProcess, StageExport, StageCleanup,
+	}, processor.pipeline().Stages())
+}
+
+// TestCommandStage 测试自定义阶段执行命令后替换待识别的音频，流水线结束时删除输出
+func TestCommandStage(t *testing.T) {
+	if _, err := exec.LookPath("cp"); err != nil {
+		t.Skip("未找到 cp 命令")
+	}
+	dir := t.TempDir()
+	input := filepath.Join(dir, "audio.mp3")
+	require.NoError(t, os.WriteFile(input, []byte("audio"), 0644))
+
+	stage, err := NewCommandStage("copy", "cp {input} {output}")
+	require.NoError(t, err)
+	job := &FileJob{
+		Processor: &BatchProcessor{TempDir: dir},
+		Result:    &BatchResult{FilePath: input, OutputPath: input, Success: true},
+		AudioPath: input,
+	}
+	require.NoError(t, stage.Run(job))
+	assert.NotEqual(t, input, job.AudioPath)
+	data, err := os.ReadFile(job.AudioPath)
+	require.NoError(t, err)
+	assert.Equal(t, "audio", string(data))
+	assert.Equal(t, input, job.Result.OutputPath, "导出仍按提取出的音频命名")
+
+	job.finish()
+	assert.NoFileExists(t, job.AudioPath)
+
+	_, err = NewCommandStage("bad", "cp {input}")
+	assert.Error(t, err)
+	failing, err := NewCommandStage("fail", "false {output}")
+	require.NoError(t, err)
+	assert.Error(t, failing.Run(job))
+}
+
+// TestNormalizeStage 测试只对直接识别的音频文件执行预处理，视频提取出的音频已在提取时处理
+func TestNormalizeStage(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "talk.wav")
+	require.NoError(t, os.WriteFile(input, []byte("audio"), 0644))
+
+	var commands [][]string
+	extractor := &AudioExtractor{}
+	extractor.SetFFmpegRunner(ffmpeg.RunnerFunc(func(ctx context.Context, cmd *ffmpeg.Command) (string, error) {
+		commands = append(commands, cmd.Args)
+		return "", os.WriteFile(cmd.Args[len(cmd.Args)-1], []byte("filtered"), 0644)
+	}))
+	config := models.NewDefaultConfig()
+	config.AudioHighpass = 100
+	config.AudioDenoise = "afftdn"
+	processor := &BatchProcessor{TempDir: dir, config: config, Extractor: extractor}
+
+	job := &FileJob{Processor: processor, Result: &BatchResult{FilePath: input, OutputPath: input, Success: true}, AudioPath: input}
+	require.NoError(t, processor.normalizeStage(job))
+	require.Len(t, commands, 1)
+	assert.Contains(t, commands[0], "highpass=f=100,afftdn")
+	assert.Equal(t, "talk.mp3", filepath.Base(job.AudioPath))
+	job.finish()
+	assert.NoFileExists(t, job.AudioPath)
+
+	extracted := filepath.Join(dir, "video.mp3")
+	job = &FileJob{Processor: processor, Result: &BatchResult{FilePath: filepath.Join(dir, "video.mp4"), OutputPath: extracted, Success: true}, AudioPath: extracted}
+	require.NoError(t, processor.normalizeStage(job))
+	assert.Len(t, commands, 1)
+	assert.Equal(t, extracted, job.AudioPath)
+}
+
+// TestClipRange 测试只识别部分时间范围时截取音频，并将识别结果平移回源文件的时间
+func TestClipRange(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "talk.wav")
+	require.NoError(t, os.WriteFile(input, []byte("audio"), 0644))
+
+	var extractArgs []string
+	extractor := &AudioExtractor{}
+	extractor.SetFFmpegRunner(ffmpeg.RunnerFunc(func(ctx context.Context, cmd *ffmpeg.Command) (string, error) {
+		if cmd.Program == "ffprobe" {
+			return "", ffmpeg.NewError(errors.New("exit status 1"), "")
+		}
+		extractArgs = cmd.Args
+		return "", os.WriteFile(cmd.Args[len(cmd.Args)-2], []byte("clip"), 0644)
+	}))
+	config := models.NewDefaultConfig()
+	config.ClipStart = "01:00"
+	config.ClipEnd = "90"
+	outputDir := t.TempDir()
+	processor := &BatchProcessor{
+		OutputDir:       outputDir,
+		TempDir:         dir,
+		AudioExtensions: DefaultAudioExtensions,
+		Extractor:       extractor,
+		config:          config,
+	}
+
+	result := processor.extractAudioFromFile(context.Background(), input)
+	require.NoError(t, result.Error)
+	assert.Equal(t, filepath.Join(outputDir, "talk.mp3"), result.OutputPath)
+	assert.Equal(t, []string{"-ss", "60", "-i", input}, extractArgs[:4])
+	assert.Contains(t, extractArgs, "30")
+
+	job := &FileJob{Processor: processor, Result: &result, Segments: []models.DataSegment{
+		{Text: "开场", StartTime: 1, EndTime: 3, Words: []models.WordTiming{{Text: "开场", StartTime: 1, EndTime: 3}}},
+	}}
+	require.NoError(t, processor.postProcessStage(job))
+	assert.Equal(t, 61.0, job.Segments[0].StartTime)
+	assert.Equal(t, 63.0, job.Segments[0].Words[0].EndTime)
+}
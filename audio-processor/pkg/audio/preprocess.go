@@ -0,0 +1,58 @@
+package audio
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/ffmpeg"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/models"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/utils"
+)
+
+// audioFilterChain 按配置返回识别前的预处理滤镜链，未启用时为空字符串
+func audioFilterChain(config *models.Config) string {
+	if config == nil {
+		return ""
+	}
+	return ffmpeg.AudioFilters{
+		Highpass:     config.AudioHighpass,
+		Lowpass:      config.AudioLowpass,
+		Denoise:      config.AudioDenoise,
+		DenoiseModel: config.AudioDenoiseModel,
+		Loudnorm:     config.AudioLoudnorm,
+	}.Chain()
+}
+
+// SetAudioFilter 设置提取音频时应用的滤镜链，为空时不处理
+func (e *AudioExtractor) SetAudioFilter(filter string) {
+	e.audioFilter = filter
+}
+
+// normalizeStage 对直接识别的音频文件执行预处理。视频与需要转码的音频在提取时已经应用了滤镜，不再重复处理；
+// 处理后的音频写在本文件的临时目录，流水线结束时删除
+func (p *BatchProcessor) normalizeStage(job *FileJob) error {
+	filter := audioFilterChain(p.config)
+	if filter == "" || filepath.Clean(job.AudioPath) != filepath.Clean(job.Result.FilePath) {
+		return nil
+	}
+
+	dir, err := os.MkdirTemp(p.TempDir, "stage-"+StageNormalize+"-")
+	if err != nil {
+		return fmt.Errorf("创建临时目录失败: %w", err)
+	}
+	job.Defer(func() { os.RemoveAll(dir) })
+	base := strings.TrimSuffix(filepath.Base(job.AudioPath), filepath.Ext(job.AudioPath))
+	output := filepath.Join(dir, base+".mp3")
+
+	utils.Info("预处理音频: %s", filepath.Base(job.AudioPath))
+	job.Result.log.Printf("预处理滤镜: %s", filter)
+	if err := p.Extractor.runFFmpeg(job.Context(), ffmpeg.FilterAudio(job.AudioPath, output, filter)); err != nil {
+		job.Result.setError(fmt.Errorf("预处理音频失败: %w", err), ErrorKindExtract)
+		job.Result.log.printFFmpegOutput(err)
+		return job.Result.Error
+	}
+	job.AudioPath = output
+	return nil
+}
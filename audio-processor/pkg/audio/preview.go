@@ -0,0 +1,187 @@
+package audio
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/audit"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/export"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/ffmpeg"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/models"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/utils"
+)
+
+// 缩略图与波形图的尺寸、计算峰值的采样率，以及截取缩略图的位置上限（秒）
+const (
+	thumbnailWidth    = 640
+	waveformWidth     = 1800
+	waveformHeight    = 140
+	peaksSampleRate   = 8000
+	maxThumbnailSeek  = 60.0
+	defaultPeakSecond = 0.1 // 时长未知时每个峰值覆盖的秒数
+)
+
+// WaveformPeaks 波形峰值数据，Peaks 为每段音频的最大振幅（0-1），依次均匀覆盖整个时长
+type WaveformPeaks struct {
+	Duration float64   `json:"duration"` // 音频时长（秒）
+	Peaks    []float64 `json:"peaks"`
+}
+
+// generatePreviews 按配置生成缩略图与波形，写入输出清单所在的子目录，并加入 job.OutputFiles。
+// 生成失败只记录警告，不影响识别结果
+func (p *BatchProcessor) generatePreviews(job *FileJob, config *models.Config) {
+	if !config.GenerateThumbnail && !config.GenerateWaveform {
+		return
+	}
+	result := job.Result
+	ctx := job.Context()
+	dir := filepath.Dir(export.ManifestPath(config.OutputFolder, result.OutputPath))
+	base := filepath.Base(dir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		utils.Warn("创建输出目录失败: %v", err)
+		return
+	}
+
+	// 波形与播放器播放的源文件对齐；源文件是远程地址时使用提取的音频
+	source := result.FilePath
+	if IsRemoteMedia(source) {
+		source = job.AudioPath
+	}
+	duration, err := ffmpeg.Duration(ctx, p.Extractor.ffmpegRunner(), source)
+	if err != nil {
+		utils.Debug("获取时长失败，预览按默认参数生成: %v", err)
+		duration = 0
+	}
+
+	outputs := make(map[string]string)
+	if config.GenerateThumbnail && p.isVideoFile(result.FilePath) {
+		path := filepath.Join(dir, base+"_thumb.jpg")
+		if err := p.generateThumbnail(ctx, source, path, duration); err != nil {
+			p.warnPreview(result, "生成缩略图失败", err)
+		} else {
+			outputs["thumbnail"] = path
+		}
+	}
+	if config.GenerateWaveform {
+		path := filepath.Join(dir, base+"_waveform.png")
+		if err := p.Extractor.runFFmpeg(ctx, ffmpeg.WaveformImage(source, path, waveformWidth, waveformHeight)); err != nil {
+			p.warnPreview(result, "生成波形图失败", err)
+		} else {
+			outputs["waveform"] = path
+		}
+
+		path = filepath.Join(dir, base+"_peaks.json")
+		if err := p.writeWaveformPeaks(ctx, source, path, duration, config.WaveformPeaks); err != nil {
+			p.warnPreview(result, "生成波形峰值失败", err)
+		} else {
+			outputs["peaks"] = path
+		}
+	}
+
+	for fileType, path := range outputs {
+		job.OutputFiles[fileType] = path
+		if _, err := export.LinkOutput(config, result.OutputPath, fileType, path); err != nil {
+			utils.Warn("更新输出清单失败: %v", err)
+		}
+	}
+}
+
+// warnPreview 记录预览生成失败
+func (p *BatchProcessor) warnPreview(result *BatchResult, message string, err error) {
+	utils.Warn("%s: %v", message, err)
+	result.log.Printf("%s: %v", message, err)
+	result.log.printFFmpegOutput(err)
+}
+
+// generateThumbnail 在视频 10% 处（不超过一分钟）截取缩略图，避开片头的黑屏
+func (p *BatchProcessor) generateThumbnail(ctx context.Context, source, path string, duration float64) error {
+	at := math.Min(duration/10, maxThumbnailSeek)
+	audit.RecordOverwrite(path, "export", "重新生成缩略图")
+	return p.Extractor.runFFmpeg(ctx, ffmpeg.Thumbnail(source, path, at, thumbnailWidth))
+}
+
+// writeWaveformPeaks 解码音频并计算 count 个峰值写入 JSON 文件
+func (p *BatchProcessor) writeWaveformPeaks(ctx context.Context, source, path string, duration float64, count int) error {
+	samplesPerPeak := int(math.Ceil(duration * peaksSampleRate / float64(count)))
+	if samplesPerPeak <= 0 {
+		samplesPerPeak = int(peaksSampleRate * defaultPeakSecond)
+	}
+	collector := &peakCollector{samplesPerPeak: samplesPerPeak}
+	if err := p.Extractor.runFFmpeg(ctx, ffmpeg.DecodePCM(source, 0, 0, peaksSampleRate, collector)); err != nil {
+		return err
+	}
+
+	peaks := WaveformPeaks{Duration: duration, Peaks: collector.finish()}
+	if peaks.Duration <= 0 {
+		peaks.Duration = float64(collector.samples) / peaksSampleRate
+	}
+	data, err := json.Marshal(peaks)
+	if err != nil {
+		return fmt.Errorf("编码波形峰值失败: %w", err)
+	}
+	audit.RecordOverwrite(path, "export", "重新生成波形峰值")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("写入波形峰值失败: %w", err)
+	}
+	return nil
+}
+
+// peakCollector 接收单声道 s16le PCM，每 samplesPerPeak 个采样记录一次最大振幅
+type peakCollector struct {
+	samplesPerPeak int
+	peaks          []float64
+	current        int    // 当前段的最大振幅
+	count          int    // 当前段已读取的采样数
+	samples        int    // 读取的采样总数
+	pending        []byte // 上次写入剩下的半个采样
+}
+
+// Write 累计采样，写入的字节可能在采样中间截断
+func (c *peakCollector) Write(data []byte) (int, error) {
+	n := len(data)
+	if len(c.pending) > 0 {
+		data = append(c.pending, data...)
+		c.pending = nil
+	}
+	for len(data) >= 2 {
+		sample := int(int16(binary.LittleEndian.Uint16(data)))
+		if sample < 0 {
+			sample = -sample
+		}
+		if sample > c.current {
+			c.current = sample
+		}
+		c.count++
+		c.samples++
+		if c.count == c.samplesPerPeak {
+			c.flush()
+		}
+		data = data[2:]
+	}
+	if len(data) > 0 {
+		c.pending = append([]byte{}, data...)
+	}
+	return n, nil
+}
+
+// flush 记录当前段的峰值，保留三位小数以减小文件体积
+func (c *peakCollector) flush() {
+	c.peaks = append(c.peaks, math.Round(float64(c.current)/32768*1000)/1000)
+	c.current, c.count = 0, 0
+}
+
+// finish 记录最后不满一段的采样并返回全部峰值
+func (c *peakCollector) finish() []float64 {
+	if c.count > 0 {
+		c.flush()
+	}
+	if c.peaks == nil {
+		return []float64{}
+	}
+	return c.peaks
+}
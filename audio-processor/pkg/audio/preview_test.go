@@ -0,0 +1,93 @@
+package audio
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/export"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/ffmpeg"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/models"
+)
+
+// pcm 将采样编码为 s16le
+func pcm(samples ...int16) []byte {
+	data := make([]byte, 2*len(samples))
+	for i, sample := range samples {
+		binary.LittleEndian.PutUint16(data[2*i:], uint16(sample))
+	}
+	return data
+}
+
+// TestPeakCollector 测试按段计算最大振幅，写入可能在采样中间截断
+func TestPeakCollector(t *testing.T) {
+	collector := &peakCollector{samplesPerPeak: 2}
+	data := pcm(100, -16384, 0, 8192, -32768)
+	collector.Write(data[:3])
+	collector.Write(data[3:])
+	assert.Equal(t, []float64{0.5, 0.25, 1}, collector.finish())
+	assert.Equal(t, 5, collector.samples)
+}
+
+// TestGeneratePreviews 测试生成缩略图与波形并加入输出清单
+func TestGeneratePreviews(t *testing.T) {
+	outputDir := t.TempDir()
+	config := models.NewDefaultConfig()
+	config.OutputFolder = outputDir
+	config.GenerateThumbnail = true
+	config.GenerateWaveform = true
+	config.WaveformPeaks = 100
+
+	extractor := &AudioExtractor{}
+	extractor.SetFFmpegRunner(ffmpeg.RunnerFunc(func(ctx context.Context, cmd *ffmpeg.Command) (string, error) {
+		switch {
+		case cmd.Program == "ffprobe":
+			fmt.Fprintln(cmd.Stdout, "0.05")
+		case cmd.Stdout != nil:
+			// 0.05 秒 8kHz 共 400 个采样，每 4 个采样一个峰值
+			samples := make([]int16, 400)
+			samples[0] = 16384
+			cmd.Stdout.Write(pcm(samples...))
+		default:
+			return "", os.WriteFile(cmd.Args[len(cmd.Args)-1], []byte("image"), 0644)
+		}
+		return "", nil
+	}))
+	processor := &BatchProcessor{VideoExtensions: DefaultVideoExtensions, Extractor: extractor, config: config}
+
+	source := filepath.Join("media", "talk.mp4")
+	transcript := filepath.Join(outputDir, "talk.txt")
+	require.NoError(t, os.WriteFile(transcript, []byte("文稿"), 0644))
+	_, err := export.WriteManifest(config, source, map[string]string{"txt": transcript})
+	require.NoError(t, err)
+
+	job := &FileJob{Processor: processor, Result: &BatchResult{FilePath: source, OutputPath: source}, OutputFiles: map[string]string{"txt": transcript}}
+	processor.generatePreviews(job, config)
+
+	dir := filepath.Join(outputDir, "talk")
+	assert.Equal(t, filepath.Join(dir, "talk_thumb.jpg"), job.OutputFiles["thumbnail"])
+	assert.Equal(t, filepath.Join(dir, "talk_waveform.png"), job.OutputFiles["waveform"])
+	require.Equal(t, filepath.Join(dir, "talk_peaks.json"), job.OutputFiles["peaks"])
+
+	data, err := os.ReadFile(job.OutputFiles["peaks"])
+	require.NoError(t, err)
+	var peaks WaveformPeaks
+	require.NoError(t, json.Unmarshal(data, &peaks))
+	assert.Equal(t, 0.05, peaks.Duration)
+	require.Len(t, peaks.Peaks, 100)
+	assert.Equal(t, 0.5, peaks.Peaks[0])
+
+	manifest, err := export.LoadManifest(export.ManifestPath(outputDir, source))
+	require.NoError(t, err)
+	for _, fileType := range []string{"thumbnail", "waveform", "peaks"} {
+		_, ok := manifest.Output(fileType)
+		assert.True(t, ok, fileType)
+	}
+}
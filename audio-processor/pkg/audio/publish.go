@@ -0,0 +1,32 @@
+package audio
+
+import (
+	"context"
+	"path/filepath"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/export"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/models"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/storage"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/utils"
+)
+
+// publishOutputs 按 output_storage 将文件的输出与清单上传到远程存储。
+// 上传失败不影响本地输出与处理结果，只记录警告，可用 `asr outputs push` 重新上传
+func (p *BatchProcessor) publishOutputs(ctx context.Context, config *models.Config, filename string, log *FileLog) {
+	if p.Storage == nil {
+		return
+	}
+	manifest, err := export.LoadManifest(export.ManifestPath(config.OutputFolder, filename))
+	if err != nil {
+		utils.Warn("上传输出失败: %v", err)
+		return
+	}
+	uploaded, err := storage.PushManifest(ctx, p.Storage, config, manifest)
+	if err != nil {
+		utils.Warn("上传 %s 的输出到 %s 失败: %v", filepath.Base(filename), p.Storage.Name(), err)
+		log.Printf("上传输出到 %s 失败: %v", p.Storage.Name(), err)
+		return
+	}
+	utils.Info("已将 %s 的 %d 个文件上传到 %s", filepath.Base(filename), uploaded, p.Storage.Name())
+	log.Printf("已上传 %d 个文件到 %s", uploaded, p.Storage.Name())
+}
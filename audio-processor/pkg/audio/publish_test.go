@@ -0,0 +1,68 @@
+package audio
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/export"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryStorage 以内存保存上传内容
+type memoryStorage struct {
+	mu      sync.Mutex
+	objects map[string]string
+}
+
+func (m *memoryStorage) Name() string { return "memory" }
+
+func (m *memoryStorage) Put(ctx context.Context, key string, body io.ReadSeeker, size int64) error {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.objects[key] = string(data)
+	return nil
+}
+
+func (m *memoryStorage) Delete(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.objects, key)
+	return nil
+}
+
+// TestPublishOutputs 测试按输出清单上传输出，Web用户的远程路径带有用户目录
+func TestPublishOutputs(t *testing.T) {
+	root := t.TempDir()
+	config := models.NewDefaultConfig()
+	config.OutputFolder = filepath.Join(root, "output")
+	config.StoragePrefix = "team"
+	processor := NewBatchProcessor(filepath.Join(root, "media"), config.OutputFolder, filepath.Join(root, "temp"), nil, config)
+	defer processor.Close()
+	assert.Nil(t, processor.Storage, "默认只写本地输出目录")
+
+	remote := &memoryStorage{objects: make(map[string]string)}
+	processor.Storage = remote
+	txt := filepath.Join(config.OutputFolder, "talk.txt")
+	require.NoError(t, os.WriteFile(txt, []byte("你好"), 0644))
+	_, err := export.WriteManifest(config, "talk.mp3", map[string]string{"txt": txt})
+	require.NoError(t, err)
+
+	processor.publishOutputs(context.Background(), config, "talk.mp3", nil)
+	assert.Equal(t, "你好", remote.objects["talk.txt"])
+	assert.Contains(t, remote.objects, "talk/outputs.json")
+
+	web := NewWebProcessor(filepath.Join(root, "uploads"), filepath.Join(root, "temp"), config.OutputFolder, config)
+	defer web.Processor.Close()
+	assert.Equal(t, "team/users/alice", web.ForUser("alice").Config.StoragePrefix)
+	assert.Equal(t, "team", web.Config.StoragePrefix)
+}
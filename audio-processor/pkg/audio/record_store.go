@@ -0,0 +1,198 @@
+package audio
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/models"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/store"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/utils"
+)
+
+// importedSuffix 旧版 JSON 处理记录导入数据库后追加的后缀
+const importedSuffix = ".imported"
+
+// openRecordStore 按配置打开处理记录数据库，并导入旧版的 processed_records.json。
+// 打开失败时退回使用 JSON 文件保存记录
+func (p *BatchProcessor) openRecordStore() {
+	if p.config == nil || p.config.RecordsStore == "json" {
+		return
+	}
+	db, err := store.Open(store.Path(p.OutputDir))
+	if err != nil {
+		utils.Warn("打开处理记录数据库失败: %v, 将使用 %s", err, p.processedRecordFile)
+		return
+	}
+	p.store = db
+	p.dirtyRecords = make(map[string]bool)
+
+	if _, err := os.Stat(p.processedRecordFile); err != nil {
+		return
+	}
+	imported, err := db.ImportJSON(p.processedRecordFile)
+	if err != nil {
+		utils.Warn("导入处理记录失败: %v", err)
+		return
+	}
+	if err := os.Rename(p.processedRecordFile, p.processedRecordFile+importedSuffix); err != nil {
+		utils.Warn("重命名已导入的处理记录失败: %v", err)
+	}
+	utils.Info("已将 %s 中的 %d 条处理记录导入数据库", p.processedRecordFile, imported)
+}
+
+// Store 返回处理记录数据库，使用 JSON 文件保存记录时为 nil
+func (p *BatchProcessor) Store() *store.Store {
+	return p.store
+}
+
+// Close 关闭处理记录数据库
+func (p *BatchProcessor) Close() error {
+	if p.store == nil {
+		return nil
+	}
+	if err := p.saveProcessedRecords(); err != nil {
+		utils.Warn("%v", err)
+	}
+	return p.store.Close()
+}
+
+// loadRecordsFromStore 从数据库加载处理记录
+func (p *BatchProcessor) loadRecordsFromStore() {
+	records, err := p.store.Records()
+	if err != nil {
+		utils.Warn("加载处理记录失败: %v, 将使用空记录", err)
+		return
+	}
+	p.recordsMu.Lock()
+	for path, row := range records {
+		var record ProcessedRecord
+		if err := json.Unmarshal(row.Data, &record); err != nil {
+			utils.Warn("解析处理记录 %s 失败: %v", path, err)
+			continue
+		}
+		if record.Filename == "" {
+			record.Filename = row.Filename
+		}
+		p.processedRecords[path] = record
+	}
+	count := len(p.processedRecords)
+	p.recordsMu.Unlock()
+
+	utils.Info("已加载处理记录: %d 个文件", count)
+}
+
+// setRecord 修改内存中的记录，并标记为待写入数据库。调用方需持有 recordsMu
+func (p *BatchProcessor) setRecord(key string, record ProcessedRecord) {
+	p.processedRecords[key] = record
+	if p.dirtyRecords != nil {
+		p.dirtyRecords[key] = true
+	}
+}
+
+// deleteRecord 删除内存中的记录，并标记为待从数据库删除。调用方需持有 recordsMu
+func (p *BatchProcessor) deleteRecord(key string) {
+	delete(p.processedRecords, key)
+	if p.dirtyRecords != nil {
+		p.dirtyRecords[key] = false
+	}
+}
+
+// saveRecordsToStore 将修改过的记录写入数据库。只写入本进程修改的记录，
+// 不会覆盖其他进程写入的记录
+func (p *BatchProcessor) saveRecordsToStore() error {
+	p.recordsMu.Lock()
+	dirty := p.dirtyRecords
+	p.dirtyRecords = make(map[string]bool)
+	var rows []store.Record
+	var deleted []string
+	for key, modified := range dirty {
+		record, exists := p.processedRecords[key]
+		if !modified || !exists {
+			deleted = append(deleted, key)
+			continue
+		}
+		data, err := json.Marshal(record)
+		if err != nil {
+			utils.Warn("编码处理记录 %s 失败: %v", key, err)
+			continue
+		}
+		rows = append(rows, store.Record{
+			Path:              key,
+			Filename:          record.Filename,
+			Completed:         record.Completed,
+			Status:            record.Status,
+			Service:           record.Service,
+			LastProcessedTime: record.LastProcessedTime,
+			ContentHash:       record.ContentHash,
+			Data:              data,
+		})
+	}
+	p.recordsMu.Unlock()
+
+	if err := p.store.SaveRecords(rows, deleted); err != nil {
+		// 写入失败的记录留到下次保存，期间再次修改的以新的标记为准
+		p.recordsMu.Lock()
+		for key, modified := range dirty {
+			if _, exists := p.dirtyRecords[key]; !exists {
+				p.dirtyRecords[key] = modified
+			}
+		}
+		p.recordsMu.Unlock()
+		utils.Error("保存处理记录失败: %v", err)
+		return fmt.Errorf("保存处理记录失败: %w", err)
+	}
+	return nil
+}
+
+// recordBatch 将一次批处理的结果写入数据库
+func (p *BatchProcessor) recordBatch(results []BatchResult, startedAt time.Time) {
+	if p.store == nil {
+		return
+	}
+	manifest := NewBatchManifest(results, startedAt, time.Now())
+	batch := store.Batch{
+		StartedAt:  manifest.StartedAt,
+		FinishedAt: manifest.FinishedAt,
+		Total:      manifest.Total,
+		Succeeded:  manifest.Succeeded,
+		Failed:     manifest.Failed,
+	}
+	for _, file := range manifest.Files {
+		batch.Files = append(batch.Files, store.BatchFile{
+			File:           file.File,
+			Success:        file.Success,
+			Status:         file.Status,
+			Service:        file.Service,
+			Duration:       file.Duration,
+			ProcessSeconds: file.ProcessSeconds,
+			Outputs:        file.Outputs,
+			ErrorKind:      file.ErrorKind,
+			Error:          file.Error,
+			LogPath:        file.LogPath,
+		})
+	}
+	if _, err := p.store.AddBatch(batch); err != nil {
+		utils.Warn("%v", err)
+	}
+}
+
+// saveSegments 按配置将识别段落保存到数据库
+func (p *BatchProcessor) saveSegments(source string, segments []models.DataSegment) {
+	if p.store == nil || p.config == nil || !p.config.StoreSegments || len(segments) == 0 {
+		return
+	}
+	rows := make([]store.Segment, 0, len(segments))
+	for _, segment := range segments {
+		rows = append(rows, store.Segment{
+			Start:   segment.StartTime,
+			End:     segment.EndTime,
+			Text:    segment.Text,
+			Speaker: segment.Speaker,
+		})
+	}
+	if err := p.store.SaveSegments(source, rows); err != nil {
+		utils.Warn("%v", err)
+	}
+}
@@ -0,0 +1,74 @@
+package audio
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/models"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/store"
+)
+
+// TestRecordStoreImportsJSON 测试首次使用数据库时导入旧版 JSON 记录，修改后重新打开仍然保留
+func TestRecordStoreImportsJSON(t *testing.T) {
+	root := t.TempDir()
+	mediaDir := filepath.Join(root, "media")
+	outputDir := filepath.Join(root, "output")
+	tempDir := filepath.Join(root, "temp")
+	require.NoError(t, os.MkdirAll(outputDir, 0755))
+
+	oldPath := filepath.Join(mediaDir, "old.mp4")
+	legacy := filepath.Join(outputDir, "processed_records.json")
+	require.NoError(t, os.WriteFile(legacy, []byte(`{"`+oldPath+`": {"filename": "old.mp4", "completed": true}}`), 0644))
+
+	config := models.NewDefaultConfig()
+	processor := NewBatchProcessor(mediaDir, outputDir, tempDir, nil, config)
+	assert.True(t, processor.IsRecognizedFile(oldPath))
+	assert.NoFileExists(t, legacy)
+	assert.FileExists(t, legacy+importedSuffix)
+
+	newPath := filepath.Join(mediaDir, "new.mp4")
+	processor.updateProcessedRecord(newPath, &BatchResult{FilePath: newPath, Success: true, Service: "bcut"})
+	processor.UpdateProcessedRecordOnRename(oldPath, filepath.Join(mediaDir, "renamed.mp4"))
+	require.NoError(t, processor.Close())
+
+	reopened := NewBatchProcessor(mediaDir, outputDir, tempDir, nil, config)
+	defer reopened.Close()
+	assert.True(t, reopened.IsRecognizedFile(newPath))
+	assert.Equal(t, "bcut", reopened.processedRecords[newPath].Service)
+	_, exists := reopened.processedRecords[oldPath]
+	assert.False(t, exists, "重命名前的记录应已删除")
+	assert.Equal(t, "renamed.mp4", reopened.processedRecords[filepath.Join(mediaDir, "renamed.mp4")].Filename)
+
+	// 批次结果与识别段落
+	reopened.config.StoreSegments = true
+	reopened.saveSegments(newPath, []models.DataSegment{{Text: "大家好", StartTime: 0, EndTime: 1}})
+	reopened.recordBatch([]BatchResult{{FilePath: newPath, Success: true}}, time.Now())
+	segments, err := reopened.store.Segments(newPath)
+	require.NoError(t, err)
+	assert.Equal(t, []store.Segment{{Start: 0, End: 1, Text: "大家好"}}, segments)
+	batches, err := reopened.store.Batches(0)
+	require.NoError(t, err)
+	require.Len(t, batches, 1)
+	assert.Equal(t, 1, batches[0].Succeeded)
+}
+
+// TestRecordStoreJSONMode 测试配置为 json 时仍使用 processed_records.json
+func TestRecordStoreJSONMode(t *testing.T) {
+	root := t.TempDir()
+	outputDir := filepath.Join(root, "output")
+	config := models.NewDefaultConfig()
+	config.RecordsStore = "json"
+
+	processor := NewBatchProcessor(filepath.Join(root, "media"), outputDir, filepath.Join(root, "temp"), nil, config)
+	assert.Nil(t, processor.store)
+	path := filepath.Join(root, "media", "a.mp4")
+	processor.updateProcessedRecord(path, &BatchResult{FilePath: path, Success: true})
+
+	assert.FileExists(t, filepath.Join(outputDir, "processed_records.json"))
+	assert.NoFileExists(t, store.Path(outputDir))
+}
@@ -0,0 +1,190 @@
+package audio
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/audit"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/export"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/models"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/utils"
+)
+
+// 重新识别时暂存输出文件的目录（位于输出目录下），全部生成后再替换原有文件
+const reprocessDirName = ".reprocess"
+
+// ReprocessCandidates 返回由非首选服务识别、源文件仍然存在的文件
+func (p *BatchProcessor) ReprocessCandidates(preferred string) []string {
+	p.recordsMu.RLock()
+	defer p.recordsMu.RUnlock()
+
+	var files []string
+	for path, record := range p.processedRecords {
+		if !record.Completed || record.Status != "" || record.Service == "" || record.Service == preferred {
+			continue
+		}
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+		files = append(files, path)
+	}
+	sort.Strings(files)
+	return files
+}
+
+// ReprocessFallbacks 首选服务可用时，用其重新识别之前由备用服务识别的文件，替换原有输出并记录服务。
+// 首选服务在过程中变为不可用时停止，剩余文件留待下次处理。
+func (p *BatchProcessor) ReprocessFallbacks() []BatchResult {
+	preferred := p.config.PreferredASRService
+	if !p.config.AutoReprocess || preferred == "" || p.ASRSelector == nil {
+		return nil
+	}
+
+	var results []BatchResult
+	for _, filePath := range p.ReprocessCandidates(preferred) {
+		if !p.ASRSelector.IsSelectable(preferred) {
+			utils.Debug("首选ASR服务 %s 当前不可用，暂不重新识别", preferred)
+			break
+		}
+		if p.baseContext().Err() != nil {
+			break
+		}
+
+		p.acquireWorker()
+		result := p.reprocessFile(filePath, preferred)
+		p.releaseWorker()
+		results = append(results, result)
+	}
+	return results
+}
+
+// reprocessFile 使用指定服务重新识别文件。输出先写入暂存目录，识别成功后再逐个替换原有文件，
+// 失败时保留原有输出与记录不变
+func (p *BatchProcessor) reprocessFile(filePath, service string) BatchResult {
+	p.recordsMu.RLock()
+	previous := p.processedRecords[filepath.Clean(filePath)].Service
+	p.recordsMu.RUnlock()
+	utils.Info("使用首选ASR服务 %s 重新识别 %s（之前使用 %s）", service, filepath.Base(filePath), previous)
+
+	stageRoot := filepath.Join(p.OutputDir, reprocessDirName)
+	if err := os.MkdirAll(stageRoot, 0755); err != nil {
+		return BatchResult{FilePath: filePath, Error: fmt.Errorf("创建暂存目录失败: %w", err), ErrorKind: ErrorKindInput}
+	}
+	stageDir, err := os.MkdirTemp(stageRoot, FileTag(filePath)+"-")
+	if err != nil {
+		return BatchResult{FilePath: filePath, Error: fmt.Errorf("创建暂存目录失败: %w", err), ErrorKind: ErrorKindInput}
+	}
+	defer os.RemoveAll(stageDir)
+
+	stageConfig := *p.config
+	stageConfig.OutputFolder = filepath.Join(stageDir, "output")
+	stageConfig.MediaFolder = filepath.Join(stageDir, "media")
+
+	result := p.extractAudioFromFile(p.baseContext(), filePath)
+	if result.Success {
+		result.asrService = service
+		result.exportConfig = &stageConfig
+		_, outputFiles, err := p.PerformASROnAudio(&result)
+		if err == nil {
+			err = p.replaceArtifacts(outputFiles, &stageConfig)
+		}
+		if err == nil {
+			// 暂存目录中没有标签库，替换后按正式目录的标签重写Markdown
+			if err := export.SyncFrontmatter(p.config, filePath); err != nil {
+				utils.Warn("写入Markdown标签失败: %v", err)
+			}
+		}
+		if err != nil && result.Success {
+			result.setError(err, ErrorKindASR)
+		}
+	}
+	result.LogPath = result.log.Finish(&result)
+
+	if !result.Success {
+		utils.Warn("重新识别失败，保留原有结果: %s: %v", filePath, result.Error)
+		return result
+	}
+
+	p.updateProcessedRecord(filePath, &result)
+	p.recordsMu.Lock()
+	record := p.processedRecords[filepath.Clean(filePath)]
+	record.ReplacedService = previous
+	record.ReprocessedTime = time.Now().Format("2006-01-02 15:04:05")
+	p.setRecord(filepath.Clean(filePath), record)
+	p.recordsMu.Unlock()
+	if err := p.saveProcessedRecords(); err != nil {
+		utils.Warn("保存处理记录失败: %v", err)
+	}
+
+	utils.Info("已使用 %s 重新识别并替换输出: %s", result.Service, filepath.Base(filePath))
+	return result
+}
+
+// replaceArtifacts 将暂存目录中的输出文件移动到对应的正式位置，每个文件的替换是原子的
+func (p *BatchProcessor) replaceArtifacts(outputFiles map[string]string, stageConfig *models.Config) error {
+	for _, staged := range outputFiles {
+		target, ok := stagedTarget(staged, stageConfig.OutputFolder, p.config.OutputFolder)
+		if !ok {
+			target, ok = stagedTarget(staged, stageConfig.MediaFolder, p.config.MediaFolder)
+		}
+		if !ok {
+			return fmt.Errorf("无法确定输出文件的位置: %s", staged)
+		}
+
+		audit.RecordOverwrite(target, "reprocess", "使用首选ASR服务重新识别后替换输出")
+		if err := replaceFile(staged, target); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// stagedTarget 将暂存目录 stageDir 下的文件映射到正式目录 finalDir 下的相同位置
+func stagedTarget(staged, stageDir, finalDir string) (string, bool) {
+	rel, err := filepath.Rel(stageDir, staged)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return filepath.Join(finalDir, rel), true
+}
+
+// replaceFile 用 src 替换 dst。先复制到 dst 所在目录的临时文件再重命名，
+// 即使暂存目录与目标位于不同的文件系统，替换也是原子的
+func replaceFile(src, dst string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return fmt.Errorf("创建输出目录失败: %w", err)
+	}
+	if err := os.Rename(src, dst); err == nil {
+		return nil
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("读取暂存文件失败: %w", err)
+	}
+	defer in.Close()
+
+	tmp, err := os.CreateTemp(filepath.Dir(dst), "."+filepath.Base(dst)+"-*")
+	if err != nil {
+		return fmt.Errorf("创建临时文件失败: %w", err)
+	}
+	if _, err := io.Copy(tmp, in); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("写入输出文件失败: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("写入输出文件失败: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), dst); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("替换输出文件失败: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package audio
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestReprocessCandidates 测试只选择由非首选服务识别且源文件存在的记录
+func TestReprocessCandidates(t *testing.T) {
+	dir := t.TempDir()
+	fallback := filepath.Join(dir, "fallback.mp4")
+	preferred := filepath.Join(dir, "preferred.mp4")
+	skipped := filepath.Join(dir, "skipped.mp4")
+	for _, path := range []string{fallback, preferred, skipped} {
+		require.NoError(t, os.WriteFile(path, []byte("x"), 0644))
+	}
+
+	processor := &BatchProcessor{processedRecords: map[string]ProcessedRecord{
+		fallback:                        {Completed: true, Service: "kuaishou"},
+		preferred:                       {Completed: true, Service: "bcut"},
+		skipped:                         {Completed: true, Status: StatusNoAudio},
+		filepath.Join(dir, "gone.mp4"):  {Completed: true, Service: "kuaishou"},
+		filepath.Join(dir, "older.mp4"): {Completed: true},
+	}}
+
+	assert.Equal(t, []string{fallback}, processor.ReprocessCandidates("bcut"))
+}
+
+// TestReplaceStagedFile 测试暂存的输出文件替换到对应的正式位置
+func TestReplaceStagedFile(t *testing.T) {
+	dir := t.TempDir()
+
+	finalOutput := filepath.Join(dir, "output")
+	finalMedia := filepath.Join(dir, "media")
+	stageOutput := filepath.Join(dir, "stage", "output")
+	stageMedia := filepath.Join(dir, "stage", "media")
+
+	target, ok := stagedTarget(filepath.Join(stageOutput, "demo", "demo.txt"), stageOutput, finalOutput)
+	assert.True(t, ok)
+	assert.Equal(t, filepath.Join(finalOutput, "demo", "demo.txt"), target)
+	_, ok = stagedTarget(filepath.Join(stageMedia, "demo.srt"), stageOutput, finalOutput)
+	assert.False(t, ok)
+
+	require.NoError(t, os.MkdirAll(stageMedia, 0755))
+	require.NoError(t, os.MkdirAll(finalMedia, 0755))
+	staged := filepath.Join(stageMedia, "demo.srt")
+	final := filepath.Join(finalMedia, "demo.srt")
+	require.NoError(t, os.WriteFile(staged, []byte("new"), 0644))
+	require.NoError(t, os.WriteFile(final, []byte("old"), 0644))
+
+	require.NoError(t, replaceFile(staged, final))
+	data, err := os.ReadFile(final)
+	require.NoError(t, err)
+	assert.Equal(t, "new", string(data))
+	assert.NoFileExists(t, staged)
+}
@@ -0,0 +1,66 @@
+package audio
+
+import (
+	"sync"
+)
+
+// SegmentEventType 片段分割事件类型
+type SegmentEventType int
+
+const (
+	SegmentSplitStarted  SegmentEventType = iota // 开始分割，Total 为预计片段数
+	SegmentExported                              // 导出了一个片段
+	SegmentSplitFinished                         // 分割完成
+	SegmentSplitFailed                           // 分割失败，Err 为失败原因
+)
+
+// SegmentEvent 片段分割过程中发布的事件，按输入文件区分，多个文件并发分割时互不干扰
+type SegmentEvent struct {
+	Type      SegmentEventType
+	File      string // 被分割的音频文件路径
+	Completed int    // 已导出的片段数
+	Total     int    // 预计片段总数
+	Err       error
+}
+
+// SegmentEventHandler 片段事件处理函数，在发布事件的协程中同步调用，不应阻塞
+type SegmentEventHandler func(event SegmentEvent)
+
+var (
+	segmentSubscribersMu  sync.RWMutex
+	segmentSubscribers    = make(map[int]SegmentEventHandler)
+	nextSegmentSubscriber int
+)
+
+// SubscribeSegmentEvents 订阅片段分割事件，返回取消订阅函数
+func SubscribeSegmentEvents(handler SegmentEventHandler) func() {
+	segmentSubscribersMu.Lock()
+	defer segmentSubscribersMu.Unlock()
+
+	id := nextSegmentSubscriber
+	nextSegmentSubscriber++
+	segmentSubscribers[id] = handler
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			segmentSubscribersMu.Lock()
+			defer segmentSubscribersMu.Unlock()
+			delete(segmentSubscribers, id)
+		})
+	}
+}
+
+// publishSegmentEvent 将事件分发给所有订阅者
+func publishSegmentEvent(event SegmentEvent) {
+	segmentSubscribersMu.RLock()
+	handlers := make([]SegmentEventHandler, 0, len(segmentSubscribers))
+	for _, handler := range segmentSubscribers {
+		handlers = append(handlers, handler)
+	}
+	segmentSubscribersMu.RUnlock()
+
+	for _, handler := range handlers {
+		handler(event)
+	}
+}
@@ -0,0 +1,389 @@
+package audio
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/utils"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/webauth"
+	"github.com/google/uuid"
+)
+
+// 未完成的分块上传在上传目录中的子目录
+const partialUploadDir = ".partial"
+
+// 未配置时建议的分块大小
+const defaultUploadChunkSize = 8 << 20
+
+// 分块上传的错误
+var (
+	ErrUploadNotFound   = errors.New("上传会话不存在")
+	ErrUploadOffset     = errors.New("上传偏移与已接收的字节数不一致")
+	ErrUploadIncomplete = errors.New("文件尚未上传完成")
+	ErrUploadTooLarge   = errors.New("文件超过大小上限")
+)
+
+// UploadSession 一次分块上传的状态，Offset 为服务器已接收的字节数，断线后从这里继续
+type UploadSession struct {
+	ID        string    `json:"id"`
+	User      string    `json:"user,omitempty"` // 创建会话的用户，未启用认证时为空
+	FileName  string    `json:"file_name"`
+	Size      int64     `json:"size"`
+	Offset    int64     `json:"offset"`
+	ChunkSize int64     `json:"chunk_size"` // 建议客户端每块的大小
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"` // 最后一次收到数据的时间
+}
+
+// UploadStore 可续传的分块上传：数据按顺序追加到上传目录下 .partial 中的临时文件，
+// 接收完整后移入上传目录再提交处理。会话保存在磁盘上，服务重启后仍可继续上传
+type UploadStore struct {
+	dir       string
+	web       *WebProcessor
+	chunkSize int64
+
+	mu    sync.Mutex
+	locks map[string]*sync.Mutex // 每个会话一把锁，同一会话的分块依次写入
+}
+
+// NewUploadStore 创建分块上传的会话存储
+func NewUploadStore(web *WebProcessor) *UploadStore {
+	store := &UploadStore{
+		dir:       filepath.Join(web.UploadDir, partialUploadDir),
+		web:       web,
+		chunkSize: defaultUploadChunkSize,
+		locks:     make(map[string]*sync.Mutex),
+	}
+	if web.Config != nil && web.Config.WebUploadChunkMB > 0 {
+		store.chunkSize = int64(web.Config.WebUploadChunkMB) << 20
+	}
+	return store
+}
+
+// metaPath 会话信息文件
+func (s *UploadStore) metaPath(id string) string {
+	return filepath.Join(s.dir, id+".json")
+}
+
+// partPath 已接收数据的临时文件
+func (s *UploadStore) partPath(id string) string {
+	return filepath.Join(s.dir, id+".part")
+}
+
+// lock 锁定会话，返回解锁函数
+func (s *UploadStore) lock(id string) func() {
+	s.mu.Lock()
+	l, exists := s.locks[id]
+	if !exists {
+		l = &sync.Mutex{}
+		s.locks[id] = l
+	}
+	s.mu.Unlock()
+	l.Lock()
+	return l.Unlock
+}
+
+// forget 删除会话的锁，会话结束后调用
+func (s *UploadStore) forget(id string) {
+	s.mu.Lock()
+	delete(s.locks, id)
+	s.mu.Unlock()
+}
+
+// Create 为 user 创建上传会话，filename 为原文件名（用于判断格式），size 为文件总大小
+func (s *UploadStore) Create(user, filename string, size int64) (UploadSession, error) {
+	if !s.web.Processor.IsSupportedFile(filename) {
+		return UploadSession{}, fmt.Errorf("不支持的文件格式: %s", strings.ToLower(filepath.Ext(filename)))
+	}
+	if size <= 0 {
+		return UploadSession{}, fmt.Errorf("文件大小无效: %d", size)
+	}
+	if size > s.web.MaxFileSize {
+		return UploadSession{}, fmt.Errorf("%w: %s > %s", ErrUploadTooLarge,
+			utils.FormatFileSize(size), utils.FormatFileSize(s.web.MaxFileSize))
+	}
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return UploadSession{}, fmt.Errorf("创建上传目录失败: %w", err)
+	}
+
+	now := time.Now()
+	session := UploadSession{
+		ID:        uuid.New().String(),
+		User:      user,
+		FileName:  filepath.Base(filename),
+		Size:      size,
+		ChunkSize: s.chunkSize,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := os.WriteFile(s.partPath(session.ID), nil, 0644); err != nil {
+		return UploadSession{}, fmt.Errorf("创建上传文件失败: %w", err)
+	}
+	data, _ := json.Marshal(session)
+	if err := os.WriteFile(s.metaPath(session.ID), data, 0644); err != nil {
+		os.Remove(s.partPath(session.ID))
+		return UploadSession{}, fmt.Errorf("保存上传会话失败: %w", err)
+	}
+	utils.Info("创建分块上传 %s: %s (%s)", session.ID, session.FileName, utils.FormatFileSize(size))
+	return session, nil
+}
+
+// Get 返回会话的当前状态，已接收的字节数以临时文件的大小为准
+func (s *UploadStore) Get(id string) (UploadSession, error) {
+	// ID 用于拼接路径，只接受 Create 生成的 UUID
+	if _, err := uuid.Parse(id); err != nil {
+		return UploadSession{}, ErrUploadNotFound
+	}
+	data, err := os.ReadFile(s.metaPath(id))
+	if err != nil {
+		return UploadSession{}, ErrUploadNotFound
+	}
+	var session UploadSession
+	if err := json.Unmarshal(data, &session); err != nil {
+		return UploadSession{}, fmt.Errorf("解析上传会话失败: %w", err)
+	}
+	info, err := os.Stat(s.partPath(id))
+	if err != nil {
+		return UploadSession{}, ErrUploadNotFound
+	}
+	session.Offset = info.Size()
+	session.UpdatedAt = info.ModTime()
+	return session, nil
+}
+
+// Append 从 offset 处追加一块数据，offset 必须等于已接收的字节数。
+// 传输中断时已写入的部分保留，客户端查询 Offset 后继续
+func (s *UploadStore) Append(id string, offset int64, body io.Reader) (UploadSession, error) {
+	unlock := s.lock(id)
+	defer unlock()
+
+	session, err := s.Get(id)
+	if err != nil {
+		return session, err
+	}
+	if offset != session.Offset {
+		return session, fmt.Errorf("%w: 请求 %d，已接收 %d", ErrUploadOffset, offset, session.Offset)
+	}
+
+	f, err := os.OpenFile(s.partPath(id), os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return session, fmt.Errorf("打开上传文件失败: %w", err)
+	}
+	remaining := session.Size - session.Offset
+	written, copyErr := io.Copy(f, io.LimitReader(body, remaining))
+	closeErr := f.Close()
+	session.Offset += written
+	session.UpdatedAt = time.Now()
+
+	switch {
+	case copyErr != nil:
+		return session, fmt.Errorf("接收数据失败: %w", copyErr)
+	case closeErr != nil:
+		return session, fmt.Errorf("写入上传文件失败: %w", closeErr)
+	}
+	// 超出声明大小的数据不写入
+	if written == remaining {
+		if n, _ := body.Read(make([]byte, 1)); n > 0 {
+			return session, fmt.Errorf("%w: 数据超过声明的大小 %d", ErrUploadTooLarge, session.Size)
+		}
+	}
+	return session, nil
+}
+
+// Complete 将接收完整的文件移入会话所属用户的上传目录，返回保存的路径
+func (s *UploadStore) Complete(id string) (string, UploadSession, error) {
+	unlock := s.lock(id)
+	defer unlock()
+
+	session, err := s.Get(id)
+	if err != nil {
+		return "", session, err
+	}
+	if session.Offset != session.Size {
+		return "", session, fmt.Errorf("%w: 已接收 %d / %d 字节", ErrUploadIncomplete, session.Offset, session.Size)
+	}
+
+	path := filepath.Join(s.web.ForUser(session.User).UploadDir, uuid.New().String()+strings.ToLower(filepath.Ext(session.FileName)))
+	if err := os.Rename(s.partPath(id), path); err != nil {
+		return "", session, fmt.Errorf("保存上传文件失败: %w", err)
+	}
+	os.Remove(s.metaPath(id))
+	s.forget(id)
+	utils.Info("分块上传完成 %s: %s", id, session.FileName)
+	return path, session, nil
+}
+
+// Abort 取消上传并删除已接收的数据
+func (s *UploadStore) Abort(id string) error {
+	unlock := s.lock(id)
+	defer unlock()
+
+	if _, err := s.Get(id); err != nil {
+		return err
+	}
+	os.Remove(s.partPath(id))
+	os.Remove(s.metaPath(id))
+	s.forget(id)
+	return nil
+}
+
+// Cleanup 删除超过 maxAge 没有收到数据的会话，返回删除的数量
+func (s *UploadStore) Cleanup(maxAge time.Duration) (int, error) {
+	entries, err := os.ReadDir(s.dir)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	for _, entry := range entries {
+		id, ok := strings.CutSuffix(entry.Name(), ".json")
+		if !ok {
+			continue
+		}
+		unlock := s.lock(id)
+		session, err := s.Get(id)
+		if err == nil && time.Since(session.UpdatedAt) <= maxAge {
+			unlock()
+			continue
+		}
+		// 临时文件已丢失的会话同样删除
+		os.Remove(s.partPath(id))
+		os.Remove(s.metaPath(id))
+		unlock()
+		s.forget(id)
+		removed++
+		utils.Info("已删除过期的分块上传 %s", id)
+	}
+	return removed, nil
+}
+
+// uploadErrorStatus 返回分块上传错误对应的HTTP状态码
+func uploadErrorStatus(err error, fallback int) int {
+	switch {
+	case errors.Is(err, ErrUploadNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, ErrUploadOffset), errors.Is(err, ErrUploadIncomplete):
+		return http.StatusConflict
+	case errors.Is(err, ErrUploadTooLarge):
+		return http.StatusRequestEntityTooLarge
+	}
+	return fallback
+}
+
+// createUploadRequest 创建上传会话的请求
+type createUploadRequest struct {
+	FileName string `json:"file_name"`
+	Size     int64  `json:"size"`
+}
+
+// completeUploadRequest 完成上传时的可选设置
+type completeUploadRequest struct {
+	CallbackURL string `json:"callback_url"` // 任务结束后的回调地址
+}
+
+// UploadsHandler 返回可续传的分块上传接口：
+//
+//	POST   prefix                 {"file_name": "...", "size": N} 创建会话，返回 201 与会话（含建议的 chunk_size）
+//	GET    prefix/<ID>            查询会话，offset 为已接收的字节数，断线后从这里继续
+//	PATCH  prefix/<ID>            请求头 Upload-Offset 为本块的起始位置，请求体为本块数据
+//	POST   prefix/<ID>/complete   接收完整后提交处理，返回 202 与任务（见 WebJobsHandler），可带 {"callback_url": "..."}
+//	DELETE prefix/<ID>            取消上传
+//
+// 偏移不一致时返回 409 与当前会话，客户端按返回的 offset 继续。启用认证时会话只对创建的用户可见
+func UploadsHandler(store *UploadStore, queue *WebJobQueue, prefix string) http.Handler {
+	return http.StripPrefix(prefix, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, sub, _ := strings.Cut(strings.Trim(r.URL.Path, "/"), "/")
+		user := webauth.User(r.Context())
+		// 其他用户的会话视为不存在，会话不存在的情况由各请求自行处理
+		if session, err := store.Get(id); id != "" && err == nil && session.User != user {
+			writeJobError(w, ErrUploadNotFound.Error(), http.StatusNotFound)
+			return
+		}
+		switch {
+		case id == "" && r.Method == http.MethodPost:
+			var req createUploadRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				writeJobError(w, fmt.Sprintf("解析请求失败: %v", err), http.StatusBadRequest)
+				return
+			}
+			session, err := store.Create(user, req.FileName, req.Size)
+			if err != nil {
+				writeJobError(w, err.Error(), uploadErrorStatus(err, http.StatusBadRequest))
+				return
+			}
+			w.Header().Set("Location", strings.TrimSuffix(prefix, "/")+"/"+session.ID)
+			writeUploadSession(w, http.StatusCreated, session)
+		case id != "" && sub == "" && (r.Method == http.MethodGet || r.Method == http.MethodHead):
+			session, err := store.Get(id)
+			if err != nil {
+				writeJobError(w, err.Error(), uploadErrorStatus(err, http.StatusInternalServerError))
+				return
+			}
+			writeUploadSession(w, http.StatusOK, session)
+		case id != "" && sub == "" && r.Method == http.MethodPatch:
+			offset, err := strconv.ParseInt(r.Header.Get("Upload-Offset"), 10, 64)
+			if err != nil {
+				writeJobError(w, "缺少或无效的 Upload-Offset 请求头", http.StatusBadRequest)
+				return
+			}
+			session, err := store.Append(id, offset, r.Body)
+			if errors.Is(err, ErrUploadOffset) {
+				writeUploadSession(w, http.StatusConflict, session)
+				return
+			}
+			if err != nil {
+				utils.Warn("分块上传 %s 接收失败: %v", id, err)
+				writeJobError(w, err.Error(), uploadErrorStatus(err, http.StatusInternalServerError))
+				return
+			}
+			writeUploadSession(w, http.StatusOK, session)
+		case id != "" && sub == "complete" && r.Method == http.MethodPost:
+			// 请求体可以为空，或 {"callback_url": "..."} 在任务结束后回调
+			var req completeUploadRequest
+			if err := json.NewDecoder(io.LimitReader(r.Body, 1<<20)).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+				writeJobError(w, fmt.Sprintf("解析请求失败: %v", err), http.StatusBadRequest)
+				return
+			}
+			if req.CallbackURL != "" {
+				if err := validateCallbackURL(req.CallbackURL, queue.web.Config.WebhookAllowedHosts); err != nil {
+					writeJobError(w, err.Error(), http.StatusBadRequest)
+					return
+				}
+			}
+			path, session, err := store.Complete(id)
+			if err != nil {
+				writeJobError(w, err.Error(), uploadErrorStatus(err, http.StatusInternalServerError))
+				return
+			}
+			writeJobJSON(w, http.StatusAccepted, queue.enqueue(fileJob(session.User, path, session.FileName), req.CallbackURL))
+		case id != "" && sub == "" && r.Method == http.MethodDelete:
+			if err := store.Abort(id); err != nil {
+				writeJobError(w, err.Error(), uploadErrorStatus(err, http.StatusInternalServerError))
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		case sub != "" && sub != "complete":
+			http.NotFound(w, r)
+		default:
+			writeJobError(w, "不支持的请求方法", http.StatusMethodNotAllowed)
+		}
+	}))
+}
+
+// writeUploadSession 返回会话，并在 Upload-Offset 响应头中给出已接收的字节数
+func writeUploadSession(w http.ResponseWriter, status int, session UploadSession) {
+	w.Header().Set("Upload-Offset", strconv.FormatInt(session.Offset, 10))
+	writeJobJSON(w, status, session)
+}
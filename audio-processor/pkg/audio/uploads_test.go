@@ -0,0 +1,155 @@
+package audio
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/webauth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUploadStore(t *testing.T) {
+	_, web := newTestWebJobQueue(t, nil)
+	store := NewUploadStore(web)
+
+	_, err := store.Create("", "notes.pdf", 10)
+	assert.Error(t, err, "不支持的格式")
+	_, err = store.Create("", "huge.mp4", web.MaxFileSize+1)
+	assert.ErrorIs(t, err, ErrUploadTooLarge)
+
+	session, err := store.Create("", "video.mp4", 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(web.Config.WebUploadChunkMB)<<20, session.ChunkSize)
+
+	session, err = store.Append(session.ID, 0, strings.NewReader("hello"))
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), session.Offset)
+
+	// 重发已接收的块时返回当前偏移
+	session, err = store.Append(session.ID, 0, strings.NewReader("hello"))
+	assert.ErrorIs(t, err, ErrUploadOffset)
+	assert.Equal(t, int64(5), session.Offset)
+
+	_, _, err = store.Complete(session.ID)
+	assert.ErrorIs(t, err, ErrUploadIncomplete)
+
+	// 超出声明大小的数据不写入
+	session, err = store.Append(session.ID, 5, strings.NewReader("world!!"))
+	assert.ErrorIs(t, err, ErrUploadTooLarge)
+	assert.Equal(t, int64(10), session.Offset)
+
+	path, session, err := store.Complete(session.ID)
+	require.NoError(t, err)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "helloworld", string(data))
+	assert.Equal(t, ".mp4", filepath.Ext(path))
+	_, err = store.Get(session.ID)
+	assert.ErrorIs(t, err, ErrUploadNotFound, "完成后会话删除")
+
+	_, err = store.Get("../../etc/passwd")
+	assert.ErrorIs(t, err, ErrUploadNotFound)
+
+	// 其他用户的会话不可见，完成后移入所属用户的上传目录
+	session, err = store.Create("alice", "talk.mp3", 2)
+	require.NoError(t, err)
+	handler := UploadsHandler(store, nil, "/api/uploads")
+	req := httptest.NewRequest(http.MethodGet, "/api/uploads/"+session.ID, nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req.WithContext(webauth.WithUser(req.Context(), "bob")))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	_, err = store.Append(session.ID, 0, strings.NewReader("ok"))
+	require.NoError(t, err)
+	path, _, err = store.Complete(session.ID)
+	require.NoError(t, err)
+	assert.Equal(t, webauth.UserDir(web.UploadDir, "alice"), filepath.Dir(path))
+}
+
+func TestUploadStoreCleanup(t *testing.T) {
+	_, web := newTestWebJobQueue(t, nil)
+	store := NewUploadStore(web)
+
+	stale, err := store.Create("", "old.mp3", 10)
+	require.NoError(t, err)
+	fresh, err := store.Create("", "new.mp3", 10)
+	require.NoError(t, err)
+	old := time.Now().Add(-48 * time.Hour)
+	require.NoError(t, os.Chtimes(store.partPath(stale.ID), old, old))
+
+	removed, err := store.Cleanup(24 * time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, 1, removed)
+	_, err = store.Get(stale.ID)
+	assert.ErrorIs(t, err, ErrUploadNotFound)
+	_, err = store.Get(fresh.ID)
+	assert.NoError(t, err)
+
+	require.NoError(t, store.Abort(fresh.ID))
+	_, err = store.Get(fresh.ID)
+	assert.ErrorIs(t, err, ErrUploadNotFound)
+}
+
+func TestUploadsHandler(t *testing.T) {
+	queue, web := newTestWebJobQueue(t, func(path string) (*WebResult, error) {
+		return &WebResult{Success: true}, nil
+	})
+	queue.Start(1)
+	defer queue.Close()
+	handler := UploadsHandler(NewUploadStore(web), queue, "/api/uploads")
+
+	serve := func(method, target, body string, header map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		for key, value := range header {
+			req.Header.Set(key, value)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := serve(http.MethodPost, "/api/uploads", `{"file_name":"talk.m4a","size":6}`, nil)
+	require.Equal(t, http.StatusCreated, rec.Code)
+	var session UploadSession
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&session))
+	target := "/api/uploads/" + session.ID
+	assert.Equal(t, target, rec.Header().Get("Location"))
+
+	rec = serve(http.MethodPatch, target, "abc", map[string]string{"Upload-Offset": "0"})
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "3", rec.Header().Get("Upload-Offset"))
+
+	// 偏移不一致时返回 409 与当前偏移，客户端从这里继续
+	rec = serve(http.MethodPatch, target, "abc", map[string]string{"Upload-Offset": "0"})
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	offset, err := strconv.Atoi(rec.Header().Get("Upload-Offset"))
+	require.NoError(t, err)
+
+	rec = serve(http.MethodPost, target+"/complete", "", nil)
+	assert.Equal(t, http.StatusConflict, rec.Code, "未接收完整时不能提交")
+
+	rec = serve(http.MethodPatch, target, "def", map[string]string{"Upload-Offset": strconv.Itoa(offset)})
+	require.Equal(t, http.StatusOK, rec.Code)
+	rec = serve(http.MethodGet, target, "", nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "6", rec.Header().Get("Upload-Offset"))
+
+	rec = serve(http.MethodPost, target+"/complete", "", nil)
+	require.Equal(t, http.StatusAccepted, rec.Code)
+	var job WebJob
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&job))
+	assert.Equal(t, "talk.m4a", job.FileName)
+	assert.Equal(t, WebJobCompleted, waitJob(t, queue, job.ID).Status)
+
+	rec = serve(http.MethodPatch, target, "x", nil)
+	assert.Equal(t, http.StatusBadRequest, rec.Code, "缺少 Upload-Offset")
+	rec = serve(http.MethodGet, target, "", nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
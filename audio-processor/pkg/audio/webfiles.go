@@ -0,0 +1,247 @@
+package audio
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/webauth"
+)
+
+// 任务列表的分页大小
+const (
+	defaultJobsPerPage = 20
+	maxJobsPerPage     = 100
+)
+
+// 任务输出文件的错误
+var (
+	ErrWebJobUnfinished = errors.New("任务尚未完成")
+	ErrWebFileNotFound  = errors.New("文件不存在")
+)
+
+// WebJobPage 分页的任务列表
+type WebJobPage struct {
+	Jobs    []WebJob `json:"jobs"`
+	Total   int      `json:"total"` // 符合条件的任务总数
+	Page    int      `json:"page"`
+	PerPage int      `json:"per_page"`
+}
+
+// WebJobFile 任务生成的一个输出文件
+type WebJobFile struct {
+	ID     string `json:"id"`   // 下载时使用的文件ID：<任务ID>.<格式>
+	Type   string `json:"type"` // 格式，如 txt、srt、json
+	Name   string `json:"name"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256,omitempty"`
+	URL    string `json:"url,omitempty"` // 下载地址，注册了 WebFilesHandler 时提供
+
+	path string
+}
+
+// History 按提交时间倒序返回 user 的第 page 页任务（从 1 开始），status 非空时只返回该状态的任务。
+// 列表不包含处理结果，第二个返回值为符合条件的任务总数
+func (q *WebJobQueue) History(user, status string, page, perPage int) ([]WebJob, int) {
+	jobs := q.List(user)
+	matched := make([]WebJob, 0, len(jobs))
+	for i := len(jobs) - 1; i >= 0; i-- {
+		if status == "" || jobs[i].Status == status {
+			matched = append(matched, jobs[i])
+		}
+	}
+	start := (page - 1) * perPage
+	if start >= len(matched) {
+		return []WebJob{}, len(matched)
+	}
+	end := start + perPage
+	if end > len(matched) {
+		end = len(matched)
+	}
+	return matched[start:end], len(matched)
+}
+
+// Files 返回 user 的已完成任务生成的输出文件，只包含仍然存在的文件
+func (q *WebJobQueue) Files(user, id string) ([]WebJobFile, error) {
+	job, err := q.getOwned(user, id)
+	if err != nil {
+		return nil, err
+	}
+	if !job.Finished() {
+		return nil, ErrWebJobUnfinished
+	}
+	if job.Result == nil {
+		return []WebJobFile{}, nil
+	}
+
+	q.mu.Lock()
+	prefix := q.filesPrefix
+	q.mu.Unlock()
+	web := q.web.ForUser(user)
+	config := web.Config
+	roots := []string{web.OutputDir, config.OutputFolder, config.MediaFolder}
+	files := []WebJobFile{}
+	seen := make(map[string]bool)
+	add := func(fileType, path, sha256 string) {
+		// 文件路径来自任务记录，仍只返回用户目录中的文件
+		if seen[fileType] || !withinAny(path, roots) {
+			return
+		}
+		info, err := os.Stat(path)
+		if err != nil || info.IsDir() {
+			return
+		}
+		seen[fileType] = true
+		file := WebJobFile{
+			ID:     job.ID + "." + fileType,
+			Type:   fileType,
+			Name:   filepath.Base(path),
+			Size:   info.Size(),
+			SHA256: sha256,
+			path:   path,
+		}
+		if prefix != "" {
+			file.URL = strings.TrimSuffix(prefix, "/") + "/" + file.ID
+		}
+		files = append(files, file)
+	}
+
+	if manifest := job.Result.Manifest; manifest != nil {
+		for _, entry := range manifest.Outputs {
+			add(entry.Type, entry.Resolve(config.OutputFolder, config.MediaFolder), entry.SHA256)
+		}
+	}
+	types := make([]string, 0, len(job.Result.OutputFiles))
+	for fileType := range job.Result.OutputFiles {
+		types = append(types, fileType)
+	}
+	sort.Strings(types)
+	for _, fileType := range types {
+		add(fileType, job.Result.OutputFiles[fileType], "")
+	}
+	return files, nil
+}
+
+// File 按文件ID（<任务ID>.<格式>）返回 user 的任务输出文件
+func (q *WebJobQueue) File(user, fileID string) (WebJobFile, error) {
+	id, fileType, ok := strings.Cut(fileID, ".")
+	if !ok {
+		return WebJobFile{}, ErrWebFileNotFound
+	}
+	files, err := q.Files(user, id)
+	if err != nil {
+		return WebJobFile{}, ErrWebFileNotFound
+	}
+	for _, file := range files {
+		if file.Type == fileType {
+			return file, nil
+		}
+	}
+	return WebJobFile{}, ErrWebFileNotFound
+}
+
+// withinAny 判断 path 是否位于 roots 中的某个目录内
+func withinAny(path string, roots []string) bool {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	for _, root := range roots {
+		if root == "" {
+			continue
+		}
+		root, err := filepath.Abs(root)
+		if err != nil {
+			continue
+		}
+		rel, err := filepath.Rel(root, path)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) && !filepath.IsAbs(rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// serveJobHistory 处理 GET prefix?status=&page=&per_page=
+func (q *WebJobQueue) serveJobHistory(w http.ResponseWriter, r *http.Request, user string) {
+	query := r.URL.Query()
+	status := query.Get("status")
+	switch status {
+	case "", WebJobQueued, WebJobRunning, WebJobCompleted, WebJobFailed:
+	default:
+		writeJobError(w, fmt.Sprintf("无效的状态: %s", status), http.StatusBadRequest)
+		return
+	}
+	page, err := queryPositive(query.Get("page"), 1)
+	if err != nil {
+		writeJobError(w, "无效的 page 参数", http.StatusBadRequest)
+		return
+	}
+	perPage, err := queryPositive(query.Get("per_page"), defaultJobsPerPage)
+	if err != nil {
+		writeJobError(w, "无效的 per_page 参数", http.StatusBadRequest)
+		return
+	}
+	if perPage > maxJobsPerPage {
+		perPage = maxJobsPerPage
+	}
+	jobs, total := q.History(user, status, page, perPage)
+	writeJobJSON(w, http.StatusOK, WebJobPage{Jobs: jobs, Total: total, Page: page, PerPage: perPage})
+}
+
+// queryPositive 解析正整数查询参数，为空时返回 fallback
+func queryPositive(value string, fallback int) (int, error) {
+	if value == "" {
+		return fallback, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("无效的参数: %s", value)
+	}
+	return n, nil
+}
+
+// serveJobFiles 处理 GET prefix/<ID>/files
+func (q *WebJobQueue) serveJobFiles(w http.ResponseWriter, user, id string) {
+	files, err := q.Files(user, id)
+	switch {
+	case errors.Is(err, ErrWebJobUnfinished):
+		writeJobError(w, err.Error(), http.StatusConflict)
+	case err != nil:
+		writeJobError(w, err.Error(), http.StatusNotFound)
+	default:
+		writeJobJSON(w, http.StatusOK, files)
+	}
+}
+
+// WebFilesHandler 返回任务输出文件的下载接口：GET prefix/<文件ID> 以附件下载，
+// 文件ID 见 GET /api/jobs/<ID>/files 的返回。只能下载当前用户任务的文件，
+// 路径取自任务记录，请求中不包含任何文件路径
+func WebFilesHandler(q *WebJobQueue, prefix string) http.Handler {
+	q.mu.Lock()
+	q.filesPrefix = prefix
+	q.mu.Unlock()
+	return http.StripPrefix(prefix, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			writeJobError(w, "不支持的请求方法", http.StatusMethodNotAllowed)
+			return
+		}
+		file, err := q.File(webauth.User(r.Context()), strings.Trim(r.URL.Path, "/"))
+		if err != nil {
+			writeJobError(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename*=UTF-8''%s", url.PathEscape(file.Name)))
+		if file.SHA256 != "" {
+			w.Header().Set("X-Checksum-SHA256", file.SHA256)
+		}
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		http.ServeFile(w, r, file.path)
+	}))
+}
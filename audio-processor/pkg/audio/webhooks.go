@@ -0,0 +1,268 @@
+package audio
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/utils"
+)
+
+// 回调事件
+const (
+	WebhookJobCompleted = "job.completed"
+	WebhookJobFailed    = "job.failed"
+)
+
+// 回调的投递状态
+const (
+	CallbackPending   = "pending"   // 等待投递或正在重试，服务重启后重新投递
+	CallbackDelivered = "delivered" // 回调地址返回了 2xx
+	CallbackFailed    = "failed"    // 重试次数用完或回调地址拒绝
+)
+
+// 回调请求头
+const (
+	WebhookSignatureHeader = "X-Webhook-Signature" // sha256=<HMAC-SHA256(密钥, 时间戳 + "." + 请求体) 的十六进制>
+	WebhookTimestampHeader = "X-Webhook-Timestamp" // 签名时的 Unix 时间戳（秒），接收方可据此拒绝过旧的请求
+	WebhookEventHeader     = "X-Webhook-Event"
+	WebhookDeliveryHeader  = "X-Webhook-Delivery" // 任务ID，重试与重启后重新投递时不变，可用于去重
+)
+
+// 回调中转写文本摘录的最大字数
+const webhookExcerptRunes = 500
+
+// WebhookPayload 任务结束后 POST 到回调地址的 JSON
+type WebhookPayload struct {
+	Event      string             `json:"event"` // job.completed 或 job.failed
+	JobID      string             `json:"job_id"`
+	Status     string             `json:"status"`
+	FileName   string             `json:"file_name"`
+	SourceURL  string             `json:"source_url,omitempty"`
+	Error      string             `json:"error,omitempty"`
+	CreatedAt  time.Time          `json:"created_at"`
+	FinishedAt time.Time          `json:"finished_at"`
+	Transcript *WebhookTranscript `json:"transcript,omitempty"` // 处理成功时的转写摘要
+	Files      []WebJobFile       `json:"files"`                // 生成的输出文件，url 需携带凭据下载
+}
+
+// WebhookTranscript 回调中的转写摘要，完整内容通过 Files 下载
+type WebhookTranscript struct {
+	Segments   int     `json:"segments"`
+	Characters int     `json:"characters"`
+	Duration   float64 `json:"duration"`         // 最后一段的结束时间（秒）
+	Excerpt    string  `json:"excerpt"`          // 开头的部分文本
+	Status     string  `json:"status,omitempty"` // 成功但未识别时的状态，如 "无音频"
+}
+
+// SignWebhook 返回回调请求的签名，接收方以同样方式计算后比较 X-Webhook-Signature
+func SignWebhook(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10) + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// validateCallbackURL 检查回调地址：只接受 http 与 https，主机解析到本机、内网或链路本地地址时拒绝，
+// 避免提交任务的人借服务器向内网发送请求。allowed（webhook_allowed_hosts）中的主机不检查地址
+func validateCallbackURL(callbackURL string, allowed []string) error {
+	u, err := url.Parse(callbackURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("无效的回调地址: %s", callbackURL)
+	}
+	host := u.Hostname()
+	for _, name := range allowed {
+		if strings.EqualFold(strings.TrimSpace(name), host) {
+			return nil
+		}
+	}
+
+	ips, err := lookupCallbackHost(host)
+	if err != nil {
+		return fmt.Errorf("无法解析回调地址 %s: %w", host, err)
+	}
+	for _, ip := range ips {
+		if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+			ip.IsUnspecified() || ip.IsMulticast() {
+			return fmt.Errorf("回调地址 %s 指向本机或内网地址 %s，如需使用请加入 webhook_allowed_hosts", host, ip)
+		}
+	}
+	return nil
+}
+
+// lookupCallbackHost 返回主机的全部IP地址，主机本身是IP时直接返回
+func lookupCallbackHost(host string) ([]net.IP, error) {
+	if ip := net.ParseIP(host); ip != nil {
+		return []net.IP{ip}, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	ips := make([]net.IP, 0, len(addrs))
+	for _, addr := range addrs {
+		ips = append(ips, addr.IP)
+	}
+	return ips, nil
+}
+
+// newWebhookClient 按配置创建回调使用的HTTP客户端，网络错误、429 与 5xx 按指数退避重试
+func newWebhookClient(web *WebProcessor) *utils.HTTPClient {
+	options := utils.DefaultHTTPClientOptions()
+	options.Timeout = time.Duration(web.Config.WebhookTimeout * float64(time.Second))
+	options.MaxRetries = web.Config.WebhookMaxRetries
+	options.BaseDelay = 2 * time.Second
+	options.MaxDelay = 2 * time.Minute
+	return utils.NewHTTPClient(options)
+}
+
+// scheduleCallback 在后台投递任务的回调，队列关闭时中止重试，投递状态保持 pending
+func (q *WebJobQueue) scheduleCallback(job WebJob) {
+	q.callbacks.Add(1)
+	go func() {
+		defer q.callbacks.Done()
+		status := CallbackDelivered
+		if err := q.deliverCallback(q.callbackCtx, job); err != nil {
+			if q.callbackCtx.Err() != nil {
+				return
+			}
+			status = CallbackFailed
+			utils.Warn("Web任务 %s 的回调失败: %v", job.ID, err)
+		} else {
+			utils.Info("Web任务 %s 的回调已送达", job.ID)
+		}
+		q.mu.Lock()
+		if j, exists := q.jobs[job.ID]; exists {
+			j.CallbackStatus = status
+			q.saveLocked()
+		}
+		q.mu.Unlock()
+	}()
+}
+
+// resumeCallbacks 重新投递上次运行时未送达的回调
+func (q *WebJobQueue) resumeCallbacks() {
+	q.mu.Lock()
+	var jobs []WebJob
+	for _, job := range q.sortedJobs() {
+		if job.Finished() && job.CallbackStatus == CallbackPending {
+			jobs = append(jobs, *job)
+		}
+	}
+	q.mu.Unlock()
+	for _, job := range jobs {
+		q.scheduleCallback(job)
+	}
+}
+
+// deliverCallback 向任务的回调地址 POST 签名的结果，返回非 2xx 状态码时视为失败
+func (q *WebJobQueue) deliverCallback(ctx context.Context, job WebJob) error {
+	// 投递前再检查一次：地址可能在提交后改为解析到内网，重启后重新投递的回调也可能来自修改配置之前
+	if err := validateCallbackURL(job.CallbackURL, q.web.Config.WebhookAllowedHosts); err != nil {
+		return err
+	}
+	body, err := json.Marshal(q.webhookPayload(job))
+	if err != nil {
+		return fmt.Errorf("编码回调内容失败: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, job.CallbackURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("创建回调请求失败: %w", err)
+	}
+	event := WebhookJobCompleted
+	if job.Status == WebJobFailed {
+		event = WebhookJobFailed
+	}
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("User-Agent", "asr-media-cli-webhook")
+	req.Header.Set(WebhookEventHeader, event)
+	req.Header.Set(WebhookDeliveryHeader, job.ID)
+	req.Header.Set(WebhookTimestampHeader, strconv.FormatInt(timestamp, 10))
+	if secret := q.web.Config.WebhookKey(); secret != "" {
+		req.Header.Set(WebhookSignatureHeader, SignWebhook(secret, timestamp, body))
+	}
+
+	resp, err := q.webhookClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("回调地址返回状态码 %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// webhookPayload 生成任务的回调内容，文件地址按 web_public_url 补全为绝对地址
+func (q *WebJobQueue) webhookPayload(job WebJob) WebhookPayload {
+	payload := WebhookPayload{
+		Event:      WebhookJobCompleted,
+		JobID:      job.ID,
+		Status:     job.Status,
+		FileName:   job.FileName,
+		SourceURL:  job.SourceURL,
+		Error:      job.Error,
+		CreatedAt:  job.CreatedAt,
+		FinishedAt: job.FinishedAt,
+		Files:      []WebJobFile{},
+	}
+	if job.Status == WebJobFailed {
+		payload.Event = WebhookJobFailed
+		return payload
+	}
+	if result := job.Result; result != nil {
+		payload.Transcript = webhookTranscript(result)
+	}
+	if files, err := q.Files(job.User, job.ID); err == nil {
+		base := strings.TrimSuffix(q.web.Config.WebPublicURL, "/")
+		for i := range files {
+			if files[i].URL != "" {
+				files[i].URL = base + files[i].URL
+			}
+		}
+		payload.Files = files
+	}
+	return payload
+}
+
+// webhookTranscript 统计转写结果并截取开头的文本
+func webhookTranscript(result *WebResult) *WebhookTranscript {
+	transcript := &WebhookTranscript{Segments: len(result.Segments), Status: result.Status}
+	var text strings.Builder
+	for _, segment := range result.Segments {
+		line := strings.TrimSpace(segment.Text)
+		if line == "" {
+			continue
+		}
+		transcript.Characters += utf8.RuneCountInString(line)
+		if segment.EndTime > transcript.Duration {
+			transcript.Duration = segment.EndTime
+		}
+		if text.Len() > 0 {
+			text.WriteByte(' ')
+		}
+		text.WriteString(line)
+	}
+	excerpt := []rune(text.String())
+	if len(excerpt) > webhookExcerptRunes {
+		excerpt = append(excerpt[:webhookExcerptRunes], '…')
+	}
+	transcript.Excerpt = string(excerpt)
+	return transcript
+}
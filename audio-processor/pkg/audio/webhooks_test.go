@@ -0,0 +1,236 @@
+package audio
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/models"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// webhookReceiver 记录收到的回调，前 failures 次返回 503
+type webhookReceiver struct {
+	mu       sync.Mutex
+	failures int
+	calls    int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (rv *webhookReceiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	rv.mu.Lock()
+	defer rv.mu.Unlock()
+	rv.calls++
+	if rv.calls <= rv.failures {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	rv.requests = append(rv.requests, r)
+	rv.bodies = append(rv.bodies, body)
+}
+
+// received 返回成功接收的回调数
+func (rv *webhookReceiver) received() int {
+	rv.mu.Lock()
+	defer rv.mu.Unlock()
+	return len(rv.bodies)
+}
+
+// fastWebhookClient 测试用的短退避回调客户端
+func fastWebhookClient(maxRetries int) *utils.HTTPClient {
+	options := utils.DefaultHTTPClientOptions()
+	options.Timeout = 5 * time.Second
+	options.MaxRetries = maxRetries
+	options.BaseDelay = time.Millisecond
+	options.MaxDelay = 5 * time.Millisecond
+	return utils.NewHTTPClient(options)
+}
+
+// waitCallback 等待任务的回调投递结束
+func waitCallback(t *testing.T, queue *WebJobQueue, id string) WebJob {
+	t.Helper()
+	var job WebJob
+	require.Eventually(t, func() bool {
+		var err error
+		job, err = queue.Get(id)
+		require.NoError(t, err)
+		return job.CallbackStatus == CallbackDelivered || job.CallbackStatus == CallbackFailed
+	}, 2*time.Second, 10*time.Millisecond)
+	return job
+}
+
+func TestWebhookCallback(t *testing.T) {
+	receiver := &webhookReceiver{failures: 2}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	queue, web := newTestWebJobQueue(t, nil)
+	web.Config.WebhookSecret = "secret"
+	web.Config.WebPublicURL = "https://asr.example.com/"
+	web.Config.WebhookAllowedHosts = []string{"127.0.0.1"}
+	queue.webhookClient = fastWebhookClient(3)
+	queue.process = func(user, path string) (*WebResult, error) {
+		txt := filepath.Join(web.OutputDir, "talk.txt")
+		require.NoError(t, os.WriteFile(txt, []byte("你好 世界"), 0644))
+		return &WebResult{
+			Success:     true,
+			Segments:    []models.DataSegment{{Text: "你好", EndTime: 1.5}, {Text: " 世界 ", StartTime: 1.5, EndTime: 3}},
+			OutputFiles: map[string]string{"txt": txt},
+		}, nil
+	}
+	WebFilesHandler(queue, "/api/files")
+	queue.Start(1)
+	defer queue.Close()
+	handler := WebJobsHandler(queue, "/api/jobs")
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, _ := form.CreateFormFile("file", "talk.mp3")
+	part.Write([]byte("audio"))
+	form.WriteField("callback_url", server.URL+"/hook")
+	form.Close()
+	req := httptest.NewRequest(http.MethodPost, "/api/jobs", &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusAccepted, rec.Code, rec.Body.String())
+	var submitted WebJob
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&submitted))
+	assert.Equal(t, server.URL+"/hook", submitted.CallbackURL)
+
+	job := waitCallback(t, queue, submitted.ID)
+	assert.Equal(t, CallbackDelivered, job.CallbackStatus)
+	require.Equal(t, 1, receiver.received(), "503 后重试直到送达")
+
+	r, data := receiver.requests[0], receiver.bodies[0]
+	assert.Equal(t, WebhookJobCompleted, r.Header.Get(WebhookEventHeader))
+	assert.Equal(t, job.ID, r.Header.Get(WebhookDeliveryHeader))
+	timestamp, err := strconv.ParseInt(r.Header.Get(WebhookTimestampHeader), 10, 64)
+	require.NoError(t, err)
+	assert.Equal(t, SignWebhook("secret", timestamp, data), r.Header.Get(WebhookSignatureHeader))
+
+	var payload WebhookPayload
+	require.NoError(t, json.Unmarshal(data, &payload))
+	assert.Equal(t, job.ID, payload.JobID)
+	assert.Equal(t, WebJobCompleted, payload.Status)
+	require.NotNil(t, payload.Transcript)
+	assert.Equal(t, 2, payload.Transcript.Segments)
+	assert.Equal(t, 4, payload.Transcript.Characters)
+	assert.Equal(t, 3.0, payload.Transcript.Duration)
+	assert.Equal(t, "你好 世界", payload.Transcript.Excerpt)
+	require.Len(t, payload.Files, 1)
+	assert.Equal(t, "https://asr.example.com/api/files/"+job.ID+".txt", payload.Files[0].URL)
+}
+
+func TestWebhookCallbackFailure(t *testing.T) {
+	receiver := &webhookReceiver{failures: 100}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	queue, web := newTestWebJobQueue(t, func(path string) (*WebResult, error) {
+		return nil, assert.AnError
+	})
+	queue.webhookClient = fastWebhookClient(1)
+	queue.Start(1)
+	defer queue.Close()
+	handler := WebJobsHandler(queue, "/api/jobs")
+
+	submit := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/jobs", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+	rec := submit(`{"source_url": "https://example.com/v", "callback_url": "ftp://example.com/hook"}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code, "回调地址无效时不创建任务")
+	assert.Empty(t, queue.List(""))
+	rec = submit(`{"source_url": "https://example.com/v", "callback_url": "` + server.URL + `"}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code, "回调地址指向本机且不在 webhook_allowed_hosts 中")
+	assert.Empty(t, queue.List(""))
+	web.Config.WebhookAllowedHosts = []string{"127.0.0.1"}
+
+	queue.download = func(_ context.Context, url, dir string, _ func(float64, string)) (string, error) {
+		return "", assert.AnError
+	}
+	rec = submit(`{"source_url": "https://example.com/v", "callback_url": "` + server.URL + `"}`)
+	require.Equal(t, http.StatusAccepted, rec.Code, rec.Body.String())
+	var submitted WebJob
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&submitted))
+
+	job := waitCallback(t, queue, submitted.ID)
+	assert.Equal(t, WebJobFailed, job.Status)
+	assert.Equal(t, CallbackFailed, job.CallbackStatus, "重试次数用完")
+	receiver.mu.Lock()
+	assert.Equal(t, 2, receiver.calls)
+	receiver.mu.Unlock()
+}
+
+// TestWebhookResume 测试重启后重新投递未送达的回调
+func TestWebhookResume(t *testing.T) {
+	t.Setenv("WEBHOOK_SECRET", "")
+	receiver := &webhookReceiver{}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	queue, web := newTestWebJobQueue(t, nil)
+	web.Config.WebhookAllowedHosts = []string{"127.0.0.1"}
+	queue.mu.Lock()
+	queue.jobs["done"] = &WebJob{
+		ID: "done", FileName: "a.mp3", Status: WebJobFailed, Error: "识别失败",
+		CallbackURL: server.URL, CallbackStatus: CallbackPending,
+		CreatedAt: time.Now(), FinishedAt: time.Now(),
+	}
+	queue.saveLocked()
+	queue.mu.Unlock()
+
+	restarted, err := NewWebJobQueue(web, queue.path)
+	require.NoError(t, err)
+	restarted.webhookClient = fastWebhookClient(0)
+	restarted.Start(1)
+	defer restarted.Close()
+
+	job := waitCallback(t, restarted, "done")
+	assert.Equal(t, CallbackDelivered, job.CallbackStatus)
+	require.Equal(t, 1, receiver.received())
+	var payload WebhookPayload
+	require.NoError(t, json.Unmarshal(receiver.bodies[0], &payload))
+	assert.Equal(t, WebhookJobFailed, payload.Event)
+	assert.Equal(t, "识别失败", payload.Error)
+	assert.Empty(t, receiver.requests[0].Header.Get(WebhookSignatureHeader), "未配置密钥时不签名")
+}
+
+// TestValidateCallbackURL 测试拒绝指向本机、内网与链路本地地址的回调，webhook_allowed_hosts 中的主机除外
+func TestValidateCallbackURL(t *testing.T) {
+	for _, callbackURL := range []string{
+		"ftp://example.com/hook",
+		"http://127.0.0.1:5678/webhook",
+		"http://localhost/hook",
+		"http://[::1]/hook",
+		"http://169.254.169.254/latest/meta-data",
+		"http://10.0.0.5/hook",
+		"http://192.168.1.20:8080/hook",
+		"http://0.0.0.0/hook",
+	} {
+		assert.Error(t, validateCallbackURL(callbackURL, nil), callbackURL)
+	}
+
+	assert.NoError(t, validateCallbackURL("https://93.184.216.34/hook", nil))
+	assert.NoError(t, validateCallbackURL("http://localhost:5678/webhook", []string{"LocalHost"}))
+	assert.Error(t, validateCallbackURL("http://169.254.169.254/", []string{"localhost"}), "只放行列出的主机")
+}
@@ -0,0 +1,736 @@
+package audio
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/internal/ui"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/utils"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/webauth"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/ytdlp"
+	"github.com/google/uuid"
+)
+
+// Web任务的状态
+const (
+	WebJobQueued    = "queued"    // 等待处理
+	WebJobRunning   = "running"   // 正在处理
+	WebJobCompleted = "completed" // 处理成功
+	WebJobFailed    = "failed"    // 处理失败
+)
+
+// StageDownload 链接任务下载音频的阶段，在处理流水线之前
+const StageDownload = "download"
+
+// WebJobsFileName 任务队列在输出目录中的文件名
+const WebJobsFileName = "web_jobs.json"
+
+// 保留的已结束任务数量，超出时删除最早提交的已结束任务
+const maxFinishedWebJobs = 500
+
+// ErrWebJobNotFound 任务不存在
+var ErrWebJobNotFound = errors.New("任务不存在")
+
+// WebJob 一次上传（或一个链接）对应的异步处理任务
+type WebJob struct {
+	ID         string     `json:"id"`
+	User       string     `json:"user,omitempty"`       // 提交任务的用户，未启用认证时为空
+	FileName   string     `json:"file_name"`            // 上传时的文件名，链接任务下载后为下载的文件名
+	SourceURL  string     `json:"source_url,omitempty"` // 链接任务的视频地址，由 yt-dlp 下载
+	FilePath   string     `json:"-"`                    // 保存在上传目录中的路径，链接任务下载前为空
+	Status     string     `json:"status"`
+	Stage      string     `json:"stage,omitempty"`   // 正在处理时的阶段，如 extract、asr
+	Progress   int        `json:"progress"`          // 处理进度（0-100）
+	Message    string     `json:"message,omitempty"` // 当前进度的说明
+	Error      string     `json:"error,omitempty"`
+	Result     *WebResult `json:"result,omitempty"` // 处理结束后的结果
+	CreatedAt  time.Time  `json:"created_at"`
+	StartedAt  time.Time  `json:"started_at,omitempty"`
+	FinishedAt time.Time  `json:"finished_at,omitempty"`
+
+	CallbackURL    string `json:"callback_url,omitempty"`    // 任务结束后 POST 结果的回调地址，见 WebhookPayload
+	CallbackStatus string `json:"callback_status,omitempty"` // 回调的投递状态：pending、delivered、failed
+}
+
+// Finished 任务是否已结束
+func (j *WebJob) Finished() bool {
+	return j.Status == WebJobCompleted || j.Status == WebJobFailed
+}
+
+// webJobRecord 持久化的任务，FilePath 需要写入文件以便重启后继续处理
+type webJobRecord struct {
+	WebJob
+	FilePath string `json:"file_path"`
+}
+
+// WebJobQueue Web上传的任务队列：提交后立即返回任务ID，由固定数量的工作协程依次处理。
+// 任务写入 JSON 文件，服务重启后未完成的任务（包括处理到一半的）重新排队
+type WebJobQueue struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	path    string
+	web     *WebProcessor
+	process func(user, path string) (*WebResult, error) // 处理已保存的文件，测试中可替换
+	jobs    map[string]*WebJob
+	pending []string          // 按提交顺序排列的等待中任务ID
+	byPath  map[string]string // 正在处理的文件路径 -> 任务ID，用于对应处理进度
+	closed  bool
+	wg      sync.WaitGroup
+
+	// 下载链接中的音频到 dir，测试中可替换
+	download func(ctx context.Context, url, dir string, progress func(float64, string)) (string, error)
+
+	filesPrefix string // 输出文件下载接口的路径前缀，见 WebFilesHandler
+	stopOnce    sync.Once
+
+	// 任务结束回调，队列关闭时取消未完成的重试
+	webhookClient   *utils.HTTPClient
+	callbackCtx     context.Context
+	cancelCallbacks context.CancelFunc
+	callbacks       sync.WaitGroup
+
+	progress    *ui.ProgressManager
+	unsubscribe func()
+}
+
+// NewWebJobQueue 创建任务队列并加载 path 中保存的任务
+func NewWebJobQueue(web *WebProcessor, path string) (*WebJobQueue, error) {
+	q := &WebJobQueue{
+		path:   path,
+		web:    web,
+		jobs:   make(map[string]*WebJob),
+		byPath: make(map[string]string),
+	}
+	q.progress = web.Processor.ProgressManager
+	if q.progress == nil {
+		q.progress = ui.NewHeadlessProgressManager()
+		web.Processor.SetProgressManager(q.progress)
+	}
+	q.process = q.processFile
+	q.download = q.downloadURL
+	q.webhookClient = newWebhookClient(web)
+	q.callbackCtx, q.cancelCallbacks = context.WithCancel(context.Background())
+	q.cond = sync.NewCond(&q.mu)
+	if err := q.load(); err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+// load 读取保存的任务，未结束的任务按提交时间重新排队
+func (q *WebJobQueue) load() error {
+	data, err := os.ReadFile(q.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("读取任务队列失败: %w", err)
+	}
+	var records []webJobRecord
+	if err := json.Unmarshal(data, &records); err != nil {
+		return fmt.Errorf("解析任务队列失败: %w", err)
+	}
+
+	for i := range records {
+		job := records[i].WebJob
+		job.FilePath = records[i].FilePath
+		if !job.Finished() {
+			job.Status = WebJobQueued
+			job.StartedAt = time.Time{}
+			job.Stage, job.Progress, job.Message = "", 0, ""
+			q.pending = append(q.pending, job.ID)
+		}
+		q.jobs[job.ID] = &job
+	}
+	if len(q.pending) > 0 {
+		utils.Info("恢复了 %d 个未完成的Web任务", len(q.pending))
+	}
+	return nil
+}
+
+// save 写入临时文件后替换任务队列文件，调用方需持有锁
+func (q *WebJobQueue) save() error {
+	records := make([]webJobRecord, 0, len(q.jobs))
+	for _, job := range q.sortedJobs() {
+		records = append(records, webJobRecord{WebJob: *job, FilePath: job.FilePath})
+	}
+	data, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return fmt.Errorf("编码任务队列失败: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(q.path), 0755); err != nil {
+		return fmt.Errorf("创建输出目录失败: %w", err)
+	}
+	tmp := q.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("写入任务队列失败: %w", err)
+	}
+	if err := os.Rename(tmp, q.path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("写入任务队列失败: %w", err)
+	}
+	return nil
+}
+
+// saveLocked 保存任务队列，失败只记录警告，调用方需持有锁
+func (q *WebJobQueue) saveLocked() {
+	if err := q.save(); err != nil {
+		utils.Warn("%v", err)
+	}
+}
+
+// sortedJobs 按提交时间（相同时按ID）返回全部任务，调用方需持有锁
+func (q *WebJobQueue) sortedJobs() []*WebJob {
+	jobs := make([]*WebJob, 0, len(q.jobs))
+	for _, job := range q.jobs {
+		jobs = append(jobs, job)
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		if !jobs[i].CreatedAt.Equal(jobs[j].CreatedAt) {
+			return jobs[i].CreatedAt.Before(jobs[j].CreatedAt)
+		}
+		return jobs[i].ID < jobs[j].ID
+	})
+	return jobs
+}
+
+// Start 启动 workers 个工作协程处理任务，workers 小于 1 时按 1 处理
+func (q *WebJobQueue) Start(workers int) {
+	if workers < 1 {
+		workers = 1
+	}
+	events, unsubscribe := q.progress.Subscribe("")
+	q.unsubscribe = unsubscribe
+	go q.trackProgress(events)
+	for i := 0; i < workers; i++ {
+		q.wg.Add(1)
+		go q.worker()
+	}
+	q.resumeCallbacks()
+}
+
+// Close 停止领取新任务并等待正在处理的任务完成，等待中的任务保留在文件中，下次启动时继续。
+// 正在重试的回调被中止，下次启动时重新投递
+func (q *WebJobQueue) Close() {
+	q.Shutdown(context.Background())
+}
+
+// Shutdown 与 Close 相同，但最多等待到 ctx 结束：超时返回 ctx.Err()，此时任务仍在处理，
+// 调用方应取消处理器的上下文（见 BatchProcessor.SetContext）中止任务后再调用 Close。
+// 被中止的任务不记为失败，保留在队列文件中，下次启动时重新处理
+func (q *WebJobQueue) Shutdown(ctx context.Context) error {
+	q.mu.Lock()
+	q.closed = true
+	q.mu.Unlock()
+	q.cond.Broadcast()
+
+	done := make(chan struct{})
+	go func() {
+		q.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	q.stopOnce.Do(func() {
+		q.cancelCallbacks()
+		q.callbacks.Wait()
+		if q.unsubscribe != nil {
+			q.unsubscribe()
+		}
+		q.mu.Lock()
+		q.saveLocked()
+		q.mu.Unlock()
+	})
+	return nil
+}
+
+// trackProgress 将处理进度记录到对应的任务，供查询接口返回
+func (q *WebJobQueue) trackProgress(events <-chan ui.ProgressEvent) {
+	for event := range events {
+		q.mu.Lock()
+		if job := q.eventJob(event.ID); job != nil && job.Status == WebJobRunning {
+			job.Stage, job.Progress, job.Message = event.Stage, event.Percent, event.Message
+		}
+		q.mu.Unlock()
+	}
+}
+
+// eventJob 返回进度事件对应的任务：下载阶段的事件以任务ID标识，处理阶段以文件路径标识。调用方需持有锁
+func (q *WebJobQueue) eventJob(id string) *WebJob {
+	if job, exists := q.jobs[id]; exists {
+		return job
+	}
+	return q.jobs[q.byPath[id]]
+}
+
+// Submit 将上传的文件保存到用户的上传目录并提交任务，立即返回任务。未启用认证时 user 为空
+func (q *WebJobQueue) Submit(user string, file io.Reader, filename string) (WebJob, error) {
+	job, err := q.uploadJob(user, file, filename)
+	if err != nil {
+		return WebJob{}, err
+	}
+	return q.enqueue(job, ""), nil
+}
+
+// SubmitFile 为已保存在用户上传目录中的文件提交任务，filename 为上传时的文件名
+func (q *WebJobQueue) SubmitFile(user, path, filename string) WebJob {
+	return q.enqueue(fileJob(user, path, filename), "")
+}
+
+// SubmitURL 提交链接任务，处理时先用 yt-dlp 下载音频
+func (q *WebJobQueue) SubmitURL(user, sourceURL string) (WebJob, error) {
+	job, err := urlJob(user, sourceURL)
+	if err != nil {
+		return WebJob{}, err
+	}
+	return q.enqueue(job, ""), nil
+}
+
+// uploadJob 将上传的文件保存到用户的上传目录，返回尚未提交的任务
+func (q *WebJobQueue) uploadJob(user string, file io.Reader, filename string) (*WebJob, error) {
+	path, err := q.web.ForUser(user).SaveUpload(file, filename)
+	if err != nil {
+		return nil, err
+	}
+	return fileJob(user, path, filename), nil
+}
+
+// fileJob 返回处理已保存文件的任务
+func fileJob(user, path, filename string) *WebJob {
+	return &WebJob{
+		ID:        uuid.New().String(),
+		User:      user,
+		FileName:  filepath.Base(filename),
+		FilePath:  path,
+		Status:    WebJobQueued,
+		CreatedAt: time.Now(),
+	}
+}
+
+// urlJob 返回链接任务
+func urlJob(user, sourceURL string) (*WebJob, error) {
+	sourceURL = strings.TrimSpace(sourceURL)
+	if err := ytdlp.ValidateURL(sourceURL); err != nil {
+		return nil, err
+	}
+	return &WebJob{
+		ID:        uuid.New().String(),
+		User:      user,
+		FileName:  sourceURL,
+		SourceURL: sourceURL,
+		Status:    WebJobQueued,
+		CreatedAt: time.Now(),
+	}, nil
+}
+
+// enqueue 保存并排队任务，callbackURL 非空时任务结束后回调（调用方需先检查地址）
+func (q *WebJobQueue) enqueue(job *WebJob, callbackURL string) WebJob {
+	job.CallbackURL = callbackURL
+	q.mu.Lock()
+	q.jobs[job.ID] = job
+	q.pending = append(q.pending, job.ID)
+	q.saveLocked()
+	snapshot := *job
+	q.mu.Unlock()
+	q.cond.Signal()
+
+	utils.Info("已提交Web任务 %s: %s", job.ID, job.FileName)
+	return snapshot
+}
+
+// Get 返回任务的当前状态
+func (q *WebJobQueue) Get(id string) (WebJob, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	job, exists := q.jobs[id]
+	if !exists {
+		return WebJob{}, ErrWebJobNotFound
+	}
+	return *job, nil
+}
+
+// getOwned 返回属于 user 的任务，其他用户的任务视为不存在
+func (q *WebJobQueue) getOwned(user, id string) (WebJob, error) {
+	job, err := q.Get(id)
+	if err == nil && job.User != user {
+		return WebJob{}, ErrWebJobNotFound
+	}
+	return job, err
+}
+
+// List 按提交时间返回 user 的全部任务，不包含处理结果
+func (q *WebJobQueue) List(user string) []WebJob {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	jobs := make([]WebJob, 0, len(q.jobs))
+	for _, job := range q.sortedJobs() {
+		if job.User != user {
+			continue
+		}
+		summary := *job
+		summary.Result = nil
+		jobs = append(jobs, summary)
+	}
+	return jobs
+}
+
+// next 取出下一个等待中的任务并标记为处理中，队列关闭时返回 nil
+func (q *WebJobQueue) next() *WebJob {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for len(q.pending) == 0 && !q.closed {
+		q.cond.Wait()
+	}
+	if q.closed {
+		return nil
+	}
+	id := q.pending[0]
+	q.pending = q.pending[1:]
+	job := q.jobs[id]
+	job.Status = WebJobRunning
+	job.StartedAt = time.Now()
+	if job.FilePath != "" {
+		q.byPath[job.FilePath] = job.ID
+	}
+	q.saveLocked()
+	return job
+}
+
+// worker 依次处理等待中的任务，直到队列关闭
+func (q *WebJobQueue) worker() {
+	defer q.wg.Done()
+	for {
+		job := q.next()
+		if job == nil {
+			return
+		}
+		utils.Info("开始处理Web任务 %s: %s", job.ID, job.FileName)
+		if job.SourceURL != "" {
+			if err := q.fetch(job); err != nil {
+				q.finishOrRequeue(job, nil, err)
+				continue
+			}
+		}
+		result, err := q.process(job.User, job.FilePath)
+		if q.finishOrRequeue(job, result, err) {
+			continue
+		}
+		if job.SourceURL != "" {
+			// 下载目录中的文件在处理过程中已清理或移走
+			os.RemoveAll(q.downloadDir(job))
+		}
+	}
+}
+
+// processFile 由任务所属用户的处理器处理文件，结果写入该用户的输出目录
+func (q *WebJobQueue) processFile(user, path string) (*WebResult, error) {
+	return q.web.ForUser(user).ProcessFile(path)
+}
+
+// downloadDir 链接任务的下载目录，位于任务所属用户的上传目录中
+func (q *WebJobQueue) downloadDir(job *WebJob) string {
+	return filepath.Join(q.web.ForUser(job.User).UploadDir, job.ID)
+}
+
+// fetch 下载链接任务的音频，重启前已下载完成的不再下载
+func (q *WebJobQueue) fetch(job *WebJob) error {
+	q.mu.Lock()
+	path := job.FilePath
+	q.mu.Unlock()
+	if path != "" {
+		if _, err := os.Stat(path); err == nil {
+			return nil
+		}
+	}
+
+	dir := q.downloadDir(job)
+	os.RemoveAll(dir) // 上次未完成的下载
+	progress := func(percent float64, message string) {
+		q.progress.Publish(ui.ProgressEvent{ID: job.ID, Stage: StageDownload, Percent: int(percent), Message: message})
+	}
+	progress(0, "下载音频")
+	path, err := q.download(q.web.Processor.baseContext(), job.SourceURL, dir, progress)
+	if err != nil {
+		os.RemoveAll(dir)
+		return err
+	}
+
+	q.mu.Lock()
+	job.FilePath = path
+	job.FileName = filepath.Base(path)
+	q.byPath[path] = job.ID
+	q.saveLocked()
+	q.mu.Unlock()
+	utils.Info("Web任务 %s 下载完成: %s", job.ID, job.FileName)
+	return nil
+}
+
+// downloadURL 用配置中的 yt-dlp 下载音频
+func (q *WebJobQueue) downloadURL(ctx context.Context, url, dir string, progress func(float64, string)) (string, error) {
+	config := q.web.Config
+	downloader := ytdlp.New(config.YtDlpPath, config.YtDlpFormat, config.YtDlpArgs)
+	downloader.Progress = progress
+	return downloader.Download(ctx, url, dir)
+}
+
+// finishOrRequeue 记录任务结果；任务因服务停止（处理器的上下文被取消）而失败时改为重新排队，
+// 下次启动时继续处理，返回 true
+func (q *WebJobQueue) finishOrRequeue(job *WebJob, result *WebResult, err error) bool {
+	if err == nil || q.web.Processor.baseContext().Err() == nil {
+		q.finish(job, result, err)
+		return false
+	}
+	q.mu.Lock()
+	delete(q.byPath, job.FilePath)
+	job.Status = WebJobQueued
+	job.StartedAt = time.Time{}
+	job.Stage, job.Progress, job.Message = "", 0, ""
+	q.saveLocked()
+	q.mu.Unlock()
+	utils.Warn("Web任务 %s 因服务停止而中断，下次启动时重新处理", job.ID)
+	return true
+}
+
+// finish 记录任务结果，并删除超出保留数量的已结束任务
+func (q *WebJobQueue) finish(job *WebJob, result *WebResult, err error) {
+	q.mu.Lock()
+	delete(q.byPath, job.FilePath)
+	job.Result = result
+	job.FinishedAt = time.Now()
+	job.Status = WebJobCompleted
+	job.Stage, job.Progress, job.Message = "", 100, ""
+	if err != nil {
+		job.Status = WebJobFailed
+		job.Error = err.Error()
+		utils.Error("Web任务 %s 处理失败: %v", job.ID, err)
+	} else {
+		utils.Info("Web任务 %s 处理完成，耗时 %s", job.ID, job.FinishedAt.Sub(job.StartedAt).Round(time.Second))
+	}
+
+	var finished []*WebJob
+	for _, j := range q.sortedJobs() {
+		if j.Finished() {
+			finished = append(finished, j)
+		}
+	}
+	for i := 0; i < len(finished)-maxFinishedWebJobs; i++ {
+		delete(q.jobs, finished[i].ID)
+	}
+	if job.CallbackURL != "" {
+		job.CallbackStatus = CallbackPending
+	}
+	q.saveLocked()
+	snapshot := *job
+	q.mu.Unlock()
+
+	// 任务状态更新后再通知，订阅者收到结束通知时可以读取结果
+	q.progress.Publish(ui.ProgressEvent{ID: job.ID, Percent: 100, Message: job.Status, Done: true})
+	if snapshot.CallbackURL != "" {
+		q.scheduleCallback(snapshot)
+	}
+}
+
+// WebJobsHandler 返回任务接口：POST prefix 以表单字段 file 上传文件并提交任务，返回 202 与任务，
+// 也可以用 JSON {"source_url": "..."} 或表单字段 source_url 提交视频链接，
+// 两种方式都可以带 callback_url，任务结束后 POST 结果（见 WebhookPayload）；
+// GET prefix?status=&page=&per_page= 按提交时间倒序分页列出任务；GET prefix/<ID> 查询任务状态，结束后包含处理结果；
+// GET prefix/<ID>/events 以 Server-Sent Events 推送处理进度；GET prefix/<ID>/files 列出生成的输出文件。
+// 启用认证时（见 webauth.Middleware）任务属于提交的用户，其他用户无法查询
+func WebJobsHandler(q *WebJobQueue, prefix string) http.Handler {
+	return http.StripPrefix(prefix, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, sub, _ := strings.Cut(strings.Trim(r.URL.Path, "/"), "/")
+		user := webauth.User(r.Context())
+		switch {
+		case id == "" && r.Method == http.MethodPost:
+			job, err := q.submitRequest(w, r, user)
+			if err != nil {
+				writeJobError(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			w.Header().Set("Location", strings.TrimSuffix(prefix, "/")+"/"+job.ID)
+			writeJobJSON(w, http.StatusAccepted, job)
+		case id == "" && r.Method == http.MethodGet:
+			q.serveJobHistory(w, r, user)
+		case sub == "" && r.Method == http.MethodGet:
+			job, err := q.getOwned(user, id)
+			if err != nil {
+				writeJobError(w, err.Error(), http.StatusNotFound)
+				return
+			}
+			writeJobJSON(w, http.StatusOK, job)
+		case sub == "events" && r.Method == http.MethodGet:
+			if _, err := q.getOwned(user, id); err != nil {
+				writeJobError(w, err.Error(), http.StatusNotFound)
+				return
+			}
+			q.streamEvents(w, r, id)
+		case sub == "files" && r.Method == http.MethodGet:
+			q.serveJobFiles(w, user, id)
+		case sub != "" && sub != "events" && sub != "files":
+			http.NotFound(w, r)
+		default:
+			writeJobError(w, "不支持的请求方法", http.StatusMethodNotAllowed)
+		}
+	}))
+}
+
+// submitRequest 按请求的内容类型提交上传文件或视频链接
+func (q *WebJobQueue) submitRequest(w http.ResponseWriter, r *http.Request, user string) (WebJob, error) {
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+		var request struct {
+			SourceURL   string `json:"source_url"`
+			CallbackURL string `json:"callback_url"`
+		}
+		if err := json.NewDecoder(io.LimitReader(r.Body, 1<<20)).Decode(&request); err != nil {
+			return WebJob{}, fmt.Errorf("无法解析请求: %w", err)
+		}
+		return q.submitWithCallback(request.CallbackURL, func() (*WebJob, error) {
+			return urlJob(user, request.SourceURL)
+		})
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, q.web.MaxFileSize)
+	if err := r.ParseMultipartForm(32 << 20); err != nil && !errors.Is(err, http.ErrNotMultipart) {
+		return WebJob{}, fmt.Errorf("无法解析表单: %w", err)
+	}
+	if r.MultipartForm != nil {
+		defer r.MultipartForm.RemoveAll()
+	}
+	callbackURL := r.FormValue("callback_url")
+	if sourceURL := r.FormValue("source_url"); sourceURL != "" {
+		return q.submitWithCallback(callbackURL, func() (*WebJob, error) {
+			return urlJob(user, sourceURL)
+		})
+	}
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		return WebJob{}, errors.New("获取上传文件失败")
+	}
+	defer file.Close()
+	return q.submitWithCallback(callbackURL, func() (*WebJob, error) {
+		return q.uploadJob(user, file, header.Filename)
+	})
+}
+
+// submitWithCallback 检查回调地址后创建并提交任务，地址无效时不创建任务
+func (q *WebJobQueue) submitWithCallback(callbackURL string, create func() (*WebJob, error)) (WebJob, error) {
+	callbackURL = strings.TrimSpace(callbackURL)
+	if callbackURL != "" {
+		if err := validateCallbackURL(callbackURL, q.web.Config.WebhookAllowedHosts); err != nil {
+			return WebJob{}, err
+		}
+	}
+	job, err := create()
+	if err != nil {
+		return WebJob{}, err
+	}
+	return q.enqueue(job, callbackURL), nil
+}
+
+// 推送进度时发送保活注释的间隔，同时检查任务是否已结束（结束通知可能因订阅缓冲已满被丢弃）
+const sseKeepAlive = 15 * time.Second
+
+// streamEvents 以 Server-Sent Events 推送任务进度：每次进度发送一个 progress 事件（不含处理结果的任务），
+// 任务结束时发送包含结果的 done 事件后关闭连接。断线重连时先发送一次当前状态
+func (q *WebJobQueue) streamEvents(w http.ResponseWriter, r *http.Request, id string) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeJobError(w, "不支持流式响应", http.StatusInternalServerError)
+		return
+	}
+	job, err := q.Get(id)
+	if err != nil {
+		writeJobError(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	// 先订阅再读取状态，避免错过两者之间的结束通知。
+	// 链接任务下载前后的进度标识不同，订阅全部事件后按任务筛选
+	events, unsubscribe := q.progress.Subscribe("")
+	defer unsubscribe()
+	job, _ = q.Get(id)
+
+	w.Header().Set("Content-Type", "text/event-stream; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no") // 关闭 nginx 的响应缓冲
+	w.WriteHeader(http.StatusOK)
+	send := func(name string, job WebJob) {
+		if name != "done" {
+			job.Result = nil
+		}
+		data, _ := json.Marshal(job)
+		fmt.Fprintf(w, "event: %s\ndata: %s\n\n", name, data)
+		flusher.Flush()
+	}
+	if job.Finished() {
+		send("done", job)
+		return
+	}
+	send("progress", job)
+
+	ticker := time.NewTicker(sseKeepAlive)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			q.mu.Lock()
+			owner := q.eventJob(event.ID)
+			q.mu.Unlock()
+			if owner == nil || owner.ID != id {
+				continue
+			}
+			job, err := q.Get(id)
+			if err != nil {
+				return
+			}
+			if event.Done || job.Finished() {
+				send("done", job)
+				return
+			}
+			// 任务记录的进度由另一个订阅者更新，可能还没有收到这一次
+			job.Stage, job.Progress, job.Message = event.Stage, event.Percent, event.Message
+			send("progress", job)
+		case <-ticker.C:
+			job, err := q.Get(id)
+			if err != nil {
+				return
+			}
+			if job.Finished() {
+				send("done", job)
+				return
+			}
+			fmt.Fprint(w, ": keepalive\n\n")
+			flusher.Flush()
+		}
+	}
+}
+
+// writeJobJSON 以JSON写入响应
+func writeJobJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeJobError 以 {"error": "..."} 返回错误，与 audio_web 其他接口一致
+func writeJobError(w http.ResponseWriter, message string, status int) {
+	writeJobJSON(w, status, map[string]string{"error": message})
+}
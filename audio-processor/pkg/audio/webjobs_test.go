@@ -0,0 +1,499 @@
+package audio
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/models"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/webauth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestWebJobQueue 创建使用临时目录的任务队列，process 替换实际的处理流程
+func newTestWebJobQueue(t *testing.T, process func(string) (*WebResult, error)) (*WebJobQueue, *WebProcessor) {
+	t.Helper()
+	root := t.TempDir()
+	config := models.NewDefaultConfig()
+	config.OutputFolder = filepath.Join(root, "output")
+	web := NewWebProcessor(filepath.Join(root, "uploads"), filepath.Join(root, "temp"), config.OutputFolder, config)
+
+	queue, err := NewWebJobQueue(web, filepath.Join(config.OutputFolder, WebJobsFileName))
+	require.NoError(t, err)
+	if process != nil {
+		queue.process = func(user, path string) (*WebResult, error) { return process(path) }
+	}
+	return queue, web
+}
+
+// waitJob 等待任务结束
+func waitJob(t *testing.T, queue *WebJobQueue, id string) WebJob {
+	t.Helper()
+	var job WebJob
+	require.Eventually(t, func() bool {
+		var err error
+		job, err = queue.Get(id)
+		require.NoError(t, err)
+		return job.Finished()
+	}, 2*time.Second, 10*time.Millisecond)
+	return job
+}
+
+func TestWebJobQueue(t *testing.T) {
+	queue, _ := newTestWebJobQueue(t, func(path string) (*WebResult, error) {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if string(data) == "bad" {
+			return &WebResult{Success: false, ErrorMessage: "语音识别失败"}, errors.New("语音识别失败")
+		}
+		return &WebResult{Success: true, OutputFiles: map[string]string{"txt": path + ".txt"}}, nil
+	})
+	queue.Start(2)
+	defer queue.Close()
+
+	good, err := queue.Submit("", strings.NewReader("audio"), "会议录音.mp3")
+	require.NoError(t, err)
+	assert.Equal(t, "会议录音.mp3", good.FileName)
+	bad, err := queue.Submit("", strings.NewReader("bad"), "坏文件.wav")
+	require.NoError(t, err)
+
+	job := waitJob(t, queue, good.ID)
+	assert.Equal(t, WebJobCompleted, job.Status)
+	require.NotNil(t, job.Result)
+	assert.True(t, job.Result.Success)
+	assert.False(t, job.StartedAt.IsZero())
+
+	job = waitJob(t, queue, bad.ID)
+	assert.Equal(t, WebJobFailed, job.Status)
+	assert.Equal(t, "语音识别失败", job.Error)
+
+	_, err = queue.Submit("", strings.NewReader("text"), "notes.pdf")
+	assert.Error(t, err, "不支持的格式不应提交")
+	_, err = queue.Get("missing")
+	assert.ErrorIs(t, err, ErrWebJobNotFound)
+
+	jobs := queue.List("")
+	require.Len(t, jobs, 2)
+	assert.Equal(t, good.ID, jobs[0].ID)
+	assert.Nil(t, jobs[0].Result, "列表不包含处理结果")
+}
+
+func TestWebJobQueueResume(t *testing.T) {
+	processed := make(chan string, 4)
+	queue, web := newTestWebJobQueue(t, func(path string) (*WebResult, error) {
+		processed <- path
+		return &WebResult{Success: true}, nil
+	})
+
+	// 未启动工作协程时提交，模拟服务在处理前退出
+	job, err := queue.Submit("", strings.NewReader("audio"), "a.mp3")
+	require.NoError(t, err)
+
+	reloaded, err := NewWebJobQueue(web, queue.path)
+	require.NoError(t, err)
+	reloaded.process = queue.process
+	restored, err := reloaded.Get(job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, WebJobQueued, restored.Status)
+
+	reloaded.Start(1)
+	defer reloaded.Close()
+	assert.Equal(t, WebJobCompleted, waitJob(t, reloaded, job.ID).Status)
+	assert.Equal(t, restored.FilePath, <-processed, "重启后处理原来保存的文件")
+}
+
+func TestWebJobsHandler(t *testing.T) {
+	queue, _ := newTestWebJobQueue(t, func(path string) (*WebResult, error) {
+		return &WebResult{Success: true, Status: "ok"}, nil
+	})
+	queue.Start(1)
+	defer queue.Close()
+	handler := WebJobsHandler(queue, "/api/jobs")
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("file", "talk.m4a")
+	require.NoError(t, err)
+	part.Write([]byte("audio"))
+	require.NoError(t, form.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/api/jobs", &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusAccepted, rec.Code)
+
+	var submitted WebJob
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&submitted))
+	assert.NotEmpty(t, submitted.ID)
+	assert.Equal(t, "/api/jobs/"+submitted.ID, rec.Header().Get("Location"))
+	waitJob(t, queue, submitted.ID)
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/jobs/"+submitted.ID, nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	var job WebJob
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&job))
+	assert.Equal(t, WebJobCompleted, job.Status)
+	require.NotNil(t, job.Result)
+	assert.Equal(t, "ok", job.Result.Status)
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/jobs/unknown", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+// readEvent 读取下一个 Server-Sent Event，跳过保活注释
+func readEvent(t *testing.T, reader *bufio.Reader) (string, WebJob) {
+	t.Helper()
+	var name string
+	var job WebJob
+	for {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		line = strings.TrimRight(line, "\n")
+		switch {
+		case strings.HasPrefix(line, "event: "):
+			name = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &job))
+		case line == "" && name != "":
+			return name, job
+		}
+	}
+}
+
+func TestWebJobEvents(t *testing.T) {
+	step := make(chan struct{})
+	var processor *BatchProcessor
+	queue, web := newTestWebJobQueue(t, func(path string) (*WebResult, error) {
+		<-step
+		processor.reportFileProgress(path, StageASR, 60, "语音识别: 3/5")
+		<-step
+		return &WebResult{Success: true, Status: "ok"}, nil
+	})
+	processor = web.Processor
+	queue.Start(1)
+	defer queue.Close()
+	server := httptest.NewServer(WebJobsHandler(queue, "/api/jobs"))
+	defer server.Close()
+
+	job, err := queue.Submit("", strings.NewReader("audio"), "a.mp3")
+	require.NoError(t, err)
+	resp, err := http.Get(server.URL + "/api/jobs/" + job.ID + "/events")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, "text/event-stream; charset=utf-8", resp.Header.Get("Content-Type"))
+	reader := bufio.NewReader(resp.Body)
+
+	name, _ := readEvent(t, reader)
+	assert.Equal(t, "progress", name, "连接后先发送当前状态")
+
+	step <- struct{}{}
+	name, current := readEvent(t, reader)
+	assert.Equal(t, "progress", name)
+	assert.Equal(t, StageASR, current.Stage)
+	assert.Equal(t, 60, current.Progress)
+	assert.Nil(t, current.Result)
+	require.Eventually(t, func() bool {
+		job, _ := queue.Get(job.ID)
+		return job.Progress == 60
+	}, time.Second, 10*time.Millisecond, "查询接口也返回处理进度")
+
+	step <- struct{}{}
+	name, current = readEvent(t, reader)
+	assert.Equal(t, "done", name)
+	assert.Equal(t, WebJobCompleted, current.Status)
+	require.NotNil(t, current.Result)
+	assert.Equal(t, "ok", current.Result.Status)
+
+	// 已结束的任务直接返回 done
+	resp2, err := http.Get(server.URL + "/api/jobs/" + job.ID + "/events")
+	require.NoError(t, err)
+	defer resp2.Body.Close()
+	name, _ = readEvent(t, bufio.NewReader(resp2.Body))
+	assert.Equal(t, "done", name)
+}
+
+func TestWebJobSourceURL(t *testing.T) {
+	processed := make(chan string, 1)
+	queue, web := newTestWebJobQueue(t, func(path string) (*WebResult, error) {
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		processed <- string(data)
+		return &WebResult{Success: true}, nil
+	})
+	queue.download = func(ctx context.Context, url, dir string, progress func(float64, string)) (string, error) {
+		if strings.Contains(url, "private") {
+			return "", errors.New("yt-dlp 下载失败: Private video")
+		}
+		progress(50, "50.0% of 1.00MiB")
+		path := filepath.Join(dir, "讲座 [BV1xx].m4a")
+		require.NoError(t, os.MkdirAll(dir, 0755))
+		return path, os.WriteFile(path, []byte(url), 0644)
+	}
+	queue.Start(1)
+	defer queue.Close()
+	handler := WebJobsHandler(queue, "/api/jobs")
+
+	req := httptest.NewRequest(http.MethodPost, "/api/jobs", strings.NewReader(`{"source_url": "https://www.bilibili.com/video/BV1xx"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusAccepted, rec.Code)
+	var submitted WebJob
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&submitted))
+	assert.Equal(t, "https://www.bilibili.com/video/BV1xx", submitted.SourceURL)
+
+	job := waitJob(t, queue, submitted.ID)
+	assert.Equal(t, WebJobCompleted, job.Status)
+	assert.Equal(t, "讲座 [BV1xx].m4a", job.FileName)
+	assert.Equal(t, "https://www.bilibili.com/video/BV1xx", <-processed)
+	assert.NoDirExists(t, filepath.Join(web.UploadDir, job.ID), "处理后删除下载目录")
+
+	// 表单字段提交，下载失败时任务失败
+	req = httptest.NewRequest(http.MethodPost, "/api/jobs", strings.NewReader("source_url=https://youtu.be/private"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusAccepted, rec.Code)
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&submitted))
+	job = waitJob(t, queue, submitted.ID)
+	assert.Equal(t, WebJobFailed, job.Status)
+	assert.Contains(t, job.Error, "Private video")
+
+	_, err := queue.SubmitURL("", "ftp://example.com/a.mp4")
+	assert.Error(t, err)
+}
+
+func TestWebJobsPerUser(t *testing.T) {
+	queue, web := newTestWebJobQueue(t, nil)
+	processed := make(chan string, 2)
+	queue.process = func(user, path string) (*WebResult, error) {
+		processed <- user + ":" + path
+		return &WebResult{Success: true}, nil
+	}
+	queue.Start(1)
+	defer queue.Close()
+	handler := WebJobsHandler(queue, "/api/jobs")
+
+	serve := func(user, method, target string, body *bytes.Buffer, contentType string) *httptest.ResponseRecorder {
+		var req *http.Request
+		if body != nil {
+			req = httptest.NewRequest(method, target, body)
+			req.Header.Set("Content-Type", contentType)
+		} else {
+			req = httptest.NewRequest(method, target, nil)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req.WithContext(webauth.WithUser(req.Context(), user)))
+		return rec
+	}
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("file", "alice.mp3")
+	require.NoError(t, err)
+	part.Write([]byte("audio"))
+	require.NoError(t, form.Close())
+	rec := serve("alice", http.MethodPost, "/api/jobs", &body, form.FormDataContentType())
+	require.Equal(t, http.StatusAccepted, rec.Code)
+	var job WebJob
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&job))
+	assert.Equal(t, "alice", job.User)
+
+	// 上传保存在用户自己的目录中，由该用户的处理器处理
+	job = waitJob(t, queue, job.ID)
+	aliceDir := webauth.UserDir(web.UploadDir, "alice")
+	assert.Equal(t, "alice:"+filepath.Join(aliceDir, filepath.Base(job.FilePath)), <-processed)
+	assert.Equal(t, webauth.UserDir(web.OutputDir, "alice"), web.ForUser("alice").Config.OutputFolder)
+	assert.Same(t, web.ForUser("alice"), web.ForUser("alice"))
+
+	// 其他用户看不到该任务
+	assert.Equal(t, http.StatusNotFound, serve("bob", http.MethodGet, "/api/jobs/"+job.ID, nil, "").Code)
+	assert.Equal(t, http.StatusNotFound, serve("bob", http.MethodGet, "/api/jobs/"+job.ID+"/events", nil, "").Code)
+	rec = serve("bob", http.MethodGet, "/api/jobs", nil, "")
+	var page WebJobPage
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&page))
+	assert.Empty(t, page.Jobs)
+
+	rec = serve("alice", http.MethodGet, "/api/jobs", nil, "")
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&page))
+	require.Len(t, page.Jobs, 1)
+	assert.Equal(t, job.ID, page.Jobs[0].ID)
+	assert.Equal(t, http.StatusOK, serve("alice", http.MethodGet, "/api/jobs/"+job.ID, nil, "").Code)
+}
+
+func TestWebJobHistory(t *testing.T) {
+	queue, _ := newTestWebJobQueue(t, func(path string) (*WebResult, error) {
+		data, _ := os.ReadFile(path)
+		if string(data) == "bad" {
+			return nil, errors.New("识别失败")
+		}
+		return &WebResult{Success: true}, nil
+	})
+	queue.Start(1)
+	defer queue.Close()
+	handler := WebJobsHandler(queue, "/api/jobs")
+
+	var ids []string
+	for i, content := range []string{"a", "bad", "b", "c", "bad"} {
+		job, err := queue.Submit("", strings.NewReader(content), fmt.Sprintf("%d.mp3", i))
+		require.NoError(t, err)
+		ids = append(ids, job.ID)
+		waitJob(t, queue, job.ID)
+	}
+
+	get := func(target string) (int, WebJobPage) {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		var page WebJobPage
+		if rec.Code == http.StatusOK {
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&page))
+		}
+		return rec.Code, page
+	}
+
+	// 最新提交的在前
+	code, page := get("/api/jobs?per_page=2")
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, 5, page.Total)
+	require.Len(t, page.Jobs, 2)
+	assert.Equal(t, []string{ids[4], ids[3]}, []string{page.Jobs[0].ID, page.Jobs[1].ID})
+
+	_, page = get("/api/jobs?per_page=2&page=3")
+	require.Len(t, page.Jobs, 1)
+	assert.Equal(t, ids[0], page.Jobs[0].ID)
+	_, page = get("/api/jobs?per_page=2&page=4")
+	assert.Empty(t, page.Jobs)
+
+	_, page = get("/api/jobs?status=failed")
+	assert.Equal(t, 2, page.Total)
+	assert.Equal(t, WebJobFailed, page.Jobs[0].Status)
+	assert.Equal(t, defaultJobsPerPage, page.PerPage)
+
+	code, _ = get("/api/jobs?status=unknown")
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, _ = get("/api/jobs?page=0")
+	assert.Equal(t, http.StatusBadRequest, code)
+}
+
+func TestWebJobFiles(t *testing.T) {
+	var outside string
+	queue, web := newTestWebJobQueue(t, nil)
+	queue.process = func(user, path string) (*WebResult, error) {
+		dir := filepath.Join(web.ForUser(user).OutputDir, "talk")
+		require.NoError(t, os.MkdirAll(dir, 0755))
+		txt := filepath.Join(dir, "talk.txt")
+		srt := filepath.Join(dir, "talk.srt")
+		require.NoError(t, os.WriteFile(txt, []byte("你好"), 0644))
+		require.NoError(t, os.WriteFile(srt, []byte("1\n00:00:00,000 --> 00:00:01,000\n你好\n"), 0644))
+		return &WebResult{Success: true, OutputFiles: map[string]string{
+			"txt": txt, "srt": srt,
+			"json":   filepath.Join(dir, "missing.json"), // 已删除的文件不列出
+			"passwd": outside,                            // 用户目录以外的文件不列出
+		}}, nil
+	}
+	outside = filepath.Join(t.TempDir(), "passwd")
+	require.NoError(t, os.WriteFile(outside, []byte("secret"), 0644))
+	queue.Start(1)
+	defer queue.Close()
+	jobs := webauth.PerUser(func(string) http.Handler { return WebJobsHandler(queue, "/api/jobs") })
+	files := WebFilesHandler(queue, "/api/files")
+
+	serve := func(handler http.Handler, user, target string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req.WithContext(webauth.WithUser(req.Context(), user)))
+		return rec
+	}
+
+	job, err := queue.Submit("alice", strings.NewReader("audio"), "talk.mp3")
+	require.NoError(t, err)
+	waitJob(t, queue, job.ID)
+
+	rec := serve(jobs, "alice", "/api/jobs/"+job.ID+"/files")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var listed []WebJobFile
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&listed))
+	require.Len(t, listed, 2)
+	assert.Equal(t, "srt", listed[0].Type)
+	assert.Equal(t, "/api/files/"+job.ID+".txt", listed[1].URL)
+	assert.Equal(t, int64(len("你好")), listed[1].Size)
+
+	rec = serve(files, "alice", listed[1].URL)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "你好", rec.Body.String())
+	assert.Contains(t, rec.Header().Get("Content-Disposition"), "attachment; filename*=UTF-8''talk.txt")
+
+	assert.Equal(t, http.StatusNotFound, serve(files, "bob", listed[1].URL).Code, "其他用户不能下载")
+	assert.Equal(t, http.StatusNotFound, serve(jobs, "bob", "/api/jobs/"+job.ID+"/files").Code)
+	assert.Equal(t, http.StatusNotFound, serve(files, "alice", "/api/files/"+job.ID+".passwd").Code)
+	assert.Equal(t, http.StatusNotFound, serve(files, "alice", "/api/files/"+job.ID+".json").Code)
+	assert.Equal(t, http.StatusNotFound, serve(files, "alice", "/api/files/../../etc/passwd").Code)
+}
+
+// TestWebJobShutdown 测试停止时等待任务完成，超时后中止的任务重新排队，下次启动时继续
+func TestWebJobShutdown(t *testing.T) {
+	ctx, cancelProcessing := context.WithCancel(context.Background())
+	defer cancelProcessing()
+	release := make(chan struct{})
+	queue, web := newTestWebJobQueue(t, func(path string) (*WebResult, error) {
+		select {
+		case <-release:
+			return &WebResult{Success: true}, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	})
+	web.Processor.SetContext(ctx)
+	queue.Start(1)
+
+	finished, err := queue.Submit("", strings.NewReader("a"), "a.mp3")
+	require.NoError(t, err)
+	release <- struct{}{}
+	waitJob(t, queue, finished.ID)
+
+	job, err := queue.Submit("", strings.NewReader("b"), "b.mp3")
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		current, _ := queue.Get(job.ID)
+		return current.Status == WebJobRunning
+	}, 2*time.Second, 10*time.Millisecond)
+
+	timeout, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, queue.Shutdown(timeout), context.DeadlineExceeded, "任务未完成前超时")
+	cancelProcessing()
+	queue.Close()
+
+	interrupted, err := queue.Get(job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, WebJobQueued, interrupted.Status, "中止的任务不记为失败")
+	assert.Empty(t, interrupted.Error)
+
+	// 重启后继续处理
+	web.Processor.SetContext(context.Background())
+	restarted, err := NewWebJobQueue(web, queue.path)
+	require.NoError(t, err)
+	restarted.process = func(user, path string) (*WebResult, error) {
+		return &WebResult{Success: true}, nil
+	}
+	restarted.Start(1)
+	assert.Equal(t, WebJobCompleted, waitJob(t, restarted, job.ID).Status)
+	assert.NoError(t, restarted.Shutdown(context.Background()))
+}
@@ -0,0 +1,55 @@
+package audio
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// WantsSummaryStream 请求是否要求流式返回摘要：Accept 包含 text/event-stream 或查询参数 stream=1
+func WantsSummaryStream(r *http.Request) bool {
+	if strings.Contains(r.Header.Get("Accept"), "text/event-stream") {
+		return true
+	}
+	switch r.URL.Query().Get("stream") {
+	case "1", "true":
+		return true
+	}
+	return false
+}
+
+// StreamSummary 以 Server-Sent Events 推送摘要：每生成一段文本发送一个 delta 事件 {"text": "..."}，
+// 完成后发送 done 事件（extra 加上完整摘要 summary），失败时发送 error 事件 {"error": "..."}。
+// 客户端断开时取消生成。返回完整摘要与生成错误
+func StreamSummary(w http.ResponseWriter, r *http.Request, summarizer Summarizer, text string, extra map[string]string) (string, error) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeJobError(w, "不支持流式响应", http.StatusInternalServerError)
+		return "", fmt.Errorf("不支持流式响应")
+	}
+	w.Header().Set("Content-Type", "text/event-stream; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no") // 关闭 nginx 的响应缓冲
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+	send := func(name string, v interface{}) {
+		data, _ := json.Marshal(v)
+		fmt.Fprintf(w, "event: %s\ndata: %s\n\n", name, data)
+		flusher.Flush()
+	}
+
+	summary, err := summarizer.GenerateSummaryStream(r.Context(), text, func(delta string) {
+		send("delta", map[string]string{"text": delta})
+	})
+	if err != nil {
+		send("error", map[string]string{"error": fmt.Sprintf("生成总结失败: %v", err)})
+		return "", err
+	}
+	done := map[string]string{"summary": summary}
+	for key, value := range extra {
+		done[key] = value
+	}
+	send("done", done)
+	return summary, nil
+}
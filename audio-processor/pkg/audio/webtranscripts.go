@@ -0,0 +1,290 @@
+package audio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/export"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/search"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/utils"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/webauth"
+)
+
+// 文稿的段落来源
+const (
+	TranscriptFromStore = "store" // 数据库中保存的识别段落（store_segments）
+	TranscriptFromFile  = "file"  // 输出清单中的 JSON、SRT 或文本文稿
+)
+
+// ErrTranscriptNotFound 文稿不存在或没有可读取的输出
+var ErrTranscriptNotFound = errors.New("文稿不存在")
+
+// Summarizer 为文本生成摘要，由 llm.LLMClient 实现
+type Summarizer interface {
+	GenerateSummary(content string) (string, error)
+	// GenerateSummaryStream 流式生成摘要，每生成一段文本调用一次 onDelta
+	GenerateSummaryStream(ctx context.Context, content string, onDelta func(string)) (string, error)
+}
+
+// WebTranscript 一个已识别文件的文稿
+type WebTranscript struct {
+	Name        string   `json:"name"`     // 输出清单名称，读取段落与生成摘要时使用
+	Filename    string   `json:"filename"` // 处理记录中的文件名
+	Service     string   `json:"service,omitempty"`
+	ProcessedAt string   `json:"processed_at"`
+	Outputs     []string `json:"outputs"` // 输出格式，可从 /api/outputs/<名称>/<格式> 下载
+	Tags        []string `json:"tags,omitempty"`
+
+	source string // 处理记录的路径，数据库中的段落以它为键
+}
+
+// WebTranscriptPage 分页的文稿列表
+type WebTranscriptPage struct {
+	Transcripts []WebTranscript `json:"transcripts"`
+	Total       int             `json:"total"`
+	Page        int             `json:"page"`
+	PerPage     int             `json:"per_page"`
+}
+
+// WebTranscriptSegment 文稿中的一段，没有时间戳（文本文稿）时 Start 与 End 为 -1
+type WebTranscriptSegment struct {
+	Start   float64 `json:"start"`
+	End     float64 `json:"end"`
+	Text    string  `json:"text"`
+	Speaker string  `json:"speaker,omitempty"`
+}
+
+// WebTranscriptDetail 文稿与全部段落
+type WebTranscriptDetail struct {
+	WebTranscript
+	Source   string                 `json:"source"` // TranscriptFromStore 或 TranscriptFromFile
+	Segments []WebTranscriptSegment `json:"segments"`
+}
+
+// Text 合并全部段落的文本，用于生成摘要
+func (d *WebTranscriptDetail) Text() string {
+	lines := make([]string, 0, len(d.Segments))
+	for _, segment := range d.Segments {
+		lines = append(lines, segment.Text)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Transcripts 返回处理记录中已完成识别且仍有输出清单的文件，按处理时间倒序排列。
+// tag 非空时只返回带有该标签的文件
+func (w *WebProcessor) Transcripts(tag string) ([]WebTranscript, error) {
+	config := w.Config
+	p := w.Processor
+	p.recordsMu.RLock()
+	sources := make(map[string]ProcessedRecord, len(p.processedRecords))
+	for path, record := range p.processedRecords {
+		if record.Completed && record.Status == "" {
+			sources[path] = record
+		}
+	}
+	p.recordsMu.RUnlock()
+
+	tags, err := export.OpenTagStore(config.OutputFolder).Load()
+	if err != nil {
+		return nil, err
+	}
+	transcripts := []WebTranscript{}
+	seen := make(map[string]bool)
+	for path, record := range sources {
+		manifest, err := export.LoadManifest(export.ManifestPath(config.OutputFolder, path))
+		if err != nil || seen[manifest.Name] {
+			continue
+		}
+		meta := tags[export.ItemName(path)]
+		if tag != "" && !meta.HasTag(tag) {
+			continue
+		}
+		seen[manifest.Name] = true
+		transcript := WebTranscript{
+			Name:        manifest.Name,
+			Filename:    record.Filename,
+			Service:     record.Service,
+			ProcessedAt: record.LastProcessedTime,
+			Outputs:     make([]string, 0, len(manifest.Outputs)),
+			Tags:        meta.Tags,
+			source:      path,
+		}
+		for _, entry := range manifest.Outputs {
+			transcript.Outputs = append(transcript.Outputs, entry.Type)
+		}
+		transcripts = append(transcripts, transcript)
+	}
+	sort.Slice(transcripts, func(i, j int) bool {
+		if transcripts[i].ProcessedAt != transcripts[j].ProcessedAt {
+			return transcripts[i].ProcessedAt > transcripts[j].ProcessedAt
+		}
+		return transcripts[i].Name < transcripts[j].Name
+	})
+	return transcripts, nil
+}
+
+// Transcript 返回名称为 name 的文稿及全部段落：优先使用数据库中保存的识别段落，
+// 没有时读取输出清单中的文稿文件
+func (w *WebProcessor) Transcript(name string) (*WebTranscriptDetail, error) {
+	transcripts, err := w.Transcripts("")
+	if err != nil {
+		return nil, err
+	}
+	var transcript *WebTranscript
+	for i := range transcripts {
+		if transcripts[i].Name == name {
+			transcript = &transcripts[i]
+			break
+		}
+	}
+	if transcript == nil {
+		return nil, ErrTranscriptNotFound
+	}
+	detail := &WebTranscriptDetail{WebTranscript: *transcript, Segments: []WebTranscriptSegment{}}
+
+	if db := w.Processor.store; db != nil {
+		rows, err := db.Segments(transcript.source)
+		if err != nil {
+			utils.Warn("%v", err)
+		}
+		if len(rows) > 0 {
+			detail.Source = TranscriptFromStore
+			for _, row := range rows {
+				detail.Segments = append(detail.Segments, WebTranscriptSegment{Start: row.Start, End: row.End, Text: row.Text, Speaker: row.Speaker})
+			}
+			return detail, nil
+		}
+	}
+
+	config := w.Config
+	manifest, err := export.LoadManifest(export.ManifestPath(config.OutputFolder, transcript.source))
+	if err != nil {
+		return nil, ErrTranscriptNotFound
+	}
+	segments, ok, err := search.LoadSegments(manifest, config.OutputFolder, config.MediaFolder)
+	if !ok || errors.Is(err, os.ErrNotExist) {
+		return nil, ErrTranscriptNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	detail.Source = TranscriptFromFile
+	for _, segment := range segments {
+		detail.Segments = append(detail.Segments, WebTranscriptSegment{Start: segment.Start, End: segment.End, Text: segment.Text, Speaker: segment.Speaker})
+	}
+	return detail, nil
+}
+
+// TranscriptsHandler 提供已识别文稿的接口，启用认证时只包含当前用户的文件：
+//
+//	GET  prefix?tag=&page=&per_page=   按处理时间倒序分页列出文稿
+//	GET  prefix/<名称>                  文稿与全部段落
+//	POST prefix/<名称>/summary          按保存的文稿重新生成摘要，无需重新上传媒体文件（?stream=1 时以 SSE 逐段返回）
+//
+// summarizer 为 nil 时摘要接口返回 503
+func TranscriptsHandler(web *WebProcessor, summarizer Summarizer, prefix string) http.Handler {
+	return http.StripPrefix(prefix, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user := webauth.User(r.Context())
+		name, sub, _ := strings.Cut(strings.Trim(r.URL.Path, "/"), "/")
+		if name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+			http.NotFound(w, r)
+			return
+		}
+		switch {
+		case name == "" && r.Method == http.MethodGet:
+			serveTranscriptList(w, r, web.ForUser(user))
+		case name != "" && sub == "" && r.Method == http.MethodGet:
+			detail, err := web.ForUser(user).Transcript(name)
+			if err != nil {
+				writeTranscriptError(w, err)
+				return
+			}
+			writeJobJSON(w, http.StatusOK, detail)
+		case name != "" && sub == "summary" && r.Method == http.MethodPost:
+			serveTranscriptSummary(w, r, web.ForUser(user), summarizer, name)
+		case name == "" || (sub != "" && sub != "summary"):
+			http.NotFound(w, r)
+		default:
+			writeJobError(w, "不支持的请求方法", http.StatusMethodNotAllowed)
+		}
+	}))
+}
+
+// serveTranscriptList 处理 GET prefix?tag=&page=&per_page=
+func serveTranscriptList(w http.ResponseWriter, r *http.Request, web *WebProcessor) {
+	query := r.URL.Query()
+	page, err := queryPositive(query.Get("page"), 1)
+	if err != nil {
+		writeJobError(w, "无效的 page 参数", http.StatusBadRequest)
+		return
+	}
+	perPage, err := queryPositive(query.Get("per_page"), defaultJobsPerPage)
+	if err != nil {
+		writeJobError(w, "无效的 per_page 参数", http.StatusBadRequest)
+		return
+	}
+	if perPage > maxJobsPerPage {
+		perPage = maxJobsPerPage
+	}
+	transcripts, err := web.Transcripts(query.Get("tag"))
+	if err != nil {
+		writeJobError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	result := WebTranscriptPage{Transcripts: []WebTranscript{}, Total: len(transcripts), Page: page, PerPage: perPage}
+	if start := (page - 1) * perPage; start < len(transcripts) {
+		end := start + perPage
+		if end > len(transcripts) {
+			end = len(transcripts)
+		}
+		result.Transcripts = transcripts[start:end]
+	}
+	writeJobJSON(w, http.StatusOK, result)
+}
+
+// serveTranscriptSummary 处理 POST prefix/<名称>/summary
+func serveTranscriptSummary(w http.ResponseWriter, r *http.Request, web *WebProcessor, summarizer Summarizer, name string) {
+	io.Copy(io.Discard, io.LimitReader(r.Body, 1<<20))
+	if summarizer == nil {
+		writeJobError(w, "未配置API密钥，无法使用总结功能", http.StatusServiceUnavailable)
+		return
+	}
+	detail, err := web.Transcript(name)
+	if err != nil {
+		writeTranscriptError(w, err)
+		return
+	}
+	text := strings.TrimSpace(detail.Text())
+	if text == "" {
+		writeJobError(w, "文稿内容为空", http.StatusUnprocessableEntity)
+		return
+	}
+	if WantsSummaryStream(r) {
+		if _, err := StreamSummary(w, r, summarizer, text, map[string]string{"name": name}); err != nil {
+			utils.Error("为 %s 生成总结失败: %v", name, err)
+		}
+		return
+	}
+	summary, err := summarizer.GenerateSummary(text)
+	if err != nil {
+		utils.Error("为 %s 生成总结失败: %v", name, err)
+		writeJobError(w, fmt.Sprintf("生成总结失败: %v", err), http.StatusBadGateway)
+		return
+	}
+	writeJobJSON(w, http.StatusOK, map[string]string{"name": name, "summary": summary})
+}
+
+// writeTranscriptError 文稿不存在时返回 404，其余错误返回 500
+func writeTranscriptError(w http.ResponseWriter, err error) {
+	if errors.Is(err, ErrTranscriptNotFound) {
+		writeJobError(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	writeJobError(w, err.Error(), http.StatusInternalServerError)
+}
@@ -0,0 +1,148 @@
+package audio
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/export"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/models"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/webauth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSummarizer 记录收到的文本并返回固定摘要
+type fakeSummarizer struct {
+	content string
+	err     error
+}
+
+func (s *fakeSummarizer) GenerateSummary(content string) (string, error) {
+	s.content = content
+	return "摘要", s.err
+}
+
+// GenerateSummaryStream 分两段返回固定摘要
+func (s *fakeSummarizer) GenerateSummaryStream(ctx context.Context, content string, onDelta func(string)) (string, error) {
+	s.content = content
+	if s.err != nil {
+		return "", s.err
+	}
+	onDelta("摘")
+	onDelta("要")
+	return "摘要", nil
+}
+
+// TestWebTranscripts 测试列出已识别的文稿，优先从数据库读取段落，并按保存的文稿重新生成摘要
+func TestWebTranscripts(t *testing.T) {
+	root := t.TempDir()
+	config := models.NewDefaultConfig()
+	config.OutputFolder = filepath.Join(root, "output")
+	config.StoreSegments = true
+	web := NewWebProcessor(filepath.Join(root, "uploads"), filepath.Join(root, "temp"), config.OutputFolder, config)
+	alice := web.ForUser("alice")
+	defer alice.Processor.Close()
+	defer web.Processor.Close()
+
+	// 一个文件的段落保存在数据库中，另一个只有SRT文稿，还有一个识别失败
+	processed := func(name, service string, outputs map[string]string) string {
+		path := filepath.Join(alice.UploadDir, name)
+		alice.Processor.updateProcessedRecord(path, &BatchResult{FilePath: path, Success: outputs != nil, Service: service})
+		if outputs != nil {
+			_, err := export.WriteManifest(alice.Config, path, outputs)
+			require.NoError(t, err)
+		}
+		return path
+	}
+	txt := filepath.Join(alice.Config.OutputFolder, "meeting.txt")
+	require.NoError(t, os.WriteFile(txt, []byte("大家好\n"), 0644))
+	meeting := processed("meeting.mp3", "bcut", map[string]string{"txt": txt})
+	alice.Processor.saveSegments(meeting, []models.DataSegment{{Text: "大家好", StartTime: 0, EndTime: 1.5, Speaker: "A"}, {Text: "开始开会", StartTime: 1.5, EndTime: 3}})
+	srt := filepath.Join(alice.Config.OutputFolder, "talk.srt")
+	require.NoError(t, os.WriteFile(srt, []byte("1\n00:00:01,000 --> 00:00:02,500\n第一句\n\n2\n00:00:03,000 --> 00:00:04,000\n第二句\n"), 0644))
+	processed("talk.mp3", "kuaishou", map[string]string{"srt": srt})
+	processed("broken.mp3", "", nil)
+	_, err := export.OpenTagStore(alice.Config.OutputFolder).Update("talk", export.MetaUpdate{Add: []string{"演讲"}})
+	require.NoError(t, err)
+
+	summarizer := &fakeSummarizer{}
+	handler := TranscriptsHandler(web, summarizer, "/api/transcripts")
+	serve := func(user, method, target string, v interface{}) int {
+		req := httptest.NewRequest(method, target, nil)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req.WithContext(webauth.WithUser(req.Context(), user)))
+		if v != nil && rec.Code == http.StatusOK {
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(v))
+		}
+		return rec.Code
+	}
+
+	var page WebTranscriptPage
+	require.Equal(t, http.StatusOK, serve("alice", http.MethodGet, "/api/transcripts", &page))
+	require.Len(t, page.Transcripts, 2)
+	assert.Equal(t, 2, page.Total)
+	names := []string{page.Transcripts[0].Name, page.Transcripts[1].Name}
+	assert.ElementsMatch(t, []string{"meeting", "talk"}, names)
+
+	require.Equal(t, http.StatusOK, serve("alice", http.MethodGet, "/api/transcripts?tag=演讲", &page))
+	require.Len(t, page.Transcripts, 1)
+	assert.Equal(t, "talk", page.Transcripts[0].Name)
+	assert.Equal(t, "talk.mp3", page.Transcripts[0].Filename)
+	assert.Equal(t, []string{"srt"}, page.Transcripts[0].Outputs)
+
+	require.Equal(t, http.StatusOK, serve("alice", http.MethodGet, "/api/transcripts?per_page=1&page=2", &page))
+	assert.Len(t, page.Transcripts, 1)
+	assert.Equal(t, 2, page.Total)
+
+	var detail WebTranscriptDetail
+	require.Equal(t, http.StatusOK, serve("alice", http.MethodGet, "/api/transcripts/meeting", &detail))
+	assert.Equal(t, TranscriptFromStore, detail.Source)
+	assert.Equal(t, "bcut", detail.Service)
+	assert.Equal(t, []WebTranscriptSegment{{Start: 0, End: 1.5, Text: "大家好", Speaker: "A"}, {Start: 1.5, End: 3, Text: "开始开会"}}, detail.Segments)
+
+	detail = WebTranscriptDetail{}
+	require.Equal(t, http.StatusOK, serve("alice", http.MethodGet, "/api/transcripts/talk", &detail))
+	assert.Equal(t, TranscriptFromFile, detail.Source)
+	assert.Equal(t, []WebTranscriptSegment{{Start: 1, End: 2.5, Text: "第一句"}, {Start: 3, End: 4, Text: "第二句"}}, detail.Segments)
+
+	// 摘要使用保存的文稿
+	var summary map[string]string
+	require.Equal(t, http.StatusOK, serve("alice", http.MethodPost, "/api/transcripts/talk/summary", &summary))
+	assert.Equal(t, "摘要", summary["summary"])
+	assert.Equal(t, "第一句\n第二句", summarizer.content)
+
+	// 流式返回时逐段推送，最后发送完整摘要
+	stream := func() string {
+		req := httptest.NewRequest(http.MethodPost, "/api/transcripts/talk/summary?stream=1", nil)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req.WithContext(webauth.WithUser(req.Context(), "alice")))
+		assert.Equal(t, "text/event-stream; charset=utf-8", rec.Header().Get("Content-Type"))
+		return rec.Body.String()
+	}
+	assert.Equal(t, "event: delta\ndata: {\"text\":\"摘\"}\n\n"+
+		"event: delta\ndata: {\"text\":\"要\"}\n\n"+
+		"event: done\ndata: {\"name\":\"talk\",\"summary\":\"摘要\"}\n\n", stream())
+
+	summarizer.err = errors.New("服务不可用")
+	assert.Equal(t, http.StatusBadGateway, serve("alice", http.MethodPost, "/api/transcripts/talk/summary", nil))
+	assert.Equal(t, "event: error\ndata: {\"error\":\"生成总结失败: 服务不可用\"}\n\n", stream())
+
+	// 其他用户看不到，不存在的文稿与失败的文件返回 404
+	require.Equal(t, http.StatusOK, serve("bob", http.MethodGet, "/api/transcripts", &page))
+	assert.Empty(t, page.Transcripts)
+	assert.Equal(t, http.StatusNotFound, serve("bob", http.MethodGet, "/api/transcripts/talk", nil))
+	assert.Equal(t, http.StatusNotFound, serve("alice", http.MethodGet, "/api/transcripts/broken", nil))
+	assert.Equal(t, http.StatusNotFound, serve("alice", http.MethodGet, "/api/transcripts/talk/other", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, serve("alice", http.MethodDelete, "/api/transcripts/talk", nil))
+	assert.Equal(t, http.StatusBadRequest, serve("alice", http.MethodGet, "/api/transcripts?page=0", nil))
+
+	// 未配置摘要服务
+	handler = TranscriptsHandler(web, nil, "/api/transcripts")
+	assert.Equal(t, http.StatusServiceUnavailable, serve("alice", http.MethodPost, "/api/transcripts/talk/summary", nil))
+}
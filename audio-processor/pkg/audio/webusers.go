@@ -0,0 +1,43 @@
+package audio
+
+import (
+	"path"
+	"path/filepath"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/webauth"
+)
+
+// ForUser 返回用户独立的Web处理器：上传、临时与输出目录位于各目录的 users/<用户名> 下，
+// 处理记录、输出清单与标签互不可见。user 为空（未启用认证）时返回 w 本身
+func (w *WebProcessor) ForUser(user string) *WebProcessor {
+	if user == "" {
+		return w
+	}
+	w.usersMu.Lock()
+	defer w.usersMu.Unlock()
+	if web, exists := w.users[user]; exists {
+		return web
+	}
+
+	outputDir := webauth.UserDir(w.OutputDir, user)
+	config := *w.Config
+	config.OutputFolder = outputDir
+	// 远程存储中各用户的输出同样位于 users/<用户名> 下
+	config.StoragePrefix = path.Join(config.StoragePrefix, filepath.ToSlash(webauth.UserDir("", user)))
+	web := NewWebProcessor(webauth.UserDir(w.UploadDir, user), webauth.UserDir(w.TempDir, user), outputDir, &config)
+	web.MaxFileSize = w.MaxFileSize
+
+	// 识别服务、进度订阅与上下文和公共处理器共用
+	processor := web.Processor
+	processor.SetASRSelector(w.Processor.ASRSelector)
+	processor.SetProgressManager(w.Processor.ProgressManager)
+	processor.SetFileProgressCallback(w.Processor.FileProgressCallback)
+	processor.ctx = w.Processor.ctx
+	processor.MaxConcurrency = w.Processor.MaxConcurrency
+
+	if w.users == nil {
+		w.users = make(map[string]*WebProcessor)
+	}
+	w.users[user] = web
+	return web
+}
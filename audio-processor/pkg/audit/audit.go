@@ -0,0 +1,177 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/utils"
+)
+
+// 操作类型
+const (
+	ActionDelete    = "delete"    // 直接删除
+	ActionTrash     = "trash"     // 移入回收站
+	ActionPurge     = "purge"     // 从回收目录中永久清理
+	ActionMove      = "move"      // 移动文件
+	ActionOverwrite = "overwrite" // 覆盖已有文件
+)
+
+// Entry 一条审计记录
+type Entry struct {
+	Time      time.Time `json:"time"`
+	Action    string    `json:"action"`
+	Path      string    `json:"path"`
+	Target    string    `json:"target,omitempty"` // 移动/回收后的新位置
+	Reason    string    `json:"reason,omitempty"`
+	Initiator string    `json:"initiator,omitempty"` // 发起者，如 batch、watch、web、cleanup
+	Error     string    `json:"error,omitempty"`
+}
+
+// Logger 将审计记录以 JSON Lines 格式追加写入文件
+type Logger struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewLogger 创建审计日志记录器
+func NewLogger(path string) *Logger {
+	return &Logger{path: path}
+}
+
+// Path 返回审计日志文件路径
+func (l *Logger) Path() string {
+	if l == nil {
+		return ""
+	}
+	return l.path
+}
+
+// Record 追加一条审计记录，nil 记录器不做任何事
+func (l *Logger) Record(entry Entry) {
+	if l == nil || l.path == "" {
+		return
+	}
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+	if abs, err := filepath.Abs(entry.Path); err == nil {
+		entry.Path = abs
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		utils.Warn("序列化审计记录失败: %v", err)
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(l.path), 0755); err != nil {
+		utils.Warn("创建审计日志目录失败: %v", err)
+		return
+	}
+	f, err := os.OpenFile(l.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		utils.Warn("打开审计日志失败: %v", err)
+		return
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		utils.Warn("写入审计日志失败: %v", err)
+	}
+}
+
+var (
+	defaultMu     sync.RWMutex
+	defaultLogger *Logger
+)
+
+// SetDefault 设置全局审计日志记录器
+func SetDefault(logger *Logger) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultLogger = logger
+}
+
+// Default 返回全局审计日志记录器，未设置时为 nil
+func Default() *Logger {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	return defaultLogger
+}
+
+// Record 使用全局记录器追加一条审计记录
+func Record(entry Entry) {
+	Default().Record(entry)
+}
+
+// RecordOverwrite 在写入文件前调用，目标文件已存在时记录一次覆盖操作
+func RecordOverwrite(path, initiator, reason string) {
+	if _, err := os.Stat(path); err != nil {
+		return
+	}
+	Record(Entry{
+		Action:    ActionOverwrite,
+		Path:      path,
+		Reason:    reason,
+		Initiator: initiator,
+	})
+}
+
+// Filter 读取审计日志时的过滤条件
+type Filter struct {
+	Since  time.Time // 只返回该时间之后的记录
+	Action string    // 只返回指定操作类型
+	Limit  int       // 只返回最近的 N 条，0 表示不限制
+}
+
+// ReadEntries 读取审计日志，按时间顺序返回符合条件的记录
+func ReadEntries(path string, filter Filter) ([]Entry, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("打开审计日志失败: %w", err)
+	}
+	defer f.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			utils.Warn("跳过无法解析的审计记录 (第 %d 行): %v", line, err)
+			continue
+		}
+		if !filter.Since.IsZero() && entry.Time.Before(filter.Since) {
+			continue
+		}
+		if filter.Action != "" && entry.Action != filter.Action {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("读取审计日志失败: %w", err)
+	}
+
+	if filter.Limit > 0 && len(entries) > filter.Limit {
+		entries = entries[len(entries)-filter.Limit:]
+	}
+	return entries, nil
+}
@@ -0,0 +1,59 @@
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordAndReadEntries(t *testing.T) {
+	dir := t.TempDir()
+	logger := NewLogger(filepath.Join(dir, "audit.jsonl"))
+
+	old := time.Now().Add(-48 * time.Hour)
+	logger.Record(Entry{Time: old, Action: ActionDelete, Path: filepath.Join(dir, "a.mp3"), Initiator: "cleanup"})
+	logger.Record(Entry{Action: ActionMove, Path: filepath.Join(dir, "b.mp4"), Target: filepath.Join(dir, "media", "b.mp4"), Initiator: "watch"})
+	logger.Record(Entry{Action: ActionTrash, Path: filepath.Join(dir, "c.mp3"), Initiator: "asr"})
+
+	all, err := ReadEntries(logger.Path(), Filter{})
+	require.NoError(t, err)
+	assert.Len(t, all, 3)
+	assert.Equal(t, ActionDelete, all[0].Action)
+
+	recent, err := ReadEntries(logger.Path(), Filter{Since: time.Now().Add(-time.Hour)})
+	require.NoError(t, err)
+	assert.Len(t, recent, 2)
+
+	moves, err := ReadEntries(logger.Path(), Filter{Action: ActionMove})
+	require.NoError(t, err)
+	require.Len(t, moves, 1)
+	assert.Equal(t, "watch", moves[0].Initiator)
+
+	last, err := ReadEntries(logger.Path(), Filter{Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, last, 1)
+	assert.Equal(t, ActionTrash, last[0].Action)
+}
+
+func TestRecordOverwriteOnlyForExistingFiles(t *testing.T) {
+	dir := t.TempDir()
+	logger := NewLogger(filepath.Join(dir, "audit.jsonl"))
+	SetDefault(logger)
+	defer SetDefault(nil)
+
+	existing := filepath.Join(dir, "talk.srt")
+	require.NoError(t, os.WriteFile(existing, []byte("1"), 0644))
+
+	RecordOverwrite(existing, "export", "重新生成SRT字幕")
+	RecordOverwrite(filepath.Join(dir, "new.srt"), "export", "重新生成SRT字幕")
+
+	entries, err := ReadEntries(logger.Path(), Filter{})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, ActionOverwrite, entries[0].Action)
+	assert.Equal(t, existing, entries[0].Path)
+}
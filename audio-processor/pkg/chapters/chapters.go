@@ -0,0 +1,269 @@
+// Package chapters 将文稿切分为按主题划分的章节：先以段落间的停顿作为候选分界点，
+// 配置了大模型时由大模型选择分界并命名章节，否则取停顿最长的位置并以章节开头的文字作为标题
+package chapters
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/models"
+)
+
+// 停顿达到该时长（秒）的段落开头作为候选分界点
+const minGap = 1.0
+
+// 没有大模型时，标题取章节开头的字数
+const titleChars = 20
+
+// 大模型给出的分界点与候选分界点相差不超过该时长（秒）时对齐到候选分界点
+const snapWindow = 15.0
+
+// 提交给大模型的文稿上限（字符）
+const maxPromptChars = 100000
+
+// chapterPrompt 选择章节的系统提示
+const chapterPrompt = "你是一个视频编辑。下面是一段音视频的识别文稿，每行开头的方括号内是该段的开始时间（秒），" +
+	"行首带 * 的段落前有明显停顿，更适合作为章节的开始。请按主题将内容划分为章节，" +
+	"只返回JSON数组，不要输出其他内容，格式为 [{\"start\": 开始时间（秒，取文稿中已有的时间）, \"title\": \"简短的章节标题\"}]。" +
+	"第一个章节从 0 开始，每个章节不短于 %.0f 秒。"
+
+// LLM 选择章节与命名的大模型接口
+type LLM interface {
+	Chat(systemPrompt, content string) (string, error)
+}
+
+// Chapter 一个章节
+type Chapter struct {
+	Start float64 `json:"start"` // 开始时间（秒）
+	End   float64 `json:"end"`   // 结束时间（秒）
+	Title string  `json:"title"`
+}
+
+// Options 章节划分选项
+type Options struct {
+	MinLength float64 // 章节最短时长（秒）
+}
+
+// OptionsFromConfig 从配置读取章节划分选项
+func OptionsFromConfig(config *models.Config) Options {
+	return Options{MinLength: config.ChapterMinLength}
+}
+
+// usable 返回有文字的段落
+func usable(segments []models.DataSegment) []models.DataSegment {
+	var result []models.DataSegment
+	for _, segment := range segments {
+		text := strings.TrimSpace(segment.Text)
+		if text == "" || text == "[无法识别的音频片段]" {
+			continue
+		}
+		result = append(result, segment)
+	}
+	return result
+}
+
+// candidate 候选分界点：段落序号与其前面的停顿时长
+type candidate struct {
+	index int
+	gap   float64
+}
+
+// candidates 返回停顿不短于 minGap 的段落，第一段不作为候选
+func candidates(segments []models.DataSegment) []candidate {
+	var result []candidate
+	for i := 1; i < len(segments); i++ {
+		if gap := segments[i].StartTime - segments[i-1].EndTime; gap >= minGap {
+			result = append(result, candidate{index: i, gap: gap})
+		}
+	}
+	return result
+}
+
+// Detect 划分章节。llm 为 nil 或调用失败时按停顿划分，返回的 error 只用于提示大模型失败的原因
+func Detect(segments []models.DataSegment, llm LLM, opts Options) ([]Chapter, error) {
+	segments = usable(segments)
+	if len(segments) == 0 {
+		return nil, nil
+	}
+
+	var llmErr error
+	if llm != nil {
+		starts, err := requestStarts(llm, segments, opts)
+		if err == nil {
+			if chapters := build(segments, snap(segments, starts), opts); len(chapters) > 0 {
+				return chapters, nil
+			}
+			err = fmt.Errorf("大模型未返回有效章节")
+		}
+		llmErr = err
+	}
+	return build(segments, byGaps(segments, opts), opts), llmErr
+}
+
+// titledStart 章节的开始段落与标题，标题为空时取开头的文字
+type titledStart struct {
+	index int
+	title string
+}
+
+// llmChapter 大模型返回的章节
+type llmChapter struct {
+	Start float64 `json:"start"`
+	Title string  `json:"title"`
+}
+
+// requestStarts 请求大模型选择章节
+func requestStarts(llm LLM, segments []models.DataSegment, opts Options) ([]llmChapter, error) {
+	gaps := make(map[int]bool)
+	for _, c := range candidates(segments) {
+		gaps[c.index] = true
+	}
+	var b strings.Builder
+	for i, segment := range segments {
+		mark := ""
+		if gaps[i] {
+			mark = "*"
+		}
+		fmt.Fprintf(&b, "%s[%.0f] %s\n", mark, segment.StartTime, strings.TrimSpace(segment.Text))
+	}
+	content := b.String()
+	if runes := []rune(content); len(runes) > maxPromptChars {
+		content = string(runes[:maxPromptChars])
+	}
+
+	reply, err := llm.Chat(fmt.Sprintf(chapterPrompt, opts.MinLength), content)
+	if err != nil {
+		return nil, fmt.Errorf("大模型划分章节失败: %w", err)
+	}
+	start := strings.Index(reply, "[")
+	end := strings.LastIndex(reply, "]")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("大模型未返回JSON: %q", reply)
+	}
+	var result []llmChapter
+	if err := json.Unmarshal([]byte(reply[start:end+1]), &result); err != nil {
+		return nil, fmt.Errorf("解析章节失败: %w", err)
+	}
+	return result, nil
+}
+
+// snap 将大模型给出的时间对齐到段落开头：附近有停顿时优先对齐到停顿处，否则对齐到最近的段落
+func snap(segments []models.DataSegment, starts []llmChapter) []titledStart {
+	gaps := candidates(segments)
+	var result []titledStart
+	for _, s := range starts {
+		best, bestDist := -1, math.Inf(1)
+		for _, c := range gaps {
+			if d := math.Abs(segments[c.index].StartTime - s.Start); d <= snapWindow && d < bestDist {
+				best, bestDist = c.index, d
+			}
+		}
+		if best < 0 {
+			for i, segment := range segments {
+				if d := math.Abs(segment.StartTime - s.Start); d < bestDist {
+					best, bestDist = i, d
+				}
+			}
+		}
+		result = append(result, titledStart{index: best, title: strings.TrimSpace(s.Title)})
+	}
+	return result
+}
+
+// byGaps 按停顿划分：从最长的停顿开始选择分界点，与已选分界点的距离不短于最短章节时长
+func byGaps(segments []models.DataSegment, opts Options) []titledStart {
+	gaps := candidates(segments)
+	sort.SliceStable(gaps, func(i, j int) bool { return gaps[i].gap > gaps[j].gap })
+
+	end := segments[len(segments)-1].EndTime
+	chosen := []float64{segments[0].StartTime, end}
+	result := []titledStart{{index: 0}}
+	for _, c := range gaps {
+		start := segments[c.index].StartTime
+		ok := true
+		for _, t := range chosen {
+			if math.Abs(start-t) < opts.MinLength {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			chosen = append(chosen, start)
+			result = append(result, titledStart{index: c.index})
+		}
+	}
+	return result
+}
+
+// build 由章节开头生成章节：按时间排序，第一个章节从 0 开始，合并短于最短时长的章节，补全结束时间与标题
+func build(segments []models.DataSegment, starts []titledStart, opts Options) []Chapter {
+	sort.SliceStable(starts, func(i, j int) bool { return starts[i].index < starts[j].index })
+	end := segments[len(segments)-1].EndTime
+
+	var chapters []Chapter
+	for _, s := range starts {
+		if s.index < 0 || s.index >= len(segments) {
+			continue
+		}
+		start := segments[s.index].StartTime
+		title := s.title
+		if title == "" {
+			title = headline(segments[s.index].Text)
+		}
+		if len(chapters) == 0 {
+			chapters = append(chapters, Chapter{Start: 0, Title: title})
+			continue
+		}
+		last := &chapters[len(chapters)-1]
+		if start-last.Start < opts.MinLength || start <= last.Start {
+			continue
+		}
+		last.End = start
+		chapters = append(chapters, Chapter{Start: start, Title: title})
+	}
+	if len(chapters) == 0 {
+		return nil
+	}
+	// 最后一个章节过短时并入前一个章节
+	if n := len(chapters); n > 1 && end-chapters[n-1].Start < opts.MinLength {
+		chapters = chapters[:n-1]
+	}
+	chapters[len(chapters)-1].End = end
+	return chapters
+}
+
+// headline 取文字开头作为标题，在标点处截断
+func headline(text string) string {
+	text = strings.TrimSpace(text)
+	if i := strings.IndexAny(text, "。！？，；.!?,;"); i > 0 {
+		text = text[:i]
+	}
+	if utf8.RuneCountInString(text) > titleChars {
+		text = string([]rune(text)[:titleChars]) + "…"
+	}
+	return text
+}
+
+// FormatYouTube 按YouTube视频描述的章节格式输出，每行为 "0:00 标题"。
+// YouTube 要求至少 3 个章节、第一个从 0:00 开始、每个不短于 10 秒
+func FormatYouTube(chapters []Chapter) string {
+	var b strings.Builder
+	for _, chapter := range chapters {
+		fmt.Fprintf(&b, "%s %s\n", youtubeTime(chapter.Start), chapter.Title)
+	}
+	return b.String()
+}
+
+// youtubeTime 将秒数格式化为 m:ss 或 h:mm:ss
+func youtubeTime(seconds float64) string {
+	total := int(seconds)
+	h, m, s := total/3600, total/60%60, total%60
+	if h > 0 {
+		return fmt.Sprintf("%d:%02d:%02d", h, m, s)
+	}
+	return fmt.Sprintf("%d:%02d", m, s)
+}
@@ -0,0 +1,68 @@
+package chapters
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeLLM 返回固定回复
+type fakeLLM struct {
+	reply string
+	err   error
+}
+
+func (f fakeLLM) Chat(systemPrompt, content string) (string, error) {
+	return f.reply, f.err
+}
+
+// testSegments 每 10 秒一段，在 60 秒与 120 秒处有较长停顿
+func testSegments() []models.DataSegment {
+	var segments []models.DataSegment
+	for start := 0.0; start < 180; start += 10 {
+		end := start + 9.5
+		if start == 50 || start == 110 {
+			end = start + 5
+		}
+		segments = append(segments, models.DataSegment{Text: "第一句，后面的话", StartTime: start, EndTime: end})
+	}
+	segments[12].Text = "第二部分开始了。"
+	return segments
+}
+
+func TestDetectByGaps(t *testing.T) {
+	list, err := Detect(testSegments(), nil, Options{MinLength: 30})
+	require.NoError(t, err)
+	assert.Equal(t, []Chapter{
+		{Start: 0, End: 60, Title: "第一句"},
+		{Start: 60, End: 120, Title: "第一句"},
+		{Start: 120, End: 179.5, Title: "第二部分开始了"},
+	}, list)
+	assert.Equal(t, "0:00 第一句\n1:00 第一句\n2:00 第二部分开始了\n", FormatYouTube(list))
+}
+
+func TestDetectWithLLM(t *testing.T) {
+	// 58 秒对齐到 60 秒处的停顿，100 秒附近没有停顿时对齐到最近的段落；过短的章节被合并
+	llm := fakeLLM{reply: "```json\n" + `[{"start": 3, "title": "开场"}, {"start": 58, "title": "背景"}, {"start": 71, "title": "太短"}, {"start": 101, "title": "正题"}]` + "\n```"}
+	list, err := Detect(testSegments(), llm, Options{MinLength: 30})
+	require.NoError(t, err)
+	assert.Equal(t, []Chapter{
+		{Start: 0, End: 60, Title: "开场"},
+		{Start: 60, End: 100, Title: "背景"},
+		{Start: 100, End: 179.5, Title: "正题"},
+	}, list)
+
+	// 大模型失败时按停顿划分，并返回失败原因
+	list, err = Detect(testSegments(), fakeLLM{err: errors.New("timeout")}, Options{MinLength: 30})
+	assert.Error(t, err)
+	assert.Len(t, list, 3)
+}
+
+func TestYouTubeTime(t *testing.T) {
+	assert.Equal(t, "0:05", youtubeTime(5.9))
+	assert.Equal(t, "12:00", youtubeTime(720))
+	assert.Equal(t, "1:02:03", youtubeTime(3723))
+}
@@ -0,0 +1,343 @@
+// Package client 访问远程 asr 实例的HTTP接口：提交识别任务并等待结果、监听队列入队与查询、按输出清单下载结果。
+// 可以在性能较弱的电脑上把文件交给家里的服务器处理
+package client
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/internal/watcher"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/audio"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/export"
+)
+
+// DefaultServer 未指定服务器时使用的地址，与 audio_web 的默认端口一致
+const DefaultServer = "http://localhost:8080"
+
+// Client 远程实例的客户端
+type Client struct {
+	BaseURL    string
+	Token      string       // 服务器启用认证（web_auth_mode）时使用的API密钥或 JWT，为空时不发送
+	HTTPClient *http.Client // 上传识别可能耗时很久，默认不设超时，由 ctx 控制
+}
+
+// New 创建客户端，baseURL 如 http://192.168.1.10:8080
+func New(baseURL string) *Client {
+	return &Client{
+		BaseURL:    strings.TrimRight(baseURL, "/"),
+		HTTPClient: &http.Client{},
+	}
+}
+
+// APIError 服务器返回的错误
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("服务器返回 %d: %s", e.StatusCode, e.Message)
+}
+
+// do 发送请求，设置了 Token 时以 Authorization: Bearer 携带，状态码不是 2xx 时返回 APIError
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("请求 %s 失败: %w", req.URL.Path, err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		message := strings.TrimSpace(string(body))
+		// audio_web 以 {"error": "..."} 返回错误
+		var payload struct {
+			Error string `json:"error"`
+		}
+		if json.Unmarshal(body, &payload) == nil && payload.Error != "" {
+			message = payload.Error
+		}
+		return nil, &APIError{StatusCode: resp.StatusCode, Message: message}
+	}
+	return resp, nil
+}
+
+// getJSON 发送GET请求并解析JSON响应
+func (c *Client) getJSON(ctx context.Context, path string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.BaseURL+path, nil)
+	if err != nil {
+		return fmt.Errorf("创建请求失败: %w", err)
+	}
+	resp, err := c.do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("解析响应失败: %w", err)
+	}
+	return nil
+}
+
+// postFile 以 multipart 表单的 file 字段上传本地文件到 path，返回服务器的响应
+func (c *Client) postFile(ctx context.Context, path, filePath string) (*http.Response, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("打开文件失败: %w", err)
+	}
+	defer file.Close()
+
+	// 边读边发送，避免大文件整个读入内存
+	body, writer := io.Pipe()
+	form := multipart.NewWriter(writer)
+	go func() {
+		part, err := form.CreateFormFile("file", filepath.Base(filePath))
+		if err == nil {
+			_, err = io.Copy(part, file)
+		}
+		if err == nil {
+			err = form.Close()
+		}
+		writer.CloseWithError(err)
+	}()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.BaseURL+path, body)
+	if err != nil {
+		body.Close()
+		return nil, fmt.Errorf("创建请求失败: %w", err)
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	resp, err := c.do(req)
+	body.Close()
+	return resp, err
+}
+
+// Upload 上传本地文件到 audio_web 的 /upload 接口，等待服务器识别完成后返回结果。
+// 整个识别期间保持一个请求，仅用于不支持 /api/jobs 的旧服务器，新代码使用 Submit
+func (c *Client) Upload(ctx context.Context, path string) (*audio.WebResult, error) {
+	resp, err := c.postFile(ctx, "/upload", path)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var result audio.WebResult
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("解析响应失败: %w", err)
+	}
+	return &result, nil
+}
+
+// Submit 上传本地文件到 /api/jobs 提交识别任务，上传完成后立即返回排队中的任务
+func (c *Client) Submit(ctx context.Context, path string) (*audio.WebJob, error) {
+	resp, err := c.postFile(ctx, "/api/jobs", path)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var job audio.WebJob
+	if err := json.NewDecoder(resp.Body).Decode(&job); err != nil {
+		return nil, fmt.Errorf("解析响应失败: %w", err)
+	}
+	return &job, nil
+}
+
+// Job 查询任务的状态，结束后包含处理结果
+func (c *Client) Job(ctx context.Context, id string) (*audio.WebJob, error) {
+	var job audio.WebJob
+	if err := c.getJSON(ctx, "/api/jobs/"+url.PathEscape(id), &job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// WaitJob 每隔 interval 查询一次任务，直到任务结束（成功或失败）后返回
+func (c *Client) WaitJob(ctx context.Context, id string, interval time.Duration) (*audio.WebJob, error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		job, err := c.Job(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if job.Finished() {
+			return job, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Enqueue 将服务器上的文件加入监听模式的处理队列，返回服务器使用的绝对路径
+func (c *Client) Enqueue(ctx context.Context, serverPath string) (string, error) {
+	data, _ := json.Marshal(map[string]string{"path": serverPath})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.BaseURL+"/api/enqueue", strings.NewReader(string(data)))
+	if err != nil {
+		return "", fmt.Errorf("创建请求失败: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Path string `json:"path"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("解析响应失败: %w", err)
+	}
+	return result.Path, nil
+}
+
+// Queue 返回监听模式的队列视图
+func (c *Client) Queue(ctx context.Context) (*watcher.QueueSnapshot, error) {
+	var snapshot watcher.QueueSnapshot
+	if err := c.getJSON(ctx, "/api/queue", &snapshot); err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
+
+// LastFinished 返回队列视图中 serverPath 最近一次处理结束的时间（服务器时间），没有记录时为零值
+func LastFinished(snapshot *watcher.QueueSnapshot, serverPath string) time.Time {
+	var last time.Time
+	for _, entry := range snapshot.Recent {
+		if entry.Path == serverPath && entry.FinishedAt.After(last) {
+			last = entry.FinishedAt
+		}
+	}
+	return last
+}
+
+// Wait 每隔 interval 查询一次队列，直到 serverPath 在 after 之后处理结束（成功、失败或隔离）。
+// after 取入队前的 LastFinished，避免把上一次的结果当作本次结果，也不受两端时钟差异影响
+func (c *Client) Wait(ctx context.Context, serverPath string, after time.Time, interval time.Duration) (*watcher.QueueEntry, error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		snapshot, err := c.Queue(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range snapshot.Recent {
+			if entry.Path == serverPath && entry.FinishedAt.After(after) {
+				return &entry, nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// ProcessNow 让监听模式立即处理等待时间窗口（watch_schedule）的文件，
+// profile 为 watch_profiles 中的名称，为空时表示只监控 media_folder 的实例
+func (c *Client) ProcessNow(ctx context.Context, profile string) (*watcher.ScheduleStatus, error) {
+	path := "/api/schedule"
+	if profile != "" {
+		path = "/api/" + url.PathEscape(profile) + "/schedule"
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.BaseURL+path, strings.NewReader(`{"action":"process_now"}`))
+	if err != nil {
+		return nil, fmt.Errorf("创建请求失败: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var status watcher.ScheduleStatus
+	if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+		return nil, fmt.Errorf("解析响应失败: %w", err)
+	}
+	return &status, nil
+}
+
+// Outputs 返回服务器上全部文件的输出清单，tag 不为空时只返回带有该标签的文件
+func (c *Client) Outputs(ctx context.Context, tag string) ([]*export.OutputManifest, error) {
+	path := "/api/outputs/"
+	if tag != "" {
+		path += "?tag=" + url.QueryEscape(tag)
+	}
+	var manifests []*export.OutputManifest
+	if err := c.getJSON(ctx, path, &manifests); err != nil {
+		return nil, err
+	}
+	return manifests, nil
+}
+
+// Manifest 返回单个文件的输出清单
+func (c *Client) Manifest(ctx context.Context, name string) (*export.OutputManifest, error) {
+	var manifest export.OutputManifest
+	if err := c.getJSON(ctx, "/api/outputs/"+url.PathEscape(name), &manifest); err != nil {
+		return nil, err
+	}
+	return &manifest, nil
+}
+
+// Download 下载文件的一个输出到 dir，文件名与服务器上相同，并按清单中的校验和核对内容。
+// 返回保存的路径
+func (c *Client) Download(ctx context.Context, name string, entry export.OutputEntry, dir string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.BaseURL+"/api/outputs/"+url.PathEscape(name)+"/"+url.PathEscape(entry.Type), nil)
+	if err != nil {
+		return "", fmt.Errorf("创建请求失败: %w", err)
+	}
+	resp, err := c.do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("创建下载目录失败: %w", err)
+	}
+	target := filepath.Join(dir, filepath.Base(filepath.FromSlash(entry.Path)))
+	tmp := target + ".part"
+	file, err := os.Create(tmp)
+	if err != nil {
+		return "", fmt.Errorf("创建文件失败: %w", err)
+	}
+	hash := sha256.New()
+	_, err = io.Copy(io.MultiWriter(file, hash), resp.Body)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return "", fmt.Errorf("下载 %s 失败: %w", entry.Type, err)
+	}
+	if sum := hex.EncodeToString(hash.Sum(nil)); entry.SHA256 != "" && sum != entry.SHA256 {
+		os.Remove(tmp)
+		return "", fmt.Errorf("下载 %s 的校验和不一致: %s != %s", entry.Type, sum, entry.SHA256)
+	}
+	if err := os.Rename(tmp, target); err != nil {
+		os.Remove(tmp)
+		return "", fmt.Errorf("保存文件失败: %w", err)
+	}
+	return target, nil
+}
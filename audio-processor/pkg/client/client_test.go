@@ -0,0 +1,189 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/internal/watcher"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/audio"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/export"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/models"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/webauth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUploadAndDownload(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/upload", func(w http.ResponseWriter, r *http.Request) {
+		file, header, err := r.FormFile("file")
+		require.NoError(t, err)
+		data, _ := io.ReadAll(file)
+		assert.Equal(t, "demo.mp3", header.Filename)
+		assert.Equal(t, "audio", string(data))
+		json.NewEncoder(w).Encode(audio.WebResult{Success: true, Manifest: &export.OutputManifest{Name: "demo"}})
+	})
+	mux.HandleFunc("/api/outputs/demo/txt", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("hello"))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	c := New(server.URL + "/")
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "demo.mp3")
+	require.NoError(t, os.WriteFile(path, []byte("audio"), 0644))
+	result, err := c.Upload(context.Background(), path)
+	require.NoError(t, err)
+	assert.True(t, result.Success)
+	assert.Equal(t, "demo", result.Manifest.Name)
+
+	// 下载时核对校验和
+	entry := export.OutputEntry{Type: "txt", Path: "demo.txt", SHA256: "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"}
+	saved, err := c.Download(context.Background(), "demo", entry, filepath.Join(dir, "out"))
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "out", "demo.txt"), saved)
+
+	entry.SHA256 = "0000"
+	_, err = c.Download(context.Background(), "demo", entry, filepath.Join(dir, "bad"))
+	assert.Error(t, err)
+	assert.NoFileExists(t, filepath.Join(dir, "bad", "demo.txt"))
+
+	// 服务器错误转换为 APIError
+	_, err = c.Manifest(context.Background(), "missing")
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+}
+
+func TestEnqueueAndWait(t *testing.T) {
+	previous := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	polls := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/enqueue", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(map[string]string{"path": "/media/demo.mp4", "status": "waiting"})
+	})
+	mux.HandleFunc("/api/queue", func(w http.ResponseWriter, r *http.Request) {
+		polls++
+		// 上一次的结果一直保留在最近记录中，第三次查询时本次处理结束
+		snapshot := watcher.QueueSnapshot{Recent: []watcher.QueueEntry{
+			{Path: "/media/demo.mp4", State: watcher.QueueFailed, FinishedAt: previous},
+		}}
+		if polls >= 3 {
+			snapshot.Recent = append([]watcher.QueueEntry{
+				{Path: "/media/demo.mp4", State: watcher.QueueCompleted, FinishedAt: previous.Add(time.Hour)},
+			}, snapshot.Recent...)
+		}
+		json.NewEncoder(w).Encode(snapshot)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	c := New(server.URL)
+
+	before, err := c.Queue(context.Background())
+	require.NoError(t, err)
+	path, err := c.Enqueue(context.Background(), "demo.mp4")
+	require.NoError(t, err)
+	assert.Equal(t, "/media/demo.mp4", path)
+	assert.Equal(t, previous, LastFinished(before, path))
+
+	entry, err := c.Wait(context.Background(), path, LastFinished(before, path), time.Millisecond)
+	require.NoError(t, err)
+	assert.Equal(t, watcher.QueueCompleted, entry.State)
+	assert.Equal(t, 3, polls)
+}
+
+func TestProcessNow(t *testing.T) {
+	var paths []string
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		assert.JSONEq(t, `{"action":"process_now"}`, string(body))
+		paths = append(paths, r.URL.Path)
+		json.NewEncoder(w).Encode(watcher.ScheduleStatus{Schedule: []string{"22:00-07:00"}, Released: 2})
+	})
+	server := httptest.NewServer(handler)
+	defer server.Close()
+	c := New(server.URL)
+
+	status, err := c.ProcessNow(context.Background(), "")
+	require.NoError(t, err)
+	assert.Equal(t, 2, status.Released)
+	_, err = c.ProcessNow(context.Background(), "podcasts")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/api/schedule", "/api/podcasts/schedule"}, paths)
+}
+
+func TestToken(t *testing.T) {
+	config := models.NewDefaultConfig()
+	config.WebAuthMode = webauth.ModeAPIKey
+	config.WebAPIKeys = map[string]string{"key-alice": "alice"}
+	auth, err := webauth.FromConfig(config)
+	require.NoError(t, err)
+
+	var user string
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user = webauth.User(r.Context())
+		json.NewEncoder(w).Encode(export.OutputManifest{Name: "demo"})
+	})
+	server := httptest.NewServer(auth.Middleware(handler))
+	defer server.Close()
+	c := New(server.URL)
+
+	// 未设置凭据时服务器返回 401
+	_, err = c.Manifest(context.Background(), "demo")
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusUnauthorized, apiErr.StatusCode)
+
+	c.Token = "key-alice"
+	manifest, err := c.Manifest(context.Background(), "demo")
+	require.NoError(t, err)
+	assert.Equal(t, "demo", manifest.Name)
+	assert.Equal(t, "alice", user)
+}
+
+func TestSubmitAndWaitJob(t *testing.T) {
+	polls := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/jobs", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		_, header, err := r.FormFile("file")
+		require.NoError(t, err)
+		assert.Equal(t, "demo.mp3", header.Filename)
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(audio.WebJob{ID: "j1", Status: audio.WebJobQueued})
+	})
+	mux.HandleFunc("/api/jobs/j1", func(w http.ResponseWriter, r *http.Request) {
+		polls++
+		job := audio.WebJob{ID: "j1", Status: audio.WebJobRunning}
+		if polls >= 2 {
+			job.Status = audio.WebJobCompleted
+			job.Result = &audio.WebResult{Success: true, Manifest: &export.OutputManifest{Name: "demo"}}
+		}
+		json.NewEncoder(w).Encode(job)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	c := New(server.URL)
+
+	path := filepath.Join(t.TempDir(), "demo.mp3")
+	require.NoError(t, os.WriteFile(path, []byte("audio"), 0644))
+	job, err := c.Submit(context.Background(), path)
+	require.NoError(t, err)
+	assert.Equal(t, "j1", job.ID)
+	assert.Equal(t, audio.WebJobQueued, job.Status)
+
+	job, err = c.WaitJob(context.Background(), job.ID, 10*time.Millisecond)
+	require.NoError(t, err)
+	assert.Equal(t, 2, polls)
+	assert.Equal(t, audio.WebJobCompleted, job.Status)
+	assert.Equal(t, "demo", job.Result.Manifest.Name)
+}
@@ -0,0 +1,300 @@
+package diarize
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/models"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/utils"
+)
+
+// 说话人分离方式
+const (
+	ModeNone    = ""        // 不做说话人分离
+	ModeCommand = "command" // 调用外部命令（如 pyannote 脚本）
+	ModeHTTP    = "http"    // 调用 HTTP 服务
+)
+
+// 命令行参数中音频路径的占位符
+const audioPlaceholder = "{audio}"
+
+// Turn 一段连续的说话人发言
+type Turn struct {
+	Speaker string  `json:"speaker"`
+	Start   float64 `json:"start"` // 开始时间（秒）
+	End     float64 `json:"end"`   // 结束时间（秒）
+}
+
+// Diarizer 说话人分离接口，返回音频中每个说话人的发言时间段
+type Diarizer interface {
+	Diarize(ctx context.Context, audioPath string) ([]Turn, error)
+}
+
+// CommandDiarizer 通过外部命令进行说话人分离。
+// 命令需将结果以 RTTM 或 JSON（Turn 数组）格式输出到标准输出。
+type CommandDiarizer struct {
+	Command string   // 可执行文件
+	Args    []string // 参数，其中的 {audio} 会被替换为音频路径；没有占位符时音频路径追加在末尾
+}
+
+// NewCommandDiarizer 根据命令行字符串创建外部命令分离器，如 "python diarize.py {audio}"
+func NewCommandDiarizer(commandLine string) (*CommandDiarizer, error) {
+	fields := strings.Fields(commandLine)
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("未设置说话人分离命令")
+	}
+	return &CommandDiarizer{Command: fields[0], Args: fields[1:]}, nil
+}
+
+// Diarize 执行外部命令并解析输出
+func (d *CommandDiarizer) Diarize(ctx context.Context, audioPath string) ([]Turn, error) {
+	args := make([]string, 0, len(d.Args)+1)
+	replaced := false
+	for _, arg := range d.Args {
+		if strings.Contains(arg, audioPlaceholder) {
+			arg = strings.ReplaceAll(arg, audioPlaceholder, audioPath)
+			replaced = true
+		}
+		args = append(args, arg)
+	}
+	if !replaced {
+		args = append(args, audioPath)
+	}
+
+	cmd := exec.CommandContext(ctx, d.Command, args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("说话人分离命令执行失败: %w, %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return ParseTurns(output)
+}
+
+// HTTPDiarizer 通过 HTTP 服务进行说话人分离。
+// 音频以 multipart 表单字段 file 上传，服务返回 Turn 数组或 {"turns": [...]}。
+type HTTPDiarizer struct {
+	URL    string
+	Client *http.Client
+}
+
+// NewHTTPDiarizer 创建 HTTP 分离器
+func NewHTTPDiarizer(url string) *HTTPDiarizer {
+	return &HTTPDiarizer{
+		URL:    url,
+		Client: &http.Client{Timeout: 30 * time.Minute},
+	}
+}
+
+// Diarize 上传音频并解析服务返回的结果
+func (d *HTTPDiarizer) Diarize(ctx context.Context, audioPath string) ([]Turn, error) {
+	file, err := os.Open(audioPath)
+	if err != nil {
+		return nil, fmt.Errorf("打开音频文件失败: %w", err)
+	}
+	defer file.Close()
+
+	// 流式上传，避免将整个音频读入内存
+	pr, pw := io.Pipe()
+	writer := multipart.NewWriter(pw)
+	go func() {
+		part, err := writer.CreateFormFile("file", filepath.Base(audioPath))
+		if err == nil {
+			_, err = io.Copy(part, file)
+		}
+		if err == nil {
+			err = writer.Close()
+		}
+		pw.CloseWithError(err)
+	}()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.URL, pr)
+	if err != nil {
+		return nil, fmt.Errorf("创建请求失败: %w", err)
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	resp, err := d.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("请求说话人分离服务失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("读取说话人分离结果失败: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("说话人分离服务返回错误 %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return ParseTurns(body)
+}
+
+// ParseTurns 解析说话人分离结果，支持 JSON（Turn 数组或 {"turns": [...]}）与 RTTM 格式
+func ParseTurns(data []byte) ([]Turn, error) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 {
+		return nil, nil
+	}
+
+	var turns []Turn
+	switch trimmed[0] {
+	case '[':
+		if err := json.Unmarshal(trimmed, &turns); err != nil {
+			return nil, fmt.Errorf("解析说话人分离结果失败: %w", err)
+		}
+	case '{':
+		var wrapped struct {
+			Turns []Turn `json:"turns"`
+		}
+		if err := json.Unmarshal(trimmed, &wrapped); err != nil {
+			return nil, fmt.Errorf("解析说话人分离结果失败: %w", err)
+		}
+		turns = wrapped.Turns
+	default:
+		var err error
+		if turns, err = parseRTTM(trimmed); err != nil {
+			return nil, err
+		}
+	}
+
+	sort.SliceStable(turns, func(i, j int) bool { return turns[i].Start < turns[j].Start })
+	return turns, nil
+}
+
+// parseRTTM 解析 RTTM 格式：SPEAKER <文件> <声道> <开始> <时长> <NA> <NA> <说话人> <NA> <NA>
+func parseRTTM(data []byte) ([]Turn, error) {
+	var turns []Turn
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	line := 0
+	for scanner.Scan() {
+		line++
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || fields[0] != "SPEAKER" {
+			continue
+		}
+		if len(fields) < 8 {
+			return nil, fmt.Errorf("RTTM 第 %d 行字段不足", line)
+		}
+		start, err := strconv.ParseFloat(fields[3], 64)
+		if err != nil {
+			return nil, fmt.Errorf("RTTM 第 %d 行开始时间无效: %w", line, err)
+		}
+		duration, err := strconv.ParseFloat(fields[4], 64)
+		if err != nil {
+			return nil, fmt.Errorf("RTTM 第 %d 行时长无效: %w", line, err)
+		}
+		turns = append(turns, Turn{Speaker: fields[7], Start: start, End: start + duration})
+	}
+	return turns, scanner.Err()
+}
+
+// Annotate 为每个段落标注与其时间重叠最多的说话人，返回新的段落列表。
+// 说话人按首次出现顺序重命名为"说话人1"、"说话人2"……
+func Annotate(segments []models.DataSegment, turns []Turn) []models.DataSegment {
+	labels := make(map[string]string)
+	annotated := make([]models.DataSegment, len(segments))
+	for i, segment := range segments {
+		annotated[i] = segment
+
+		speaker := dominantSpeaker(segment.StartTime, segment.EndTime, turns)
+		if speaker == "" {
+			continue
+		}
+		label, ok := labels[speaker]
+		if !ok {
+			label = fmt.Sprintf("说话人%d", len(labels)+1)
+			labels[speaker] = label
+		}
+		annotated[i].Speaker = label
+	}
+	return annotated
+}
+
+// dominantSpeaker 返回在 [start, end] 内发言时长最长的说话人，没有重叠时返回最近的说话人
+func dominantSpeaker(start, end float64, turns []Turn) string {
+	overlap := make(map[string]float64)
+	best := ""
+	for _, turn := range turns {
+		d := min(end, turn.End) - max(start, turn.Start)
+		if d <= 0 {
+			continue
+		}
+		overlap[turn.Speaker] += d
+		if best == "" || overlap[turn.Speaker] > overlap[best] {
+			best = turn.Speaker
+		}
+	}
+	if best != "" {
+		return best
+	}
+
+	// 段落落在发言间隙中时，取距离最近的发言
+	nearest := -1.0
+	mid := (start + end) / 2
+	for _, turn := range turns {
+		var distance float64
+		switch {
+		case mid < turn.Start:
+			distance = turn.Start - mid
+		case mid > turn.End:
+			distance = mid - turn.End
+		}
+		if nearest < 0 || distance < nearest {
+			nearest, best = distance, turn.Speaker
+		}
+	}
+	return best
+}
+
+// FromConfig 根据配置创建说话人分离器，未启用时返回 nil
+func FromConfig(config *models.Config) (Diarizer, error) {
+	switch config.Diarization {
+	case ModeNone:
+		return nil, nil
+	case ModeCommand:
+		return NewCommandDiarizer(config.DiarizationCommand)
+	case ModeHTTP:
+		if config.DiarizationURL == "" {
+			return nil, fmt.Errorf("未设置说话人分离服务地址")
+		}
+		return NewHTTPDiarizer(config.DiarizationURL), nil
+	default:
+		return nil, fmt.Errorf("未知的说话人分离方式: %s", config.Diarization)
+	}
+}
+
+// Apply 对音频执行说话人分离并标注段落；分离失败时记录警告并返回原段落
+func Apply(ctx context.Context, d Diarizer, segments []models.DataSegment, audioPath string) []models.DataSegment {
+	if d == nil || len(segments) == 0 {
+		return segments
+	}
+
+	utils.Info("开始说话人分离: %s", audioPath)
+	turns, err := d.Diarize(ctx, audioPath)
+	if err != nil {
+		utils.Warn("说话人分离失败，输出将不包含说话人信息: %v", err)
+		return segments
+	}
+	if len(turns) == 0 {
+		utils.Warn("说话人分离未返回任何发言: %s", audioPath)
+		return segments
+	}
+
+	annotated := Annotate(segments, turns)
+	utils.Info("说话人分离完成，共 %d 段发言", len(turns))
+	return annotated
+}
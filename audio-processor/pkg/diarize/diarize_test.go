@@ -0,0 +1,59 @@
+package diarize
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const sampleRTTM = `SPEAKER interview 1 0.00 4.50 <NA> <NA> SPEAKER_01 <NA> <NA>
+SPEAKER interview 1 4.50 3.00 <NA> <NA> SPEAKER_00 <NA> <NA>
+SPEAKER interview 1 8.00 2.00 <NA> <NA> SPEAKER_01 <NA> <NA>
+`
+
+func TestParseTurns(t *testing.T) {
+	turns, err := ParseTurns([]byte(sampleRTTM))
+	require.NoError(t, err)
+	require.Len(t, turns, 3)
+	assert.Equal(t, Turn{Speaker: "SPEAKER_00", Start: 4.5, End: 7.5}, turns[1])
+
+	turns, err = ParseTurns([]byte(`{"turns": [{"speaker": "B", "start": 2, "end": 3}, {"speaker": "A", "start": 0, "end": 2}]}`))
+	require.NoError(t, err)
+	assert.Equal(t, "A", turns[0].Speaker)
+}
+
+func TestAnnotate(t *testing.T) {
+	turns, err := ParseTurns([]byte(sampleRTTM))
+	require.NoError(t, err)
+
+	segments := []models.DataSegment{
+		{Text: "你好", StartTime: 0, EndTime: 4},
+		{Text: "欢迎", StartTime: 4, EndTime: 7}, // 与 SPEAKER_00 重叠更多
+		{Text: "谢谢", StartTime: 7.6, EndTime: 7.9},
+		{Text: "再见", StartTime: 8.5, EndTime: 9.5},
+	}
+	annotated := Annotate(segments, turns)
+
+	// 按首次出现顺序编号
+	assert.Equal(t, "说话人1", annotated[0].Speaker)
+	assert.Equal(t, "说话人2", annotated[1].Speaker)
+	assert.Equal(t, "说话人2", annotated[2].Speaker) // 落在间隙中，取最近的发言
+	assert.Equal(t, "说话人1", annotated[3].Speaker)
+	assert.Empty(t, segments[0].Speaker)
+}
+
+func TestCommandDiarizer(t *testing.T) {
+	audio := filepath.Join(t.TempDir(), "audio.rttm")
+	require.NoError(t, os.WriteFile(audio, []byte(sampleRTTM), 0644))
+
+	d, err := NewCommandDiarizer("cat {audio}")
+	require.NoError(t, err)
+	turns, err := d.Diarize(context.Background(), audio)
+	require.NoError(t, err)
+	assert.Len(t, turns, 3)
+}
@@ -0,0 +1,272 @@
+// Package digest 汇总一段时间内处理过的文件，由大模型生成包含主题与要点的汇总
+package digest
+
+import (
+	"encoding/base64"
+	"fmt"
+	"html"
+	"mime"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/export"
+)
+
+// 每个文件提交给大模型的文本上限（字符），避免长文稿占满上下文
+const maxItemChars = 2000
+
+// systemPrompt 生成汇总的系统提示
+const systemPrompt = "你是一个内容编辑。下面是一段时间内处理的多个音视频的摘要或文稿节选，" +
+	"请用 Markdown 写一份汇总：先用一段话概括整体内容，再按主题分组列出要点与亮点，" +
+	"引用具体内容时注明来自哪个文件（使用给出的文件名）。不要编造文件名或链接。"
+
+// Summarizer 生成汇总的大模型接口
+type Summarizer interface {
+	Chat(systemPrompt, content string) (string, error)
+}
+
+// Item 时间窗口内处理过的一个文件
+type Item struct {
+	Name      string            // 文件名（不含扩展名）
+	CreatedAt time.Time         // 输出生成时间
+	Summary   string            // 已有摘要，没有时为文稿节选
+	Links     map[string]string // 格式 -> 输出文件路径
+}
+
+// Digest 一份汇总
+type Digest struct {
+	Since   time.Time
+	Until   time.Time
+	Content string // 大模型生成的汇总（Markdown）
+	Items   []Item
+}
+
+// ParseSince 解析时间窗口，支持 "7d"、"2w" 以及 Go 的时长格式如 "36h"
+func ParseSince(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if len(value) > 1 {
+		unit := value[len(value)-1]
+		if n, err := strconv.Atoi(value[:len(value)-1]); err == nil && n > 0 {
+			switch unit {
+			case 'd':
+				return time.Duration(n) * 24 * time.Hour, nil
+			case 'w':
+				return time.Duration(n) * 7 * 24 * time.Hour, nil
+			}
+		}
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("无效的时间窗口 %q，示例: 7d、2w、36h", value)
+	}
+	return d, nil
+}
+
+// Collect 读取输出目录中 since 之后生成的输出清单，按生成时间排序。
+// 优先使用清单中的 summary 输出，否则使用文稿（txt）的开头部分
+func Collect(outputFolder, mediaFolder string, since time.Time) ([]Item, error) {
+	manifests, err := export.ListManifests(outputFolder)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []Item
+	for _, manifest := range manifests {
+		createdAt, err := time.ParseInLocation("2006-01-02 15:04:05", manifest.CreatedAt, time.Local)
+		if err != nil || createdAt.Before(since) {
+			continue
+		}
+
+		item := Item{Name: manifest.Name, CreatedAt: createdAt, Links: make(map[string]string)}
+		for _, entry := range manifest.Outputs {
+			item.Links[entry.Type] = entry.Resolve(outputFolder, mediaFolder)
+		}
+		for _, fileType := range []string{"summary", "txt"} {
+			if path, ok := item.Links[fileType]; ok {
+				if text, err := readText(path); err == nil && text != "" {
+					item.Summary = text
+					break
+				}
+			}
+		}
+		items = append(items, item)
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].CreatedAt.Before(items[j].CreatedAt) })
+	return items, nil
+}
+
+// readText 读取文本并去掉文稿开头的标题行（以 # 开头），超出上限时截断
+func readText(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	var lines []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	text := strings.TrimSpace(strings.Join(lines, "\n"))
+	if runes := []rune(text); len(runes) > maxItemChars {
+		text = string(runes[:maxItemChars]) + "……"
+	}
+	return text, nil
+}
+
+// Prompt 生成提交给大模型的内容
+func Prompt(items []Item) string {
+	var b strings.Builder
+	for i, item := range items {
+		fmt.Fprintf(&b, "## 文件 %d: %s（%s）\n\n%s\n\n", i+1, item.Name, item.CreatedAt.Format("2006-01-02"), item.Summary)
+	}
+	return b.String()
+}
+
+// Generate 收集 since 之后处理的文件并生成汇总，窗口内没有文件时返回错误
+func Generate(summarizer Summarizer, outputFolder, mediaFolder string, since time.Time) (*Digest, error) {
+	items, err := Collect(outputFolder, mediaFolder, since)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, fmt.Errorf("%s 之后没有处理过的文件", since.Format("2006-01-02 15:04"))
+	}
+
+	content, err := summarizer.Chat(systemPrompt, Prompt(items))
+	if err != nil {
+		return nil, fmt.Errorf("生成汇总失败: %w", err)
+	}
+	return &Digest{Since: since, Until: time.Now(), Content: strings.TrimSpace(content), Items: items}, nil
+}
+
+// title 汇总的标题
+func (d *Digest) title() string {
+	return fmt.Sprintf("内容汇总 %s ~ %s", d.Since.Format("2006-01-02"), d.Until.Format("2006-01-02"))
+}
+
+// link 返回文件链接：指定 baseDir 时使用相对路径，否则使用绝对路径
+func link(path, baseDir string) string {
+	if baseDir != "" {
+		if rel, err := filepath.Rel(baseDir, path); err == nil {
+			return filepath.ToSlash(rel)
+		}
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	return filepath.ToSlash(path)
+}
+
+// sortedTypes 返回按名称排序的输出格式
+func sortedTypes(links map[string]string) []string {
+	types := make([]string, 0, len(links))
+	for fileType := range links {
+		types = append(types, fileType)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// Markdown 以 Markdown 格式输出汇总，文件链接相对于 baseDir（为空时使用绝对路径）
+func (d *Digest) Markdown(baseDir string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n%s\n\n## 处理的文件（%d）\n\n", d.title(), d.Content, len(d.Items))
+	for _, item := range d.Items {
+		var links []string
+		for _, fileType := range sortedTypes(item.Links) {
+			links = append(links, fmt.Sprintf("[%s](<%s>)", fileType, link(item.Links[fileType], baseDir)))
+		}
+		fmt.Fprintf(&b, "- **%s**（%s） %s\n", item.Name, item.CreatedAt.Format("2006-01-02 15:04"), strings.Join(links, " · "))
+	}
+	return b.String()
+}
+
+// HTML 以独立 HTML 页面输出汇总，大模型生成的内容按段落显示
+func (d *Digest) HTML(baseDir string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "<!DOCTYPE html>\n<html lang=\"zh-CN\">\n<head>\n<meta charset=\"utf-8\">\n<title>%s</title>\n</head>\n<body>\n", html.EscapeString(d.title()))
+	fmt.Fprintf(&b, "<h1>%s</h1>\n", html.EscapeString(d.title()))
+	b.WriteString(markdownToHTML(d.Content))
+	fmt.Fprintf(&b, "<h2>处理的文件（%d）</h2>\n<ul>\n", len(d.Items))
+	for _, item := range d.Items {
+		fmt.Fprintf(&b, "<li><b>%s</b>（%s）", html.EscapeString(item.Name), item.CreatedAt.Format("2006-01-02 15:04"))
+		for _, fileType := range sortedTypes(item.Links) {
+			fmt.Fprintf(&b, ` <a href="%s">%s</a>`, html.EscapeString(link(item.Links[fileType], baseDir)), html.EscapeString(fileType))
+		}
+		b.WriteString("</li>\n")
+	}
+	b.WriteString("</ul>\n</body>\n</html>\n")
+	return b.String()
+}
+
+// markdownToHTML 将大模型生成的 Markdown 转换为简单的 HTML：标题、列表与段落，其余按原文显示
+func markdownToHTML(markdown string) string {
+	var b strings.Builder
+	inList := false
+	closeList := func() {
+		if inList {
+			b.WriteString("</ul>\n")
+			inList = false
+		}
+	}
+	for _, line := range strings.Split(markdown, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "":
+			closeList()
+		case strings.HasPrefix(line, "#"):
+			closeList()
+			text := strings.TrimLeft(line, "#")
+			level := min(len(line)-len(text)+1, 6) // 页面标题占用 h1
+			fmt.Fprintf(&b, "<h%d>%s</h%d>\n", level, html.EscapeString(strings.TrimSpace(text)), level)
+		case strings.HasPrefix(line, "- "), strings.HasPrefix(line, "* "):
+			if !inList {
+				b.WriteString("<ul>\n")
+				inList = true
+			}
+			fmt.Fprintf(&b, "<li>%s</li>\n", html.EscapeString(line[2:]))
+		default:
+			closeList()
+			fmt.Fprintf(&b, "<p>%s</p>\n", html.EscapeString(line))
+		}
+	}
+	closeList()
+	return b.String()
+}
+
+// Email 以 MIME 邮件（.eml）格式输出汇总，同时包含纯文本（Markdown）与 HTML 两种正文，
+// 文件链接使用绝对路径。from 或 to 为空时省略对应的邮件头
+func (d *Digest) Email(from, to string) string {
+	boundary := fmt.Sprintf("digest-%d", d.Until.UnixNano())
+	var b strings.Builder
+	if from != "" {
+		fmt.Fprintf(&b, "From: %s\r\n", from)
+	}
+	if to != "" {
+		fmt.Fprintf(&b, "To: %s\r\n", to)
+	}
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.BEncoding.Encode("UTF-8", d.title()))
+	fmt.Fprintf(&b, "Date: %s\r\n", d.Until.Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&b, "Content-Type: multipart/alternative; boundary=%q\r\n\r\n", boundary)
+	for _, part := range []struct{ contentType, body string }{
+		{"text/plain", d.Markdown("")},
+		{"text/html", d.HTML("")},
+	} {
+		fmt.Fprintf(&b, "--%s\r\nContent-Type: %s; charset=UTF-8\r\nContent-Transfer-Encoding: base64\r\n\r\n", boundary, part.contentType)
+		encoded := base64.StdEncoding.EncodeToString([]byte(part.body))
+		for len(encoded) > 76 {
+			b.WriteString(encoded[:76] + "\r\n")
+			encoded = encoded[76:]
+		}
+		b.WriteString(encoded + "\r\n")
+	}
+	fmt.Fprintf(&b, "--%s--\r\n", boundary)
+	return b.String()
+}
@@ -0,0 +1,76 @@
+package digest
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/export"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/models"
+)
+
+// fakeSummarizer 记录提交的内容并返回固定的汇总
+type fakeSummarizer struct {
+	content string
+}
+
+func (f *fakeSummarizer) Chat(systemPrompt, content string) (string, error) {
+	f.content = content
+	return "## 主题\n- 要点 <一>", nil
+}
+
+// TestParseSince 测试按天、周与 Go 时长格式解析时间窗口
+func TestParseSince(t *testing.T) {
+	for value, want := range map[string]time.Duration{
+		"7d":  7 * 24 * time.Hour,
+		"2w":  14 * 24 * time.Hour,
+		"36h": 36 * time.Hour,
+	} {
+		got, err := ParseSince(value)
+		require.NoError(t, err, value)
+		assert.Equal(t, want, got, value)
+	}
+	for _, value := range []string{"", "0d", "abc", "-3h"} {
+		_, err := ParseSince(value)
+		assert.Error(t, err, value)
+	}
+}
+
+// TestGenerate 测试只汇总窗口内的文件，并在各格式中链接到输出文件
+func TestGenerate(t *testing.T) {
+	root := t.TempDir()
+	config := models.NewDefaultConfig()
+	config.OutputFolder = filepath.Join(root, "output")
+	config.MediaFolder = filepath.Join(root, "media")
+
+	txt := filepath.Join(config.OutputFolder, "talk.txt")
+	require.NoError(t, os.MkdirAll(config.OutputFolder, 0755))
+	require.NoError(t, os.WriteFile(txt, []byte("# talk\n# 处理时间: x\n\n今天讨论了发布计划"), 0644))
+	_, err := export.WriteManifest(config, "talk.mp3", map[string]string{"txt": txt})
+	require.NoError(t, err)
+
+	summarizer := &fakeSummarizer{}
+	result, err := Generate(summarizer, config.OutputFolder, config.MediaFolder, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	require.Len(t, result.Items, 1)
+	assert.Contains(t, summarizer.content, "今天讨论了发布计划")
+	assert.NotContains(t, summarizer.content, "处理时间")
+
+	digestDir := filepath.Join(config.OutputFolder, "digests")
+	assert.Contains(t, result.Markdown(digestDir), "[txt](<../talk.txt>)")
+	html := result.HTML(digestDir)
+	assert.Contains(t, html, `<a href="../talk.txt">txt</a>`)
+	assert.Contains(t, html, "<h3>主题</h3>")
+	assert.Contains(t, html, "<li>要点 &lt;一&gt;</li>")
+	email := result.Email("a@example.com", "b@example.com")
+	assert.True(t, strings.HasPrefix(email, "From: a@example.com\r\nTo: b@example.com\r\nSubject: =?UTF-8?b?"))
+	assert.Contains(t, email, "Content-Type: text/html; charset=UTF-8")
+
+	_, err = Generate(summarizer, config.OutputFolder, config.MediaFolder, time.Now().Add(time.Hour))
+	assert.Error(t, err)
+}
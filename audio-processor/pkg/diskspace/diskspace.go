@@ -0,0 +1,128 @@
+// Package diskspace 检查临时目录与输出目录所在磁盘的可用空间，空间不足时暂停开始新的任务，
+// 避免提取或切分音频写到一半因磁盘写满而失败
+package diskspace
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/models"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/utils"
+)
+
+// DefaultInterval 空间不足时重新检查的间隔
+const DefaultInterval = 30 * time.Second
+
+// LowSpaceError 目录所在磁盘的可用空间低于要求
+type LowSpaceError struct {
+	Dir      string
+	Free     uint64
+	Required uint64 // 阈值加上任务预计写入的大小
+}
+
+func (e *LowSpaceError) Error() string {
+	return fmt.Sprintf("磁盘空间不足: %s 可用 %s，需要至少 %s",
+		e.Dir, utils.FormatFileSize(int64(e.Free)), utils.FormatFileSize(int64(e.Required)))
+}
+
+// Guard 检查若干目录所在磁盘的可用空间。nil Guard 不做任何检查
+type Guard struct {
+	Dirs     []string
+	MinFree  uint64        // 任务完成后至少保留的可用空间
+	Interval time.Duration // 空间不足时重新检查的间隔
+
+	free func(path string) (uint64, error) // 查询可用空间，测试时替换
+}
+
+// New 创建检查 dirs 的 Guard，minFree 为需要保留的可用字节数
+func New(minFree uint64, dirs ...string) *Guard {
+	return &Guard{
+		Dirs:     dirs,
+		MinFree:  minFree,
+		Interval: DefaultInterval,
+		free:     Free,
+	}
+}
+
+// FromConfig 按 min_free_space_mb 创建 Guard，未启用时返回 nil
+func FromConfig(config *models.Config, dirs ...string) *Guard {
+	if config == nil || config.MinFreeSpaceMB <= 0 {
+		return nil
+	}
+	return New(uint64(config.MinFreeSpaceMB)<<20, dirs...)
+}
+
+// Check 检查写入 need 字节后各目录是否仍保留 MinFree 的可用空间，不足时返回 *LowSpaceError。
+// 无法查询的目录（如尚未创建）不参与检查
+func (g *Guard) Check(need uint64) error {
+	if g == nil {
+		return nil
+	}
+	for _, dir := range g.Dirs {
+		free, err := g.free(existingParent(dir))
+		if err != nil {
+			utils.Debug("查询磁盘可用空间失败 %s: %v", dir, err)
+			continue
+		}
+		if required := g.MinFree + need; free < required {
+			return &LowSpaceError{Dir: dir, Free: free, Required: required}
+		}
+	}
+	return nil
+}
+
+// Wait 在可用空间足够写入 need 字节前阻塞，每隔 Interval 重新检查。
+// 首次发现空间不足时调用 onWait（可为 nil），ctx 取消时返回其错误
+func (g *Guard) Wait(ctx context.Context, need uint64, onWait func(err error)) error {
+	if g == nil {
+		return nil
+	}
+	interval := g.Interval
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+
+	waiting := false
+	for {
+		err := g.Check(need)
+		if err == nil {
+			if waiting {
+				utils.Info("磁盘空间已恢复，继续处理")
+			}
+			return nil
+		}
+		if !waiting {
+			waiting = true
+			utils.Warn("%v，暂停开始新的任务，每 %s 重新检查", err, interval)
+			if onWait != nil {
+				onWait(err)
+			}
+		}
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// existingParent 返回 path 自身或最近的已存在上级目录，用于查询尚未创建的目录所在的磁盘
+func existingParent(path string) string {
+	path = filepath.Clean(path)
+	for {
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			return path
+		}
+		path = parent
+	}
+}
@@ -0,0 +1,89 @@
+package diskspace
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/models"
+)
+
+// TestFree 测试查询临时目录与尚未创建的目录所在磁盘的可用空间
+func TestFree(t *testing.T) {
+	dir := t.TempDir()
+	free, err := Free(dir)
+	require.NoError(t, err)
+	assert.Greater(t, free, uint64(0))
+	assert.Equal(t, dir, existingParent(filepath.Join(dir, "a", "b")))
+}
+
+// TestCheck 测试可用空间低于阈值与预计写入大小之和时返回错误
+func TestCheck(t *testing.T) {
+	a, b := t.TempDir(), t.TempDir()
+	guard := New(100, a, b)
+	guard.free = func(path string) (uint64, error) {
+		if path == b {
+			return 150, nil
+		}
+		return 1000, nil
+	}
+
+	assert.NoError(t, guard.Check(0))
+	err := guard.Check(60)
+	var lowSpace *LowSpaceError
+	require.True(t, errors.As(err, &lowSpace))
+	assert.Equal(t, b, lowSpace.Dir)
+	assert.Equal(t, uint64(160), lowSpace.Required)
+
+	// 无法查询的目录不参与检查
+	guard.free = func(string) (uint64, error) { return 0, errors.New("not found") }
+	assert.NoError(t, guard.Check(1<<40))
+
+	var disabled *Guard
+	assert.NoError(t, disabled.Check(1<<40))
+	assert.NoError(t, disabled.Wait(context.Background(), 1<<40, nil))
+}
+
+// TestWait 测试空间不足时等待，释放后继续；取消时返回上下文错误
+func TestWait(t *testing.T) {
+	var available int64 = 50
+	guard := New(100, t.TempDir())
+	guard.Interval = 5 * time.Millisecond
+	guard.free = func(string) (uint64, error) { return uint64(atomic.LoadInt64(&available)), nil }
+
+	waited := 0
+	done := make(chan error, 1)
+	go func() { done <- guard.Wait(context.Background(), 0, func(error) { waited++ }) }()
+
+	time.Sleep(20 * time.Millisecond)
+	select {
+	case <-done:
+		t.Fatal("空间不足时不应返回")
+	default:
+	}
+	atomic.StoreInt64(&available, 200)
+	require.NoError(t, <-done)
+	assert.Equal(t, 1, waited, "只在开始等待时通知一次")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, guard.Wait(ctx, 1000, nil), context.Canceled)
+}
+
+// TestFromConfig 测试阈值为 0 时不检查
+func TestFromConfig(t *testing.T) {
+	config := models.NewDefaultConfig()
+	config.MinFreeSpaceMB = 0
+	assert.Nil(t, FromConfig(config, "/tmp"))
+
+	config.MinFreeSpaceMB = 2
+	guard := FromConfig(config, "/tmp")
+	require.NotNil(t, guard)
+	assert.Equal(t, uint64(2<<20), guard.MinFree)
+}
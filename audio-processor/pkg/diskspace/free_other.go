@@ -0,0 +1,10 @@
+//go:build !unix && !windows
+
+package diskspace
+
+import "errors"
+
+// Free 当前平台不支持查询可用空间
+func Free(path string) (uint64, error) {
+	return 0, errors.New("当前平台不支持查询磁盘可用空间")
+}
@@ -0,0 +1,14 @@
+//go:build unix
+
+package diskspace
+
+import "syscall"
+
+// Free 返回 path 所在文件系统中当前用户可用的字节数
+func Free(path string) (uint64, error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return 0, err
+	}
+	return uint64(stat.Bavail) * uint64(stat.Bsize), nil
+}
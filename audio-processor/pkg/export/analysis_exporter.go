@@ -0,0 +1,44 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/analysis"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/audit"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/utils"
+)
+
+// AnalysisExporter 将关键词、命名实体与主题标签导出为JSON，批处理结束后汇总到关键词索引
+type AnalysisExporter struct {
+	OutputFolder string
+	Namer        *Namer // 输出命名规则，为 nil 时使用默认规则
+}
+
+// NewAnalysisExporter 创建一个新的分析结果导出器
+func NewAnalysisExporter(outputFolder string) *AnalysisExporter {
+	return &AnalysisExporter{
+		OutputFolder: outputFolder,
+	}
+}
+
+// ExportAnalysis 导出分析结果，文件名为 <文件名>_analysis.json
+func (e *AnalysisExporter) ExportAnalysis(result *analysis.Result, filename string) (string, error) {
+	outputFile, err := e.Namer.Path(e.OutputFolder, filename, nil, "json", "_analysis")
+	if err != nil {
+		return "", err
+	}
+
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("编码分析结果失败: %w", err)
+	}
+	audit.RecordOverwrite(outputFile, "export", "重新生成关键词")
+	if err := os.WriteFile(outputFile, data, 0644); err != nil {
+		return "", fmt.Errorf("写入分析结果失败: %w", err)
+	}
+
+	utils.Info("已导出关键词: %s", outputFile)
+	return outputFile, nil
+}
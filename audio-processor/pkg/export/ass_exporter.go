@@ -0,0 +1,225 @@
+package export
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/audit"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/models"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/utils"
+)
+
+// ASSStyle ASS字幕样式，颜色使用 #RRGGBB 格式
+type ASSStyle struct {
+	FontName       string
+	FontSize       int
+	PrimaryColor   string // 文字颜色
+	HighlightColor string // 卡拉OK模式下已读文字的颜色
+	OutlineColor   string // 描边颜色
+	Bold           bool
+	BorderStyle    int     // 1: 描边加阴影, 3: 不透明背景框
+	Outline        float64 // 描边宽度
+	Shadow         float64 // 阴影距离
+	Alignment      int     // 位置，按小键盘方位 1-9（2 为底部居中，8 为顶部居中）
+	MarginV        int     // 距画面上下边缘的距离
+}
+
+// 内置样式预设
+var assPresets = map[string]ASSStyle{
+	"default": {
+		FontName: "Microsoft YaHei", FontSize: 64,
+		PrimaryColor: "#FFFFFF", HighlightColor: "#FFD700", OutlineColor: "#000000",
+		BorderStyle: 1, Outline: 3, Shadow: 1, Alignment: 2, MarginV: 60,
+	},
+	"large": {
+		FontName: "Microsoft YaHei", FontSize: 88, Bold: true,
+		PrimaryColor: "#FFFFFF", HighlightColor: "#FFD700", OutlineColor: "#000000",
+		BorderStyle: 1, Outline: 4, Shadow: 2, Alignment: 2, MarginV: 80,
+	},
+	"top": {
+		FontName: "Microsoft YaHei", FontSize: 56,
+		PrimaryColor: "#FFFFFF", HighlightColor: "#00E5FF", OutlineColor: "#000000",
+		BorderStyle: 1, Outline: 3, Shadow: 1, Alignment: 8, MarginV: 40,
+	},
+	"boxed": {
+		FontName: "Microsoft YaHei", FontSize: 60,
+		PrimaryColor: "#FFFFFF", HighlightColor: "#FFD700", OutlineColor: "#000000",
+		BorderStyle: 3, Outline: 8, Shadow: 0, Alignment: 2, MarginV: 60,
+	},
+}
+
+// ASSPreset 返回内置样式预设
+func ASSPreset(name string) (ASSStyle, bool) {
+	style, ok := assPresets[name]
+	return style, ok
+}
+
+// ASSStyleFromConfig 以配置中的预设为基础，应用单独设置的字体、颜色与位置
+func ASSStyleFromConfig(config *models.Config) ASSStyle {
+	style, ok := ASSPreset(config.ASSPreset)
+	if !ok {
+		utils.Warn("未知的ASS样式预设 %s，使用 default", config.ASSPreset)
+		style = assPresets["default"]
+	}
+	if config.ASSFontName != "" {
+		style.FontName = config.ASSFontName
+	}
+	if config.ASSFontSize > 0 {
+		style.FontSize = config.ASSFontSize
+	}
+	if config.ASSPrimaryColor != "" {
+		style.PrimaryColor = config.ASSPrimaryColor
+	}
+	if config.ASSHighlightColor != "" {
+		style.HighlightColor = config.ASSHighlightColor
+	}
+	if config.ASSAlignment > 0 {
+		style.Alignment = config.ASSAlignment
+	}
+	return style
+}
+
+// ASSExporter 负责将ASR结果导出为ASS字幕文件。
+// 启用卡拉OK且段落带有逐词时间戳时，逐词高亮显示。
+type ASSExporter struct {
+	OutputFolder string
+	Namer        *Namer // 输出命名规则，为 nil 时使用默认规则
+	Style        ASSStyle
+	Karaoke      bool
+}
+
+// NewASSExporter 创建一个使用默认样式的ASS导出器
+func NewASSExporter(outputFolder string) *ASSExporter {
+	return &ASSExporter{
+		OutputFolder: outputFolder,
+		Style:        assPresets["default"],
+		Karaoke:      true,
+	}
+}
+
+// FormatASSTime 将秒数格式化为ASS时间格式 (H:MM:SS.cc)
+func (e *ASSExporter) FormatASSTime(seconds float64) string {
+	if seconds < 0 {
+		seconds = 0
+	}
+	centis := int(seconds*100 + 0.5)
+	return fmt.Sprintf("%d:%02d:%02d.%02d", centis/360000, centis/6000%60, centis/100%60, centis%100)
+}
+
+// GenerateASSContent 生成ASS格式内容
+func (e *ASSExporter) GenerateASSContent(segments []models.DataSegment) string {
+	var b strings.Builder
+
+	b.WriteString("[Script Info]\n")
+	b.WriteString("ScriptType: v4.00+\n")
+	b.WriteString("PlayResX: 1920\n")
+	b.WriteString("PlayResY: 1080\n")
+	b.WriteString("WrapStyle: 0\n")
+	b.WriteString("ScaledBorderAndShadow: yes\n\n")
+
+	// 卡拉OK样式中 SecondaryColour 为未读文字颜色，PrimaryColour 为已读文字颜色
+	b.WriteString("[V4+ Styles]\n")
+	b.WriteString("Format: Name, Fontname, Fontsize, PrimaryColour, SecondaryColour, OutlineColour, BackColour, " +
+		"Bold, Italic, Underline, StrikeOut, ScaleX, ScaleY, Spacing, Angle, BorderStyle, Outline, Shadow, " +
+		"Alignment, MarginL, MarginR, MarginV, Encoding\n")
+	b.WriteString(e.styleLine("Default", e.Style.PrimaryColor, e.Style.HighlightColor))
+	b.WriteString(e.styleLine("Karaoke", e.Style.HighlightColor, e.Style.PrimaryColor))
+	b.WriteString("\n")
+
+	b.WriteString("[Events]\n")
+	b.WriteString("Format: Layer, Start, End, Style, Name, MarginL, MarginR, MarginV, Effect, Text\n")
+	for _, segment := range segments {
+		text := strings.TrimSpace(segment.Text)
+		if text == "" || text == "[无法识别的音频片段]" {
+			continue
+		}
+
+		startTime := segment.StartTime
+		endTime := segment.EndTime
+		if endTime <= startTime {
+			endTime = startTime + 5.0
+		}
+
+		style := "Default"
+		if e.Karaoke && segment.HasWordTimings() {
+			style = "Karaoke"
+			text = karaokeText(segment, startTime)
+		} else {
+			text = escapeASSText(text)
+		}
+
+		fmt.Fprintf(&b, "Dialogue: 0,%s,%s,%s,%s,0,0,0,,%s\n",
+			e.FormatASSTime(startTime), e.FormatASSTime(endTime), style,
+			strings.ReplaceAll(segment.Speaker, ",", "，"), text)
+	}
+
+	return b.String()
+}
+
+// styleLine 生成一行样式定义
+func (e *ASSExporter) styleLine(name, primary, secondary string) string {
+	bold := 0
+	if e.Style.Bold {
+		bold = -1
+	}
+	return fmt.Sprintf("Style: %s,%s,%d,%s,%s,%s,&H80000000,%d,0,0,0,100,100,0,0,%d,%g,%g,%d,40,40,%d,1\n",
+		name, e.Style.FontName, e.Style.FontSize,
+		assColor(primary), assColor(secondary), assColor(e.Style.OutlineColor),
+		bold, e.Style.BorderStyle, e.Style.Outline, e.Style.Shadow, e.Style.Alignment, e.Style.MarginV)
+}
+
+// karaokeText 生成逐词高亮的文本，词之间的停顿用空的 \k 标签占位
+func karaokeText(segment models.DataSegment, start float64) string {
+	var b strings.Builder
+	cursor := start
+	for _, word := range segment.Words {
+		if gap := centiseconds(word.StartTime - cursor); gap > 0 {
+			fmt.Fprintf(&b, "{\\k%d}", gap)
+		}
+		fmt.Fprintf(&b, "{\\kf%d}%s", centiseconds(word.EndTime-word.StartTime), escapeASSText(word.Text))
+		cursor = word.EndTime
+	}
+	return b.String()
+}
+
+// centiseconds 将秒数转换为百分之一秒，负数按0处理
+func centiseconds(seconds float64) int {
+	if seconds <= 0 {
+		return 0
+	}
+	return int(seconds*100 + 0.5)
+}
+
+// escapeASSText 转义文本中的换行与样式标签符号
+func escapeASSText(text string) string {
+	return strings.NewReplacer("\r\n", "\\N", "\n", "\\N", "{", "｛", "}", "｝").Replace(text)
+}
+
+// assColor 将 #RRGGBB 转换为ASS的 &HAABBGGRR 格式，格式不正确时返回白色
+func assColor(color string) string {
+	color = strings.TrimPrefix(color, "#")
+	if len(color) != 6 {
+		return "&H00FFFFFF"
+	}
+	return "&H00" + strings.ToUpper(color[4:6]+color[2:4]+color[0:2])
+}
+
+// ExportASS 导出ASS格式字幕文件
+func (e *ASSExporter) ExportASS(segments []models.DataSegment, filename string, partNum *int) (string, error) {
+	// 按命名模板确定输出路径
+	outputFile, err := e.Namer.Path(e.OutputFolder, filename, partNum, "ass", "")
+	if err != nil {
+		return "", err
+	}
+
+	// 写入文件，带BOM便于部分播放器识别UTF-8编码
+	audit.RecordOverwrite(outputFile, "export", "重新生成ASS字幕")
+	content := "\ufeff" + e.GenerateASSContent(segments)
+	if err := os.WriteFile(outputFile, []byte(content), 0644); err != nil {
+		return "", fmt.Errorf("写入ASS文件失败: %w", err)
+	}
+
+	utils.Info("已导出ASS字幕: %s", outputFile)
+	return outputFile, nil
+}
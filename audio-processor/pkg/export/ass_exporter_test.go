@@ -0,0 +1,52 @@
+package export
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateASSContent(t *testing.T) {
+	exporter := NewASSExporter(t.TempDir())
+
+	segments := []models.DataSegment{
+		{Text: "第一行\n{注释}", StartTime: 1.5, EndTime: 3, Speaker: "A"},
+		{
+			Text:      "你好",
+			StartTime: 65.2,
+			EndTime:   66.2,
+			Words: []models.WordTiming{
+				{Text: "你", StartTime: 65.4, EndTime: 65.7},
+				{Text: "好", StartTime: 65.7, EndTime: 66.2},
+			},
+		},
+		{Text: "  ", StartTime: 70, EndTime: 71},
+	}
+
+	content := exporter.GenerateASSContent(segments)
+
+	assert.Contains(t, content, "Style: Default,Microsoft YaHei,64,&H00FFFFFF,&H0000D7FF,&H00000000,")
+	assert.Contains(t, content, "Style: Karaoke,Microsoft YaHei,64,&H0000D7FF,&H00FFFFFF,")
+	assert.Contains(t, content, "Dialogue: 0,0:00:01.50,0:00:03.00,Default,A,0,0,0,,第一行\\N｛注释｝\n")
+	assert.Contains(t, content, "Dialogue: 0,0:01:05.20,0:01:06.20,Karaoke,,0,0,0,,{\\k20}{\\kf30}你{\\kf50}好\n")
+	assert.Equal(t, 2, strings.Count(content, "Dialogue:"))
+
+	// 关闭卡拉OK时输出普通文本
+	exporter.Karaoke = false
+	assert.Contains(t, exporter.GenerateASSContent(segments), ",Default,,0,0,0,,你好\n")
+}
+
+func TestASSStyleFromConfig(t *testing.T) {
+	config := models.NewDefaultConfig()
+	config.ASSPreset = "top"
+	config.ASSFontSize = 48
+	config.ASSPrimaryColor = "#112233"
+
+	style := ASSStyleFromConfig(config)
+
+	assert.Equal(t, 8, style.Alignment)
+	assert.Equal(t, 48, style.FontSize)
+	assert.Equal(t, "&H00332211", assColor(style.PrimaryColor))
+}
@@ -0,0 +1,42 @@
+package export
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/audit"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/chapters"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/utils"
+)
+
+// ChaptersExporter 将章节导出为YouTube视频描述格式的文本，可直接粘贴到视频简介中
+type ChaptersExporter struct {
+	OutputFolder string
+	Namer        *Namer // 输出命名规则，为 nil 时使用默认规则
+}
+
+// NewChaptersExporter 创建一个新的章节导出器
+func NewChaptersExporter(outputFolder string) *ChaptersExporter {
+	return &ChaptersExporter{
+		OutputFolder: outputFolder,
+	}
+}
+
+// ExportChapters 导出章节，文件名为 <文件名>_chapters.txt
+func (e *ChaptersExporter) ExportChapters(list []chapters.Chapter, filename string) (string, error) {
+	outputFile, err := e.Namer.Path(e.OutputFolder, filename, nil, "txt", "_chapters")
+	if err != nil {
+		return "", err
+	}
+
+	if len(list) < 3 {
+		utils.Warn("只划分出 %d 个章节，YouTube 需要至少 3 个章节才会显示: %s", len(list), filename)
+	}
+	audit.RecordOverwrite(outputFile, "export", "重新生成章节")
+	if err := os.WriteFile(outputFile, []byte(chapters.FormatYouTube(list)), 0644); err != nil {
+		return "", fmt.Errorf("写入章节文件失败: %w", err)
+	}
+
+	utils.Info("已导出章节: %s", outputFile)
+	return outputFile, nil
+}
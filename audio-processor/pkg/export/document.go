@@ -0,0 +1,64 @@
+package export
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/models"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/utils"
+)
+
+// DocumentOptions 文档（DOCX、PDF）导出选项
+type DocumentOptions struct {
+	IncludeTimestamps bool // 段落前标注时间范围
+	IncludeSpeakers   bool // 段落前标注说话人
+}
+
+// Transcript 排版用的文稿：标题、副标题与按段落组织的文本
+type Transcript struct {
+	Title      string
+	Subtitle   string
+	Paragraphs []TranscriptParagraph
+}
+
+// TranscriptParagraph 文稿中的一个段落，Time 与 Speaker 未启用时为空
+type TranscriptParagraph struct {
+	Time    string
+	Speaker string
+	Text    string
+}
+
+// BuildTranscript 将识别结果整理为文稿，跳过空段落与无法识别的片段
+func BuildTranscript(segments []models.DataSegment, filename string, partNum *int, opts DocumentOptions) Transcript {
+	baseName := filepath.Base(filename)
+	transcript := Transcript{
+		Title:    strings.TrimSuffix(baseName, filepath.Ext(baseName)),
+		Subtitle: "处理时间: " + time.Now().Format("2006-01-02 15:04:05"),
+	}
+	if partNum != nil {
+		transcript.Title += fmt.Sprintf(" - 第 %d 部分", *partNum)
+	}
+
+	for _, segment := range segments {
+		text := strings.TrimSpace(segment.Text)
+		if text == "" || text == "[无法识别的音频片段]" {
+			continue
+		}
+		paragraph := TranscriptParagraph{Text: text}
+		if opts.IncludeTimestamps {
+			paragraph.Time = fmt.Sprintf("[%s-%s]", utils.FormatTime(segment.StartTime), utils.FormatTime(segment.EndTime))
+		}
+		if opts.IncludeSpeakers {
+			paragraph.Speaker = segment.Speaker
+		}
+		transcript.Paragraphs = append(transcript.Paragraphs, paragraph)
+	}
+	return transcript
+}
+
+// splitLines 按换行拆分文本
+func splitLines(text string) []string {
+	return strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+}
@@ -0,0 +1,116 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var documentSegments = []models.DataSegment{
+	{Text: "大家好，欢迎收听。", StartTime: 0, EndTime: 4.2, Speaker: "A"},
+	{Text: "[无法识别的音频片段]", StartTime: 4.2, EndTime: 5},
+	{Text: "Tom & Jerry <live>", StartTime: 65, EndTime: 70, Speaker: "B"},
+}
+
+func TestBuildTranscript(t *testing.T) {
+	part := 2
+	transcript := BuildTranscript(documentSegments, "/media/demo.mp4", &part, DocumentOptions{IncludeTimestamps: true})
+
+	assert.Equal(t, "demo - 第 2 部分", transcript.Title)
+	require.Len(t, transcript.Paragraphs, 2)
+	assert.Equal(t, TranscriptParagraph{Time: "[00:00-00:04]", Text: "大家好，欢迎收听。"}, transcript.Paragraphs[0])
+	assert.Equal(t, "[01:05-01:10]", transcript.Paragraphs[1].Time)
+	assert.Empty(t, transcript.Paragraphs[1].Speaker)
+}
+
+func TestWriteDOCX(t *testing.T) {
+	exporter := NewDOCXExporter(t.TempDir())
+	transcript := BuildTranscript(documentSegments, "demo.mp4", nil, exporter.Options)
+
+	var buf bytes.Buffer
+	require.NoError(t, exporter.WriteDOCX(&buf, transcript))
+
+	reader, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	parts := map[string]string{}
+	for _, file := range reader.File {
+		rc, err := file.Open()
+		require.NoError(t, err)
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		require.NoError(t, err)
+		parts[file.Name] = string(data)
+	}
+
+	assert.Contains(t, parts, "[Content_Types].xml")
+	assert.Contains(t, parts, "word/styles.xml")
+	document := parts["word/document.xml"]
+	assert.Contains(t, document, `<w:t xml:space="preserve">demo</w:t>`)
+	assert.Contains(t, document, `<w:rPr><w:b/></w:rPr><w:t xml:space="preserve">B: </w:t>`)
+	assert.Contains(t, document, "Tom &amp; Jerry &lt;live&gt;")
+	assert.NotContains(t, document, "无法识别")
+}
+
+func TestWritePDF(t *testing.T) {
+	exporter := NewPDFExporter(t.TempDir())
+	transcript := BuildTranscript(documentSegments, "demo.mp4", nil, exporter.Options)
+	// 足够多的段落以产生分页
+	for i := 0; i < 60; i++ {
+		transcript.Paragraphs = append(transcript.Paragraphs, TranscriptParagraph{Text: strings.Repeat("长段落文本", 12)})
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, exporter.WritePDF(&buf, transcript))
+	pdf := buf.String()
+
+	assert.True(t, strings.HasPrefix(pdf, "%PDF-1.4"))
+	assert.True(t, strings.HasSuffix(pdf, "%%EOF\n"))
+	pages := regexp.MustCompile(`/Count (\d+)`).FindStringSubmatch(pdf)
+	require.NotNil(t, pages)
+	count, _ := strconv.Atoi(pages[1])
+	assert.Greater(t, count, 1)
+
+	// 交叉引用表中的偏移指向对应对象
+	startxref := regexp.MustCompile(`startxref\n(\d+)`).FindStringSubmatch(pdf)
+	require.NotNil(t, startxref)
+	xref, _ := strconv.Atoi(startxref[1])
+	require.True(t, strings.HasPrefix(pdf[xref:], "xref\n"))
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllStringSubmatch(pdf[xref:], -1)
+	require.Len(t, entries, 6+2*count)
+	for i, entry := range entries {
+		offset, _ := strconv.Atoi(entry[1])
+		assert.True(t, strings.HasPrefix(pdf[offset:], fmt.Sprintf("%d 0 obj", i+1)), "object %d", i+1)
+	}
+
+	// 第一页内容包含编码后的正文与灰色时间戳
+	start := strings.Index(pdf, "stream\n") + len("stream\n")
+	end := strings.Index(pdf[start:], "\nendstream")
+	zr, err := zlib.NewReader(strings.NewReader(pdf[start : start+end]))
+	require.NoError(t, err)
+	content, err := io.ReadAll(zr)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), strings.Trim(pdfHexString("大家好"), "<>"))
+	assert.Contains(t, string(content), "0.5 g "+pdfHexString("[00:00-00:04] "))
+}
+
+func TestWrapStyled(t *testing.T) {
+	lines := wrapStyled(styled("hello world again", 0), 4)
+	var texts []string
+	for _, line := range lines {
+		texts = append(texts, spansOf(line)[0].Text)
+	}
+	assert.Equal(t, []string{"hello", "world", "again"}, texts)
+
+	lines = wrapStyled(styled("一二三四五", 0), 2)
+	assert.Len(t, lines, 3)
+}
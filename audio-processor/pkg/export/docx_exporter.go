@@ -0,0 +1,157 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/audit"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/models"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/utils"
+)
+
+// DOCX 包中固定不变的部分
+const (
+	docxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/word/document.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.document.main+xml"/>` +
+		`<Override PartName="/word/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.styles+xml"/>` +
+		`</Types>`
+	docxRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="word/document.xml"/>` +
+		`</Relationships>`
+	docxDocumentRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>` +
+		`</Relationships>`
+	docxStyles = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:styles xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">` +
+		`<w:docDefaults><w:rPrDefault><w:rPr>` +
+		`<w:rFonts w:ascii="Calibri" w:hAnsi="Calibri" w:eastAsia="Microsoft YaHei"/><w:sz w:val="22"/>` +
+		`</w:rPr></w:rPrDefault><w:pPrDefault><w:pPr><w:spacing w:after="160" w:line="300" w:lineRule="auto"/></w:pPr></w:pPrDefault></w:docDefaults>` +
+		`<w:style w:type="paragraph" w:default="1" w:styleId="Normal"><w:name w:val="Normal"/></w:style>` +
+		`<w:style w:type="paragraph" w:styleId="Title"><w:name w:val="Title"/><w:basedOn w:val="Normal"/>` +
+		`<w:pPr><w:spacing w:after="80"/></w:pPr><w:rPr><w:b/><w:sz w:val="36"/></w:rPr></w:style>` +
+		`<w:style w:type="paragraph" w:styleId="Subtitle"><w:name w:val="Subtitle"/><w:basedOn w:val="Normal"/>` +
+		`<w:pPr><w:spacing w:after="320"/></w:pPr><w:rPr><w:color w:val="808080"/><w:sz w:val="20"/></w:rPr></w:style>` +
+		`</w:styles>`
+)
+
+// DOCXExporter 负责将ASR结果导出为Word文档，便于交给非技术人员阅读与编辑
+type DOCXExporter struct {
+	OutputFolder string
+	Namer        *Namer // 输出命名规则，为 nil 时使用默认规则
+	Options      DocumentOptions
+}
+
+// NewDOCXExporter 创建一个新的DOCX导出器
+func NewDOCXExporter(outputFolder string) *DOCXExporter {
+	return &DOCXExporter{
+		OutputFolder: outputFolder,
+		Options:      DocumentOptions{IncludeTimestamps: true, IncludeSpeakers: true},
+	}
+}
+
+// WriteDOCX 将文稿写为DOCX格式
+func (e *DOCXExporter) WriteDOCX(w io.Writer, transcript Transcript) error {
+	parts := []struct {
+		name    string
+		content string
+	}{
+		{"[Content_Types].xml", docxContentTypes},
+		{"_rels/.rels", docxRels},
+		{"word/_rels/document.xml.rels", docxDocumentRels},
+		{"word/styles.xml", docxStyles},
+		{"word/document.xml", e.GenerateDocumentXML(transcript)},
+	}
+
+	zw := zip.NewWriter(w)
+	for _, part := range parts {
+		fw, err := zw.Create(part.name)
+		if err != nil {
+			return fmt.Errorf("写入DOCX内容失败: %w", err)
+		}
+		if _, err := io.WriteString(fw, part.content); err != nil {
+			return fmt.Errorf("写入DOCX内容失败: %w", err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("写入DOCX内容失败: %w", err)
+	}
+	return nil
+}
+
+// GenerateDocumentXML 生成文档正文 word/document.xml：时间戳为灰色，说话人加粗
+func (e *DOCXExporter) GenerateDocumentXML(transcript Transcript) string {
+	var b bytes.Buffer
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n")
+	b.WriteString(`<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>`)
+
+	b.WriteString(`<w:p><w:pPr><w:pStyle w:val="Title"/></w:pPr>`)
+	writeDOCXRun(&b, transcript.Title, "")
+	b.WriteString(`</w:p>`)
+	b.WriteString(`<w:p><w:pPr><w:pStyle w:val="Subtitle"/></w:pPr>`)
+	writeDOCXRun(&b, transcript.Subtitle, "")
+	b.WriteString(`</w:p>`)
+
+	for _, paragraph := range transcript.Paragraphs {
+		b.WriteString(`<w:p>`)
+		if paragraph.Time != "" {
+			writeDOCXRun(&b, paragraph.Time+" ", `<w:color w:val="808080"/>`)
+		}
+		if paragraph.Speaker != "" {
+			writeDOCXRun(&b, paragraph.Speaker+": ", `<w:b/>`)
+		}
+		writeDOCXRun(&b, paragraph.Text, "")
+		b.WriteString(`</w:p>`)
+	}
+
+	b.WriteString(`<w:sectPr><w:pgSz w:w="11906" w:h="16838"/>` +
+		`<w:pgMar w:top="1440" w:right="1440" w:bottom="1440" w:left="1440" w:header="720" w:footer="720" w:gutter="0"/>` +
+		`</w:sectPr></w:body></w:document>`)
+	return b.String()
+}
+
+// writeDOCXRun 写入一段文本，换行转换为 <w:br/>
+func writeDOCXRun(b *bytes.Buffer, text, props string) {
+	b.WriteString(`<w:r>`)
+	if props != "" {
+		b.WriteString(`<w:rPr>` + props + `</w:rPr>`)
+	}
+	for i, line := range splitLines(text) {
+		if i > 0 {
+			b.WriteString(`<w:br/>`)
+		}
+		b.WriteString(`<w:t xml:space="preserve">`)
+		xml.EscapeText(b, []byte(line))
+		b.WriteString(`</w:t>`)
+	}
+	b.WriteString(`</w:r>`)
+}
+
+// ExportDOCX 导出DOCX格式文档
+func (e *DOCXExporter) ExportDOCX(segments []models.DataSegment, filename string, partNum *int) (string, error) {
+	outputFile, err := e.Namer.Path(e.OutputFolder, filename, partNum, "docx", "")
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := e.WriteDOCX(&buf, BuildTranscript(segments, filename, partNum, e.Options)); err != nil {
+		return "", err
+	}
+
+	audit.RecordOverwrite(outputFile, "export", "重新生成DOCX文档")
+	if err := os.WriteFile(outputFile, buf.Bytes(), 0644); err != nil {
+		return "", fmt.Errorf("写入DOCX文件失败: %w", err)
+	}
+
+	utils.Info("已导出DOCX文档: %s", outputFile)
+	return outputFile, nil
+}
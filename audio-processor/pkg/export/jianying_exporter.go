@@ -0,0 +1,189 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/audit"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/models"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/utils"
+)
+
+// 字幕导入剪辑软件的预设，对应配置项 subtitle_preset 与命令行 --preset
+const (
+	PresetJianying = "jianying" // 额外生成剪映草稿（只含字幕轨道）
+	PresetPremiere = "premiere" // SRT 使用 UTF-8 BOM 与 CRLF 换行，避免 Premiere 导入中文乱码
+)
+
+// JianyingExporter 将字幕导出为剪映草稿格式（draft_content.json）。
+// 草稿只包含一条文本轨道，复制到剪映草稿目录后即可打开，再导入视频素材对齐
+type JianyingExporter struct {
+	OutputFolder string
+	Namer        *Namer     // 输出命名规则，为 nil 时使用默认规则
+	Options      SRTOptions // 行长、行数与阅读速度限制，与SRT相同
+}
+
+// NewJianyingExporter 创建一个新的剪映草稿导出器
+func NewJianyingExporter(outputFolder string) *JianyingExporter {
+	return &JianyingExporter{
+		OutputFolder: outputFolder,
+	}
+}
+
+// 剪映草稿中用到的结构，省略的字段由剪映使用默认值
+type jianyingDraft struct {
+	ID           string            `json:"id"`
+	Version      int               `json:"version"`
+	NewVersion   string            `json:"new_version"`
+	Duration     int64             `json:"duration"` // 微秒
+	FPS          float64           `json:"fps"`
+	CanvasConfig jianyingCanvas    `json:"canvas_config"`
+	Materials    jianyingMaterials `json:"materials"`
+	Tracks       []jianyingTrack   `json:"tracks"`
+}
+
+type jianyingCanvas struct {
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+	Ratio  string `json:"ratio"`
+}
+
+type jianyingMaterials struct {
+	Texts []jianyingText `json:"texts"`
+}
+
+type jianyingText struct {
+	ID           string  `json:"id"`
+	Type         string  `json:"type"`
+	Content      string  `json:"content"` // 文本与样式，JSON字符串
+	FontSize     float64 `json:"font_size"`
+	TextColor    string  `json:"text_color"`
+	Alignment    int     `json:"alignment"`
+	LineMaxWidth float64 `json:"line_max_width"`
+	CheckFlag    int     `json:"check_flag"`
+}
+
+type jianyingTrack struct {
+	ID       string            `json:"id"`
+	Type     string            `json:"type"`
+	Segments []jianyingSegment `json:"segments"`
+}
+
+type jianyingSegment struct {
+	ID              string            `json:"id"`
+	MaterialID      string            `json:"material_id"`
+	TargetTimerange jianyingTimerange `json:"target_timerange"`
+	RenderIndex     int               `json:"render_index"`
+	Visible         bool              `json:"visible"`
+	Clip            jianyingClip      `json:"clip"`
+}
+
+type jianyingTimerange struct {
+	Start    int64 `json:"start"`
+	Duration int64 `json:"duration"`
+}
+
+type jianyingClip struct {
+	Alpha     float64       `json:"alpha"`
+	Scale     jianyingPoint `json:"scale"`
+	Transform jianyingPoint `json:"transform"`
+}
+
+type jianyingPoint struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// jianyingID 生成草稿内的ID。剪映只要求ID唯一，按序号生成使同样的输入得到同样的草稿
+func jianyingID(kind, index int) string {
+	return fmt.Sprintf("%08X-0000-4000-8000-%012X", kind, index)
+}
+
+// jianyingMicros 将秒数转换为剪映使用的微秒
+func jianyingMicros(seconds float64) int64 {
+	return int64(seconds*1e6 + 0.5)
+}
+
+// jianyingContent 生成文本素材的 content 字段：白色文字，样式覆盖全部文字
+func jianyingContent(text string) string {
+	content := map[string]interface{}{
+		"text": text,
+		"styles": []map[string]interface{}{{
+			"fill":  map[string]interface{}{"content": map[string]interface{}{"solid": map[string]interface{}{"color": []float64{1, 1, 1}}}},
+			"range": []int{0, utf8.RuneCountInString(text)},
+			"size":  8.0,
+		}},
+	}
+	data, _ := json.Marshal(content)
+	return string(data)
+}
+
+// GenerateJianyingContent 生成剪映草稿内容
+func (e *JianyingExporter) GenerateJianyingContent(segments []models.DataSegment) ([]byte, error) {
+	draft := jianyingDraft{
+		ID:           jianyingID(0, 0),
+		Version:      360000,
+		NewVersion:   "110.0.0",
+		FPS:          30,
+		CanvasConfig: jianyingCanvas{Width: 1920, Height: 1080, Ratio: "original"},
+		Materials:    jianyingMaterials{Texts: []jianyingText{}},
+	}
+	track := jianyingTrack{ID: jianyingID(1, 0), Type: "text", Segments: []jianyingSegment{}}
+
+	for i, cue := range e.Options.buildCues(segments) {
+		material := jianyingText{
+			ID:           jianyingID(2, i+1),
+			Type:         "subtitle",
+			Content:      jianyingContent(strings.Join(cue.Lines, "\n")),
+			FontSize:     8,
+			TextColor:    "#FFFFFF",
+			Alignment:    1,
+			LineMaxWidth: 0.82,
+			CheckFlag:    7,
+		}
+		start := jianyingMicros(cue.Start)
+		end := jianyingMicros(cue.End)
+		draft.Materials.Texts = append(draft.Materials.Texts, material)
+		track.Segments = append(track.Segments, jianyingSegment{
+			ID:              jianyingID(3, i+1),
+			MaterialID:      material.ID,
+			TargetTimerange: jianyingTimerange{Start: start, Duration: end - start},
+			RenderIndex:     14000,
+			Visible:         true,
+			// 字幕位于画面底部
+			Clip: jianyingClip{Alpha: 1, Scale: jianyingPoint{X: 1, Y: 1}, Transform: jianyingPoint{X: 0, Y: -0.73}},
+		})
+		draft.Duration = max(draft.Duration, end)
+	}
+	draft.Tracks = []jianyingTrack{track}
+
+	data, err := json.MarshalIndent(draft, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("编码剪映草稿失败: %w", err)
+	}
+	return data, nil
+}
+
+// ExportJianying 导出剪映草稿，文件名为 <文件名>_jianying.json
+func (e *JianyingExporter) ExportJianying(segments []models.DataSegment, filename string, partNum *int) (string, error) {
+	// 按命名模板确定输出路径
+	outputFile, err := e.Namer.Path(e.OutputFolder, filename, partNum, "json", "_jianying")
+	if err != nil {
+		return "", err
+	}
+
+	content, err := e.GenerateJianyingContent(segments)
+	if err != nil {
+		return "", err
+	}
+	audit.RecordOverwrite(outputFile, "export", "重新生成剪映草稿")
+	if err := os.WriteFile(outputFile, content, 0644); err != nil {
+		return "", fmt.Errorf("写入剪映草稿失败: %w", err)
+	}
+
+	utils.Info("已导出剪映草稿: %s", outputFile)
+	return outputFile, nil
+}
@@ -0,0 +1,54 @@
+package export
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateJianyingContent(t *testing.T) {
+	exporter := NewJianyingExporter(t.TempDir())
+	segments := []models.DataSegment{
+		{Text: "你好", StartTime: 1, EndTime: 2.5},
+		{Text: "世界", StartTime: 3, EndTime: 4, Speaker: "B"},
+	}
+
+	data, err := exporter.GenerateJianyingContent(segments)
+	require.NoError(t, err)
+
+	var draft jianyingDraft
+	require.NoError(t, json.Unmarshal(data, &draft))
+	assert.Equal(t, int64(4000000), draft.Duration)
+	require.Len(t, draft.Tracks, 1)
+	require.Len(t, draft.Tracks[0].Segments, 2)
+	require.Len(t, draft.Materials.Texts, 2)
+
+	segment := draft.Tracks[0].Segments[1]
+	assert.Equal(t, jianyingTimerange{Start: 3000000, Duration: 1000000}, segment.TargetTimerange)
+	assert.Equal(t, draft.Materials.Texts[1].ID, segment.MaterialID)
+
+	var content struct {
+		Text   string `json:"text"`
+		Styles []struct {
+			Range []int `json:"range"`
+		} `json:"styles"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(draft.Materials.Texts[1].Content), &content))
+	assert.Equal(t, "B: 世界", content.Text)
+	assert.Equal(t, []int{0, 5}, content.Styles[0].Range)
+}
+
+func TestExportSRTPremierePreset(t *testing.T) {
+	exporter := NewSRTExporter(t.TempDir())
+	exporter.ApplyPreset(PresetPremiere)
+
+	path, err := exporter.ExportSRT([]models.DataSegment{{Text: "你好", StartTime: 0, EndTime: 1}}, "demo.mp3", nil)
+	require.NoError(t, err)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "\ufeff1\r\n00:00:00,000 --> 00:00:01,000\r\n你好\r\n", string(data))
+}
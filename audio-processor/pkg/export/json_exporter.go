@@ -4,31 +4,56 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
-	"path/filepath"
 	"strings"
 
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/analysis"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/audit"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/chapters"
 	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/models"
 	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/utils"
 )
 
+// JSONSchemaVersion JSON导出的格式版本，字段有不兼容的变化时递增。
+// 版本 1 为早期的 *_json.txt 文件（没有 schema_version 字段），版本 2 起使用 .json 扩展名并为每段标注语言
+const JSONSchemaVersion = 2
+
 // TranscriptSegment 表示字幕的一个片段
 type TranscriptSegment struct {
     Start float64 `json:"start"`  // 开始时间（秒）
     End   float64 `json:"end"`    // 结束时间（秒）
     Text  string  `json:"text"`   // 该段文字
+    Speaker    string           `json:"speaker,omitempty"`    // 说话人标签
+    Confidence float64          `json:"confidence,omitempty"` // 置信度（0-1）
+    Language   string           `json:"language,omitempty"`   // 该段语言，版本 2 起
+    Words      []TranscriptWord `json:"words,omitempty"`      // 逐词时间戳
+}
+
+// TranscriptWord 表示片段中单个词的时间信息
+type TranscriptWord struct {
+    Start      float64 `json:"start"`                // 开始时间（秒）
+    End        float64 `json:"end"`                  // 结束时间（秒）
+    Text       string  `json:"text"`                 // 词文本
+    Confidence float64 `json:"confidence,omitempty"` // 置信度（0-1）
 }
 
 // TranscriptResult 表示整个转录结果
 type TranscriptResult struct {
+    SchemaVersion int              `json:"schema_version"`     // 格式版本，见 JSONSchemaVersion
     Language string              `json:"language,omitempty"` // 检测语言（如 "zh"、"en"）
     FullText string              `json:"full_text"`          // 完整合并后的文本（用于摘要）
     Segments []TranscriptSegment `json:"segments"`           // 分段结构，适合前端显示时间轴字幕等
+    Chapters []chapters.Chapter  `json:"chapters,omitempty"` // 章节，启用 export_chapters 时写入
+    Analysis *analysis.Result    `json:"analysis,omitempty"` // 关键词、命名实体与主题标签，启用 export_analysis 时写入
     Raw      interface{}         `json:"raw,omitempty"`      // 原始响应数据，便于调试或平台特性处理
 }
 
 // JSONExporter 负责将ASR结果导出为JSON文件
 type JSONExporter struct {
     OutputFolder string
+    Namer        *Namer // 输出命名规则，为 nil 时使用默认规则
+    Language     string // 音频语言，为空时不写入结果
+    Chapters     []chapters.Chapter // 章节，为空时不写入结果
+    Analysis     *analysis.Result   // 关键词与实体，为 nil 时不写入结果
 }
 
 // NewJSONExporter 创建一个新的JSON导出器
@@ -42,8 +67,11 @@ func NewJSONExporter(outputFolder string) *JSONExporter {
 func (e *JSONExporter) GenerateJSONContent(segments []models.DataSegment) TranscriptResult {
     // 创建TranscriptResult
     result := TranscriptResult{
-        Language: "zh", // 默认为自动检测，实际应用中应该从识别结果中获取
+        SchemaVersion: JSONSchemaVersion,
+        Language: e.Language,
         Segments: make([]TranscriptSegment, 0),
+        Chapters: e.Chapters,
+        Analysis: e.Analysis,
     }
 
     // 构建完整文本和分段
@@ -68,11 +96,23 @@ func (e *JSONExporter) GenerateJSONContent(segments []models.DataSegment) Transc
         }
         
         // 添加到分段
-        result.Segments = append(result.Segments, TranscriptSegment{
-            Start: segment.StartTime,
-            End:   endTime,
-            Text:  text,
-        })
+        transcriptSegment := TranscriptSegment{
+            Start:      segment.StartTime,
+            End:        endTime,
+            Text:       text,
+            Confidence: segment.Confidence,
+            Speaker:    segment.Speaker,
+            Language:   e.Language,
+        }
+        for _, word := range segment.Words {
+            transcriptSegment.Words = append(transcriptSegment.Words, TranscriptWord{
+                Start:      word.StartTime,
+                End:        word.EndTime,
+                Text:       word.Text,
+                Confidence: word.Confidence,
+            })
+        }
+        result.Segments = append(result.Segments, transcriptSegment)
     }
     
     result.FullText = fullTextBuilder.String()
@@ -82,25 +122,10 @@ func (e *JSONExporter) GenerateJSONContent(segments []models.DataSegment) Transc
 
 // ExportJSON 导出JSON格式文件
 func (e *JSONExporter) ExportJSON(segments []models.DataSegment, filename string, partNum *int) (string, error) {
-    // 创建输出文件夹
-    if err := os.MkdirAll(e.OutputFolder, 0755); err != nil {
-        return "", fmt.Errorf("创建输出目录失败: %w", err)
-    }
-    
-    // 构建文件名
-    baseName := filepath.Base(filename)
-    baseName = strings.TrimSuffix(baseName, filepath.Ext(baseName))
-    
-    var outputFile string
-    if partNum != nil {
-        // 创建子文件夹
-        outputSubfolder := filepath.Join(e.OutputFolder, baseName)
-        if err := os.MkdirAll(outputSubfolder, 0755); err != nil {
-            return "", fmt.Errorf("创建子目录失败: %w", err)
-        }
-        outputFile = filepath.Join(outputSubfolder, fmt.Sprintf("%s_part%d_json.txt", baseName, *partNum))
-    } else {
-        outputFile = filepath.Join(e.OutputFolder, fmt.Sprintf("%s_json.txt", baseName))
+    // 按命名模板确定输出路径
+    outputFile, err := e.Namer.Path(e.OutputFolder, filename, partNum, "json", "")
+    if err != nil {
+        return "", err
     }
     
     // 检查是否为空结果
@@ -108,6 +133,7 @@ func (e *JSONExporter) ExportJSON(segments []models.DataSegment, filename string
         utils.Warn("没有文本段落可导出JSON: %s", filename)
         // 创建一个空结果
         emptyContent := TranscriptResult{
+            SchemaVersion: JSONSchemaVersion,
             Language: "unknown",
             FullText: "",
             Segments: []TranscriptSegment{},
@@ -119,6 +145,7 @@ func (e *JSONExporter) ExportJSON(segments []models.DataSegment, filename string
         }
         
         // 写入文件
+        audit.RecordOverwrite(outputFile, "export", "重新生成JSON文件")
         if err := os.WriteFile(outputFile, jsonData, 0644); err != nil {
             return "", fmt.Errorf("写入JSON文件失败: %w", err)
         }
@@ -137,10 +164,124 @@ func (e *JSONExporter) ExportJSON(segments []models.DataSegment, filename string
     }
     
     // 写入文件
+    audit.RecordOverwrite(outputFile, "export", "重新生成JSON文件")
     if err := os.WriteFile(outputFile, jsonData, 0644); err != nil {
         return "", fmt.Errorf("写入JSON文件失败: %w", err)
     }
     
     utils.Info("已导出JSON文件: %s", outputFile)
     return outputFile, nil
-}
\ No newline at end of file
+}
+
+// LegacyJSONSuffix 版本 1 的JSON导出文件名后缀
+const LegacyJSONSuffix = "_json.txt"
+
+// legacyTranscriptResult 版本 1 的文件结构，没有 schema_version 与每段的语言
+type legacyTranscriptResult struct {
+    Language string              `json:"language,omitempty"`
+    FullText string              `json:"full_text"`
+    Segments []TranscriptSegment `json:"segments"`
+    Raw      interface{}         `json:"raw,omitempty"`
+}
+
+// ParseTranscript 解析任意版本的JSON导出，旧版本的内容升级为当前版本
+func ParseTranscript(data []byte) (*TranscriptResult, error) {
+    var result TranscriptResult
+    if err := json.Unmarshal(data, &result); err != nil {
+        return nil, fmt.Errorf("解析JSON导出失败: %w", err)
+    }
+    if result.SchemaVersion > JSONSchemaVersion {
+        return nil, fmt.Errorf("JSON导出的版本 %d 高于当前支持的版本 %d", result.SchemaVersion, JSONSchemaVersion)
+    }
+    if result.SchemaVersion < 2 {
+        // 版本 1 只有整体语言，逐段补上
+        for i := range result.Segments {
+            if result.Segments[i].Language == "" && result.Language != "unknown" {
+                result.Segments[i].Language = result.Language
+            }
+        }
+    }
+    result.SchemaVersion = JSONSchemaVersion
+    if result.Segments == nil {
+        result.Segments = []TranscriptSegment{}
+    }
+    return &result, nil
+}
+
+// DataSegments 将转录结果还原为识别段落，用于从JSON导出重新生成其他格式
+func (r *TranscriptResult) DataSegments() []models.DataSegment {
+    segments := make([]models.DataSegment, 0, len(r.Segments))
+    for _, s := range r.Segments {
+        segment := models.DataSegment{
+            Text:       s.Text,
+            StartTime:  s.Start,
+            EndTime:    s.End,
+            Confidence: s.Confidence,
+            Speaker:    s.Speaker,
+        }
+        for _, w := range s.Words {
+            segment.Words = append(segment.Words, models.WordTiming{
+                Text:       w.Text,
+                StartTime:  w.Start,
+                EndTime:    w.End,
+                Confidence: w.Confidence,
+            })
+        }
+        segments = append(segments, segment)
+    }
+    return segments
+}
+
+// MarshalLegacyJSON 按版本 1 的结构编码，供仍读取 *_json.txt 的旧工具使用
+func MarshalLegacyJSON(result *TranscriptResult) ([]byte, error) {
+    legacy := legacyTranscriptResult{
+        Language: result.Language,
+        FullText: result.FullText,
+        Segments: make([]TranscriptSegment, len(result.Segments)),
+        Raw:      result.Raw,
+    }
+    for i, segment := range result.Segments {
+        segment.Language = ""
+        legacy.Segments[i] = segment
+    }
+    data, err := json.MarshalIndent(legacy, "", "  ")
+    if err != nil {
+        return nil, fmt.Errorf("JSON编码失败: %w", err)
+    }
+    return data, nil
+}
+
+// ConvertJSONFile 在新旧格式之间转换JSON导出文件，返回生成的文件路径：
+// *_json.txt（版本 1）转换为同目录下的 .json（当前版本）；legacy 为 true 时反向生成 *_json.txt。
+// 原文件保留不变
+func ConvertJSONFile(path string, legacy bool) (string, error) {
+    data, err := os.ReadFile(path)
+    if err != nil {
+        return "", fmt.Errorf("读取JSON导出失败: %w", err)
+    }
+    result, err := ParseTranscript(data)
+    if err != nil {
+        return "", fmt.Errorf("%s: %w", path, err)
+    }
+
+    var target string
+    if legacy {
+        target = strings.TrimSuffix(path, ".json") + LegacyJSONSuffix
+        data, err = MarshalLegacyJSON(result)
+    } else {
+        if !strings.HasSuffix(path, LegacyJSONSuffix) {
+            return "", fmt.Errorf("不是旧版JSON导出文件: %s", path)
+        }
+        target = strings.TrimSuffix(path, LegacyJSONSuffix) + ".json"
+        data, err = json.MarshalIndent(result, "", "  ")
+    }
+    if err != nil {
+        return "", fmt.Errorf("JSON编码失败: %w", err)
+    }
+
+    audit.RecordOverwrite(target, "convert", "转换JSON导出格式")
+    if err := os.WriteFile(target, data, 0644); err != nil {
+        return "", fmt.Errorf("写入JSON文件失败: %w", err)
+    }
+    return target, nil
+}
@@ -0,0 +1,73 @@
+package export
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportJSONWritesVersionedFile(t *testing.T) {
+	exporter := NewJSONExporter(t.TempDir())
+	exporter.Language = "zh"
+
+	path, err := exporter.ExportJSON([]models.DataSegment{{Text: "你好", StartTime: 0, EndTime: 1, Speaker: "A", Confidence: 0.9}}, "/tmp/demo.mp3", nil)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(exporter.OutputFolder, "demo.json"), path)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	result, err := ParseTranscript(data)
+	require.NoError(t, err)
+	assert.Equal(t, JSONSchemaVersion, result.SchemaVersion)
+	assert.Equal(t, TranscriptSegment{Start: 0, End: 1, Text: "你好", Speaker: "A", Confidence: 0.9, Language: "zh"}, result.Segments[0])
+}
+
+func TestConvertJSONFile(t *testing.T) {
+	dir := t.TempDir()
+	legacyPath := filepath.Join(dir, "demo"+LegacyJSONSuffix)
+	legacy := `{"language": "en", "full_text": "hi", "segments": [{"start": 0, "end": 1, "text": "hi"}]}`
+	require.NoError(t, os.WriteFile(legacyPath, []byte(legacy), 0644))
+
+	// 旧格式升级为 .json，逐段补上语言
+	target, err := ConvertJSONFile(legacyPath, false)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "demo.json"), target)
+	data, err := os.ReadFile(target)
+	require.NoError(t, err)
+	result, err := ParseTranscript(data)
+	require.NoError(t, err)
+	assert.Equal(t, "en", result.Segments[0].Language)
+	assert.Contains(t, string(data), `"schema_version": 2`)
+
+	// 反向转换得到与原文件相同结构的 *_json.txt
+	require.NoError(t, os.Remove(legacyPath))
+	back, err := ConvertJSONFile(target, true)
+	require.NoError(t, err)
+	assert.Equal(t, legacyPath, back)
+	data, err = os.ReadFile(back)
+	require.NoError(t, err)
+	assert.JSONEq(t, legacy, string(data))
+
+	// 高于当前版本的文件不做转换
+	_, err = ParseTranscript([]byte(`{"schema_version": 99, "segments": []}`))
+	assert.Error(t, err)
+}
+
+func TestTranscriptDataSegments(t *testing.T) {
+	segments := []models.DataSegment{{
+		Text: "你好", StartTime: 1, EndTime: 2, Speaker: "A", Confidence: 0.9,
+		Words: []models.WordTiming{{Text: "你", StartTime: 1, EndTime: 1.5}, {Text: "好", StartTime: 1.5, EndTime: 2}},
+	}}
+	path, err := NewJSONExporter(t.TempDir()).ExportJSON(segments, "/tmp/demo.mp3", nil)
+	require.NoError(t, err)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	result, err := ParseTranscript(data)
+	require.NoError(t, err)
+	assert.Equal(t, segments, result.DataSegments())
+}
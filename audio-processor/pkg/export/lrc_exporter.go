@@ -0,0 +1,86 @@
+package export
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/audit"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/models"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/utils"
+)
+
+// LRCExporter 负责将ASR结果导出为LRC歌词文件。
+// 段落带有逐词时间戳时输出增强LRC（卡拉OK逐字高亮）格式，否则输出普通逐行LRC。
+type LRCExporter struct {
+	OutputFolder string
+	Namer        *Namer // 输出命名规则，为 nil 时使用默认规则
+}
+
+// NewLRCExporter 创建一个新的LRC导出器
+func NewLRCExporter(outputFolder string) *LRCExporter {
+	return &LRCExporter{
+		OutputFolder: outputFolder,
+	}
+}
+
+// FormatLRCTime 将秒数格式化为LRC时间格式 (mm:ss.xx)
+func (e *LRCExporter) FormatLRCTime(seconds float64) string {
+	if seconds < 0 {
+		seconds = 0
+	}
+	centis := int(seconds*100 + 0.5)
+	minutes := centis / 6000
+	secs := (centis % 6000) / 100
+	hundredths := centis % 100
+
+	return fmt.Sprintf("%02d:%02d.%02d", minutes, secs, hundredths)
+}
+
+// GenerateLRCContent 生成LRC格式内容
+func (e *LRCExporter) GenerateLRCContent(segments []models.DataSegment) string {
+	var lines []string
+
+	for _, segment := range segments {
+		text := strings.TrimSpace(segment.Text)
+		if text == "" || text == "[无法识别的音频片段]" {
+			continue
+		}
+
+		line := fmt.Sprintf("[%s]", e.FormatLRCTime(segment.StartTime))
+		if segment.HasWordTimings() {
+			// 增强LRC：每个词前标注开始时间，行尾标注最后一个词的结束时间
+			var b strings.Builder
+			for _, word := range segment.Words {
+				fmt.Fprintf(&b, "<%s>%s", e.FormatLRCTime(word.StartTime), word.Text)
+			}
+			last := segment.Words[len(segment.Words)-1]
+			fmt.Fprintf(&b, "<%s>", e.FormatLRCTime(last.EndTime))
+			line += b.String()
+		} else {
+			line += text
+		}
+
+		lines = append(lines, line)
+	}
+
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// ExportLRC 导出LRC格式文件
+func (e *LRCExporter) ExportLRC(segments []models.DataSegment, filename string, partNum *int) (string, error) {
+	// 按命名模板确定输出路径
+	outputFile, err := e.Namer.Path(e.OutputFolder, filename, partNum, "lrc", "")
+	if err != nil {
+		return "", err
+	}
+
+	// 写入文件
+	audit.RecordOverwrite(outputFile, "export", "重新生成LRC文件")
+	if err := os.WriteFile(outputFile, []byte(e.GenerateLRCContent(segments)), 0644); err != nil {
+		return "", fmt.Errorf("写入LRC文件失败: %w", err)
+	}
+
+	utils.Info("已导出LRC歌词: %s", outputFile)
+	return outputFile, nil
+}
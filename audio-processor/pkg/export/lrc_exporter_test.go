@@ -0,0 +1,30 @@
+package export
+
+import (
+	"testing"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateLRCContent(t *testing.T) {
+	exporter := NewLRCExporter(t.TempDir())
+
+	segments := []models.DataSegment{
+		{Text: "普通一行", StartTime: 1.5, EndTime: 3},
+		{
+			Text:      "你好",
+			StartTime: 65.2,
+			EndTime:   66,
+			Words: []models.WordTiming{
+				{Text: "你", StartTime: 65.2, EndTime: 65.5},
+				{Text: "好", StartTime: 65.5, EndTime: 66},
+			},
+		},
+		{Text: "  ", StartTime: 70, EndTime: 71},
+	}
+
+	content := exporter.GenerateLRCContent(segments)
+
+	assert.Equal(t, "[00:01.50]普通一行\n[01:05.20]<01:05.20>你<01:05.50>好<01:06.00>\n", content)
+}
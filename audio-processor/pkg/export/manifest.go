@@ -0,0 +1,294 @@
+package export
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/audit"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/models"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/utils"
+)
+
+// ManifestFileName 每个文件的输出清单文件名，位于输出目录下以文件名命名的子目录中
+const ManifestFileName = "outputs.json"
+
+// 输出文件所在的根目录
+const (
+	RootOutput = "output" // 输出目录 (output_folder)
+	RootMedia  = "media"  // 媒体目录 (media_folder)，字幕文件与视频放在一起
+)
+
+// OutputManifest 一个文件的全部输出，Web界面、下载接口与 `asr outputs` 都读取它，而不是扫描输出目录
+type OutputManifest struct {
+	Name      string        `json:"name"`       // 文件名（不含扩展名）
+	CreatedAt string        `json:"created_at"` // 生成时间
+	Outputs   []OutputEntry `json:"outputs"`
+	Tags      []string      `json:"tags,omitempty"` // 列出时从标签库填入，不写入清单文件
+}
+
+// OutputEntry 一个输出文件。路径相对于 Root 对应的目录，目录迁移后清单仍然有效
+type OutputEntry struct {
+	Type   string `json:"type"` // 格式，如 txt、srt、json
+	Root   string `json:"root"` // RootOutput 或 RootMedia
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// ManifestPath 返回文件输出清单的路径
+func ManifestPath(outputFolder, filename string) string {
+	baseName := filepath.Base(filename)
+	baseName = strings.TrimSuffix(baseName, filepath.Ext(baseName))
+	return filepath.Join(outputFolder, baseName, ManifestFileName)
+}
+
+// WriteManifest 为 outputFiles（格式 -> 路径）生成输出清单，返回清单路径。
+// 输出文件必须位于配置的输出目录或媒体目录下。
+func WriteManifest(config *models.Config, filename string, outputFiles map[string]string) (string, error) {
+	baseName := filepath.Base(filename)
+	manifest := OutputManifest{
+		Name:      strings.TrimSuffix(baseName, filepath.Ext(baseName)),
+		CreatedAt: time.Now().Format("2006-01-02 15:04:05"),
+	}
+
+	for fileType, path := range outputFiles {
+		entry, err := newOutputEntry(config, fileType, path)
+		if err != nil {
+			return "", err
+		}
+		manifest.Outputs = append(manifest.Outputs, entry)
+	}
+	sort.Slice(manifest.Outputs, func(i, j int) bool {
+		return manifest.Outputs[i].Type < manifest.Outputs[j].Type
+	})
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("编码输出清单失败: %w", err)
+	}
+	path := ManifestPath(config.OutputFolder, filename)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", fmt.Errorf("创建输出目录失败: %w", err)
+	}
+	audit.RecordOverwrite(path, "export", "重新生成输出清单")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return "", fmt.Errorf("写入输出清单失败: %w", err)
+	}
+	return path, nil
+}
+
+// LinkOutput 在文件已有的输出清单中添加或替换一项输出，如归档后的原文件，返回清单路径
+func LinkOutput(config *models.Config, filename, fileType, path string) (string, error) {
+	manifestPath := ManifestPath(config.OutputFolder, filename)
+	manifest, err := LoadManifest(manifestPath)
+	if err != nil {
+		return "", err
+	}
+	entry, err := newOutputEntry(config, fileType, path)
+	if err != nil {
+		return "", err
+	}
+
+	replaced := false
+	for i := range manifest.Outputs {
+		if manifest.Outputs[i].Type == fileType {
+			manifest.Outputs[i], replaced = entry, true
+		}
+	}
+	if !replaced {
+		manifest.Outputs = append(manifest.Outputs, entry)
+		sort.Slice(manifest.Outputs, func(i, j int) bool {
+			return manifest.Outputs[i].Type < manifest.Outputs[j].Type
+		})
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("编码输出清单失败: %w", err)
+	}
+	audit.RecordOverwrite(manifestPath, "export", "更新输出清单")
+	if err := os.WriteFile(manifestPath, data, 0644); err != nil {
+		return "", fmt.Errorf("写入输出清单失败: %w", err)
+	}
+	return manifestPath, nil
+}
+
+// newOutputEntry 计算输出文件的大小与校验和，并确定其所在的根目录
+func newOutputEntry(config *models.Config, fileType, path string) (OutputEntry, error) {
+	entry := OutputEntry{Type: fileType}
+	for _, root := range []struct{ name, dir string }{
+		{RootOutput, config.OutputFolder},
+		{RootMedia, config.MediaFolder},
+	} {
+		if rel, ok := relativeTo(root.dir, path); ok {
+			entry.Root, entry.Path = root.name, rel
+			break
+		}
+	}
+	if entry.Root == "" {
+		return entry, fmt.Errorf("输出文件不在输出目录或媒体目录下: %s", path)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return entry, fmt.Errorf("读取输出文件失败: %w", err)
+	}
+	defer file.Close()
+	hash := sha256.New()
+	if entry.Size, err = io.Copy(hash, file); err != nil {
+		return entry, fmt.Errorf("读取输出文件失败: %w", err)
+	}
+	entry.SHA256 = hex.EncodeToString(hash.Sum(nil))
+	return entry, nil
+}
+
+// relativeTo 返回 path 相对于 dir 的路径（使用 / 分隔），path 不在 dir 下时返回 false
+func relativeTo(dir, path string) (string, bool) {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) || filepath.IsAbs(rel) {
+		return "", false
+	}
+	return filepath.ToSlash(rel), true
+}
+
+// LoadManifest 读取输出清单
+func LoadManifest(path string) (*OutputManifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取输出清单失败: %w", err)
+	}
+	var manifest OutputManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("解析输出清单失败 %s: %w", path, err)
+	}
+	return &manifest, nil
+}
+
+// ListManifests 读取输出目录下所有文件的输出清单，按名称排序，无法解析的清单被跳过
+func ListManifests(outputFolder string) ([]*OutputManifest, error) {
+	entries, err := os.ReadDir(outputFolder)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("读取输出目录失败: %w", err)
+	}
+
+	var manifests []*OutputManifest
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		path := filepath.Join(outputFolder, entry.Name(), ManifestFileName)
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+		manifest, err := LoadManifest(path)
+		if err != nil {
+			utils.Warn("%v", err)
+			continue
+		}
+		manifests = append(manifests, manifest)
+	}
+	sort.Slice(manifests, func(i, j int) bool { return manifests[i].Name < manifests[j].Name })
+	return manifests, nil
+}
+
+// Output 返回指定格式的输出，不存在时返回 false
+func (m *OutputManifest) Output(fileType string) (OutputEntry, bool) {
+	for _, entry := range m.Outputs {
+		if entry.Type == fileType {
+			return entry, true
+		}
+	}
+	return OutputEntry{}, false
+}
+
+// Resolve 返回输出文件的实际路径
+func (e OutputEntry) Resolve(outputFolder, mediaFolder string) string {
+	dir := outputFolder
+	if e.Root == RootMedia {
+		dir = mediaFolder
+	}
+	return filepath.Join(dir, filepath.FromSlash(e.Path))
+}
+
+// ManifestHandler 提供输出清单的HTTP接口：
+//
+//	GET <prefix>                 全部文件的输出清单
+//	GET <prefix><name>           单个文件的输出清单
+//	GET <prefix><name>/<type>    下载指定格式的输出文件
+func ManifestHandler(outputFolder, mediaFolder, prefix string) http.Handler {
+	return http.StripPrefix(prefix, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			http.Error(w, "只支持GET请求", http.StatusMethodNotAllowed)
+			return
+		}
+
+		parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+		if parts[0] == "" {
+			manifests, err := ListManifests(outputFolder)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			if manifests, err = ApplyTags(manifests, outputFolder, r.URL.Query().Get("tag")); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			if manifests == nil {
+				manifests = []*OutputManifest{}
+			}
+			writeManifestJSON(w, manifests)
+			return
+		}
+		if len(parts) > 2 || parts[0] == "." || parts[0] == ".." {
+			http.NotFound(w, r)
+			return
+		}
+
+		manifest, err := LoadManifest(filepath.Join(outputFolder, parts[0], ManifestFileName))
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		if len(parts) == 1 {
+			if meta, err := OpenTagStore(outputFolder).Get(manifest.Name); err == nil {
+				manifest.Tags = meta.Tags
+			}
+			writeManifestJSON(w, manifest)
+			return
+		}
+
+		entry, ok := manifest.Output(parts[1])
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		path := entry.Resolve(outputFolder, mediaFolder)
+		// 播放页直接在浏览器中打开，其余输出作为附件下载
+		if entry.Type != "player" {
+			w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename*=UTF-8''%s", url.PathEscape(filepath.Base(path))))
+		}
+		w.Header().Set("X-Checksum-SHA256", entry.SHA256)
+		http.ServeFile(w, r, path)
+	}))
+}
+
+// writeManifestJSON 以JSON格式返回清单
+func writeManifestJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(v)
+}
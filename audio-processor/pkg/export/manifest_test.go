@@ -0,0 +1,67 @@
+package export
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteManifest(t *testing.T) {
+	dir := t.TempDir()
+	config := &models.Config{
+		OutputFolder: filepath.Join(dir, "output"),
+		MediaFolder:  filepath.Join(dir, "media"),
+	}
+	txt := filepath.Join(config.OutputFolder, "demo.txt")
+	srt := filepath.Join(config.MediaFolder, "demo.srt")
+	require.NoError(t, os.MkdirAll(config.OutputFolder, 0755))
+	require.NoError(t, os.MkdirAll(config.MediaFolder, 0755))
+	require.NoError(t, os.WriteFile(txt, []byte("hello"), 0644))
+	require.NoError(t, os.WriteFile(srt, []byte("1\n"), 0644))
+
+	path, err := WriteManifest(config, "/tmp/demo.mp3", map[string]string{"txt": txt, "srt": srt})
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(config.OutputFolder, "demo", ManifestFileName), path)
+
+	manifest, err := LoadManifest(path)
+	require.NoError(t, err)
+	assert.Equal(t, "demo", manifest.Name)
+	require.Len(t, manifest.Outputs, 2)
+	assert.Equal(t, OutputEntry{
+		Type: "srt", Root: RootMedia, Path: "demo.srt", Size: 2,
+		SHA256: "4355a46b19d348dc2f57c046f8ef63d4538ebb936000f3c9ee954a27460dd865",
+	}, manifest.Outputs[0])
+	entry, ok := manifest.Output("txt")
+	require.True(t, ok)
+	assert.Equal(t, txt, entry.Resolve(config.OutputFolder, config.MediaFolder))
+	assert.Equal(t, "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824", entry.SHA256)
+
+	// 输出目录之外的文件无法记录
+	_, err = WriteManifest(config, "demo.mp3", map[string]string{"txt": filepath.Join(dir, "other.txt")})
+	assert.Error(t, err)
+
+	// HTTP接口：列表、单个清单与下载
+	handler := ManifestHandler(config.OutputFolder, config.MediaFolder, "/api/outputs/")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/outputs/", nil))
+	var list []OutputManifest
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &list))
+	assert.Len(t, list, 1)
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/outputs/demo/txt", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "hello", rec.Body.String())
+	assert.Equal(t, entry.SHA256, rec.Header().Get("X-Checksum-SHA256"))
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/outputs/demo/pdf", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
@@ -0,0 +1,146 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/models"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/utils"
+)
+
+// 提交给大模型的文稿上限（字符），超出部分截断，章节仍按完整文稿排版
+const maxOutlineChars = 100000
+
+// outlinePrompt 生成文稿结构的系统提示，要求只返回JSON，便于按时间戳排版
+const outlinePrompt = "你是一个内容编辑。下面是一段音视频的识别文稿，每行开头的方括号内是该段的开始时间（秒）。" +
+	"请为它生成结构，只返回如下格式的JSON，不要输出其他内容：\n" +
+	`{"title": "标题", "summary": "一段话的摘要", ` +
+	`"chapters": [{"start": 章节开始时间（秒，取文稿中已有的时间）, "heading": "章节标题"}], ` +
+	`"takeaways": ["要点"]}` + "\n" +
+	"章节按时间顺序排列，第一个章节从文稿开头开始，章节数量视内容长短而定，一般 3 到 10 个；要点 3 到 7 条。"
+
+// OutlineLLM 生成文稿结构的大模型接口
+type OutlineLLM interface {
+	Chat(systemPrompt, content string) (string, error)
+}
+
+// Outline 大模型生成的文稿结构
+type Outline struct {
+	Title     string           `json:"title"`
+	Summary   string           `json:"summary"`
+	Chapters  []OutlineChapter `json:"chapters"`
+	Takeaways []string         `json:"takeaways"`
+}
+
+// OutlineChapter 一个章节，Start 为开始时间（秒）
+type OutlineChapter struct {
+	Start   float64 `json:"start"`
+	Heading string  `json:"heading"`
+}
+
+// RequestOutline 请求大模型生成文稿的标题、摘要、章节与要点
+func RequestOutline(client OutlineLLM, segments []models.DataSegment) (*Outline, error) {
+	var b strings.Builder
+	for _, segment := range segments {
+		text := strings.TrimSpace(segment.Text)
+		if text == "" || text == "[无法识别的音频片段]" {
+			continue
+		}
+		fmt.Fprintf(&b, "[%.0f] %s\n", segment.StartTime, text)
+	}
+	content := b.String()
+	if content == "" {
+		return nil, fmt.Errorf("文稿为空")
+	}
+	if runes := []rune(content); len(runes) > maxOutlineChars {
+		content = string(runes[:maxOutlineChars])
+	}
+
+	reply, err := client.Chat(outlinePrompt, content)
+	if err != nil {
+		return nil, fmt.Errorf("生成文稿结构失败: %w", err)
+	}
+	return ParseOutline(reply)
+}
+
+// ParseOutline 解析大模型返回的文稿结构，容忍代码块包裹与前后的说明文字。
+// 章节按时间排序，去掉空标题
+func ParseOutline(reply string) (*Outline, error) {
+	start := strings.Index(reply, "{")
+	end := strings.LastIndex(reply, "}")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("大模型未返回JSON: %q", reply)
+	}
+	var outline Outline
+	if err := json.Unmarshal([]byte(reply[start:end+1]), &outline); err != nil {
+		return nil, fmt.Errorf("解析文稿结构失败: %w", err)
+	}
+
+	chapters := outline.Chapters[:0]
+	for _, chapter := range outline.Chapters {
+		chapter.Heading = strings.TrimSpace(chapter.Heading)
+		if chapter.Heading != "" && chapter.Start >= 0 {
+			chapters = append(chapters, chapter)
+		}
+	}
+	sort.SliceStable(chapters, func(i, j int) bool { return chapters[i].Start < chapters[j].Start })
+	outline.Chapters = chapters
+	return &outline, nil
+}
+
+// RenderOutlineMarkdown 按文稿结构排版Markdown：标题、摘要、要点、章节目录，
+// 然后在各章节标题下放入该时间段的文稿。没有章节时文稿放在一个“全文”小节中
+func RenderOutlineMarkdown(outline *Outline, segments []models.DataSegment, fallbackTitle string) string {
+	var b strings.Builder
+	title := strings.TrimSpace(outline.Title)
+	if title == "" {
+		title = fallbackTitle
+	}
+	fmt.Fprintf(&b, "# %s\n\n", title)
+	if summary := strings.TrimSpace(outline.Summary); summary != "" {
+		fmt.Fprintf(&b, "> %s\n\n", strings.ReplaceAll(summary, "\n", "\n> "))
+	}
+	if len(outline.Takeaways) > 0 {
+		b.WriteString("## 要点\n\n")
+		for _, takeaway := range outline.Takeaways {
+			if takeaway = strings.TrimSpace(takeaway); takeaway != "" {
+				fmt.Fprintf(&b, "- %s\n", takeaway)
+			}
+		}
+		b.WriteString("\n")
+	}
+
+	chapters := outline.Chapters
+	if len(chapters) == 0 {
+		chapters = []OutlineChapter{{Start: 0, Heading: "全文"}}
+	} else {
+		b.WriteString("## 目录\n\n")
+		for _, chapter := range chapters {
+			fmt.Fprintf(&b, "- [%s] %s\n", utils.FormatTime(chapter.Start), chapter.Heading)
+		}
+		b.WriteString("\n")
+	}
+
+	// 每段文稿归入开始时间所在的章节，第一个章节之前的内容归入第一个章节
+	next := 0
+	for i, chapter := range chapters {
+		fmt.Fprintf(&b, "## [%s] %s\n\n", utils.FormatTime(chapter.Start), chapter.Heading)
+		for ; next < len(segments); next++ {
+			segment := segments[next]
+			if i+1 < len(chapters) && segment.StartTime >= chapters[i+1].Start {
+				break
+			}
+			text := strings.TrimSpace(segment.Text)
+			if text == "" || text == "[无法识别的音频片段]" {
+				continue
+			}
+			if segment.Speaker != "" {
+				text = "**" + segment.Speaker + "**: " + text
+			}
+			b.WriteString(text + "\n\n")
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,50 @@
+package export
+
+import (
+	"testing"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeOutlineLLM 返回固定回复，并记录收到的文稿
+type fakeOutlineLLM struct {
+	reply   string
+	content string
+}
+
+func (f *fakeOutlineLLM) Chat(systemPrompt, content string) (string, error) {
+	f.content = content
+	return f.reply, nil
+}
+
+func TestRequestOutlineAndRender(t *testing.T) {
+	segments := []models.DataSegment{
+		{Text: "大家好", StartTime: 0, EndTime: 2},
+		{Text: "今天讲并发", StartTime: 2, EndTime: 5, Speaker: "A"},
+		{Text: "[无法识别的音频片段]", StartTime: 5, EndTime: 6},
+		{Text: "最后总结", StartTime: 65, EndTime: 70},
+	}
+	client := &fakeOutlineLLM{reply: "```json\n" + `{"title": "并发入门", "summary": "介绍并发。",
+		"chapters": [{"start": 65, "heading": "总结"}, {"start": 0, "heading": "开场"}, {"start": 30, "heading": " "}],
+		"takeaways": ["先理解goroutine"]}` + "\n```"}
+
+	outline, err := RequestOutline(client, segments)
+	require.NoError(t, err)
+	assert.Equal(t, "[0] 大家好\n[2] 今天讲并发\n[65] 最后总结\n", client.content)
+	// 章节按时间排序并去掉空标题
+	assert.Equal(t, []OutlineChapter{{Start: 0, Heading: "开场"}, {Start: 65, Heading: "总结"}}, outline.Chapters)
+
+	expected := "# 并发入门\n\n> 介绍并发。\n\n" +
+		"## 要点\n\n- 先理解goroutine\n\n" +
+		"## 目录\n\n- [00:00] 开场\n- [01:05] 总结\n\n" +
+		"## [00:00] 开场\n\n大家好\n\n**A**: 今天讲并发\n\n" +
+		"## [01:05] 总结\n\n最后总结\n\n"
+	assert.Equal(t, expected, RenderOutlineMarkdown(outline, segments, "demo"))
+}
+
+func TestParseOutlineRejectsNonJSON(t *testing.T) {
+	_, err := ParseOutline("抱歉，我无法完成")
+	assert.Error(t, err)
+}
@@ -0,0 +1,109 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// DefaultOutputTemplate 未配置 output_template 时的命名规则，与早期版本的固定路径一致：
+// 整个文件输出为 <文件名><后缀>.<扩展名>，分段结果写入以文件名命名的子目录
+const DefaultOutputTemplate = `{{if .Part}}{{.Basename}}/{{.Basename}}_{{.Part}}{{else}}{{.Basename}}{{end}}{{.Suffix}}.{{.Ext}}`
+
+// NameData 输出文件命名模板可用的字段
+type NameData struct {
+	Basename string // 不含扩展名的文件名
+	Ext      string // 扩展名（不含点），如 txt、srt
+	Suffix   string // 同一扩展名下区分格式的后缀，如剪映草稿为 "_jianying"（扩展名为 json）
+	Part     string // 分段编号，如 "part3"，整个文件时为空
+	Folder   string // 源文件所在目录的名称，可用于按频道或来源整理
+	Date     string // 处理日期 (2006-01-02)
+	Year     string
+	Month    string
+	Day      string
+}
+
+// Namer 按模板确定输出文件相对于输出根目录的路径。nil 时使用 DefaultOutputTemplate
+type Namer struct {
+	Source string // 源媒体文件路径，用于 {{.Folder}}，为空时使用输出文件名所在目录
+
+	tmpl *template.Template
+}
+
+// NewNamer 解析命名模板，模板为空时使用 DefaultOutputTemplate
+func NewNamer(pattern string) (*Namer, error) {
+	if pattern == "" {
+		pattern = DefaultOutputTemplate
+	}
+	tmpl, err := template.New("output").Option("missingkey=error").Parse(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("解析输出命名模板失败: %w", err)
+	}
+	return &Namer{tmpl: tmpl}, nil
+}
+
+// defaultNamer 未设置 Namer 的导出器使用的命名规则
+var defaultNamer, _ = NewNamer("")
+
+// Data 返回 filename 的模板字段
+func (n *Namer) Data(filename string, partNum *int, ext, suffix string) NameData {
+	baseName := filepath.Base(filename)
+	source := filename
+	if n != nil && n.Source != "" {
+		source = n.Source
+	}
+	now := time.Now()
+	data := NameData{
+		Basename: strings.TrimSuffix(baseName, filepath.Ext(baseName)),
+		Ext:      ext,
+		Suffix:   suffix,
+		Folder:   filepath.Base(filepath.Dir(source)),
+		Date:     now.Format("2006-01-02"),
+		Year:     now.Format("2006"),
+		Month:    now.Format("01"),
+		Day:      now.Format("02"),
+	}
+	if partNum != nil {
+		data.Part = fmt.Sprintf("part%d", *partNum)
+	}
+	return data
+}
+
+// Path 返回 filename 的 ext 格式输出在 root 下的路径，并创建所在目录。
+// 模板生成的路径必须是 root 下的相对路径。
+func (n *Namer) Path(root, filename string, partNum *int, ext, suffix string) (string, error) {
+	if n == nil {
+		n = defaultNamer
+	}
+	rel, err := n.render(n.Data(filename, partNum, ext, suffix))
+	if err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(root, rel)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", fmt.Errorf("创建输出目录失败: %w", err)
+	}
+	return path, nil
+}
+
+// render 执行模板并校验结果，返回使用系统分隔符的相对路径
+func (n *Namer) render(data NameData) (string, error) {
+	var buf bytes.Buffer
+	if err := n.tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("执行输出命名模板失败: %w", err)
+	}
+	rel := strings.TrimSpace(buf.String())
+	if rel == "" || strings.HasSuffix(rel, "/") {
+		return "", fmt.Errorf("输出命名模板生成的路径无效: %q", rel)
+	}
+	rel = filepath.Clean(filepath.FromSlash(rel))
+	if filepath.IsAbs(rel) || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("输出命名模板生成的路径超出输出目录: %q", rel)
+	}
+	return rel, nil
+}
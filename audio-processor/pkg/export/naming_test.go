@@ -0,0 +1,48 @@
+package export
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestDefaultNamer 测试默认命名规则与早期版本的固定路径一致
+func TestDefaultNamer(t *testing.T) {
+	root := t.TempDir()
+	part := 3
+
+	var namer *Namer
+	path, err := namer.Path(root, "/tmp/demo.mp3", nil, "txt", "_json")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(root, "demo_json.txt"), path)
+
+	path, err = namer.Path(root, "/tmp/demo.mp3", &part, "srt", "")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(root, "demo", "demo_part3.srt"), path)
+	assert.DirExists(t, filepath.Join(root, "demo"))
+}
+
+// TestNamerTemplate 测试自定义模板按日期与来源目录整理输出，并拒绝超出输出目录的路径
+func TestNamerTemplate(t *testing.T) {
+	root := t.TempDir()
+	namer, err := NewNamer("{{.Folder}}/{{.Date}}/{{.Basename}}{{if .Part}}_{{.Part}}{{end}}{{.Suffix}}.{{.Ext}}")
+	require.NoError(t, err)
+	namer.Source = filepath.Join("media", "channel-a", "demo.mp4")
+
+	path, err := namer.Path(root, "/tmp/demo.mp3", nil, "md", "")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(root, "channel-a", time.Now().Format("2006-01-02"), "demo.md"), path)
+
+	escaping, err := NewNamer("../{{.Basename}}{{.Suffix}}.{{.Ext}}")
+	require.NoError(t, err)
+	_, err = escaping.Path(root, "demo.mp3", nil, "txt", "")
+	assert.Error(t, err)
+
+	unknown, err := NewNamer("{{.Channel}}.{{.Ext}}")
+	require.NoError(t, err)
+	_, err = unknown.Path(root, "demo.mp3", nil, "txt", "")
+	assert.Error(t, err)
+}
@@ -0,0 +1,364 @@
+package export
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/audit"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/models"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/utils"
+)
+
+// PDF 版面参数（单位为点，A4 纸）
+const (
+	pdfPageWidth    = 595.0
+	pdfPageHeight   = 842.0
+	pdfMargin       = 56.0
+	pdfTitleSize    = 18.0
+	pdfSubtitleSize = 9.0
+	pdfBodySize     = 11.0
+	pdfLineSpacing  = 1.55 // 行距为字号的倍数
+	pdfParagraphGap = 6.0  // 段落之间的额外间距
+	pdfFooterSize   = 8.0
+)
+
+// 灰度颜色：0 为黑色，1 为白色
+const (
+	pdfTextGray  = 0.0
+	pdfMutedGray = 0.5
+)
+
+// PDFExporter 负责将ASR结果导出为PDF文档。
+// 使用阅读器内置的 STSong-Light 中文字体（Adobe-GB1），不嵌入字体文件，生成的文件体积小；
+// 半角字符按半个字宽排版。
+type PDFExporter struct {
+	OutputFolder string
+	Namer        *Namer // 输出命名规则，为 nil 时使用默认规则
+	Options      DocumentOptions
+}
+
+// NewPDFExporter 创建一个新的PDF导出器
+func NewPDFExporter(outputFolder string) *PDFExporter {
+	return &PDFExporter{
+		OutputFolder: outputFolder,
+		Options:      DocumentOptions{IncludeTimestamps: true, IncludeSpeakers: true},
+	}
+}
+
+// pdfSpan 一行中颜色相同的一段文字
+type pdfSpan struct {
+	Text string
+	Gray float64
+}
+
+// pdfLine 排版后的一行
+type pdfLine struct {
+	Size  float64
+	Gap   float64 // 行前的额外间距
+	Spans []pdfSpan
+}
+
+// styledRune 带颜色的字符，用于跨颜色段换行
+type styledRune struct {
+	r    rune
+	gray float64
+}
+
+// WritePDF 将文稿排版并写为PDF格式
+func (e *PDFExporter) WritePDF(w io.Writer, transcript Transcript) error {
+	pages := paginatePDF(layoutTranscript(transcript))
+
+	pw := &pdfWriter{}
+	pw.buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// 1-6 号对象固定，之后每页占用页面与内容流两个对象
+	pageIDs := make([]int, len(pages))
+	for i := range pages {
+		pageIDs[i] = 7 + i*2
+	}
+	kids := make([]string, len(pageIDs))
+	for i, id := range pageIDs {
+		kids[i] = fmt.Sprintf("%d 0 R", id)
+	}
+
+	pw.object(1, "<< /Type /Catalog /Pages 2 0 R >>")
+	pw.object(2, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)))
+	pw.object(3, "<< /Type /Font /Subtype /Type0 /BaseFont /STSong-Light /Encoding /UniGB-UCS2-H /DescendantFonts [4 0 R] >>")
+	pw.object(4, "<< /Type /Font /Subtype /CIDFontType0 /BaseFont /STSong-Light "+
+		"/CIDSystemInfo << /Registry (Adobe) /Ordering (GB1) /Supplement 2 >> "+
+		"/FontDescriptor 5 0 R /DW 1000 /W [1 95 500] >>")
+	pw.object(5, "<< /Type /FontDescriptor /FontName /STSong-Light /Flags 6 "+
+		"/FontBBox [-25 -254 1000 880] /ItalicAngle 0 /Ascent 880 /Descent -120 /CapHeight 880 /StemV 93 >>")
+	pw.object(6, fmt.Sprintf("<< /Title %s /Producer (asr-media-cli) >>", pdfTextString(transcript.Title)))
+
+	for i, page := range pages {
+		content, err := pageContent(page, i+1, len(pages))
+		if err != nil {
+			return err
+		}
+		pw.object(pageIDs[i], fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %g %g] "+
+			"/Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>", pdfPageWidth, pdfPageHeight, pageIDs[i]+1))
+		pw.stream(pageIDs[i]+1, content)
+	}
+
+	pw.finish(1, 6)
+	if _, err := w.Write(pw.buf.Bytes()); err != nil {
+		return fmt.Errorf("写入PDF内容失败: %w", err)
+	}
+	return nil
+}
+
+// layoutTranscript 将文稿按页面宽度换行：标题、副标题，然后逐段输出正文
+func layoutTranscript(transcript Transcript) []pdfLine {
+	var lines []pdfLine
+	add := func(text []styledRune, size, gap float64) {
+		for i, line := range wrapStyled(text, (pdfPageWidth-2*pdfMargin)/size) {
+			l := pdfLine{Size: size, Spans: spansOf(line)}
+			if i == 0 {
+				l.Gap = gap
+			}
+			lines = append(lines, l)
+		}
+	}
+
+	add(styled(transcript.Title, pdfTextGray), pdfTitleSize, 0)
+	add(styled(transcript.Subtitle, pdfMutedGray), pdfSubtitleSize, 4)
+
+	gap := 2 * pdfParagraphGap
+	for _, paragraph := range transcript.Paragraphs {
+		var text []styledRune
+		if paragraph.Time != "" {
+			text = append(text, styled(paragraph.Time+" ", pdfMutedGray)...)
+		}
+		if paragraph.Speaker != "" {
+			text = append(text, styled(paragraph.Speaker+": ", pdfTextGray)...)
+		}
+		for i, line := range splitLines(paragraph.Text) {
+			if i > 0 {
+				add(text, pdfBodySize, gap)
+				text, gap = nil, 0
+			}
+			text = append(text, styled(line, pdfTextGray)...)
+		}
+		add(text, pdfBodySize, gap)
+		gap = pdfParagraphGap
+	}
+	return lines
+}
+
+// paginatePDF 按页面高度分页，页首的段落间距被忽略
+func paginatePDF(lines []pdfLine) [][]pdfLine {
+	pages := [][]pdfLine{nil}
+	y := pdfPageHeight - pdfMargin
+	for _, line := range lines {
+		current := &pages[len(pages)-1]
+		height := line.Size * pdfLineSpacing
+		if len(*current) > 0 && y-line.Gap-height < pdfMargin {
+			pages = append(pages, nil)
+			current = &pages[len(pages)-1]
+			y = pdfPageHeight - pdfMargin
+		}
+		if len(*current) == 0 {
+			line.Gap = 0
+		}
+		y -= line.Gap + height
+		*current = append(*current, line)
+	}
+	return pages
+}
+
+// pageContent 生成一页的内容流，页脚标注页码
+func pageContent(lines []pdfLine, page, total int) ([]byte, error) {
+	var b bytes.Buffer
+	y := pdfPageHeight - pdfMargin
+	for _, line := range lines {
+		y -= line.Gap + line.Size*pdfLineSpacing
+		fmt.Fprintf(&b, "BT /F1 %g Tf %.2f %.2f Td", line.Size, pdfMargin, y+line.Size*(pdfLineSpacing-1)/2)
+		for _, span := range line.Spans {
+			fmt.Fprintf(&b, " %g g %s Tj", span.Gray, pdfHexString(span.Text))
+		}
+		b.WriteString(" ET\n")
+	}
+
+	footer := fmt.Sprintf("%d / %d", page, total)
+	x := (pdfPageWidth - textWidth([]rune(footer))*pdfFooterSize) / 2
+	fmt.Fprintf(&b, "BT /F1 %g Tf %.2f %.2f Td %g g %s Tj ET\n", pdfFooterSize, x, pdfMargin/2, pdfMutedGray, pdfHexString(footer))
+
+	var compressed bytes.Buffer
+	zw := zlib.NewWriter(&compressed)
+	if _, err := zw.Write(b.Bytes()); err != nil {
+		return nil, fmt.Errorf("压缩PDF内容失败: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("压缩PDF内容失败: %w", err)
+	}
+	return compressed.Bytes(), nil
+}
+
+// styled 为文本的每个字符附加颜色。字体只覆盖基本多文种平面，其余字符以问号代替，控制字符被丢弃
+func styled(text string, gray float64) []styledRune {
+	runes := make([]styledRune, 0, len(text))
+	for _, r := range text {
+		switch {
+		case r == '\t':
+			r = ' '
+		case unicode.IsControl(r):
+			continue
+		case r > 0xFFFF:
+			r = '?'
+		}
+		runes = append(runes, styledRune{r, gray})
+	}
+	return runes
+}
+
+// wrapStyled 按最大宽度（以字号为单位）换行，英文单词尽量不拆开
+func wrapStyled(text []styledRune, maxWidth float64) [][]styledRune {
+	var lines [][]styledRune
+	for len(text) > 0 {
+		width, cut := 0.0, len(text)
+		for i, sr := range text {
+			width += runeWidth(sr.r)
+			if width > maxWidth && i > 0 {
+				cut = i
+				break
+			}
+		}
+		if cut < len(text) && text[cut].r != ' ' && text[cut].r < 0x80 {
+			for i := cut - 1; i > cut/2; i-- {
+				if text[i].r == ' ' {
+					cut = i + 1
+					break
+				}
+			}
+		}
+
+		line := text[:cut]
+		for len(line) > 0 && line[len(line)-1].r == ' ' {
+			line = line[:len(line)-1]
+		}
+		lines = append(lines, line)
+
+		text = text[cut:]
+		for len(text) > 0 && text[0].r == ' ' {
+			text = text[1:]
+		}
+	}
+	if len(lines) == 0 {
+		lines = append(lines, nil)
+	}
+	return lines
+}
+
+// spansOf 将一行字符按颜色合并为若干段
+func spansOf(line []styledRune) []pdfSpan {
+	var spans []pdfSpan
+	for _, sr := range line {
+		if n := len(spans); n > 0 && spans[n-1].Gray == sr.gray {
+			spans[n-1].Text += string(sr.r)
+			continue
+		}
+		spans = append(spans, pdfSpan{Text: string(sr.r), Gray: sr.gray})
+	}
+	return spans
+}
+
+// runeWidth 字符宽度（以字号为单位）：半角字符为 0.5，其余为 1
+func runeWidth(r rune) float64 {
+	if r < 0x80 {
+		return 0.5
+	}
+	return 1
+}
+
+// textWidth 文本宽度（以字号为单位）
+func textWidth(runes []rune) float64 {
+	width := 0.0
+	for _, r := range runes {
+		width += runeWidth(r)
+	}
+	return width
+}
+
+// pdfHexString 将文本编码为 UniGB-UCS2-H 使用的 UCS-2 大端十六进制字符串
+func pdfHexString(text string) string {
+	var b strings.Builder
+	b.WriteByte('<')
+	for _, r := range text {
+		if r > 0xFFFF {
+			r = '?'
+		}
+		fmt.Fprintf(&b, "%04X", r)
+	}
+	b.WriteByte('>')
+	return b.String()
+}
+
+// pdfTextString 将文本编码为文档信息中使用的 UTF-16 大端十六进制字符串（带BOM）
+func pdfTextString(text string) string {
+	var b strings.Builder
+	b.WriteString("<FEFF")
+	for _, u := range utf16.Encode([]rune(text)) {
+		fmt.Fprintf(&b, "%04X", u)
+	}
+	b.WriteByte('>')
+	return b.String()
+}
+
+// pdfWriter 按对象编号顺序写入PDF对象并记录交叉引用表偏移
+type pdfWriter struct {
+	buf     bytes.Buffer
+	offsets []int
+}
+
+// object 写入一个间接对象，编号必须连续递增
+func (w *pdfWriter) object(id int, body string) {
+	w.offsets = append(w.offsets, w.buf.Len())
+	fmt.Fprintf(&w.buf, "%d 0 obj\n%s\nendobj\n", id, body)
+}
+
+// stream 写入一个使用 FlateDecode 压缩的流对象
+func (w *pdfWriter) stream(id int, data []byte) {
+	w.offsets = append(w.offsets, w.buf.Len())
+	fmt.Fprintf(&w.buf, "%d 0 obj\n<< /Length %d /Filter /FlateDecode >>\nstream\n", id, len(data))
+	w.buf.Write(data)
+	w.buf.WriteString("\nendstream\nendobj\n")
+}
+
+// finish 写入交叉引用表与文件尾
+func (w *pdfWriter) finish(root, info int) {
+	xref := w.buf.Len()
+	fmt.Fprintf(&w.buf, "xref\n0 %d\n0000000000 65535 f \n", len(w.offsets)+1)
+	for _, offset := range w.offsets {
+		fmt.Fprintf(&w.buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&w.buf, "trailer\n<< /Size %d /Root %d 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n",
+		len(w.offsets)+1, root, info, xref)
+}
+
+// ExportPDF 导出PDF格式文档
+func (e *PDFExporter) ExportPDF(segments []models.DataSegment, filename string, partNum *int) (string, error) {
+	outputFile, err := e.Namer.Path(e.OutputFolder, filename, partNum, "pdf", "")
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := e.WritePDF(&buf, BuildTranscript(segments, filename, partNum, e.Options)); err != nil {
+		return "", err
+	}
+
+	audit.RecordOverwrite(outputFile, "export", "重新生成PDF文档")
+	if err := os.WriteFile(outputFile, buf.Bytes(), 0644); err != nil {
+		return "", fmt.Errorf("写入PDF文件失败: %w", err)
+	}
+
+	utils.Info("已导出PDF文档: %s", outputFile)
+	return outputFile, nil
+}
@@ -0,0 +1,245 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/audit"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/models"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/utils"
+)
+
+// PlayerFileName 播放页的文件名，与输出清单位于同一目录
+const PlayerFileName = "player.html"
+
+// PlayerExporter 生成可离线打开的播放页：播放源媒体、显示字幕，点击文稿段落跳转到对应位置。
+// 通过 file:// 打开时使用相对路径引用媒体，字幕内嵌在页面中（浏览器禁止 file:// 加载字幕轨道）；
+// 通过输出清单接口访问时使用同目录下的 media 与 vtt 下载地址。
+type PlayerExporter struct {
+	OutputFolder string
+	MediaFolder  string
+	Options      SRTOptions // 字幕排版限制，与SRT、VTT相同
+}
+
+// NewPlayerExporter 创建一个新的播放页导出器
+func NewPlayerExporter(outputFolder, mediaFolder string) *PlayerExporter {
+	return &PlayerExporter{
+		OutputFolder: outputFolder,
+		MediaFolder:  mediaFolder,
+	}
+}
+
+// playerData 嵌入页面的数据
+type playerData struct {
+	MediaFile   string      `json:"media_file"`             // file:// 打开时的媒体地址
+	ServedMedia string      `json:"served_media,omitempty"` // 通过接口访问时的媒体地址，媒体不在媒体目录下时为空
+	ServedVTT   string      `json:"served_vtt,omitempty"`   // 通过接口访问时的字幕地址，未生成VTT时为空
+	Subtitles   []playerCue `json:"subtitles"`
+	Paragraphs  []playerCue `json:"paragraphs"`
+}
+
+// playerCue 一条字幕或一个文稿段落
+type playerCue struct {
+	Start   float64 `json:"start"`
+	End     float64 `json:"end"`
+	Text    string  `json:"text"`
+	Speaker string  `json:"speaker,omitempty"`
+	Time    string  `json:"time,omitempty"`
+}
+
+// playerAudioExts 使用 <audio> 播放的扩展名，其余使用 <video>
+var playerAudioExts = map[string]bool{
+	".mp3": true, ".wav": true, ".m4a": true, ".aac": true, ".flac": true, ".ogg": true, ".opus": true,
+}
+
+// ExportPlayer 为源媒体 mediaPath 生成播放页，vttPath 为已导出的VTT字幕（可为空），返回播放页路径
+func (e *PlayerExporter) ExportPlayer(segments []models.DataSegment, filename, mediaPath, vttPath string) (string, error) {
+	path := filepath.Join(filepath.Dir(ManifestPath(e.OutputFolder, filename)), PlayerFileName)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", fmt.Errorf("创建输出目录失败: %w", err)
+	}
+
+	content, err := e.GeneratePlayerHTML(segments, filename, mediaPath, vttPath, filepath.Dir(path))
+	if err != nil {
+		return "", err
+	}
+
+	audit.RecordOverwrite(path, "export", "重新生成播放页")
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		return "", fmt.Errorf("写入播放页失败: %w", err)
+	}
+
+	utils.Info("已生成播放页: %s", path)
+	return path, nil
+}
+
+// GeneratePlayerHTML 生成播放页内容，pageDir 为播放页所在目录，用于计算媒体的相对路径
+func (e *PlayerExporter) GeneratePlayerHTML(segments []models.DataSegment, filename, mediaPath, vttPath, pageDir string) ([]byte, error) {
+	data := playerData{
+		MediaFile:  mediaFileURL(pageDir, mediaPath, e.MediaFolder),
+		Subtitles:  []playerCue{},
+		Paragraphs: []playerCue{},
+	}
+	if e.ServesMedia(mediaPath) {
+		data.ServedMedia = "media"
+	}
+	if vttPath != "" {
+		data.ServedVTT = "vtt"
+	}
+
+	for _, cue := range e.Options.buildCues(segments) {
+		data.Subtitles = append(data.Subtitles, playerCue{Start: cue.Start, End: cue.End, Text: strings.Join(cue.Lines, "\n")})
+	}
+	for _, segment := range segments {
+		text := strings.TrimSpace(segment.Text)
+		if text == "" || text == "[无法识别的音频片段]" {
+			continue
+		}
+		data.Paragraphs = append(data.Paragraphs, playerCue{
+			Start:   segment.StartTime,
+			End:     segment.EndTime,
+			Text:    text,
+			Speaker: segment.Speaker,
+			Time:    utils.FormatTime(segment.StartTime),
+		})
+	}
+
+	baseName := filepath.Base(filename)
+	tag := "video"
+	if playerAudioExts[strings.ToLower(filepath.Ext(mediaPath))] {
+		tag = "audio"
+	}
+
+	var buf bytes.Buffer
+	err := playerTemplate.Execute(&buf, struct {
+		Title string
+		Tag   string
+		Data  playerData
+	}{strings.TrimSuffix(baseName, filepath.Ext(baseName)), tag, data})
+	if err != nil {
+		return nil, fmt.Errorf("生成播放页失败: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// ServesMedia 判断源媒体是否位于媒体目录下，此时可写入输出清单并通过接口播放
+func (e *PlayerExporter) ServesMedia(mediaPath string) bool {
+	_, ok := relativeTo(e.MediaFolder, mediaPath)
+	return ok
+}
+
+// mediaFileURL 返回 file:// 打开播放页时的媒体地址：媒体位于媒体目录下时使用相对路径，
+// 目录整体迁移后仍然有效；否则使用绝对路径
+func mediaFileURL(pageDir, mediaPath, mediaFolder string) string {
+	if _, ok := relativeTo(mediaFolder, mediaPath); ok {
+		if rel, err := filepath.Rel(pageDir, mediaPath); err == nil {
+			return escapeURLPath(filepath.ToSlash(rel))
+		}
+	}
+	abs, err := filepath.Abs(mediaPath)
+	if err != nil {
+		abs = mediaPath
+	}
+	abs = filepath.ToSlash(abs)
+	if !strings.HasPrefix(abs, "/") {
+		abs = "/" + abs // Windows 盘符路径
+	}
+	return "file://" + escapeURLPath(abs)
+}
+
+// escapeURLPath 逐段转义路径
+func escapeURLPath(path string) string {
+	parts := strings.Split(path, "/")
+	for i, part := range parts {
+		parts[i] = url.PathEscape(part)
+	}
+	return strings.Join(parts, "/")
+}
+
+var playerTemplate = template.Must(template.New("player").Parse(`<!DOCTYPE html>
+<html lang="zh-CN">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<style>
+body { margin: 0; font-family: -apple-system, "Segoe UI", "Microsoft YaHei", sans-serif; background: #f5f5f5; color: #222; }
+main { max-width: 960px; margin: 0 auto; padding: 16px; }
+h1 { font-size: 20px; margin: 8px 0 16px; }
+video, audio { width: 100%; background: #000; border-radius: 6px; }
+audio { background: transparent; }
+#transcript { margin-top: 16px; max-height: 50vh; overflow-y: auto; background: #fff; border-radius: 6px; padding: 8px; }
+.para { padding: 8px; border-radius: 4px; cursor: pointer; line-height: 1.6; }
+.para:hover { background: #eef3fb; }
+.para.active { background: #dbe8fb; }
+.time { color: #2a6ad4; font-family: monospace; margin-right: 8px; }
+.speaker { font-weight: bold; margin-right: 4px; }
+</style>
+</head>
+<body>
+<main>
+<h1>{{.Title}}</h1>
+{{if eq .Tag "audio"}}<audio id="media" controls preload="metadata"></audio>{{else}}<video id="media" controls preload="metadata"></video>{{end}}
+<div id="transcript"></div>
+</main>
+<script>
+const data = {{.Data}};
+const media = document.getElementById("media");
+const served = location.protocol === "http:" || location.protocol === "https:";
+
+media.src = served && data.served_media ? data.served_media : data.media_file;
+
+if (served && data.served_vtt) {
+	const track = document.createElement("track");
+	track.kind = "subtitles";
+	track.label = "字幕";
+	track.src = data.served_vtt;
+	track.default = true;
+	media.appendChild(track);
+} else if (media.addTextTrack && window.VTTCue) {
+	const track = media.addTextTrack("subtitles", "字幕");
+	data.subtitles.forEach(c => track.addCue(new VTTCue(c.start, c.end, c.text)));
+	track.mode = "showing";
+}
+
+const transcript = document.getElementById("transcript");
+const items = data.paragraphs.map(p => {
+	const div = document.createElement("div");
+	div.className = "para";
+	const time = document.createElement("span");
+	time.className = "time";
+	time.textContent = p.time;
+	div.appendChild(time);
+	if (p.speaker) {
+		const speaker = document.createElement("span");
+		speaker.className = "speaker";
+		speaker.textContent = p.speaker + ":";
+		div.appendChild(speaker);
+	}
+	div.appendChild(document.createTextNode(p.text));
+	div.addEventListener("click", () => { media.currentTime = p.start; media.play(); });
+	transcript.appendChild(div);
+	return div;
+});
+
+let current = -1;
+media.addEventListener("timeupdate", () => {
+	const t = media.currentTime;
+	const index = data.paragraphs.findIndex(p => t >= p.start && t < p.end);
+	if (index === current) return;
+	if (current >= 0) items[current].classList.remove("active");
+	current = index;
+	if (current >= 0) {
+		items[current].classList.add("active");
+		items[current].scrollIntoView({ block: "nearest" });
+	}
+});
+</script>
+</body>
+</html>
+`))
@@ -0,0 +1,45 @@
+package export
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/models"
+)
+
+// TestGeneratePlayerHTML 测试播放页以相对路径引用媒体目录下的文件，并转义文稿中的脚本
+func TestGeneratePlayerHTML(t *testing.T) {
+	root := t.TempDir()
+	output := filepath.Join(root, "output")
+	media := filepath.Join(root, "media")
+	exporter := NewPlayerExporter(output, media)
+
+	segments := []models.DataSegment{
+		{Text: "你好</script><script>alert(1)", StartTime: 1, EndTime: 2, Speaker: "A"},
+	}
+	content, err := exporter.GeneratePlayerHTML(segments, "demo video.mp3", filepath.Join(media, "demo video.mp4"), "demo.vtt", filepath.Join(output, "demo video"))
+	require.NoError(t, err)
+
+	html := string(content)
+	assert.Contains(t, html, `<video id="media"`)
+	assert.Contains(t, html, `../../media/demo%20video.mp4`)
+	assert.Contains(t, html, `"served_media":"media"`)
+	assert.Contains(t, html, `"served_vtt":"vtt"`)
+	assert.Equal(t, 1, strings.Count(html, "</script>"))
+}
+
+// TestMediaFileURL 测试媒体不在媒体目录下时使用绝对路径
+func TestMediaFileURL(t *testing.T) {
+	root := t.TempDir()
+	exporter := NewPlayerExporter(filepath.Join(root, "output"), filepath.Join(root, "media"))
+	upload := filepath.Join(root, "uploads", "a.mp4")
+
+	assert.False(t, exporter.ServesMedia(upload))
+	url := mediaFileURL(filepath.Join(root, "output", "a"), upload, exporter.MediaFolder)
+	assert.True(t, strings.HasPrefix(url, "file:///"))
+	assert.True(t, strings.HasSuffix(url, "/uploads/a.mp4"))
+}
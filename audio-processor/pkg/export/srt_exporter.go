@@ -4,9 +4,9 @@ import (
 	"fmt"
 	"math"
 	"os"
-	"path/filepath"
 	"strings"
 
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/audit"
 	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/models"
 	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/utils"
 )
@@ -14,6 +14,10 @@ import (
 // SRTExporter 负责将ASR结果导出为SRT字幕文件
 type SRTExporter struct {
 	OutputFolder string
+	Namer        *Namer // 输出命名规则，为 nil 时使用默认规则
+	Options      SRTOptions // 行长、行数与阅读速度限制
+	BOM          bool       // 文件开头写入 UTF-8 BOM
+	CRLF         bool       // 使用 Windows 换行 (\r\n)
 }
 
 // NewSRTExporter 创建一个新的SRT导出器
@@ -23,6 +27,12 @@ func NewSRTExporter(outputFolder string) *SRTExporter {
 	}
 }
 
+// ApplyPreset 按剪辑软件预设调整SRT编码，premiere 使用 UTF-8 BOM 与 CRLF 换行
+func (e *SRTExporter) ApplyPreset(preset string) {
+	e.BOM = preset == PresetPremiere
+	e.CRLF = preset == PresetPremiere
+}
+
 // FormatSRTTime 将秒数格式化为SRT时间格式 (HH:MM:SS,mmm)
 func (e *SRTExporter) FormatSRTTime(seconds float64) string {
 	hours := int(seconds / 3600)
@@ -33,11 +43,36 @@ func (e *SRTExporter) FormatSRTTime(seconds float64) string {
 	return fmt.Sprintf("%02d:%02d:%02d,%03d", hours, minutes, secs, milliseconds)
 }
 
-// GenerateSRTContent 生成SRT格式内容
+// GenerateSRTContent 生成SRT格式内容，超出排版限制的段落拆分为多条字幕
 func (e *SRTExporter) GenerateSRTContent(segments []models.DataSegment) string {
+	return e.formatCues(e.Options.buildCues(segments))
+}
+
+// GenerateBilingualSRTContent 生成双语SRT内容，每条字幕原文在上、译文在下。
+// translated 与 segments 按下标一一对应；双语字幕按段落输出，不按行数拆分
+func (e *SRTExporter) GenerateBilingualSRTContent(segments, translated []models.DataSegment) string {
+	return e.formatCues(e.Options.buildBilingualCues(segments, translated))
+}
+
+// formatCues 将字幕条目格式化为SRT内容
+func (e *SRTExporter) formatCues(cues []srtCue) string {
 	var srtLines []string
+	for i, cue := range cues {
+		// 添加序号、时间范围和文本
+		srtLines = append(srtLines, fmt.Sprintf("%d", i+1))
+		srtLines = append(srtLines, fmt.Sprintf("%s --> %s", e.FormatSRTTime(cue.Start), e.FormatSRTTime(cue.End)))
+		srtLines = append(srtLines, strings.Join(cue.Lines, "\n"))
+		srtLines = append(srtLines, "") // 空行分隔
+	}
 	
-	for i, segment := range segments {
+	return strings.Join(srtLines, "\n")
+}
+
+// buildCues 按排版限制将段落排成字幕条目，SRT 与 VTT 共用
+func (o SRTOptions) buildCues(segments []models.DataSegment) []srtCue {
+	var cues []srtCue
+	
+	for _, segment := range segments {
 		text := strings.TrimSpace(segment.Text)
 		if text == "" || text == "[无法识别的音频片段]" {
 			continue
@@ -51,51 +86,98 @@ func (e *SRTExporter) GenerateSRTContent(segments []models.DataSegment) string {
 			endTime = startTime + 5.0
 		}
 		
-		// 格式化SRT条目
-		srtStart := e.FormatSRTTime(startTime)
-		srtEnd := e.FormatSRTTime(endTime)
+		// 有说话人信息时在文本前标注
+		prefix := ""
+		if segment.Speaker != "" {
+			prefix = segment.Speaker + ": "
+		}
 		
-		// 添加序号、时间范围和文本
-		srtLines = append(srtLines, fmt.Sprintf("%d", i+1))
-		srtLines = append(srtLines, fmt.Sprintf("%s --> %s", srtStart, srtEnd))
-		srtLines = append(srtLines, text)
-		srtLines = append(srtLines, "") // 空行分隔
+		cues = append(cues, o.layoutSegment(segment, prefix, text, startTime, endTime)...)
 	}
-	
-	return strings.Join(srtLines, "\n")
+	o.applyCPS(cues)
+	return cues
+}
+
+// buildBilingualCues 每个段落排成一条字幕，原文与译文分别按行长换行
+func (o SRTOptions) buildBilingualCues(segments, translated []models.DataSegment) []srtCue {
+	var cues []srtCue
+	for i, segment := range segments {
+		text := strings.TrimSpace(segment.Text)
+		if text == "" || text == "[无法识别的音频片段]" {
+			continue
+		}
+		endTime := segment.EndTime
+		if endTime <= segment.StartTime {
+			endTime = segment.StartTime + 5.0
+		}
+		prefix := ""
+		if segment.Speaker != "" {
+			prefix = segment.Speaker + ": "
+		}
+
+		lines := wrapText(prefix+text, o.MaxLineChars)
+		if i < len(translated) {
+			if translation := strings.TrimSpace(translated[i].Text); translation != "" {
+				lines = append(lines, wrapText(translation, o.MaxLineChars)...)
+			}
+		}
+		cues = append(cues, srtCue{Start: segment.StartTime, End: endTime, Lines: lines})
+	}
+	return cues
 }
 
 // ExportSRT 导出SRT格式字幕文件
 func (e *SRTExporter) ExportSRT(segments []models.DataSegment, filename string, partNum *int) (string, error) {
-	// 创建输出文件夹
-	if err := os.MkdirAll(e.OutputFolder, 0755); err != nil {
-		return "", fmt.Errorf("创建输出目录失败: %w", err)
+	// 按命名模板确定输出路径
+	outputFile, err := e.Namer.Path(e.OutputFolder, filename, partNum, "srt", "")
+	if err != nil {
+		return "", err
 	}
 	
-	// 构建文件名
-	baseName := filepath.Base(filename)
-	baseName = strings.TrimSuffix(baseName, filepath.Ext(baseName))
+	// 生成SRT内容
+	srtContent := e.GenerateSRTContent(segments)
+	if err := e.writeSRT(outputFile, srtContent, "重新生成SRT字幕"); err != nil {
+		return "", err
+	}
 	
-	var outputFile string
-	if partNum != nil {
-		// 创建子文件夹
-		outputSubfolder := filepath.Join(e.OutputFolder, baseName)
-		if err := os.MkdirAll(outputSubfolder, 0755); err != nil {
-			return "", fmt.Errorf("创建子目录失败: %w", err)
-		}
-		outputFile = filepath.Join(outputSubfolder, fmt.Sprintf("%s_part%d.srt", baseName, *partNum))
+	utils.Info("已导出SRT字幕: %s", outputFile)
+	return outputFile, nil
+}
+
+// ExportTranslatedSRT 导出译文字幕 <文件名>.<语言>.srt，bilingual 时为原文与译文对照的双语字幕
+func (e *SRTExporter) ExportTranslatedSRT(segments, translated []models.DataSegment, filename string, partNum *int, language string, bilingual bool) (string, error) {
+	outputFile, err := e.Namer.Path(e.OutputFolder, filename, partNum, "srt", "."+language)
+	if err != nil {
+		return "", err
+	}
+	
+	var srtContent string
+	if bilingual {
+		srtContent = e.GenerateBilingualSRTContent(segments, translated)
 	} else {
-		outputFile = filepath.Join(e.OutputFolder, fmt.Sprintf("%s.srt", baseName))
+		srtContent = e.GenerateSRTContent(translated)
+	}
+	if err := e.writeSRT(outputFile, srtContent, "重新生成译文字幕"); err != nil {
+		return "", err
 	}
 	
-	// 生成SRT内容
-	srtContent := e.GenerateSRTContent(segments)
+	utils.Info("已导出译文字幕: %s", outputFile)
+	return outputFile, nil
+}
+
+// writeSRT 按编码设置写入SRT文件
+func (e *SRTExporter) writeSRT(outputFile, srtContent, reason string) error {
+	if e.CRLF {
+		srtContent = strings.ReplaceAll(srtContent, "\n", "\r\n")
+	}
+	if e.BOM {
+		srtContent = "\ufeff" + srtContent
+	}
 	
 	// 写入文件
+	audit.RecordOverwrite(outputFile, "export", reason)
 	if err := os.WriteFile(outputFile, []byte(srtContent), 0644); err != nil {
-		return "", fmt.Errorf("写入SRT文件失败: %w", err)
+		return fmt.Errorf("写入SRT文件失败: %w", err)
 	}
-	
-	utils.Info("已导出SRT字幕: %s", outputFile)
-	return outputFile, nil
+	return nil
 }
@@ -0,0 +1,93 @@
+package export
+
+import (
+	"testing"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateSRTContentSplitsLongSegments(t *testing.T) {
+	exporter := NewSRTExporter(t.TempDir())
+	exporter.Options = SRTOptions{MaxLineChars: 8, MaxLines: 1}
+
+	segments := []models.DataSegment{
+		{Text: "今天天气很好，我们去公园散步吧", StartTime: 10, EndTime: 25},
+		{Text: "  ", StartTime: 30, EndTime: 31},
+		{Text: "好的", StartTime: 40, EndTime: 41, Speaker: "B"},
+	}
+
+	content := exporter.GenerateSRTContent(segments)
+
+	expected := "1\n00:00:10,000 --> 00:00:17,000\n今天天气很好，\n\n" +
+		"2\n00:00:17,000 --> 00:00:25,000\n我们去公园散步吧\n\n" +
+		"3\n00:00:40,000 --> 00:00:41,000\nB: 好的\n"
+	assert.Equal(t, expected, content)
+}
+
+func TestLayoutSegmentUsesWordTimings(t *testing.T) {
+	options := SRTOptions{MaxLineChars: 2, MaxLines: 2}
+	segment := models.DataSegment{
+		Text:      "一二三四五六",
+		StartTime: 0,
+		EndTime:   6,
+		Words: []models.WordTiming{
+			{Text: "一二三四", StartTime: 0, EndTime: 1},
+			{Text: "五六", StartTime: 5, EndTime: 6},
+		},
+	}
+
+	cues := options.layoutSegment(segment, "", segment.Text, 0, 6)
+
+	assert.Len(t, cues, 2)
+	assert.Equal(t, []string{"一二", "三四"}, cues[0].Lines)
+	assert.Equal(t, 0.0, cues[0].Start)
+	assert.Equal(t, 5.0, cues[0].End)
+	assert.Equal(t, []string{"五六"}, cues[1].Lines)
+	assert.Equal(t, 5.0, cues[1].Start)
+	assert.Equal(t, 6.0, cues[1].End)
+}
+
+func TestWrapTextPrefersPunctuationAndSpaces(t *testing.T) {
+	assert.Equal(t, []string{"hello world", "again"}, wrapText("hello world again", 12))
+	assert.Equal(t, []string{"你好，", "世界和平"}, wrapText("你好，世界和平", 5))
+	assert.Equal(t, []string{"abcdef", "gh"}, wrapText("abcdefgh", 6))
+	assert.Equal(t, []string{"不换行的文本"}, wrapText("不换行的文本", 0))
+}
+
+func TestApplyCPSExtendsWithoutOverlap(t *testing.T) {
+	options := SRTOptions{MaxCPS: 2}
+	cues := []srtCue{
+		{Start: 0, End: 1, Lines: []string{"一二三四"}},
+		{Start: 1.5, End: 2, Lines: []string{"五"}},
+		{Start: 10, End: 11, Lines: []string{"六七八九十"}},
+	}
+
+	options.applyCPS(cues)
+
+	assert.Equal(t, 1.5, cues[0].End)
+	assert.Equal(t, 2.0, cues[1].End)
+	assert.Equal(t, 12.5, cues[2].End)
+}
+
+func TestGenerateBilingualSRTContent(t *testing.T) {
+	exporter := NewSRTExporter(t.TempDir())
+	exporter.Options = SRTOptions{MaxLineChars: 20, MaxLines: 1}
+
+	segments := []models.DataSegment{
+		{Text: "你好", StartTime: 1, EndTime: 2, Speaker: "A"},
+		{Text: "[无法识别的音频片段]", StartTime: 3, EndTime: 4},
+		{Text: "今天开会", StartTime: 5, EndTime: 5},
+	}
+	translated := []models.DataSegment{
+		{Text: "Hello", StartTime: 1, EndTime: 2, Speaker: "A"},
+		{Text: "[无法识别的音频片段]", StartTime: 3, EndTime: 4},
+		{Text: "We have a meeting today", StartTime: 5, EndTime: 5},
+	}
+
+	content := exporter.GenerateBilingualSRTContent(segments, translated)
+
+	expected := "1\n00:00:01,000 --> 00:00:02,000\nA: 你好\nHello\n\n" +
+		"2\n00:00:05,000 --> 00:00:10,000\n今天开会\nWe have a meeting\ntoday\n"
+	assert.Equal(t, expected, content)
+}
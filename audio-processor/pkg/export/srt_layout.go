@@ -0,0 +1,177 @@
+package export
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/models"
+)
+
+// 适合在其后换行的标点
+const lineBreakPunctuation = "，。！？；：、,.!?;:…）)》」』”’"
+
+// SRTOptions 字幕排版限制，各项为 0 时不限制。
+// 字符数按 Unicode 字符计算（中英文均计 1），不含空白。
+type SRTOptions struct {
+	MaxLineChars int     // 每行最大字符数，超出时换行
+	MaxLines     int     // 每条字幕最大行数，超出时拆分为多条字幕
+	MaxCPS       float64 // 最大阅读速度（每秒字符数），超出时在不与下一条重叠的前提下延长显示时间
+}
+
+// SRTOptionsFromConfig 从配置读取字幕排版限制
+func SRTOptionsFromConfig(config *models.Config) SRTOptions {
+	return SRTOptions{
+		MaxLineChars: config.SRTMaxLineChars,
+		MaxLines:     config.SRTMaxLines,
+		MaxCPS:       config.SRTMaxCPS,
+	}
+}
+
+// srtCue 一条字幕
+type srtCue struct {
+	Start float64
+	End   float64
+	Lines []string
+}
+
+// layoutSegment 按行长与行数限制将段落拆分为多条字幕。
+// 各条字幕的时间按字符位置插值，段落带有逐词时间戳时按词的时间插值。
+// prefix 为说话人标注，计入行长但不参与时间插值。
+func (o SRTOptions) layoutSegment(segment models.DataSegment, prefix, text string, start, end float64) []srtCue {
+	lines := wrapText(prefix+text, o.MaxLineChars)
+	perCue := o.MaxLines
+	if perCue <= 0 {
+		perCue = len(lines)
+	}
+
+	timeline := newCueTimeline(segment, text, start, end)
+	skip := countChars(prefix)
+
+	cues := make([]srtCue, 0, (len(lines)+perCue-1)/perCue)
+	offset := 0
+	for i := 0; i < len(lines); i += perCue {
+		group := lines[i:min(i+perCue, len(lines))]
+		n := countChars(group...)
+		cues = append(cues, srtCue{
+			Start: timeline.at(offset - skip),
+			End:   timeline.at(offset + n - skip),
+			Lines: group,
+		})
+		offset += n
+	}
+	return cues
+}
+
+// applyCPS 延长阅读速度超限的字幕，不会与下一条字幕重叠
+func (o SRTOptions) applyCPS(cues []srtCue) {
+	if o.MaxCPS <= 0 {
+		return
+	}
+	for i := range cues {
+		need := float64(countChars(cues[i].Lines...)) / o.MaxCPS
+		if cues[i].End-cues[i].Start >= need {
+			continue
+		}
+		end := cues[i].Start + need
+		if i+1 < len(cues) && end > cues[i+1].Start {
+			end = max(cues[i+1].Start, cues[i].End)
+		}
+		cues[i].End = end
+	}
+}
+
+// cueTimeline 将段落中第 k 个字符映射到时间
+type cueTimeline struct {
+	start float64
+	end   float64
+	total int
+	times []float64 // 由逐词时间戳得到的各字符开始时间，长度为 total+1；为 nil 时线性插值
+}
+
+// newCueTimeline 创建段落的字符时间轴，逐词时间戳与文本对不上时退回线性插值
+func newCueTimeline(segment models.DataSegment, text string, start, end float64) cueTimeline {
+	timeline := cueTimeline{start: start, end: end, total: countChars(text)}
+	if !segment.HasWordTimings() {
+		return timeline
+	}
+
+	times := make([]float64, 0, timeline.total+1)
+	for _, word := range segment.Words {
+		n := countChars(word.Text)
+		for j := 0; j < n; j++ {
+			times = append(times, word.StartTime+(word.EndTime-word.StartTime)*float64(j)/float64(n))
+		}
+	}
+	if len(times) != timeline.total {
+		return timeline
+	}
+	timeline.times = append(times, segment.Words[len(segment.Words)-1].EndTime)
+	return timeline
+}
+
+// at 返回第 k 个字符开始的时间
+func (t cueTimeline) at(k int) float64 {
+	switch {
+	case k <= 0:
+		return t.start
+	case k >= t.total:
+		return t.end
+	case t.times != nil:
+		return t.times[k]
+	}
+	return t.start + (t.end-t.start)*float64(k)/float64(t.total)
+}
+
+// wrapText 按每行最大字符数换行，优先在标点后或空格处断开，limit 不大于 0 时不换行
+func wrapText(text string, limit int) []string {
+	text = strings.TrimSpace(text)
+	if limit <= 0 {
+		return []string{text}
+	}
+
+	var lines []string
+	runes := []rune(text)
+	for len(runes) > limit {
+		cut := lineBreakPoint(runes, limit)
+		if line := strings.TrimSpace(string(runes[:cut])); line != "" {
+			lines = append(lines, line)
+		}
+		runes = []rune(strings.TrimLeftFunc(string(runes[cut:]), unicode.IsSpace))
+	}
+	if rest := strings.TrimSpace(string(runes)); rest != "" {
+		lines = append(lines, rest)
+	}
+	return lines
+}
+
+// lineBreakPoint 在前 limit 个字符内寻找断行位置：优先在标点后，其次在空格处；
+// 后半行内都没有合适位置时直接在 limit 处截断
+func lineBreakPoint(runes []rune, limit int) int {
+	if unicode.IsSpace(runes[limit]) {
+		return limit
+	}
+	for i := limit; i > limit/2; i-- {
+		if strings.ContainsRune(lineBreakPunctuation, runes[i-1]) {
+			return i
+		}
+	}
+	for i := limit; i > limit/2; i-- {
+		if unicode.IsSpace(runes[i-1]) {
+			return i
+		}
+	}
+	return limit
+}
+
+// countChars 统计非空白字符数
+func countChars(texts ...string) int {
+	n := 0
+	for _, text := range texts {
+		for _, r := range text {
+			if !unicode.IsSpace(r) {
+				n++
+			}
+		}
+	}
+	return n
+}
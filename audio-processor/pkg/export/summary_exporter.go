@@ -0,0 +1,42 @@
+package export
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/audit"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/summary"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/utils"
+)
+
+// SummaryExporter 将大模型生成的摘要与会议纪要导出为 Markdown
+type SummaryExporter struct {
+	OutputFolder string
+	Namer        *Namer // 输出命名规则，为 nil 时使用默认规则
+}
+
+// NewSummaryExporter 创建一个新的摘要导出器
+func NewSummaryExporter(outputFolder string) *SummaryExporter {
+	return &SummaryExporter{
+		OutputFolder: outputFolder,
+	}
+}
+
+// ExportSummary 导出摘要或会议纪要，文件名为 <文件名>_summary.md
+func (e *SummaryExporter) ExportSummary(minutes *summary.Minutes, filename string) (string, error) {
+	outputFile, err := e.Namer.Path(e.OutputFolder, filename, nil, "md", "_summary")
+	if err != nil {
+		return "", err
+	}
+
+	title := strings.TrimSuffix(filepath.Base(filename), filepath.Ext(filename))
+	audit.RecordOverwrite(outputFile, "export", "重新生成摘要")
+	if err := os.WriteFile(outputFile, []byte(minutes.Markdown(title)), 0644); err != nil {
+		return "", fmt.Errorf("写入摘要失败: %w", err)
+	}
+
+	utils.Info("已导出摘要: %s", outputFile)
+	return outputFile, nil
+}
@@ -0,0 +1,315 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/audit"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/models"
+)
+
+// TagsFileName 标签库文件名，位于输出目录下。标签单独保存，重新识别或重新生成清单时不会丢失
+const TagsFileName = "tags.json"
+
+// ItemMeta 用户为一个已处理文件添加的标签与备注
+type ItemMeta struct {
+	Tags      []string `json:"tags,omitempty"`
+	Note      string   `json:"note,omitempty"`
+	UpdatedAt string   `json:"updated_at,omitempty"`
+}
+
+// Empty 判断是否没有任何标签与备注
+func (m ItemMeta) Empty() bool {
+	return len(m.Tags) == 0 && m.Note == ""
+}
+
+// HasTag 判断是否带有指定标签
+func (m ItemMeta) HasTag(tag string) bool {
+	tag = normalizeTag(tag)
+	for _, t := range m.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// MetaUpdate 对标签与备注的修改，Note 为 nil 时不修改备注
+type MetaUpdate struct {
+	Add    []string `json:"add,omitempty"`
+	Remove []string `json:"remove,omitempty"`
+	Note   *string  `json:"note,omitempty"`
+}
+
+// TagStore 以文件名（不含扩展名，与输出清单一致）为键保存标签与备注
+type TagStore struct {
+	path string
+}
+
+// 同一进程内对标签库的读改写互斥；写入通过临时文件替换，其他进程不会读到写了一半的文件
+var tagStoreMu sync.Mutex
+
+// OpenTagStore 返回输出目录下的标签库
+func OpenTagStore(outputFolder string) *TagStore {
+	return &TagStore{path: filepath.Join(outputFolder, TagsFileName)}
+}
+
+// ItemName 返回文件在标签库与输出清单中的名称
+func ItemName(filename string) string {
+	baseName := filepath.Base(filename)
+	return strings.TrimSuffix(baseName, filepath.Ext(baseName))
+}
+
+// Load 读取全部标签，标签库不存在时返回空
+func (s *TagStore) Load() (map[string]ItemMeta, error) {
+	items := make(map[string]ItemMeta)
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return items, nil
+		}
+		return nil, fmt.Errorf("读取标签库失败: %w", err)
+	}
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, fmt.Errorf("解析标签库失败 %s: %w", s.path, err)
+	}
+	return items, nil
+}
+
+// Get 返回文件的标签与备注
+func (s *TagStore) Get(filename string) (ItemMeta, error) {
+	items, err := s.Load()
+	if err != nil {
+		return ItemMeta{}, err
+	}
+	return items[ItemName(filename)], nil
+}
+
+// Update 修改文件的标签与备注并保存，返回修改后的结果
+func (s *TagStore) Update(filename string, update MetaUpdate) (ItemMeta, error) {
+	tagStoreMu.Lock()
+	defer tagStoreMu.Unlock()
+
+	items, err := s.Load()
+	if err != nil {
+		return ItemMeta{}, err
+	}
+	name := ItemName(filename)
+	meta := items[name]
+
+	tags := make(map[string]bool)
+	for _, tag := range meta.Tags {
+		tags[tag] = true
+	}
+	for _, tag := range update.Add {
+		if tag = normalizeTag(tag); tag != "" {
+			tags[tag] = true
+		}
+	}
+	for _, tag := range update.Remove {
+		delete(tags, normalizeTag(tag))
+	}
+	meta.Tags = meta.Tags[:0]
+	for tag := range tags {
+		meta.Tags = append(meta.Tags, tag)
+	}
+	sort.Strings(meta.Tags)
+	if update.Note != nil {
+		meta.Note = strings.TrimSpace(*update.Note)
+	}
+	meta.UpdatedAt = time.Now().Format("2006-01-02 15:04:05")
+
+	if meta.Empty() {
+		delete(items, name)
+	} else {
+		items[name] = meta
+	}
+	if err := s.save(items); err != nil {
+		return ItemMeta{}, err
+	}
+	return meta, nil
+}
+
+// save 写入临时文件后替换标签库
+func (s *TagStore) save(items map[string]ItemMeta) error {
+	data, err := json.MarshalIndent(items, "", "  ")
+	if err != nil {
+		return fmt.Errorf("编码标签库失败: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("创建输出目录失败: %w", err)
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("写入标签库失败: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("写入标签库失败: %w", err)
+	}
+	return nil
+}
+
+// ApplyTags 为清单填入标签库中的标签，tag 不为空时只保留带有该标签的清单
+func ApplyTags(manifests []*OutputManifest, outputFolder, tag string) ([]*OutputManifest, error) {
+	items, err := OpenTagStore(outputFolder).Load()
+	if err != nil {
+		return nil, err
+	}
+	var result []*OutputManifest
+	for _, manifest := range manifests {
+		meta := items[manifest.Name]
+		if tag != "" && !meta.HasTag(tag) {
+			continue
+		}
+		manifest.Tags = meta.Tags
+		result = append(result, manifest)
+	}
+	return result, nil
+}
+
+// normalizeTag 去掉标签两端的空白与开头的 #
+func normalizeTag(tag string) string {
+	return strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(tag), "#"))
+}
+
+// Frontmatter 返回 Markdown 的 YAML 头，没有标签与备注时为空
+func Frontmatter(title string, meta ItemMeta) string {
+	if meta.Empty() {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("---\n")
+	fmt.Fprintf(&b, "title: %s\n", yamlString(title))
+	if len(meta.Tags) > 0 {
+		quoted := make([]string, len(meta.Tags))
+		for i, tag := range meta.Tags {
+			quoted[i] = yamlString(tag)
+		}
+		fmt.Fprintf(&b, "tags: [%s]\n", strings.Join(quoted, ", "))
+	}
+	if meta.Note != "" {
+		fmt.Fprintf(&b, "note: %s\n", yamlString(meta.Note))
+	}
+	b.WriteString("---\n\n")
+	return b.String()
+}
+
+// yamlString 以 JSON 字符串形式输出，是合法的 YAML 双引号字符串
+func yamlString(s string) string {
+	data, _ := json.Marshal(s)
+	return string(data)
+}
+
+// stripFrontmatter 去掉内容开头已有的 YAML 头
+func stripFrontmatter(content string) string {
+	if !strings.HasPrefix(content, "---\n") {
+		return content
+	}
+	end := strings.Index(content[4:], "\n---\n")
+	if end < 0 {
+		return content
+	}
+	return strings.TrimLeft(content[4+end+5:], "\n")
+}
+
+// SyncFrontmatter 将标签库中的标签与备注写入文件 Markdown 输出的 YAML 头，并更新输出清单。
+// 文件没有输出清单或 Markdown 输出时不做任何事
+func SyncFrontmatter(config *models.Config, filename string) error {
+	manifestPath := ManifestPath(config.OutputFolder, filename)
+	if _, err := os.Stat(manifestPath); err != nil {
+		return nil
+	}
+	manifest, err := LoadManifest(manifestPath)
+	if err != nil {
+		return err
+	}
+	entry, ok := manifest.Output("md")
+	if !ok {
+		return nil
+	}
+	meta, err := OpenTagStore(config.OutputFolder).Get(filename)
+	if err != nil {
+		return err
+	}
+
+	path := entry.Resolve(config.OutputFolder, config.MediaFolder)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("读取Markdown文件失败: %w", err)
+	}
+	content := Frontmatter(manifest.Name, meta) + stripFrontmatter(string(data))
+	if content == string(data) {
+		return nil
+	}
+	audit.RecordOverwrite(path, "tag", "更新Markdown文件的标签")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		return fmt.Errorf("写入Markdown文件失败: %w", err)
+	}
+	_, err = LinkOutput(config, filename, "md", path)
+	return err
+}
+
+// TagsHandler 提供标签的HTTP接口：
+//
+//	GET  <prefix>          全部文件的标签与备注
+//	GET  <prefix><name>    单个文件的标签与备注
+//	POST <prefix><name>    修改标签与备注，请求体为 MetaUpdate
+func TagsHandler(config *models.Config, prefix string) http.Handler {
+	store := OpenTagStore(config.OutputFolder)
+	return http.StripPrefix(prefix, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := strings.Trim(r.URL.Path, "/")
+		if strings.Contains(name, "/") || name == "." || name == ".." {
+			http.NotFound(w, r)
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet:
+			if name == "" {
+				items, err := store.Load()
+				if err != nil {
+					http.Error(w, err.Error(), http.StatusInternalServerError)
+					return
+				}
+				writeManifestJSON(w, items)
+				return
+			}
+			meta, err := store.Get(name)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			writeManifestJSON(w, meta)
+		case http.MethodPost, http.MethodPatch:
+			if name == "" {
+				http.Error(w, "缺少文件名", http.StatusBadRequest)
+				return
+			}
+			var update MetaUpdate
+			if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+				http.Error(w, fmt.Sprintf("请求格式错误: %v", err), http.StatusBadRequest)
+				return
+			}
+			meta, err := store.Update(name, update)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			if err := SyncFrontmatter(config, name); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			writeManifestJSON(w, meta)
+		default:
+			http.Error(w, "只支持GET与POST请求", http.StatusMethodNotAllowed)
+		}
+	}))
+}
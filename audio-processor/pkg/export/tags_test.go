@@ -0,0 +1,88 @@
+package export
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTagStoreUpdate(t *testing.T) {
+	store := OpenTagStore(t.TempDir())
+
+	meta, err := store.Update("/videos/demo.mp4", MetaUpdate{Add: []string{"golang", "#course", " "}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"course", "golang"}, meta.Tags)
+
+	note := "第二讲"
+	meta, err = store.Update("demo", MetaUpdate{Remove: []string{"golang"}, Note: &note})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"course"}, meta.Tags)
+	assert.Equal(t, "第二讲", meta.Note)
+
+	meta, err = store.Get("demo.mp3")
+	require.NoError(t, err)
+	assert.True(t, meta.HasTag("#course"))
+
+	// 标签与备注全部删除后不再保留记录
+	empty := ""
+	_, err = store.Update("demo", MetaUpdate{Remove: []string{"course"}, Note: &empty})
+	require.NoError(t, err)
+	items, err := store.Load()
+	require.NoError(t, err)
+	assert.Empty(t, items)
+}
+
+func TestSyncFrontmatter(t *testing.T) {
+	dir := t.TempDir()
+	config := &models.Config{
+		OutputFolder: filepath.Join(dir, "output"),
+		MediaFolder:  filepath.Join(dir, "media"),
+	}
+	md := filepath.Join(config.OutputFolder, "demo.md")
+	require.NoError(t, os.MkdirAll(config.OutputFolder, 0755))
+	require.NoError(t, os.WriteFile(md, []byte("# demo\n\n正文\n"), 0644))
+	_, err := WriteManifest(config, "demo.mp3", map[string]string{"md": md})
+	require.NoError(t, err)
+
+	// HTTP接口添加标签后写入Markdown的YAML头
+	handler := TagsHandler(config, "/api/tags/")
+	rec := httptest.NewRecorder()
+	body := strings.NewReader(`{"add": ["course", "golang"], "note": "第二讲"}`)
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/tags/demo", body))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	data, err := os.ReadFile(md)
+	require.NoError(t, err)
+	assert.Equal(t, "---\ntitle: \"demo\"\ntags: [\"course\", \"golang\"]\nnote: \"第二讲\"\n---\n\n# demo\n\n正文\n", string(data))
+
+	// 再次同步时替换已有的YAML头
+	_, err = OpenTagStore(config.OutputFolder).Update("demo", MetaUpdate{Remove: []string{"golang"}})
+	require.NoError(t, err)
+	require.NoError(t, SyncFrontmatter(config, "demo"))
+	data, err = os.ReadFile(md)
+	require.NoError(t, err)
+	assert.Equal(t, "---\ntitle: \"demo\"\ntags: [\"course\"]\nnote: \"第二讲\"\n---\n\n# demo\n\n正文\n", string(data))
+
+	// 清单的校验值随之更新，列表可按标签筛选
+	manifest, err := LoadManifest(ManifestPath(config.OutputFolder, "demo"))
+	require.NoError(t, err)
+	entry, _ := manifest.Output("md")
+	assert.Equal(t, int64(len(data)), entry.Size)
+
+	outputs := ManifestHandler(config.OutputFolder, config.MediaFolder, "/api/outputs/")
+	for tag, count := range map[string]int{"course": 1, "golang": 0} {
+		rec = httptest.NewRecorder()
+		outputs.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/outputs/?tag="+tag, nil))
+		var list []OutputManifest
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &list))
+		assert.Len(t, list, count, tag)
+	}
+}
@@ -0,0 +1,74 @@
+package export
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/audit"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/models"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/utils"
+)
+
+// VTTExporter 负责将ASR结果导出为WebVTT字幕文件，供浏览器 <track> 使用，排版规则与SRT相同
+type VTTExporter struct {
+	OutputFolder string
+	Namer        *Namer     // 输出命名规则，为 nil 时使用默认规则
+	Options      SRTOptions // 行长、行数与阅读速度限制
+}
+
+// NewVTTExporter 创建一个新的VTT导出器
+func NewVTTExporter(outputFolder string) *VTTExporter {
+	return &VTTExporter{
+		OutputFolder: outputFolder,
+	}
+}
+
+// FormatVTTTime 将秒数格式化为VTT时间格式 (HH:MM:SS.mmm)
+func (e *VTTExporter) FormatVTTTime(seconds float64) string {
+	if seconds < 0 {
+		seconds = 0
+	}
+	millis := int(seconds*1000 + 0.5)
+	return fmt.Sprintf("%02d:%02d:%02d.%03d", millis/3600000, millis/60000%60, millis/1000%60, millis%1000)
+}
+
+// GenerateVTTContent 生成VTT格式内容
+func (e *VTTExporter) GenerateVTTContent(segments []models.DataSegment) string {
+	var b strings.Builder
+	b.WriteString("WEBVTT\n\n")
+	for i, cue := range e.Options.buildCues(segments) {
+		fmt.Fprintf(&b, "%d\n%s --> %s\n", i+1, e.FormatVTTTime(cue.Start), e.FormatVTTTime(cue.End))
+		for _, line := range cue.Lines {
+			b.WriteString(escapeVTT(line))
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// vttEscaper VTT文本中有特殊含义的字符
+var vttEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// escapeVTT 转义VTT文本，避免被解析为标签
+func escapeVTT(text string) string {
+	return vttEscaper.Replace(text)
+}
+
+// ExportVTT 导出VTT格式字幕文件
+func (e *VTTExporter) ExportVTT(segments []models.DataSegment, filename string, partNum *int) (string, error) {
+	// 按命名模板确定输出路径
+	outputFile, err := e.Namer.Path(e.OutputFolder, filename, partNum, "vtt", "")
+	if err != nil {
+		return "", err
+	}
+
+	audit.RecordOverwrite(outputFile, "export", "重新生成VTT字幕")
+	if err := os.WriteFile(outputFile, []byte(e.GenerateVTTContent(segments)), 0644); err != nil {
+		return "", fmt.Errorf("写入VTT文件失败: %w", err)
+	}
+
+	utils.Info("已导出VTT字幕: %s", outputFile)
+	return outputFile, nil
+}
@@ -0,0 +1,21 @@
+package export
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/models"
+)
+
+// TestGenerateVTTContent 测试VTT文件头、时间格式与特殊字符转义
+func TestGenerateVTTContent(t *testing.T) {
+	exporter := NewVTTExporter(t.TempDir())
+	assert.Equal(t, "01:02:03.450", exporter.FormatVTTTime(3723.45))
+
+	content := exporter.GenerateVTTContent([]models.DataSegment{
+		{Text: "a < b & c", StartTime: 0, EndTime: 1.5},
+		{Text: "[无法识别的音频片段]", StartTime: 2, EndTime: 3},
+	})
+	assert.Equal(t, "WEBVTT\n\n1\n00:00:00.000 --> 00:00:01.500\na &lt; b &amp; c\n\n", content)
+}
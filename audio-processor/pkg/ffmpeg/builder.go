@@ -0,0 +1,209 @@
+package ffmpeg
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// FFmpeg 以任意参数创建 ffmpeg 命令
+func FFmpeg(args ...string) *Command {
+	return &Command{Program: "ffmpeg", Args: args}
+}
+
+// FFprobe 以任意参数创建 ffprobe 命令
+func FFprobe(args ...string) *Command {
+	return &Command{Program: "ffprobe", Args: args}
+}
+
+// seconds 将秒数格式化为 ffmpeg 时间参数，整数秒不带小数
+func seconds(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+// AllTracks 提取全部音频流，而不是其中一条
+const AllTracks = -1
+
+// ExtractOptions 提取音频时的可选设置
+type ExtractOptions struct {
+	Filter string  // 滤镜链（见 AudioFilters.Chain），为空时不处理
+	Track  int     // 音频流序号（从 0 开始，对应 -map 0:a:N），AllTracks 表示全部
+	Start  float64 // 起始时间（秒），0 表示从头开始
+	End    float64 // 结束时间（秒），0 表示到结尾
+}
+
+// ExtractAudio 从视频中提取音频为 output（按扩展名选择格式），覆盖已有文件
+func ExtractAudio(input, output string, opts ExtractOptions) *Command {
+	var args []string
+	if opts.Start > 0 {
+		args = append(args, "-ss", seconds(opts.Start))
+	}
+	args = append(args, "-i", input, "-q:a", "0")
+	if opts.Track == AllTracks {
+		args = append(args, "-map", "a")
+	} else {
+		args = append(args, "-map", fmt.Sprintf("0:a:%d", opts.Track))
+	}
+	// 输入端跳转后时间戳从 0 开始，结束时间换算为时长
+	if opts.End > opts.Start {
+		args = append(args, "-t", seconds(opts.End-opts.Start))
+	}
+	if opts.Filter != "" {
+		args = append(args, "-af", opts.Filter)
+	}
+	return FFmpeg(append(args, output, "-y")...)
+}
+
+// ExtractSegment 截取 [start, end) 秒的音频，转为 16kHz 单声道
+func ExtractSegment(input, output string, start, end float64) *Command {
+	return FFmpeg(
+		"-y",
+		"-i", input,
+		"-ss", seconds(start),
+		"-to", seconds(end),
+		"-ac", "1",
+		"-ar", "16000",
+		output,
+	)
+}
+
+// hlsProtocols 读取 HLS 播放列表时允许的协议，本地播放列表引用远程分片也需要 http/https
+const hlsProtocols = "file,http,https,tcp,tls,crypto,data"
+
+// DownloadHLS 下载 HLS 播放列表（本地文件或 URL）的全部分片，将第一条音频流不经转码拼接为 output（如 .ts）
+func DownloadHLS(input, output string) *Command {
+	return FFmpeg(
+		"-protocol_whitelist", hlsProtocols,
+		"-i", input,
+		"-map", "0:a:0",
+		"-c", "copy",
+		"-y",
+		output,
+	)
+}
+
+// Downsample 将第一条音频流压缩为 16kHz 单声道、码率 bitrate kbps 的音频，.opus/.ogg 使用 Opus 编码，其余使用 MP3
+func Downsample(input, output string, bitrate int) *Command {
+	codec := "libmp3lame"
+	switch strings.ToLower(filepath.Ext(output)) {
+	case ".opus", ".ogg":
+		codec = "libopus"
+	}
+	return FFmpeg(
+		"-v", "error",
+		"-i", input,
+		"-map", "0:a:0",
+		"-ac", "1",
+		"-ar", "16000",
+		"-c:a", codec,
+		"-b:a", fmt.Sprintf("%dk", bitrate),
+		"-y",
+		output,
+	)
+}
+
+// Sample 截取开头 length 秒的音频，转为 16kHz 单声道
+func Sample(input, output string, length float64) *Command {
+	return FFmpeg(
+		"-i", input,
+		"-t", seconds(length),
+		"-ac", "1",
+		"-ar", "16000",
+		"-y",
+		output,
+	)
+}
+
+// DecodePCM 将从 start 秒开始、最长 length 秒（不大于 0 时到结尾）的音频解码为单声道 s16le PCM 写入 stdout
+func DecodePCM(input string, start, length float64, sampleRate int, stdout io.Writer) *Command {
+	args := []string{"-v", "error", "-ss", seconds(start)}
+	if length > 0 {
+		args = append(args, "-t", seconds(length))
+	}
+	cmd := FFmpeg(append(args,
+		"-i", input,
+		"-ac", "1",
+		"-ar", strconv.Itoa(sampleRate),
+		"-f", "s16le", "-",
+	)...)
+	cmd.Stdout = stdout
+	return cmd
+}
+
+// Thumbnail 从 at 秒处开始的若干帧中选出最有代表性的一帧，缩放为宽 width 像素的图片
+func Thumbnail(input, output string, at float64, width int) *Command {
+	return FFmpeg(
+		"-v", "error",
+		"-ss", seconds(at),
+		"-i", input,
+		"-vf", fmt.Sprintf("thumbnail=50,scale=%d:-2", width),
+		"-frames:v", "1",
+		"-y",
+		output,
+	)
+}
+
+// WaveformImage 将第一条音频流绘制为 width×height 的波形图
+func WaveformImage(input, output string, width, height int) *Command {
+	return FFmpeg(
+		"-v", "error",
+		"-i", input,
+		"-filter_complex", fmt.Sprintf("[0:a:0]aformat=channel_layouts=mono,showwavespic=s=%dx%d:colors=0x4a90d9", width, height),
+		"-frames:v", "1",
+		"-y",
+		output,
+	)
+}
+
+// VolumeDetect 用 volumedetect 滤镜统计第一条音频流的音量，统计结果打印在 stderr
+func VolumeDetect(input string) *Command {
+	return FFmpeg(
+		"-hide_banner", "-nostats",
+		"-i", input,
+		"-map", "0:a:0",
+		"-af", "volumedetect",
+		"-f", "null", "-",
+	)
+}
+
+// Duration 使用 ffprobe 获取媒体时长（秒）
+func Duration(ctx context.Context, runner Runner, input string) (float64, error) {
+	var output bytes.Buffer
+	cmd := FFprobe(
+		"-v", "error",
+		"-show_entries", "format=duration",
+		"-of", "default=noprint_wrappers=1:nokey=1",
+		input,
+	)
+	cmd.Stdout = &output
+	if _, err := runner.Run(ctx, cmd); err != nil {
+		return 0, err
+	}
+
+	var duration float64
+	if _, err := fmt.Sscanf(output.String(), "%f", &duration); err != nil {
+		return 0, fmt.Errorf("无法解析时长 %q: %w", strings.TrimSpace(output.String()), err)
+	}
+	return duration, nil
+}
+
+// HasAudioStream 使用 ffprobe 判断文件是否包含音频流
+func HasAudioStream(ctx context.Context, runner Runner, input string) (bool, error) {
+	var output bytes.Buffer
+	cmd := FFprobe(
+		"-v", "error",
+		"-select_streams", "a",
+		"-show_entries", "stream=index",
+		"-of", "csv=p=0",
+		input,
+	)
+	cmd.Stdout = &output
+	if _, err := runner.Run(ctx, cmd); err != nil {
+		return false, err
+	}
+	return strings.TrimSpace(output.String()) != "", nil
+}
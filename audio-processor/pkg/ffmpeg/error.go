@@ -0,0 +1,141 @@
+package ffmpeg
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// ffmpeg 常见失败原因
+var (
+	ErrNoAudioStream = errors.New("文件不包含音频流")
+	ErrCorruptMedia  = errors.New("文件已损坏或格式无法识别")
+)
+
+// 保留的 stderr 输出上限，以及错误信息中展示的行数
+const (
+	stderrTailBytes   = 8 * 1024
+	errorSummaryLines = 2
+)
+
+// stderr 中对应失败原因的关键字（小写）
+var (
+	noAudioPatterns = []string{
+		"matches no streams",
+		"does not contain any stream",
+		"output file does not contain any stream",
+		"no audio stream",
+	}
+	corruptPatterns = []string{
+		"invalid data found when processing input",
+		"moov atom not found",
+		"could not find codec parameters",
+		"error while decoding",
+		"header missing",
+		"truncated",
+		"end of file",
+	}
+)
+
+// Error ffmpeg/ffprobe 执行失败，携带 stderr 末尾的输出
+type Error struct {
+	Err    error  // 命令返回的错误，通常为退出状态
+	Stderr string // stderr 末尾的输出
+	Cause  error  // 识别出的失败原因（ErrNoAudioStream、ErrCorruptMedia，或被取消时 ctx 的错误），无法识别时为 nil
+}
+
+// NewError 根据 stderr 识别失败原因并创建错误，假的 Runner 也可用它返回与真实命令一致的错误
+func NewError(err error, stderr string) *Error {
+	return &Error{
+		Err:    err,
+		Stderr: stderr,
+		Cause:  detectCause(stderr),
+	}
+}
+
+// Error 返回包含失败原因与 stderr 最后几行的单行信息
+func (e *Error) Error() string {
+	msg := e.Err.Error()
+	if summary := strings.Join(LastLines(e.Stderr, errorSummaryLines), "; "); summary != "" {
+		msg += ": " + summary
+	}
+	if e.Cause != nil {
+		return fmt.Sprintf("%v (%s)", e.Cause, msg)
+	}
+	return msg
+}
+
+// Unwrap 同时暴露命令错误与失败原因，可用 errors.Is 判断
+func (e *Error) Unwrap() []error {
+	if e.Cause != nil {
+		return []error{e.Err, e.Cause}
+	}
+	return []error{e.Err}
+}
+
+// Tail 返回 stderr 最后 n 行
+func (e *Error) Tail(n int) []string {
+	return LastLines(e.Stderr, n)
+}
+
+// detectCause 根据 stderr 中的关键字识别常见失败原因
+func detectCause(stderr string) error {
+	lower := strings.ToLower(stderr)
+	for _, pattern := range noAudioPatterns {
+		if strings.Contains(lower, pattern) {
+			return ErrNoAudioStream
+		}
+	}
+	for _, pattern := range corruptPatterns {
+		if strings.Contains(lower, pattern) {
+			return ErrCorruptMedia
+		}
+	}
+	return nil
+}
+
+// LastLines 返回文本中最后 n 个非空行
+func LastLines(text string, n int) []string {
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return lines
+}
+
+// tailBuffer 只保留最后 limit 字节的写入缓冲，避免长时间运行的 ffmpeg 输出占用过多内存
+type tailBuffer struct {
+	mu    sync.Mutex
+	limit int
+	data  []byte
+}
+
+// Write 追加数据，超出上限时丢弃最早的部分
+func (b *tailBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.data = append(b.data, p...)
+	if over := len(b.data) - b.limit; over > 0 {
+		b.data = append(b.data[:0], b.data[over:]...)
+	}
+	return len(p), nil
+}
+
+// String 返回缓冲内容，截断处的不完整行会被丢弃
+func (b *tailBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	text := string(b.data)
+	if len(b.data) >= b.limit {
+		if i := strings.IndexByte(text, '\n'); i >= 0 {
+			text = text[i+1:]
+		}
+	}
+	return text
+}
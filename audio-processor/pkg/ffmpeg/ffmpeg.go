@@ -0,0 +1,94 @@
+// Package ffmpeg 构建并执行 ffmpeg/ffprobe 命令：捕获 stderr 末尾的输出用于错误信息，
+// 解析 -progress pipe:1 输出的进度，执行方式通过 Runner 接口注入，单元测试可替换为假实现
+package ffmpeg
+
+import (
+	"context"
+	"io"
+	"os/exec"
+	"time"
+)
+
+// 命令因 ctx 取消被终止后，等待其输出关闭的最长时间
+const DefaultWaitDelay = 5 * time.Second
+
+// Command 一条待执行的 ffmpeg/ffprobe 命令
+type Command struct {
+	Program  string         // ffmpeg 或 ffprobe
+	Args     []string       // 不含程序名的参数
+	Stdout   io.Writer      // 接收 stdout，为 nil 时丢弃；设置 Progress 时不可用
+	Stderr   io.Writer      // 额外接收完整的 stderr，为 nil 时只保留末尾的输出用于错误信息
+	Progress func(Progress) // 设置后以 -progress pipe:1 运行并回调进度，仅适用于输出到文件的 ffmpeg 命令
+}
+
+// Runner 执行命令，成功时返回 stderr 末尾的输出（用于读取滤镜打印的统计信息），
+// 失败时返回 *Error
+type Runner interface {
+	Run(ctx context.Context, cmd *Command) (string, error)
+}
+
+// RunnerFunc 将函数适配为 Runner，便于测试中替换
+type RunnerFunc func(ctx context.Context, cmd *Command) (string, error)
+
+// Run 调用函数本身
+func (f RunnerFunc) Run(ctx context.Context, cmd *Command) (string, error) {
+	return f(ctx, cmd)
+}
+
+// ExecRunner 在本机执行命令
+type ExecRunner struct {
+	WaitDelay time.Duration // ctx 取消后等待输出关闭的时间，0 表示 DefaultWaitDelay
+}
+
+// DefaultRunner 未指定 Runner 时使用的执行方式
+var DefaultRunner Runner = ExecRunner{}
+
+// Run 执行命令并捕获 stderr。因 ctx 取消或超时而终止时，错误的失败原因为 ctx 的错误
+func (r ExecRunner) Run(ctx context.Context, c *Command) (string, error) {
+	args := c.Args
+	if c.Progress != nil {
+		args = append([]string{"-progress", "pipe:1", "-nostats"}, args...)
+	}
+	cmd := exec.CommandContext(ctx, c.Program, args...)
+	stderr := &tailBuffer{limit: stderrTailBytes}
+	cmd.Stderr = stderr
+	if c.Stderr != nil {
+		cmd.Stderr = io.MultiWriter(stderr, c.Stderr)
+	}
+	cmd.Stdout = c.Stdout
+	cmd.WaitDelay = r.WaitDelay
+	if cmd.WaitDelay == 0 {
+		cmd.WaitDelay = DefaultWaitDelay
+	}
+
+	var err error
+	if c.Progress != nil {
+		reader, writer := io.Pipe()
+		cmd.Stdout = writer
+		parsed := make(chan struct{})
+		go func() {
+			defer close(parsed)
+			ParseProgress(reader, c.Progress)
+			io.Copy(io.Discard, reader)
+		}()
+		err = cmd.Run()
+		writer.Close()
+		<-parsed
+	} else {
+		err = cmd.Run()
+	}
+
+	if err != nil {
+		ffmpegErr := NewError(err, stderr.String())
+		if ctx.Err() != nil {
+			ffmpegErr.Cause = ctx.Err()
+		}
+		return "", ffmpegErr
+	}
+	return stderr.String(), nil
+}
+
+// Run 使用 DefaultRunner 执行命令
+func Run(ctx context.Context, cmd *Command) (string, error) {
+	return DefaultRunner.Run(ctx, cmd)
+}
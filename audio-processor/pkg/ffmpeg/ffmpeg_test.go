@@ -0,0 +1,130 @@
+package ffmpeg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestErrorCause 测试从 stderr 识别失败原因
+func TestErrorCause(t *testing.T) {
+	exitErr := errors.New("exit status 1")
+
+	err := NewError(exitErr, "Input #0, mov,mp4\nStream map '0:a' matches no streams.\nTo ignore this, add a trailing '?' to the map.\n")
+	assert.True(t, errors.Is(err, ErrNoAudioStream))
+	assert.True(t, errors.Is(err, exitErr))
+	assert.Contains(t, err.Error(), "matches no streams")
+	assert.NotContains(t, err.Error(), "\n")
+
+	err = NewError(exitErr, "[mov,mp4,m4a,3gp,3g2,mj2 @ 0x1] moov atom not found\nbroken.mp4: Invalid data found when processing input\n")
+	assert.True(t, errors.Is(err, ErrCorruptMedia))
+
+	assert.Nil(t, NewError(exitErr, "something else\n").Cause)
+}
+
+// TestTailBuffer 测试只保留 stderr 末尾的输出
+func TestTailBuffer(t *testing.T) {
+	buffer := &tailBuffer{limit: 32}
+	for i := 0; i < 20; i++ {
+		fmt.Fprintf(buffer, "line %02d\n", i)
+	}
+
+	text := buffer.String()
+	assert.True(t, strings.HasPrefix(text, "line "))
+	assert.True(t, strings.HasSuffix(text, "line 19\n"))
+	assert.Equal(t, []string{"line 18", "line 19"}, LastLines(text, 2))
+}
+
+// TestExecRunnerCapturesStderr 测试命令失败时错误中包含 stderr
+func TestExecRunnerCapturesStderr(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("跳过需要sh的测试")
+	}
+
+	_, err := Run(context.Background(), &Command{Program: "sh", Args: []string{"-c", "echo 'x.mp4: Invalid data found when processing input' >&2; exit 1"}})
+
+	var ffmpegErr *Error
+	require.True(t, errors.As(err, &ffmpegErr))
+	assert.True(t, errors.Is(err, ErrCorruptMedia))
+	assert.Equal(t, []string{"x.mp4: Invalid data found when processing input"}, ffmpegErr.Tail(5))
+}
+
+// TestParseProgress 测试解析 -progress 输出
+func TestParseProgress(t *testing.T) {
+	output := "frame=0\nout_time_us=1500000\ntotal_size=4096\nspeed=2.5x\nprogress=continue\n" +
+		"out_time_us=N/A\nout_time_ms=3000000\ntotal_size=8192\nspeed=N/A\nprogress=end\n"
+
+	var reports []Progress
+	require.NoError(t, ParseProgress(strings.NewReader(output), func(p Progress) {
+		reports = append(reports, p)
+	}))
+	require.Len(t, reports, 2)
+	assert.Equal(t, Progress{OutTime: 1500 * 1e6, TotalSize: 4096, Speed: 2.5}, reports[0])
+	assert.Equal(t, 50.0, reports[0].Percent(3*1e9))
+	assert.True(t, reports[1].Done)
+	assert.Equal(t, int64(8192), reports[1].TotalSize)
+	assert.Equal(t, 100.0, reports[1].Percent(0))
+}
+
+// TestParseSilences 测试解析 silencedetect 输出
+func TestParseSilences(t *testing.T) {
+	output := "[silencedetect @ 0x1] silence_start: -0.01\n" +
+		"[silencedetect @ 0x1] silence_end: 0.52 | silence_duration: 0.53\n" +
+		"size=N/A time=00:00:30.00 bitrate=N/A\n" +
+		"[silencedetect @ 0x1] silence_start: 28.4\n" +
+		"[silencedetect @ 0x1] silence_end: 29.1 | silence_duration: 0.7\n" +
+		"[silencedetect @ 0x1] silence_start: 59.5\n"
+
+	silences := ParseSilences(strings.NewReader(output))
+	assert.Equal(t, []Silence{{Start: 0, End: 0.52}, {Start: 28.4, End: 29.1}}, silences)
+	assert.InDelta(t, 28.75, silences[1].Mid(), 1e-9)
+}
+
+// TestExecRunnerProgress 测试用真实的 ffmpeg 生成音频时回调进度
+func TestExecRunnerProgress(t *testing.T) {
+	if _, err := exec.LookPath("ffmpeg"); err != nil || os.Getenv("SKIP_FFMPEG_TESTS") == "1" {
+		t.Skip("跳过需要ffmpeg的测试")
+	}
+
+	output := filepath.Join(t.TempDir(), "tone.wav")
+	var last Progress
+	cmd := FFmpeg("-v", "error", "-f", "lavfi", "-i", "sine=frequency=440:duration=2", "-y", output)
+	cmd.Progress = func(p Progress) { last = p }
+	_, err := Run(context.Background(), cmd)
+	require.NoError(t, err)
+	assert.True(t, last.Done)
+	assert.InDelta(t, 2.0, last.OutTime.Seconds(), 0.1)
+
+	duration, err := Duration(context.Background(), DefaultRunner, output)
+	require.NoError(t, err)
+	assert.InDelta(t, 2.0, duration, 0.1)
+}
+
+// TestAudioFiltersChain 测试按固定顺序组合预处理滤镜
+func TestAudioFiltersChain(t *testing.T) {
+	assert.Equal(t, "", AudioFilters{}.Chain())
+	assert.Equal(t, "highpass=f=80,lowpass=f=8000,afftdn,loudnorm=I=-16:TP=-1.5:LRA=11,aresample=48000",
+		AudioFilters{Highpass: 80, Lowpass: 8000, Denoise: DenoiseAFFTDN, Loudnorm: true}.Chain())
+	assert.Equal(t, `arnndn=m='C\:/models/sh.rnnn'`,
+		AudioFilters{Denoise: DenoiseRNNoise, DenoiseModel: "C:/models/sh.rnnn"}.Chain())
+
+	cmd := ExtractAudio("in.mp4", "out.mp3", ExtractOptions{Filter: "afftdn", Track: AllTracks})
+	assert.Equal(t, []string{"-i", "in.mp4", "-q:a", "0", "-map", "a", "-af", "afftdn", "out.mp3", "-y"}, cmd.Args)
+}
+
+// TestExtractAudioRange 测试选择音轨与时间范围
+func TestExtractAudioRange(t *testing.T) {
+	cmd := ExtractAudio("in.mkv", "out.mp3", ExtractOptions{Track: 1, Start: 90, End: 150.5})
+	assert.Equal(t, []string{"-ss", "90", "-i", "in.mkv", "-q:a", "0", "-map", "0:a:1", "-t", "60.5", "out.mp3", "-y"}, cmd.Args)
+
+	cmd = ExtractAudio("in.mkv", "out.mp3", ExtractOptions{End: 30})
+	assert.Equal(t, []string{"-i", "in.mkv", "-q:a", "0", "-map", "0:a:0", "-t", "30", "out.mp3", "-y"}, cmd.Args)
+}
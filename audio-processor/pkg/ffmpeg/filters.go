@@ -0,0 +1,61 @@
+package ffmpeg
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// 降噪方式
+const (
+	DenoiseAFFTDN  = "afftdn"  // ffmpeg 内置的频域降噪
+	DenoiseRNNoise = "rnnoise" // arnndn 神经网络降噪，需要 RNNoise 模型文件
+)
+
+// AudioFilters 识别前对音频的预处理，按高通、低通、降噪、响度归一化的顺序执行
+type AudioFilters struct {
+	Highpass     int    // 高通截止频率（Hz），0 表示不启用
+	Lowpass      int    // 低通截止频率（Hz），0 表示不启用
+	Denoise      string // 降噪方式，为空时不降噪
+	DenoiseModel string // rnnoise 使用的模型文件（.rnnn）
+	Loudnorm     bool   // 是否按 EBU R128 归一化响度
+}
+
+// Chain 返回 -af 使用的滤镜链，未启用任何处理时为空字符串
+func (f AudioFilters) Chain() string {
+	var filters []string
+	if f.Highpass > 0 {
+		filters = append(filters, fmt.Sprintf("highpass=f=%d", f.Highpass))
+	}
+	if f.Lowpass > 0 {
+		filters = append(filters, fmt.Sprintf("lowpass=f=%d", f.Lowpass))
+	}
+	switch f.Denoise {
+	case DenoiseAFFTDN:
+		filters = append(filters, "afftdn")
+	case DenoiseRNNoise:
+		filters = append(filters, "arnndn=m="+quoteFilterPath(f.DenoiseModel))
+	}
+	if f.Loudnorm {
+		// loudnorm 输出 192kHz，重采样回常用采样率，避免写出体积过大的音频
+		filters = append(filters, "loudnorm=I=-16:TP=-1.5:LRA=11", "aresample=48000")
+	}
+	return strings.Join(filters, ",")
+}
+
+// quoteFilterPath 转义滤镜参数中的文件路径：Windows 盘符中的冒号会被当作参数分隔符
+func quoteFilterPath(path string) string {
+	path = strings.ReplaceAll(filepath.ToSlash(path), ":", `\:`)
+	return "'" + strings.ReplaceAll(path, "'", `'\''`) + "'"
+}
+
+// FilterAudio 对音频应用滤镜链，写入 output（按扩展名选择格式）
+func FilterAudio(input, output, filter string) *Command {
+	return FFmpeg(
+		"-y",
+		"-i", input,
+		"-vn",
+		"-af", filter,
+		output,
+	)
+}
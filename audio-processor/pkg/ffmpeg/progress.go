@@ -0,0 +1,66 @@
+package ffmpeg
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Progress ffmpeg 通过 -progress 报告的一次进度
+type Progress struct {
+	OutTime   time.Duration // 已输出的媒体时长
+	TotalSize int64         // 已写入的字节数
+	Speed     float64       // 相对实时的处理速度，未知时为 0
+	Done      bool          // 最后一次报告（progress=end）
+}
+
+// Percent 按媒体总时长计算完成百分比（0-100），总时长未知时返回 0
+func (p Progress) Percent(total time.Duration) float64 {
+	if p.Done {
+		return 100
+	}
+	if total <= 0 {
+		return 0
+	}
+	percent := float64(p.OutTime) / float64(total) * 100
+	if percent > 100 {
+		percent = 100
+	}
+	if percent < 0 {
+		percent = 0
+	}
+	return percent
+}
+
+// ParseProgress 读取 -progress 输出的 key=value 行，每读完一组（以 progress=continue 或 end 结尾）
+// 回调一次，直到 r 结束
+func ParseProgress(r io.Reader, fn func(Progress)) error {
+	var current Progress
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(strings.TrimSpace(scanner.Text()), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "out_time_us", "out_time_ms": // 两者单位均为微秒
+			if us, err := strconv.ParseInt(value, 10, 64); err == nil && us >= 0 {
+				current.OutTime = time.Duration(us) * time.Microsecond
+			}
+		case "total_size":
+			if size, err := strconv.ParseInt(value, 10, 64); err == nil {
+				current.TotalSize = size
+			}
+		case "speed":
+			if speed, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(value), "x"), 64); err == nil {
+				current.Speed = speed
+			}
+		case "progress":
+			current.Done = value == "end"
+			fn(current)
+		}
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,76 @@
+package ffmpeg
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+)
+
+// Silence 一段静音的起止时间（秒）
+type Silence struct {
+	Start float64
+	End   float64
+}
+
+// Mid 返回静音的中点
+func (s Silence) Mid() float64 {
+	return (s.Start + s.End) / 2
+}
+
+// silencedetect 滤镜输出中的静音起止时间
+var (
+	silenceStartPattern = regexp.MustCompile(`silence_start:\s*(-?[0-9.]+)`)
+	silenceEndPattern   = regexp.MustCompile(`silence_end:\s*(-?[0-9.]+)`)
+)
+
+// SilenceDetect 用 silencedetect 滤镜查找音量低于 noiseDB、持续至少 minDuration 秒的静音，结果打印在 stderr
+func SilenceDetect(input string, noiseDB, minDuration float64) *Command {
+	return FFmpeg(
+		"-hide_banner", "-nostats",
+		"-i", input,
+		"-map", "0:a:0",
+		"-af", fmt.Sprintf("silencedetect=noise=%sdB:d=%s", seconds(noiseDB), seconds(minDuration)),
+		"-f", "null", "-",
+	)
+}
+
+// DetectSilences 执行 silencedetect 并返回按时间排列的静音段
+func DetectSilences(ctx context.Context, runner Runner, input string, noiseDB, minDuration float64) ([]Silence, error) {
+	var stderr bytes.Buffer
+	cmd := SilenceDetect(input, noiseDB, minDuration)
+	cmd.Stderr = &stderr
+	if _, err := runner.Run(ctx, cmd); err != nil {
+		return nil, err
+	}
+	return ParseSilences(&stderr), nil
+}
+
+// ParseSilences 解析 silencedetect 的输出。文件以静音结尾时最后一段没有 silence_end，不计入结果
+func ParseSilences(r io.Reader) []Silence {
+	var silences []Silence
+	start, open := 0.0, false
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if match := silenceStartPattern.FindStringSubmatch(line); match != nil {
+			if value, err := strconv.ParseFloat(match[1], 64); err == nil {
+				start, open = value, true
+			}
+			continue
+		}
+		if match := silenceEndPattern.FindStringSubmatch(line); match != nil && open {
+			if value, err := strconv.ParseFloat(match[1], 64); err == nil {
+				if start < 0 {
+					start = 0
+				}
+				silences = append(silences, Silence{Start: start, End: value})
+			}
+			open = false
+		}
+	}
+	return silences
+}
@@ -0,0 +1,224 @@
+package langdetect
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/ffmpeg"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/models"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/utils"
+)
+
+// 语言检测方式
+const (
+	ModeNone    = ""        // 不检测
+	ModeCommand = "command" // 调用外部命令（如 whisper 语言识别脚本）
+	ModeHTTP    = "http"    // 调用 HTTP 服务
+)
+
+// AutoLanguage 表示需要自动检测语言
+const AutoLanguage = "auto"
+
+// 命令行参数中音频路径的占位符
+const audioPlaceholder = "{audio}"
+
+// Result 语言检测结果
+type Result struct {
+	Language    string  `json:"language"`              // 语言代码，如 zh、en
+	Probability float64 `json:"probability,omitempty"` // 置信度（0-1），未提供时为0
+}
+
+// Detector 语言检测接口
+type Detector interface {
+	Detect(ctx context.Context, audioPath string) (Result, error)
+}
+
+// CommandDetector 通过外部命令检测语言。
+// 命令需向标准输出打印 JSON（Result）或仅打印语言代码。
+type CommandDetector struct {
+	Command string   // 可执行文件
+	Args    []string // 参数，其中的 {audio} 会被替换为音频路径；没有占位符时音频路径追加在末尾
+}
+
+// NewCommandDetector 根据命令行字符串创建外部命令检测器，如 "python detect_lang.py {audio}"
+func NewCommandDetector(commandLine string) (*CommandDetector, error) {
+	fields := strings.Fields(commandLine)
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("未设置语言检测命令")
+	}
+	return &CommandDetector{Command: fields[0], Args: fields[1:]}, nil
+}
+
+// Detect 执行外部命令并解析输出
+func (d *CommandDetector) Detect(ctx context.Context, audioPath string) (Result, error) {
+	args := make([]string, 0, len(d.Args)+1)
+	replaced := false
+	for _, arg := range d.Args {
+		if strings.Contains(arg, audioPlaceholder) {
+			arg = strings.ReplaceAll(arg, audioPlaceholder, audioPath)
+			replaced = true
+		}
+		args = append(args, arg)
+	}
+	if !replaced {
+		args = append(args, audioPath)
+	}
+
+	cmd := exec.CommandContext(ctx, d.Command, args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return Result{}, fmt.Errorf("语言检测命令执行失败: %w, %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return ParseResult(output)
+}
+
+// HTTPDetector 通过 HTTP 服务检测语言，音频以 multipart 表单字段 file 上传，服务返回 Result
+type HTTPDetector struct {
+	URL    string
+	Client *http.Client
+}
+
+// NewHTTPDetector 创建 HTTP 检测器
+func NewHTTPDetector(url string) *HTTPDetector {
+	return &HTTPDetector{
+		URL:    url,
+		Client: &http.Client{Timeout: 2 * time.Minute},
+	}
+}
+
+// Detect 上传音频片段并解析服务返回的结果
+func (d *HTTPDetector) Detect(ctx context.Context, audioPath string) (Result, error) {
+	file, err := os.Open(audioPath)
+	if err != nil {
+		return Result{}, fmt.Errorf("打开音频文件失败: %w", err)
+	}
+	defer file.Close()
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("file", filepath.Base(audioPath))
+	if err != nil {
+		return Result{}, err
+	}
+	if _, err := io.Copy(part, file); err != nil {
+		return Result{}, fmt.Errorf("读取音频文件失败: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return Result{}, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.URL, &body)
+	if err != nil {
+		return Result{}, fmt.Errorf("创建请求失败: %w", err)
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	resp, err := d.Client.Do(req)
+	if err != nil {
+		return Result{}, fmt.Errorf("请求语言检测服务失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return Result{}, fmt.Errorf("读取语言检测结果失败: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return Result{}, fmt.Errorf("语言检测服务返回错误 %d: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	return ParseResult(data)
+}
+
+// ParseResult 解析检测结果，支持 JSON（Result）或仅包含语言代码的文本
+func ParseResult(data []byte) (Result, error) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 {
+		return Result{}, fmt.Errorf("语言检测结果为空")
+	}
+
+	var result Result
+	if trimmed[0] == '{' {
+		if err := json.Unmarshal(trimmed, &result); err != nil {
+			return Result{}, fmt.Errorf("解析语言检测结果失败: %w", err)
+		}
+	} else {
+		// 纯文本输出时取最后一行，兼容脚本先打印日志的情况
+		scanner := bufio.NewScanner(bytes.NewReader(trimmed))
+		for scanner.Scan() {
+			if line := strings.TrimSpace(scanner.Text()); line != "" {
+				result.Language = line
+			}
+		}
+	}
+
+	result.Language = Normalize(result.Language)
+	if result.Language == "" {
+		return Result{}, fmt.Errorf("语言检测结果中没有语言代码")
+	}
+	return result, nil
+}
+
+// Normalize 规范化语言代码：转为小写并去掉地区后缀，如 zh-CN -> zh
+func Normalize(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	if i := strings.IndexAny(code, "-_"); i > 0 {
+		code = code[:i]
+	}
+	return code
+}
+
+// Sample 用 ffmpeg 截取音频开头 seconds 秒，转为 16kHz 单声道 wav 供检测使用
+func Sample(ctx context.Context, audioPath, dir string, seconds int) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("创建临时目录失败: %w", err)
+	}
+	base := strings.TrimSuffix(filepath.Base(audioPath), filepath.Ext(audioPath))
+	samplePath := filepath.Join(dir, fmt.Sprintf("%s_lang_%s.wav", base, utils.GenerateRandomString(4)))
+
+	if _, err := ffmpeg.Run(ctx, ffmpeg.Sample(audioPath, samplePath, float64(seconds))); err != nil {
+		os.Remove(samplePath)
+		return "", fmt.Errorf("截取检测音频失败: %w", err)
+	}
+	return samplePath, nil
+}
+
+// DetectFile 截取音频样本并检测语言，检测完成后删除样本
+func DetectFile(ctx context.Context, d Detector, audioPath, tempDir string, seconds int) (Result, error) {
+	samplePath, err := Sample(ctx, audioPath, tempDir, seconds)
+	if err != nil {
+		return Result{}, err
+	}
+	defer os.Remove(samplePath)
+
+	return d.Detect(ctx, samplePath)
+}
+
+// FromConfig 根据配置创建语言检测器，未启用时返回 nil
+func FromConfig(config *models.Config) (Detector, error) {
+	switch config.LanguageDetect {
+	case ModeNone:
+		return nil, nil
+	case ModeCommand:
+		return NewCommandDetector(config.LanguageDetectCommand)
+	case ModeHTTP:
+		if config.LanguageDetectURL == "" {
+			return nil, fmt.Errorf("未设置语言检测服务地址")
+		}
+		return NewHTTPDetector(config.LanguageDetectURL), nil
+	default:
+		return nil, fmt.Errorf("未知的语言检测方式: %s", config.LanguageDetect)
+	}
+}
@@ -0,0 +1,22 @@
+package langdetect
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseResult(t *testing.T) {
+	result, err := ParseResult([]byte(`{"language": "en-US", "probability": 0.93}`))
+	require.NoError(t, err)
+	assert.Equal(t, Result{Language: "en", Probability: 0.93}, result)
+
+	// 纯文本输出取最后一行
+	result, err = ParseResult([]byte("loading model...\nzh_CN\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "zh", result.Language)
+
+	_, err = ParseResult([]byte(`{"probability": 0.5}`))
+	assert.Error(t, err)
+}
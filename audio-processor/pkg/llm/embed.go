@@ -0,0 +1,147 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/models"
+)
+
+// Embedder 将文本转换为向量，用于文稿问答的检索
+type Embedder interface {
+	// Name 返回服务商与向量模型，如 openai/text-embedding-3-small，缓存的向量按它区分
+	Name() string
+	// Embed 返回与 texts 一一对应的向量
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// EmbedderFromConfig 按配置 llm 的服务商与 embedding_model 创建向量模型客户端。
+// 未设置 embedding_model 或未配置API密钥时返回 nil，调用方改用关键词检索
+func EmbedderFromConfig(config *models.Config) (Embedder, error) {
+	if config.EmbeddingModel == "" {
+		return nil, nil
+	}
+	apiKey := config.LLMAPIKey()
+	if apiKey == "" && config.LLMProvider() != models.LLMProviderOllama {
+		return nil, nil
+	}
+	if config.LLMProvider() == models.LLMProviderDeepSeek {
+		return nil, fmt.Errorf("DeepSeek 不提供向量模型，请清空 embedding_model 使用关键词检索")
+	}
+	client, err := newClient(config.LLM, apiKey)
+	if err != nil {
+		return nil, err
+	}
+	switch c := client.(type) {
+	case *OpenAIClient:
+		return &openAIEmbedder{client: c, model: config.EmbeddingModel}, nil
+	case *OllamaClient:
+		return &ollamaEmbedder{client: c, model: config.EmbeddingModel}, nil
+	case *GeminiClient:
+		return &geminiEmbedder{client: c, model: config.EmbeddingModel}, nil
+	}
+	return nil, fmt.Errorf("%s 不支持向量模型", client.Name())
+}
+
+// openAIEmbedder 访问 OpenAI 兼容的 <BaseURL>/embeddings 接口（火山方舟同样兼容）
+type openAIEmbedder struct {
+	client *OpenAIClient
+	model  string
+}
+
+func (e *openAIEmbedder) Name() string {
+	return e.client.Provider + "/" + e.model
+}
+
+func (e *openAIEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	request := map[string]interface{}{"model": e.model, "input": texts}
+	var response struct {
+		Data []struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		} `json:"data"`
+	}
+	if err := PostJSON(ctx, e.client.HttpClient, e.client.BaseURL+"/embeddings", e.client.headers(), request, &response); err != nil {
+		return nil, err
+	}
+	vectors := make([][]float32, len(texts))
+	for _, item := range response.Data {
+		if item.Index >= 0 && item.Index < len(vectors) {
+			vectors[item.Index] = item.Embedding
+		}
+	}
+	return checkVectors(vectors)
+}
+
+// ollamaEmbedder 访问 Ollama 的 /api/embed 接口
+type ollamaEmbedder struct {
+	client *OllamaClient
+	model  string
+}
+
+func (e *ollamaEmbedder) Name() string {
+	return "ollama/" + e.model
+}
+
+func (e *ollamaEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	request := map[string]interface{}{"model": e.model, "input": texts}
+	var response struct {
+		Embeddings [][]float32 `json:"embeddings"`
+	}
+	if err := PostJSON(ctx, e.client.HttpClient, e.client.BaseURL+"/api/embed", nil, request, &response); err != nil {
+		return nil, err
+	}
+	if len(response.Embeddings) != len(texts) {
+		return nil, fmt.Errorf("向量模型返回 %d 个向量，应为 %d 个", len(response.Embeddings), len(texts))
+	}
+	return checkVectors(response.Embeddings)
+}
+
+// geminiEmbedder 访问 Gemini 的 batchEmbedContents 接口
+type geminiEmbedder struct {
+	client *GeminiClient
+	model  string
+}
+
+func (e *geminiEmbedder) Name() string {
+	return "gemini/" + e.model
+}
+
+func (e *geminiEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	type embedRequest struct {
+		Model   string        `json:"model"`
+		Content geminiContent `json:"content"`
+	}
+	requests := make([]embedRequest, len(texts))
+	for i, text := range texts {
+		requests[i] = embedRequest{Model: "models/" + e.model, Content: geminiContent{Parts: []geminiPart{{Text: text}}}}
+	}
+	var response struct {
+		Embeddings []struct {
+			Values []float32 `json:"values"`
+		} `json:"embeddings"`
+	}
+	endpoint := fmt.Sprintf("%s/models/%s:batchEmbedContents", e.client.BaseURL, url.PathEscape(e.model))
+	if err := PostJSON(ctx, e.client.HttpClient, endpoint, e.client.headers(), map[string]interface{}{"requests": requests}, &response); err != nil {
+		return nil, err
+	}
+	if len(response.Embeddings) != len(texts) {
+		return nil, fmt.Errorf("向量模型返回 %d 个向量，应为 %d 个", len(response.Embeddings), len(texts))
+	}
+	vectors := make([][]float32, len(texts))
+	for i, embedding := range response.Embeddings {
+		vectors[i] = embedding.Values
+	}
+	return checkVectors(vectors)
+}
+
+// checkVectors 检查每段文本都有向量
+func checkVectors(vectors [][]float32) ([][]float32, error) {
+	for i, vector := range vectors {
+		if len(vector) == 0 {
+			return nil, fmt.Errorf("向量模型未返回第 %d 段文本的向量", i+1)
+		}
+	}
+	return vectors, nil
+}
@@ -0,0 +1,173 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/utils"
+)
+
+// Gemini 的默认地址与模型
+const (
+	GeminiBaseURL = "https://generativelanguage.googleapis.com/v1beta"
+	GeminiModel   = "gemini-2.0-flash"
+)
+
+// GeminiClient 访问 Google Gemini 的 generateContent 接口
+type GeminiClient struct {
+	APIKey     string
+	BaseURL    string
+	Model      string
+	HttpClient *utils.HTTPClient
+	meter      *Meter
+}
+
+// geminiContent Gemini 的一条内容，由若干文本片段组成
+type geminiContent struct {
+	Role  string       `json:"role,omitempty"`
+	Parts []geminiPart `json:"parts"`
+}
+
+type geminiPart struct {
+	Text string `json:"text"`
+}
+
+// geminiRequest generateContent 请求
+type geminiRequest struct {
+	SystemInstruction *geminiContent  `json:"systemInstruction,omitempty"`
+	Contents          []geminiContent `json:"contents"`
+}
+
+// geminiResponse generateContent 响应
+type geminiResponse struct {
+	Candidates []struct {
+		Content      geminiContent `json:"content"`
+		FinishReason string        `json:"finishReason"`
+	} `json:"candidates"`
+	PromptFeedback struct {
+		BlockReason string `json:"blockReason"`
+	} `json:"promptFeedback"`
+	// UsageMetadata token 用量，流式响应的每段为截至该段的累计值
+	UsageMetadata struct {
+		PromptTokenCount     int `json:"promptTokenCount"`
+		CandidatesTokenCount int `json:"candidatesTokenCount"`
+	} `json:"usageMetadata"`
+}
+
+// NewGeminiClient 创建 Gemini 客户端
+func NewGeminiClient(apiKey, model string) *GeminiClient {
+	return &GeminiClient{APIKey: apiKey, BaseURL: GeminiBaseURL, Model: model, HttpClient: utils.DefaultHTTPClient(), meter: &Meter{}}
+}
+
+// Meter 返回累计用量的计量器
+func (c *GeminiClient) Meter() *Meter {
+	return c.meter
+}
+
+// Name 返回服务商与模型
+func (c *GeminiClient) Name() string {
+	return "gemini/" + c.Model
+}
+
+// GenerateSummary 生成文本摘要
+func (c *GeminiClient) GenerateSummary(content string) (string, error) {
+	return c.Chat(SummaryPrompt, content)
+}
+
+// Chat 以 systemPrompt 为系统指令发送一轮对话，返回模型的回复
+func (c *GeminiClient) Chat(systemPrompt, content string) (string, error) {
+	var response geminiResponse
+	if err := postJSON(c.HttpClient, c.endpoint("generateContent"), c.headers(), c.request(systemPrompt, content), &response); err != nil {
+		return "", err
+	}
+	c.meter.record("gemini", c.Model, response.usage())
+	text, err := response.text()
+	if err != nil {
+		return "", err
+	}
+	if text == "" {
+		finishReason := ""
+		if len(response.Candidates) > 0 {
+			finishReason = response.Candidates[0].FinishReason
+		}
+		return "", fmt.Errorf("API响应中没有生成内容（%s）", finishReason)
+	}
+	return text, nil
+}
+
+// GenerateSummaryStream 流式生成文本摘要，每收到一段文本调用一次 onDelta，返回完整摘要
+func (c *GeminiClient) GenerateSummaryStream(ctx context.Context, content string, onDelta func(string)) (string, error) {
+	return c.ChatStream(ctx, SummaryPrompt, content, onDelta)
+}
+
+// ChatStream 通过 streamGenerateContent（SSE）发送一轮对话，每个事件是一个部分响应
+func (c *GeminiClient) ChatStream(ctx context.Context, systemPrompt, content string, onDelta func(string)) (string, error) {
+	body, err := postStream(ctx, c.HttpClient, c.endpoint("streamGenerateContent")+"?alt=sse", c.headers(), c.request(systemPrompt, content))
+	if err != nil {
+		return "", err
+	}
+	defer body.Close()
+
+	add, result := collect(onDelta)
+	var usage Usage
+	err = readSSE(body, func(data []byte) error {
+		var chunk geminiResponse
+		if err := json.Unmarshal(data, &chunk); err != nil {
+			return fmt.Errorf("解析流式响应失败: %w", err)
+		}
+		text, err := chunk.text()
+		if err != nil {
+			return err
+		}
+		add(text)
+		if chunk.UsageMetadata.PromptTokenCount > 0 {
+			usage = chunk.usage()
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	c.meter.record("gemini", c.Model, usage)
+	return result()
+}
+
+// endpoint 返回模型的接口地址，method 为 generateContent 或 streamGenerateContent
+func (c *GeminiClient) endpoint(method string) string {
+	return fmt.Sprintf("%s/models/%s:%s", c.BaseURL, url.PathEscape(c.Model), method)
+}
+
+func (c *GeminiClient) headers() map[string]string {
+	return map[string]string{"x-goog-api-key": c.APIKey}
+}
+
+// request 构建一轮对话的请求
+func (c *GeminiClient) request(systemPrompt, content string) geminiRequest {
+	return geminiRequest{
+		SystemInstruction: &geminiContent{Parts: []geminiPart{{Text: systemPrompt}}},
+		Contents:          []geminiContent{{Role: "user", Parts: []geminiPart{{Text: content}}}},
+	}
+}
+
+// usage 返回响应中的 token 用量
+func (r geminiResponse) usage() Usage {
+	return Usage{PromptTokens: r.UsageMetadata.PromptTokenCount, CompletionTokens: r.UsageMetadata.CandidatesTokenCount}
+}
+
+// text 拼接第一个候选的全部文本，请求被拒绝时返回错误
+func (r geminiResponse) text() (string, error) {
+	if r.PromptFeedback.BlockReason != "" {
+		return "", fmt.Errorf("Gemini拒绝了请求: %s", r.PromptFeedback.BlockReason)
+	}
+	if len(r.Candidates) == 0 {
+		return "", nil
+	}
+	var text strings.Builder
+	for _, part := range r.Candidates[0].Content.Parts {
+		text.WriteString(part.Text)
+	}
+	return text.String(), nil
+}
@@ -0,0 +1,168 @@
+// Package llm 封装生成摘要、章节、关键词与汇总使用的大模型，
+// 支持火山方舟、OpenAI 兼容接口、本地 Ollama、DeepSeek 与 Gemini，由配置 llm 选择
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/models"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/utils"
+)
+
+// SummaryPrompt 生成摘要的系统提示
+const SummaryPrompt = "你是一个专业的文字总结助手。请对以下文本进行简明扼要的总结，提取关键信息和主要观点。"
+
+// LLMClient 大模型客户端，各服务商的实现都满足它
+type LLMClient interface {
+	// Name 返回服务商与模型，如 openai/gpt-4o-mini
+	Name() string
+	// Chat 以 systemPrompt 为系统提示发送一轮对话，返回模型的回复
+	Chat(systemPrompt, content string) (string, error)
+	// GenerateSummary 生成文本摘要
+	GenerateSummary(content string) (string, error)
+	// ChatStream 以流式方式发送一轮对话，每收到一段文本调用一次 onDelta，返回完整回复。
+	// 不受单次请求超时限制，长文稿不会因等待完整回复而超时，由 ctx 取消
+	ChatStream(ctx context.Context, systemPrompt, content string, onDelta func(string)) (string, error)
+	// GenerateSummaryStream 流式生成文本摘要
+	GenerateSummaryStream(ctx context.Context, content string, onDelta func(string)) (string, error)
+	// Meter 返回累计该客户端 token 用量的计量器
+	Meter() *Meter
+}
+
+// FromConfig 按配置 llm 创建客户端。需要API密钥的服务商未配置密钥时返回 nil，
+// 调用方据此关闭依赖大模型的功能。配置了 llm_fallbacks 或限流时返回在各服务商之间
+// 选择与切换的 Selector
+func FromConfig(config *models.Config) (LLMClient, error) {
+	var providers []SelectorProvider
+	for i, settings := range append([]models.LLMConfig{config.LLM}, config.LLMFallbacks...) {
+		apiKey := settings.Key()
+		if i == 0 {
+			apiKey = config.LLMAPIKey()
+		}
+		if apiKey == "" && settings.ProviderName() != models.LLMProviderOllama {
+			if i > 0 {
+				utils.Warn("备用大模型 %s 未配置API密钥，已跳过", settings.ProviderName())
+			}
+			continue
+		}
+		client, err := newClient(settings, apiKey)
+		if err != nil {
+			return nil, err
+		}
+		providers = append(providers, SelectorProvider{
+			Client:            client,
+			BaseURL:           settings.BaseURL,
+			Weight:            settings.Weight,
+			RequestsPerMinute: settings.RequestsPerMinute,
+		})
+	}
+	if len(providers) == 0 {
+		return nil, nil
+	}
+	if len(providers) == 1 && providers[0].RequestsPerMinute == 0 {
+		return providers[0].Client, nil
+	}
+	return NewSelector(providers), nil
+}
+
+// newClient 创建单个服务商的客户端
+func newClient(settings models.LLMConfig, apiKey string) (LLMClient, error) {
+	switch settings.ProviderName() {
+	case models.LLMProviderVolces:
+		c := NewVolcesAPIClient(apiKey)
+		applySettings(&c.BaseURL, &c.Model, &c.HttpClient, settings)
+		return c, nil
+	case models.LLMProviderOpenAI:
+		c := NewOpenAIClient(OpenAIBaseURL, apiKey, OpenAIModel)
+		applySettings(&c.BaseURL, &c.Model, &c.HttpClient, settings)
+		return c, nil
+	case models.LLMProviderDeepSeek:
+		c := NewDeepSeekClient(apiKey)
+		applySettings(&c.BaseURL, &c.Model, &c.HttpClient, settings)
+		return c, nil
+	case models.LLMProviderOllama:
+		c := NewOllamaClient(OllamaBaseURL, OllamaModel)
+		applySettings(&c.BaseURL, &c.Model, &c.HttpClient, settings)
+		return c, nil
+	case models.LLMProviderGemini:
+		c := NewGeminiClient(apiKey, GeminiModel)
+		applySettings(&c.BaseURL, &c.Model, &c.HttpClient, settings)
+		return c, nil
+	}
+	return nil, fmt.Errorf("不支持的大模型服务商: %s", settings.Provider)
+}
+
+// applySettings 用配置中的地址、模型与超时覆盖服务商的默认值
+func applySettings(baseURL, model *string, httpClient **utils.HTTPClient, settings models.LLMConfig) {
+	if settings.BaseURL != "" {
+		*baseURL = strings.TrimRight(settings.BaseURL, "/")
+	}
+	if settings.Model != "" {
+		*model = settings.Model
+	}
+	if settings.Timeout > 0 {
+		*httpClient = (*httpClient).WithTimeout(time.Duration(settings.Timeout * float64(time.Second)))
+	}
+}
+
+// setMeter 让客户端把用量记到 meter，Selector 用它让各服务商共用一个计量器
+func setMeter(client LLMClient, meter *Meter) {
+	switch c := client.(type) {
+	case *OpenAIClient:
+		c.meter = meter
+	case *OllamaClient:
+		c.meter = meter
+	case *GeminiClient:
+		c.meter = meter
+	case *Selector:
+		c.meter = meter
+	}
+}
+
+// postJSON 与 PostJSON 相同，请求不可取消
+func postJSON(client *utils.HTTPClient, url string, headers map[string]string, request, response interface{}) error {
+	return PostJSON(context.Background(), client, url, headers, request, response)
+}
+
+// PostJSON 以 JSON 发送请求并解析 JSON 响应，由 ctx 取消请求，非 200 状态码时返回包含响应内容的错误。
+// 也供翻译等其他调用 HTTP 接口的包使用
+func PostJSON(ctx context.Context, client *utils.HTTPClient, url string, headers map[string]string, request, response interface{}) error {
+	jsonBytes, err := json.Marshal(request)
+	if err != nil {
+		return fmt.Errorf("序列化请求失败: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(jsonBytes))
+	if err != nil {
+		return fmt.Errorf("创建请求失败: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	utils.Info("发送API请求到 %s", url)
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("发送请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("读取响应失败: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("API返回错误状态码: %d, 响应: %s", resp.StatusCode, string(body))
+	}
+	if err := json.Unmarshal(body, response); err != nil {
+		return fmt.Errorf("解析响应失败: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,265 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/models"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordedRequest 测试服务收到的请求
+type recordedRequest struct {
+	Path   string
+	Header http.Header
+	Body   map[string]interface{}
+}
+
+// newTestServer 返回固定响应并记录请求的服务
+func newTestServer(t *testing.T, reply string) (*httptest.Server, *recordedRequest) {
+	t.Helper()
+	recorded := &recordedRequest{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		recorded.Path = r.URL.Path
+		recorded.Header = r.Header.Clone()
+		json.NewDecoder(r.Body).Decode(&recorded.Body)
+		w.Write([]byte(reply))
+	}))
+	t.Cleanup(server.Close)
+	return server, recorded
+}
+
+// testConfig 指向测试服务的配置
+func testConfig(provider, baseURL string) *models.Config {
+	config := models.NewDefaultConfig()
+	config.LLM = models.LLMConfig{Provider: provider, BaseURL: baseURL + "/", APIKey: "key"}
+	return config
+}
+
+// fromConfig 创建客户端并换成不重试的HTTP客户端
+func fromConfig(t *testing.T, config *models.Config) LLMClient {
+	t.Helper()
+	client, err := FromConfig(config)
+	require.NoError(t, err)
+	require.NotNil(t, client)
+	httpClient := utils.NewHTTPClient(utils.HTTPClientOptions{Timeout: 5 * time.Second, MaxRetries: 1})
+	switch c := client.(type) {
+	case *OpenAIClient:
+		c.HttpClient = httpClient
+	case *OllamaClient:
+		c.HttpClient = httpClient
+	case *GeminiClient:
+		c.HttpClient = httpClient
+	}
+	return client
+}
+
+func TestOpenAICompatible(t *testing.T) {
+	server, recorded := newTestServer(t, `{"choices":[{"message":{"role":"assistant","content":"摘要"}}]}`)
+	for _, provider := range []string{models.LLMProviderVolces, models.LLMProviderOpenAI, models.LLMProviderDeepSeek} {
+		config := testConfig(provider, server.URL)
+		config.LLM.Model = "m1"
+		client := fromConfig(t, config)
+		assert.Equal(t, provider+"/m1", client.Name())
+
+		reply, err := client.GenerateSummary("文本")
+		require.NoError(t, err)
+		assert.Equal(t, "摘要", reply)
+		assert.Equal(t, "/chat/completions", recorded.Path)
+		assert.Equal(t, "Bearer key", recorded.Header.Get("Authorization"))
+		assert.Equal(t, "m1", recorded.Body["model"])
+		messages := recorded.Body["messages"].([]interface{})
+		assert.Equal(t, SummaryPrompt, messages[0].(map[string]interface{})["content"])
+	}
+}
+
+func TestOllama(t *testing.T) {
+	server, recorded := newTestServer(t, `{"model":"qwen2.5","message":{"role":"assistant","content":"你好"},"done":true}`)
+	config := testConfig(models.LLMProviderOllama, server.URL)
+	config.LLM.APIKey = ""
+	client := fromConfig(t, config)
+	assert.Equal(t, "ollama/"+OllamaModel, client.Name())
+
+	reply, err := client.Chat("系统", "内容")
+	require.NoError(t, err)
+	assert.Equal(t, "你好", reply)
+	assert.Equal(t, "/api/chat", recorded.Path)
+	assert.Equal(t, false, recorded.Body["stream"])
+	assert.Empty(t, recorded.Header.Get("Authorization"))
+}
+
+func TestGemini(t *testing.T) {
+	server, recorded := newTestServer(t, `{"candidates":[{"content":{"role":"model","parts":[{"text":"第一"},{"text":"第二"}]},"finishReason":"STOP"}]}`)
+	client := fromConfig(t, testConfig(models.LLMProviderGemini, server.URL))
+
+	reply, err := client.Chat("系统", "内容")
+	require.NoError(t, err)
+	assert.Equal(t, "第一第二", reply)
+	assert.Equal(t, "/models/"+GeminiModel+":generateContent", recorded.Path)
+	assert.Equal(t, "key", recorded.Header.Get("x-goog-api-key"))
+	system := recorded.Body["systemInstruction"].(map[string]interface{})
+	assert.Equal(t, "系统", system["parts"].([]interface{})[0].(map[string]interface{})["text"])
+}
+
+func TestErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "bad key", http.StatusUnauthorized)
+	}))
+	defer server.Close()
+	client := fromConfig(t, testConfig(models.LLMProviderOpenAI, server.URL))
+	_, err := client.Chat("系统", "内容")
+	assert.ErrorContains(t, err, "401")
+}
+
+// TestFromConfigKeys 测试未配置密钥时返回 nil，火山方舟兼容旧的 volces_api_key
+func TestFromConfigKeys(t *testing.T) {
+	for _, env := range []string{"VOLCES_API_KEY", "OPENAI_API_KEY", "DEEPSEEK_API_KEY", "GEMINI_API_KEY"} {
+		t.Setenv(env, "")
+	}
+	config := models.NewDefaultConfig()
+	client, err := FromConfig(config)
+	require.NoError(t, err)
+	assert.Nil(t, client)
+
+	config.VolcesAPIKey = "old"
+	client, err = FromConfig(config)
+	require.NoError(t, err)
+	require.NotNil(t, client)
+	assert.Equal(t, "old", client.(*OpenAIClient).APIKey)
+	assert.Equal(t, VolcesBaseURL, client.(*OpenAIClient).BaseURL)
+
+	config.LLM.Provider = models.LLMProviderDeepSeek
+	client, err = FromConfig(config)
+	require.NoError(t, err)
+	assert.Nil(t, client, "volces_api_key 只用于火山方舟")
+
+	t.Setenv("DEEPSEEK_API_KEY", "ds")
+	client, err = FromConfig(config)
+	require.NoError(t, err)
+	assert.Equal(t, "deepseek/"+DeepSeekModel, client.Name())
+}
+
+// TestChatStream 测试各服务商的流式响应格式，逐段回调并返回完整回复
+func TestChatStream(t *testing.T) {
+	cases := []struct {
+		provider string
+		reply    string
+		path     string
+	}{
+		{models.LLMProviderOpenAI, "data: {\"choices\":[{\"delta\":{\"role\":\"assistant\"}}]}\n\n" +
+			"data: {\"choices\":[{\"delta\":{\"content\":\"第一\"}}]}\n\n" +
+			": keepalive\n\n" +
+			"data: {\"choices\":[{\"delta\":{\"content\":\"第二\"}}]}\n\n" +
+			"data: [DONE]\n\n", "/chat/completions"},
+		{models.LLMProviderOllama, "{\"message\":{\"content\":\"第一\"},\"done\":false}\n" +
+			"{\"message\":{\"content\":\"第二\"},\"done\":false}\n" +
+			"{\"message\":{\"content\":\"\"},\"done\":true}\n", "/api/chat"},
+		{models.LLMProviderGemini, "data: {\"candidates\":[{\"content\":{\"parts\":[{\"text\":\"第一\"}]}}]}\n\n" +
+			"data: {\"candidates\":[{\"content\":{\"parts\":[{\"text\":\"第二\"}]},\"finishReason\":\"STOP\"}]}\n\n",
+			"/models/" + GeminiModel + ":streamGenerateContent"},
+	}
+	for _, c := range cases {
+		t.Run(c.provider, func(t *testing.T) {
+			server, recorded := newTestServer(t, c.reply)
+			client := fromConfig(t, testConfig(c.provider, server.URL))
+			var deltas []string
+			reply, err := client.GenerateSummaryStream(context.Background(), "内容", func(delta string) {
+				deltas = append(deltas, delta)
+			})
+			require.NoError(t, err)
+			assert.Equal(t, "第一第二", reply)
+			assert.Equal(t, []string{"第一", "第二"}, deltas)
+			assert.Equal(t, c.path, recorded.Path)
+			if c.provider != models.LLMProviderGemini {
+				assert.Equal(t, true, recorded.Body["stream"])
+			}
+		})
+	}
+
+	server, _ := newTestServer(t, "data: {\"error\":{\"message\":\"上下文过长\"}}\n\n")
+	client := fromConfig(t, testConfig(models.LLMProviderOpenAI, server.URL))
+	_, err := client.ChatStream(context.Background(), "系统", "内容", nil)
+	assert.ErrorContains(t, err, "上下文过长")
+}
+
+// TestUsage 测试从各服务商的响应中读取 token 用量，按客户端累计并交给记录函数
+func TestUsage(t *testing.T) {
+	var events []UsageEvent
+	SetUsageRecorder(func(event UsageEvent) { events = append(events, event) })
+	defer SetUsageRecorder(nil)
+
+	cases := []struct {
+		provider string
+		reply    string
+	}{
+		{models.LLMProviderOpenAI, `{"choices":[{"message":{"content":"好"}}],"usage":{"prompt_tokens":12,"completion_tokens":3,"total_tokens":15}}`},
+		{models.LLMProviderOllama, `{"message":{"content":"好"},"done":true,"prompt_eval_count":12,"eval_count":3}`},
+		{models.LLMProviderGemini, `{"candidates":[{"content":{"parts":[{"text":"好"}]}}],"usageMetadata":{"promptTokenCount":12,"candidatesTokenCount":3}}`},
+	}
+	for _, c := range cases {
+		server, _ := newTestServer(t, c.reply)
+		client := fromConfig(t, testConfig(c.provider, server.URL))
+		client.Meter().SetJob("a.mp4")
+		_, err := client.Chat("系统", "内容")
+		require.NoError(t, err)
+		_, err = client.Chat("系统", "内容")
+		require.NoError(t, err)
+		assert.Equal(t, Usage{Requests: 2, PromptTokens: 24, CompletionTokens: 6}, client.Meter().Usage(), c.provider)
+	}
+	require.Len(t, events, 6)
+	assert.Equal(t, "a.mp4", events[0].Job)
+	assert.Equal(t, models.LLMProviderOpenAI, events[0].Provider)
+	assert.Equal(t, 15, events[0].Usage.TotalTokens())
+
+	// 流式请求要求在最后一段返回用量
+	server, recorded := newTestServer(t, "data: {\"choices\":[{\"delta\":{\"content\":\"好\"}}]}\n\n"+
+		"data: {\"choices\":[],\"usage\":{\"prompt_tokens\":7,\"completion_tokens\":1}}\n\n"+
+		"data: [DONE]\n\n")
+	client := fromConfig(t, testConfig(models.LLMProviderOpenAI, server.URL))
+	_, err := client.ChatStream(context.Background(), "系统", "内容", nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"include_usage": true}, recorded.Body["stream_options"])
+	assert.Equal(t, Usage{Requests: 1, PromptTokens: 7, CompletionTokens: 1}, client.Meter().Usage())
+}
+
+// TestEmbedder 测试按服务商请求向量接口，未设置 embedding_model 时不创建
+func TestEmbedder(t *testing.T) {
+	config := testConfig(models.LLMProviderOpenAI, "http://unused")
+	embedder, err := EmbedderFromConfig(config)
+	require.NoError(t, err)
+	assert.Nil(t, embedder)
+
+	cases := []struct {
+		provider string
+		path     string
+		reply    string
+	}{
+		{models.LLMProviderOpenAI, "/embeddings", `{"data":[{"index":1,"embedding":[0,1]},{"index":0,"embedding":[1,0]}]}`},
+		{models.LLMProviderOllama, "/api/embed", `{"embeddings":[[1,0],[0,1]]}`},
+		{models.LLMProviderGemini, "/models/e:batchEmbedContents", `{"embeddings":[{"values":[1,0]},{"values":[0,1]}]}`},
+	}
+	for _, c := range cases {
+		server, recorded := newTestServer(t, c.reply)
+		config := testConfig(c.provider, server.URL)
+		config.EmbeddingModel = "e"
+		embedder, err := EmbedderFromConfig(config)
+		require.NoError(t, err)
+		assert.Equal(t, c.provider+"/e", embedder.Name())
+
+		vectors, err := embedder.Embed(context.Background(), []string{"一", "二"})
+		require.NoError(t, err, c.provider)
+		assert.Equal(t, [][]float32{{1, 0}, {0, 1}}, vectors, c.provider)
+		assert.Equal(t, c.path, recorded.Path)
+	}
+
+	config = testConfig(models.LLMProviderDeepSeek, "http://unused")
+	config.EmbeddingModel = "e"
+	_, err = EmbedderFromConfig(config)
+	assert.Error(t, err)
+}
@@ -0,0 +1,132 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/utils"
+)
+
+// Ollama 的默认地址与模型
+const (
+	OllamaBaseURL = "http://localhost:11434"
+	OllamaModel   = "qwen2.5"
+)
+
+// OllamaClient 访问本地 Ollama 的 /api/chat 接口，不需要API密钥
+type OllamaClient struct {
+	BaseURL    string
+	Model      string
+	HttpClient *utils.HTTPClient
+	meter      *Meter
+}
+
+// ollamaRequest Ollama 对话请求，关闭流式输出时一次返回完整回复
+type ollamaRequest struct {
+	Model    string        `json:"model"`
+	Messages []ChatMessage `json:"messages"`
+	Stream   bool          `json:"stream"`
+}
+
+// ollamaResponse Ollama 对话响应
+type ollamaResponse struct {
+	Model   string      `json:"model"`
+	Message ChatMessage `json:"message"`
+	Done    bool        `json:"done"`
+	Error   string      `json:"error"`
+	// 输入与输出的 token 数，流式响应只在最后一行提供
+	PromptEvalCount int `json:"prompt_eval_count"`
+	EvalCount       int `json:"eval_count"`
+}
+
+// NewOllamaClient 创建 Ollama 客户端
+func NewOllamaClient(baseURL, model string) *OllamaClient {
+	return &OllamaClient{BaseURL: baseURL, Model: model, HttpClient: utils.DefaultHTTPClient(), meter: &Meter{}}
+}
+
+// Meter 返回累计用量的计量器
+func (c *OllamaClient) Meter() *Meter {
+	return c.meter
+}
+
+// Name 返回服务商与模型
+func (c *OllamaClient) Name() string {
+	return "ollama/" + c.Model
+}
+
+// GenerateSummary 生成文本摘要
+func (c *OllamaClient) GenerateSummary(content string) (string, error) {
+	return c.Chat(SummaryPrompt, content)
+}
+
+// Chat 以 systemPrompt 为系统提示发送一轮对话，返回模型的回复
+func (c *OllamaClient) Chat(systemPrompt, content string) (string, error) {
+	var response ollamaResponse
+	if err := postJSON(c.HttpClient, c.BaseURL+"/api/chat", nil, c.request(systemPrompt, content, false), &response); err != nil {
+		return "", err
+	}
+	if response.Error != "" {
+		return "", fmt.Errorf("Ollama返回错误: %s", response.Error)
+	}
+	c.meter.record("ollama", c.Model, response.usage())
+	if response.Message.Content == "" {
+		return "", fmt.Errorf("API响应中没有生成内容")
+	}
+	return response.Message.Content, nil
+}
+
+// GenerateSummaryStream 流式生成文本摘要，每收到一段文本调用一次 onDelta，返回完整摘要
+func (c *OllamaClient) GenerateSummaryStream(ctx context.Context, content string, onDelta func(string)) (string, error) {
+	return c.ChatStream(ctx, SummaryPrompt, content, onDelta)
+}
+
+// ChatStream 以流式方式发送一轮对话。Ollama 的流式响应每行一个 JSON 对象，最后一行 done 为 true
+func (c *OllamaClient) ChatStream(ctx context.Context, systemPrompt, content string, onDelta func(string)) (string, error) {
+	body, err := postStream(ctx, c.HttpClient, c.BaseURL+"/api/chat", nil, c.request(systemPrompt, content, true))
+	if err != nil {
+		return "", err
+	}
+	defer body.Close()
+
+	add, result := collect(onDelta)
+	var usage Usage
+	err = readLines(body, func(line string) error {
+		var chunk ollamaResponse
+		if err := json.Unmarshal([]byte(line), &chunk); err != nil {
+			return fmt.Errorf("解析流式响应失败: %w", err)
+		}
+		if chunk.Error != "" {
+			return fmt.Errorf("Ollama返回错误: %s", chunk.Error)
+		}
+		add(chunk.Message.Content)
+		if chunk.Done {
+			usage = chunk.usage()
+			return io.EOF
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	c.meter.record("ollama", c.Model, usage)
+	return result()
+}
+
+// request 构建一轮对话的请求
+func (c *OllamaClient) request(systemPrompt, content string, stream bool) ollamaRequest {
+	return ollamaRequest{
+		Model: c.Model,
+		Messages: []ChatMessage{
+			{Role: "system", Content: systemPrompt},
+			{Role: "user", Content: content},
+		},
+		Stream: stream,
+	}
+}
+
+// usage 返回响应中的 token 用量
+func (r ollamaResponse) usage() Usage {
+	return Usage{PromptTokens: r.PromptEvalCount, CompletionTokens: r.EvalCount}
+}
@@ -0,0 +1,188 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/utils"
+)
+
+// OpenAI 与 DeepSeek 的默认地址与模型
+const (
+	OpenAIBaseURL   = "https://api.openai.com/v1"
+	OpenAIModel     = "gpt-4o-mini"
+	DeepSeekBaseURL = "https://api.deepseek.com"
+	DeepSeekModel   = "deepseek-chat"
+)
+
+// OpenAIClient 访问兼容 OpenAI <BaseURL>/chat/completions 接口的服务，
+// 火山方舟、DeepSeek 以及 vLLM、LM Studio 等本地服务都使用它
+type OpenAIClient struct {
+	Provider   string            // 服务商名称，用于日志
+	APIKey     string            // 为空时不发送 Authorization
+	BaseURL    string            // 接口地址，不含 /chat/completions
+	Model      string            // 模型名称
+	HttpClient *utils.HTTPClient // 共享的重试客户端
+	meter      *Meter
+}
+
+// ChatMessage 表示聊天消息
+type ChatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// ChatRequest 表示对API的请求
+type ChatRequest struct {
+	Model    string        `json:"model"`
+	Messages []ChatMessage `json:"messages"`
+	Stream   bool          `json:"stream,omitempty"`
+	// StreamOptions 流式请求时要求在最后一段返回用量
+	StreamOptions *StreamOptions `json:"stream_options,omitempty"`
+}
+
+// StreamOptions 流式请求的选项
+type StreamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
+}
+
+// chatUsage 响应中的 token 用量
+type chatUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+// ChatResponse 表示API的响应
+type ChatResponse struct {
+	ID      string `json:"id"`
+	Object  string `json:"object"`
+	Created int64  `json:"created"`
+	Model   string `json:"model"`
+	Choices []struct {
+		Index        int         `json:"index"`
+		Message      ChatMessage `json:"message"`
+		FinishReason string      `json:"finish_reason"`
+	} `json:"choices"`
+	Usage chatUsage `json:"usage"`
+}
+
+// chatStreamChunk 流式响应中的一段，增量文本位于 delta
+type chatStreamChunk struct {
+	Choices []struct {
+		Delta ChatMessage `json:"delta"`
+	} `json:"choices"`
+	Usage *chatUsage `json:"usage"` // 要求返回用量时在最后一段提供
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// NewOpenAIClient 创建 OpenAI 兼容接口的客户端
+func NewOpenAIClient(baseURL, apiKey, model string) *OpenAIClient {
+	return &OpenAIClient{
+		Provider:   "openai",
+		APIKey:     apiKey,
+		BaseURL:    baseURL,
+		Model:      model,
+		HttpClient: utils.DefaultHTTPClient(),
+		meter:      &Meter{},
+	}
+}
+
+// NewDeepSeekClient 创建访问 DeepSeek 的客户端
+func NewDeepSeekClient(apiKey string) *OpenAIClient {
+	client := NewOpenAIClient(DeepSeekBaseURL, apiKey, DeepSeekModel)
+	client.Provider = "deepseek"
+	return client
+}
+
+// Name 返回服务商与模型
+func (c *OpenAIClient) Name() string {
+	return c.Provider + "/" + c.Model
+}
+
+// Meter 返回累计用量的计量器
+func (c *OpenAIClient) Meter() *Meter {
+	return c.meter
+}
+
+// GenerateSummary 使用API生成文本摘要
+func (c *OpenAIClient) GenerateSummary(content string) (string, error) {
+	return c.Chat(SummaryPrompt, content)
+}
+
+// Chat 以 systemPrompt 为系统提示发送一轮对话，返回模型的回复
+func (c *OpenAIClient) Chat(systemPrompt, content string) (string, error) {
+	var response ChatResponse
+	if err := postJSON(c.HttpClient, c.BaseURL+"/chat/completions", c.headers(), c.request(systemPrompt, content), &response); err != nil {
+		return "", err
+	}
+	c.meter.record(c.Provider, c.Model, Usage{PromptTokens: response.Usage.PromptTokens, CompletionTokens: response.Usage.CompletionTokens})
+	if len(response.Choices) > 0 {
+		return response.Choices[0].Message.Content, nil
+	}
+	return "", fmt.Errorf("API响应中没有生成内容")
+}
+
+// GenerateSummaryStream 流式生成文本摘要，每收到一段文本调用一次 onDelta，返回完整摘要
+func (c *OpenAIClient) GenerateSummaryStream(ctx context.Context, content string, onDelta func(string)) (string, error) {
+	return c.ChatStream(ctx, SummaryPrompt, content, onDelta)
+}
+
+// ChatStream 以流式（SSE）方式发送一轮对话，每收到一段文本调用一次 onDelta，返回完整回复
+func (c *OpenAIClient) ChatStream(ctx context.Context, systemPrompt, content string, onDelta func(string)) (string, error) {
+	request := c.request(systemPrompt, content)
+	request.Stream = true
+	request.StreamOptions = &StreamOptions{IncludeUsage: true}
+	body, err := postStream(ctx, c.HttpClient, c.BaseURL+"/chat/completions", c.headers(), request)
+	if err != nil {
+		return "", err
+	}
+	defer body.Close()
+
+	add, result := collect(onDelta)
+	var usage Usage
+	err = readSSE(body, func(data []byte) error {
+		var chunk chatStreamChunk
+		if err := json.Unmarshal(data, &chunk); err != nil {
+			return fmt.Errorf("解析流式响应失败: %w", err)
+		}
+		if chunk.Error != nil {
+			return fmt.Errorf("API返回错误: %s", chunk.Error.Message)
+		}
+		for _, choice := range chunk.Choices {
+			add(choice.Delta.Content)
+		}
+		if chunk.Usage != nil {
+			usage = Usage{PromptTokens: chunk.Usage.PromptTokens, CompletionTokens: chunk.Usage.CompletionTokens}
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	c.meter.record(c.Provider, c.Model, usage)
+	return result()
+}
+
+// request 构建一轮对话的请求
+func (c *OpenAIClient) request(systemPrompt, content string) ChatRequest {
+	return ChatRequest{
+		Model: c.Model,
+		Messages: []ChatMessage{
+			{Role: "system", Content: systemPrompt},
+			{Role: "user", Content: content},
+		},
+	}
+}
+
+// headers 返回认证请求头，未设置API密钥时为空
+func (c *OpenAIClient) headers() map[string]string {
+	headers := map[string]string{}
+	if c.APIKey != "" {
+		headers["Authorization"] = "Bearer " + c.APIKey
+	}
+	return headers
+}
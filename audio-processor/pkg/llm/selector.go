@@ -0,0 +1,297 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/utils"
+)
+
+// 服务商连续失败 failureThreshold 次后熔断，probeInterval 后允许一次试探请求
+const (
+	failureThreshold = 3
+	probeInterval    = 5 * time.Minute
+)
+
+// SelectorProvider Selector 中的一个服务商
+type SelectorProvider struct {
+	Client            LLMClient
+	BaseURL           string // 配置的接口地址，与服务商和模型一起区分健康状态
+	Weight            int    // 选择权重，全部为 0 时按顺序使用
+	RequestsPerMinute int    // 每分钟最多请求数，0 表示不限制
+}
+
+// Selector 在多个大模型服务商之间选择：按权重随机选择健康的服务商，请求失败时依次改用其他服务商，
+// 连续失败的服务商熔断一段时间，超出每分钟请求数的服务商优先让给其他服务商。
+// 健康状态与限流在进程内按服务商共享，每个任务创建的 Selector 都能看到之前任务的失败
+type Selector struct {
+	providers []SelectorProvider
+	states    []*providerState
+	meter     *Meter
+}
+
+// providerState 服务商的健康状态与限流器
+type providerState struct {
+	provider            string
+	model               string
+	limiter             *utils.RateLimiter // 未限流时为 nil
+	consecutiveFailures int
+	openUntil           time.Time // 熔断截止时间，之后允许试探请求
+	requests            int
+	failures            int
+}
+
+var (
+	statesMu sync.Mutex
+	states   = make(map[string]*providerState)
+)
+
+// ProviderHealth 服务商的健康状态，用于监控
+type ProviderHealth struct {
+	Provider            string
+	Model               string
+	Available           bool // 未熔断（熔断后试探成功即恢复）
+	ConsecutiveFailures int
+	Requests            int
+	Failures            int
+}
+
+// NewSelector 创建在 providers 之间选择的客户端，各服务商的用量都记到 Selector 的计量器
+func NewSelector(providers []SelectorProvider) *Selector {
+	s := &Selector{providers: providers, meter: &Meter{}}
+	statesMu.Lock()
+	defer statesMu.Unlock()
+	for _, p := range providers {
+		setMeter(p.Client, s.meter)
+		key := p.Client.Name() + "@" + p.BaseURL
+		state, ok := states[key]
+		if !ok {
+			provider, model, _ := strings.Cut(p.Client.Name(), "/")
+			state = &providerState{provider: provider, model: model}
+			states[key] = state
+		}
+		if p.RequestsPerMinute > 0 && (state.limiter == nil || state.limiter.PerMinute() != p.RequestsPerMinute) {
+			state.limiter = utils.NewRateLimiter(p.RequestsPerMinute, time.Now())
+		}
+		s.states = append(s.states, state)
+	}
+	return s
+}
+
+// Name 返回各服务商的名称，以逗号分隔
+func (s *Selector) Name() string {
+	names := make([]string, len(s.providers))
+	for i, p := range s.providers {
+		names[i] = p.Client.Name()
+	}
+	return strings.Join(names, ",")
+}
+
+// Meter 返回各服务商共用的计量器
+func (s *Selector) Meter() *Meter {
+	return s.meter
+}
+
+// Chat 发送一轮对话，失败时改用其他服务商
+func (s *Selector) Chat(systemPrompt, content string) (string, error) {
+	return s.run(context.Background(), func(client LLMClient) (string, error) {
+		return client.Chat(systemPrompt, content)
+	})
+}
+
+// GenerateSummary 生成文本摘要
+func (s *Selector) GenerateSummary(content string) (string, error) {
+	return s.Chat(SummaryPrompt, content)
+}
+
+// ChatStream 流式发送一轮对话。已经输出部分文本后失败时不再改用其他服务商，避免输出重复的内容
+func (s *Selector) ChatStream(ctx context.Context, systemPrompt, content string, onDelta func(string)) (string, error) {
+	started := false
+	return s.run(ctx, func(client LLMClient) (string, error) {
+		reply, err := client.ChatStream(ctx, systemPrompt, content, func(delta string) {
+			started = true
+			if onDelta != nil {
+				onDelta(delta)
+			}
+		})
+		if err != nil && started {
+			return reply, &partialError{err}
+		}
+		return reply, err
+	})
+}
+
+// GenerateSummaryStream 流式生成文本摘要
+func (s *Selector) GenerateSummaryStream(ctx context.Context, content string, onDelta func(string)) (string, error) {
+	return s.ChatStream(ctx, SummaryPrompt, content, onDelta)
+}
+
+// partialError 已输出部分回复后的失败，不能再改用其他服务商
+type partialError struct{ err error }
+
+func (e *partialError) Error() string { return e.err.Error() }
+func (e *partialError) Unwrap() error { return e.err }
+
+// run 依次在选出的服务商上调用 call，直到成功、全部失败或 ctx 取消
+func (s *Selector) run(ctx context.Context, call func(LLMClient) (string, error)) (string, error) {
+	tried := make([]bool, len(s.providers))
+	var errs []error
+	for {
+		index, wait, ok := s.next(tried, time.Now())
+		if !ok {
+			break
+		}
+		tried[index] = true
+		name := s.providers[index].Client.Name()
+		if wait > 0 {
+			utils.Info("大模型 %s 已达每分钟请求上限，等待 %v", name, wait.Round(time.Second))
+			timer := time.NewTimer(wait)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return "", ctx.Err()
+			case <-timer.C:
+			}
+		}
+
+		reply, err := call(s.providers[index].Client)
+		if ctx.Err() != nil {
+			// 取消不是服务商的问题，不计入失败
+			return reply, ctx.Err()
+		}
+		s.report(index, err == nil, time.Now())
+		if err == nil {
+			return reply, nil
+		}
+		var partial *partialError
+		if errors.As(err, &partial) {
+			return reply, err
+		}
+		utils.Warn("大模型 %s 请求失败: %v", name, err)
+		errs = append(errs, fmt.Errorf("%s: %w", name, err))
+	}
+	if len(errs) == 0 {
+		return "", fmt.Errorf("所有大模型服务商都已熔断，请稍后重试")
+	}
+	return "", fmt.Errorf("所有大模型服务商都请求失败: %w", errors.Join(errs...))
+}
+
+// next 选择下一个尝试的服务商：未尝试且未熔断的服务商中，优先选择不需要等待限流的，
+// 其中按权重随机选择，权重全为 0 时按配置顺序；都需要等待时选择等待最短的。
+// 选中后占用一个令牌，返回需要等待的时间
+func (s *Selector) next(tried []bool, now time.Time) (int, time.Duration, bool) {
+	statesMu.Lock()
+	defer statesMu.Unlock()
+
+	var ready []int
+	best, bestWait := -1, time.Duration(0)
+	for i, state := range s.states {
+		if tried[i] || !state.available(now) {
+			continue
+		}
+		wait := time.Duration(0)
+		if state.limiter != nil {
+			wait = state.limiter.Wait(now)
+		}
+		if wait == 0 {
+			ready = append(ready, i)
+		} else if best < 0 || wait < bestWait {
+			best, bestWait = i, wait
+		}
+	}
+
+	if len(ready) > 0 {
+		best, bestWait = s.pick(ready), 0
+	}
+	if best < 0 {
+		return 0, 0, false
+	}
+	state := s.states[best]
+	if state.limiter != nil {
+		// 按等待之后的时间扣除令牌，等待期间令牌刚好补满一个
+		state.limiter.Take(now.Add(bestWait))
+	}
+	if !state.openUntil.IsZero() {
+		// 熔断到期后只放行一次试探，失败时重新熔断
+		state.openUntil = now.Add(probeInterval)
+	}
+	return best, bestWait, true
+}
+
+// pick 在候选服务商中按权重随机选择，权重全为 0 时返回第一个
+func (s *Selector) pick(candidates []int) int {
+	total := 0
+	for _, i := range candidates {
+		total += s.providers[i].Weight
+	}
+	if total == 0 {
+		return candidates[0]
+	}
+	r := rand.Intn(total)
+	for _, i := range candidates {
+		r -= s.providers[i].Weight
+		if r < 0 {
+			return i
+		}
+	}
+	return candidates[0]
+}
+
+// report 记录一次请求的结果，连续失败达到阈值时熔断
+func (s *Selector) report(index int, success bool, now time.Time) {
+	statesMu.Lock()
+	defer statesMu.Unlock()
+	state := s.states[index]
+	state.requests++
+	if success {
+		if !state.openUntil.IsZero() {
+			utils.Info("大模型 %s/%s 已恢复", state.provider, state.model)
+		}
+		state.consecutiveFailures = 0
+		state.openUntil = time.Time{}
+		return
+	}
+	state.failures++
+	state.consecutiveFailures++
+	if state.consecutiveFailures >= failureThreshold {
+		if state.openUntil.IsZero() {
+			utils.Warn("大模型 %s/%s 连续失败 %d 次，%v 内不再使用", state.provider, state.model, state.consecutiveFailures, probeInterval)
+		}
+		state.openUntil = now.Add(probeInterval)
+	}
+}
+
+// available 判断服务商未熔断或已到试探时间
+func (state *providerState) available(now time.Time) bool {
+	return state.openUntil.IsZero() || !now.Before(state.openUntil)
+}
+
+// ProvidersHealth 返回进程内使用过的服务商的健康状态，按名称排序
+func ProvidersHealth() []ProviderHealth {
+	statesMu.Lock()
+	defer statesMu.Unlock()
+	health := make([]ProviderHealth, 0, len(states))
+	for _, state := range states {
+		health = append(health, ProviderHealth{
+			Provider:            state.provider,
+			Model:               state.model,
+			Available:           state.consecutiveFailures < failureThreshold,
+			ConsecutiveFailures: state.consecutiveFailures,
+			Requests:            state.requests,
+			Failures:            state.failures,
+		})
+	}
+	sort.Slice(health, func(i, j int) bool {
+		if health[i].Provider != health[j].Provider {
+			return health[i].Provider < health[j].Provider
+		}
+		return health[i].Model < health[j].Model
+	})
+	return health
+}
@@ -0,0 +1,134 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClient 返回固定回复或错误的客户端，流式请求先输出 deltas 再返回结果
+type fakeClient struct {
+	name   string
+	err    error
+	deltas []string
+	calls  int
+}
+
+func (f *fakeClient) Name() string  { return f.name }
+func (f *fakeClient) Meter() *Meter { return nil }
+
+func (f *fakeClient) Chat(systemPrompt, content string) (string, error) {
+	f.calls++
+	if f.err != nil {
+		return "", f.err
+	}
+	return f.name, nil
+}
+
+func (f *fakeClient) GenerateSummary(content string) (string, error) {
+	return f.Chat(SummaryPrompt, content)
+}
+
+func (f *fakeClient) ChatStream(ctx context.Context, systemPrompt, content string, onDelta func(string)) (string, error) {
+	for _, delta := range f.deltas {
+		onDelta(delta)
+	}
+	return f.Chat(systemPrompt, content)
+}
+
+func (f *fakeClient) GenerateSummaryStream(ctx context.Context, content string, onDelta func(string)) (string, error) {
+	return f.ChatStream(ctx, SummaryPrompt, content, onDelta)
+}
+
+// newTestSelector 以测试名作为地址创建 Selector，避免与其他测试共享健康状态
+func newTestSelector(t *testing.T, providers ...SelectorProvider) *Selector {
+	t.Helper()
+	for i := range providers {
+		providers[i].BaseURL = t.Name()
+	}
+	return NewSelector(providers)
+}
+
+// TestSelectorFallback 测试失败时改用备用服务商，连续失败后熔断不再尝试
+func TestSelectorFallback(t *testing.T) {
+	primary := &fakeClient{name: "a/m", err: errors.New("503")}
+	backup := &fakeClient{name: "b/m"}
+	selector := newTestSelector(t, SelectorProvider{Client: primary}, SelectorProvider{Client: backup})
+
+	for i := 0; i < failureThreshold+1; i++ {
+		reply, err := selector.Chat("系统", "内容")
+		require.NoError(t, err)
+		assert.Equal(t, "b/m", reply)
+	}
+	assert.Equal(t, failureThreshold, primary.calls, "熔断后不再请求主服务商")
+	assert.Equal(t, failureThreshold+1, backup.calls)
+	for _, health := range ProvidersHealth() {
+		if health.Provider == "a" {
+			assert.False(t, health.Available)
+		}
+	}
+
+	backup.err = errors.New("429")
+	_, err := selector.Chat("系统", "内容")
+	assert.ErrorContains(t, err, "b/m: 429")
+}
+
+// TestSelectorRateLimit 测试超出每分钟请求数时优先使用其他服务商
+func TestSelectorRateLimit(t *testing.T) {
+	limited := &fakeClient{name: "a/m"}
+	other := &fakeClient{name: "b/m"}
+	selector := newTestSelector(t,
+		SelectorProvider{Client: limited, Weight: 1, RequestsPerMinute: 1},
+		SelectorProvider{Client: other})
+
+	reply, err := selector.Chat("系统", "内容")
+	require.NoError(t, err)
+	assert.Equal(t, "a/m", reply, "只有 a 有权重")
+	reply, err = selector.Chat("系统", "内容")
+	require.NoError(t, err)
+	assert.Equal(t, "b/m", reply, "a 已达每分钟请求上限")
+}
+
+// TestSelectorStream 测试流式请求输出部分文本后失败时不改用其他服务商
+func TestSelectorStream(t *testing.T) {
+	broken := &fakeClient{name: "a/m", err: errors.New("连接中断"), deltas: []string{"部分"}}
+	backup := &fakeClient{name: "b/m"}
+	selector := newTestSelector(t, SelectorProvider{Client: broken}, SelectorProvider{Client: backup})
+
+	var output string
+	_, err := selector.ChatStream(context.Background(), "系统", "内容", func(delta string) { output += delta })
+	assert.ErrorContains(t, err, "连接中断")
+	assert.Equal(t, "部分", output)
+	assert.Equal(t, 0, backup.calls)
+
+	broken.deltas = nil
+	reply, err := selector.ChatStream(context.Background(), "系统", "内容", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "b/m", reply)
+}
+
+// TestFromConfigFallbacks 测试配置备用大模型时返回 Selector，缺少密钥的备用服务商被跳过
+func TestFromConfigFallbacks(t *testing.T) {
+	t.Setenv("GEMINI_API_KEY", "")
+	config := testConfig(models.LLMProviderOpenAI, "http://primary")
+	config.LLM.Model = "m1"
+	config.LLMFallbacks = []models.LLMConfig{
+		{Provider: models.LLMProviderGemini},
+		{Provider: models.LLMProviderOllama, Timeout: 30},
+	}
+	client, err := FromConfig(config)
+	require.NoError(t, err)
+	selector, ok := client.(*Selector)
+	require.True(t, ok)
+	assert.Equal(t, "openai/m1,ollama/"+OllamaModel, selector.Name())
+	assert.Same(t, selector.Meter(), selector.providers[1].Client.Meter(), "各服务商共用计量器")
+
+	config.LLMFallbacks = nil
+	client, err = FromConfig(config)
+	require.NoError(t, err)
+	assert.IsType(t, &OpenAIClient{}, client, "只有一个服务商且不限流时不使用 Selector")
+}
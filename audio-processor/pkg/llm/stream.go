@@ -0,0 +1,103 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/utils"
+)
+
+// maxStreamLine 流式响应中单行的最大长度
+const maxStreamLine = 1 << 20
+
+// postStream 以 JSON 发送流式请求，返回响应体，由调用方关闭。
+// 流式响应可能持续很久，不使用客户端的单次请求超时，由 ctx 控制
+func postStream(ctx context.Context, client *utils.HTTPClient, url string, headers map[string]string, request interface{}) (io.ReadCloser, error) {
+	jsonBytes, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("序列化请求失败: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(jsonBytes))
+	if err != nil {
+		return nil, fmt.Errorf("创建请求失败: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	utils.Info("发送流式API请求到 %s", url)
+	resp, err := client.WithTimeout(0).Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("发送请求失败: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+		return nil, fmt.Errorf("API返回错误状态码: %d, 响应: %s", resp.StatusCode, string(body))
+	}
+	return resp.Body, nil
+}
+
+// readLines 逐行读取响应，跳过空行，onLine 返回 io.EOF 时正常结束
+func readLines(body io.Reader, onLine func(line string) error) error {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 64<<10), maxStreamLine)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if err := onLine(line); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("读取流式响应失败: %w", err)
+	}
+	return nil
+}
+
+// readSSE 读取 Server-Sent Events 响应，对每个 data 字段调用 onData，遇到 [DONE] 时结束。
+// 各服务商的事件都只有一行 data，注释与其他字段被忽略
+func readSSE(body io.Reader, onData func(data []byte) error) error {
+	return readLines(body, func(line string) error {
+		if !strings.HasPrefix(line, "data:") {
+			return nil
+		}
+		data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		if data == "[DONE]" {
+			return io.EOF
+		}
+		return onData([]byte(data))
+	})
+}
+
+// collect 返回拼接各段文本并转发给 onDelta 的回调，以及读取完整文本的函数
+func collect(onDelta func(string)) (func(string), func() (string, error)) {
+	var text strings.Builder
+	add := func(delta string) {
+		if delta == "" {
+			return
+		}
+		text.WriteString(delta)
+		if onDelta != nil {
+			onDelta(delta)
+		}
+	}
+	result := func() (string, error) {
+		if text.Len() == 0 {
+			return "", fmt.Errorf("API响应中没有生成内容")
+		}
+		return text.String(), nil
+	}
+	return add, result
+}
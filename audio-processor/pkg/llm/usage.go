@@ -0,0 +1,126 @@
+package llm
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Usage 大模型请求的 token 用量
+type Usage struct {
+	Requests         int `json:"requests"`
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+}
+
+// TotalTokens 返回输入与输出 token 的总数
+func (u Usage) TotalTokens() int {
+	return u.PromptTokens + u.CompletionTokens
+}
+
+// Add 累加另一份用量
+func (u *Usage) Add(other Usage) {
+	u.Requests += other.Requests
+	u.PromptTokens += other.PromptTokens
+	u.CompletionTokens += other.CompletionTokens
+}
+
+// UsageEvent 一次请求的用量，交给 SetUsageRecorder 设置的记录函数
+type UsageEvent struct {
+	Time     time.Time
+	Job      string // 请求所属的任务（处理的文件），不属于任何任务时为空
+	Provider string
+	Model    string
+	Usage    Usage
+}
+
+// ModelUsage 一个服务商与模型的累计用量
+type ModelUsage struct {
+	Provider string
+	Model    string
+	Usage
+}
+
+// Meter 累计一个客户端的用量。处理文件时由处理器设置所属任务，按任务汇总用量
+type Meter struct {
+	mu    sync.Mutex
+	job   string
+	usage Usage
+}
+
+// SetJob 设置之后的请求所属的任务，nil 时不做任何事
+func (m *Meter) SetJob(job string) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.job = job
+}
+
+// Usage 返回累计用量
+func (m *Meter) Usage() Usage {
+	if m == nil {
+		return Usage{}
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.usage
+}
+
+// record 记录一次请求的用量：累加到客户端与进程的统计，并交给全局记录函数
+func (m *Meter) record(provider, model string, usage Usage) {
+	usage.Requests = 1
+	job := ""
+	if m != nil {
+		m.mu.Lock()
+		m.usage.Add(usage)
+		job = m.job
+		m.mu.Unlock()
+	}
+
+	usageMu.Lock()
+	key := provider + "/" + model
+	total, ok := usageTotals[key]
+	if !ok {
+		total = &ModelUsage{Provider: provider, Model: model}
+		usageTotals[key] = total
+	}
+	total.Add(usage)
+	recorder := usageRecorder
+	usageMu.Unlock()
+
+	if recorder != nil {
+		recorder(UsageEvent{Time: time.Now(), Job: job, Provider: provider, Model: model, Usage: usage})
+	}
+}
+
+var (
+	usageMu       sync.Mutex
+	usageTotals   = make(map[string]*ModelUsage)
+	usageRecorder func(UsageEvent)
+)
+
+// SetUsageRecorder 设置保存每次请求用量的函数，nil 表示不保存
+func SetUsageRecorder(recorder func(UsageEvent)) {
+	usageMu.Lock()
+	defer usageMu.Unlock()
+	usageRecorder = recorder
+}
+
+// UsageTotals 返回进程启动以来各服务商与模型的累计用量，按名称排序
+func UsageTotals() []ModelUsage {
+	usageMu.Lock()
+	defer usageMu.Unlock()
+	totals := make([]ModelUsage, 0, len(usageTotals))
+	for _, total := range usageTotals {
+		totals = append(totals, *total)
+	}
+	sort.Slice(totals, func(i, j int) bool {
+		if totals[i].Provider != totals[j].Provider {
+			return totals[i].Provider < totals[j].Provider
+		}
+		return totals[i].Model < totals[j].Model
+	})
+	return totals
+}
@@ -2,15 +2,22 @@ package models
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
 
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/schedule"
 	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/utils"
 )
 
 // Config 表示应用程序的配置
 type Config struct {
+    SchemaVersion     int     `json:"schema_version"`      // 配置文件格式版本，用于检测并迁移旧配置
     MediaFolder       string  `json:"media_folder"`        // 媒体文件所在文件夹
     OutputFolder      string  `json:"output_folder"`       // 输出结果文件夹
     MaxRetries        int     `json:"max_retries"`         // 最大重试次数
@@ -27,16 +34,356 @@ type Config struct {
     SegmentLength     int     `json:"segment_length"`      // 音频片段长度（秒）
     MaxSegmentLength  int     `json:"max_segment_length"`  // 最大段落长度
     MinSegmentLength  int     `json:"min_segment_length"`  // 最小段落长度
+    SplitOnSilence    bool    `json:"split_on_silence"`    // 分割长音频时是否在片段长度附近的静音处切分，避免切断词语
+    SplitSilenceDB    float64 `json:"split_silence_db"`    // 判定为静音的音量阈值（dB）
     RetryDelay        float64 `json:"retry_delay"`         // 重试延迟（秒）
-    TempDir           string  `json:"temp_dir"`            // 临时目录
+    TempDir           string  `json:"temp_dir"`            // 临时目录，为空时使用系统临时目录下的 audio-processor，每个任务使用独立子目录
+    MinFreeSpaceMB    int     `json:"min_free_space_mb"`   // 临时目录与输出目录所在磁盘至少保留的可用空间（MB），不足时暂停开始新的提取与切分，0 表示不检查
     LogLevel          string  `json:"log_level"`           // 日志级别
     LogFile           string  `json:"log_file"`            // 日志文件
-    MaxPartTime       int     `json:"max_part_time"`       // 最大部分时间（分钟）
+    MaxPartTime       int     `json:"max_part_time"`       // 最大部分时间（分钟），超过该时长的音频将分段识别
+    ChunkConcurrency  int     `json:"chunk_concurrency"`   // 分段识别时的并发数
+    SkipNoAudio       bool    `json:"skip_no_audio"`       // 是否跳过不包含音频流或近乎静音的文件，直接标记为"无音频"
+    SilenceThresholdDB float64 `json:"silence_threshold_db"` // 最大音量不高于该值（dB）时视为静音
+    MusicGate         string  `json:"music_gate"`          // 音频以音乐为主时的处理方式 (off: 不检测, warn: 警告后仍识别, skip: 跳过识别)
+    Dedup             string  `json:"dedup"`               // 内容与已识别文件相同（大小与部分内容的哈希一致）时的处理方式 (off: 照常识别, skip: 只记录不输出, symlink: 链接已有输出, copy: 复制已有输出)
     ExportSRT         bool    `json:"export_srt"`          // 是否导出SRT字幕文件
+    SRTMaxLineChars   int     `json:"srt_max_line_chars"`  // SRT字幕每行最大字符数，0 表示不限制
+    SRTMaxLines       int     `json:"srt_max_lines"`       // SRT每条字幕最大行数，超出时拆分为多条并按时间插值，0 表示不限制
+    SRTMaxCPS         float64 `json:"srt_max_cps"`         // SRT最大阅读速度（每秒字符数），超出时延长显示时间，0 表示不限制
     ExportJSON       bool    `json:"export_json"`         // 是否导出JSON格式的文本
     ExportMD       bool    `json:"export_md"`         // 是否导出JSON格式的文本
+    MDStructure    bool    `json:"md_structure"`      // 配置了API密钥时，由大模型为Markdown生成标题、摘要、带时间戳的章节与要点
+    ExportChapters bool    `json:"export_chapters"`   // 是否划分章节，导出YouTube格式的 *_chapters.txt 并写入JSON导出
+    ChapterMinLength float64 `json:"chapter_min_length"` // 章节最短时长（秒）
+    ExportAnalysis   bool    `json:"export_analysis"`    // 是否提取关键词、命名实体与主题标签，导出 *_analysis.json、写入JSON导出并汇总到 batches/tags.json
+    AnalysisKeywords int     `json:"analysis_keywords"`  // 每个文件提取的关键词数量上限
+    ExportSummary    bool    `json:"export_summary"`     // 配置了大模型时，识别完成后生成摘要并导出 *_summary.md
+    SummaryMinutes   bool    `json:"summary_minutes"`    // 生成摘要时同时整理待办事项与问答，导出为会议纪要
+    SummaryChunkSize int     `json:"summary_chunk_size"` // 摘要时每段文稿的最大字符数，超出时分段摘要后再汇总，应小于模型的上下文长度
+    SummaryChunkOverlap int  `json:"summary_chunk_overlap"` // 相邻两段重叠的字符数，避免在分段处丢失上下文
+    EmbeddingModel   string  `json:"embedding_model"`    // 文稿问答（/api/ask）检索使用的向量模型，由 llm 的服务商提供（如 text-embedding-3-small、nomic-embed-text），为空时按关键词检索
+    AskTopK          int     `json:"ask_top_k"`          // 文稿问答时交给大模型的相关片段数
+    TranslateTo       string `json:"translate_to"`        // 识别完成后翻译为该语言（如 en、ja），导出 <文件名>.<语言>.srt，为空时不翻译
+    TranslateProvider string `json:"translate_provider"`  // 翻译服务 (llm: 配置的大模型, deepl, google)
+    TranslateAPIKey   string `json:"translate_api_key"`   // DeepL 或 Google 翻译的API密钥，为空时读取 DEEPL_API_KEY 或 GOOGLE_TRANSLATE_API_KEY
+    TranslateBatchChars int  `json:"translate_batch_chars"` // 每次请求翻译的最大字符数，避免超出模型的上下文长度与接口限制
+    TranslateBilingual bool  `json:"translate_bilingual"` // 导出双语字幕（原文在上、译文在下），否则只包含译文
+    ExportLRC      bool    `json:"export_lrc"`        // 是否导出LRC歌词文件（有逐词时间戳时为卡拉OK逐字格式）
+    ExportASS      bool    `json:"export_ass"`        // 是否导出ASS字幕文件
+    ASSPreset      string  `json:"ass_preset"`        // ASS样式预设 (default, large, top, boxed)
+    ASSFontName    string  `json:"ass_font_name"`     // ASS字体，为空时使用预设
+    ASSFontSize    int     `json:"ass_font_size"`     // ASS字号（按1080p画面），0 表示使用预设
+    ASSPrimaryColor   string `json:"ass_primary_color"`   // ASS文字颜色 (#RRGGBB)，为空时使用预设
+    ASSHighlightColor string `json:"ass_highlight_color"` // ASS卡拉OK已读文字颜色 (#RRGGBB)，为空时使用预设
+    ASSAlignment   int     `json:"ass_alignment"`     // ASS字幕位置，按小键盘方位 1-9，0 表示使用预设
+    ASSKaraoke     bool    `json:"ass_karaoke"`       // 有逐词时间戳时是否逐词高亮
+    ExportDOCX     bool    `json:"export_docx"`       // 是否导出Word文档（时间戳随 include_timestamps）
+    ExportPDF      bool    `json:"export_pdf"`        // 是否导出PDF文档（时间戳随 include_timestamps）
+    DocumentSpeakers bool  `json:"document_speakers"` // Word/PDF文档中是否标注说话人
+    ExportVTT      bool    `json:"export_vtt"`        // 是否导出WebVTT字幕文件（浏览器 <track> 使用）
+    ExportPlayer   bool    `json:"export_player"`     // 是否在输出目录生成可离线打开的 player.html（自动导出VTT）
+    OutputTemplate string  `json:"output_template"`   // 输出目录中文稿文件的命名模板，如 {{.Date}}/{{.Basename}}{{.Suffix}}.{{.Ext}}，为空时为 <文件名>.txt
+    SubtitlePreset string  `json:"subtitle_preset"`   // 字幕导入剪辑软件的预设 (空: 通用, jianying: 额外生成剪映草稿, premiere: SRT使用UTF-8 BOM与CRLF换行)
+    MuxSubtitles   bool    `json:"mux_subtitles"`     // 是否将SRT/ASS字幕作为软字幕封装进源视频，生成 *_subbed.mp4（不重新编码）
+    GenerateThumbnail bool `json:"generate_thumbnail"` // 是否为视频生成封面缩略图 *_thumb.jpg，与其他输出放在同一子目录
+    GenerateWaveform  bool `json:"generate_waveform"`  // 是否生成波形图 *_waveform.png 与峰值数据 *_peaks.json，供网页播放器绘制带波形的进度条
+    WaveformPeaks     int  `json:"waveform_peaks"`     // 峰值数据的点数
+    BatchManifest  bool    `json:"batch_manifest"`    // 批处理完成后是否在输出目录的 batches 子目录生成批次清单（JSON与CSV）
+    RecordsStore   string  `json:"records_store"`     // 处理记录的存储方式 (sqlite: 输出目录的 processed_records.db，首次使用时导入 processed_records.json; json: processed_records.json)
+    StoreSegments  bool    `json:"store_segments"`    // 使用 sqlite 存储时是否同时保存完整的识别段落
+    QueueOrder     string  `json:"queue_order"`       // 批处理的文件顺序 (scan: 扫描顺序, smallest: 小文件优先, newest: 最近修改的优先)
+    QueuePinned    []string `json:"queue_pinned"`    // 优先处理的文件，按文件名或完整路径的通配符匹配（如 *urgent*），排在其他文件之前
+    VolcesAPIKey   string  `json:"volces_api_key"`    // 火山方舟API密钥，用于生成摘要与汇总（也可通过环境变量 VOLCES_API_KEY 设置）
+    LLM            LLMConfig `json:"llm"`             // 生成摘要、章节、关键词与汇总使用的大模型
+    LLMFallbacks   []LLMConfig `json:"llm_fallbacks"`  // 备用大模型，llm 失败、熔断或限流时依次改用，也可按 weight 分担请求
+    LLMDailyTokenBudget int  `json:"llm_daily_token_budget"` // 每天的大模型 token 预算，当天用量超出时警告，0 表示不限制
+    // 归档：识别完成后转码原视频以节省空间
+    ArchiveMode         string  `json:"archive_mode"`          // 归档方式 (off: 不归档, hevc: 重新编码为H.265, audio: 只保留音频)
+    ArchiveDelay        float64 `json:"archive_delay"`         // 识别完成后等待多久再归档（小时），留出检查识别结果的时间
+    ArchiveCRF          int     `json:"archive_crf"`           // H.265 编码质量 (0-51)，越大文件越小
+    ArchiveAudioBitrate string  `json:"archive_audio_bitrate"` // 归档文件的音频码率，如 96k
     // asr-service
-    ASRService string `json:"asr_service"` // ASR服务名称 ASR服务选择 (kuaishou, bcut, auto)
+    ASRService string `json:"asr_service"` // ASR服务名称 ASR服务选择 (kuaishou, bcut, auto, consensus)
+    ASRStrategy string                      `json:"asr_strategy"` // 自动选择服务的策略 (weighted_random, round_robin)
+    ASRServices map[string]ASRServiceConfig `json:"asr_services"` // 各ASR服务的启用状态、权重、超时、重试次数与请求配额
+    ASRHealthHalfLife float64 `json:"asr_health_half_life"` // ASR服务成功率衰减半衰期（小时）
+    ASRProbeInterval  float64 `json:"asr_probe_interval"`   // ASR服务熔断后再次试探的间隔（秒）
+    PreferredASRService string  `json:"preferred_asr_service"` // 首选ASR服务，由其他服务识别的文件可在其恢复后重新识别
+    AutoReprocess       bool    `json:"auto_reprocess"`        // 监听模式下首选服务可用时是否自动重新识别备用服务的结果
+    ReprocessInterval   float64 `json:"reprocess_interval"`    // 检查是否需要重新识别的间隔（分钟）
+    // ASR与大模型的HTTP请求
+    HTTPTimeout    float64 `json:"http_timeout"`     // 单次请求超时（秒），0 表示不限制
+    HTTPMaxRetries int     `json:"http_max_retries"` // 网络错误、429 与 5xx 响应的重试次数
+    HTTPRetryDelay float64 `json:"http_retry_delay"` // 首次重试前的等待时间（秒），之后按指数退避并加入随机抖动
+    HTTPProxy      string  `json:"http_proxy"`       // HTTP代理，如 http://127.0.0.1:7890，为空时使用环境变量 HTTP_PROXY/HTTPS_PROXY
+    SOCKS5Proxy    string  `json:"socks5_proxy"`     // SOCKS5代理，如 127.0.0.1:1080 或 socks5://用户名:密码@host:port，不能与 http_proxy 同时设置
+    // ASR识别结果缓存
+    ASRCache          bool    `json:"asr_cache"`             // 是否缓存识别结果，同一音频再次识别时直接使用
+    ASRCacheDir       string  `json:"asr_cache_dir"`         // 缓存目录，为空时使用 ./cache
+    ASRCacheMaxSizeMB int     `json:"asr_cache_max_size_mb"` // 缓存总大小上限（MB），超出时删除最久未使用的条目，0 表示不限制
+    ASRCacheTTLDays   float64 `json:"asr_cache_ttl_days"`    // 缓存条目最近一次使用后的保留天数，0 表示永不过期
+    // 监听模式重试
+    WatchMaxAttempts int     `json:"watch_max_attempts"` // 监听模式下单个文件的最大尝试次数
+    WatchRetryDelay  float64 `json:"watch_retry_delay"`  // 监听模式重试的基础延迟（秒），按指数退避
+    QuarantineFolder string  `json:"quarantine_folder"`  // 多次失败后隔离文件的目录，为空时使用媒体目录下的 quarantine
+    // 监听范围
+    WatchRecursive bool     `json:"watch_recursive"` // 监听模式是否同时监控媒体目录的子目录，之后新建的子目录自动加入
+    WatchInclude   []string `json:"watch_include"`   // 只处理匹配的文件，为空时处理全部媒体文件。不含 / 的通配符匹配文件或目录名，含 / 的匹配相对媒体目录的路径，** 匹配任意层目录
+    WatchExclude   []string `json:"watch_exclude"`   // 跳过匹配的文件与目录，规则同 watch_include；隐藏目录与隔离目录总是跳过
+    // 监听模式轮询
+    WatchPoll         bool    `json:"watch_poll"`          // 定期扫描媒体目录发现新文件，不依赖系统文件事件（SMB/NFS 等网络驱动器上常收不到事件）；注册系统文件监控失败时自动改为扫描
+    WatchPollInterval float64 `json:"watch_poll_interval"` // 扫描媒体目录的间隔（秒），比较文件大小与修改时间发现新增或变化的文件
+    // 监听模式处理时间
+    WatchSchedule []string `json:"watch_schedule"` // 只在这些时间窗口内开始处理，窗口外检测到的文件排队等待（可用 --process-now 立即处理）。每项为 "[星期] [HH:MM-HH:MM]"，如 "22:00-07:00"、"Mon-Fri 19:00-23:30"、"Sat,Sun"；为空时不限制
+    // 监听模式判断文件写入完成
+    WatchStableChecks    int     `json:"watch_stable_checks"`    // 文件大小与修改时间连续多少次检查不变后才处理，避免处理仍在下载的文件，0 表示不等待
+    WatchStableInterval  float64 `json:"watch_stable_interval"`  // 检查文件是否写入完成的间隔（秒）
+    WatchStableTimeout   float64 `json:"watch_stable_timeout"`   // 等待写入完成的最长时间（秒），超时后按处理失败重试，0 表示不限制
+    WatchExclusiveCheck  bool    `json:"watch_exclusive_check"`  // 处理前尝试独占打开文件，其他程序仍在写入时继续等待（Windows 上可靠，其他系统只能检测到加锁写入的程序）
+    // 监听模式处理成功后的原文件
+    WatchAfterSuccess string `json:"watch_after_success"` // 处理成功后如何处理原媒体文件 (keep: 保留, move: 移入 watch_done_folder, rename: 文件名添加 watch_done_prefix 前缀, delete: 按 trash_mode 删除)
+    WatchDoneFolder   string `json:"watch_done_folder"`   // watch_after_success 为 move 时的目标目录，为空时使用媒体目录下的 archived
+    WatchDonePrefix   string `json:"watch_done_prefix"`   // watch_after_success 为 rename 时添加的文件名前缀
+    // 监听模式处理结束的通知
+    NotifyOn            string `json:"notify_on"`             // 何时通知 (all: 处理成功与最终失败, failure: 仅最终失败, off: 不通知)
+    NotifyDesktop       bool   `json:"notify_desktop"`        // 发送系统桌面通知（Windows 通知中心、macOS 通知中心、Linux notify-send）
+    NotifySound         bool   `json:"notify_sound"`          // 通知时播放提示音
+    NotifyWebhookURL    string `json:"notify_webhook_url"`    // 处理结束时 POST JSON 的地址，设置了 webhook_secret 时同样签名
+    NotifyTelegramToken string `json:"notify_telegram_token"` // Telegram 机器人令牌（也可通过环境变量 TELEGRAM_BOT_TOKEN 设置）
+    NotifyTelegramChat  string `json:"notify_telegram_chat"`  // 接收通知的 Telegram chat_id
+    NotifyBarkURL       string `json:"notify_bark_url"`       // Bark 推送地址，如 "https://api.day.app/<设备密钥>"
+    // 监听多个目录
+    WatchProfiles []WatchProfile `json:"watch_profiles"` // 监听模式同时监控的多个目录及各自的配置，为空时只监控 media_folder
+    // 安全删除
+    TrashMode          string  `json:"trash_mode"`           // 删除方式 (delete: 直接删除, folder: 移入回收目录, system: 移入系统回收站)
+    TrashFolder        string  `json:"trash_folder"`         // 回收目录，为空时使用输出目录下的 .trash
+    TrashRetentionDays float64 `json:"trash_retention_days"` // 回收目录中文件的保留天数，0 表示永久保留
+    AuditLog           string  `json:"audit_log"`            // 删除/移动/覆盖等操作的审计日志路径，为空时使用输出目录下的 audit.jsonl
+    // 保留策略
+    Retention         map[string]RetentionPolicy `json:"retention"`          // 各类目录的保留策略，键为 uploads（Web上传）、temp（临时文件）、outputs（输出目录中的识别结果）、cache（识别结果缓存），未列出的目录不清理
+    RetentionInterval float64                    `json:"retention_interval"` // Web服务定期按保留策略清理的间隔（小时），0 表示只通过 asr cleanup 手动清理
+    // 监听队列视图
+    WatchQueueInterval float64 `json:"watch_queue_interval"` // 定期打印监听队列的间隔（秒），0 表示不打印
+    WatchStatusAddr    string  `json:"watch_status_addr"`    // 监听模式HTTP接口地址（如 127.0.0.1:8090），提供队列查询与手动入队，为空时不启动；配置了 web_auth_mode 时需要携带凭据，未配置时只应监听本机地址
+    // 长时间运行时的自检
+    DebugAddr         string  `json:"debug_addr"`          // pprof 与自检数据的HTTP地址（如 127.0.0.1:6060），为空时不启动，不要暴露到公网
+    SelfCheckInterval float64 `json:"self_check_interval"` // 监听与Web服务模式下记录 goroutine 数与堆内存的间隔（分钟），0 表示不检查
+    SelfCheckWindow   int     `json:"self_check_window"`   // 连续多少次采样持续增长时报警
+    // 文本后处理
+    TextPipeline    []string `json:"text_pipeline"`     // 导出前依次执行的文本处理步骤 (punctuation, s2t, t2s, profanity, replace)
+    ProfanityFile   string   `json:"profanity_file"`    // 敏感词词表文件，每行一个词
+    ReplaceDictFile string   `json:"replace_dict_file"` // 自定义替换字典文件，每行 "查找 => 替换"
+    // 使用量统计（仅保存在本地）
+    UsageStats     bool   `json:"usage_stats"`      // 是否记录本地使用量统计（处理文件数、识别时长、各服务用量）
+    UsageStatsFile string `json:"usage_stats_file"` // 统计文件路径，为空时使用输出目录下的 usage_stats.json
+    // 语言检测
+    Language              string `json:"language"`                // 音频语言 (auto: 自动检测, 或 zh、en 等语言代码)
+    LanguageDetect        string `json:"language_detect"`         // 语言检测方式 (空: 不检测, command: 外部命令, http: HTTP服务)
+    LanguageDetectCommand string `json:"language_detect_command"` // 外部命令，如 "python detect_lang.py {audio}"，需输出语言代码或 JSON
+    LanguageDetectURL     string `json:"language_detect_url"`     // HTTP服务地址，音频样本以 multipart 字段 file 上传
+    LanguageSampleSeconds int    `json:"language_sample_seconds"` // 用于检测的音频样本时长（秒）
+    // 说话人分离
+    Diarization        string `json:"diarization"`         // 说话人分离方式 (空: 不启用, command: 外部命令, http: HTTP服务)
+    DiarizationCommand string `json:"diarization_command"` // 外部命令，如 "python diarize.py {audio}"，需输出 RTTM 或 JSON
+    DiarizationURL     string `json:"diarization_url"`     // HTTP服务地址，音频以 multipart 字段 file 上传
+    // 识别前的音频预处理：视频在提取音频时应用，音频文件在 normalize 阶段单独处理
+    AudioLoudnorm     bool   `json:"audio_loudnorm"`      // 是否按 EBU R128 归一化响度（loudnorm 滤镜）
+    AudioHighpass     int    `json:"audio_highpass"`      // 高通滤波截止频率（Hz），滤除低频嗡嗡声，0 表示不启用
+    AudioLowpass      int    `json:"audio_lowpass"`       // 低通滤波截止频率（Hz），滤除高频嘶声，0 表示不启用
+    AudioDenoise      string `json:"audio_denoise"`       // 降噪方式 (空: 不降噪, afftdn: ffmpeg 内置频域降噪, rnnoise: arnndn 神经网络降噪)
+    AudioDenoiseModel string `json:"audio_denoise_model"` // rnnoise 使用的模型文件（.rnnn）
+    DownsampleOversized bool   `json:"downsample_oversized"` // 待识别的音频超过ASR服务的文件大小上限时，是否先压缩为 16kHz 单声道低码率音频（原音频保留）
+    DownsampleFormat    string `json:"downsample_format"`    // 压缩后的格式 (mp3, opus)
+    // 音轨与时间范围
+    AudioTrack  int                `json:"audio_track"`  // 提取的音频流序号（从 0 开始，对应 -map 0:a:N），-1 表示全部音频流
+    ClipStart   string             `json:"clip_start"`   // 只识别该时间之后的部分，如 "90"、"01:30"，为空时从头开始
+    ClipEnd     string             `json:"clip_end"`     // 只识别到该时间为止，为空时到结尾
+    MediaRanges []MediaRangeConfig `json:"media_ranges"` // 按文件名为单个文件指定音轨与时间范围，覆盖全局设置，按顺序取第一个匹配项
+    // Web上传
+    WebMaxUploadMB   int     `json:"web_max_upload_mb"`   // Web上传文件的大小上限（MB）
+    WebUploadChunkMB int     `json:"web_upload_chunk_mb"` // 分块上传时建议客户端每块的大小（MB）
+    WebUploadExpiry  float64 `json:"web_upload_expiry"`   // 未完成的分块上传保留的时间（小时），超过后删除
+    WebShutdownTimeout float64 `json:"web_shutdown_timeout"` // 收到停止信号后等待请求与正在处理的任务完成的时间（秒），超时后中止任务，下次启动时重新处理
+
+    // Web认证，启用后每个用户的上传、输出与任务相互隔离
+    WebAuthMode     string            `json:"web_auth_mode"`      // 认证方式：空为不认证（只适合本机访问），apikey、jwt
+    WebAPIKeys      map[string]string `json:"web_api_keys"`       // apikey 模式下 API密钥 -> 用户名
+    WebJWTSecret    string            `json:"web_jwt_secret"`     // jwt 模式下 HS256 签名密钥（也可通过环境变量 WEB_JWT_SECRET 设置）
+    WebJWTUserClaim string            `json:"web_jwt_user_claim"` // jwt 中表示用户名的字段
+
+    // 任务结束回调（webhook），提交任务时指定 callback_url
+    WebPublicURL        string   `json:"web_public_url"`        // 服务的外部访问地址，如 "https://asr.example.com"，回调中的文件地址以此为前缀，为空时为相对路径
+    WebhookSecret       string   `json:"webhook_secret"`        // 回调签名（HMAC-SHA256）密钥（也可通过环境变量 WEBHOOK_SECRET 设置），为空时不签名
+    WebhookMaxRetries   int      `json:"webhook_max_retries"`   // 回调遇到网络错误、429 与 5xx 时的最大重试次数
+    WebhookTimeout      float64  `json:"webhook_timeout"`       // 单次回调请求的超时（秒）
+    WebhookAllowedHosts []string `json:"webhook_allowed_hosts"` // 允许回调的本机或内网主机名、IP，如本机运行的 n8n "localhost"；其他解析到本机、内网或链路本地地址的回调地址被拒绝
+
+    // 输出的远程存储，本地输出目录仍保留完整的输出，远程按相对路径保存一份
+    OutputStorage  string `json:"output_storage"`  // 输出存储 (local: 只写本地输出目录, s3: S3 兼容的对象存储如 MinIO, webdav: WebDAV 如 Nextcloud)
+    StoragePrefix  string `json:"storage_prefix"`  // 远程路径前缀，多个部署共用同一存储桶或目录时区分，如 "team-a"；启用Web认证时各用户的输出位于 <前缀>/users/<用户名> 下
+    S3Endpoint     string `json:"s3_endpoint"`     // S3 服务地址，如 "https://s3.us-east-1.amazonaws.com"、"http://127.0.0.1:9000"
+    S3Region       string `json:"s3_region"`       // 签名使用的区域，MinIO 通常为 us-east-1
+    S3Bucket       string `json:"s3_bucket"`       // 存储桶
+    S3AccessKey    string `json:"s3_access_key"`   // 访问密钥ID（也可通过环境变量 S3_ACCESS_KEY 设置）
+    S3SecretKey    string `json:"s3_secret_key"`   // 访问密钥（也可通过环境变量 S3_SECRET_KEY 设置）
+    S3PathStyle    bool   `json:"s3_path_style"`   // 使用路径风格的地址（<地址>/<存储桶>/<键>），MinIO 等自建服务需要
+    WebDAVURL      string `json:"webdav_url"`      // WebDAV 目录地址，如 "https://cloud.example.com/remote.php/dav/files/alice/asr"
+    WebDAVUser     string `json:"webdav_user"`     // WebDAV 用户名
+    WebDAVPassword string `json:"webdav_password"` // WebDAV 密码或应用密码（也可通过环境变量 WEBDAV_PASSWORD 设置）
+
+    // 链接下载（yt-dlp）
+    YtDlpPath   string   `json:"yt_dlp_path"`   // yt-dlp 可执行文件路径
+    YtDlpFormat string   `json:"yt_dlp_format"` // yt-dlp 格式选择（-f），默认优先只下载音频
+    YtDlpArgs   []string `json:"yt_dlp_args"`   // yt-dlp 额外参数，如 ["--cookies", "cookies.txt"]
+    // 处理流水线
+    PipelineStages []PipelineStageConfig `json:"pipeline_stages"` // 插入处理流水线的自定义阶段（如降噪），按顺序插入
+}
+
+// PipelineStageConfig 插入处理流水线的自定义阶段，对待识别的音频执行外部命令
+type PipelineStageConfig struct {
+    Name    string `json:"name"`    // 阶段名称，不能与内置阶段重名
+    After   string `json:"after"`   // 插入在哪个阶段之后 (extract、normalize、split 或前面的自定义阶段)
+    Command string `json:"command"` // 外部命令，如 "ffmpeg -y -i {input} -af afftdn {output}"，{input} 为当前音频，{output} 为命令需写入的音频，{source} 为源文件
+}
+
+// MediaRangeConfig 文件名匹配 Pattern 时使用的音轨与时间范围
+type MediaRangeConfig struct {
+    Pattern    string `json:"pattern"`               // 文件名通配符（filepath.Match 语法），如 "lecture-*.mkv"
+    AudioTrack *int   `json:"audio_track,omitempty"` // 音频流序号，未设置时使用全局的 audio_track
+    Start      string `json:"start"`                 // 起始时间，为空时从头开始
+    End        string `json:"end"`                   // 结束时间，为空时到结尾
+}
+
+// MediaSelection 一个文件实际使用的音轨与时间范围
+type MediaSelection struct {
+    AudioTrack int     // 音频流序号，-1 表示全部
+    Start      float64 // 起始时间（秒），0 表示从头开始
+    End        float64 // 结束时间（秒），0 表示到结尾
+}
+
+// Clipped 判断是否只识别部分时间范围
+func (s MediaSelection) Clipped() bool {
+    return s.Start > 0 || s.End > 0
+}
+
+// RetentionPolicy 一类目录的保留策略，启用认证时各用户的目录分别计算
+type RetentionPolicy struct {
+    MaxAgeHours float64 `json:"max_age_hours"` // 修改时间早于该时长的文件被清理，0 表示不按时间清理
+    MaxSizeMB   int     `json:"max_size_mb"`   // 目录总大小上限（MB），超出时从最旧的文件开始清理，0 表示不限制
+}
+
+// 大模型服务商
+const (
+    LLMProviderVolces   = "volces"   // 火山方舟（豆包）
+    LLMProviderOpenAI   = "openai"   // OpenAI 及兼容 /v1/chat/completions 的服务
+    LLMProviderOllama   = "ollama"   // 本地 Ollama
+    LLMProviderDeepSeek = "deepseek" // DeepSeek
+    LLMProviderGemini   = "gemini"   // Google Gemini
+)
+
+// 翻译服务
+const (
+    TranslateProviderLLM    = "llm"    // 使用配置 llm 的大模型
+    TranslateProviderDeepL  = "deepl"  // DeepL API
+    TranslateProviderGoogle = "google" // Google Cloud Translation (v2)
+)
+
+// LLMConfig 大模型的服务商、地址与模型
+type LLMConfig struct {
+    Provider string `json:"provider"` // 服务商 (volces, openai, ollama, deepseek, gemini)
+    BaseURL  string `json:"base_url"` // 接口地址，为空时使用服务商的默认地址（ollama 为 http://localhost:11434）
+    Model    string `json:"model"`    // 模型名称，为空时使用服务商的默认模型
+    APIKey   string `json:"api_key"`  // API密钥，为空时读取服务商的环境变量（如 OPENAI_API_KEY），ollama 不需要
+
+    Weight            int     `json:"weight,omitempty"`              // 配置了 llm_fallbacks 时按权重随机选择，全部为 0 时按顺序使用
+    Timeout           float64 `json:"timeout,omitempty"`             // 单次请求超时（秒），0 表示使用 http_timeout
+    RequestsPerMinute int     `json:"requests_per_minute,omitempty"` // 每分钟最多请求数，超出时改用其他服务商或等待，0 表示不限制
+}
+
+// ProviderName 返回服务商，未配置时为火山方舟
+func (s LLMConfig) ProviderName() string {
+    if s.Provider == "" {
+        return LLMProviderVolces
+    }
+    return s.Provider
+}
+
+// Key 返回API密钥：优先使用 api_key，其次为服务商的环境变量，ollama 不需要
+func (s LLMConfig) Key() string {
+    if s.APIKey != "" {
+        return s.APIKey
+    }
+    switch s.ProviderName() {
+    case LLMProviderOpenAI:
+        return os.Getenv("OPENAI_API_KEY")
+    case LLMProviderDeepSeek:
+        return os.Getenv("DEEPSEEK_API_KEY")
+    case LLMProviderGemini:
+        return os.Getenv("GEMINI_API_KEY")
+    case LLMProviderOllama:
+        return ""
+    }
+    return os.Getenv("VOLCES_API_KEY")
+}
+
+// validate 检查服务商与调用参数，field 为错误信息中的字段名
+func (s LLMConfig) validate(field string) error {
+    switch s.Provider {
+    case "", LLMProviderVolces, LLMProviderOpenAI, LLMProviderOllama, LLMProviderDeepSeek, LLMProviderGemini:
+    default:
+        return &ConfigValidationError{field + ".Provider", "必须为 volces、openai、ollama、deepseek 或 gemini"}
+    }
+    if s.Provider == LLMProviderOpenAI && s.Model == "" && s.BaseURL != "" {
+        return &ConfigValidationError{field + ".Model", "使用 OpenAI 兼容服务时必须指定模型"}
+    }
+    if s.Weight < 0 {
+        return &ConfigValidationError{field + ".Weight", "不能为负数"}
+    }
+    if s.Timeout < 0 {
+        return &ConfigValidationError{field + ".Timeout", "不能为负数"}
+    }
+    if s.RequestsPerMinute < 0 {
+        return &ConfigValidationError{field + ".RequestsPerMinute", "不能为负数"}
+    }
+    return nil
+}
+
+// ASRServiceConfig 单个ASR服务的配置
+type ASRServiceConfig struct {
+    Enabled    *bool   `json:"enabled,omitempty"` // 是否启用，未设置时视为启用
+    Weight     int     `json:"weight"`            // 自动选择时的权重，0 表示不参与加权随机选择
+    Timeout    float64 `json:"timeout"`           // 单次识别超时（秒），0 表示使用默认值 300
+    MaxRetries int     `json:"max_retries"`       // 失败后的重试次数
+
+    RequestsPerMinute int `json:"requests_per_minute"` // 每分钟最多请求数，0 表示不限制
+    DailyQuota        int `json:"daily_quota"`         // 每日最多请求数（按本地日期重置），0 表示不限制
+
+    Languages []string `json:"languages,omitempty"` // 支持的语言代码（如 zh、en），为空时使用内置默认值
+
+    MaxFileSizeMB float64 `json:"max_file_size_mb,omitempty"` // 可上传的最大文件（MB），超过时先降采样压缩，0 表示使用内置默认值
+}
+
+// IsEnabled 判断服务是否启用
+func (s ASRServiceConfig) IsEnabled() bool {
+    return s.Enabled == nil || *s.Enabled
+}
+
+// WatchProfile 监听模式下的一个监控目录，Settings 中的配置项覆盖全局配置，
+// 例如播客目录使用 bcut 识别英文并导出字幕，会议目录识别中文并生成摘要
+type WatchProfile struct {
+    Name         string                     `json:"name"`               // 名称，用于日志，不能重复
+    MediaFolder  string                     `json:"media_folder"`       // 监控的媒体目录
+    OutputFolder string                     `json:"output_folder"`      // 输出目录，为空时使用全局 output_folder 下以名称命名的子目录
+    Settings     map[string]json.RawMessage `json:"settings,omitempty"` // 覆盖的配置项，如 {"asr_service": "bcut", "language": "en", "export_srt": true}。影响整个进程的配置项（如 log_file、watch_status_addr、max_workers）在这里设置无效
+}
+
+// profileReservedKeys 不能在 WatchProfile.Settings 中设置的配置项
+var profileReservedKeys = map[string]bool{
+    "schema_version": true,
+    "media_folder":   true,
+    "output_folder":  true,
+    "watch_profiles": true,
 }
 
 // ConfigValidationError 表示配置验证错误
@@ -54,8 +401,9 @@ func (e ConfigValidationError) Error() string {
 // NewDefaultConfig 创建默认配置
 func NewDefaultConfig() *Config {
     return &Config{
-        MediaFolder:       "D:\\download",
-        OutputFolder:      "D:\\download\\dest",
+        SchemaVersion:     ConfigSchemaVersion,
+        MediaFolder:       "./media",
+        OutputFolder:      "./output",
         MaxRetries:        3,
         MaxWorkers:        8,
         UseJianyingFirst:  true,
@@ -70,15 +418,155 @@ func NewDefaultConfig() *Config {
         SegmentLength:     30,
         MaxSegmentLength:  2000,
         MinSegmentLength:  10,
+        SplitOnSilence:    true,
+        SplitSilenceDB:    -35,
         RetryDelay:        1.0,
         TempDir:           "",
+        MinFreeSpaceMB:    1024,
         LogLevel:          "INFO",
         LogFile:           "",
         MaxPartTime:       20,
+        ChunkConcurrency:  2,
+        SkipNoAudio:       true,
+        SilenceThresholdDB: -60,
+        MusicGate:         "warn",
+        Dedup:             "copy",
         ExportSRT:         true,
+        SRTMaxLineChars:   20,
+        SRTMaxLines:       2,
+        SRTMaxCPS:         0,
         ExportMD:         true,
         ASRService:       "auto",
+        ASRStrategy:      "weighted_random",
+        ASRServices: map[string]ASRServiceConfig{
+            "bcut":     {Weight: 30, Timeout: 300, MaxRetries: 2},
+            "kuaishou": {Enabled: boolPtr(false), Weight: 10, Timeout: 300, MaxRetries: 2},
+        },
+        ASRHealthHalfLife: 24,
+        ASRProbeInterval:  600,
+        ReprocessInterval: 60,
+        ASRCache:          true,
+        ASRCacheDir:       "",
+        ASRCacheMaxSizeMB: 500,
+        ASRCacheTTLDays:   30,
+        HTTPTimeout:       180,
+        HTTPMaxRetries:    3,
+        HTTPRetryDelay:    1,
         ExportJSON: false,
+        MDStructure: true,
+        ExportChapters: false,
+        ChapterMinLength: 60,
+        ExportAnalysis:   false,
+        AnalysisKeywords: 10,
+        ExportSummary:    false,
+        SummaryMinutes:   true,
+        SummaryChunkSize: 12000,
+        SummaryChunkOverlap: 300,
+        EmbeddingModel:   "",
+        AskTopK:          8,
+        TranslateTo:        "",
+        TranslateProvider:  TranslateProviderLLM,
+        TranslateBatchChars: 3000,
+        TranslateBilingual: true,
+        ExportLRC:  false,
+        ExportASS:  false,
+        ASSPreset:  "default",
+        ASSKaraoke: true,
+        ExportDOCX: false,
+        ExportPDF:  false,
+        DocumentSpeakers: true,
+        OutputTemplate: "",
+        ExportVTT:    false,
+        ExportPlayer: false,
+        SubtitlePreset: "",
+        MuxSubtitles: false,
+        GenerateThumbnail: false,
+        GenerateWaveform:  false,
+        WaveformPeaks:     1000,
+        BatchManifest: true,
+        RecordsStore:  "sqlite",
+        StoreSegments: false,
+        LLM: LLMConfig{
+            Provider: LLMProviderVolces,
+        },
+        LLMDailyTokenBudget: 0,
+        QueueOrder:    "scan",
+        ArchiveMode:         "off",
+        ArchiveDelay:        24,
+        ArchiveCRF:          28,
+        ArchiveAudioBitrate: "96k",
+        WatchMaxAttempts: 3,
+        WatchRetryDelay:  30,
+        QuarantineFolder: "",
+        WatchRecursive: false,
+        WatchInclude:   nil,
+        WatchExclude:   []string{"dest", "temp"},
+        WatchPoll:         false,
+        WatchPollInterval: 10,
+        WatchSchedule:     nil,
+        WatchStableChecks:   3,
+        WatchStableInterval: 2,
+        WatchStableTimeout:  0,
+        WatchExclusiveCheck: false,
+        WatchAfterSuccess: "keep",
+        WatchDoneFolder:   "",
+        WatchDonePrefix:   "✅",
+        NotifyOn:            "all",
+        NotifyDesktop:       false,
+        NotifySound:         false,
+        NotifyWebhookURL:    "",
+        NotifyTelegramToken: "",
+        NotifyTelegramChat:  "",
+        NotifyBarkURL:       "",
+        TrashMode:          "folder",
+        TrashFolder:        "",
+        TrashRetentionDays: 7,
+        AuditLog:           "",
+        Retention: map[string]RetentionPolicy{
+            "uploads": {MaxAgeHours: 24},
+            "temp":    {MaxAgeHours: 24},
+        },
+        RetentionInterval: 6,
+        WatchQueueInterval: 30,
+        WatchStatusAddr:    "",
+        DebugAddr:         "",
+        SelfCheckInterval: 10,
+        SelfCheckWindow:   6,
+        TextPipeline:          []string{},
+        ProfanityFile:         "",
+        ReplaceDictFile:       "",
+        UsageStats:            true,
+        UsageStatsFile:        "",
+        Language:              "auto",
+        LanguageDetect:        "",
+        LanguageDetectCommand: "",
+        LanguageDetectURL:     "",
+        LanguageSampleSeconds: 30,
+        Diarization:        "",
+        DiarizationCommand: "",
+        DiarizationURL:     "",
+        AudioLoudnorm:     false,
+        AudioHighpass:     0,
+        AudioLowpass:      0,
+        AudioDenoise:      "",
+        AudioDenoiseModel: "",
+        DownsampleOversized: true,
+        DownsampleFormat:    "mp3",
+        AudioTrack:  0,
+        ClipStart:   "",
+        ClipEnd:     "",
+        MediaRanges: nil,
+        WebMaxUploadMB:   2048,
+        WebUploadChunkMB: 8,
+        WebUploadExpiry:  24,
+        WebShutdownTimeout: 60,
+        WebJWTUserClaim:  "sub",
+        WebhookMaxRetries: 5,
+        WebhookTimeout:    30,
+        OutputStorage:    "local",
+        S3Region:         "us-east-1",
+        YtDlpPath:        "yt-dlp",
+        YtDlpFormat:      "bestaudio/best",
     }
 }
 
@@ -114,20 +602,576 @@ func (c *Config) Validate() error {
         return &ConfigValidationError{"MinSegmentLength", "必须在5-100之间"}
     }
 
+    if c.SplitSilenceDB >= 0 {
+        return &ConfigValidationError{"SplitSilenceDB", "必须小于0"}
+    }
+
     if c.RetryDelay < 0.1 || c.RetryDelay > 10.0 {
         return &ConfigValidationError{"RetryDelay", "必须在0.1-10.0秒之间"}
     }
 
+    if c.MinFreeSpaceMB < 0 {
+        return &ConfigValidationError{"MinFreeSpaceMB", "不能为负数"}
+    }
+
+    if c.ChunkConcurrency < 1 || c.ChunkConcurrency > 16 {
+        return &ConfigValidationError{"ChunkConcurrency", "必须在1-16之间"}
+    }
+
+    if c.SilenceThresholdDB > 0 {
+        return &ConfigValidationError{"SilenceThresholdDB", "不能大于0"}
+    }
+
+    if c.MusicGate != "off" && c.MusicGate != "warn" && c.MusicGate != "skip" {
+        return &ConfigValidationError{"MusicGate", "必须是 off、warn 或 skip"}
+    }
+    if c.Dedup != "" && c.Dedup != "off" && c.Dedup != "skip" && c.Dedup != "symlink" && c.Dedup != "copy" {
+        return &ConfigValidationError{"Dedup", "必须是 off、skip、symlink 或 copy"}
+    }
+
+    if c.ASSFontSize < 0 {
+        return &ConfigValidationError{"ASSFontSize", "不能为负数"}
+    }
+
+    if c.ASSAlignment < 0 || c.ASSAlignment > 9 {
+        return &ConfigValidationError{"ASSAlignment", "必须在0-9之间"}
+    }
+
+    if c.ASSPrimaryColor != "" && !isHexColor(c.ASSPrimaryColor) {
+        return &ConfigValidationError{"ASSPrimaryColor", "必须是 #RRGGBB 格式"}
+    }
+
+    if c.ASSHighlightColor != "" && !isHexColor(c.ASSHighlightColor) {
+        return &ConfigValidationError{"ASSHighlightColor", "必须是 #RRGGBB 格式"}
+    }
+
+    if c.SRTMaxLineChars < 0 {
+        return &ConfigValidationError{"SRTMaxLineChars", "不能为负数"}
+    }
+
+    if c.SRTMaxLines < 0 {
+        return &ConfigValidationError{"SRTMaxLines", "不能为负数"}
+    }
+
+    if c.SRTMaxCPS < 0 {
+        return &ConfigValidationError{"SRTMaxCPS", "不能为负数"}
+    }
+
+    if c.WatchMaxAttempts < 1 || c.WatchMaxAttempts > 20 {
+        return &ConfigValidationError{"WatchMaxAttempts", "必须在1-20之间"}
+    }
+
+    if c.WatchRetryDelay < 0 {
+        return &ConfigValidationError{"WatchRetryDelay", "不能为负数"}
+    }
+
+    if c.WatchPollInterval <= 0 {
+        return &ConfigValidationError{"WatchPollInterval", "必须大于0"}
+    }
+    if _, err := schedule.Parse(c.WatchSchedule); err != nil {
+        return &ConfigValidationError{"WatchSchedule", err.Error()}
+    }
+    if c.WatchStableChecks < 0 {
+        return &ConfigValidationError{"WatchStableChecks", "不能为负数"}
+    }
+    if c.WatchStableInterval <= 0 {
+        return &ConfigValidationError{"WatchStableInterval", "必须大于0"}
+    }
+    if c.WatchStableTimeout < 0 {
+        return &ConfigValidationError{"WatchStableTimeout", "不能为负数"}
+    }
+    switch c.WatchAfterSuccess {
+    case "", "keep", "move", "delete":
+    case "rename":
+        if c.WatchDonePrefix == "" || strings.ContainsAny(c.WatchDonePrefix, `/\`) {
+            return &ConfigValidationError{"WatchDonePrefix", "不能为空或包含路径分隔符"}
+        }
+    default:
+        return &ConfigValidationError{"WatchAfterSuccess", "必须是 keep、move、rename 或 delete"}
+    }
+    switch c.NotifyOn {
+    case "", "all", "failure", "off":
+    default:
+        return &ConfigValidationError{"NotifyOn", "必须是 all、failure 或 off"}
+    }
+    for field, value := range map[string]string{"NotifyWebhookURL": c.NotifyWebhookURL, "NotifyBarkURL": c.NotifyBarkURL} {
+        if value == "" {
+            continue
+        }
+        if u, err := url.Parse(value); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+            return &ConfigValidationError{field, "必须是 http 或 https 地址"}
+        }
+    }
+    if c.NotifyTelegramChat == "" && c.NotifyTelegramToken != "" {
+        return &ConfigValidationError{"NotifyTelegramChat", "设置了 notify_telegram_token 时不能为空"}
+    }
+    names := make(map[string]bool)
+    folders := make(map[string]bool)
+    for i, profile := range c.WatchProfiles {
+        field := fmt.Sprintf("WatchProfiles[%d]", i)
+        if profile.Name == "" || profile.Name == "." || profile.Name == ".." || strings.ContainsAny(profile.Name, `/\`) {
+            return &ConfigValidationError{field, "name 不能为空或包含路径分隔符"}
+        }
+        if names[profile.Name] {
+            return &ConfigValidationError{field, fmt.Sprintf("名称 %s 重复", profile.Name)}
+        }
+        names[profile.Name] = true
+        if profile.MediaFolder == "" {
+            return &ConfigValidationError{field, "media_folder 不能为空"}
+        }
+        folder := filepath.Clean(profile.MediaFolder)
+        if folders[folder] {
+            return &ConfigValidationError{field, fmt.Sprintf("媒体目录 %s 重复", profile.MediaFolder)}
+        }
+        folders[folder] = true
+        if _, err := c.ProfileConfig(profile); err != nil {
+            var invalid *ConfigValidationError
+            if errors.As(err, &invalid) {
+                return &ConfigValidationError{field + "." + invalid.Field, invalid.Message}
+            }
+            return &ConfigValidationError{field, err.Error()}
+        }
+    }
+    for _, pattern := range c.WatchInclude {
+        if _, err := filepath.Match(pattern, ""); err != nil {
+            return &ConfigValidationError{"WatchInclude", fmt.Sprintf("无效的通配符: %s", pattern)}
+        }
+    }
+    for _, pattern := range c.WatchExclude {
+        if _, err := filepath.Match(pattern, ""); err != nil {
+            return &ConfigValidationError{"WatchExclude", fmt.Sprintf("无效的通配符: %s", pattern)}
+        }
+    }
+
+    if c.ASRStrategy != "weighted_random" && c.ASRStrategy != "round_robin" {
+        return &ConfigValidationError{"ASRStrategy", "必须是 weighted_random 或 round_robin"}
+    }
+
+    for name, service := range c.ASRServices {
+        if service.Weight < 0 {
+            return &ConfigValidationError{"ASRServices." + name + ".Weight", "不能为负数"}
+        }
+        if service.Timeout < 0 {
+            return &ConfigValidationError{"ASRServices." + name + ".Timeout", "不能为负数"}
+        }
+        if service.MaxRetries < 0 || service.MaxRetries > 10 {
+            return &ConfigValidationError{"ASRServices." + name + ".MaxRetries", "必须在0-10之间"}
+        }
+        if service.RequestsPerMinute < 0 {
+            return &ConfigValidationError{"ASRServices." + name + ".RequestsPerMinute", "不能为负数"}
+        }
+        if service.DailyQuota < 0 {
+            return &ConfigValidationError{"ASRServices." + name + ".DailyQuota", "不能为负数"}
+        }
+        if service.MaxFileSizeMB < 0 {
+            return &ConfigValidationError{"ASRServices." + name + ".MaxFileSizeMB", "不能为负数"}
+        }
+    }
+
+    for class, policy := range c.Retention {
+        switch class {
+        case "uploads", "temp", "outputs", "cache":
+        default:
+            return &ConfigValidationError{"Retention." + class, "目录类别必须为 uploads、temp、outputs 或 cache"}
+        }
+        if policy.MaxAgeHours < 0 {
+            return &ConfigValidationError{"Retention." + class + ".MaxAgeHours", "不能为负数"}
+        }
+        if policy.MaxSizeMB < 0 {
+            return &ConfigValidationError{"Retention." + class + ".MaxSizeMB", "不能为负数"}
+        }
+    }
+    if c.RetentionInterval < 0 {
+        return &ConfigValidationError{"RetentionInterval", "不能为负数"}
+    }
+
+    if c.OutputTemplate != "" {
+        if _, err := template.New("output").Parse(c.OutputTemplate); err != nil {
+            return &ConfigValidationError{"OutputTemplate", fmt.Sprintf("模板无效: %v", err)}
+        }
+        // 不同格式、不同分段需要生成不同的文件名
+        if !strings.Contains(c.OutputTemplate, ".Ext") || !strings.Contains(c.OutputTemplate, ".Suffix") || !strings.Contains(c.OutputTemplate, ".Part") {
+            return &ConfigValidationError{"OutputTemplate", "必须包含 {{.Ext}}、{{.Suffix}} 与 {{.Part}}"}
+        }
+    }
+
+    if c.ChapterMinLength < 0 {
+        return &ConfigValidationError{"ChapterMinLength", "不能为负数"}
+    }
+
+    if c.AnalysisKeywords < 1 || c.AnalysisKeywords > 100 {
+        return &ConfigValidationError{"AnalysisKeywords", "必须在1-100之间"}
+    }
+
+    if c.SummaryChunkSize < 1000 {
+        return &ConfigValidationError{"SummaryChunkSize", "不能小于1000"}
+    }
+    if c.SummaryChunkOverlap < 0 || c.SummaryChunkOverlap >= c.SummaryChunkSize/2 {
+        return &ConfigValidationError{"SummaryChunkOverlap", "必须大于等于0且小于 summary_chunk_size 的一半"}
+    }
+    if c.AskTopK < 1 {
+        return &ConfigValidationError{"AskTopK", "必须大于0"}
+    }
+
+    switch c.TranslateProvider {
+    case TranslateProviderLLM, TranslateProviderDeepL, TranslateProviderGoogle:
+    default:
+        return &ConfigValidationError{"TranslateProvider", "必须为 llm、deepl 或 google"}
+    }
+    if c.LLMDailyTokenBudget < 0 {
+        return &ConfigValidationError{"LLMDailyTokenBudget", "不能为负数"}
+    }
+    if c.TranslateBatchChars < 200 {
+        return &ConfigValidationError{"TranslateBatchChars", "不能小于200"}
+    }
+
+    switch c.SubtitlePreset {
+    case "", "jianying":
+    case "premiere":
+        if !c.ExportSRT {
+            return &ConfigValidationError{"SubtitlePreset", "premiere 预设需要启用 export_srt"}
+        }
+    default:
+        return &ConfigValidationError{"SubtitlePreset", "必须为空、jianying 或 premiere"}
+    }
+
+    if c.MuxSubtitles && !c.ExportSRT && !c.ExportASS {
+        return &ConfigValidationError{"MuxSubtitles", "需要启用 export_srt 或 export_ass"}
+    }
+
+    if c.WaveformPeaks < 100 || c.WaveformPeaks > 20000 {
+        return &ConfigValidationError{"WaveformPeaks", "必须在100-20000之间"}
+    }
+
+    if c.RecordsStore != "" && c.RecordsStore != "sqlite" && c.RecordsStore != "json" {
+        return &ConfigValidationError{"RecordsStore", "必须是 sqlite 或 json"}
+    }
+    if c.QueueOrder != "" && c.QueueOrder != "scan" && c.QueueOrder != "smallest" && c.QueueOrder != "newest" {
+        return &ConfigValidationError{"QueueOrder", "必须是 scan、smallest 或 newest"}
+    }
+    if err := c.LLM.validate("LLM"); err != nil {
+        return err
+    }
+    for i, fallback := range c.LLMFallbacks {
+        if err := fallback.validate(fmt.Sprintf("LLMFallbacks[%d]", i)); err != nil {
+            return err
+        }
+    }
+
+    for _, pattern := range c.QueuePinned {
+        if _, err := filepath.Match(pattern, ""); err != nil {
+            return &ConfigValidationError{"QueuePinned", fmt.Sprintf("无效的通配符: %s", pattern)}
+        }
+    }
+    if c.ArchiveMode != "" && c.ArchiveMode != "off" && c.ArchiveMode != "hevc" && c.ArchiveMode != "audio" {
+        return &ConfigValidationError{"ArchiveMode", "必须是 off、hevc 或 audio"}
+    }
+    if c.ArchiveMode != "" && c.ArchiveMode != "off" {
+        if c.ArchiveDelay < 0 {
+            return &ConfigValidationError{"ArchiveDelay", "不能为负数"}
+        }
+        if c.ArchiveCRF < 0 || c.ArchiveCRF > 51 {
+            return &ConfigValidationError{"ArchiveCRF", "必须在0-51之间"}
+        }
+        if c.ArchiveAudioBitrate == "" {
+            return &ConfigValidationError{"ArchiveAudioBitrate", "不能为空"}
+        }
+    }
+
+    if c.PreferredASRService != "" {
+        if _, ok := c.ASRServices[c.PreferredASRService]; !ok {
+            return &ConfigValidationError{"PreferredASRService", "必须是 asr_services 中配置的服务"}
+        }
+    }
+    if c.AutoReprocess {
+        if c.PreferredASRService == "" {
+            return &ConfigValidationError{"AutoReprocess", "启用时必须设置 preferred_asr_service"}
+        }
+        if c.ReprocessInterval <= 0 {
+            return &ConfigValidationError{"ReprocessInterval", "必须大于0"}
+        }
+    }
+
+    if c.ASRHealthHalfLife <= 0 {
+        return &ConfigValidationError{"ASRHealthHalfLife", "必须大于0"}
+    }
+
+    if c.ASRProbeInterval < 0 {
+        return &ConfigValidationError{"ASRProbeInterval", "不能为负数"}
+    }
+
+    if c.ASRCacheMaxSizeMB < 0 {
+        return &ConfigValidationError{"ASRCacheMaxSizeMB", "不能为负数"}
+    }
+
+    if c.ASRCacheTTLDays < 0 {
+        return &ConfigValidationError{"ASRCacheTTLDays", "不能为负数"}
+    }
+
+    if c.HTTPTimeout < 0 {
+        return &ConfigValidationError{"HTTPTimeout", "不能为负数"}
+    }
+
+    if c.HTTPMaxRetries < 0 || c.HTTPMaxRetries > 10 {
+        return &ConfigValidationError{"HTTPMaxRetries", "必须在0-10之间"}
+    }
+
+    if c.HTTPRetryDelay < 0 {
+        return &ConfigValidationError{"HTTPRetryDelay", "不能为负数"}
+    }
+
+    if c.HTTPProxy != "" && c.SOCKS5Proxy != "" {
+        return &ConfigValidationError{"HTTPProxy", "不能与 socks5_proxy 同时设置"}
+    }
+
+    if _, err := c.ProxyURL(); err != nil {
+        field := "HTTPProxy"
+        if c.SOCKS5Proxy != "" {
+            field = "SOCKS5Proxy"
+        }
+        return &ConfigValidationError{field, err.Error()}
+    }
+
+    switch c.TrashMode {
+    case "delete", "folder", "system":
+    default:
+        return &ConfigValidationError{"TrashMode", "必须是 delete、folder 或 system"}
+    }
+
+    if c.TrashRetentionDays < 0 {
+        return &ConfigValidationError{"TrashRetentionDays", "不能为负数"}
+    }
+
+    if c.WatchQueueInterval < 0 {
+        return &ConfigValidationError{"WatchQueueInterval", "不能为负数"}
+    }
+
+    if c.SelfCheckInterval < 0 {
+        return &ConfigValidationError{"SelfCheckInterval", "不能为负数"}
+    }
+    if c.SelfCheckWindow < 2 {
+        return &ConfigValidationError{"SelfCheckWindow", "不能小于2"}
+    }
+
+    for _, step := range c.TextPipeline {
+        switch step {
+        case "punctuation", "s2t", "t2s":
+        case "profanity":
+            if c.ProfanityFile == "" {
+                return &ConfigValidationError{"ProfanityFile", "启用 profanity 步骤时不能为空"}
+            }
+        case "replace":
+            if c.ReplaceDictFile == "" {
+                return &ConfigValidationError{"ReplaceDictFile", "启用 replace 步骤时不能为空"}
+            }
+        default:
+            return &ConfigValidationError{"TextPipeline", "未知的处理步骤: " + step}
+        }
+    }
+
+    switch c.LanguageDetect {
+    case "":
+    case "command":
+        if c.LanguageDetectCommand == "" {
+            return &ConfigValidationError{"LanguageDetectCommand", "使用 command 方式时不能为空"}
+        }
+    case "http":
+        if c.LanguageDetectURL == "" {
+            return &ConfigValidationError{"LanguageDetectURL", "使用 http 方式时不能为空"}
+        }
+    default:
+        return &ConfigValidationError{"LanguageDetect", "必须为空、command 或 http"}
+    }
+
+    if c.LanguageDetect != "" && (c.LanguageSampleSeconds < 5 || c.LanguageSampleSeconds > 300) {
+        return &ConfigValidationError{"LanguageSampleSeconds", "必须在5-300秒之间"}
+    }
+
+    switch c.Diarization {
+    case "":
+    case "command":
+        if c.DiarizationCommand == "" {
+            return &ConfigValidationError{"DiarizationCommand", "使用 command 方式时不能为空"}
+        }
+    case "http":
+        if c.DiarizationURL == "" {
+            return &ConfigValidationError{"DiarizationURL", "使用 http 方式时不能为空"}
+        }
+    default:
+        return &ConfigValidationError{"Diarization", "必须为空、command 或 http"}
+    }
+
+    if c.AudioHighpass < 0 {
+        return &ConfigValidationError{"AudioHighpass", "不能为负数"}
+    }
+    if c.AudioLowpass < 0 {
+        return &ConfigValidationError{"AudioLowpass", "不能为负数"}
+    }
+    if c.AudioHighpass > 0 && c.AudioLowpass > 0 && c.AudioLowpass <= c.AudioHighpass {
+        return &ConfigValidationError{"AudioLowpass", "必须大于 audio_highpass"}
+    }
+    switch c.AudioDenoise {
+    case "", "afftdn":
+    case "rnnoise":
+        if c.AudioDenoiseModel == "" {
+            return &ConfigValidationError{"AudioDenoiseModel", "使用 rnnoise 降噪时不能为空"}
+        }
+    default:
+        return &ConfigValidationError{"AudioDenoise", "必须为空、afftdn 或 rnnoise"}
+    }
+
+    if c.DownsampleFormat != "mp3" && c.DownsampleFormat != "opus" {
+        return &ConfigValidationError{"DownsampleFormat", "必须是 mp3 或 opus"}
+    }
+
+    if c.AudioTrack < -1 {
+        return &ConfigValidationError{"AudioTrack", "必须大于等于 -1"}
+    }
+    if err := validateClipRange("ClipStart", c.ClipStart, "ClipEnd", c.ClipEnd); err != nil {
+        return err
+    }
+    for i, r := range c.MediaRanges {
+        field := fmt.Sprintf("MediaRanges[%d]", i)
+        if r.Pattern == "" {
+            return &ConfigValidationError{field + ".Pattern", "不能为空"}
+        }
+        if _, err := filepath.Match(r.Pattern, ""); err != nil {
+            return &ConfigValidationError{field + ".Pattern", "通配符格式无效"}
+        }
+        if r.AudioTrack != nil && *r.AudioTrack < -1 {
+            return &ConfigValidationError{field + ".AudioTrack", "必须大于等于 -1"}
+        }
+        if err := validateClipRange(field+".Start", r.Start, field+".End", r.End); err != nil {
+            return err
+        }
+    }
+
+    if c.WebMaxUploadMB <= 0 {
+        return &ConfigValidationError{"WebMaxUploadMB", "必须大于0"}
+    }
+    if c.WebUploadChunkMB <= 0 || c.WebUploadChunkMB > c.WebMaxUploadMB {
+        return &ConfigValidationError{"WebUploadChunkMB", "必须大于0且不超过上传大小上限"}
+    }
+    if c.WebUploadExpiry <= 0 {
+        return &ConfigValidationError{"WebUploadExpiry", "必须大于0"}
+    }
+    if c.WebShutdownTimeout < 0 {
+        return &ConfigValidationError{"WebShutdownTimeout", "不能为负数"}
+    }
+    switch c.WebAuthMode {
+    case "":
+    case "apikey":
+        if len(c.WebAPIKeys) == 0 {
+            return &ConfigValidationError{"WebAPIKeys", "apikey 认证需要至少一个API密钥"}
+        }
+        for key, user := range c.WebAPIKeys {
+            if key == "" || user == "" {
+                return &ConfigValidationError{"WebAPIKeys", "API密钥与用户名不能为空"}
+            }
+        }
+    case "jwt":
+        if c.WebJWTUserClaim == "" {
+            return &ConfigValidationError{"WebJWTUserClaim", "jwt 认证需要指定用户名字段"}
+        }
+    default:
+        return &ConfigValidationError{"WebAuthMode", "必须为空、apikey 或 jwt"}
+    }
+    if c.WebPublicURL != "" {
+        if u, err := url.Parse(c.WebPublicURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+            return &ConfigValidationError{"WebPublicURL", "必须是 http 或 https 地址"}
+        }
+    }
+    if c.WebhookMaxRetries < 0 {
+        return &ConfigValidationError{"WebhookMaxRetries", "不能为负数"}
+    }
+    if c.WebhookTimeout <= 0 {
+        return &ConfigValidationError{"WebhookTimeout", "必须大于0"}
+    }
+    switch c.OutputStorage {
+    case "", "local":
+    case "s3":
+        if u, err := url.Parse(c.S3Endpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+            return &ConfigValidationError{"S3Endpoint", "必须是 http 或 https 地址"}
+        }
+        if c.S3Bucket == "" {
+            return &ConfigValidationError{"S3Bucket", "使用 s3 存储时不能为空"}
+        }
+        if c.S3Region == "" {
+            return &ConfigValidationError{"S3Region", "使用 s3 存储时不能为空"}
+        }
+    case "webdav":
+        if u, err := url.Parse(c.WebDAVURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+            return &ConfigValidationError{"WebDAVURL", "必须是 http 或 https 地址"}
+        }
+    default:
+        return &ConfigValidationError{"OutputStorage", "必须为 local、s3 或 webdav"}
+    }
+
+    stageNames := make(map[string]bool)
+    for i, stage := range c.PipelineStages {
+        field := fmt.Sprintf("PipelineStages[%d]", i)
+        if stage.Name == "" {
+            return &ConfigValidationError{field + ".Name", "不能为空"}
+        }
+        if stageNames[stage.Name] {
+            return &ConfigValidationError{field + ".Name", "与前面的阶段重名"}
+        }
+        stageNames[stage.Name] = true
+        if stage.After == "" {
+            return &ConfigValidationError{field + ".After", "不能为空"}
+        }
+        if stage.Command == "" {
+            return &ConfigValidationError{field + ".Command", "不能为空"}
+        }
+    }
+
+    return nil
+}
+
+// validateClipRange 检查起止时间的格式，且结束时间晚于起始时间
+func validateClipRange(startField, start, endField, end string) error {
+    var startSeconds, endSeconds float64
+    var err error
+    if start != "" {
+        if startSeconds, err = utils.ParseTimestamp(start); err != nil {
+            return &ConfigValidationError{startField, "格式无效，应为秒数或 hh:mm:ss"}
+        }
+    }
+    if end != "" {
+        if endSeconds, err = utils.ParseTimestamp(end); err != nil {
+            return &ConfigValidationError{endField, "格式无效，应为秒数或 hh:mm:ss"}
+        }
+        if endSeconds <= startSeconds {
+            return &ConfigValidationError{endField, "必须晚于起始时间"}
+        }
+    }
     return nil
 }
 
-// LoadFromFile 从文件加载配置
+// LoadFromFile 从文件加载配置，扩展名为 .yaml 或 .yml 时按 YAML 解析
 func (c *Config) LoadFromFile(path string) error {
     data, err := os.ReadFile(path)
     if err != nil {
         utils.Error("读取配置文件失败: %v", err)
         return err
     }
+    if data, err = DecodeConfigFile(path, data); err != nil {
+        utils.Error("%v", err)
+        return err
+    }
+
+    // 检查配置版本与字段名称，迁移旧配置
+    data, warnings, err := MigrateConfigData(data)
+    if err != nil {
+        utils.Error("%v", err)
+        return err
+    }
+    for _, warning := range warnings {
+        utils.Warn("配置文件 %s: %s", path, warning)
+    }
+    if len(warnings) > 0 {
+        utils.Warn("可运行 asr config migrate --config %s 将迁移结果写回配置文件", path)
+    }
 
     err = json.Unmarshal(data, c)
     if err != nil {
@@ -152,11 +1196,17 @@ func (c *Config) SaveToFile(path string) error {
         return err
     }
 
-    data, err := json.MarshalIndent(c, "", "  ")
+    c.SchemaVersion = ConfigSchemaVersion
+    data, err := json.Marshal(c)
     if err != nil {
         utils.Error("序列化配置失败: %v", err)
         return err
     }
+    // 按扩展名保存为 JSON 或 YAML
+    if data, err = EncodeConfigFile(path, data); err != nil {
+        utils.Error("%v", err)
+        return err
+    }
 
     err = os.WriteFile(path, data, 0644)
     if err != nil {
@@ -199,12 +1249,234 @@ func (c *Config) Update(updates map[string]interface{}) error {
     return nil
 }
 
+// ProfileConfig 返回监控目录的完整配置：复制全局配置，应用 profile.Settings，
+// 并使用 profile 的媒体目录与输出目录
+func (c *Config) ProfileConfig(profile WatchProfile) (*Config, error) {
+    known := configKeys()
+    for key := range profile.Settings {
+        if profileReservedKeys[key] {
+            return nil, &ConfigValidationError{"settings", fmt.Sprintf("%s 不能在 settings 中设置", key)}
+        }
+        if !known[key] {
+            msg := fmt.Sprintf("未知的配置项 %q", key)
+            if suggestion := suggestKey(key, known); suggestion != "" {
+                msg += fmt.Sprintf("，是否应为 %q？", suggestion)
+            }
+            return nil, &ConfigValidationError{"settings", msg}
+        }
+    }
+
+    // 经 JSON 复制，避免与全局配置共用 map 与切片
+    data, err := json.Marshal(c)
+    if err != nil {
+        return nil, fmt.Errorf("复制配置失败: %w", err)
+    }
+    profileConfig := &Config{}
+    if err := json.Unmarshal(data, profileConfig); err != nil {
+        return nil, fmt.Errorf("复制配置失败: %w", err)
+    }
+    if len(profile.Settings) > 0 {
+        settings, err := json.Marshal(profile.Settings)
+        if err != nil {
+            return nil, fmt.Errorf("解析 settings 失败: %w", err)
+        }
+        if err := json.Unmarshal(settings, profileConfig); err != nil {
+            return nil, &ConfigValidationError{"settings", fmt.Sprintf("解析失败: %v", err)}
+        }
+    }
+
+    profileConfig.MediaFolder = profile.MediaFolder
+    profileConfig.OutputFolder = profile.OutputFolder
+    if profileConfig.OutputFolder == "" {
+        profileConfig.OutputFolder = filepath.Join(c.OutputFolder, profile.Name)
+    }
+    profileConfig.WatchProfiles = nil
+    if err := profileConfig.Validate(); err != nil {
+        return nil, err
+    }
+    return profileConfig, nil
+}
+
 // Reset 重置为默认配置
 func (c *Config) Reset() {
     defaultConfig := NewDefaultConfig()
     *c = *defaultConfig
 }
 
+// boolPtr 返回布尔值的指针，用于可选配置项
+func boolPtr(v bool) *bool {
+    return &v
+}
+
+// isHexColor 判断是否为 #RRGGBB 格式的颜色
+func isHexColor(s string) bool {
+    if len(s) != 7 || s[0] != '#' {
+        return false
+    }
+    for _, r := range s[1:] {
+        if !strings.ContainsRune("0123456789abcdefABCDEF", r) {
+            return false
+        }
+    }
+    return true
+}
+
+// AuditLogPath 返回审计日志的实际路径
+func (c *Config) AuditLogPath() string {
+    if c.AuditLog != "" {
+        return c.AuditLog
+    }
+    return filepath.Join(c.OutputFolder, "audit.jsonl")
+}
+
+// UsageStatsPath 返回使用量统计文件的实际路径
+func (c *Config) UsageStatsPath() string {
+    if c.UsageStatsFile != "" {
+        return c.UsageStatsFile
+    }
+    return filepath.Join(c.OutputFolder, "usage_stats.json")
+}
+
+// WebJWTKey 返回 JWT 签名密钥，未配置 web_jwt_secret 时读取环境变量 WEB_JWT_SECRET
+func (c *Config) WebJWTKey() string {
+    if c.WebJWTSecret != "" {
+        return c.WebJWTSecret
+    }
+    return os.Getenv("WEB_JWT_SECRET")
+}
+
+// WebhookKey 返回回调签名密钥，未配置 webhook_secret 时读取环境变量 WEBHOOK_SECRET
+func (c *Config) WebhookKey() string {
+    if c.WebhookSecret != "" {
+        return c.WebhookSecret
+    }
+    return os.Getenv("WEBHOOK_SECRET")
+}
+
+// TelegramToken 返回 Telegram 机器人令牌，未配置 notify_telegram_token 时读取环境变量 TELEGRAM_BOT_TOKEN
+func (c *Config) TelegramToken() string {
+    if c.NotifyTelegramToken != "" {
+        return c.NotifyTelegramToken
+    }
+    return os.Getenv("TELEGRAM_BOT_TOKEN")
+}
+
+// S3Credentials 返回 S3 访问密钥ID与密钥，未配置时读取环境变量 S3_ACCESS_KEY 与 S3_SECRET_KEY
+func (c *Config) S3Credentials() (string, string) {
+    accessKey, secretKey := c.S3AccessKey, c.S3SecretKey
+    if accessKey == "" {
+        accessKey = os.Getenv("S3_ACCESS_KEY")
+    }
+    if secretKey == "" {
+        secretKey = os.Getenv("S3_SECRET_KEY")
+    }
+    return accessKey, secretKey
+}
+
+// WebDAVKey 返回 WebDAV 密码，未配置 webdav_password 时读取环境变量 WEBDAV_PASSWORD
+func (c *Config) WebDAVKey() string {
+    if c.WebDAVPassword != "" {
+        return c.WebDAVPassword
+    }
+    return os.Getenv("WEBDAV_PASSWORD")
+}
+
+// LLMAPIKey 返回大模型API密钥：优先使用 llm.api_key，其次为服务商的环境变量。
+// 火山方舟还兼容旧的 volces_api_key 配置
+func (c *Config) LLMAPIKey() string {
+    if c.LLM.APIKey == "" && c.LLM.ProviderName() == LLMProviderVolces && c.VolcesAPIKey != "" {
+        return c.VolcesAPIKey
+    }
+    return c.LLM.Key()
+}
+
+// TranslateKey 返回翻译服务的API密钥：优先使用 translate_api_key，其次为服务商的环境变量。
+// 使用大模型翻译时不需要
+func (c *Config) TranslateKey() string {
+    if c.TranslateAPIKey != "" {
+        return c.TranslateAPIKey
+    }
+    switch c.TranslateProvider {
+    case TranslateProviderDeepL:
+        return os.Getenv("DEEPL_API_KEY")
+    case TranslateProviderGoogle:
+        return os.Getenv("GOOGLE_TRANSLATE_API_KEY")
+    }
+    return ""
+}
+
+// LLMProvider 返回大模型服务商，未配置时为火山方舟
+func (c *Config) LLMProvider() string {
+    return c.LLM.ProviderName()
+}
+
+// HTTPClientOptions 返回ASR与大模型共享HTTP客户端的设置
+func (c *Config) HTTPClientOptions() utils.HTTPClientOptions {
+    options := utils.DefaultHTTPClientOptions()
+    options.Timeout = time.Duration(c.HTTPTimeout * float64(time.Second))
+    options.MaxRetries = c.HTTPMaxRetries
+    options.BaseDelay = time.Duration(c.HTTPRetryDelay * float64(time.Second))
+    if proxy, err := c.ProxyURL(); err != nil {
+        utils.Warn("代理配置无效，将不使用代理: %v", err)
+    } else {
+        options.Proxy = proxy
+    }
+    return options
+}
+
+// ProxyURL 解析 http_proxy 或 socks5_proxy，均未设置时返回 nil
+func (c *Config) ProxyURL() (*url.URL, error) {
+    raw, schemes := c.HTTPProxy, []string{"http", "https"}
+    if c.SOCKS5Proxy != "" {
+        raw, schemes = c.SOCKS5Proxy, []string{"socks5"}
+        if !strings.Contains(raw, "://") {
+            raw = "socks5://" + raw
+        }
+    }
+    if raw == "" {
+        return nil, nil
+    }
+
+    proxy, err := url.Parse(raw)
+    if err != nil {
+        return nil, fmt.Errorf("无法解析代理地址 %q: %w", raw, err)
+    }
+    if proxy.Host == "" {
+        return nil, fmt.Errorf("代理地址缺少主机: %q", raw)
+    }
+    for _, scheme := range schemes {
+        if proxy.Scheme == scheme {
+            return proxy, nil
+        }
+    }
+    return nil, fmt.Errorf("代理地址必须以 %s:// 开头: %q", strings.Join(schemes, ":// 或 "), raw)
+}
+
+// MediaSelection 返回文件使用的音轨与时间范围：文件名匹配 media_ranges 中的某项时使用该项，
+// 否则使用全局的 audio_track、clip_start 与 clip_end。时间格式无效时视为未设置（Validate 会提前报错）
+func (c *Config) MediaSelection(path string) MediaSelection {
+    track, start, end := c.AudioTrack, c.ClipStart, c.ClipEnd
+    name := filepath.Base(path)
+    for _, r := range c.MediaRanges {
+        if matched, _ := filepath.Match(r.Pattern, name); matched {
+            start, end = r.Start, r.End
+            if r.AudioTrack != nil {
+                track = *r.AudioTrack
+            }
+            break
+        }
+    }
+
+    selection := MediaSelection{AudioTrack: track}
+    if start != "" {
+        selection.Start, _ = utils.ParseTimestamp(start)
+    }
+    if end != "" {
+        selection.End, _ = utils.ParseTimestamp(end)
+    }
+    return selection
+}
+
 // PrintConfig 打印当前配置
 func (c *Config) PrintConfig() {
     utils.Info("\n当前配置:")
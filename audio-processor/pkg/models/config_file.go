@@ -0,0 +1,76 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// IsYAMLConfig 按扩展名判断配置文件是否为 YAML（.yaml、.yml），其余按 JSON 处理
+func IsYAMLConfig(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return true
+	}
+	return false
+}
+
+// DecodeConfigFile 将配置文件内容转换为 JSON，供迁移与解析共用。字段名与 JSON 配置相同
+func DecodeConfigFile(path string, data []byte) ([]byte, error) {
+	if !IsYAMLConfig(path) {
+		return data, nil
+	}
+	var value interface{}
+	if err := yaml.Unmarshal(data, &value); err != nil {
+		return nil, fmt.Errorf("解析YAML配置失败: %w", err)
+	}
+	if value == nil {
+		// 空文件或只有注释
+		value = map[string]interface{}{}
+	}
+	out, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("转换YAML配置失败: %w", err)
+	}
+	return out, nil
+}
+
+// EncodeConfigFile 将 JSON 配置内容按 path 的扩展名编码为写入文件的内容，YAML 保持字段顺序
+func EncodeConfigFile(path string, data []byte) ([]byte, error) {
+	var out bytes.Buffer
+	if !IsYAMLConfig(path) {
+		if err := json.Indent(&out, data, "", "  "); err != nil {
+			return nil, fmt.Errorf("格式化配置失败: %w", err)
+		}
+		out.WriteByte('\n')
+		return out.Bytes(), nil
+	}
+
+	// JSON 也是合法的 YAML，解析为节点后改用块格式输出
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return nil, fmt.Errorf("转换配置失败: %w", err)
+	}
+	blockStyle(&node)
+	encoder := yaml.NewEncoder(&out)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&node); err != nil {
+		return nil, fmt.Errorf("编码YAML配置失败: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, fmt.Errorf("编码YAML配置失败: %w", err)
+	}
+	return out.Bytes(), nil
+}
+
+// blockStyle 去掉从 JSON 解析得到的流格式与引号，需要时由编码器重新加引号
+func blockStyle(node *yaml.Node) {
+	node.Style &^= yaml.FlowStyle | yaml.DoubleQuotedStyle
+	for _, child := range node.Content {
+		blockStyle(child)
+	}
+}
@@ -0,0 +1,127 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// EnvPrefix 覆盖配置项的环境变量前缀
+const EnvPrefix = "ASR_"
+
+// EnvName 返回配置项对应的环境变量名，如 media_folder 对应 ASR_MEDIA_FOLDER。
+// asr_ 开头的配置项不重复前缀，asr_service 对应 ASR_SERVICE
+func EnvName(key string) string {
+	return EnvPrefix + strings.ToUpper(strings.TrimPrefix(key, "asr_"))
+}
+
+// ApplyEnv 用环境变量覆盖配置项，lookup 通常为 os.LookupEnv，返回被覆盖的配置项。
+// 值的写法与 Set 相同
+func (c *Config) ApplyEnv(lookup func(string) (string, bool)) ([]string, error) {
+	keys := make([]string, 0)
+	for key := range configKeys() {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var applied []string
+	for _, key := range keys {
+		value, ok := lookup(EnvName(key))
+		if !ok {
+			continue
+		}
+		if err := c.setKey(key, value); err != nil {
+			return applied, fmt.Errorf("环境变量 %s: %w", EnvName(key), err)
+		}
+		applied = append(applied, key)
+	}
+	return applied, nil
+}
+
+// Set 覆盖一项配置。key 为配置文件中的字段名，也接受大小写、下划线不同的写法；
+// 字符串直接使用，字符串列表可用逗号分隔，其余类型使用 JSON，如 true、4、{"bcut":{"weight":2}}
+func (c *Config) Set(key, value string) error {
+	known := configKeys()
+	if !known[key] {
+		target, ok := configAliases[key]
+		if !ok {
+			for candidate := range known {
+				if normalizeKey(candidate) == normalizeKey(key) {
+					target, ok = candidate, true
+					break
+				}
+			}
+		}
+		if !ok {
+			if suggestion := suggestKey(key, known); suggestion != "" {
+				return fmt.Errorf("未知的配置项 %q，是否为 %q", key, suggestion)
+			}
+			return fmt.Errorf("未知的配置项 %q", key)
+		}
+		key = target
+	}
+	return c.setKey(key, value)
+}
+
+// setKey 将文本值按字段类型转换为 JSON 后写入配置，对象与列表整体替换
+func (c *Config) setKey(key, value string) error {
+	field, ok := configField(key)
+	if !ok {
+		return fmt.Errorf("未知的配置项 %q", key)
+	}
+
+	var raw []byte
+	switch {
+	case field.Type.Kind() == reflect.String:
+		raw, _ = json.Marshal(value)
+	case field.Type == reflect.TypeOf([]string(nil)) && !strings.HasPrefix(strings.TrimSpace(value), "["):
+		items := make([]string, 0)
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		raw, _ = json.Marshal(items)
+	default:
+		if !json.Valid([]byte(value)) {
+			return fmt.Errorf("配置项 %s 的值 %q 无效，应为 %s", key, value, jsonTypeName(field.Type))
+		}
+		raw = []byte(value)
+	}
+
+	// 先解析到字段类型的新值，避免对象与已有的值合并
+	target := reflect.New(field.Type)
+	if err := json.Unmarshal(raw, target.Interface()); err != nil {
+		return fmt.Errorf("配置项 %s 的值 %q 无效，应为 %s", key, value, jsonTypeName(field.Type))
+	}
+	reflect.ValueOf(c).Elem().FieldByIndex(field.Index).Set(target.Elem())
+	return nil
+}
+
+// configField 按 JSON 字段名查找配置字段
+func configField(key string) (reflect.StructField, bool) {
+	t := reflect.TypeOf(Config{})
+	for i := 0; i < t.NumField(); i++ {
+		if strings.Split(t.Field(i).Tag.Get("json"), ",")[0] == key {
+			return t.Field(i), true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// jsonTypeName 返回字段类型在错误提示中的名称
+func jsonTypeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Bool:
+		return "true 或 false"
+	case reflect.Int, reflect.Int64:
+		return "整数"
+	case reflect.Float64:
+		return "数字"
+	case reflect.Slice:
+		return "JSON 数组"
+	}
+	return "JSON 对象"
+}
@@ -0,0 +1,74 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnvName(t *testing.T) {
+	assert.Equal(t, "ASR_MEDIA_FOLDER", EnvName("media_folder"))
+	assert.Equal(t, "ASR_SERVICE", EnvName("asr_service"))
+
+	// 每个配置项的环境变量名都不相同
+	seen := make(map[string]string)
+	for key := range configKeys() {
+		name := EnvName(key)
+		assert.NotContains(t, seen, name, "%s 与 %s", key, seen[name])
+		seen[name] = key
+	}
+}
+
+func TestConfigApplyEnv(t *testing.T) {
+	env := map[string]string{
+		"ASR_MEDIA_FOLDER":  "/data/media",
+		"ASR_MAX_WORKERS":   "2",
+		"ASR_PROCESS_VIDEO": "false",
+		"ASR_SERVICE":       "bcut",
+		"ASR_WATCH_INCLUDE": "*.mp4, *.mkv",
+		"ASR_QUEUE_PINNED":  `["a,b"]`,
+		"ASR_SERVICES":      `{"bcut":{"weight":5}}`,
+	}
+	lookup := func(name string) (string, bool) {
+		value, ok := env[name]
+		return value, ok
+	}
+
+	config := NewDefaultConfig()
+	applied, err := config.ApplyEnv(lookup)
+	require.NoError(t, err)
+	assert.Len(t, applied, len(env))
+	assert.Equal(t, "/data/media", config.MediaFolder)
+	assert.Equal(t, 2, config.MaxWorkers)
+	assert.False(t, config.ProcessVideo)
+	assert.Equal(t, "bcut", config.ASRService)
+	assert.Equal(t, []string{"*.mp4", "*.mkv"}, config.WatchInclude)
+	assert.Equal(t, []string{"a,b"}, config.QueuePinned)
+	// 对象整体替换，不与默认值合并
+	assert.Equal(t, map[string]ASRServiceConfig{"bcut": {Weight: 5}}, config.ASRServices)
+
+	env = map[string]string{"ASR_MAX_WORKERS": "many"}
+	_, err = NewDefaultConfig().ApplyEnv(lookup)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "ASR_MAX_WORKERS")
+	assert.Contains(t, err.Error(), "整数")
+}
+
+func TestConfigSet(t *testing.T) {
+	config := NewDefaultConfig()
+	require.NoError(t, config.Set("max_workers", "3"))
+	require.NoError(t, config.Set("MaxWorkers", "4"))
+	require.NoError(t, config.Set("ExprotSRT", "false"))
+	require.NoError(t, config.Set("language", "true"))
+	assert.Equal(t, 4, config.MaxWorkers)
+	assert.False(t, config.ExportSRT)
+	assert.Equal(t, "true", config.Language)
+
+	err := config.Set("max_worker", "2")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `"max_workers"`)
+
+	assert.Error(t, config.Set("process_video", "yes"))
+	assert.Error(t, config.Set("max_workers", `"2"`))
+}
@@ -0,0 +1,212 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ConfigSchemaVersion 当前程序使用的配置文件格式版本。
+// 修改配置结构且需要迁移旧配置时递增，并在 configMigrations 中追加迁移步骤。
+const ConfigSchemaVersion = 1
+
+// configAliases 旧版本或常见拼写错误的配置项名称与当前名称的对应关系
+var configAliases = map[string]string{
+	"watch":     "watch_mode",
+	"ExprotSRT": "export_srt",
+}
+
+// configMigration 将原始配置从上一个版本迁移到下一个版本，返回迁移说明
+type configMigration func(raw map[string]json.RawMessage) ([]string, error)
+
+// configMigrations 第 i 项负责从版本 i 迁移到版本 i+1
+var configMigrations = []configMigration{
+	migrateV0ToV1,
+}
+
+// MigrateConfigData 检查配置文件内容的版本与字段，尽可能迁移到当前格式。
+// 返回迁移后的配置内容及需要提示用户的警告。
+func MigrateConfigData(data []byte) ([]byte, []string, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, nil, fmt.Errorf("解析配置文件失败: %w", err)
+	}
+
+	version := 0
+	if v, ok := raw["schema_version"]; ok {
+		if err := json.Unmarshal(v, &version); err != nil {
+			return nil, nil, fmt.Errorf("schema_version 必须是整数: %w", err)
+		}
+	}
+
+	var warnings []string
+	if version > ConfigSchemaVersion {
+		warnings = append(warnings, fmt.Sprintf(
+			"配置文件版本 %d 高于程序支持的版本 %d，可能由更新的程序生成，新增的配置项将被忽略；请升级程序",
+			version, ConfigSchemaVersion))
+	}
+
+	for v := version; v < ConfigSchemaVersion; v++ {
+		notes, err := configMigrations[v](raw)
+		if err != nil {
+			return nil, nil, fmt.Errorf("配置从版本 %d 迁移失败: %w", v, err)
+		}
+		warnings = append(warnings, notes...)
+	}
+	if version < ConfigSchemaVersion {
+		raw["schema_version"] = json.RawMessage(fmt.Sprint(ConfigSchemaVersion))
+	}
+
+	warnings = append(warnings, normalizeConfigKeys(raw, version <= ConfigSchemaVersion)...)
+
+	migrated, err := json.Marshal(raw)
+	if err != nil {
+		return nil, nil, fmt.Errorf("序列化配置失败: %w", err)
+	}
+	return migrated, warnings, nil
+}
+
+// migrateV0ToV1 版本 0（无 schema_version 字段）到版本 1：
+// 旧的 use_kuaishou/use_bcut 开关迁移为 asr_services 中的 enabled
+func migrateV0ToV1(raw map[string]json.RawMessage) ([]string, error) {
+	if _, ok := raw["asr_services"]; ok {
+		return nil, nil
+	}
+
+	services := NewDefaultConfig().ASRServices
+	legacy := map[string]string{"use_kuaishou": "kuaishou", "use_bcut": "bcut"}
+	keys := make([]string, 0, len(legacy))
+	for key := range legacy {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var notes []string
+	for _, key := range keys {
+		value, ok := raw[key]
+		if !ok {
+			continue
+		}
+		var enabled bool
+		if err := json.Unmarshal(value, &enabled); err != nil {
+			return nil, fmt.Errorf("%s 必须是布尔值: %w", key, err)
+		}
+		name := legacy[key]
+		service := services[name]
+		service.Enabled = boolPtr(enabled)
+		services[name] = service
+		notes = append(notes, fmt.Sprintf("配置项 %q 已由 asr_services.%s.enabled 取代，已自动迁移为 %v", key, name, enabled))
+	}
+	if len(notes) == 0 {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(services)
+	if err != nil {
+		return nil, err
+	}
+	raw["asr_services"] = data
+	return notes, nil
+}
+
+// normalizeConfigKeys 将别名、Go 字段名或大小写不一致的配置项改为当前名称，
+// 并对无法识别的配置项给出提示。knownVersion 为 false 时（配置来自更新的程序）不提示未知字段。
+func normalizeConfigKeys(raw map[string]json.RawMessage, knownVersion bool) []string {
+	known := configKeys()
+	byNormalized := make(map[string]string, len(known))
+	for key := range known {
+		byNormalized[normalizeKey(key)] = key
+	}
+
+	keys := make([]string, 0, len(raw))
+	for key := range raw {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var warnings []string
+	for _, key := range keys {
+		if known[key] {
+			continue
+		}
+
+		target, ok := configAliases[key]
+		if !ok {
+			target, ok = byNormalized[normalizeKey(key)]
+		}
+		if !ok {
+			if knownVersion {
+				msg := fmt.Sprintf("未知的配置项 %q 将被忽略", key)
+				if suggestion := suggestKey(key, known); suggestion != "" {
+					msg += fmt.Sprintf("，是否应为 %q？", suggestion)
+				}
+				warnings = append(warnings, msg)
+			}
+			continue
+		}
+
+		if _, exists := raw[target]; exists {
+			warnings = append(warnings, fmt.Sprintf("配置项 %q 与 %q 重复，已使用 %q 的值，请删除 %q", key, target, target, key))
+		} else {
+			raw[target] = raw[key]
+			warnings = append(warnings, fmt.Sprintf("配置项 %q 应写作 %q，已自动迁移", key, target))
+		}
+		delete(raw, key)
+	}
+	return warnings
+}
+
+// configKeys 返回 Config 中所有配置项的 JSON 名称
+func configKeys() map[string]bool {
+	keys := make(map[string]bool)
+	t := reflect.TypeOf(Config{})
+	for i := 0; i < t.NumField(); i++ {
+		tag := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		if tag != "" && tag != "-" {
+			keys[tag] = true
+		}
+	}
+	return keys
+}
+
+// normalizeKey 忽略大小写与下划线，使 ExportJSON、exportJson 与 export_json 视为同一配置项
+func normalizeKey(key string) string {
+	return strings.ToLower(strings.NewReplacer("_", "", "-", "").Replace(key))
+}
+
+// suggestKey 为未知的配置项找出拼写最接近的已知配置项
+func suggestKey(key string, known map[string]bool) string {
+	best := ""
+	bestDistance := 3 // 编辑距离超过2时不给出建议
+	normalized := normalizeKey(key)
+	for candidate := range known {
+		d := editDistance(normalized, normalizeKey(candidate))
+		if d < bestDistance || (d == bestDistance && best != "" && candidate < best) {
+			best, bestDistance = candidate, d
+		}
+	}
+	return best
+}
+
+// editDistance 计算两个字符串的编辑距离
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
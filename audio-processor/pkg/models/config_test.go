@@ -1,7 +1,10 @@
 package models
 
 import (
+	"encoding/json"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -72,6 +75,45 @@ func TestConfigSaveAndLoad(t *testing.T) {
 	assert.Equal(t, originalConfig.ExportSRT, loadedConfig.ExportSRT)
 }
 
+func TestConfigYAML(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+
+	original := NewDefaultConfig()
+	original.MediaFolder = filepath.Join(dir, "media")
+	original.OutputFolder = filepath.Join(dir, "output")
+	original.ExportVTT = true
+	original.QueuePinned = []string{"*urgent*", "yes", "0755"}
+	assert.NoError(t, original.SaveToFile(path))
+
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	text := string(data)
+	assert.Contains(t, text, "schema_version: 1\n")
+	assert.Contains(t, text, "export_vtt: true\n")
+	assert.Less(t, strings.Index(text, "media_folder:"), strings.Index(text, "output_folder:"), "保持字段顺序")
+	assert.NotContains(t, text, "{")
+
+	loaded := NewDefaultConfig()
+	assert.NoError(t, loaded.LoadFromFile(path))
+	assert.Equal(t, original.MediaFolder, loaded.MediaFolder)
+	assert.True(t, loaded.ExportVTT)
+	assert.Equal(t, original.QueuePinned, loaded.QueuePinned, "看起来像布尔值或数字的字符串保持为字符串")
+
+	// 手写的 YAML，未列出的配置项使用默认值
+	handwritten := "# 注释\nmedia_folder: " + filepath.Join(dir, "in") + "\nexport_srt: false\nmax_workers: 2\n"
+	assert.NoError(t, os.WriteFile(path, []byte(handwritten), 0644))
+	loaded = NewDefaultConfig()
+	assert.NoError(t, loaded.LoadFromFile(path))
+	assert.Equal(t, filepath.Join(dir, "in"), loaded.MediaFolder)
+	assert.False(t, loaded.ExportSRT)
+	assert.Equal(t, 2, loaded.MaxWorkers)
+	assert.Equal(t, 3, loaded.MaxRetries)
+
+	assert.NoError(t, os.WriteFile(path, []byte("media_folder: [\n"), 0644))
+	assert.Error(t, NewDefaultConfig().LoadFromFile(path))
+}
+
 func TestConfigUpdate(t *testing.T) {
 	config := NewDefaultConfig()
 	
@@ -114,3 +156,140 @@ func TestConfigReset(t *testing.T) {
 	assert.Equal(t, 3, config.MaxRetries)
 	assert.False(t, config.ExportSRT)
 }
+
+func TestMigrateConfigData(t *testing.T) {
+	legacy := []byte(`{
+		"watch": true,
+		"ExprotSRT": true,
+		"ExportJSON": true,
+		"use_kuaishou": true,
+		"max_retris": 5
+	}`)
+
+	data, warnings, err := MigrateConfigData(legacy)
+	assert.NoError(t, err)
+	assert.Len(t, warnings, 5)
+	assert.Contains(t, strings.Join(warnings, "\n"), `是否应为 "max_retries"`)
+
+	config := NewDefaultConfig()
+	config.WatchMode = false
+	assert.NoError(t, json.Unmarshal(data, config))
+	assert.Equal(t, ConfigSchemaVersion, config.SchemaVersion)
+	assert.True(t, config.WatchMode)
+	assert.True(t, config.ExportSRT)
+	assert.True(t, config.ExportJSON)
+	assert.True(t, config.ASRServices["kuaishou"].IsEnabled())
+	assert.Equal(t, 30, config.ASRServices["bcut"].Weight)
+
+	// 已是当前版本的配置不再迁移
+	_, warnings, err = MigrateConfigData([]byte(`{"schema_version": 1, "use_kuaishou": true}`))
+	assert.NoError(t, err)
+	assert.Empty(t, warnings)
+
+	// 更新版本的配置给出升级提示，且不对未知字段报警
+	_, warnings, err = MigrateConfigData([]byte(`{"schema_version": 99, "new_feature": 1}`))
+	assert.NoError(t, err)
+	assert.Len(t, warnings, 1)
+}
+
+// TestConfigProxyURL 测试解析与校验代理配置
+func TestConfigProxyURL(t *testing.T) {
+	config := NewDefaultConfig()
+	proxy, err := config.ProxyURL()
+	assert.NoError(t, err)
+	assert.Nil(t, proxy)
+
+	config.SOCKS5Proxy = "127.0.0.1:1080"
+	proxy, err = config.ProxyURL()
+	assert.NoError(t, err)
+	assert.Equal(t, "socks5://127.0.0.1:1080", proxy.String())
+	assert.Equal(t, proxy, config.HTTPClientOptions().Proxy)
+
+	config.HTTPProxy = "http://127.0.0.1:7890"
+	configErr, ok := config.Validate().(*ConfigValidationError)
+	assert.True(t, ok)
+	assert.Equal(t, "HTTPProxy", configErr.Field)
+
+	config.SOCKS5Proxy = ""
+	config.HTTPProxy = "ftp://127.0.0.1:21"
+	configErr, ok = config.Validate().(*ConfigValidationError)
+	assert.True(t, ok)
+	assert.Equal(t, "HTTPProxy", configErr.Field)
+}
+
+// TestConfigMediaSelection 测试按文件名选择音轨与时间范围
+func TestConfigMediaSelection(t *testing.T) {
+	config := NewDefaultConfig()
+	assert.Equal(t, MediaSelection{}, config.MediaSelection("talk.mp4"))
+	assert.False(t, config.MediaSelection("talk.mp4").Clipped())
+
+	track := 1
+	config.ClipStart = "00:30"
+	config.MediaRanges = []MediaRangeConfig{
+		{Pattern: "lecture-*.mkv", AudioTrack: &track, Start: "1:02:03.5"},
+	}
+	assert.NoError(t, config.Validate())
+	assert.Equal(t, MediaSelection{AudioTrack: 1, Start: 3723.5}, config.MediaSelection("/media/lecture-01.mkv"))
+	assert.Equal(t, MediaSelection{Start: 30}, config.MediaSelection("/media/talk.mp4"))
+
+	config.ClipEnd = "20"
+	configErr, ok := config.Validate().(*ConfigValidationError)
+	assert.True(t, ok)
+	assert.Equal(t, "ClipEnd", configErr.Field)
+
+	config.ClipEnd = ""
+	config.MediaRanges[0].End = "1:75"
+	configErr, ok = config.Validate().(*ConfigValidationError)
+	assert.True(t, ok)
+	assert.Equal(t, "MediaRanges[0].End", configErr.Field)
+}
+
+// TestConfigProfileConfig 测试监控目录的配置覆盖全局配置，且不影响全局配置
+func TestConfigProfileConfig(t *testing.T) {
+	config := NewDefaultConfig()
+	config.OutputFolder = "out"
+	config.WatchProfiles = []WatchProfile{
+		{Name: "podcasts", MediaFolder: "podcasts", Settings: map[string]json.RawMessage{
+			"asr_service":  json.RawMessage(`"bcut"`),
+			"language":     json.RawMessage(`"en"`),
+			"asr_services": json.RawMessage(`{"bcut": {"weight": 9}}`),
+		}},
+		{Name: "meetings", MediaFolder: "meetings", OutputFolder: "minutes"},
+	}
+	assert.NoError(t, config.Validate())
+
+	podcasts, err := config.ProfileConfig(config.WatchProfiles[0])
+	assert.NoError(t, err)
+	assert.Equal(t, "podcasts", podcasts.MediaFolder)
+	assert.Equal(t, filepath.Join("out", "podcasts"), podcasts.OutputFolder)
+	assert.Equal(t, "bcut", podcasts.ASRService)
+	assert.Equal(t, "en", podcasts.Language)
+	assert.Equal(t, 9, podcasts.ASRServices["bcut"].Weight)
+	assert.Nil(t, podcasts.WatchProfiles)
+	assert.Equal(t, "auto", config.ASRService, "全局配置不变")
+	assert.NotEqual(t, 9, config.ASRServices["bcut"].Weight, "不与全局配置共用 map")
+
+	meetings, err := config.ProfileConfig(config.WatchProfiles[1])
+	assert.NoError(t, err)
+	assert.Equal(t, "minutes", meetings.OutputFolder)
+	assert.Equal(t, config.Language, meetings.Language)
+
+	invalid := []struct {
+		profile WatchProfile
+		field   string
+	}{
+		{WatchProfile{Name: "a", MediaFolder: "podcasts"}, "WatchProfiles[2]"},
+		{WatchProfile{Name: "meetings", MediaFolder: "x"}, "WatchProfiles[2]"},
+		{WatchProfile{Name: "a/b", MediaFolder: "x"}, "WatchProfiles[2]"},
+		{WatchProfile{Name: "a", MediaFolder: "x", Settings: map[string]json.RawMessage{"languag": json.RawMessage(`"en"`)}}, "WatchProfiles[2].settings"},
+		{WatchProfile{Name: "a", MediaFolder: "x", Settings: map[string]json.RawMessage{"output_folder": json.RawMessage(`"y"`)}}, "WatchProfiles[2].settings"},
+		{WatchProfile{Name: "a", MediaFolder: "x", Settings: map[string]json.RawMessage{"trash_mode": json.RawMessage(`"bin"`)}}, "WatchProfiles[2].TrashMode"},
+	}
+	for _, c := range invalid {
+		config.WatchProfiles = append(config.WatchProfiles[:2], c.profile)
+		configErr, ok := config.Validate().(*ConfigValidationError)
+		if assert.True(t, ok, "%+v", c.profile) {
+			assert.Equal(t, c.field, configErr.Field)
+		}
+	}
+}
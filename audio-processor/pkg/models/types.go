@@ -3,8 +3,39 @@ package models
 
 // DataSegment 表示一个语音识别结果段落，对应Python中的ASRDataSeg
 type DataSegment struct {
-	Text      string   `json:"text"` // 识别出的文本内容
-	StartTime float64  `json:"start_time"` // 开始时间（秒）
-	EndTime   float64  `json:"end_time"`   // 结束时间（秒）
+	Text       string       `json:"text"`                 // 识别出的文本内容
+	StartTime  float64      `json:"start_time"`           // 开始时间（秒）
+	EndTime    float64      `json:"end_time"`             // 结束时间（秒）
+	Confidence float64      `json:"confidence,omitempty"` // 置信度（0-1），服务未提供时为0
+	Words      []WordTiming `json:"words,omitempty"`      // 逐词时间戳，服务未提供时为空
+	Speaker    string       `json:"speaker,omitempty"`    // 说话人标签，未做说话人分离时为空
 }
 
+// WordTiming 表示段落中单个词（或字）的时间信息
+type WordTiming struct {
+	Text       string  `json:"text"`                 // 词文本
+	StartTime  float64 `json:"start_time"`           // 开始时间（秒）
+	EndTime    float64 `json:"end_time"`             // 结束时间（秒）
+	Confidence float64 `json:"confidence,omitempty"` // 置信度（0-1），服务未提供时为0
+}
+
+// HasWordTimings 判断段落是否带有逐词时间戳
+func (s DataSegment) HasWordTimings() bool {
+	return len(s.Words) > 0
+}
+
+// Shift 返回整体平移 offset 秒后的段落，逐词时间戳同步平移
+func (s DataSegment) Shift(offset float64) DataSegment {
+	shifted := s
+	shifted.StartTime += offset
+	shifted.EndTime += offset
+	if len(s.Words) > 0 {
+		shifted.Words = make([]WordTiming, len(s.Words))
+		for i, w := range s.Words {
+			w.StartTime += offset
+			w.EndTime += offset
+			shifted.Words[i] = w
+		}
+	}
+	return shifted
+}
@@ -0,0 +1,108 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// 传给通知脚本的环境变量，避免标题与正文中的引号破坏脚本
+const (
+	titleEnv = "AUDIOPROC_NOTIFY_TITLE"
+	bodyEnv  = "AUDIOPROC_NOTIFY_BODY"
+)
+
+// windowsToastScript 通过 Windows 通知中心显示通知
+const windowsToastScript = `[Windows.UI.Notifications.ToastNotificationManager, Windows.UI.Notifications, ContentType = WindowsRuntime] > $null
+$template = [Windows.UI.Notifications.ToastNotificationManager]::GetTemplateContent([Windows.UI.Notifications.ToastTemplateType]::ToastText02)
+$text = $template.GetElementsByTagName('text')
+$text.Item(0).AppendChild($template.CreateTextNode($env:AUDIOPROC_NOTIFY_TITLE)) > $null
+$text.Item(1).AppendChild($template.CreateTextNode($env:AUDIOPROC_NOTIFY_BODY)) > $null
+$toast = [Windows.UI.Notifications.ToastNotification]::new($template)
+[Windows.UI.Notifications.ToastNotificationManager]::CreateToastNotifier('audioproc').Show($toast)`
+
+// desktopNotifier 调用系统命令显示桌面通知或播放提示音：Windows 使用 PowerShell，
+// macOS 使用 osascript，其他系统使用 notify-send（libnotify）与 canberra-gtk-play，没有时响终端铃声
+type desktopNotifier struct {
+	popup bool
+	sound bool
+}
+
+func (n *desktopNotifier) Name() string { return "桌面" }
+
+func (n *desktopNotifier) Notify(ctx context.Context, event Event) error {
+	switch runtime.GOOS {
+	case "windows":
+		return n.notifyWindows(ctx, event)
+	case "darwin":
+		return n.notifyMac(ctx, event)
+	default:
+		return n.notifyLinux(ctx, event)
+	}
+}
+
+func (n *desktopNotifier) notifyWindows(ctx context.Context, event Event) error {
+	var script []string
+	if n.popup {
+		script = append(script, windowsToastScript)
+	}
+	if n.sound {
+		sound := "Asterisk"
+		if event.Failed() {
+			sound = "Hand"
+		}
+		script = append(script, fmt.Sprintf("[System.Media.SystemSounds]::%s.Play(); Start-Sleep -Milliseconds 500", sound))
+	}
+	return run(ctx, event, "powershell", "-NoProfile", "-NonInteractive", "-Command", strings.Join(script, "\n"))
+}
+
+func (n *desktopNotifier) notifyMac(ctx context.Context, event Event) error {
+	sound := "Glass"
+	if event.Failed() {
+		sound = "Basso"
+	}
+	if !n.popup {
+		return run(ctx, event, "afplay", "/System/Library/Sounds/"+sound+".aiff")
+	}
+	script := fmt.Sprintf(`display notification (system attribute "%s") with title (system attribute "%s")`, bodyEnv, titleEnv)
+	if n.sound {
+		script += fmt.Sprintf(" sound name %q", sound)
+	}
+	return run(ctx, event, "osascript", "-e", script)
+}
+
+func (n *desktopNotifier) notifyLinux(ctx context.Context, event Event) error {
+	if n.popup {
+		urgency := "normal"
+		if event.Failed() {
+			urgency = "critical"
+		}
+		if err := run(ctx, event, "notify-send", "-a", "audioproc", "-u", urgency, event.Title(), event.Body()); err != nil {
+			return err
+		}
+	}
+	if n.sound {
+		sound := "complete"
+		if event.Failed() {
+			sound = "dialog-error"
+		}
+		if _, err := exec.LookPath("canberra-gtk-play"); err == nil {
+			return run(ctx, event, "canberra-gtk-play", "-i", sound)
+		}
+		fmt.Fprint(os.Stderr, "\a")
+	}
+	return nil
+}
+
+// run 执行通知命令，标题与正文通过环境变量传入
+func run(ctx context.Context, event Event, name string, args ...string) error {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Env = append(os.Environ(), titleEnv+"="+event.Title(), bodyEnv+"="+event.Body())
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%s: %v, %s", name, err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+// Package notify 在监听模式下文件处理结束时发送通知：系统桌面通知与提示音，
+// 以及 webhook、Telegram 与 Bark 推送，无人值守的长时间处理无需一直盯着终端
+package notify
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/models"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/utils"
+)
+
+// 通知事件
+const (
+	EventCompleted = "watch.completed" // 处理成功
+	EventFailed    = "watch.failed"    // 多次重试后仍然失败
+)
+
+// notify_on 取值
+const (
+	OnAll     = "all"
+	OnFailure = "failure"
+	OnOff     = "off"
+)
+
+// 单次通知（包括重试）的最长时间
+const sendTimeout = 2 * time.Minute
+
+// Event 一个文件的处理结果
+type Event struct {
+	Event   string    `json:"event"` // EventCompleted 或 EventFailed
+	Path    string    `json:"path"`
+	Name    string    `json:"name"`
+	Folder  string    `json:"folder"`            // 监控目录
+	Message string    `json:"message,omitempty"` // 失败原因或成功后原文件的去向
+	Time    time.Time `json:"time"`
+}
+
+// Failed 是否为失败事件
+func (e Event) Failed() bool {
+	return e.Event == EventFailed
+}
+
+// Title 通知标题
+func (e Event) Title() string {
+	if e.Failed() {
+		return "转写失败: " + e.Name
+	}
+	return "转写完成: " + e.Name
+}
+
+// Body 通知正文
+func (e Event) Body() string {
+	if e.Message != "" {
+		return e.Message
+	}
+	return e.Path
+}
+
+// Notifier 一种通知方式
+type Notifier interface {
+	Name() string
+	Notify(ctx context.Context, event Event) error
+}
+
+// Dispatcher 将事件发送给所有通知方式。nil 值不发送任何通知
+type Dispatcher struct {
+	notifiers []Notifier
+	failOnly  bool
+	wg        sync.WaitGroup
+}
+
+// New 创建通知分发器，failOnly 为 true 时只通知失败事件
+func New(notifiers []Notifier, failOnly bool) *Dispatcher {
+	return &Dispatcher{notifiers: notifiers, failOnly: failOnly}
+}
+
+// FromConfig 按配置创建通知分发器，notify_on 为 off 或没有启用任何通知方式时返回 nil
+func FromConfig(config *models.Config) *Dispatcher {
+	if config.NotifyOn == OnOff {
+		return nil
+	}
+	var notifiers []Notifier
+	if config.NotifyDesktop || config.NotifySound {
+		notifiers = append(notifiers, &desktopNotifier{popup: config.NotifyDesktop, sound: config.NotifySound})
+	}
+
+	client := utils.NewHTTPClient(config.HTTPClientOptions())
+	if config.NotifyWebhookURL != "" {
+		notifiers = append(notifiers, &webhookNotifier{client: client, url: config.NotifyWebhookURL, secret: config.WebhookKey()})
+	}
+	if token := config.TelegramToken(); token != "" && config.NotifyTelegramChat != "" {
+		notifiers = append(notifiers, &telegramNotifier{client: client, apiBase: telegramAPI, token: token, chat: config.NotifyTelegramChat})
+	}
+	if config.NotifyBarkURL != "" {
+		notifiers = append(notifiers, &barkNotifier{client: client, url: config.NotifyBarkURL})
+	}
+	if len(notifiers) == 0 {
+		return nil
+	}
+	return New(notifiers, config.NotifyOn == OnFailure)
+}
+
+// NewEvent 创建文件 path 的处理结果事件
+func NewEvent(event, path, folder, message string) Event {
+	return Event{
+		Event:   event,
+		Path:    path,
+		Name:    filepath.Base(path),
+		Folder:  folder,
+		Message: message,
+		Time:    time.Now(),
+	}
+}
+
+// Send 在后台发送通知，不阻塞处理流程，失败只记录警告
+func (d *Dispatcher) Send(event Event) {
+	if d == nil || (d.failOnly && !event.Failed()) {
+		return
+	}
+	for _, notifier := range d.notifiers {
+		d.wg.Add(1)
+		go func(notifier Notifier) {
+			defer d.wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+			defer cancel()
+			if err := notifier.Notify(ctx, event); err != nil {
+				utils.Warn("发送%s通知失败: %v", notifier.Name(), err)
+			}
+		}(notifier)
+	}
+}
+
+// Wait 等待已发送的通知完成，退出前调用
+func (d *Dispatcher) Wait() {
+	if d == nil {
+		return
+	}
+	d.wg.Wait()
+}
@@ -0,0 +1,118 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/audio"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/models"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeNotifier 记录收到的事件
+type fakeNotifier struct {
+	mu     sync.Mutex
+	events []Event
+}
+
+func (f *fakeNotifier) Name() string { return "fake" }
+
+func (f *fakeNotifier) Notify(ctx context.Context, event Event) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.events = append(f.events, event)
+	return nil
+}
+
+func TestDispatcher(t *testing.T) {
+	completed := NewEvent(EventCompleted, "/media/a.mp4", "/media", "")
+	failed := NewEvent(EventFailed, "/media/b.mp4", "/media", "连续 3 次处理失败")
+	assert.Equal(t, "转写完成: a.mp4", completed.Title())
+	assert.Equal(t, "/media/a.mp4", completed.Body())
+	assert.Equal(t, "连续 3 次处理失败", failed.Body())
+
+	all := &fakeNotifier{}
+	dispatcher := New([]Notifier{all}, false)
+	dispatcher.Send(completed)
+	dispatcher.Send(failed)
+	dispatcher.Wait()
+	assert.Len(t, all.events, 2)
+
+	failures := &fakeNotifier{}
+	dispatcher = New([]Notifier{failures}, true)
+	dispatcher.Send(completed)
+	dispatcher.Send(failed)
+	dispatcher.Wait()
+	require.Len(t, failures.events, 1, "notify_on 为 failure 时只通知失败")
+	assert.Equal(t, "b.mp4", failures.events[0].Name)
+
+	// 未配置通知方式时不通知
+	var disabled *Dispatcher
+	disabled.Send(failed)
+	disabled.Wait()
+	assert.Nil(t, FromConfig(models.NewDefaultConfig()))
+
+	config := models.NewDefaultConfig()
+	config.NotifyBarkURL = "https://api.day.app/key"
+	assert.NotNil(t, FromConfig(config))
+	config.NotifyOn = OnOff
+	assert.Nil(t, FromConfig(config))
+}
+
+// TestPushNotifiers 测试 webhook、Telegram 与 Bark 的请求内容
+func TestPushNotifiers(t *testing.T) {
+	type request struct {
+		path   string
+		header http.Header
+		body   []byte
+	}
+	requests := make(chan request, 3)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests <- request{path: r.URL.Path, header: r.Header, body: body}
+	}))
+	defer server.Close()
+
+	client := utils.NewHTTPClient(utils.DefaultHTTPClientOptions())
+	event := NewEvent(EventFailed, "/media/b.mp4", "/media", "连续 3 次处理失败")
+	ctx := context.Background()
+
+	require.NoError(t, (&webhookNotifier{client: client, url: server.URL + "/hook", secret: "s"}).Notify(ctx, event))
+	got := <-requests
+	assert.Equal(t, EventFailed, got.header.Get(audio.WebhookEventHeader))
+	var payload Event
+	require.NoError(t, json.Unmarshal(got.body, &payload))
+	assert.Equal(t, "b.mp4", payload.Name)
+	timestamp, err := strconv.ParseInt(got.header.Get(audio.WebhookTimestampHeader), 10, 64)
+	require.NoError(t, err)
+	assert.Equal(t, audio.SignWebhook("s", timestamp, got.body), got.header.Get(audio.WebhookSignatureHeader))
+
+	require.NoError(t, (&telegramNotifier{client: client, apiBase: server.URL, token: "T", chat: "42"}).Notify(ctx, event))
+	got = <-requests
+	assert.Equal(t, "/botT/sendMessage", got.path)
+	form, err := url.ParseQuery(string(got.body))
+	require.NoError(t, err)
+	assert.Equal(t, "42", form.Get("chat_id"))
+	assert.Equal(t, "转写失败: b.mp4\n连续 3 次处理失败", form.Get("text"))
+
+	require.NoError(t, (&barkNotifier{client: client, url: server.URL + "/key"}).Notify(ctx, event))
+	got = <-requests
+	var bark map[string]string
+	require.NoError(t, json.Unmarshal(got.body, &bark))
+	assert.Equal(t, "转写失败: b.mp4", bark["title"])
+	assert.Equal(t, "timeSensitive", bark["level"])
+
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	})
+	assert.Error(t, (&barkNotifier{client: client, url: server.URL + "/key"}).Notify(ctx, event))
+}
@@ -0,0 +1,123 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/audio"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/utils"
+)
+
+// telegramAPI Telegram Bot API 地址
+const telegramAPI = "https://api.telegram.org"
+
+// webhookNotifier 向 notify_webhook_url POST 事件 JSON，请求头与 Web 任务回调相同（见 audio.SignWebhook）
+type webhookNotifier struct {
+	client *utils.HTTPClient
+	url    string
+	secret string
+}
+
+func (n *webhookNotifier) Name() string { return "webhook" }
+
+func (n *webhookNotifier) Notify(ctx context.Context, event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("编码通知内容失败: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("创建请求失败: %w", err)
+	}
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("User-Agent", "asr-media-cli-webhook")
+	req.Header.Set(audio.WebhookEventHeader, event.Event)
+	req.Header.Set(audio.WebhookTimestampHeader, strconv.FormatInt(timestamp, 10))
+	if n.secret != "" {
+		req.Header.Set(audio.WebhookSignatureHeader, audio.SignWebhook(n.secret, timestamp, body))
+	}
+	return send(n.client, req)
+}
+
+// telegramNotifier 通过 Telegram 机器人的 sendMessage 接口发送消息
+type telegramNotifier struct {
+	client  *utils.HTTPClient
+	apiBase string
+	token   string
+	chat    string
+}
+
+func (n *telegramNotifier) Name() string { return "Telegram" }
+
+func (n *telegramNotifier) Notify(ctx context.Context, event Event) error {
+	form := url.Values{
+		"chat_id": {n.chat},
+		"text":    {event.Title() + "\n" + event.Body()},
+	}
+	endpoint := fmt.Sprintf("%s/bot%s/sendMessage", n.apiBase, n.token)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		// 错误信息中的地址包含令牌
+		return fmt.Errorf("创建请求失败")
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return send(n.client, req)
+}
+
+// barkNotifier 向 Bark 推送地址 POST 标题与正文，失败事件使用 timeSensitive 级别
+type barkNotifier struct {
+	client *utils.HTTPClient
+	url    string
+}
+
+func (n *barkNotifier) Name() string { return "Bark" }
+
+func (n *barkNotifier) Notify(ctx context.Context, event Event) error {
+	payload := map[string]string{
+		"title": event.Title(),
+		"body":  event.Body(),
+		"group": "audioproc",
+	}
+	if event.Failed() {
+		payload["level"] = "timeSensitive"
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("编码通知内容失败: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("创建请求失败: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	return send(n.client, req)
+}
+
+// send 发送请求，返回非 2xx 状态码时视为失败
+func send(client *utils.HTTPClient, req *http.Request) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		// 去掉可能包含令牌的请求地址
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			return urlErr.Err
+		}
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("返回状态码 %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,283 @@
+// Package retention 按配置的保留策略清理上传、临时文件、输出与识别结果缓存：
+// 删除修改时间超过保留时长的文件，目录总大小超过上限时从最旧的文件开始删除。
+// 输出目录按输出清单整体清理（清单中位于输出目录的文件与清单本身），不会只删除一个文件的部分输出；
+// 媒体目录中的输出（如与视频放在一起的字幕）不清理。文件通过回收站删除
+package retention
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/export"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/models"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/trash"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/utils"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/webauth"
+)
+
+// 目录类别，对应配置 retention 的键
+const (
+	ClassUploads = "uploads" // Web上传目录
+	ClassTemp    = "temp"    // 临时目录
+	ClassOutputs = "outputs" // 输出目录中的识别结果
+	ClassCache   = "cache"   // 识别结果缓存
+)
+
+// Classes 全部目录类别，按清理顺序排列
+var Classes = []string{ClassUploads, ClassTemp, ClassOutputs, ClassCache}
+
+// Policy 保留策略，零值表示不清理
+type Policy struct {
+	MaxAge   time.Duration // 修改时间早于该时长的文件被清理，0 表示不限制
+	MaxBytes int64         // 目录总大小上限，0 表示不限制
+}
+
+// Enabled 是否需要清理
+func (p Policy) Enabled() bool {
+	return p.MaxAge > 0 || p.MaxBytes > 0
+}
+
+// String 返回便于阅读的描述
+func (p Policy) String() string {
+	switch {
+	case p.MaxAge > 0 && p.MaxBytes > 0:
+		return fmt.Sprintf("保留 %s，上限 %.1f MB", p.MaxAge, float64(p.MaxBytes)/(1024*1024))
+	case p.MaxAge > 0:
+		return fmt.Sprintf("保留 %s", p.MaxAge)
+	case p.MaxBytes > 0:
+		return fmt.Sprintf("上限 %.1f MB", float64(p.MaxBytes)/(1024*1024))
+	default:
+		return "不清理"
+	}
+}
+
+// PolicyFromConfig 返回配置中一类目录的保留策略
+func PolicyFromConfig(config *models.Config, class string) Policy {
+	policy := config.Retention[class]
+	return Policy{
+		MaxAge:   time.Duration(policy.MaxAgeHours * float64(time.Hour)),
+		MaxBytes: int64(policy.MaxSizeMB) * 1024 * 1024,
+	}
+}
+
+// Dirs 各类目录，为空的类别不清理
+type Dirs struct {
+	Uploads string
+	Temp    string
+	Outputs string
+	Cache   string
+}
+
+// dir 返回类别对应的目录
+func (d Dirs) dir(class string) string {
+	switch class {
+	case ClassUploads:
+		return d.Uploads
+	case ClassTemp:
+		return d.Temp
+	case ClassOutputs:
+		return d.Outputs
+	case ClassCache:
+		return d.Cache
+	}
+	return ""
+}
+
+// Report 一个目录的清理结果
+type Report struct {
+	Class     string `json:"class"`
+	Dir       string `json:"dir"`
+	Policy    string `json:"policy"`
+	Items     int    `json:"items"`     // 清理前的文件数（输出目录为清单数）
+	Removed   int    `json:"removed"`   // 清理的文件数（输出目录为清单数）
+	Freed     int64  `json:"freed"`     // 释放的字节数
+	Remaining int64  `json:"remaining"` // 清理后的总大小
+	Errors    int    `json:"errors"`    // 删除失败的文件数
+}
+
+// Cleaner 按保留策略清理目录
+type Cleaner struct {
+	Trash  *trash.Trash     // 删除文件时使用的回收站，nil 时直接删除
+	DryRun bool             // 只统计将要清理的文件，不删除
+	Now    func() time.Time // 当前时间，nil 时使用 time.Now
+}
+
+// item 一起清理的一组文件：普通目录中为一个文件，输出目录中为一个清单及其输出
+type item struct {
+	name    string
+	paths   []string
+	dir     string // 清理后需要删除的空目录
+	size    int64
+	modTime time.Time
+}
+
+// Run 按配置清理 dirs 中的各类目录。启用认证时各用户的目录（<目录>/users/<用户名>）分别按同一策略清理
+func (c *Cleaner) Run(config *models.Config, dirs Dirs) ([]Report, error) {
+	var reports []Report
+	for _, class := range Classes {
+		policy := PolicyFromConfig(config, class)
+		base := dirs.dir(class)
+		if base == "" || !policy.Enabled() {
+			continue
+		}
+		targets := []string{base}
+		if class != ClassCache {
+			targets = append(targets, userDirs(base)...)
+		}
+		for _, dir := range targets {
+			report, err := c.Clean(class, dir, policy)
+			if err != nil {
+				return reports, err
+			}
+			reports = append(reports, report)
+		}
+	}
+	return reports, nil
+}
+
+// Clean 按策略清理一个目录
+func (c *Cleaner) Clean(class, dir string, policy Policy) (Report, error) {
+	report := Report{Class: class, Dir: dir, Policy: policy.String()}
+	var items []item
+	var err error
+	if class == ClassOutputs {
+		items, err = outputItems(dir)
+	} else {
+		items, err = fileItems(dir)
+	}
+	if err != nil {
+		return report, err
+	}
+
+	// 从旧到新排列，超出大小上限时先清理最旧的
+	sort.Slice(items, func(i, j int) bool { return items[i].modTime.Before(items[j].modTime) })
+	var total int64
+	for _, it := range items {
+		total += it.size
+	}
+	report.Items = len(items)
+
+	now := time.Now()
+	if c.Now != nil {
+		now = c.Now()
+	}
+	for _, it := range items {
+		reason := ""
+		switch {
+		case policy.MaxAge > 0 && now.Sub(it.modTime) > policy.MaxAge:
+			reason = fmt.Sprintf("超过保留时间 %s", policy.MaxAge)
+		case policy.MaxBytes > 0 && total > policy.MaxBytes:
+			reason = fmt.Sprintf("目录超过大小上限 %.1f MB", float64(policy.MaxBytes)/(1024*1024))
+		default:
+			continue
+		}
+		if !c.DryRun && !c.remove(it, reason, &report) {
+			continue
+		}
+		report.Removed++
+		report.Freed += it.size
+		total -= it.size
+	}
+	report.Remaining = total
+	return report, nil
+}
+
+// remove 删除一组文件，全部删除成功时返回 true
+func (c *Cleaner) remove(it item, reason string, report *Report) bool {
+	ok := true
+	for _, path := range it.paths {
+		if _, err := c.Trash.Remove(path, "cleanup", reason); err != nil && !os.IsNotExist(err) {
+			utils.Warn("清理文件失败 %s: %v", path, err)
+			report.Errors++
+			ok = false
+		}
+	}
+	if !ok {
+		return false
+	}
+	if it.dir != "" {
+		os.Remove(it.dir) // 清单目录中还有其他文件时保留
+	}
+	utils.Info("已清理 %s（%s）", it.name, reason)
+	return true
+}
+
+// fileItems 列出目录中的文件（不含子目录）
+func fileItems(dir string) ([]item, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取目录失败: %w", err)
+	}
+	var items []item
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		items = append(items, item{name: path, paths: []string{path}, size: info.Size(), modTime: info.ModTime()})
+	}
+	return items, nil
+}
+
+// outputItems 按输出清单列出输出目录中的识别结果，时间取清单的修改时间。
+// 多个清单引用同一文件（如重复文件的链接）时，该文件只计入第一个清单
+func outputItems(dir string) ([]item, error) {
+	manifests, err := export.ListManifests(dir)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	var items []item
+	for _, manifest := range manifests {
+		manifestPath := filepath.Join(dir, manifest.Name, export.ManifestFileName)
+		info, err := os.Stat(manifestPath)
+		if err != nil {
+			continue
+		}
+		it := item{name: manifest.Name, dir: filepath.Dir(manifestPath), size: info.Size(), modTime: info.ModTime()}
+		for _, entry := range manifest.Outputs {
+			if entry.Root != export.RootOutput {
+				continue
+			}
+			path := entry.Resolve(dir, "")
+			if seen[path] {
+				continue
+			}
+			seen[path] = true
+			if info, err := os.Lstat(path); err == nil && !info.IsDir() {
+				it.paths = append(it.paths, path)
+				it.size += info.Size()
+			}
+		}
+		// 清单最后删除，中途失败时下次仍能找到剩余的输出
+		it.paths = append(it.paths, manifestPath)
+		items = append(items, it)
+	}
+	return items, nil
+}
+
+// userDirs 返回 base 下各用户的目录
+func userDirs(base string) []string {
+	root := filepath.Join(base, webauth.UsersDirName)
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil
+	}
+	var dirs []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			dirs = append(dirs, filepath.Join(root, entry.Name()))
+		}
+	}
+	return dirs
+}
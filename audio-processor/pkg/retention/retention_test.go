@@ -0,0 +1,106 @@
+package retention
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/export"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testNow = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+// writeFile 写入 size 字节的文件并将修改时间设为 testNow 之前 age
+func writeFile(t *testing.T, path string, size int, age time.Duration) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(strings.Repeat("a", size)), 0644))
+	mtime := testNow.Add(-age)
+	require.NoError(t, os.Chtimes(path, mtime, mtime))
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// TestCleanFiles 测试先按保留时间清理，再从最旧的文件开始清理到大小上限以内
+func TestCleanFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "expired.mp3"), 100, 48*time.Hour)
+	writeFile(t, filepath.Join(dir, "old.mp3"), 300, 10*time.Hour)
+	writeFile(t, filepath.Join(dir, "new.mp3"), 300, time.Hour)
+	writeFile(t, filepath.Join(dir, "sub", "keep.mp3"), 1000, 72*time.Hour)
+
+	cleaner := &Cleaner{Now: func() time.Time { return testNow }, DryRun: true}
+	policy := Policy{MaxAge: 24 * time.Hour, MaxBytes: 400}
+	report, err := cleaner.Clean(ClassUploads, dir, policy)
+	require.NoError(t, err)
+	assert.Equal(t, Report{Class: ClassUploads, Dir: dir, Policy: policy.String(), Items: 3, Removed: 2, Freed: 400, Remaining: 300}, report)
+	assert.True(t, exists(filepath.Join(dir, "expired.mp3")), "dry-run 不删除文件")
+
+	cleaner.DryRun = false
+	report, err = cleaner.Clean(ClassUploads, dir, policy)
+	require.NoError(t, err)
+	assert.Equal(t, 2, report.Removed)
+	assert.False(t, exists(filepath.Join(dir, "expired.mp3")))
+	assert.False(t, exists(filepath.Join(dir, "old.mp3")))
+	assert.True(t, exists(filepath.Join(dir, "new.mp3")))
+	assert.True(t, exists(filepath.Join(dir, "sub", "keep.mp3")), "只清理目录下的文件")
+}
+
+// TestCleanOutputs 测试按清单整体清理输出，媒体目录中的输出保留
+func TestCleanOutputs(t *testing.T) {
+	root := t.TempDir()
+	config := models.NewDefaultConfig()
+	config.OutputFolder = filepath.Join(root, "output")
+	config.MediaFolder = filepath.Join(root, "media")
+
+	write := func(name string, age time.Duration) {
+		txt := filepath.Join(config.OutputFolder, name+".txt")
+		srt := filepath.Join(config.MediaFolder, name+".srt")
+		writeFile(t, txt, 100, age)
+		writeFile(t, srt, 100, age)
+		manifestPath, err := export.WriteManifest(config, filepath.Join(config.MediaFolder, name+".mp4"), map[string]string{"txt": txt, "srt": srt})
+		require.NoError(t, err)
+		mtime := testNow.Add(-age)
+		require.NoError(t, os.Chtimes(manifestPath, mtime, mtime))
+	}
+	write("old.talk", 30*24*time.Hour)
+	write("new", time.Hour)
+
+	config.Retention = map[string]models.RetentionPolicy{ClassOutputs: {MaxAgeHours: 7 * 24}}
+	cleaner := &Cleaner{Now: func() time.Time { return testNow }}
+	reports, err := cleaner.Run(config, Dirs{Outputs: config.OutputFolder, Uploads: filepath.Join(root, "uploads")})
+	require.NoError(t, err)
+	require.Len(t, reports, 1, "未配置策略的目录不清理")
+	assert.Equal(t, 2, reports[0].Items)
+	assert.Equal(t, 1, reports[0].Removed)
+
+	assert.False(t, exists(filepath.Join(config.OutputFolder, "old.talk.txt")))
+	assert.False(t, exists(filepath.Join(config.OutputFolder, "old.talk")), "清单目录一起删除")
+	assert.True(t, exists(filepath.Join(config.MediaFolder, "old.talk.srt")))
+	assert.True(t, exists(filepath.Join(config.OutputFolder, "new.txt")))
+	assert.True(t, exists(export.ManifestPath(config.OutputFolder, "new.mp4")))
+}
+
+// TestRunUserDirs 测试各用户的目录按同一策略清理
+func TestRunUserDirs(t *testing.T) {
+	uploads := t.TempDir()
+	writeFile(t, filepath.Join(uploads, "a.mp3"), 10, 48*time.Hour)
+	writeFile(t, filepath.Join(uploads, "users", "alice", "b.mp3"), 10, 48*time.Hour)
+
+	config := models.NewDefaultConfig()
+	cleaner := &Cleaner{Now: func() time.Time { return testNow }}
+	reports, err := cleaner.Run(config, Dirs{Uploads: uploads})
+	require.NoError(t, err)
+	require.Len(t, reports, 2)
+	assert.Equal(t, filepath.Join(uploads, "users", "alice"), reports[1].Dir)
+	assert.False(t, exists(filepath.Join(uploads, "a.mp3")))
+	assert.False(t, exists(filepath.Join(uploads, "users", "alice", "b.mp3")))
+}
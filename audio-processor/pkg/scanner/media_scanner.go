@@ -30,8 +30,8 @@ type MediaScanner struct {
 // NewMediaScanner 创建新的媒体扫描器
 func NewMediaScanner() *MediaScanner {
 	return &MediaScanner{
-		AudioExtensions: []string{".mp3", ".wav", ".m4a", ".flac", ".ogg", ".aac"},
-		VideoExtensions: []string{".flv",".mp4", ".mov", ".avi", ".mkv", ".wmv"},
+		AudioExtensions: []string{".mp3", ".wav", ".m4a", ".flac", ".ogg", ".opus", ".aac"},
+		VideoExtensions: []string{".flv",".mp4", ".mov", ".avi", ".mkv", ".wmv", ".webm", ".ts", ".m3u8"},
 	}
 }
 
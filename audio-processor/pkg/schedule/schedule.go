@@ -0,0 +1,207 @@
+// Package schedule 解析监听模式允许处理的时间窗口（配置 watch_schedule），
+// 如 "22:00-07:00"（每天夜间）、"Mon-Fri 19:00-23:30"、"Sat,Sun"（周末全天）
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 一天的分钟数
+const minutesPerDay = 24 * 60
+
+// dayNames 星期的英文缩写，按 time.Weekday 排列
+var dayNames = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+
+// window 一个时间窗口。end 不大于 start 时窗口跨过午夜，在 days 中某天开始、次日结束
+type window struct {
+	spec  string
+	days  [7]bool // 窗口开始的星期，按 time.Weekday 索引
+	start int     // 开始时间，从 0 点起的分钟数
+	end   int     // 结束时间（不含），24:00 为 1440
+}
+
+// Schedule 若干时间窗口，落在任一窗口内即允许处理。nil 表示不限制时间
+type Schedule struct {
+	windows []window
+}
+
+// Parse 解析时间窗口列表，列表为空时返回 nil（不限制）。
+// 每项为 "[星期] [HH:MM-HH:MM]"：星期用逗号分隔，支持 Mon-Fri 这样的范围、0-7 的数字（0 与 7 为周日）与 *；
+// 省略星期表示每天，省略时间表示全天。结束时间早于开始时间的窗口跨过午夜，如 "Fri 22:00-06:00" 持续到周六早上
+func Parse(specs []string) (*Schedule, error) {
+	if len(specs) == 0 {
+		return nil, nil
+	}
+	schedule := &Schedule{}
+	for _, spec := range specs {
+		w, err := parseWindow(spec)
+		if err != nil {
+			return nil, fmt.Errorf("时间窗口 %q 无效: %w", spec, err)
+		}
+		schedule.windows = append(schedule.windows, w)
+	}
+	return schedule, nil
+}
+
+// parseWindow 解析一个时间窗口
+func parseWindow(spec string) (window, error) {
+	w := window{spec: strings.TrimSpace(spec), end: minutesPerDay}
+	fields := strings.Fields(spec)
+	var days, hours string
+	switch {
+	case len(fields) == 1 && strings.Contains(fields[0], ":"):
+		hours = fields[0]
+	case len(fields) == 1:
+		days = fields[0]
+	case len(fields) == 2:
+		days, hours = fields[0], fields[1]
+	default:
+		return w, fmt.Errorf("格式应为 \"[星期] [HH:MM-HH:MM]\"")
+	}
+
+	if days == "" || days == "*" {
+		for i := range w.days {
+			w.days[i] = true
+		}
+	} else {
+		for _, item := range strings.Split(days, ",") {
+			first, last, isRange := strings.Cut(item, "-")
+			from, err := parseDay(first)
+			if err != nil {
+				return w, err
+			}
+			to := from
+			if isRange {
+				if to, err = parseDay(last); err != nil {
+					return w, err
+				}
+			}
+			// 允许 Fri-Mon 这样跨过周日的范围
+			for day := from; ; day = (day + 1) % 7 {
+				w.days[day] = true
+				if day == to {
+					break
+				}
+			}
+		}
+	}
+
+	if hours != "" {
+		start, end, ok := strings.Cut(hours, "-")
+		if !ok {
+			return w, fmt.Errorf("时间应为 HH:MM-HH:MM")
+		}
+		var err error
+		if w.start, err = parseClock(start); err != nil {
+			return w, err
+		}
+		if w.end, err = parseClock(end); err != nil {
+			return w, err
+		}
+		if w.start == w.end {
+			return w, fmt.Errorf("开始与结束时间相同")
+		}
+		if w.start == minutesPerDay {
+			return w, fmt.Errorf("开始时间不能为 24:00")
+		}
+	}
+	return w, nil
+}
+
+// parseDay 解析星期的英文名称（取前三个字母）或 0-7 的数字
+func parseDay(value string) (int, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if n, err := strconv.Atoi(value); err == nil {
+		if n < 0 || n > 7 {
+			return 0, fmt.Errorf("星期 %d 超出 0-7 的范围", n)
+		}
+		return n % 7, nil
+	}
+	if len(value) >= 3 {
+		for i, name := range dayNames {
+			if value[:3] == name {
+				return i, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("无法识别的星期: %q", value)
+}
+
+// parseClock 解析 HH:MM，返回从 0 点起的分钟数，允许 24:00
+func parseClock(value string) (int, error) {
+	hour, minute, ok := strings.Cut(strings.TrimSpace(value), ":")
+	h, herr := strconv.Atoi(hour)
+	m, merr := strconv.Atoi(minute)
+	if !ok || herr != nil || merr != nil || h < 0 || h > 24 || m < 0 || m > 59 || (h == 24 && m != 0) {
+		return 0, fmt.Errorf("无法识别的时间: %q", value)
+	}
+	return h*60 + m, nil
+}
+
+// Active 判断 t 是否落在任一时间窗口内，nil 总是返回 true
+func (s *Schedule) Active(t time.Time) bool {
+	if s == nil {
+		return true
+	}
+	minute := t.Hour()*60 + t.Minute()
+	today := int(t.Weekday())
+	yesterday := (today + 6) % 7
+	for _, w := range s.windows {
+		if w.end > w.start {
+			if w.days[today] && minute >= w.start && minute < w.end {
+				return true
+			}
+			continue
+		}
+		// 跨过午夜：今天开始的部分，或昨天开始、延续到今天的部分
+		if (w.days[today] && minute >= w.start) || (w.days[yesterday] && minute < w.end) {
+			return true
+		}
+	}
+	return false
+}
+
+// Next 返回 t 之后最近一个时间窗口的开始时间，nil 时返回零值
+func (s *Schedule) Next(t time.Time) time.Time {
+	var next time.Time
+	if s == nil {
+		return next
+	}
+	year, month, day := t.Date()
+	for offset := 0; offset <= 7; offset++ {
+		weekday := (int(t.Weekday()) + offset) % 7
+		for _, w := range s.windows {
+			if !w.days[weekday] {
+				continue
+			}
+			start := time.Date(year, month, day+offset, w.start/60, w.start%60, 0, 0, t.Location())
+			if start.After(t) && (next.IsZero() || start.Before(next)) {
+				next = start
+			}
+		}
+	}
+	return next
+}
+
+// Specs 返回配置中的时间窗口
+func (s *Schedule) Specs() []string {
+	if s == nil {
+		return nil
+	}
+	specs := make([]string, len(s.windows))
+	for i, w := range s.windows {
+		specs[i] = w.spec
+	}
+	return specs
+}
+
+// String 以逗号分隔的时间窗口，nil 时为 "不限"
+func (s *Schedule) String() string {
+	if s == nil {
+		return "不限"
+	}
+	return strings.Join(s.Specs(), ", ")
+}
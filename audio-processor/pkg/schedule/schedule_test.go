@@ -0,0 +1,66 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// at 返回 2024-06-03（周一）起第 day 天的 hh:mm
+func at(day, hour, minute int) time.Time {
+	return time.Date(2024, 6, 3+day, hour, minute, 0, 0, time.Local)
+}
+
+func TestParse(t *testing.T) {
+	s, err := Parse(nil)
+	require.NoError(t, err)
+	assert.Nil(t, s)
+	assert.True(t, s.Active(at(0, 12, 0)), "未配置时不限制")
+	assert.True(t, s.Next(at(0, 12, 0)).IsZero())
+
+	for _, spec := range []string{"", "22:00", "25:00-01:00", "10:00-10:00", "24:00-06:00", "Mon-Funday 10:00-11:00", "8 10:00-11:00", "Mon 10:00-11:00 extra"} {
+		_, err := Parse([]string{spec})
+		assert.Error(t, err, spec)
+	}
+
+	s, err = Parse([]string{" Mon-Fri 22:00-06:00", "sat,0"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Mon-Fri 22:00-06:00", "sat,0"}, s.Specs())
+	assert.Equal(t, "Mon-Fri 22:00-06:00, sat,0", s.String())
+}
+
+func TestActiveAndNext(t *testing.T) {
+	s, err := Parse([]string{"Mon-Fri 22:00-06:00", "Sat,Sun"})
+	require.NoError(t, err)
+
+	cases := []struct {
+		time   time.Time
+		active bool
+		next   time.Time
+	}{
+		{at(0, 12, 0), false, at(0, 22, 0)}, // 周一中午
+		{at(0, 22, 0), true, time.Time{}},   // 周一夜间开始
+		{at(1, 5, 59), true, time.Time{}},   // 跨过午夜延续到周二早上
+		{at(1, 6, 0), false, at(1, 22, 0)},  // 窗口结束（不含）
+		{at(0, 3, 0), false, at(0, 22, 0)},  // 周一凌晨：周日没有夜间窗口
+		{at(4, 23, 0), true, time.Time{}},   // 周五夜间
+		{at(5, 12, 0), true, time.Time{}},   // 周六全天
+		{at(7, 3, 0), false, at(7, 22, 0)},  // 下周一凌晨：周日全天窗口在 24:00 结束
+	}
+
+	for _, c := range cases {
+		assert.Equal(t, c.active, s.Active(c.time), c.time.String())
+		if !c.active {
+			assert.Equal(t, c.next, s.Next(c.time), c.time.String())
+		}
+	}
+
+	// 跨过周日的星期范围
+	s, err = Parse([]string{"Fri-Mon 09:00-10:00"})
+	require.NoError(t, err)
+	assert.True(t, s.Active(at(6, 9, 30)))
+	assert.False(t, s.Active(at(1, 9, 30)))
+	assert.Equal(t, at(4, 9, 0), s.Next(at(1, 9, 30)))
+}
@@ -0,0 +1,60 @@
+package search
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// 未指定时每次返回的文件数与每个文件的段落数
+const (
+	defaultLimit   = 20
+	defaultPerFile = 5
+)
+
+// Handler 提供搜索接口：GET ?q=查询词[&limit=文件数][&per_file=段落数]。
+// 每次请求前按输出清单增量更新索引
+func Handler(outputFolder, mediaFolder string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "只支持GET请求", http.StatusMethodNotAllowed)
+			return
+		}
+		query := strings.TrimSpace(r.URL.Query().Get("q"))
+		if query == "" {
+			http.Error(w, "缺少查询参数 q", http.StatusBadRequest)
+			return
+		}
+		opts := Options{
+			Limit:   queryInt(r, "limit", defaultLimit),
+			PerFile: queryInt(r, "per_file", defaultPerFile),
+		}
+
+		index, err := Build(outputFolder, mediaFolder)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		results := index.Search(query, opts)
+		if results == nil {
+			results = []FileResult{}
+		}
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		encoder.Encode(map[string]interface{}{
+			"query":   query,
+			"results": results,
+		})
+	})
+}
+
+// queryInt 读取非负整数参数，缺省或无效时返回 fallback
+func queryInt(r *http.Request, name string, fallback int) int {
+	value, err := strconv.Atoi(r.URL.Query().Get(name))
+	if err != nil || value < 0 {
+		return fallback
+	}
+	return value
+}
@@ -0,0 +1,464 @@
+// Package search 在输出目录的全部文稿上建立全文索引，按关键词查找文件、段落与时间点。
+// 索引以输出清单为准增量更新：文稿内容（校验和）未变的文件不重新读取。
+// 英文按单词、中文按单字与相邻两字建立倒排表，命中后再按原文核对，避免两字组合的误命中
+package search
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/export"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/utils"
+)
+
+// IndexFileName 索引文件名，位于输出目录
+const IndexFileName = "search_index.gob"
+
+// indexVersion 索引文件的格式版本，不一致时重建
+const indexVersion = 1
+
+// 按优先级选择建立索引的文稿：JSON 与 SRT 带有时间戳，文本文件没有
+var sourceTypes = []string{"json", "srt", "txt"}
+
+// buildMu 串行化索引的更新与保存
+var buildMu sync.Mutex
+
+// Segment 文稿中的一段，没有时间戳时 Start 为 -1
+type Segment struct {
+	Start   float64
+	End     float64
+	Text    string
+	Speaker string
+}
+
+// Document 一个文件的文稿
+type Document struct {
+	Name     string // 文件名（不含扩展名），与输出清单一致
+	Type     string // 建立索引使用的输出格式
+	SHA256   string // 该输出的校验和，用于判断是否需要重新读取
+	Segments []Segment
+}
+
+// posting 倒排表中的一项：文件与段落序号
+type posting struct {
+	doc string
+	seg int
+}
+
+// Index 全文索引
+type Index struct {
+	Version  int
+	Docs     map[string]*Document
+	postings map[string][]posting
+}
+
+// IndexPath 返回索引文件的路径
+func IndexPath(outputFolder string) string {
+	return filepath.Join(outputFolder, IndexFileName)
+}
+
+// Open 读取索引，不存在或格式版本不一致时返回空索引
+func Open(outputFolder string) (*Index, error) {
+	index := &Index{Version: indexVersion, Docs: make(map[string]*Document)}
+	data, err := os.ReadFile(IndexPath(outputFolder))
+	if os.IsNotExist(err) {
+		index.rebuild()
+		return index, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取搜索索引失败: %w", err)
+	}
+	var stored Index
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&stored); err != nil || stored.Version != indexVersion {
+		utils.Warn("搜索索引无法读取或版本不一致，将重建")
+		index.rebuild()
+		return index, nil
+	}
+	if stored.Docs == nil {
+		stored.Docs = make(map[string]*Document)
+	}
+	stored.rebuild()
+	return &stored, nil
+}
+
+// Build 按输出清单更新索引并保存，返回更新后的索引
+func Build(outputFolder, mediaFolder string) (*Index, error) {
+	buildMu.Lock()
+	defer buildMu.Unlock()
+	index, err := Open(outputFolder)
+	if err != nil {
+		return nil, err
+	}
+	changed, err := index.Update(outputFolder, mediaFolder)
+	if err != nil {
+		return nil, err
+	}
+	if changed {
+		if err := index.Save(outputFolder); err != nil {
+			return nil, err
+		}
+	}
+	return index, nil
+}
+
+// Update 按输出清单同步索引：加入新文件，重新读取内容变化的文件，移除已删除的文件。返回索引是否变化
+func (index *Index) Update(outputFolder, mediaFolder string) (bool, error) {
+	manifests, err := export.ListManifests(outputFolder)
+	if err != nil {
+		return false, err
+	}
+	changed := false
+	seen := make(map[string]bool)
+	for _, manifest := range manifests {
+		entry, ok := transcriptEntry(manifest)
+		if !ok {
+			continue
+		}
+		seen[manifest.Name] = true
+		if doc := index.Docs[manifest.Name]; doc != nil && doc.Type == entry.Type && doc.SHA256 == entry.SHA256 && entry.SHA256 != "" {
+			continue
+		}
+		segments, err := loadSegments(entry.Type, entry.Resolve(outputFolder, mediaFolder))
+		if err != nil {
+			utils.Warn("读取 %s 的文稿失败: %v", manifest.Name, err)
+			continue
+		}
+		index.Docs[manifest.Name] = &Document{Name: manifest.Name, Type: entry.Type, SHA256: entry.SHA256, Segments: segments}
+		changed = true
+	}
+	for name := range index.Docs {
+		if !seen[name] {
+			delete(index.Docs, name)
+			changed = true
+		}
+	}
+	if changed {
+		index.rebuild()
+	}
+	return changed, nil
+}
+
+// Save 写入临时文件后替换索引文件
+func (index *Index) Save(outputFolder string) error {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(index); err != nil {
+		return fmt.Errorf("编码搜索索引失败: %w", err)
+	}
+	path := IndexPath(outputFolder)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("写入搜索索引失败: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("写入搜索索引失败: %w", err)
+	}
+	return nil
+}
+
+// transcriptEntry 选择清单中用于建立索引的输出
+func transcriptEntry(manifest *export.OutputManifest) (export.OutputEntry, bool) {
+	for _, fileType := range sourceTypes {
+		if entry, ok := manifest.Output(fileType); ok {
+			return entry, true
+		}
+	}
+	return export.OutputEntry{}, false
+}
+
+// LoadSegments 读取清单中的文稿段落，按 JSON、SRT、文本的优先级选择。清单中没有文稿时返回 false
+func LoadSegments(manifest *export.OutputManifest, outputFolder, mediaFolder string) ([]Segment, bool, error) {
+	entry, ok := transcriptEntry(manifest)
+	if !ok {
+		return nil, false, nil
+	}
+	segments, err := loadSegments(entry.Type, entry.Resolve(outputFolder, mediaFolder))
+	if err != nil {
+		return nil, true, fmt.Errorf("读取文稿失败: %w", err)
+	}
+	return segments, true, nil
+}
+
+// loadSegments 读取文稿
+func loadSegments(fileType, path string) ([]Segment, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	switch fileType {
+	case "json":
+		transcript, err := export.ParseTranscript(data)
+		if err != nil {
+			return nil, err
+		}
+		segments := make([]Segment, 0, len(transcript.Segments))
+		for _, s := range transcript.Segments {
+			segments = append(segments, Segment{Start: s.Start, End: s.End, Text: strings.TrimSpace(s.Text), Speaker: s.Speaker})
+		}
+		return segments, nil
+	case "srt":
+		return parseSRT(data), nil
+	default:
+		return parseText(data), nil
+	}
+}
+
+// parseSRT 解析SRT字幕，兼容 UTF-8 BOM 与 CRLF 换行
+func parseSRT(data []byte) []Segment {
+	text := strings.TrimPrefix(string(data), "\ufeff")
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	var segments []Segment
+	for _, block := range strings.Split(text, "\n\n") {
+		lines := strings.Split(strings.TrimSpace(block), "\n")
+		for i, line := range lines {
+			start, end, ok := parseSRTTiming(line)
+			if !ok {
+				continue
+			}
+			if content := strings.TrimSpace(strings.Join(lines[i+1:], " ")); content != "" {
+				segments = append(segments, Segment{Start: start, End: end, Text: content})
+			}
+			break
+		}
+	}
+	return segments
+}
+
+// parseSRTTiming 解析 "00:01:02,500 --> 00:01:04,000"
+func parseSRTTiming(line string) (float64, float64, bool) {
+	parts := strings.Split(line, "-->")
+	if len(parts) != 2 {
+		return 0, 0, false
+	}
+	start, ok1 := parseSRTTime(parts[0])
+	end, ok2 := parseSRTTime(parts[1])
+	return start, end, ok1 && ok2
+}
+
+// parseSRTTime 解析 "hh:mm:ss,mmm"
+func parseSRTTime(value string) (float64, bool) {
+	value = strings.ReplaceAll(strings.TrimSpace(value), ",", ".")
+	fields := strings.Split(value, ":")
+	if len(fields) != 3 {
+		return 0, false
+	}
+	var total float64
+	for _, field := range fields {
+		n, err := strconv.ParseFloat(field, 64)
+		if err != nil {
+			return 0, false
+		}
+		total = total*60 + n
+	}
+	return total, true
+}
+
+// parseText 解析文本文稿：跳过 # 开头的文件头，每个非空行作为一段
+func parseText(data []byte) []Segment {
+	var segments []Segment
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		segments = append(segments, Segment{Start: -1, End: -1, Text: line})
+	}
+	return segments
+}
+
+// rebuild 由文稿重新生成倒排表
+func (index *Index) rebuild() {
+	index.postings = make(map[string][]posting)
+	names := make([]string, 0, len(index.Docs))
+	for name := range index.Docs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for i, segment := range index.Docs[name].Segments {
+			seen := make(map[string]bool)
+			for _, token := range tokens(segment.Text) {
+				if !seen[token] {
+					seen[token] = true
+					index.postings[token] = append(index.postings[token], posting{doc: name, seg: i})
+				}
+			}
+		}
+	}
+}
+
+// tokens 切分文字：英文与数字按单词（小写），中文取单字与相邻两字
+func tokens(text string) []string {
+	var result []string
+	var word []rune
+	var prevHan rune
+	flush := func() {
+		if len(word) > 0 {
+			result = append(result, strings.ToLower(string(word)))
+			word = word[:0]
+		}
+	}
+	for _, r := range text {
+		switch {
+		case unicode.Is(unicode.Han, r):
+			flush()
+			result = append(result, string(r))
+			if prevHan != 0 {
+				result = append(result, string([]rune{prevHan, r}))
+			}
+			prevHan = r
+			continue
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			word = append(word, r)
+		default:
+			flush()
+		}
+		prevHan = 0
+	}
+	flush()
+	return result
+}
+
+// Tokens 按搜索索引的规则切分文字，供文稿问答的关键词检索使用
+func Tokens(text string) []string {
+	return tokens(text)
+}
+
+// queryTokens 返回查询词用于查找候选段落的词：中文取相邻两字（单字时取单字）
+func queryTokens(term string) []string {
+	var result []string
+	for _, token := range tokens(term) {
+		if len([]rune(token)) == 1 && unicode.Is(unicode.Han, []rune(token)[0]) && len([]rune(term)) > 1 {
+			continue
+		}
+		result = append(result, token)
+	}
+	return result
+}
+
+// Hit 命中的一段
+type Hit struct {
+	Start   float64 `json:"start"` // 开始时间（秒），文稿没有时间戳时为 -1
+	End     float64 `json:"end"`
+	Text    string  `json:"text"`
+	Speaker string  `json:"speaker,omitempty"`
+	Score   float64 `json:"score"`
+}
+
+// FileResult 一个文件的搜索结果
+type FileResult struct {
+	Name  string  `json:"name"`
+	Type  string  `json:"type"`  // 建立索引使用的输出格式
+	Score float64 `json:"score"` // 各段得分之和
+	Total int     `json:"total"` // 命中的段落数
+	Hits  []Hit   `json:"hits"`  // 按时间排序，最多 Options.PerFile 段
+}
+
+// Options 搜索选项
+type Options struct {
+	Limit   int // 最多返回的文件数，0 表示不限
+	PerFile int // 每个文件最多返回的段落数，0 表示不限
+}
+
+// Search 查找包含全部查询词（以空白分隔，不区分大小写）的段落，按文件汇总，得分高的文件在前
+func (index *Index) Search(query string, opts Options) []FileResult {
+	terms := strings.Fields(strings.ToLower(query))
+	if len(terms) == 0 {
+		return nil
+	}
+
+	// 候选段落：每个查询词的全部索引词都命中的段落
+	var candidates map[posting]bool
+	for _, term := range terms {
+		for _, token := range queryTokens(term) {
+			next := make(map[posting]bool)
+			for _, p := range index.postings[token] {
+				if candidates == nil || candidates[p] {
+					next[p] = true
+				}
+			}
+			candidates = next
+		}
+	}
+
+	// 出现在越少文件中的词权重越高
+	n := float64(len(index.Docs))
+	weights := make(map[string]float64, len(terms))
+	for _, term := range terms {
+		weights[term] = math.Log(1 + n/float64(index.docFrequency(term)))
+	}
+	results := make(map[string]*FileResult)
+	for p := range candidates {
+		doc := index.Docs[p.doc]
+		segment := doc.Segments[p.seg]
+		text := strings.ToLower(segment.Text)
+		score := 0.0
+		for _, term := range terms {
+			count := strings.Count(text, term)
+			if count == 0 {
+				score = 0
+				break
+			}
+			score += float64(count) * weights[term]
+		}
+		if score == 0 {
+			continue
+		}
+		result := results[doc.Name]
+		if result == nil {
+			result = &FileResult{Name: doc.Name, Type: doc.Type}
+			results[doc.Name] = result
+		}
+		result.Score += score
+		result.Total++
+		result.Hits = append(result.Hits, Hit{Start: segment.Start, End: segment.End, Text: segment.Text, Speaker: segment.Speaker, Score: score})
+	}
+
+	list := make([]FileResult, 0, len(results))
+	for _, result := range results {
+		if opts.PerFile > 0 && len(result.Hits) > opts.PerFile {
+			// 保留得分最高的段落
+			sort.SliceStable(result.Hits, func(i, j int) bool { return result.Hits[i].Score > result.Hits[j].Score })
+			result.Hits = result.Hits[:opts.PerFile]
+		}
+		sort.SliceStable(result.Hits, func(i, j int) bool { return result.Hits[i].Start < result.Hits[j].Start })
+		list = append(list, *result)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Score != list[j].Score {
+			return list[i].Score > list[j].Score
+		}
+		return list[i].Name < list[j].Name
+	})
+	if opts.Limit > 0 && len(list) > opts.Limit {
+		list = list[:opts.Limit]
+	}
+	return list
+}
+
+// docFrequency 返回包含查询词的文件数（按索引词估计），至少为 1
+func (index *Index) docFrequency(term string) int {
+	docs := make(map[string]bool)
+	for i, token := range queryTokens(term) {
+		current := make(map[string]bool)
+		for _, p := range index.postings[token] {
+			if i == 0 || docs[p.doc] {
+				current[p.doc] = true
+			}
+		}
+		docs = current
+	}
+	return max(len(docs), 1)
+}
@@ -0,0 +1,122 @@
+package search
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/export"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeOutput 写入一个输出文件并生成输出清单
+func writeOutput(t *testing.T, config *models.Config, name, fileType, path, content string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	_, err := export.WriteManifest(config, name+".mp4", map[string]string{fileType: path})
+	require.NoError(t, err)
+}
+
+func testLibrary(t *testing.T) *models.Config {
+	config := models.NewDefaultConfig()
+	config.OutputFolder = t.TempDir()
+	config.MediaFolder = t.TempDir()
+
+	writeOutput(t, config, "lecture", "json", filepath.Join(config.OutputFolder, "lecture.json"),
+		`{"schema_version": 2, "segments": [
+			{"start": 0.5, "end": 3, "text": "欢迎来到语音识别课程", "speaker": "A"},
+			{"start": 65, "end": 70, "text": "Whisper 模型的语音识别效果很好"},
+			{"start": 90, "end": 95, "text": "识别语音之前先降噪"}]}`)
+	writeOutput(t, config, "podcast", "srt", filepath.Join(config.MediaFolder, "podcast.srt"),
+		"\ufeff1\r\n00:00:01,000 --> 00:00:02,500\r\n今天聊聊语音识别\r\n\r\n2\r\n00:01:00,000 --> 00:01:03,000\r\n还有 whisper\r\n的部署\r\n")
+	writeOutput(t, config, "notes", "txt", filepath.Join(config.OutputFolder, "notes.txt"),
+		"# notes\n# 处理时间: 2024-01-01 00:00:00\n\n第一段没有时间戳\n\n第二段提到识别\n")
+	return config
+}
+
+func TestSearch(t *testing.T) {
+	config := testLibrary(t)
+	index, err := Build(config.OutputFolder, config.MediaFolder)
+	require.NoError(t, err)
+	assert.Len(t, index.Docs, 3)
+
+	results := index.Search("语音识别", Options{})
+	require.Len(t, results, 2)
+	// lecture 命中两段，得分更高
+	assert.Equal(t, "lecture", results[0].Name)
+	assert.Equal(t, 2, results[0].Total)
+	assert.Equal(t, []Hit{
+		{Start: 0.5, End: 3, Text: "欢迎来到语音识别课程", Speaker: "A", Score: results[0].Hits[0].Score},
+		{Start: 65, End: 70, Text: "Whisper 模型的语音识别效果很好", Score: results[0].Hits[1].Score},
+	}, results[0].Hits)
+	assert.Equal(t, "podcast", results[1].Name)
+	assert.Equal(t, 1.0, results[1].Hits[0].Start)
+
+	// 两字组合都出现但不相连的段落不算命中（“识别语音”中没有“语音识别”）
+	for _, hit := range results[0].Hits {
+		assert.NotEqual(t, 90.0, hit.Start)
+	}
+
+	// 多个查询词需全部出现，英文不区分大小写
+	results = index.Search("WHISPER 部署", Options{})
+	require.Len(t, results, 1)
+	assert.Equal(t, "podcast", results[0].Name)
+	assert.Equal(t, "还有 whisper 的部署", results[0].Hits[0].Text)
+	assert.Equal(t, 60.0, results[0].Hits[0].Start)
+
+	// 文本文稿没有时间戳
+	results = index.Search("提到", Options{})
+	require.Len(t, results, 1)
+	assert.Equal(t, -1.0, results[0].Hits[0].Start)
+
+	results = index.Search("识别", Options{Limit: 1, PerFile: 1})
+	require.Len(t, results, 1)
+	assert.Len(t, results[0].Hits, 1)
+	assert.Equal(t, 3, results[0].Total)
+
+	assert.Empty(t, index.Search("不存在的内容", Options{}))
+	assert.Empty(t, index.Search("  ", Options{}))
+}
+
+func TestBuildIsIncremental(t *testing.T) {
+	config := testLibrary(t)
+	_, err := Build(config.OutputFolder, config.MediaFolder)
+	require.NoError(t, err)
+
+	// 未变化时直接使用保存的索引
+	index, err := Open(config.OutputFolder)
+	require.NoError(t, err)
+	changed, err := index.Update(config.OutputFolder, config.MediaFolder)
+	require.NoError(t, err)
+	assert.False(t, changed)
+	assert.Len(t, index.Search("降噪", Options{}), 1)
+
+	// 文稿更新后重新读取，删除的文件从索引中移除
+	writeOutput(t, config, "notes", "txt", filepath.Join(config.OutputFolder, "notes.txt"), "改写后的笔记\n")
+	require.NoError(t, os.RemoveAll(filepath.Join(config.OutputFolder, "podcast")))
+	index, err = Build(config.OutputFolder, config.MediaFolder)
+	require.NoError(t, err)
+	assert.Len(t, index.Docs, 2)
+	assert.Empty(t, index.Search("提到", Options{}))
+	assert.Len(t, index.Search("笔记", Options{}), 1)
+	assert.Empty(t, index.Search("部署", Options{}))
+}
+
+func TestHandler(t *testing.T) {
+	config := testLibrary(t)
+	handler := Handler(config.OutputFolder, config.MediaFolder)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/search?q=%E8%AF%AD%E9%9F%B3&limit=1", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"name": "lecture"`)
+	assert.NotContains(t, rec.Body.String(), `"name": "podcast"`)
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/search", nil))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
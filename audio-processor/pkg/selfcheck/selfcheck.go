@@ -0,0 +1,172 @@
+// Package selfcheck 长时间运行（监听模式、Web服务）时的自检：定期记录 goroutine 数与堆内存，
+// 连续多次采样持续增长时报警，并提供 pprof 接口用于定位泄漏
+package selfcheck
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/models"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/utils"
+)
+
+// 保留的历史采样数，供 /debug/selfcheck 查看
+const historySize = 144
+
+// Sample 一次采样
+type Sample struct {
+	Time        time.Time `json:"time"`
+	Goroutines  int       `json:"goroutines"`
+	HeapAlloc   uint64    `json:"heap_alloc"`   // 堆上已分配对象的字节数
+	HeapObjects uint64    `json:"heap_objects"` // 堆上的对象数
+	NumGC       uint32    `json:"num_gc"`
+}
+
+// Take 采样当前进程
+func Take() Sample {
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	return Sample{
+		Time:        time.Now(),
+		Goroutines:  runtime.NumGoroutine(),
+		HeapAlloc:   stats.HeapAlloc,
+		HeapObjects: stats.HeapObjects,
+		NumGC:       stats.NumGC,
+	}
+}
+
+// Monitor 定期采样并检查是否持续增长
+type Monitor struct {
+	Interval time.Duration
+	Window   int // 连续多少次采样持续增长时报警
+
+	mu      sync.Mutex
+	history []Sample
+	alerted map[string]bool // 当前这一轮增长是否已经报警，增长中断后重置
+}
+
+// NewMonitor 创建自检器
+func NewMonitor(interval time.Duration, window int) *Monitor {
+	return &Monitor{
+		Interval: interval,
+		Window:   window,
+		alerted:  make(map[string]bool),
+	}
+}
+
+// FromConfig 按配置创建自检器，未启用定期检查时返回 nil
+func FromConfig(config *models.Config) *Monitor {
+	if config.SelfCheckInterval <= 0 {
+		return nil
+	}
+	return NewMonitor(time.Duration(config.SelfCheckInterval*float64(time.Minute)), config.SelfCheckWindow)
+}
+
+// Run 定期采样，直到 ctx 取消
+func (m *Monitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.Interval)
+	defer ticker.Stop()
+	for {
+		sample := Take()
+		utils.Info("自检: goroutine %d，堆内存 %s（%d 个对象），GC %d 次",
+			sample.Goroutines, utils.FormatFileSize(int64(sample.HeapAlloc)), sample.HeapObjects, sample.NumGC)
+		for _, alert := range m.Record(sample) {
+			utils.Warn("自检: %s，可能存在泄漏，可通过 debug_addr 的 /debug/pprof/ 排查", alert)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Record 记录一次采样，返回新产生的报警：最近 Window 次采样中每次都比上一次多
+func (m *Monitor) Record(sample Sample) []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.history = append(m.history, sample)
+	if len(m.history) > historySize {
+		m.history = m.history[len(m.history)-historySize:]
+	}
+
+	var alerts []string
+	check := func(kind string, value func(Sample) uint64, format func(uint64) string) {
+		if m.Window < 2 || len(m.history) < m.Window {
+			return
+		}
+		recent := m.history[len(m.history)-m.Window:]
+		for i := 1; i < len(recent); i++ {
+			if value(recent[i]) <= value(recent[i-1]) {
+				m.alerted[kind] = false
+				return
+			}
+		}
+		if m.alerted[kind] {
+			return
+		}
+		m.alerted[kind] = true
+		alerts = append(alerts, fmt.Sprintf("%s在最近 %d 次采样中持续增长: %s → %s",
+			kind, m.Window, format(value(recent[0])), format(value(recent[len(recent)-1]))))
+	}
+	check("goroutine 数", func(s Sample) uint64 { return uint64(s.Goroutines) },
+		func(v uint64) string { return fmt.Sprint(v) })
+	check("堆内存", func(s Sample) uint64 { return s.HeapAlloc },
+		func(v uint64) string { return utils.FormatFileSize(int64(v)) })
+	return alerts
+}
+
+// History 返回保留的采样
+func (m *Monitor) History() []Sample {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Sample(nil), m.history...)
+}
+
+// DebugHandler 返回调试接口：/debug/pprof/ 为标准的 pprof，/debug/selfcheck 返回当前与历史采样。
+// monitor 为 nil 时 /debug/selfcheck 只返回当前采样
+func DebugHandler(monitor *Monitor) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.HandleFunc("/debug/selfcheck", func(w http.ResponseWriter, r *http.Request) {
+		response := struct {
+			Current Sample   `json:"current"`
+			History []Sample `json:"history"`
+		}{Current: Take(), History: []Sample{}}
+		if monitor != nil {
+			response.History = monitor.History()
+		}
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		json.NewEncoder(w).Encode(response)
+	})
+	return mux
+}
+
+// StartDebugServer 在 addr 上启动调试接口，返回关闭函数
+func StartDebugServer(addr string, monitor *Monitor) func() {
+	server := &http.Server{
+		Addr:    addr,
+		Handler: DebugHandler(monitor),
+	}
+	go func() {
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			utils.Error("调试接口启动失败: %v", err)
+		}
+	}()
+	utils.Info("调试接口已启动: http://%s/debug/pprof/, /debug/selfcheck", addr)
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(ctx)
+	}
+}
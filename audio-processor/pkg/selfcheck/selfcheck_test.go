@@ -0,0 +1,76 @@
+package selfcheck
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sample(goroutines int, heap uint64) Sample {
+	return Sample{Time: time.Now(), Goroutines: goroutines, HeapAlloc: heap}
+}
+
+func TestRecordAlertsOnMonotonicGrowth(t *testing.T) {
+	m := NewMonitor(time.Minute, 3)
+
+	assert.Empty(t, m.Record(sample(10, 100)))
+	assert.Empty(t, m.Record(sample(12, 100)))
+	alerts := m.Record(sample(15, 100))
+	require.Len(t, alerts, 1)
+	assert.Contains(t, alerts[0], "goroutine 数")
+	assert.Contains(t, alerts[0], "10 → 15")
+
+	// 同一轮增长只报警一次
+	assert.Empty(t, m.Record(sample(20, 100)))
+
+	// 增长中断后重新计数
+	assert.Empty(t, m.Record(sample(20, 100)))
+	assert.Empty(t, m.Record(sample(21, 100)))
+	alerts = m.Record(sample(22, 100))
+	require.Len(t, alerts, 1)
+	assert.Contains(t, alerts[0], "20 → 22")
+}
+
+func TestRecordHeapGrowth(t *testing.T) {
+	m := NewMonitor(time.Minute, 2)
+	assert.Empty(t, m.Record(sample(5, 1024)))
+	alerts := m.Record(sample(5, 4096))
+	require.Len(t, alerts, 1)
+	assert.Contains(t, alerts[0], "堆内存")
+}
+
+func TestRecordKeepsBoundedHistory(t *testing.T) {
+	m := NewMonitor(time.Minute, 3)
+	for i := 0; i < historySize+10; i++ {
+		m.Record(sample(i%2, 0))
+	}
+	assert.Len(t, m.History(), historySize)
+}
+
+func TestDebugHandler(t *testing.T) {
+	m := NewMonitor(time.Minute, 3)
+	m.Record(sample(7, 100))
+	handler := DebugHandler(m)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/selfcheck", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	var response struct {
+		Current Sample   `json:"current"`
+		History []Sample `json:"history"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	assert.Positive(t, response.Current.Goroutines)
+	require.Len(t, response.History, 1)
+	assert.Equal(t, 7, response.History[0].Goroutines)
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/pprof/goroutine?debug=1", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "goroutine profile")
+}
@@ -0,0 +1,387 @@
+package state
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/models"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/store"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/trash"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/utils"
+)
+
+// 状态包的格式版本
+const formatVersion = 1
+
+// 归档内的根目录与清单文件名
+const (
+	manifestName = "manifest.json"
+	configName   = "config.json"
+)
+
+// Item 需要导出的一项状态，Name 为归档内的逻辑名称，Path 为磁盘上的实际位置
+type Item struct {
+	Name string `json:"name"`
+	Path string `json:"original_path"`
+	Dir  bool   `json:"dir,omitempty"`
+
+	// Database 为 SQLite 数据库（WAL 模式）：导出一致的快照，恢复时连同 -wal、-shm 文件一起替换
+	Database bool `json:"database,omitempty"`
+}
+
+// Manifest 状态包清单
+type Manifest struct {
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+	Hostname  string    `json:"hostname,omitempty"`
+	Items     []Item    `json:"items"`
+}
+
+// Locations 状态文件在本机的位置
+type Locations struct {
+	ConfigFile string // 配置文件路径，为空时不导出/恢复配置
+	CacheDir   string // ASR识别缓存目录
+}
+
+// Items 根据配置列出需要导出的状态：配置文件、处理记录（JSON 与数据库）、服务统计、审计日志、使用量统计与识别缓存
+func Items(config *models.Config, loc Locations) []Item {
+	var items []Item
+	if loc.ConfigFile != "" {
+		items = append(items, Item{Name: configName, Path: loc.ConfigFile})
+	}
+	items = append(items,
+		Item{Name: "output/processed_records.json", Path: filepath.Join(config.OutputFolder, "processed_records.json")},
+		Item{Name: "output/processed_records.db", Path: store.Path(config.OutputFolder), Database: true},
+		Item{Name: "output/asr_service_stats.json", Path: filepath.Join(config.OutputFolder, "asr_service_stats.json")},
+		Item{Name: "output/audit.jsonl", Path: config.AuditLogPath()},
+		Item{Name: "output/usage_stats.json", Path: config.UsageStatsPath()},
+	)
+	if loc.CacheDir != "" {
+		items = append(items, Item{Name: "cache", Path: loc.CacheDir, Dir: true})
+	}
+	return items
+}
+
+// Export 将状态打包为 tar.gz，不存在的项会被跳过，返回实际导出的项
+func Export(dest string, items []Item) ([]Item, error) {
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return nil, fmt.Errorf("创建目录失败: %w", err)
+	}
+
+	f, err := os.Create(dest)
+	if err != nil {
+		return nil, fmt.Errorf("创建状态包失败: %w", err)
+	}
+	defer f.Close()
+
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+
+	var exported []Item
+	for _, item := range items {
+		info, err := os.Stat(item.Path)
+		if os.IsNotExist(err) {
+			utils.Debug("状态项不存在，跳过: %s", item.Path)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("读取 %s 失败: %w", item.Path, err)
+		}
+
+		switch {
+		case info.IsDir():
+			err = addDir(tw, item.Name, item.Path)
+		case item.Database:
+			err = addDatabase(tw, item.Name, item.Path)
+		default:
+			err = addFile(tw, item.Name, item.Path, info)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		abs, _ := filepath.Abs(item.Path)
+		exported = append(exported, Item{Name: item.Name, Path: abs, Dir: info.IsDir(), Database: item.Database})
+	}
+
+	hostname, _ := os.Hostname()
+	manifest := Manifest{
+		Version:   formatVersion,
+		CreatedAt: time.Now(),
+		Hostname:  hostname,
+		Items:     exported,
+	}
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("序列化清单失败: %w", err)
+	}
+	if err := writeEntry(tw, manifestName, data); err != nil {
+		return nil, err
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, fmt.Errorf("写入状态包失败: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return nil, fmt.Errorf("写入状态包失败: %w", err)
+	}
+	return exported, nil
+}
+
+// addFile 向归档写入单个文件
+func addFile(tw *tar.Writer, name, filePath string, info os.FileInfo) error {
+	header, err := tar.FileInfoHeader(info, "")
+	if err != nil {
+		return err
+	}
+	header.Name = name
+
+	if err := tw.WriteHeader(header); err != nil {
+		return fmt.Errorf("写入 %s 失败: %w", name, err)
+	}
+
+	src, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	if _, err := io.Copy(tw, src); err != nil {
+		return fmt.Errorf("写入 %s 失败: %w", name, err)
+	}
+	return nil
+}
+
+// addDatabase 向归档写入数据库的快照。监听模式或Web服务运行时，最近的写入可能还在 -wal 文件中，
+// 直接复制数据库文件会丢失这些写入
+func addDatabase(tw *tar.Writer, name, dbPath string) error {
+	dir, err := os.MkdirTemp("", "asr-state-")
+	if err != nil {
+		return fmt.Errorf("创建临时目录失败: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	snapshot := filepath.Join(dir, filepath.Base(dbPath))
+	if err := store.Snapshot(dbPath, snapshot); err != nil {
+		return fmt.Errorf("导出 %s 失败: %w", name, err)
+	}
+	info, err := os.Stat(snapshot)
+	if err != nil {
+		return err
+	}
+	return addFile(tw, name, snapshot, info)
+}
+
+// addDir 向归档递归写入目录中的所有普通文件
+func addDir(tw *tar.Writer, name, dir string) error {
+	return filepath.Walk(dir, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		return addFile(tw, path.Join(name, filepath.ToSlash(rel)), p, info)
+	})
+}
+
+// writeEntry 向归档写入内存中的数据
+func writeEntry(tw *tar.Writer, name string, data []byte) error {
+	header := &tar.Header{
+		Name:    name,
+		Mode:    0644,
+		Size:    int64(len(data)),
+		ModTime: time.Now(),
+	}
+	if err := tw.WriteHeader(header); err != nil {
+		return fmt.Errorf("写入 %s 失败: %w", name, err)
+	}
+	if _, err := tw.Write(data); err != nil {
+		return fmt.Errorf("写入 %s 失败: %w", name, err)
+	}
+	return nil
+}
+
+// ReadManifest 读取状态包中的清单
+func ReadManifest(src string) (*Manifest, error) {
+	var manifest *Manifest
+	err := walkArchive(src, func(header *tar.Header, r io.Reader) error {
+		if header.Name != manifestName {
+			return nil
+		}
+		var m Manifest
+		if err := json.NewDecoder(r).Decode(&m); err != nil {
+			return fmt.Errorf("解析清单失败: %w", err)
+		}
+		manifest = &m
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if manifest == nil {
+		return nil, fmt.Errorf("状态包中缺少 %s", manifestName)
+	}
+	if manifest.Version > formatVersion {
+		return nil, fmt.Errorf("状态包版本 %d 高于当前程序支持的版本 %d，请升级程序", manifest.Version, formatVersion)
+	}
+	return manifest, nil
+}
+
+// Restore 将状态包中的项恢复到 items 指定的位置（按逻辑名称匹配）。
+// 被覆盖的已有文件会先移入回收站。返回恢复的文件数。
+func Restore(src string, items []Item, bin *trash.Trash) (int, error) {
+	if _, err := ReadManifest(src); err != nil {
+		return 0, err
+	}
+
+	// 按名称长度倒序，保证更具体的名称优先匹配
+	targets := make([]Item, len(items))
+	copy(targets, items)
+	sort.Slice(targets, func(i, j int) bool { return len(targets[i].Name) > len(targets[j].Name) })
+
+	restored := 0
+	err := walkArchive(src, func(header *tar.Header, r io.Reader) error {
+		if header.Typeflag != tar.TypeReg || header.Name == manifestName {
+			return nil
+		}
+
+		dest, item, ok := resolveTarget(header.Name, targets)
+		if !ok {
+			utils.Warn("状态包中的 %s 在本机没有对应位置，已跳过", header.Name)
+			return nil
+		}
+
+		if item.Database {
+			// 旧的 -wal 文件会被当作新数据库的日志重放，损坏恢复的数据库
+			if err := removeDatabaseJournal(dest, bin); err != nil {
+				return err
+			}
+		}
+		if err := restoreFile(dest, r, header, bin); err != nil {
+			return err
+		}
+		restored++
+		return nil
+	})
+	return restored, err
+}
+
+// RestoreConfig 仅从状态包中恢复配置文件到指定路径，状态包中没有配置文件时返回 false
+func RestoreConfig(src, dest string, bin *trash.Trash) (bool, error) {
+	found := false
+	err := walkArchive(src, func(header *tar.Header, r io.Reader) error {
+		if header.Name != configName {
+			return nil
+		}
+		found = true
+		return restoreFile(dest, r, header, bin)
+	})
+	return found, err
+}
+
+// resolveTarget 根据逻辑名称找到本机上的目标路径及其所属的项
+func resolveTarget(name string, targets []Item) (string, Item, bool) {
+	for _, item := range targets {
+		if item.Dir {
+			prefix := item.Name + "/"
+			if strings.HasPrefix(name, prefix) {
+				rel := strings.TrimPrefix(name, prefix)
+				if rel == "" || strings.Contains(rel, "..") {
+					return "", Item{}, false
+				}
+				return filepath.Join(item.Path, filepath.FromSlash(rel)), item, true
+			}
+			continue
+		}
+		if item.Name == name {
+			return item.Path, item, true
+		}
+	}
+	return "", Item{}, false
+}
+
+// removeDatabaseJournal 将数据库的 -wal、-shm 文件移入回收站
+func removeDatabaseJournal(dbPath string, bin *trash.Trash) error {
+	for _, suffix := range []string{"-wal", "-shm"} {
+		journal := dbPath + suffix
+		if _, err := os.Stat(journal); err != nil {
+			continue
+		}
+		if _, err := bin.Remove(journal, "state", "恢复数据库前备份已有的日志文件"); err != nil {
+			return fmt.Errorf("备份已有文件 %s 失败: %w", journal, err)
+		}
+	}
+	return nil
+}
+
+// restoreFile 写入单个文件，已有文件先移入回收站（由回收站记录审计日志）
+func restoreFile(dest string, r io.Reader, header *tar.Header, bin *trash.Trash) error {
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return fmt.Errorf("创建目录失败: %w", err)
+	}
+
+	if _, err := os.Stat(dest); err == nil {
+		if _, err := bin.Remove(dest, "state", "恢复状态前备份已有文件"); err != nil {
+			return fmt.Errorf("备份已有文件 %s 失败: %w", dest, err)
+		}
+	}
+
+	out, err := os.OpenFile(dest, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("创建 %s 失败: %w", dest, err)
+	}
+	if _, err := io.Copy(out, r); err != nil {
+		out.Close()
+		return fmt.Errorf("写入 %s 失败: %w", dest, err)
+	}
+	if err := out.Close(); err != nil {
+		return fmt.Errorf("写入 %s 失败: %w", dest, err)
+	}
+	os.Chtimes(dest, header.ModTime, header.ModTime)
+
+	utils.Info("已恢复: %s", dest)
+	return nil
+}
+
+// walkArchive 依次处理 tar.gz 中的每个条目
+func walkArchive(src string, fn func(header *tar.Header, r io.Reader) error) error {
+	f, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("打开状态包失败: %w", err)
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return fmt.Errorf("读取状态包失败: %w", err)
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("读取状态包失败: %w", err)
+		}
+		if err := fn(header, tr); err != nil {
+			return err
+		}
+	}
+}
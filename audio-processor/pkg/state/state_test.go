@@ -0,0 +1,115 @@
+package state
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/models"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/store"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/trash"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportAndRestore(t *testing.T) {
+	src := t.TempDir()
+	config := models.NewDefaultConfig()
+	config.OutputFolder = filepath.Join(src, "output")
+	cacheDir := filepath.Join(src, "cache")
+
+	require.NoError(t, os.MkdirAll(config.OutputFolder, 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(cacheDir, "bcut"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(config.OutputFolder, "processed_records.json"), []byte(`{"a":1}`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(cacheDir, "bcut", "key"), []byte("cached"), 0644))
+
+	archive := filepath.Join(src, "state.tar.gz")
+	exported, err := Export(archive, Items(config, Locations{CacheDir: cacheDir}))
+	require.NoError(t, err)
+	// 不存在的服务统计与审计日志会被跳过
+	assert.Len(t, exported, 2)
+
+	manifest, err := ReadManifest(archive)
+	require.NoError(t, err)
+	assert.Equal(t, formatVersion, manifest.Version)
+
+	// 恢复到另一台机器的目录结构
+	dst := t.TempDir()
+	target := models.NewDefaultConfig()
+	target.OutputFolder = filepath.Join(dst, "out")
+	targetCache := filepath.Join(dst, "cache")
+	require.NoError(t, os.MkdirAll(target.OutputFolder, 0755))
+	existing := filepath.Join(target.OutputFolder, "processed_records.json")
+	require.NoError(t, os.WriteFile(existing, []byte(`{"old":1}`), 0644))
+
+	bin := trash.New(trash.ModeFolder, filepath.Join(dst, ".trash"), 0)
+	restored, err := Restore(archive, Items(target, Locations{CacheDir: targetCache}), bin)
+	require.NoError(t, err)
+	assert.Equal(t, 2, restored)
+
+	data, err := os.ReadFile(existing)
+	require.NoError(t, err)
+	assert.Equal(t, `{"a":1}`, string(data))
+
+	data, err = os.ReadFile(filepath.Join(targetCache, "bcut", "key"))
+	require.NoError(t, err)
+	assert.Equal(t, "cached", string(data))
+
+	// 被覆盖的文件已移入回收目录
+	trashed, err := filepath.Glob(filepath.Join(dst, ".trash", "*processed_records.json"))
+	require.NoError(t, err)
+	assert.Len(t, trashed, 1)
+}
+
+// TestExportAndRestoreDatabase 测试数据库正被使用时导出，写入还在 -wal 文件中也不丢失；
+// 恢复时旧的 -wal、-shm 文件一起移入回收站
+func TestExportAndRestoreDatabase(t *testing.T) {
+	src := t.TempDir()
+	config := models.NewDefaultConfig()
+	config.OutputFolder = filepath.Join(src, "output")
+
+	db, err := store.Open(store.Path(config.OutputFolder))
+	require.NoError(t, err)
+	defer db.Close()
+	require.NoError(t, db.SaveRecords([]store.Record{
+		{Path: "/media/a.mp4", Filename: "a.mp4", Completed: true, Data: json.RawMessage(`{"completed":true}`)},
+	}, nil))
+	_, err = os.Stat(store.Path(config.OutputFolder) + "-wal")
+	require.NoError(t, err, "写入应还在 -wal 文件中")
+
+	archive := filepath.Join(src, "state.tar.gz")
+	_, err = Export(archive, Items(config, Locations{}))
+	require.NoError(t, err)
+
+	// 目标目录中已有数据库，并留有旧的 -wal、-shm 文件
+	dst := t.TempDir()
+	target := models.NewDefaultConfig()
+	target.OutputFolder = filepath.Join(dst, "output")
+	old, err := store.Open(store.Path(target.OutputFolder))
+	require.NoError(t, err)
+	require.NoError(t, old.SaveRecords([]store.Record{
+		{Path: "/media/old.mp4", Filename: "old.mp4", Data: json.RawMessage(`{}`)},
+	}, nil))
+	require.NoError(t, old.Close())
+	dbPath := store.Path(target.OutputFolder)
+	require.NoError(t, os.WriteFile(dbPath+"-wal", []byte("stale wal"), 0644))
+	require.NoError(t, os.WriteFile(dbPath+"-shm", []byte("stale shm"), 0644))
+
+	bin := trash.New(trash.ModeFolder, filepath.Join(dst, ".trash"), 0)
+	_, err = Restore(archive, Items(target, Locations{}), bin)
+	require.NoError(t, err)
+	for _, suffix := range []string{"-wal", "-shm"} {
+		trashed, err := filepath.Glob(filepath.Join(dst, ".trash", "*processed_records.db"+suffix))
+		require.NoError(t, err)
+		assert.Len(t, trashed, 1, suffix)
+	}
+
+	restored, err := store.Open(dbPath)
+	require.NoError(t, err)
+	defer restored.Close()
+	records, err := restored.Records()
+	require.NoError(t, err)
+	assert.Len(t, records, 1)
+	assert.True(t, records["/media/a.mp4"].Completed)
+}
@@ -0,0 +1,154 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/utils"
+)
+
+// 空请求体的 SHA256
+const emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+// S3Options S3 兼容存储的地址与凭据
+type S3Options struct {
+	Endpoint  string // 服务地址，如 https://s3.us-east-1.amazonaws.com
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	PathStyle bool   // 使用 <地址>/<存储桶>/<键>，否则使用 <存储桶>.<主机>/<键>
+	Prefix    string // 键的前缀
+}
+
+// S3 S3 兼容的对象存储，请求使用 AWS Signature Version 4 签名
+type S3 struct {
+	options  S3Options
+	endpoint *url.URL
+	client   *utils.HTTPClient
+	now      func() time.Time
+}
+
+// NewS3 创建 S3 存储
+func NewS3(options S3Options, client *utils.HTTPClient) (*S3, error) {
+	endpoint, err := url.Parse(strings.TrimSuffix(options.Endpoint, "/"))
+	if err != nil || endpoint.Host == "" || (endpoint.Scheme != "http" && endpoint.Scheme != "https") {
+		return nil, fmt.Errorf("无效的 S3 地址: %q", options.Endpoint)
+	}
+	if options.Bucket == "" || options.Region == "" {
+		return nil, fmt.Errorf("S3 存储需要设置存储桶与区域")
+	}
+	return &S3{options: options, endpoint: endpoint, client: client, now: time.Now}, nil
+}
+
+// Name 返回 s3://<存储桶>/<前缀>
+func (s *S3) Name() string {
+	return "s3://" + joinKey(s.options.Bucket, s.options.Prefix)
+}
+
+// Put 上传对象
+func (s *S3) Put(ctx context.Context, key string, body io.ReadSeeker, size int64) error {
+	hash := sha256.New()
+	if _, err := io.Copy(hash, body); err != nil {
+		return fmt.Errorf("读取文件失败: %w", err)
+	}
+	if _, err := body.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("读取文件失败: %w", err)
+	}
+	req, err := s.newRequest(ctx, http.MethodPut, key, hex.EncodeToString(hash.Sum(nil)))
+	if err != nil {
+		return err
+	}
+	setBody(req, body, size)
+	return s.do(req, http.StatusOK)
+}
+
+// Delete 删除对象
+func (s *S3) Delete(ctx context.Context, key string) error {
+	req, err := s.newRequest(ctx, http.MethodDelete, key, emptyPayloadHash)
+	if err != nil {
+		return err
+	}
+	return s.do(req, http.StatusNoContent, http.StatusOK, http.StatusNotFound)
+}
+
+// do 发送请求，状态码不在 accepted 中时返回错误
+func (s *S3) do(req *http.Request, accepted ...int) error {
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	for _, status := range accepted {
+		if resp.StatusCode == status {
+			io.Copy(io.Discard, resp.Body)
+			return nil
+		}
+	}
+	return statusError(resp)
+}
+
+// newRequest 创建对 key 的已签名请求
+func (s *S3) newRequest(ctx context.Context, method, key, payloadHash string) (*http.Request, error) {
+	objectPath := "/" + escapePath(joinKey(s.options.Prefix, key))
+	host := s.endpoint.Host
+	if s.options.PathStyle {
+		objectPath = s.endpoint.EscapedPath() + "/" + escapePath(s.options.Bucket) + objectPath
+	} else {
+		host = s.options.Bucket + "." + host
+		objectPath = s.endpoint.EscapedPath() + objectPath
+	}
+	req, err := http.NewRequestWithContext(ctx, method, s.endpoint.Scheme+"://"+host+objectPath, nil)
+	if err != nil {
+		return nil, fmt.Errorf("创建请求失败: %w", err)
+	}
+	s.sign(req, objectPath, payloadHash, s.now().UTC())
+	return req, nil
+}
+
+// sign 按 AWS Signature Version 4 为请求签名，签名包含 host、x-amz-content-sha256 与 x-amz-date
+func (s *S3) sign(req *http.Request, canonicalPath, payloadHash string, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	req.Header.Set("x-amz-content-sha256", payloadHash)
+	req.Header.Set("x-amz-date", amzDate)
+
+	const signedHeaders = "host;x-amz-content-sha256;x-amz-date"
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		canonicalPath,
+		"",
+		"host:" + req.URL.Host,
+		"x-amz-content-sha256:" + payloadHash,
+		"x-amz-date:" + amzDate,
+		"",
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	scope := date + "/" + s.options.Region + "/s3/aws4_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := hmacSHA256([]byte("AWS4"+s.options.SecretKey), date)
+	for _, part := range []string{s.options.Region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.options.AccessKey, scope, signedHeaders, signature))
+}
+
+// hmacSHA256 计算 HMAC-SHA256
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,158 @@
+// Package storage 将输出文件保存到远程存储：S3 兼容的对象存储（AWS S3、MinIO 等）或 WebDAV（Nextcloud 等）。
+// 本地输出目录仍保留完整的输出，供网页、搜索与重新导出使用；远程存储按输出清单中的相对路径保存一份，
+// 媒体目录中的输出（如与视频放在一起的字幕）位于 media/ 下
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/export"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/models"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/utils"
+)
+
+// 存储方式
+const (
+	BackendLocal  = "local"  // 只写本地输出目录
+	BackendS3     = "s3"     // S3 兼容的对象存储
+	BackendWebDAV = "webdav" // WebDAV
+)
+
+// Storage 远程存储，key 为以 / 分隔的相对路径
+type Storage interface {
+	// Name 返回存储的描述，如 s3://bucket/prefix，用于日志
+	Name() string
+	// Put 写入 key，已存在时覆盖。body 需要支持 Seek，请求失败重试时重新读取
+	Put(ctx context.Context, key string, body io.ReadSeeker, size int64) error
+	// Delete 删除 key，不存在时不返回错误
+	Delete(ctx context.Context, key string) error
+}
+
+// FromConfig 根据配置创建远程存储，只写本地输出目录时返回 nil
+func FromConfig(config *models.Config) (Storage, error) {
+	if config == nil {
+		return nil, nil
+	}
+	client := utils.NewHTTPClient(config.HTTPClientOptions())
+	switch config.OutputStorage {
+	case "", BackendLocal:
+		return nil, nil
+	case BackendS3:
+		accessKey, secretKey := config.S3Credentials()
+		if accessKey == "" || secretKey == "" {
+			return nil, fmt.Errorf("未设置 S3 访问密钥（s3_access_key/s3_secret_key 或环境变量 S3_ACCESS_KEY/S3_SECRET_KEY）")
+		}
+		return NewS3(S3Options{
+			Endpoint:  config.S3Endpoint,
+			Region:    config.S3Region,
+			Bucket:    config.S3Bucket,
+			AccessKey: accessKey,
+			SecretKey: secretKey,
+			PathStyle: config.S3PathStyle,
+			Prefix:    config.StoragePrefix,
+		}, client)
+	case BackendWebDAV:
+		return NewWebDAV(config.WebDAVURL, config.WebDAVUser, config.WebDAVKey(), config.StoragePrefix, client)
+	default:
+		return nil, fmt.Errorf("未知的输出存储: %s", config.OutputStorage)
+	}
+}
+
+// Key 返回输出文件在远程存储中的路径
+func Key(entry export.OutputEntry) string {
+	if entry.Root == export.RootMedia {
+		return path.Join(export.RootMedia, entry.Path)
+	}
+	return path.Clean(entry.Path)
+}
+
+// ManifestKey 返回输出清单在远程存储中的路径，与本地输出目录中的位置一致
+func ManifestKey(manifest *export.OutputManifest) string {
+	return path.Join(manifest.Name, export.ManifestFileName)
+}
+
+// PutFile 将本地文件写入 key
+func PutFile(ctx context.Context, s Storage, key, filePath string) error {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return fmt.Errorf("打开文件失败: %w", err)
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("读取文件信息失败: %w", err)
+	}
+	if err := s.Put(ctx, key, file, info.Size()); err != nil {
+		return fmt.Errorf("上传 %s 失败: %w", key, err)
+	}
+	return nil
+}
+
+// PushManifest 上传清单中的全部输出，最后上传清单本身，使远程的清单只引用已上传的文件。
+// 返回上传的文件数；部分文件失败时继续上传其余输出，但不上传清单
+func PushManifest(ctx context.Context, s Storage, config *models.Config, manifest *export.OutputManifest) (int, error) {
+	var errs []error
+	uploaded := 0
+	for _, entry := range manifest.Outputs {
+		if err := PutFile(ctx, s, Key(entry), entry.Resolve(config.OutputFolder, config.MediaFolder)); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		uploaded++
+	}
+	if len(errs) > 0 {
+		return uploaded, errors.Join(errs...)
+	}
+	if err := PutFile(ctx, s, ManifestKey(manifest), export.ManifestPath(config.OutputFolder, manifest.Name)); err != nil {
+		return uploaded, err
+	}
+	return uploaded + 1, nil
+}
+
+// joinKey 将前缀与 key 拼接为远程路径
+func joinKey(prefix, key string) string {
+	return strings.TrimPrefix(path.Join("/", prefix, key), "/")
+}
+
+// escapePath 按 RFC 3986 逐段编码路径，保留 /
+func escapePath(p string) string {
+	var b strings.Builder
+	for i := 0; i < len(p); i++ {
+		c := p[i]
+		if c == '/' || c == '-' || c == '_' || c == '.' || c == '~' ||
+			('A' <= c && c <= 'Z') || ('a' <= c && c <= 'z') || ('0' <= c && c <= '9') {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
+
+// setBody 设置可重放的请求体，重试时从头读取
+func setBody(req *http.Request, body io.ReadSeeker, size int64) {
+	req.ContentLength = size
+	req.Body = io.NopCloser(body)
+	req.GetBody = func() (io.ReadCloser, error) {
+		if _, err := body.Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
+		return io.NopCloser(body), nil
+	}
+	if size == 0 {
+		req.Body = http.NoBody
+	}
+}
+
+// statusError 读取失败响应的内容，作为错误返回
+func statusError(resp *http.Response) error {
+	message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return fmt.Errorf("状态码 %d: %s", resp.StatusCode, strings.TrimSpace(string(message)))
+}
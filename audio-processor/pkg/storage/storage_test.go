@@ -0,0 +1,227 @@
+package storage
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/export"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/models"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testClient 不等待重试的HTTP客户端
+func testClient() *utils.HTTPClient {
+	return utils.NewHTTPClient(utils.HTTPClientOptions{Timeout: 5 * time.Second, MaxRetries: 1})
+}
+
+// fakeServer 以内存保存上传内容，按路径记录
+type fakeServer struct {
+	mu      sync.Mutex
+	objects map[string]string
+	dirs    map[string]bool
+	webdav  bool
+	fail    int // 前几次 PUT 返回 503
+}
+
+func newFakeServer(webdav bool) *fakeServer {
+	return &fakeServer{objects: make(map[string]string), dirs: map[string]bool{"/dav": true}, webdav: webdav}
+}
+
+func (f *fakeServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.webdav {
+		if user, password, ok := r.BasicAuth(); !ok || user != "alice" || password != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+	} else if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/") {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPut:
+		if f.fail > 0 {
+			f.fail--
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if f.webdav && !f.dirs[filepath.ToSlash(filepath.Dir(r.URL.Path))] {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		if !f.webdav {
+			hash := sha256.Sum256(body)
+			if r.Header.Get("x-amz-content-sha256") != hex.EncodeToString(hash[:]) {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+		}
+		f.objects[r.URL.Path] = string(body)
+		if f.webdav {
+			w.WriteHeader(http.StatusCreated)
+		}
+	case "MKCOL":
+		dir := strings.TrimSuffix(r.URL.Path, "/")
+		if f.dirs[dir] {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		if !f.dirs[filepath.ToSlash(filepath.Dir(dir))] {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		f.dirs[dir] = true
+		w.WriteHeader(http.StatusCreated)
+	case http.MethodDelete:
+		if _, ok := f.objects[r.URL.Path]; !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		delete(f.objects, r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// TestS3Sign 测试签名与按 AWS 规则编码的路径
+func TestS3Sign(t *testing.T) {
+	s, err := NewS3(S3Options{Endpoint: "http://127.0.0.1:9000", Region: "us-east-1", Bucket: "asr", AccessKey: "AKID", SecretKey: "SECRET", PathStyle: true, Prefix: "team"}, testClient())
+	require.NoError(t, err)
+	s.now = func() time.Time { return time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC) }
+	hash := sha256.Sum256([]byte("hello"))
+
+	req, err := s.newRequest(context.Background(), http.MethodPut, "会议/meeting.txt", hex.EncodeToString(hash[:]))
+	require.NoError(t, err)
+	assert.Equal(t, "/asr/team/%E4%BC%9A%E8%AE%AE/meeting.txt", req.URL.EscapedPath())
+	assert.Equal(t, "20260102T030405Z", req.Header.Get("x-amz-date"))
+	assert.Equal(t, "AWS4-HMAC-SHA256 Credential=AKID/20260102/us-east-1/s3/aws4_request, "+
+		"SignedHeaders=host;x-amz-content-sha256;x-amz-date, "+
+		"Signature=a0fd299c4fc70ec44688b288ad634161309951982115ee2477c2775d2c5370f7", req.Header.Get("Authorization"))
+	assert.Equal(t, "s3://asr/team", s.Name())
+
+	// 虚拟主机风格
+	s.options.PathStyle = false
+	req, err = s.newRequest(context.Background(), http.MethodDelete, "a.txt", emptyPayloadHash)
+	require.NoError(t, err)
+	assert.Equal(t, "asr.127.0.0.1:9000", req.URL.Host)
+	assert.Equal(t, "/team/a.txt", req.URL.EscapedPath())
+}
+
+// writeOutputs 在输出目录与媒体目录中各生成一个输出并写入清单
+func writeOutputs(t *testing.T) (*models.Config, *export.OutputManifest) {
+	t.Helper()
+	root := t.TempDir()
+	config := models.NewDefaultConfig()
+	config.OutputFolder = filepath.Join(root, "output")
+	config.MediaFolder = filepath.Join(root, "media")
+	txt := filepath.Join(config.OutputFolder, "会议.txt")
+	srt := filepath.Join(config.MediaFolder, "会议.srt")
+	require.NoError(t, os.MkdirAll(config.OutputFolder, 0755))
+	require.NoError(t, os.MkdirAll(config.MediaFolder, 0755))
+	require.NoError(t, os.WriteFile(txt, []byte("大家好"), 0644))
+	require.NoError(t, os.WriteFile(srt, []byte("1\n00:00:00,000 --> 00:00:01,000\n大家好\n"), 0644))
+	manifestPath, err := export.WriteManifest(config, filepath.Join(config.MediaFolder, "会议.mp4"), map[string]string{"txt": txt, "srt": srt})
+	require.NoError(t, err)
+	manifest, err := export.LoadManifest(manifestPath)
+	require.NoError(t, err)
+	return config, manifest
+}
+
+// TestPushManifestS3 测试上传全部输出与清单，5xx 时重试，删除不存在的对象不报错
+func TestPushManifestS3(t *testing.T) {
+	fake := newFakeServer(false)
+	fake.fail = 1
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	config, manifest := writeOutputs(t)
+	config.OutputStorage = BackendS3
+	config.S3Endpoint = server.URL
+	config.S3Bucket = "asr"
+	config.S3PathStyle = true
+	config.StoragePrefix = "team"
+	config.HTTPRetryDelay = 0.01
+
+	_, err := FromConfig(config)
+	assert.Error(t, err, "未设置密钥")
+	t.Setenv("S3_ACCESS_KEY", "AKID")
+	t.Setenv("S3_SECRET_KEY", "SECRET")
+	remote, err := FromConfig(config)
+	require.NoError(t, err)
+
+	uploaded, err := PushManifest(context.Background(), remote, config, manifest)
+	require.NoError(t, err)
+	assert.Equal(t, 3, uploaded)
+	assert.Equal(t, "大家好", fake.objects["/asr/team/会议.txt"])
+	assert.Contains(t, fake.objects["/asr/team/media/会议.srt"], "大家好")
+	assert.Contains(t, fake.objects["/asr/team/会议/outputs.json"], `"name": "会议"`)
+
+	require.NoError(t, remote.Delete(context.Background(), "会议.txt"))
+	require.NoError(t, remote.Delete(context.Background(), "会议.txt"))
+	assert.NotContains(t, fake.objects, "/asr/team/会议.txt")
+}
+
+// TestPushManifestWebDAV 测试上级目录不存在时逐级创建
+func TestPushManifestWebDAV(t *testing.T) {
+	fake := newFakeServer(true)
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	config, manifest := writeOutputs(t)
+	config.OutputStorage = BackendWebDAV
+	config.WebDAVURL = server.URL + "/dav/"
+	config.WebDAVUser = "alice"
+	config.StoragePrefix = "users/alice"
+	t.Setenv("WEBDAV_PASSWORD", "secret")
+	remote, err := FromConfig(config)
+	require.NoError(t, err)
+	assert.Equal(t, server.URL+"/dav/users/alice", remote.Name())
+
+	uploaded, err := PushManifest(context.Background(), remote, config, manifest)
+	require.NoError(t, err)
+	assert.Equal(t, 3, uploaded)
+	assert.Equal(t, "大家好", fake.objects["/dav/users/alice/会议.txt"])
+	assert.Contains(t, fake.objects, "/dav/users/alice/media/会议.srt")
+	assert.Contains(t, fake.objects, "/dav/users/alice/会议/outputs.json")
+	assert.True(t, fake.dirs["/dav/users/alice/media"])
+
+	// 输出文件缺失时不上传清单
+	require.NoError(t, os.Remove(filepath.Join(config.OutputFolder, "会议.txt")))
+	delete(fake.objects, "/dav/users/alice/会议/outputs.json")
+	uploaded, err = PushManifest(context.Background(), remote, config, manifest)
+	assert.Error(t, err)
+	assert.Equal(t, 1, uploaded)
+	assert.NotContains(t, fake.objects, "/dav/users/alice/会议/outputs.json")
+
+	config.WebDAVUser = "bob"
+	remote, err = FromConfig(config)
+	require.NoError(t, err)
+	assert.Error(t, PutFile(context.Background(), remote, "a.txt", filepath.Join(config.MediaFolder, "会议.srt")))
+}
+
+func TestFromConfigLocal(t *testing.T) {
+	config := models.NewDefaultConfig()
+	remote, err := FromConfig(config)
+	require.NoError(t, err)
+	assert.Nil(t, remote)
+
+	config.OutputStorage = "ftp"
+	_, err = FromConfig(config)
+	assert.Error(t, err)
+}
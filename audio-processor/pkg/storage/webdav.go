@@ -0,0 +1,123 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/utils"
+)
+
+// WebDAV WebDAV 目录，写入时按需创建上级目录
+type WebDAV struct {
+	base     *url.URL
+	user     string
+	password string
+	prefix   string
+	client   *utils.HTTPClient
+}
+
+// NewWebDAV 创建 WebDAV 存储，baseURL 为保存输出的目录地址
+func NewWebDAV(baseURL, user, password, prefix string, client *utils.HTTPClient) (*WebDAV, error) {
+	base, err := url.Parse(strings.TrimSuffix(baseURL, "/"))
+	if err != nil || base.Host == "" || (base.Scheme != "http" && base.Scheme != "https") {
+		return nil, fmt.Errorf("无效的 WebDAV 地址: %q", baseURL)
+	}
+	return &WebDAV{base: base, user: user, password: password, prefix: prefix, client: client}, nil
+}
+
+// Name 返回目录地址（不含凭据）
+func (d *WebDAV) Name() string {
+	return strings.TrimSuffix(d.base.Redacted()+"/"+joinKey(d.prefix, ""), "/")
+}
+
+// Put 上传文件，上级目录不存在（409）时逐级创建后重试一次
+func (d *WebDAV) Put(ctx context.Context, key string, body io.ReadSeeker, size int64) error {
+	remote := joinKey(d.prefix, key)
+	put := func() (int, error) {
+		if _, err := body.Seek(0, io.SeekStart); err != nil {
+			return 0, fmt.Errorf("读取文件失败: %w", err)
+		}
+		req, err := d.newRequest(ctx, http.MethodPut, remote)
+		if err != nil {
+			return 0, err
+		}
+		setBody(req, body, size)
+		return d.do(req, http.StatusCreated, http.StatusNoContent, http.StatusOK)
+	}
+
+	status, err := put()
+	if err == nil || (status != http.StatusConflict && status != http.StatusNotFound) {
+		return err
+	}
+	if err := d.mkdirAll(ctx, path.Dir(remote)); err != nil {
+		return err
+	}
+	_, err = put()
+	return err
+}
+
+// Delete 删除文件
+func (d *WebDAV) Delete(ctx context.Context, key string) error {
+	req, err := d.newRequest(ctx, http.MethodDelete, joinKey(d.prefix, key))
+	if err != nil {
+		return err
+	}
+	_, err = d.do(req, http.StatusNoContent, http.StatusOK, http.StatusNotFound)
+	return err
+}
+
+// mkdirAll 从上到下逐级创建目录，已存在（405）时忽略
+func (d *WebDAV) mkdirAll(ctx context.Context, dir string) error {
+	if dir == "." || dir == "" {
+		return nil
+	}
+	current := ""
+	for _, part := range strings.Split(dir, "/") {
+		current = path.Join(current, part)
+		req, err := d.newRequest(ctx, "MKCOL", current+"/")
+		if err != nil {
+			return err
+		}
+		if _, err := d.do(req, http.StatusCreated, http.StatusMethodNotAllowed); err != nil {
+			return fmt.Errorf("创建目录 %s 失败: %w", current, err)
+		}
+	}
+	return nil
+}
+
+// newRequest 创建对 remote 的请求
+func (d *WebDAV) newRequest(ctx context.Context, method, remote string) (*http.Request, error) {
+	target := d.base.Scheme + "://" + d.base.Host + d.base.EscapedPath() + "/" + escapePath(remote)
+	req, err := http.NewRequestWithContext(ctx, method, target, nil)
+	if err != nil {
+		return nil, fmt.Errorf("创建请求失败: %w", err)
+	}
+	if d.user != "" || d.password != "" {
+		req.SetBasicAuth(d.user, d.password)
+	} else if d.base.User != nil {
+		password, _ := d.base.User.Password()
+		req.SetBasicAuth(d.base.User.Username(), password)
+	}
+	return req, nil
+}
+
+// do 发送请求，状态码不在 accepted 中时返回状态码与错误
+func (d *WebDAV) do(req *http.Request, accepted ...int) (int, error) {
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	for _, status := range accepted {
+		if resp.StatusCode == status {
+			io.Copy(io.Discard, resp.Body)
+			return resp.StatusCode, nil
+		}
+	}
+	return resp.StatusCode, statusError(resp)
+}
@@ -0,0 +1,675 @@
+// Package store 基于嵌入式 SQLite 的处理记录与文稿库：保存已处理文件的记录、每次批处理的结果，
+// 以及可选的识别段落。多个进程可以同时读写同一个数据库（WAL 模式，写入冲突时等待）
+package store
+
+import (
+	"database/sql"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// FileName 数据库的文件名，位于输出目录
+const FileName = "processed_records.db"
+
+// 写入冲突时的等待时间（毫秒）
+const busyTimeout = 10000
+
+// migrations 按顺序执行的建表语句，数据库的 user_version 记录已执行到第几项
+var migrations = []string{
+	`CREATE TABLE records (
+		path TEXT PRIMARY KEY,
+		filename TEXT NOT NULL,
+		completed INTEGER NOT NULL DEFAULT 0,
+		status TEXT NOT NULL DEFAULT '',
+		service TEXT NOT NULL DEFAULT '',
+		last_processed_time TEXT NOT NULL DEFAULT '',
+		data TEXT NOT NULL
+	);
+	CREATE INDEX records_filename ON records(filename);`,
+
+	`CREATE TABLE batches (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		started_at TEXT NOT NULL,
+		finished_at TEXT NOT NULL,
+		total INTEGER NOT NULL,
+		succeeded INTEGER NOT NULL,
+		failed INTEGER NOT NULL
+	);
+	CREATE TABLE batch_files (
+		batch_id INTEGER NOT NULL REFERENCES batches(id) ON DELETE CASCADE,
+		file TEXT NOT NULL,
+		success INTEGER NOT NULL,
+		status TEXT NOT NULL DEFAULT '',
+		service TEXT NOT NULL DEFAULT '',
+		duration_seconds INTEGER NOT NULL DEFAULT 0,
+		process_seconds REAL NOT NULL DEFAULT 0,
+		outputs TEXT NOT NULL DEFAULT '{}',
+		error_kind TEXT NOT NULL DEFAULT '',
+		error TEXT NOT NULL DEFAULT '',
+		log_path TEXT NOT NULL DEFAULT ''
+	);
+	CREATE INDEX batch_files_batch ON batch_files(batch_id);
+	CREATE INDEX batch_files_file ON batch_files(file);`,
+
+	`CREATE TABLE segments (
+		source TEXT NOT NULL,
+		idx INTEGER NOT NULL,
+		start_time REAL NOT NULL,
+		end_time REAL NOT NULL,
+		text TEXT NOT NULL,
+		speaker TEXT NOT NULL DEFAULT '',
+		PRIMARY KEY (source, idx)
+	);`,
+
+	`ALTER TABLE records ADD COLUMN content_hash TEXT NOT NULL DEFAULT '';
+	CREATE INDEX records_content_hash ON records(content_hash);`,
+
+	`CREATE TABLE llm_usage (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		time TEXT NOT NULL,
+		day TEXT NOT NULL,
+		job TEXT NOT NULL DEFAULT '',
+		provider TEXT NOT NULL,
+		model TEXT NOT NULL,
+		requests INTEGER NOT NULL DEFAULT 1,
+		prompt_tokens INTEGER NOT NULL DEFAULT 0,
+		completion_tokens INTEGER NOT NULL DEFAULT 0
+	);
+	CREATE INDEX llm_usage_day ON llm_usage(day);
+	CREATE INDEX llm_usage_job ON llm_usage(job);`,
+
+	`CREATE TABLE embeddings (
+		job TEXT NOT NULL,
+		model TEXT NOT NULL,
+		idx INTEGER NOT NULL,
+		start_time REAL NOT NULL,
+		end_time REAL NOT NULL,
+		text TEXT NOT NULL,
+		vector BLOB NOT NULL,
+		PRIMARY KEY (job, model, idx)
+	);`,
+}
+
+// ErrNotFound 记录不存在
+var ErrNotFound = errors.New("记录不存在")
+
+// Record 一个已处理文件的记录。Data 为完整记录的 JSON，其余字段为便于查询而单独保存的列
+type Record struct {
+	Path              string
+	Filename          string
+	Completed         bool
+	Status            string
+	Service           string
+	LastProcessedTime string
+	ContentHash       string // 文件内容的快速哈希，用于识别内容相同的文件
+	Data              json.RawMessage
+}
+
+// Batch 一次批处理的结果
+type Batch struct {
+	ID         int64
+	StartedAt  string
+	FinishedAt string
+	Total      int
+	Succeeded  int
+	Failed     int
+	Files      []BatchFile
+}
+
+// BatchFile 批处理中一个文件的结果
+type BatchFile struct {
+	File           string
+	Success        bool
+	Status         string
+	Service        string
+	Duration       int
+	ProcessSeconds float64
+	Outputs        map[string]string
+	ErrorKind      string
+	Error          string
+	LogPath        string
+}
+
+// LLMUsage 一次大模型请求的 token 用量
+type LLMUsage struct {
+	Time             time.Time
+	Job              string // 所属任务（处理的文件），不属于任何任务时为空
+	Provider         string
+	Model            string
+	Requests         int
+	PromptTokens     int
+	CompletionTokens int
+}
+
+// LLMUsageTotal 按天或按任务汇总的大模型用量
+type LLMUsageTotal struct {
+	Key              string // 日期 (2006-01-02) 或任务
+	Provider         string
+	Model            string
+	Requests         int
+	PromptTokens     int
+	CompletionTokens int
+}
+
+// Embedding 文稿片段的向量，用于文稿问答的检索
+type Embedding struct {
+	Start  float64
+	End    float64
+	Text   string
+	Vector []float32
+}
+
+// Segment 一段识别结果
+type Segment struct {
+	Start   float64
+	End     float64
+	Text    string
+	Speaker string
+}
+
+// Store 处理记录数据库
+type Store struct {
+	db *sql.DB
+}
+
+// Path 返回输出目录中数据库的路径
+func Path(outputDir string) string {
+	return filepath.Join(outputDir, FileName)
+}
+
+// Open 打开（不存在时创建）数据库并执行未完成的迁移
+func Open(path string) (*Store, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("创建数据库目录失败: %w", err)
+	}
+	dsn := fmt.Sprintf("file:%s?_pragma=busy_timeout(%d)&_pragma=journal_mode(WAL)&_pragma=foreign_keys(1)",
+		filepath.ToSlash(path), busyTimeout)
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("打开数据库失败: %w", err)
+	}
+	// 同一进程内串行写入，进程之间由 busy_timeout 等待
+	db.SetMaxOpenConns(1)
+
+	s := &Store{db: db}
+	if err := s.migrate(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// Snapshot 将 path 处的数据库复制为 dest 处独立的一致副本，包括尚在 WAL 文件中的写入。
+// 数据库可以正被其他进程读写；不执行迁移，dest 已存在时返回错误
+func Snapshot(path, dest string) error {
+	dsn := fmt.Sprintf("file:%s?mode=ro&_pragma=busy_timeout(%d)", filepath.ToSlash(path), busyTimeout)
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return fmt.Errorf("打开数据库失败: %w", err)
+	}
+	defer db.Close()
+	if _, err := db.Exec("VACUUM INTO ?", dest); err != nil {
+		return fmt.Errorf("复制数据库失败: %w", err)
+	}
+	return nil
+}
+
+// Close 关闭数据库
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// migrate 执行尚未执行的迁移，每项迁移与版本号在同一个事务中提交
+func (s *Store) migrate() error {
+	version, err := s.Version()
+	if err != nil {
+		return err
+	}
+	if version > len(migrations) {
+		return fmt.Errorf("数据库版本 %d 高于程序支持的版本 %d，请升级程序", version, len(migrations))
+	}
+	for i := version; i < len(migrations); i++ {
+		tx, err := s.db.Begin()
+		if err != nil {
+			return fmt.Errorf("迁移数据库失败: %w", err)
+		}
+		if _, err := tx.Exec(migrations[i]); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("迁移数据库到版本 %d 失败: %w", i+1, err)
+		}
+		// PRAGMA 不支持参数绑定
+		if _, err := tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", i+1)); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("迁移数据库到版本 %d 失败: %w", i+1, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("迁移数据库到版本 %d 失败: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// Version 返回数据库已执行的迁移数
+func (s *Store) Version() (int, error) {
+	var version int
+	if err := s.db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return 0, fmt.Errorf("读取数据库版本失败: %w", err)
+	}
+	return version, nil
+}
+
+// Records 返回全部处理记录（路径 -> 记录）
+func (s *Store) Records() (map[string]Record, error) {
+	rows, err := s.db.Query(`SELECT path, filename, completed, status, service, last_processed_time, content_hash, data FROM records`)
+	if err != nil {
+		return nil, fmt.Errorf("读取处理记录失败: %w", err)
+	}
+	defer rows.Close()
+
+	records := make(map[string]Record)
+	for rows.Next() {
+		var record Record
+		var data string
+		if err := rows.Scan(&record.Path, &record.Filename, &record.Completed, &record.Status,
+			&record.Service, &record.LastProcessedTime, &record.ContentHash, &data); err != nil {
+			return nil, fmt.Errorf("读取处理记录失败: %w", err)
+		}
+		record.Data = json.RawMessage(data)
+		records[record.Path] = record
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("读取处理记录失败: %w", err)
+	}
+	return records, nil
+}
+
+// SaveRecords 在一个事务中写入 records 并删除 deleted 中的路径
+func (s *Store) SaveRecords(records []Record, deleted []string) error {
+	if len(records) == 0 && len(deleted) == 0 {
+		return nil
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("保存处理记录失败: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, path := range deleted {
+		if _, err := tx.Exec(`DELETE FROM records WHERE path = ?`, path); err != nil {
+			return fmt.Errorf("删除处理记录失败: %w", err)
+		}
+	}
+	for _, record := range records {
+		if err := putRecord(tx, record); err != nil {
+			return err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("保存处理记录失败: %w", err)
+	}
+	return nil
+}
+
+// putRecord 写入一条记录，覆盖同路径的记录
+func putRecord(tx *sql.Tx, record Record) error {
+	data := string(record.Data)
+	if data == "" {
+		data = "{}"
+	}
+	_, err := tx.Exec(`INSERT OR REPLACE INTO records (path, filename, completed, status, service, last_processed_time, content_hash, data)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		record.Path, record.Filename, record.Completed, record.Status, record.Service, record.LastProcessedTime, record.ContentHash, data)
+	if err != nil {
+		return fmt.Errorf("保存处理记录 %s 失败: %w", record.Path, err)
+	}
+	return nil
+}
+
+// ImportJSON 导入旧版 processed_records.json 中的记录，数据库中已有的路径保留数据库中的记录，
+// 返回导入的记录数
+func (s *Store) ImportJSON(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, fmt.Errorf("读取处理记录失败: %w", err)
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return 0, fmt.Errorf("解析处理记录失败: %w", err)
+	}
+
+	paths := make([]string, 0, len(raw))
+	for path := range raw {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("导入处理记录失败: %w", err)
+	}
+	defer tx.Rollback()
+
+	imported := 0
+	for _, recordPath := range paths {
+		var fields struct {
+			Filename          string `json:"filename"`
+			Completed         bool   `json:"completed"`
+			Status            string `json:"status"`
+			Service           string `json:"service"`
+			LastProcessedTime string `json:"last_processed_time"`
+			ContentHash       string `json:"content_hash"`
+		}
+		if err := json.Unmarshal(raw[recordPath], &fields); err != nil {
+			return 0, fmt.Errorf("解析处理记录 %s 失败: %w", recordPath, err)
+		}
+		if fields.Filename == "" {
+			fields.Filename = filepath.Base(recordPath)
+		}
+		result, err := tx.Exec(`INSERT OR IGNORE INTO records (path, filename, completed, status, service, last_processed_time, content_hash, data)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+			recordPath, fields.Filename, fields.Completed, fields.Status, fields.Service, fields.LastProcessedTime, fields.ContentHash, string(raw[recordPath]))
+		if err != nil {
+			return 0, fmt.Errorf("导入处理记录 %s 失败: %w", recordPath, err)
+		}
+		if n, _ := result.RowsAffected(); n > 0 {
+			imported++
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("导入处理记录失败: %w", err)
+	}
+	return imported, nil
+}
+
+// AddBatch 保存一次批处理的结果，返回批次编号
+func (s *Store) AddBatch(batch Batch) (int64, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("保存批次结果失败: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`INSERT INTO batches (started_at, finished_at, total, succeeded, failed) VALUES (?, ?, ?, ?, ?)`,
+		batch.StartedAt, batch.FinishedAt, batch.Total, batch.Succeeded, batch.Failed)
+	if err != nil {
+		return 0, fmt.Errorf("保存批次结果失败: %w", err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("保存批次结果失败: %w", err)
+	}
+	for _, file := range batch.Files {
+		outputs, err := json.Marshal(file.Outputs)
+		if err != nil {
+			return 0, fmt.Errorf("编码输出文件失败: %w", err)
+		}
+		if file.Outputs == nil {
+			outputs = []byte("{}")
+		}
+		_, err = tx.Exec(`INSERT INTO batch_files (batch_id, file, success, status, service, duration_seconds,
+			process_seconds, outputs, error_kind, error, log_path) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			id, file.File, file.Success, file.Status, file.Service, file.Duration, file.ProcessSeconds,
+			string(outputs), file.ErrorKind, file.Error, file.LogPath)
+		if err != nil {
+			return 0, fmt.Errorf("保存批次结果 %s 失败: %w", file.File, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("保存批次结果失败: %w", err)
+	}
+	return id, nil
+}
+
+// Batches 返回最近的 limit 次批处理（新的在前），limit 不大于 0 时返回全部
+func (s *Store) Batches(limit int) ([]Batch, error) {
+	query := `SELECT id, started_at, finished_at, total, succeeded, failed FROM batches ORDER BY id DESC`
+	if limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", limit)
+	}
+	rows, err := s.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("读取批次结果失败: %w", err)
+	}
+	var batches []Batch
+	for rows.Next() {
+		var batch Batch
+		if err := rows.Scan(&batch.ID, &batch.StartedAt, &batch.FinishedAt, &batch.Total, &batch.Succeeded, &batch.Failed); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("读取批次结果失败: %w", err)
+		}
+		batches = append(batches, batch)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("读取批次结果失败: %w", err)
+	}
+
+	for i := range batches {
+		files, err := s.batchFiles(batches[i].ID)
+		if err != nil {
+			return nil, err
+		}
+		batches[i].Files = files
+	}
+	return batches, nil
+}
+
+// batchFiles 返回一次批处理中各文件的结果
+func (s *Store) batchFiles(id int64) ([]BatchFile, error) {
+	rows, err := s.db.Query(`SELECT file, success, status, service, duration_seconds, process_seconds,
+		outputs, error_kind, error, log_path FROM batch_files WHERE batch_id = ? ORDER BY file`, id)
+	if err != nil {
+		return nil, fmt.Errorf("读取批次结果失败: %w", err)
+	}
+	defer rows.Close()
+
+	var files []BatchFile
+	for rows.Next() {
+		var file BatchFile
+		var outputs string
+		if err := rows.Scan(&file.File, &file.Success, &file.Status, &file.Service, &file.Duration,
+			&file.ProcessSeconds, &outputs, &file.ErrorKind, &file.Error, &file.LogPath); err != nil {
+			return nil, fmt.Errorf("读取批次结果失败: %w", err)
+		}
+		if err := json.Unmarshal([]byte(outputs), &file.Outputs); err != nil {
+			return nil, fmt.Errorf("解析输出文件失败: %w", err)
+		}
+		files = append(files, file)
+	}
+	return files, rows.Err()
+}
+
+// SaveSegments 保存源文件的识别段落，替换该文件之前保存的段落
+func (s *Store) SaveSegments(source string, segments []Segment) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("保存识别段落失败: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM segments WHERE source = ?`, source); err != nil {
+		return fmt.Errorf("保存识别段落失败: %w", err)
+	}
+	for i, segment := range segments {
+		_, err := tx.Exec(`INSERT INTO segments (source, idx, start_time, end_time, text, speaker) VALUES (?, ?, ?, ?, ?, ?)`,
+			source, i, segment.Start, segment.End, segment.Text, segment.Speaker)
+		if err != nil {
+			return fmt.Errorf("保存识别段落失败: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("保存识别段落失败: %w", err)
+	}
+	return nil
+}
+
+// Segments 返回源文件保存的识别段落，没有时返回 nil
+func (s *Store) Segments(source string) ([]Segment, error) {
+	rows, err := s.db.Query(`SELECT start_time, end_time, text, speaker FROM segments WHERE source = ? ORDER BY idx`, source)
+	if err != nil {
+		return nil, fmt.Errorf("读取识别段落失败: %w", err)
+	}
+	defer rows.Close()
+
+	var segments []Segment
+	for rows.Next() {
+		var segment Segment
+		if err := rows.Scan(&segment.Start, &segment.End, &segment.Text, &segment.Speaker); err != nil {
+			return nil, fmt.Errorf("读取识别段落失败: %w", err)
+		}
+		segments = append(segments, segment)
+	}
+	return segments, rows.Err()
+}
+
+// Record 返回单个文件的处理记录，不存在时返回 ErrNotFound
+func (s *Store) Record(path string) (Record, error) {
+	record := Record{Path: path}
+	var data string
+	err := s.db.QueryRow(`SELECT filename, completed, status, service, last_processed_time, content_hash, data FROM records WHERE path = ?`, path).
+		Scan(&record.Filename, &record.Completed, &record.Status, &record.Service, &record.LastProcessedTime, &record.ContentHash, &data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return record, ErrNotFound
+	}
+	if err != nil {
+		return record, fmt.Errorf("读取处理记录失败: %w", err)
+	}
+	record.Data = json.RawMessage(data)
+	return record, nil
+}
+
+// dayLayout 按天汇总用量的日期格式
+const dayLayout = "2006-01-02"
+
+// AddLLMUsage 保存一次大模型请求的用量
+func (s *Store) AddLLMUsage(usage LLMUsage) error {
+	if usage.Time.IsZero() {
+		usage.Time = time.Now()
+	}
+	_, err := s.db.Exec(`INSERT INTO llm_usage (time, day, job, provider, model, requests, prompt_tokens, completion_tokens)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		usage.Time.Format(time.RFC3339), usage.Time.Format(dayLayout), usage.Job, usage.Provider, usage.Model,
+		usage.Requests, usage.PromptTokens, usage.CompletionTokens)
+	if err != nil {
+		return fmt.Errorf("保存大模型用量失败: %w", err)
+	}
+	return nil
+}
+
+// LLMUsageByDay 按天与模型汇总 since 当天及之后的用量，新的在前
+func (s *Store) LLMUsageByDay(since time.Time) ([]LLMUsageTotal, error) {
+	return s.llmUsageTotals("day", since)
+}
+
+// LLMUsageByJob 按任务与模型汇总 since 当天及之后的用量，不属于任务的请求不计入，用量大的在前
+func (s *Store) LLMUsageByJob(since time.Time) ([]LLMUsageTotal, error) {
+	return s.llmUsageTotals("job", since)
+}
+
+// LLMTokensOn 返回某一天的 token 总数
+func (s *Store) LLMTokensOn(day time.Time) (int, error) {
+	var total int
+	err := s.db.QueryRow(`SELECT COALESCE(SUM(prompt_tokens + completion_tokens), 0) FROM llm_usage WHERE day = ?`,
+		day.Format(dayLayout)).Scan(&total)
+	if err != nil {
+		return 0, fmt.Errorf("读取大模型用量失败: %w", err)
+	}
+	return total, nil
+}
+
+// llmUsageTotals 按 column（day 或 job）与模型汇总用量
+func (s *Store) llmUsageTotals(column string, since time.Time) ([]LLMUsageTotal, error) {
+	where, order := "day >= ?", "day DESC, provider, model"
+	if column == "job" {
+		where, order = "day >= ? AND job != ''", "SUM(prompt_tokens + completion_tokens) DESC, job"
+	}
+	// 列名来自固定的两个值，不是用户输入
+	rows, err := s.db.Query(`SELECT `+column+`, provider, model, SUM(requests), SUM(prompt_tokens), SUM(completion_tokens)
+		FROM llm_usage WHERE `+where+` GROUP BY `+column+`, provider, model ORDER BY `+order, since.Format(dayLayout))
+	if err != nil {
+		return nil, fmt.Errorf("读取大模型用量失败: %w", err)
+	}
+	defer rows.Close()
+
+	var totals []LLMUsageTotal
+	for rows.Next() {
+		var total LLMUsageTotal
+		if err := rows.Scan(&total.Key, &total.Provider, &total.Model, &total.Requests, &total.PromptTokens, &total.CompletionTokens); err != nil {
+			return nil, fmt.Errorf("读取大模型用量失败: %w", err)
+		}
+		totals = append(totals, total)
+	}
+	return totals, rows.Err()
+}
+
+// SaveEmbeddings 保存任务（文稿）的片段向量，替换该任务之前用同一模型计算的向量
+func (s *Store) SaveEmbeddings(job, model string, embeddings []Embedding) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("保存文稿向量失败: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM embeddings WHERE job = ? AND model = ?`, job, model); err != nil {
+		return fmt.Errorf("保存文稿向量失败: %w", err)
+	}
+	for i, embedding := range embeddings {
+		_, err := tx.Exec(`INSERT INTO embeddings (job, model, idx, start_time, end_time, text, vector) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			job, model, i, embedding.Start, embedding.End, embedding.Text, encodeVector(embedding.Vector))
+		if err != nil {
+			return fmt.Errorf("保存文稿向量失败: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("保存文稿向量失败: %w", err)
+	}
+	return nil
+}
+
+// Embeddings 返回任务（文稿）用 model 计算的片段向量，没有时返回 nil
+func (s *Store) Embeddings(job, model string) ([]Embedding, error) {
+	rows, err := s.db.Query(`SELECT start_time, end_time, text, vector FROM embeddings WHERE job = ? AND model = ? ORDER BY idx`, job, model)
+	if err != nil {
+		return nil, fmt.Errorf("读取文稿向量失败: %w", err)
+	}
+	defer rows.Close()
+
+	var embeddings []Embedding
+	for rows.Next() {
+		var embedding Embedding
+		var vector []byte
+		if err := rows.Scan(&embedding.Start, &embedding.End, &embedding.Text, &vector); err != nil {
+			return nil, fmt.Errorf("读取文稿向量失败: %w", err)
+		}
+		embedding.Vector = decodeVector(vector)
+		embeddings = append(embeddings, embedding)
+	}
+	return embeddings, rows.Err()
+}
+
+// encodeVector 将向量编码为小端序的 float32 序列
+func encodeVector(vector []float32) []byte {
+	data := make([]byte, 4*len(vector))
+	for i, value := range vector {
+		binary.LittleEndian.PutUint32(data[4*i:], math.Float32bits(value))
+	}
+	return data
+}
+
+// decodeVector 解码 encodeVector 编码的向量
+func decodeVector(data []byte) []float32 {
+	vector := make([]float32, len(data)/4)
+	for i := range vector {
+		vector[i] = math.Float32frombits(binary.LittleEndian.Uint32(data[4*i:]))
+	}
+	return vector
+}
@@ -0,0 +1,197 @@
+package store
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// openTemp 在临时目录打开数据库
+func openTemp(t *testing.T) (*Store, string) {
+	t.Helper()
+	path := Path(t.TempDir())
+	s, err := Open(path)
+	require.NoError(t, err)
+	t.Cleanup(func() { s.Close() })
+	return s, path
+}
+
+// TestMigrations 测试新建数据库执行全部迁移，再次打开时不重复执行
+func TestMigrations(t *testing.T) {
+	s, path := openTemp(t)
+	version, err := s.Version()
+	require.NoError(t, err)
+	assert.Equal(t, len(migrations), version)
+	require.NoError(t, s.Close())
+
+	reopened, err := Open(path)
+	require.NoError(t, err)
+	defer reopened.Close()
+	version, err = reopened.Version()
+	require.NoError(t, err)
+	assert.Equal(t, len(migrations), version)
+}
+
+// TestSaveRecords 测试写入、覆盖与删除处理记录
+func TestSaveRecords(t *testing.T) {
+	s, _ := openTemp(t)
+
+	require.NoError(t, s.SaveRecords([]Record{
+		{Path: "/media/a.mp4", Filename: "a.mp4", Data: json.RawMessage(`{"filename":"a.mp4"}`)},
+		{Path: "/media/b.mp4", Filename: "b.mp4", Completed: true, Service: "bcut", Data: json.RawMessage(`{"completed":true}`)},
+	}, nil))
+	require.NoError(t, s.SaveRecords([]Record{
+		{Path: "/media/a.mp4", Filename: "a.mp4", Completed: true, Status: "无音频", ContentHash: "12-ab", Data: json.RawMessage(`{"completed":true}`)},
+	}, []string{"/media/b.mp4"}))
+
+	records, err := s.Records()
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	record := records["/media/a.mp4"]
+	assert.True(t, record.Completed)
+	assert.Equal(t, "无音频", record.Status)
+	assert.Equal(t, "12-ab", record.ContentHash)
+	assert.JSONEq(t, `{"completed":true}`, string(record.Data))
+
+	_, err = s.Record("/media/b.mp4")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+// TestImportJSON 测试导入旧版 JSON 记录，数据库中已有的记录不被覆盖
+func TestImportJSON(t *testing.T) {
+	s, _ := openTemp(t)
+	require.NoError(t, s.SaveRecords([]Record{
+		{Path: "/media/a.mp4", Filename: "a.mp4", Service: "kuaishou", Data: json.RawMessage(`{"service":"kuaishou"}`)},
+	}, nil))
+
+	legacy := filepath.Join(t.TempDir(), "processed_records.json")
+	require.NoError(t, os.WriteFile(legacy, []byte(`{
+		"/media/a.mp4": {"filename": "a.mp4", "completed": true, "service": "bcut"},
+		"/media/c.mp4": {"completed": true, "service": "bcut", "last_processed_time": "2024-01-02 03:04:05",
+			"parts": {"part_1": {"completed": true}}}
+	}`), 0644))
+
+	imported, err := s.ImportJSON(legacy)
+	require.NoError(t, err)
+	assert.Equal(t, 1, imported)
+
+	kept, err := s.Record("/media/a.mp4")
+	require.NoError(t, err)
+	assert.Equal(t, "kuaishou", kept.Service)
+
+	record, err := s.Record("/media/c.mp4")
+	require.NoError(t, err)
+	assert.Equal(t, "c.mp4", record.Filename)
+	assert.True(t, record.Completed)
+	assert.Equal(t, "2024-01-02 03:04:05", record.LastProcessedTime)
+	assert.Contains(t, string(record.Data), "part_1")
+
+	_, err = s.ImportJSON(filepath.Join(t.TempDir(), "missing.json"))
+	assert.Error(t, err)
+}
+
+// TestBatches 测试保存批次结果并按时间倒序读取
+func TestBatches(t *testing.T) {
+	s, _ := openTemp(t)
+	for _, started := range []string{"2024-01-01 10:00:00", "2024-01-02 10:00:00"} {
+		_, err := s.AddBatch(Batch{
+			StartedAt:  started,
+			FinishedAt: started,
+			Total:      2,
+			Succeeded:  1,
+			Failed:     1,
+			Files: []BatchFile{
+				{File: "/media/b.mp4", Error: "识别失败", ErrorKind: "asr"},
+				{File: "/media/a.mp4", Success: true, Service: "bcut", Duration: 60, Outputs: map[string]string{"txt": "/out/a.txt"}},
+			},
+		})
+		require.NoError(t, err)
+	}
+
+	batches, err := s.Batches(1)
+	require.NoError(t, err)
+	require.Len(t, batches, 1)
+	assert.Equal(t, "2024-01-02 10:00:00", batches[0].StartedAt)
+	require.Len(t, batches[0].Files, 2)
+	assert.Equal(t, "/media/a.mp4", batches[0].Files[0].File)
+	assert.Equal(t, "/out/a.txt", batches[0].Files[0].Outputs["txt"])
+	assert.Equal(t, "识别失败", batches[0].Files[1].Error)
+	assert.Empty(t, batches[0].Files[1].Outputs)
+
+	all, err := s.Batches(0)
+	require.NoError(t, err)
+	assert.Len(t, all, 2)
+}
+
+// TestSegments 测试保存识别段落时替换之前的段落
+func TestSegments(t *testing.T) {
+	s, _ := openTemp(t)
+	require.NoError(t, s.SaveSegments("/media/a.mp4", []Segment{{Start: 0, End: 1, Text: "旧"}, {Start: 1, End: 2, Text: "旧"}}))
+	require.NoError(t, s.SaveSegments("/media/a.mp4", []Segment{{Start: 0.5, End: 1.5, Text: "大家好", Speaker: "A"}}))
+
+	segments, err := s.Segments("/media/a.mp4")
+	require.NoError(t, err)
+	assert.Equal(t, []Segment{{Start: 0.5, End: 1.5, Text: "大家好", Speaker: "A"}}, segments)
+
+	missing, err := s.Segments("/media/none.mp4")
+	require.NoError(t, err)
+	assert.Nil(t, missing)
+}
+
+// TestLLMUsage 测试按天与按任务汇总大模型用量
+func TestLLMUsage(t *testing.T) {
+	s, _ := openTemp(t)
+	day1 := time.Date(2026, 10, 1, 9, 0, 0, 0, time.Local)
+	day2 := time.Date(2026, 10, 2, 9, 0, 0, 0, time.Local)
+	for _, usage := range []LLMUsage{
+		{Time: day1, Job: "a.mp4", Provider: "openai", Model: "m", Requests: 1, PromptTokens: 100, CompletionTokens: 10},
+		{Time: day2, Job: "a.mp4", Provider: "openai", Model: "m", Requests: 1, PromptTokens: 200, CompletionTokens: 20},
+		{Time: day2, Job: "b.mp4", Provider: "openai", Model: "m", Requests: 1, PromptTokens: 1000, CompletionTokens: 100},
+		{Time: day2, Provider: "openai", Model: "m", Requests: 1, PromptTokens: 5, CompletionTokens: 5},
+	} {
+		require.NoError(t, s.AddLLMUsage(usage))
+	}
+
+	days, err := s.LLMUsageByDay(day1)
+	require.NoError(t, err)
+	assert.Equal(t, []LLMUsageTotal{
+		{Key: "2026-10-02", Provider: "openai", Model: "m", Requests: 3, PromptTokens: 1205, CompletionTokens: 125},
+		{Key: "2026-10-01", Provider: "openai", Model: "m", Requests: 1, PromptTokens: 100, CompletionTokens: 10},
+	}, days)
+
+	jobs, err := s.LLMUsageByJob(day1)
+	require.NoError(t, err)
+	require.Len(t, jobs, 2, "不属于任务的请求不计入")
+	assert.Equal(t, "b.mp4", jobs[0].Key)
+	assert.Equal(t, LLMUsageTotal{Key: "a.mp4", Provider: "openai", Model: "m", Requests: 2, PromptTokens: 300, CompletionTokens: 30}, jobs[1])
+
+	total, err := s.LLMTokensOn(day2)
+	require.NoError(t, err)
+	assert.Equal(t, 1330, total)
+}
+
+func TestEmbeddings(t *testing.T) {
+	s, _ := openTemp(t)
+	embeddings := []Embedding{
+		{Start: 0, End: 5, Text: "第一段", Vector: []float32{0.5, -1, 3.25}},
+		{Start: 5, End: 9, Text: "第二段", Vector: []float32{1, 0, 0}},
+	}
+	require.NoError(t, s.SaveEmbeddings("a", "openai/e", embeddings))
+	loaded, err := s.Embeddings("a", "openai/e")
+	require.NoError(t, err)
+	assert.Equal(t, embeddings, loaded)
+
+	// 同一模型重新保存时替换，不同模型互不影响
+	require.NoError(t, s.SaveEmbeddings("a", "openai/e", embeddings[:1]))
+	loaded, err = s.Embeddings("a", "openai/e")
+	require.NoError(t, err)
+	assert.Len(t, loaded, 1)
+	loaded, err = s.Embeddings("a", "ollama/e")
+	require.NoError(t, err)
+	assert.Empty(t, loaded)
+}
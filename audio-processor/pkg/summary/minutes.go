@@ -0,0 +1,142 @@
+package summary
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/utils"
+)
+
+// minutesFormat 会议纪要的返回格式
+const minutesFormat = "只返回如下格式的JSON，不要输出其他内容：\n" +
+	`{"summary": "总结", "action_items": [{"task": "待办事项", "owner": "负责人", "due": "截止时间"}], "qa": [{"question": "问题", "answer": "回答"}]}` + "\n" +
+	"待办事项只包含文稿中明确提到需要完成的工作，负责人或截止时间未提到时留空；问答只包含文稿中提出并得到回答的问题，没有时返回空数组。"
+
+// minutesPrompt 一次完成会议纪要的系统提示
+const minutesPrompt = "你是一个专业的会议记录员。下面是一段会议或讲座的识别文稿，请整理会议纪要：" +
+	"简明扼要地总结关键信息和主要观点，列出待办事项，并整理其中的问答。" + minutesFormat
+
+// minutesChunkPrompt 分段整理会议纪要的系统提示
+const minutesChunkPrompt = "你是一个专业的会议记录员。下面是一份长文稿的第 %d/%d 部分，请总结这一部分的关键信息与主要观点，" +
+	"并逐条列出其中的待办事项（任务、负责人、截止时间）以及提出的问题与回答，不要补充文稿中没有的内容。"
+
+// minutesReducePrompt 合并各段纪要的系统提示
+const minutesReducePrompt = "你是一个专业的会议记录员。下面是一份长文稿按顺序分段整理的纪要，" +
+	"请合并为一份完整的会议纪要：总结去除重复内容，待办事项与问答合并相同的条目。" + minutesFormat
+
+// minutesPrompts 整理会议纪要的提示
+var minutesPrompts = prompts{single: minutesPrompt, chunk: minutesChunkPrompt, reduce: minutesReducePrompt}
+
+// ActionItem 待办事项
+type ActionItem struct {
+	Task  string `json:"task"`
+	Owner string `json:"owner,omitempty"`
+	Due   string `json:"due,omitempty"`
+}
+
+// QA 文稿中的一组问答
+type QA struct {
+	Question string `json:"question"`
+	Answer   string `json:"answer"`
+}
+
+// Minutes 一个文件的摘要与会议纪要。ActionItems 与 QA 为 nil 时表示只生成了摘要
+type Minutes struct {
+	Summary     string       `json:"summary"`
+	ActionItems []ActionItem `json:"action_items"`
+	QA          []QA         `json:"qa"`
+}
+
+// GenerateMinutes 整理会议纪要：摘要、待办事项与问答，长文稿分段整理后合并。
+// 大模型未按格式返回时只保留回复作为摘要
+func GenerateMinutes(client LLM, text string, opts Options) (*Minutes, error) {
+	reply, err := summarize(context.Background(), client, text, opts, minutesPrompts, client.Chat)
+	if err != nil {
+		return nil, err
+	}
+	minutes, err := ParseMinutes(reply)
+	if err != nil {
+		utils.Warn("%v，只保存摘要", err)
+		return &Minutes{Summary: strings.TrimSpace(reply)}, nil
+	}
+	return minutes, nil
+}
+
+// ParseMinutes 解析大模型返回的会议纪要JSON，容忍代码块包裹与前后的说明文字，去掉空的条目
+func ParseMinutes(reply string) (*Minutes, error) {
+	start := strings.Index(reply, "{")
+	end := strings.LastIndex(reply, "}")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("大模型未返回JSON")
+	}
+	var parsed Minutes
+	if err := json.Unmarshal([]byte(reply[start:end+1]), &parsed); err != nil {
+		return nil, fmt.Errorf("解析会议纪要失败: %w", err)
+	}
+	minutes := &Minutes{
+		Summary:     strings.TrimSpace(parsed.Summary),
+		ActionItems: []ActionItem{},
+		QA:          []QA{},
+	}
+	if minutes.Summary == "" {
+		return nil, fmt.Errorf("大模型未返回总结")
+	}
+	for _, item := range parsed.ActionItems {
+		item.Task = strings.TrimSpace(item.Task)
+		if item.Task != "" {
+			item.Owner = strings.TrimSpace(item.Owner)
+			item.Due = strings.TrimSpace(item.Due)
+			minutes.ActionItems = append(minutes.ActionItems, item)
+		}
+	}
+	for _, qa := range parsed.QA {
+		qa.Question = strings.TrimSpace(qa.Question)
+		if qa.Question != "" {
+			qa.Answer = strings.TrimSpace(qa.Answer)
+			minutes.QA = append(minutes.QA, qa)
+		}
+	}
+	return minutes, nil
+}
+
+// Markdown 以 Markdown 输出，title 为文件名。只有摘要时不分节
+func (m *Minutes) Markdown(title string) string {
+	var b strings.Builder
+	if m.ActionItems == nil && m.QA == nil {
+		fmt.Fprintf(&b, "# %s 摘要\n\n%s\n", title, m.Summary)
+		return b.String()
+	}
+
+	fmt.Fprintf(&b, "# %s 会议纪要\n\n## 摘要\n\n%s\n\n## 待办事项\n\n", title, m.Summary)
+	if len(m.ActionItems) == 0 {
+		b.WriteString("无\n")
+	}
+	for _, item := range m.ActionItems {
+		var details []string
+		if item.Owner != "" {
+			details = append(details, "负责人："+item.Owner)
+		}
+		if item.Due != "" {
+			details = append(details, "截止："+item.Due)
+		}
+		if len(details) > 0 {
+			fmt.Fprintf(&b, "- [ ] %s（%s）\n", item.Task, strings.Join(details, "，"))
+		} else {
+			fmt.Fprintf(&b, "- [ ] %s\n", item.Task)
+		}
+	}
+
+	b.WriteString("\n## 问答\n\n")
+	if len(m.QA) == 0 {
+		b.WriteString("无\n")
+	}
+	for i, qa := range m.QA {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "**问：** %s\n\n**答：** %s\n", qa.Question, qa.Answer)
+	}
+	return b.String()
+}
@@ -0,0 +1,195 @@
+// Package summary 由大模型为文稿生成摘要。文稿超过一段的长度时先分段摘要（map），
+// 再将各段摘要合并为完整的总结（reduce），合并后仍然过长时继续分段合并，
+// 使超出模型上下文长度的长文稿也能完整总结
+package summary
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/llm"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/models"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/utils"
+)
+
+// chunkPrompt 分段摘要的系统提示
+const chunkPrompt = "你是一个专业的文字总结助手。下面是一份长文稿的第 %d/%d 部分，请总结这一部分的关键信息与主要观点，" +
+	"保留人名、数字、结论与待办事项，不要补充文稿中没有的内容。"
+
+// reducePrompt 合并各段摘要的系统提示
+const reducePrompt = "你是一个专业的文字总结助手。下面是一份长文稿按顺序分段得到的摘要，" +
+	"请将它们合并为一份完整、连贯的总结，去除重复内容，提取关键信息和主要观点。"
+
+// prompts 一次完成、分段与合并时使用的系统提示，chunk 包含段号与段数两个 %d
+type prompts struct {
+	single string
+	chunk  string
+	reduce string
+}
+
+// summaryPrompts 生成摘要的提示
+var summaryPrompts = prompts{single: llm.SummaryPrompt, chunk: chunkPrompt, reduce: reducePrompt}
+
+// defaultChunkSize 未配置时每段文稿的最大字符数
+const defaultChunkSize = 12000
+
+// maxRounds 合并的最大轮数，摘要未能缩短时不再继续分段
+const maxRounds = 4
+
+// LLM 生成摘要的大模型接口
+type LLM interface {
+	Chat(systemPrompt, content string) (string, error)
+}
+
+// StreamLLM 支持流式回复的大模型接口，由 llm.LLMClient 实现
+type StreamLLM interface {
+	LLM
+	ChatStream(ctx context.Context, systemPrompt, content string, onDelta func(string)) (string, error)
+}
+
+// Options 摘要选项
+type Options struct {
+	ChunkSize int // 每段文稿的最大字符数
+	Overlap   int // 相邻两段重叠的字符数
+}
+
+// OptionsFromConfig 从配置读取摘要选项
+func OptionsFromConfig(config *models.Config) Options {
+	return Options{ChunkSize: config.SummaryChunkSize, Overlap: config.SummaryChunkOverlap}
+}
+
+// normalize 补全未设置或无效的选项
+func (o Options) normalize() Options {
+	if o.ChunkSize <= 0 {
+		o.ChunkSize = defaultChunkSize
+	}
+	if o.Overlap < 0 || o.Overlap >= o.ChunkSize/2 {
+		o.Overlap = 0
+	}
+	return o
+}
+
+// Text 将识别段落拼接为摘要使用的文稿，跳过无法识别的片段
+func Text(segments []models.DataSegment) string {
+	var lines []string
+	for _, segment := range segments {
+		text := strings.TrimSpace(segment.Text)
+		if text == "" || text == "[无法识别的音频片段]" {
+			continue
+		}
+		lines = append(lines, text)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Summarize 为文稿生成摘要，超过一段长度时分段摘要后合并
+func Summarize(client LLM, text string, opts Options) (string, error) {
+	return summarize(context.Background(), client, text, opts, summaryPrompts, client.Chat)
+}
+
+// SummarizeStream 与 Summarize 相同，最后一次请求（合并或一次完成的总结）以流式返回，
+// 每收到一段文本调用一次 onDelta
+func SummarizeStream(ctx context.Context, client StreamLLM, text string, opts Options, onDelta func(string)) (string, error) {
+	return summarize(ctx, client, text, opts, summaryPrompts, func(systemPrompt, content string) (string, error) {
+		return client.ChatStream(ctx, systemPrompt, content, onDelta)
+	})
+}
+
+// summarize 分段摘要直到合并后的内容不超过一段，再用 final 生成最终的总结，ctx 取消时停止分段摘要
+func summarize(ctx context.Context, client LLM, text string, opts Options, p prompts, final func(systemPrompt, content string) (string, error)) (string, error) {
+	opts = opts.normalize()
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return "", fmt.Errorf("文稿内容为空")
+	}
+
+	prompt := p.single
+	for round := 1; ; round++ {
+		chunks := Split(text, opts.ChunkSize, opts.Overlap)
+		if len(chunks) == 1 || round > maxRounds {
+			if len(chunks) > 1 {
+				utils.Warn("合并 %d 轮后摘要仍超过 %d 字，截断后生成总结", maxRounds, opts.ChunkSize)
+				text = chunks[0]
+			}
+			return final(prompt, text)
+		}
+
+		utils.Info("文稿共 %d 字，分为 %d 段摘要（第 %d 轮）", len([]rune(text)), len(chunks), round)
+		partials := make([]string, 0, len(chunks))
+		for i, chunk := range chunks {
+			if err := ctx.Err(); err != nil {
+				return "", err
+			}
+			partial, err := client.Chat(fmt.Sprintf(p.chunk, i+1, len(chunks)), chunk)
+			if err != nil {
+				return "", fmt.Errorf("第 %d/%d 段摘要失败: %w", i+1, len(chunks), err)
+			}
+			partials = append(partials, fmt.Sprintf("【第 %d 部分】\n%s", i+1, strings.TrimSpace(partial)))
+		}
+		text = strings.Join(partials, "\n\n")
+		prompt = p.reduce
+	}
+}
+
+// Split 将文稿按 size 个字符分段，相邻两段重叠 overlap 个字符。
+// 分段处优先选在段落末尾，其次是句末标点，避免切断句子
+func Split(text string, size, overlap int) []string {
+	runes := []rune(text)
+	if size <= 0 || len(runes) <= size {
+		return []string{text}
+	}
+	if overlap < 0 || overlap >= size/2 {
+		overlap = 0
+	}
+
+	var chunks []string
+	for start := 0; start < len(runes); {
+		end := start + size
+		if end >= len(runes) {
+			chunks = append(chunks, string(runes[start:]))
+			break
+		}
+		end = breakPoint(runes, start+size/2, end)
+		chunks = append(chunks, string(runes[start:end]))
+		start = end - overlap
+	}
+	return chunks
+}
+
+// breakPoint 在 [min, end) 中从后往前寻找分段位置：先找换行，再找句末标点，都没有时在 end 处切断
+func breakPoint(runes []rune, min, end int) int {
+	for i := end - 1; i >= min; i-- {
+		if runes[i] == '\n' {
+			return i + 1
+		}
+	}
+	for i := end - 1; i >= min; i-- {
+		switch runes[i] {
+		case '。', '！', '？', '.', '!', '?', '；', ';':
+			return i + 1
+		}
+	}
+	return end
+}
+
+// Summarizer 以分段合并的方式生成摘要，满足 Web 接口使用的摘要接口
+type Summarizer struct {
+	LLM     StreamLLM
+	Options Options
+}
+
+// New 创建摘要生成器
+func New(client StreamLLM, opts Options) *Summarizer {
+	return &Summarizer{LLM: client, Options: opts}
+}
+
+// GenerateSummary 生成摘要
+func (s *Summarizer) GenerateSummary(content string) (string, error) {
+	return Summarize(s.LLM, content, s.Options)
+}
+
+// GenerateSummaryStream 生成摘要，最终的总结以流式返回
+func (s *Summarizer) GenerateSummaryStream(ctx context.Context, content string, onDelta func(string)) (string, error) {
+	return SummarizeStream(ctx, s.LLM, content, s.Options, onDelta)
+}
@@ -0,0 +1,125 @@
+package summary
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/llm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeLLM 记录每次请求，分段摘要返回固定的短文本
+type fakeLLM struct {
+	prompts  []string
+	contents []string
+	streamed bool
+}
+
+func (f *fakeLLM) Chat(systemPrompt, content string) (string, error) {
+	f.prompts = append(f.prompts, systemPrompt)
+	f.contents = append(f.contents, content)
+	return fmt.Sprintf("摘要%d", len(f.prompts)), nil
+}
+
+func (f *fakeLLM) ChatStream(ctx context.Context, systemPrompt, content string, onDelta func(string)) (string, error) {
+	f.streamed = true
+	reply, err := f.Chat(systemPrompt, content)
+	onDelta(reply)
+	return reply, err
+}
+
+func TestSplit(t *testing.T) {
+	assert.Equal(t, []string{"短文"}, Split("短文", 10, 2))
+
+	// 优先在换行处分段，相邻两段重叠
+	text := "第一句话。第二句\n第三句话。第四句话。"
+	chunks := Split(text, 12, 2)
+	require.Len(t, chunks, 2)
+	assert.Equal(t, "第一句话。第二句\n", chunks[0])
+	assert.Equal(t, "句\n第三句话。第四句话。", chunks[1])
+
+	// 没有合适的分段位置时按长度切断，全部内容都被覆盖
+	long := strings.Repeat("字", 25)
+	chunks = Split(long, 10, 3)
+	assert.Equal(t, []string{strings.Repeat("字", 10), strings.Repeat("字", 10), strings.Repeat("字", 10), strings.Repeat("字", 4)}, chunks)
+}
+
+func TestSummarize(t *testing.T) {
+	client := &fakeLLM{}
+	result, err := Summarize(client, "简短的文稿", Options{ChunkSize: 100})
+	require.NoError(t, err)
+	assert.Equal(t, "摘要1", result)
+	assert.Equal(t, []string{llm.SummaryPrompt}, client.prompts, "未超出长度时一次完成")
+
+	client = &fakeLLM{}
+	text := strings.Repeat("这是一句话。", 30) // 180 字
+	result, err = Summarize(client, text, Options{ChunkSize: 100, Overlap: 10})
+	require.NoError(t, err)
+	require.Len(t, client.prompts, 3, "两段摘要加一次合并")
+	assert.Contains(t, client.prompts[0], "第 1/2 部分")
+	assert.Equal(t, reducePrompt, client.prompts[2])
+	assert.Equal(t, "【第 1 部分】\n摘要1\n\n【第 2 部分】\n摘要2", client.contents[2])
+	assert.Equal(t, "摘要3", result)
+
+	_, err = Summarize(client, "  \n", Options{})
+	assert.Error(t, err)
+}
+
+// TestSummarizeStream 测试只有最后的合并以流式返回
+func TestSummarizeStream(t *testing.T) {
+	client := &fakeLLM{}
+	var deltas []string
+	result, err := New(client, Options{ChunkSize: 100}).GenerateSummaryStream(context.Background(), strings.Repeat("这是一句话。", 30), func(delta string) {
+		deltas = append(deltas, delta)
+	})
+	require.NoError(t, err)
+	assert.True(t, client.streamed)
+	assert.Equal(t, []string{"摘要3"}, deltas)
+	assert.Equal(t, "摘要3", result)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = SummarizeStream(ctx, &fakeLLM{}, strings.Repeat("这是一句话。", 30), Options{ChunkSize: 100}, func(string) {})
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+// replyLLM 返回固定回复
+type replyLLM struct {
+	reply   string
+	prompts []string
+}
+
+func (r *replyLLM) Chat(systemPrompt, content string) (string, error) {
+	r.prompts = append(r.prompts, systemPrompt)
+	return r.reply, nil
+}
+
+// TestGenerateMinutes 测试整理会议纪要并导出为 Markdown，大模型未按格式返回时只保留摘要
+func TestGenerateMinutes(t *testing.T) {
+	client := &replyLLM{reply: "好的：\n```json\n" + `{"summary": "讨论了发布计划。", "action_items": [{"task": "准备发布说明", "owner": "小王", "due": "周五"}, {"task": " "}, {"task": "更新文档"}], "qa": [{"question": "什么时候发布？", "answer": "下周一。"}]}` + "\n```"}
+	minutes, err := GenerateMinutes(client, "会议文稿", Options{ChunkSize: 100})
+	require.NoError(t, err)
+	assert.Equal(t, []string{minutesPrompt}, client.prompts)
+	assert.Equal(t, "# 周会 会议纪要\n\n## 摘要\n\n讨论了发布计划。\n\n## 待办事项\n\n"+
+		"- [ ] 准备发布说明（负责人：小王，截止：周五）\n- [ ] 更新文档\n\n"+
+		"## 问答\n\n**问：** 什么时候发布？\n\n**答：** 下周一。\n", minutes.Markdown("周会"))
+
+	minutes, err = GenerateMinutes(&replyLLM{reply: `{"summary": "只有总结"}`}, "会议文稿", Options{})
+	require.NoError(t, err)
+	assert.Contains(t, minutes.Markdown("周会"), "## 待办事项\n\n无\n\n## 问答\n\n无\n")
+
+	minutes, err = GenerateMinutes(&replyLLM{reply: "没有按格式返回的总结"}, "会议文稿", Options{})
+	require.NoError(t, err)
+	assert.Equal(t, "# 周会 摘要\n\n没有按格式返回的总结\n", minutes.Markdown("周会"))
+
+	// 长文稿分段整理后按纪要格式合并
+	client = &replyLLM{reply: `{"summary": "总结"}`}
+	_, err = GenerateMinutes(client, strings.Repeat("这是一句话。", 30), Options{ChunkSize: 100})
+	require.NoError(t, err)
+	require.Len(t, client.prompts, 3)
+	assert.Contains(t, client.prompts[0], "会议记录员")
+	assert.Equal(t, minutesReducePrompt, client.prompts[2])
+}
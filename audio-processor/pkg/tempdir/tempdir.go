@@ -0,0 +1,228 @@
+package tempdir
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+	"unicode"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/models"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/utils"
+)
+
+// 任务目录前缀与记录所属进程的文件名
+const (
+	jobPrefix = "job-"
+	ownerFile = ".owner"
+)
+
+// session 标识当前进程，容器中重启后进程号可能与之前相同，需要同时比对
+var session = strconv.FormatInt(time.Now().UnixNano(), 36)
+
+// DefaultRoot 未配置 temp_dir 时使用的临时根目录。
+// 使用固定位置，进程异常退出后重启时才能找到遗留的任务目录。
+func DefaultRoot() string {
+	return filepath.Join(os.TempDir(), "audio-processor")
+}
+
+// Manager 统一管理临时目录：为每个任务分配独立的子目录，记录并负责清理
+type Manager struct {
+	root string
+
+	mu   sync.Mutex
+	jobs map[string]*Job
+}
+
+// NewManager 创建临时目录管理器，root 为空时使用 DefaultRoot
+func NewManager(root string) (*Manager, error) {
+	if root == "" {
+		root = DefaultRoot()
+	}
+	if err := os.MkdirAll(root, 0755); err != nil {
+		return nil, fmt.Errorf("创建临时目录失败: %w", err)
+	}
+	return &Manager{
+		root: root,
+		jobs: make(map[string]*Job),
+	}, nil
+}
+
+// FromConfig 按配置中的 temp_dir 创建管理器
+func FromConfig(config *models.Config) (*Manager, error) {
+	return NewManager(config.TempDir)
+}
+
+// Root 返回临时根目录
+func (m *Manager) Root() string {
+	return m.root
+}
+
+// Allocate 为任务分配独立的临时子目录，name 仅用于便于辨认目录
+func (m *Manager) Allocate(name string) (*Job, error) {
+	dir, err := os.MkdirTemp(m.root, jobPrefix+sanitize(name)+"-")
+	if err != nil {
+		return nil, fmt.Errorf("创建任务临时目录失败: %w", err)
+	}
+	// 记录所属进程，重启后据此判断目录是否已被遗弃
+	owner := filepath.Join(dir, ownerFile)
+	if err := os.WriteFile(owner, []byte(fmt.Sprintf("%d %s", os.Getpid(), session)), 0644); err != nil {
+		os.RemoveAll(dir)
+		return nil, fmt.Errorf("写入临时目录所属进程失败: %w", err)
+	}
+
+	job := &Job{Dir: dir, manager: m}
+	m.mu.Lock()
+	m.jobs[dir] = job
+	m.mu.Unlock()
+	utils.Debug("分配临时目录: %s", dir)
+	return job, nil
+}
+
+// Active 返回尚未释放的任务目录数
+func (m *Manager) Active() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.jobs)
+}
+
+// Cleanup 释放所有尚未释放的任务目录，用于程序退出或取消时
+func (m *Manager) Cleanup() {
+	m.mu.Lock()
+	jobs := make([]*Job, 0, len(m.jobs))
+	for _, job := range m.jobs {
+		jobs = append(jobs, job)
+	}
+	m.mu.Unlock()
+
+	for _, job := range jobs {
+		if err := job.Release(); err != nil {
+			utils.Warn("%v", err)
+		}
+	}
+}
+
+// RecoverStale 清理之前异常退出的进程遗留的任务目录，返回清理的目录数。
+// 所属进程仍在运行的目录（例如同时运行的其他实例）保持不变。
+func (m *Manager) RecoverStale() (int, error) {
+	entries, err := os.ReadDir(m.root)
+	if err != nil {
+		return 0, fmt.Errorf("读取临时目录失败: %w", err)
+	}
+
+	removed := 0
+	for _, entry := range entries {
+		if !entry.IsDir() || !strings.HasPrefix(entry.Name(), jobPrefix) {
+			continue
+		}
+		dir := filepath.Join(m.root, entry.Name())
+
+		if data, err := os.ReadFile(filepath.Join(dir, ownerFile)); err == nil && ownerAlive(string(data)) {
+			continue
+		}
+
+		if err := os.RemoveAll(dir); err != nil {
+			utils.Warn("清理遗留临时目录失败: %v", err)
+			continue
+		}
+		removed++
+	}
+	if removed > 0 {
+		utils.Info("已清理 %d 个遗留的临时目录", removed)
+	}
+	return removed, nil
+}
+
+// Job 一个任务独占的临时目录
+type Job struct {
+	Dir string
+
+	manager *Manager
+	once    sync.Once
+	err     error
+}
+
+// Path 返回任务目录下的路径
+func (j *Job) Path(elem ...string) string {
+	return filepath.Join(append([]string{j.Dir}, elem...)...)
+}
+
+// MkdirAll 在任务目录下创建子目录并返回其路径
+func (j *Job) MkdirAll(elem ...string) (string, error) {
+	dir := j.Path(elem...)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("创建临时子目录失败: %w", err)
+	}
+	return dir, nil
+}
+
+// Release 删除任务目录，可重复调用，nil 任务不做任何事
+func (j *Job) Release() error {
+	if j == nil {
+		return nil
+	}
+	j.once.Do(func() {
+		if err := os.RemoveAll(j.Dir); err != nil {
+			j.err = fmt.Errorf("删除临时目录失败: %w", err)
+		}
+		j.manager.mu.Lock()
+		delete(j.manager.jobs, j.Dir)
+		j.manager.mu.Unlock()
+	})
+	return j.err
+}
+
+// sanitize 将任务名称转换为可用作目录名的形式
+func sanitize(name string) string {
+	name = strings.TrimSuffix(filepath.Base(name), filepath.Ext(name))
+	var b strings.Builder
+	for _, r := range name {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_' || r == '.' {
+			b.WriteRune(r)
+		} else {
+			b.WriteRune('_')
+		}
+		if b.Len() >= 32 {
+			break
+		}
+	}
+	if b.Len() == 0 {
+		return "task"
+	}
+	return b.String()
+}
+
+// ownerAlive 判断记录的所属进程是否仍在运行
+func ownerAlive(owner string) bool {
+	fields := strings.Fields(owner)
+	if len(fields) != 2 {
+		return false
+	}
+	pid, err := strconv.Atoi(fields[0])
+	if err != nil || pid <= 0 {
+		return false
+	}
+	if pid == os.Getpid() {
+		return fields[1] == session
+	}
+	return processAlive(pid)
+}
+
+// processAlive 判断进程是否仍在运行
+func processAlive(pid int) bool {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	if runtime.GOOS == "windows" {
+		// Windows 下 FindProcess 会打开进程句柄，进程不存在时返回错误
+		process.Release()
+		return true
+	}
+	return process.Signal(syscall.Signal(0)) == nil
+}
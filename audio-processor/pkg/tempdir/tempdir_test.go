@@ -0,0 +1,66 @@
+package tempdir
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAllocateAndRelease(t *testing.T) {
+	m, err := NewManager(t.TempDir())
+	require.NoError(t, err)
+
+	a, err := m.Allocate("/media/会议 录音.mp3")
+	require.NoError(t, err)
+	b, err := m.Allocate("/other/会议 录音.mp3")
+	require.NoError(t, err)
+	assert.NotEqual(t, a.Dir, b.Dir)
+	assert.Contains(t, filepath.Base(a.Dir), "job-会议_录音-")
+	assert.Equal(t, 2, m.Active())
+
+	segments, err := a.MkdirAll("segments")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(segments, "part001.wav"), []byte("x"), 0644))
+
+	require.NoError(t, a.Release())
+	require.NoError(t, a.Release())
+	assert.NoDirExists(t, a.Dir)
+	assert.Equal(t, 1, m.Active())
+
+	m.Cleanup()
+	assert.NoDirExists(t, b.Dir)
+	assert.Equal(t, 0, m.Active())
+}
+
+func TestRecoverStale(t *testing.T) {
+	root := t.TempDir()
+	m, err := NewManager(root)
+	require.NoError(t, err)
+
+	live, err := m.Allocate("live")
+	require.NoError(t, err)
+
+	// 之前运行的进程遗留的目录：进程号相同但会话不同（容器重启），以及未写入所属进程的目录
+	restarted := filepath.Join(root, "job-restarted-1")
+	require.NoError(t, os.MkdirAll(restarted, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(restarted, ownerFile),
+		[]byte(fmt.Sprintf("%d old", os.Getpid())), 0644))
+	unowned := filepath.Join(root, "job-unowned-2")
+	require.NoError(t, os.MkdirAll(unowned, 0755))
+
+	// 不属于管理器的目录保持不变
+	other := filepath.Join(root, "other")
+	require.NoError(t, os.MkdirAll(other, 0755))
+
+	removed, err := m.RecoverStale()
+	require.NoError(t, err)
+	assert.DirExists(t, live.Dir)
+	assert.DirExists(t, other)
+	assert.NoDirExists(t, restarted)
+	assert.NoDirExists(t, unowned)
+	assert.Equal(t, 2, removed)
+}
@@ -0,0 +1,94 @@
+package textproc
+
+import (
+	"strings"
+)
+
+// charPairs 常用简繁一一对应的字（每项为"简繁"），双向转换均使用
+const charPairs = `这這 个個 们們 来來 时時 为為 说說 国國 对對 会會 学學 过過 还還 没沒 开開
+经經 关關 问問 应應 点點 现現 进進 动動 样樣 种種 与與 从從 边邊 长長 间間
+头頭 题題 实實 给給 让讓 认認 体體 条條 数數 义義 话話 语語 读讀 书書 写寫
+记記 门門 见見 车車 东東 马馬 鸟鳥 鱼魚 风風 飞飛 龙龍 爱愛 听聽 乐樂 买買
+卖賣 亲親 观觀 电電 脑腦 网網 络絡 视視 频頻 录錄 声聲 员員 师師 费費 钱錢
+银銀 谁誰 请請 谢謝 难難 欢歡 场場 报報 纸紙 节節 级級 结結 统統 总總 计計
+论論 证證 设設 该該 讲講 选選 运運 远遠 连連 办辦 务務 华華 单單 广廣 无無
+业業 两兩 严嚴 临臨 丽麗 举舉 么麼 习習 乡鄉 乱亂 争爭 亏虧 亚亞 产產 亿億
+仅僅 价價 众眾 优優 伟偉 传傳 伤傷 债債 儿兒 党黨 兰蘭 兴興 养養 内內 军軍
+农農 决決 况況 净淨 凉涼 减減 击擊 刘劉 则則 刚剛 创創 删刪 别別 剧劇 劝勸
+励勵 劳勞 势勢 区區 医醫 协協 卫衛 却卻 厅廳 压壓 县縣 参參 双雙 变變 叶葉
+号號 叹嘆 吗嗎 启啟 响響 园園 围圍 图圖 圆圓 圣聖 坏壞 块塊 坚堅 执執 扩擴
+扫掃 扬揚 护護 担擔 拥擁 择擇 挂掛 挤擠 挥揮 损損 换換 摄攝 摆擺 旧舊 显顯
+晓曉 暂暫 机機 杀殺 杂雜 权權 极極 构構 枪槍 标標 树樹 桥橋 梦夢 检檢 欧歐
+岁歲 毕畢 气氣 汇匯 汉漢 汤湯 沟溝 泪淚 洁潔 浅淺 测測 济濟 浓濃 润潤 涨漲
+渐漸 温溫 湾灣 满滿 灭滅 灯燈 灵靈 灾災 炉爐 烟煙 烦煩 烧燒 热熱 爷爺 犹猶
+状狀 独獨 猫貓 环環 画畫 疗療 监監 盘盤 码碼 确確 礼禮 称稱 积積 稳穩 穷窮
+笔筆 签簽 简簡 类類 红紅 约約 级級 纪紀 线線 练練 组組 细細 终終 经經
+给給 绝絕 继繼 绩績 续續 维維 综綜 罗羅 职職 联聯 肠腸 肤膚 胜勝
+脸臉 舰艦 艺藝 节節 苏蘇 药藥 获獲 虽雖 补補 装裝 规規 觉覺 览覽 订訂 认認
+讨討 让讓 训訓 议議 许許 论論 识識 词詞 译譯 试試 诚誠 详詳 误誤 说說 调調
+谈談 谱譜 贝貝 负負 贡貢 财財 责責 败敗 货貨 质質 购購 贵貴 贸貿 资資 赛賽
+赶趕 车車 轮輪 软軟 转轉 轻輕 较較 辑輯 输輸 边邊 达達 迁遷 过過 还還 这這
+进進 远遠 违違 连連 迟遲 适適 选選 递遞 遗遺 邮郵 邻鄰 郑鄭 酱醬 释釋 钢鋼
+铁鐵 链鏈 销銷 锁鎖 错錯 键鍵 镇鎮 长長 门門 闭閉 问問 闲閒 间間 闻聞 阅閱
+队隊 阳陽 阴陰 阵陣 际際 陆陸 险險 随隨 隐隱 难難 雾霧 静靜 页頁 项項 顺順
+须須 顾顧 顿頓 预預 领領 频頻 题題 颜顏 额額 风風 饭飯 饮飲 饱飽 馆館 驾駕
+验驗 鲜鮮 鸡雞 麦麥 黄黃 齐齊 齿齒`
+
+// traditionalOnly 多个繁体字对应同一个简体字时的转换（每项为"简繁"），只用于繁转简
+const traditionalOnly = `发發 发髮 后後 干乾 干幹 里裡 里裏 面麵 台臺 台颱 余餘 冲衝 划劃 制製
+历歷 历曆 据據 只隻 准準 复復 复複 松鬆 钟鐘 钟鍾 系係 系繫 斗鬥 谷穀 丑醜
+表錶 范範 几幾 云雲 团糰 团團 汇彙 蒙濛 蒙懞 蒙矇 尽盡 尽儘 脏髒 脏臟`
+
+// ChineseConverter 简繁转换，仅使用内置常用字表，按字逐一转换
+type ChineseConverter struct {
+	toTraditional bool
+	replacer      *strings.Replacer
+}
+
+// NewChineseConverter 创建简繁转换器，toTraditional 为 true 时简转繁，否则繁转简
+func NewChineseConverter(toTraditional bool) *ChineseConverter {
+	var oldnew []string
+	seen := make(map[string]bool)
+	add := func(from, to string) {
+		if seen[from] || from == to {
+			return
+		}
+		seen[from] = true
+		oldnew = append(oldnew, from, to)
+	}
+
+	for _, pair := range strings.Fields(charPairs) {
+		r := []rune(pair)
+		simplified, traditional := string(r[0]), string(r[1])
+		if toTraditional {
+			add(simplified, traditional)
+		} else {
+			add(traditional, simplified)
+		}
+	}
+	if !toTraditional {
+		// 一简对多繁的字只能安全地从繁体转为简体
+		for _, pair := range strings.Fields(traditionalOnly) {
+			r := []rune(pair)
+			add(string(r[1]), string(r[0]))
+		}
+	}
+
+	return &ChineseConverter{
+		toTraditional: toTraditional,
+		replacer:      strings.NewReplacer(oldnew...),
+	}
+}
+
+// Name 返回步骤名称
+func (c *ChineseConverter) Name() string {
+	if c.toTraditional {
+		return StepToTraditional
+	}
+	return StepToSimplified
+}
+
+// Process 转换文本
+func (c *ChineseConverter) Process(text string) string {
+	return c.replacer.Replace(text)
+}
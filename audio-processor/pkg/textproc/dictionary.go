@@ -0,0 +1,140 @@
+package textproc
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// ProfanityFilter 敏感词过滤，将命中的词逐字替换为 *
+type ProfanityFilter struct {
+	replacer *strings.Replacer
+}
+
+// NewProfanityFilter 根据词表创建过滤器
+func NewProfanityFilter(words []string) *ProfanityFilter {
+	// 长词优先，避免短词先命中导致长词只被部分替换
+	sorted := append([]string(nil), words...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return utf8.RuneCountInString(sorted[i]) > utf8.RuneCountInString(sorted[j])
+	})
+
+	oldnew := make([]string, 0, len(sorted)*2)
+	for _, word := range sorted {
+		if word == "" {
+			continue
+		}
+		oldnew = append(oldnew, word, strings.Repeat("*", utf8.RuneCountInString(word)))
+	}
+	return &ProfanityFilter{replacer: strings.NewReplacer(oldnew...)}
+}
+
+// LoadProfanityFilter 从词表文件创建过滤器，每行一个词，# 开头为注释
+func LoadProfanityFilter(path string) (*ProfanityFilter, error) {
+	if path == "" {
+		return nil, fmt.Errorf("未设置敏感词词表文件")
+	}
+	var words []string
+	err := readDictionary(path, func(line string, lineNum int) error {
+		words = append(words, strings.TrimSpace(line))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return NewProfanityFilter(words), nil
+}
+
+// Name 返回步骤名称
+func (f *ProfanityFilter) Name() string { return StepProfanity }
+
+// Process 过滤文本中的敏感词
+func (f *ProfanityFilter) Process(text string) string {
+	return f.replacer.Replace(text)
+}
+
+// Replacer 自定义查找替换，用于修正专有名词、常见误识别等
+type Replacer struct {
+	replacer *strings.Replacer
+}
+
+// NewReplacer 根据查找/替换对创建替换器，较长的查找词优先匹配
+func NewReplacer(pairs [][2]string) *Replacer {
+	sorted := append([][2]string(nil), pairs...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return utf8.RuneCountInString(sorted[i][0]) > utf8.RuneCountInString(sorted[j][0])
+	})
+
+	oldnew := make([]string, 0, len(sorted)*2)
+	for _, pair := range sorted {
+		oldnew = append(oldnew, pair[0], pair[1])
+	}
+	return &Replacer{replacer: strings.NewReplacer(oldnew...)}
+}
+
+// LoadReplacer 从字典文件创建替换器。
+// 每行一条规则，格式为 "查找 => 替换" 或以制表符分隔；替换内容可以为空，表示删除。# 开头为注释。
+func LoadReplacer(path string) (*Replacer, error) {
+	if path == "" {
+		return nil, fmt.Errorf("未设置替换字典文件")
+	}
+	var pairs [][2]string
+	err := readDictionary(path, func(line string, lineNum int) error {
+		var from, to string
+		if i := strings.Index(line, "=>"); i >= 0 {
+			from, to = line[:i], line[i+2:]
+		} else if i := strings.Index(line, "\t"); i >= 0 {
+			from, to = line[:i], line[i+1:]
+		} else {
+			return fmt.Errorf("第 %d 行格式错误，应为 \"查找 => 替换\"", lineNum)
+		}
+		from, to = strings.TrimSpace(from), strings.TrimSpace(to)
+		if from == "" {
+			return fmt.Errorf("第 %d 行缺少查找内容", lineNum)
+		}
+		pairs = append(pairs, [2]string{from, to})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return NewReplacer(pairs), nil
+}
+
+// Name 返回步骤名称
+func (r *Replacer) Name() string { return StepReplace }
+
+// Process 按字典替换文本
+func (r *Replacer) Process(text string) string {
+	return r.replacer.Replace(text)
+}
+
+// readDictionary 逐行读取字典文件，跳过空行与 # 开头的注释
+func readDictionary(path string, fn func(line string, lineNum int) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("打开字典文件失败: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		// 保留行尾制表符，"查找\t" 表示删除
+		line := strings.Trim(scanner.Text(), " \r")
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if err := fn(line, lineNum); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("读取字典文件失败: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package textproc
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/models"
+)
+
+// 处理步骤名称，用于配置 text_pipeline
+const (
+	StepPunctuation   = "punctuation" // 标点恢复
+	StepToTraditional = "s2t"         // 简体转繁体
+	StepToSimplified  = "t2s"         // 繁体转简体
+	StepProfanity     = "profanity"   // 敏感词过滤
+	StepReplace       = "replace"     // 自定义查找替换
+)
+
+// Step 文本后处理步骤
+type Step interface {
+	Name() string
+	Process(text string) string
+}
+
+// Pipeline 按顺序执行的文本后处理流水线。nil 流水线不做任何处理。
+type Pipeline struct {
+	steps []Step
+}
+
+// NewPipeline 创建流水线
+func NewPipeline(steps ...Step) *Pipeline {
+	return &Pipeline{steps: steps}
+}
+
+// Steps 返回流水线中各步骤的名称
+func (p *Pipeline) Steps() []string {
+	if p == nil {
+		return nil
+	}
+	names := make([]string, len(p.steps))
+	for i, step := range p.steps {
+		names[i] = step.Name()
+	}
+	return names
+}
+
+// Apply 对文本依次执行所有步骤
+func (p *Pipeline) Apply(text string) string {
+	if p == nil {
+		return text
+	}
+	for _, step := range p.steps {
+		text = step.Process(text)
+	}
+	return text
+}
+
+// ApplySegments 对每个段落的文本执行流水线，返回新的段落列表。
+// 逐词时间戳保持不变；无法识别的占位段落不做处理。
+func (p *Pipeline) ApplySegments(segments []models.DataSegment) []models.DataSegment {
+	if p == nil || len(p.steps) == 0 {
+		return segments
+	}
+	processed := make([]models.DataSegment, len(segments))
+	for i, segment := range segments {
+		processed[i] = segment
+		if strings.TrimSpace(segment.Text) == "" || segment.Text == "[无法识别的音频片段]" {
+			continue
+		}
+		processed[i].Text = p.Apply(segment.Text)
+	}
+	return processed
+}
+
+// FromConfig 按配置中的 text_pipeline 构建流水线，未配置任何步骤时返回 nil
+func FromConfig(config *models.Config) (*Pipeline, error) {
+	if len(config.TextPipeline) == 0 {
+		return nil, nil
+	}
+
+	steps := make([]Step, 0, len(config.TextPipeline))
+	for _, name := range config.TextPipeline {
+		var step Step
+		switch name {
+		case StepPunctuation:
+			step = Punctuation{}
+		case StepToTraditional:
+			step = NewChineseConverter(true)
+		case StepToSimplified:
+			step = NewChineseConverter(false)
+		case StepProfanity:
+			filter, err := LoadProfanityFilter(config.ProfanityFile)
+			if err != nil {
+				return nil, err
+			}
+			step = filter
+		case StepReplace:
+			replacer, err := LoadReplacer(config.ReplaceDictFile)
+			if err != nil {
+				return nil, err
+			}
+			step = replacer
+		default:
+			return nil, fmt.Errorf("未知的文本处理步骤: %s", name)
+		}
+		steps = append(steps, step)
+	}
+	return NewPipeline(steps...), nil
+}
@@ -0,0 +1,63 @@
+package textproc
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPunctuation(t *testing.T) {
+	cases := map[string]string{
+		"今天天气不错  我们出去走走": "今天天气不错，我们出去走走。",
+		"你吃饭了吗":          "你吃饭了吗？",
+		"已经有句号了。":        "已经有句号了。",
+		"hello world":    "hello world.",
+		"我在用 Go 写代码":     "我在用 Go 写代码。",
+		"结尾是逗号，":         "结尾是逗号。",
+	}
+	for input, want := range cases {
+		assert.Equal(t, want, Punctuation{}.Process(input), input)
+	}
+}
+
+func TestChineseConverter(t *testing.T) {
+	assert.Equal(t, "這個問題還沒解決", NewChineseConverter(true).Process("这个问题还没解决"))
+	assert.Equal(t, "这个问题还没解决", NewChineseConverter(false).Process("這個問題還沒解決"))
+
+	// 一简对多繁的字只做繁转简
+	assert.Equal(t, "头发后面", NewChineseConverter(false).Process("頭髮後面"))
+	assert.Equal(t, "頭发后面", NewChineseConverter(true).Process("头发后面"))
+}
+
+func TestFromConfig(t *testing.T) {
+	dir := t.TempDir()
+	dict := filepath.Join(dir, "replace.txt")
+	require.NoError(t, os.WriteFile(dict, []byte("# 常见误识别\n阿里 云 => 阿里云\n嗯\t\n"), 0644))
+	words := filepath.Join(dir, "profanity.txt")
+	require.NoError(t, os.WriteFile(words, []byte("笨蛋\n"), 0644))
+
+	config := models.NewDefaultConfig()
+	config.TextPipeline = []string{"replace", "profanity", "punctuation"}
+	config.ReplaceDictFile = dict
+	config.ProfanityFile = words
+
+	pipeline, err := FromConfig(config)
+	require.NoError(t, err)
+	assert.Equal(t, config.TextPipeline, pipeline.Steps())
+
+	segments := pipeline.ApplySegments([]models.DataSegment{
+		{Text: "嗯 笨蛋 我用阿里 云 识别", StartTime: 1, EndTime: 2},
+		{Text: "[无法识别的音频片段]"},
+	})
+	assert.Equal(t, "** 我用阿里云，识别。", segments[0].Text)
+	assert.Equal(t, "[无法识别的音频片段]", segments[1].Text)
+
+	// 未配置步骤时不处理
+	pipeline, err = FromConfig(models.NewDefaultConfig())
+	require.NoError(t, err)
+	assert.Nil(t, pipeline)
+}
@@ -0,0 +1,73 @@
+package textproc
+
+import (
+	"strings"
+	"unicode"
+)
+
+// 以这些字结尾的中文句子视为疑问句
+const questionParticles = "吗么"
+
+// Punctuation 基于规则的标点恢复：
+// 合并多余空白，将汉字之间的停顿空格替换为逗号，并按语言补充句末标点
+type Punctuation struct{}
+
+// Name 返回步骤名称
+func (Punctuation) Name() string { return StepPunctuation }
+
+// Process 恢复文本中的标点
+func (Punctuation) Process(text string) string {
+	words := strings.Fields(text)
+	if len(words) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	for i, word := range words {
+		if i > 0 {
+			prev, _ := lastRune(words[i-1])
+			next := []rune(word)[0]
+			if isCJK(prev) && isCJK(next) {
+				// 识别服务常以空格表示汉字之间的停顿
+				b.WriteString("，")
+			} else {
+				b.WriteString(" ")
+			}
+		}
+		b.WriteString(word)
+	}
+	result := b.String()
+
+	// 末尾的逗号改为句末标点，已有其他标点（含引号、括号）时保持不变
+	result = strings.TrimRight(result, "，,、")
+	last, ok := lastRune(result)
+	if !ok || unicode.IsPunct(last) {
+		return result
+	}
+
+	switch {
+	case strings.ContainsRune(questionParticles, last):
+		return result + "？"
+	case isCJK(last):
+		return result + "。"
+	default:
+		return result + "."
+	}
+}
+
+// isCJK 判断是否为汉字（含日文假名与韩文），用于区分中英文
+func isCJK(r rune) bool {
+	return unicode.Is(unicode.Han, r) ||
+		unicode.Is(unicode.Hiragana, r) ||
+		unicode.Is(unicode.Katakana, r) ||
+		unicode.Is(unicode.Hangul, r)
+}
+
+// lastRune 返回字符串的最后一个字符
+func lastRune(s string) (rune, bool) {
+	runes := []rune(s)
+	if len(runes) == 0 {
+		return 0, false
+	}
+	return runes[len(runes)-1], true
+}
@@ -0,0 +1,88 @@
+package translate
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/llm"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/utils"
+)
+
+// DeepL 接口地址，免费版的密钥以 :fx 结尾
+const (
+	DeepLURL     = "https://api.deepl.com"
+	DeepLFreeURL = "https://api-free.deepl.com"
+)
+
+// DeepLTranslator 使用 DeepL API 翻译
+type DeepLTranslator struct {
+	APIKey     string
+	BaseURL    string            // 接口地址，不含 /v2/translate
+	HttpClient *utils.HTTPClient // 共享的重试客户端
+}
+
+// deeplRequest DeepL 翻译请求
+type deeplRequest struct {
+	Text       []string `json:"text"`
+	TargetLang string   `json:"target_lang"`
+}
+
+// deeplResponse DeepL 翻译响应
+type deeplResponse struct {
+	Translations []struct {
+		DetectedSourceLanguage string `json:"detected_source_language"`
+		Text                   string `json:"text"`
+	} `json:"translations"`
+}
+
+// NewDeepLTranslator 创建 DeepL 翻译，按密钥选择免费版或专业版地址
+func NewDeepLTranslator(apiKey string) *DeepLTranslator {
+	baseURL := DeepLURL
+	if strings.HasSuffix(apiKey, ":fx") {
+		baseURL = DeepLFreeURL
+	}
+	return &DeepLTranslator{
+		APIKey:     apiKey,
+		BaseURL:    baseURL,
+		HttpClient: utils.DefaultHTTPClient(),
+	}
+}
+
+// Name 返回翻译服务名称
+func (t *DeepLTranslator) Name() string {
+	return "deepl"
+}
+
+// Translate 翻译一批文本
+func (t *DeepLTranslator) Translate(ctx context.Context, texts []string, target string) ([]string, error) {
+	var response deeplResponse
+	headers := map[string]string{"Authorization": "DeepL-Auth-Key " + t.APIKey}
+	request := deeplRequest{Text: texts, TargetLang: deeplLanguage(target)}
+	if err := llm.PostJSON(ctx, t.HttpClient, t.BaseURL+"/v2/translate", headers, request, &response); err != nil {
+		return nil, err
+	}
+	if len(response.Translations) != len(texts) {
+		return nil, fmt.Errorf("DeepL 返回 %d 条译文，应为 %d 条", len(response.Translations), len(texts))
+	}
+	results := make([]string, len(texts))
+	for i, translation := range response.Translations {
+		results[i] = translation.Text
+	}
+	return results, nil
+}
+
+// deeplLanguage 将语言代码转换为 DeepL 的目标语言，英语与葡萄牙语需要指定地区
+func deeplLanguage(code string) string {
+	switch strings.ToLower(code) {
+	case "en":
+		return "EN-US"
+	case "pt":
+		return "PT-BR"
+	case "zh", "zh-cn":
+		return "ZH-HANS"
+	case "zh-tw":
+		return "ZH-HANT"
+	}
+	return strings.ToUpper(code)
+}
@@ -0,0 +1,83 @@
+package translate
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"net/url"
+	"strings"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/llm"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/utils"
+)
+
+// GoogleURL Google Cloud Translation 接口地址
+const GoogleURL = "https://translation.googleapis.com"
+
+// GoogleTranslator 使用 Google Cloud Translation (v2) 翻译
+type GoogleTranslator struct {
+	APIKey     string
+	BaseURL    string            // 接口地址，不含 /language/translate/v2
+	HttpClient *utils.HTTPClient // 共享的重试客户端
+}
+
+// googleRequest Google 翻译请求
+type googleRequest struct {
+	Q      []string `json:"q"`
+	Target string   `json:"target"`
+	Format string   `json:"format"`
+}
+
+// googleResponse Google 翻译响应
+type googleResponse struct {
+	Data struct {
+		Translations []struct {
+			TranslatedText         string `json:"translatedText"`
+			DetectedSourceLanguage string `json:"detectedSourceLanguage"`
+		} `json:"translations"`
+	} `json:"data"`
+}
+
+// NewGoogleTranslator 创建 Google 翻译
+func NewGoogleTranslator(apiKey string) *GoogleTranslator {
+	return &GoogleTranslator{
+		APIKey:     apiKey,
+		BaseURL:    GoogleURL,
+		HttpClient: utils.DefaultHTTPClient(),
+	}
+}
+
+// Name 返回翻译服务名称
+func (t *GoogleTranslator) Name() string {
+	return "google"
+}
+
+// Translate 翻译一批文本
+func (t *GoogleTranslator) Translate(ctx context.Context, texts []string, target string) ([]string, error) {
+	var response googleResponse
+	endpoint := t.BaseURL + "/language/translate/v2?key=" + url.QueryEscape(t.APIKey)
+	request := googleRequest{Q: texts, Target: googleLanguage(target), Format: "text"}
+	if err := llm.PostJSON(ctx, t.HttpClient, endpoint, nil, request, &response); err != nil {
+		return nil, err
+	}
+	if len(response.Data.Translations) != len(texts) {
+		return nil, fmt.Errorf("Google 翻译返回 %d 条译文，应为 %d 条", len(response.Data.Translations), len(texts))
+	}
+	results := make([]string, len(texts))
+	for i, translation := range response.Data.Translations {
+		// format 为 text 时通常不转义，个别字符仍可能以 HTML 实体返回
+		results[i] = html.UnescapeString(translation.TranslatedText)
+	}
+	return results, nil
+}
+
+// googleLanguage 将语言代码转换为 Google 翻译的目标语言，中文需要区分简繁
+func googleLanguage(code string) string {
+	switch strings.ToLower(code) {
+	case "zh", "zh-cn":
+		return "zh-CN"
+	case "zh-tw":
+		return "zh-TW"
+	}
+	return code
+}
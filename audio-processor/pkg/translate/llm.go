@@ -0,0 +1,86 @@
+package translate
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// translatePrompt 大模型翻译的系统提示，%s 为目标语言
+const translatePrompt = "你是一个专业的字幕翻译。下面是一个JSON字符串数组，每个元素是一条按时间顺序排列的字幕，" +
+	"请结合上下文将每一条翻译为%s。保持条数与顺序不变，不要合并、拆分或省略任何一条，" +
+	"只返回翻译后的JSON字符串数组，不要输出其他内容。"
+
+// LLM 翻译使用的大模型接口
+type LLM interface {
+	Chat(systemPrompt, content string) (string, error)
+}
+
+// LLMTranslator 由大模型翻译，一批字幕以JSON数组发送，上下文连贯
+type LLMTranslator struct {
+	LLM LLM
+}
+
+// NewLLMTranslator 创建大模型翻译
+func NewLLMTranslator(client LLM) *LLMTranslator {
+	return &LLMTranslator{LLM: client}
+}
+
+// Name 返回翻译服务名称
+func (t *LLMTranslator) Name() string {
+	if named, ok := t.LLM.(interface{ Name() string }); ok {
+		return named.Name()
+	}
+	return "llm"
+}
+
+// Translate 翻译一批字幕。返回的条数不一致时将这一批拆成两半分别重新翻译
+func (t *LLMTranslator) Translate(ctx context.Context, texts []string, target string) ([]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	content, err := json.Marshal(texts)
+	if err != nil {
+		return nil, fmt.Errorf("序列化字幕失败: %w", err)
+	}
+	reply, err := t.LLM.Chat(fmt.Sprintf(translatePrompt, LanguageName(target)), string(content))
+	if err != nil {
+		return nil, err
+	}
+	results, err := parseTranslations(reply)
+	if err == nil && len(results) != len(texts) {
+		err = fmt.Errorf("大模型返回 %d 条译文，应为 %d 条", len(results), len(texts))
+	}
+	if err == nil {
+		return results, nil
+	}
+	if len(texts) == 1 {
+		return nil, err
+	}
+
+	half := len(texts) / 2
+	first, err := t.Translate(ctx, texts[:half], target)
+	if err != nil {
+		return nil, err
+	}
+	second, err := t.Translate(ctx, texts[half:], target)
+	if err != nil {
+		return nil, err
+	}
+	return append(first, second...), nil
+}
+
+// parseTranslations 解析大模型返回的JSON字符串数组，容忍代码块包裹与前后的说明文字
+func parseTranslations(reply string) ([]string, error) {
+	start := strings.Index(reply, "[")
+	end := strings.LastIndex(reply, "]")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("大模型未返回JSON数组")
+	}
+	var results []string
+	if err := json.Unmarshal([]byte(reply[start:end+1]), &results); err != nil {
+		return nil, fmt.Errorf("解析译文失败: %w", err)
+	}
+	return results, nil
+}
@@ -0,0 +1,161 @@
+// Package translate 将识别结果翻译为目标语言，保留每段的时间与说话人，用于导出译文或双语字幕。
+// 可使用配置的大模型，或 DeepL、Google 翻译等专门的机器翻译服务，由配置 translate_provider 选择。
+// 文稿按字符数分批请求，避免超出模型的上下文长度与接口的单次限制
+package translate
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/llm"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/models"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/utils"
+)
+
+// maxBatchTexts 每批最多的段数，DeepL 单次请求最多 50 段
+const maxBatchTexts = 50
+
+// defaultBatchChars 未配置时每批的最大字符数
+const defaultBatchChars = 3000
+
+// Translator 翻译接口，各服务的实现都满足它
+type Translator interface {
+	// Name 返回翻译服务名称，用于日志
+	Name() string
+	// Translate 将 texts 翻译为 target 语言，返回与 texts 一一对应的译文
+	Translate(ctx context.Context, texts []string, target string) ([]string, error)
+}
+
+// Options 翻译选项
+type Options struct {
+	Target     string // 目标语言代码，如 en、ja
+	BatchChars int    // 每批的最大字符数
+}
+
+// OptionsFromConfig 从配置读取翻译选项
+func OptionsFromConfig(config *models.Config) Options {
+	return Options{Target: config.TranslateTo, BatchChars: config.TranslateBatchChars}
+}
+
+// FromConfig 按配置创建翻译服务，未设置 translate_to 时返回 nil。
+// 使用大模型翻译时复用 client（由 llm.FromConfig 创建，未配置大模型时为 nil）
+func FromConfig(config *models.Config, client llm.LLMClient) (Translator, error) {
+	if config.TranslateTo == "" {
+		return nil, nil
+	}
+	switch config.TranslateProvider {
+	case models.TranslateProviderLLM, "":
+		if client == nil {
+			return nil, fmt.Errorf("使用大模型翻译需要配置大模型API密钥")
+		}
+		return NewLLMTranslator(client), nil
+	case models.TranslateProviderDeepL:
+		key := config.TranslateKey()
+		if key == "" {
+			return nil, fmt.Errorf("未配置 DeepL API密钥 (translate_api_key 或 DEEPL_API_KEY)")
+		}
+		return NewDeepLTranslator(key), nil
+	case models.TranslateProviderGoogle:
+		key := config.TranslateKey()
+		if key == "" {
+			return nil, fmt.Errorf("未配置 Google 翻译API密钥 (translate_api_key 或 GOOGLE_TRANSLATE_API_KEY)")
+		}
+		return NewGoogleTranslator(key), nil
+	}
+	return nil, fmt.Errorf("不支持的翻译服务: %s", config.TranslateProvider)
+}
+
+// Segments 翻译识别段落，返回时间与说话人不变、文本为译文的段落。
+// 空段与无法识别的片段保持原样；译文与逐词时间戳不再对应，不保留逐词时间
+func Segments(ctx context.Context, translator Translator, segments []models.DataSegment, opts Options) ([]models.DataSegment, error) {
+	if opts.Target == "" {
+		return nil, fmt.Errorf("未设置目标语言")
+	}
+	var texts []string
+	var indexes []int
+	for i, segment := range segments {
+		text := strings.TrimSpace(segment.Text)
+		if text == "" || text == "[无法识别的音频片段]" {
+			continue
+		}
+		texts = append(texts, text)
+		indexes = append(indexes, i)
+	}
+
+	translated := make([]models.DataSegment, len(segments))
+	copy(translated, segments)
+	batches := Batches(texts, opts.BatchChars)
+	utils.Info("使用 %s 将 %d 段文稿翻译为 %s，分 %d 批请求", translator.Name(), len(texts), opts.Target, len(batches))
+	done := 0
+	for i, batch := range batches {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		results, err := translator.Translate(ctx, batch, opts.Target)
+		if err != nil {
+			return nil, fmt.Errorf("第 %d/%d 批翻译失败: %w", i+1, len(batches), err)
+		}
+		if len(results) != len(batch) {
+			return nil, fmt.Errorf("第 %d/%d 批翻译返回 %d 段，应为 %d 段", i+1, len(batches), len(results), len(batch))
+		}
+		for j, result := range results {
+			segment := &translated[indexes[done+j]]
+			segment.Text = strings.TrimSpace(result)
+			segment.Words = nil
+		}
+		done += len(batch)
+	}
+	return translated, nil
+}
+
+// Batches 将文本按顺序分批，每批的字符数不超过 maxChars、段数不超过 maxBatchTexts，
+// 单段超过 maxChars 时独占一批
+func Batches(texts []string, maxChars int) [][]string {
+	if maxChars <= 0 {
+		maxChars = defaultBatchChars
+	}
+	var batches [][]string
+	var current []string
+	size := 0
+	for _, text := range texts {
+		n := len([]rune(text))
+		if len(current) > 0 && (size+n > maxChars || len(current) >= maxBatchTexts) {
+			batches = append(batches, current)
+			current, size = nil, 0
+		}
+		current = append(current, text)
+		size += n
+	}
+	if len(current) > 0 {
+		batches = append(batches, current)
+	}
+	return batches
+}
+
+// languageNames 常用语言代码对应的名称，用于大模型提示
+var languageNames = map[string]string{
+	"zh":    "简体中文",
+	"zh-cn": "简体中文",
+	"zh-tw": "繁体中文",
+	"en":    "英语",
+	"ja":    "日语",
+	"ko":    "韩语",
+	"fr":    "法语",
+	"de":    "德语",
+	"es":    "西班牙语",
+	"pt":    "葡萄牙语",
+	"ru":    "俄语",
+	"it":    "意大利语",
+	"ar":    "阿拉伯语",
+	"vi":    "越南语",
+	"th":    "泰语",
+}
+
+// LanguageName 返回语言代码的中文名称，未知的代码原样返回
+func LanguageName(code string) string {
+	if name, ok := languageNames[strings.ToLower(code)]; ok {
+		return name
+	}
+	return code
+}
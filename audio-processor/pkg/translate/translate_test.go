@@ -0,0 +1,150 @@
+package translate
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/models"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatches(t *testing.T) {
+	batches := Batches([]string{"一二三", "四五", "六七八九", "十"}, 5)
+	assert.Equal(t, [][]string{{"一二三", "四五"}, {"六七八九", "十"}}, batches)
+
+	// 单段超长时独占一批
+	batches = Batches([]string{"短", strings.Repeat("长", 10), "短"}, 5)
+	assert.Equal(t, [][]string{{"短"}, {strings.Repeat("长", 10)}, {"短"}}, batches)
+
+	texts := make([]string, maxBatchTexts+1)
+	for i := range texts {
+		texts[i] = "字"
+	}
+	batches = Batches(texts, 10000)
+	require.Len(t, batches, 2)
+	assert.Len(t, batches[0], maxBatchTexts)
+}
+
+// fakeLLM 将每条字幕加上前缀后返回，broken 为 true 时多条字幕只返回第一条
+type fakeLLM struct {
+	broken  bool
+	prompts []string
+}
+
+func (f *fakeLLM) Chat(systemPrompt, content string) (string, error) {
+	f.prompts = append(f.prompts, systemPrompt)
+	var texts []string
+	if err := json.Unmarshal([]byte(content), &texts); err != nil {
+		return "", err
+	}
+	for i := range texts {
+		texts[i] = "EN:" + texts[i]
+	}
+	if f.broken && len(texts) > 1 {
+		texts = texts[:1]
+	}
+	data, _ := json.Marshal(texts)
+	return "```json\n" + string(data) + "\n```", nil
+}
+
+// TestSegments 测试翻译后保留时间与说话人，跳过无法识别的片段
+func TestSegments(t *testing.T) {
+	client := &fakeLLM{}
+	segments := []models.DataSegment{
+		{Text: "你好", StartTime: 1, EndTime: 2, Speaker: "A", Words: []models.WordTiming{{Text: "你好", StartTime: 1, EndTime: 2}}},
+		{Text: "[无法识别的音频片段]", StartTime: 2, EndTime: 3},
+		{Text: "再见", StartTime: 3, EndTime: 4},
+	}
+	translated, err := Segments(context.Background(), NewLLMTranslator(client), segments, Options{Target: "en", BatchChars: 1000})
+	require.NoError(t, err)
+	require.Len(t, translated, 3)
+	assert.Equal(t, models.DataSegment{Text: "EN:你好", StartTime: 1, EndTime: 2, Speaker: "A"}, translated[0])
+	assert.Equal(t, segments[1], translated[1])
+	assert.Equal(t, "EN:再见", translated[2].Text)
+	assert.Equal(t, "你好", segments[0].Text, "不修改原段落")
+	assert.Contains(t, client.prompts[0], "英语")
+
+	// 返回条数不一致时拆分重新翻译
+	client = &fakeLLM{broken: true}
+	results, err := NewLLMTranslator(client).Translate(context.Background(), []string{"一", "二", "三"}, "en")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"EN:一", "EN:二", "EN:三"}, results)
+}
+
+// newTestServer 返回固定响应并记录请求的服务
+func newTestServer(t *testing.T, reply string, request *map[string]interface{}, header *http.Header, path *string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*path = r.URL.RequestURI()
+		*header = r.Header.Clone()
+		json.NewDecoder(r.Body).Decode(request)
+		w.Write([]byte(reply))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestDeepL(t *testing.T) {
+	var request map[string]interface{}
+	var header http.Header
+	var path string
+	server := newTestServer(t, `{"translations":[{"detected_source_language":"ZH","text":"Hello"},{"detected_source_language":"ZH","text":"Bye"}]}`, &request, &header, &path)
+
+	translator := NewDeepLTranslator("key:fx")
+	assert.Equal(t, DeepLFreeURL, translator.BaseURL)
+	translator.BaseURL = server.URL
+	translator.HttpClient = utils.NewHTTPClient(utils.HTTPClientOptions{Timeout: 5 * time.Second, MaxRetries: 1})
+
+	results, err := translator.Translate(context.Background(), []string{"你好", "再见"}, "en")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Hello", "Bye"}, results)
+	assert.Equal(t, "/v2/translate", path)
+	assert.Equal(t, "DeepL-Auth-Key key:fx", header.Get("Authorization"))
+	assert.Equal(t, "EN-US", request["target_lang"])
+}
+
+func TestGoogle(t *testing.T) {
+	var request map[string]interface{}
+	var header http.Header
+	var path string
+	server := newTestServer(t, `{"data":{"translations":[{"translatedText":"Tom &amp; Jerry"}]}}`, &request, &header, &path)
+
+	translator := NewGoogleTranslator("key")
+	translator.BaseURL = server.URL
+	translator.HttpClient = utils.NewHTTPClient(utils.HTTPClientOptions{Timeout: 5 * time.Second, MaxRetries: 1})
+
+	results, err := translator.Translate(context.Background(), []string{"汤姆和杰瑞"}, "en")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Tom & Jerry"}, results)
+	assert.Equal(t, "/language/translate/v2?key=key", path)
+	assert.Equal(t, "text", request["format"])
+}
+
+// TestFromConfig 测试未设置目标语言时不翻译，机器翻译服务缺少密钥时报错
+func TestFromConfig(t *testing.T) {
+	t.Setenv("DEEPL_API_KEY", "")
+	config := models.NewDefaultConfig()
+	translator, err := FromConfig(config, nil)
+	require.NoError(t, err)
+	assert.Nil(t, translator)
+
+	config.TranslateTo = "en"
+	_, err = FromConfig(config, nil)
+	assert.Error(t, err, "未配置大模型时不能使用大模型翻译")
+
+	config.TranslateProvider = models.TranslateProviderDeepL
+	_, err = FromConfig(config, nil)
+	assert.Error(t, err)
+
+	t.Setenv("DEEPL_API_KEY", "secret")
+	translator, err = FromConfig(config, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "deepl", translator.Name())
+}
@@ -0,0 +1,109 @@
+package trash
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// moveToSystemTrash 将文件移动到操作系统回收站，返回新位置
+func moveToSystemTrash(path string) (string, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+
+	switch runtime.GOOS {
+	case "windows":
+		return "", moveToRecycleBin(absPath)
+	case "darwin":
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		return moveIntoDir(absPath, filepath.Join(home, ".Trash"))
+	default:
+		return moveToFreedesktopTrash(absPath)
+	}
+}
+
+// moveToRecycleBin 通过 PowerShell 调用 Windows 回收站
+func moveToRecycleBin(path string) error {
+	script := fmt.Sprintf(
+		"Add-Type -AssemblyName Microsoft.VisualBasic; "+
+			"[Microsoft.VisualBasic.FileIO.FileSystem]::DeleteFile('%s', 'OnlyErrorDialogs', 'SendToRecycleBin')",
+		strings.ReplaceAll(path, "'", "''"))
+	cmd := exec.Command("powershell", "-NoProfile", "-NonInteractive", "-Command", script)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("调用回收站失败: %v, %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// moveToFreedesktopTrash 按 freedesktop.org 回收站规范移动文件（Linux 桌面环境）
+func moveToFreedesktopTrash(path string) (string, error) {
+	dataHome := os.Getenv("XDG_DATA_HOME")
+	if dataHome == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dataHome = filepath.Join(home, ".local", "share")
+	}
+	trashDir := filepath.Join(dataHome, "Trash")
+	filesDir := filepath.Join(trashDir, "files")
+	infoDir := filepath.Join(trashDir, "info")
+	if err := os.MkdirAll(filesDir, 0700); err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(infoDir, 0700); err != nil {
+		return "", err
+	}
+
+	name := uniqueName(filesDir, filepath.Base(path))
+	info := fmt.Sprintf("[Trash Info]\nPath=%s\nDeletionDate=%s\n",
+		(&url.URL{Path: path}).EscapedPath(), time.Now().Format("2006-01-02T15:04:05"))
+	infoPath := filepath.Join(infoDir, name+".trashinfo")
+	if err := os.WriteFile(infoPath, []byte(info), 0600); err != nil {
+		return "", err
+	}
+
+	target := filepath.Join(filesDir, name)
+	if err := moveFile(path, target); err != nil {
+		os.Remove(infoPath)
+		return "", err
+	}
+	return target, nil
+}
+
+// moveIntoDir 将文件移动到目录中，重名时自动加序号
+func moveIntoDir(path, dir string) (string, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+	target := filepath.Join(dir, uniqueName(dir, filepath.Base(path)))
+	if err := moveFile(path, target); err != nil {
+		return "", err
+	}
+	return target, nil
+}
+
+// uniqueName 返回目录中不重名的文件名
+func uniqueName(dir, name string) string {
+	if _, err := os.Stat(filepath.Join(dir, name)); os.IsNotExist(err) {
+		return name
+	}
+	ext := filepath.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	for i := 1; ; i++ {
+		candidate := fmt.Sprintf("%s.%d%s", base, i, ext)
+		if _, err := os.Stat(filepath.Join(dir, candidate)); os.IsNotExist(err) {
+			return candidate
+		}
+	}
+}
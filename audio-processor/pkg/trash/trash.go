@@ -0,0 +1,255 @@
+package trash
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/audit"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/models"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/utils"
+)
+
+// 删除模式
+const (
+	ModeDelete = "delete" // 直接删除，不可恢复
+	ModeFolder = "folder" // 移动到回收目录，超过保留期后清理
+	ModeSystem = "system" // 移动到操作系统回收站，失败时退回回收目录
+)
+
+// 回收目录中记录原始路径的附属文件后缀
+const infoSuffix = ".trashinfo"
+
+// Info 回收目录中每个文件对应的删除信息
+type Info struct {
+	OriginalPath string    `json:"original_path"`
+	DeletedAt    time.Time `json:"deleted_at"`
+}
+
+// Trash 可恢复的删除操作。nil 值等同于直接删除。
+type Trash struct {
+	mode      string
+	dir       string
+	retention time.Duration
+}
+
+// New 创建回收站。dir 为回收目录（folder 模式及 system 模式的退路），
+// retention 为回收目录中文件的保留时间，0 表示永久保留。
+func New(mode, dir string, retention time.Duration) *Trash {
+	if mode == "" {
+		mode = ModeFolder
+	}
+	return &Trash{
+		mode:      mode,
+		dir:       dir,
+		retention: retention,
+	}
+}
+
+// Mode 返回删除模式
+func (t *Trash) Mode() string {
+	if t == nil {
+		return ModeDelete
+	}
+	return t.mode
+}
+
+// Dir 返回回收目录
+func (t *Trash) Dir() string {
+	if t == nil {
+		return ""
+	}
+	return t.dir
+}
+
+// Remove 按配置的模式删除文件，返回文件的新位置（直接删除时为空）。
+// initiator 与 reason 会写入审计日志。
+func (t *Trash) Remove(path, initiator, reason string) (string, error) {
+	target, err := t.remove(path)
+
+	entry := audit.Entry{
+		Action:    audit.ActionTrash,
+		Path:      path,
+		Target:    target,
+		Reason:    reason,
+		Initiator: initiator,
+	}
+	if t.Mode() == ModeDelete {
+		entry.Action = audit.ActionDelete
+	}
+	if err != nil {
+		if os.IsNotExist(err) {
+			return target, err
+		}
+		entry.Error = err.Error()
+	}
+	audit.Record(entry)
+
+	return target, err
+}
+
+// remove 执行实际的删除或移动
+func (t *Trash) remove(path string) (string, error) {
+	if t == nil || t.mode == ModeDelete {
+		return "", os.Remove(path)
+	}
+
+	if _, err := os.Stat(path); err != nil {
+		return "", err
+	}
+
+	if t.mode == ModeSystem {
+		target, err := moveToSystemTrash(path)
+		if err == nil {
+			utils.Info("已移至系统回收站: %s", path)
+			return target, nil
+		}
+		utils.Warn("移至系统回收站失败，改用回收目录: %v", err)
+	}
+
+	target, err := t.moveToFolder(path)
+	if err != nil {
+		return "", err
+	}
+	utils.Info("已移至回收目录: %s -> %s", path, target)
+	return target, nil
+}
+
+// moveToFolder 将文件移入回收目录，并写入记录原始路径的附属文件
+func (t *Trash) moveToFolder(path string) (string, error) {
+	if t.dir == "" {
+		return "", fmt.Errorf("未设置回收目录")
+	}
+	if err := os.MkdirAll(t.dir, 0755); err != nil {
+		return "", fmt.Errorf("创建回收目录失败: %w", err)
+	}
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		absPath = path
+	}
+
+	now := time.Now()
+	name := fmt.Sprintf("%s_%s_%s", now.Format("20060102150405"), utils.GenerateRandomString(4), filepath.Base(path))
+	target := filepath.Join(t.dir, name)
+
+	if err := moveFile(path, target); err != nil {
+		return "", fmt.Errorf("移动文件到回收目录失败: %w", err)
+	}
+
+	info := Info{OriginalPath: absPath, DeletedAt: now}
+	data, _ := json.MarshalIndent(info, "", "  ")
+	if err := os.WriteFile(target+infoSuffix, data, 0644); err != nil {
+		utils.Warn("写入回收信息失败: %v", err)
+	}
+
+	return target, nil
+}
+
+// Purge 清理回收目录中超过保留时间的文件，返回清理的文件数
+func (t *Trash) Purge() (int, error) {
+	if t == nil || t.dir == "" || t.retention <= 0 {
+		return 0, nil
+	}
+
+	entries, err := os.ReadDir(t.dir)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("读取回收目录失败: %w", err)
+	}
+
+	cutoff := time.Now().Add(-t.retention)
+	purged := 0
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasSuffix(entry.Name(), infoSuffix) {
+			continue
+		}
+
+		path := filepath.Join(t.dir, entry.Name())
+		deletedAt := t.deletedAt(path, entry)
+		if deletedAt.After(cutoff) {
+			continue
+		}
+
+		if err := os.Remove(path); err != nil {
+			utils.Warn("清理回收文件失败 %s: %v", path, err)
+			continue
+		}
+		os.Remove(path + infoSuffix)
+		purged++
+		audit.Record(audit.Entry{
+			Action:    audit.ActionPurge,
+			Path:      path,
+			Reason:    fmt.Sprintf("超过回收保留期 %s", t.retention),
+			Initiator: "cleanup",
+		})
+	}
+
+	if purged > 0 {
+		utils.Info("已清理回收目录中 %d 个过期文件", purged)
+	}
+	return purged, nil
+}
+
+// deletedAt 读取文件的删除时间，没有附属信息时使用文件修改时间
+func (t *Trash) deletedAt(path string, entry os.DirEntry) time.Time {
+	if data, err := os.ReadFile(path + infoSuffix); err == nil {
+		var info Info
+		if json.Unmarshal(data, &info) == nil && !info.DeletedAt.IsZero() {
+			return info.DeletedAt
+		}
+	}
+	if fi, err := entry.Info(); err == nil {
+		return fi.ModTime()
+	}
+	return time.Now()
+}
+
+// moveFile 移动文件，跨文件系统时退回复制后删除
+func moveFile(source, target string) error {
+	if err := os.Rename(source, target); err == nil {
+		return nil
+	}
+
+	in, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(target)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(target)
+		return err
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(target)
+		return err
+	}
+
+	in.Close()
+	return os.Remove(source)
+}
+
+// FromConfig 根据配置创建回收站，未指定回收目录时使用输出目录下的 .trash
+func FromConfig(config *models.Config) *Trash {
+	if config == nil {
+		return nil
+	}
+	dir := config.TrashFolder
+	if dir == "" {
+		dir = filepath.Join(config.OutputFolder, ".trash")
+	}
+	retention := time.Duration(config.TrashRetentionDays * float64(24*time.Hour))
+	return New(config.TrashMode, dir, retention)
+}
@@ -0,0 +1,74 @@
+package trash
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRemoveMovesToFolder(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "talk.mp3")
+	require.NoError(t, os.WriteFile(source, []byte("audio"), 0644))
+
+	bin := New(ModeFolder, filepath.Join(dir, ".trash"), 0)
+	target, err := bin.Remove(source, "test", "测试")
+	require.NoError(t, err)
+
+	_, err = os.Stat(source)
+	assert.True(t, os.IsNotExist(err))
+
+	data, err := os.ReadFile(target)
+	require.NoError(t, err)
+	assert.Equal(t, "audio", string(data))
+	assert.FileExists(t, target+infoSuffix)
+}
+
+func TestRemoveDeleteMode(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "talk.mp3")
+	require.NoError(t, os.WriteFile(source, []byte("audio"), 0644))
+
+	target, err := New(ModeDelete, filepath.Join(dir, ".trash"), 0).Remove(source, "test", "测试")
+	require.NoError(t, err)
+	assert.Empty(t, target)
+	assert.NoDirExists(t, filepath.Join(dir, ".trash"))
+
+	// nil 回收站等同于直接删除
+	require.NoError(t, os.WriteFile(source, []byte("audio"), 0644))
+	var bin *Trash
+	_, err = bin.Remove(source, "test", "测试")
+	require.NoError(t, err)
+	assert.NoFileExists(t, source)
+}
+
+func TestPurgeExpired(t *testing.T) {
+	dir := t.TempDir()
+	trashDir := filepath.Join(dir, ".trash")
+	bin := New(ModeFolder, trashDir, time.Hour)
+
+	source := filepath.Join(dir, "old.mp3")
+	require.NoError(t, os.WriteFile(source, []byte("audio"), 0644))
+	oldTarget, err := bin.Remove(source, "test", "测试")
+	require.NoError(t, err)
+
+	// 将删除时间改到保留期之前
+	require.NoError(t, os.Remove(oldTarget+infoSuffix))
+	past := time.Now().Add(-2 * time.Hour)
+	require.NoError(t, os.Chtimes(oldTarget, past, past))
+
+	source = filepath.Join(dir, "new.mp3")
+	require.NoError(t, os.WriteFile(source, []byte("audio"), 0644))
+	newTarget, err := bin.Remove(source, "test", "测试")
+	require.NoError(t, err)
+
+	purged, err := bin.Purge()
+	require.NoError(t, err)
+	assert.Equal(t, 1, purged)
+	assert.NoFileExists(t, oldTarget)
+	assert.FileExists(t, newTarget)
+}
@@ -0,0 +1,59 @@
+package usage
+
+import (
+	"sync"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/llm"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/store"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/utils"
+)
+
+// LLMRecorder 将每次大模型请求的 token 用量写入处理记录数据库，当天用量超出预算时警告
+type LLMRecorder struct {
+	store  *store.Store
+	budget int // 每天的 token 预算，0 表示不限制
+
+	mu        sync.Mutex
+	warnedDay string // 已警告过超出预算的日期，每天只警告一次
+}
+
+// NewLLMRecorder 创建大模型用量记录器
+func NewLLMRecorder(db *store.Store, budget int) *LLMRecorder {
+	return &LLMRecorder{store: db, budget: budget}
+}
+
+// Record 保存一次请求的用量，可作为 llm.SetUsageRecorder 的参数
+func (r *LLMRecorder) Record(event llm.UsageEvent) {
+	err := r.store.AddLLMUsage(store.LLMUsage{
+		Time:             event.Time,
+		Job:              event.Job,
+		Provider:         event.Provider,
+		Model:            event.Model,
+		Requests:         event.Usage.Requests,
+		PromptTokens:     event.Usage.PromptTokens,
+		CompletionTokens: event.Usage.CompletionTokens,
+	})
+	if err != nil {
+		utils.Warn("%v", err)
+		return
+	}
+	if r.budget <= 0 {
+		return
+	}
+
+	day := event.Time.Format("2006-01-02")
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.warnedDay == day {
+		return
+	}
+	total, err := r.store.LLMTokensOn(event.Time)
+	if err != nil {
+		utils.Warn("%v", err)
+		return
+	}
+	if total > r.budget {
+		r.warnedDay = day
+		utils.Warn("今天的大模型用量已达 %d tokens，超出预算 %d (llm_daily_token_budget)", total, r.budget)
+	}
+}
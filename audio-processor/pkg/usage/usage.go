@@ -0,0 +1,167 @@
+package usage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/utils"
+)
+
+// 按月汇总使用量的键格式
+const monthLayout = "2006-01"
+
+// Event 一次文件处理的结果
+type Event struct {
+	Time         time.Time
+	Service      string  // 使用的ASR服务，未进入识别阶段时为空
+	AudioSeconds float64 // 音频时长（秒）
+	Success      bool
+}
+
+// ServiceUsage 单个ASR服务的使用量
+type ServiceUsage struct {
+	Files        int     `json:"files"`
+	Failures     int     `json:"failures"`
+	AudioSeconds float64 `json:"audio_seconds"`
+}
+
+// MonthUsage 一个月的使用量汇总
+type MonthUsage struct {
+	Files        int                      `json:"files"`         // 处理的文件数（含失败）
+	Failures     int                      `json:"failures"`      // 失败的文件数
+	AudioSeconds float64                  `json:"audio_seconds"` // 成功识别的音频总时长（秒）
+	Services     map[string]*ServiceUsage `json:"services"`      // 按服务统计
+}
+
+// Minutes 返回成功识别的音频总分钟数
+func (m *MonthUsage) Minutes() float64 {
+	return m.AudioSeconds / 60
+}
+
+// Stats 使用量统计文件的内容，只保存汇总数据，不包含文件名等隐私信息
+type Stats struct {
+	Months map[string]*MonthUsage `json:"months"`
+}
+
+// add 将一次处理结果累加到对应月份
+func (s *Stats) add(event Event) {
+	if s.Months == nil {
+		s.Months = make(map[string]*MonthUsage)
+	}
+	key := event.Time.Format(monthLayout)
+	month, ok := s.Months[key]
+	if !ok {
+		month = &MonthUsage{Services: make(map[string]*ServiceUsage)}
+		s.Months[key] = month
+	}
+	if month.Services == nil {
+		month.Services = make(map[string]*ServiceUsage)
+	}
+
+	month.Files++
+	if !event.Success {
+		month.Failures++
+	} else {
+		month.AudioSeconds += event.AudioSeconds
+	}
+
+	if event.Service == "" {
+		return
+	}
+	service, ok := month.Services[event.Service]
+	if !ok {
+		service = &ServiceUsage{}
+		month.Services[event.Service] = service
+	}
+	service.Files++
+	if !event.Success {
+		service.Failures++
+	} else {
+		service.AudioSeconds += event.AudioSeconds
+	}
+}
+
+// MonthKeys 返回有记录的月份，按时间倒序
+func (s *Stats) MonthKeys() []string {
+	keys := make([]string, 0, len(s.Months))
+	for key := range s.Months {
+		keys = append(keys, key)
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(keys)))
+	return keys
+}
+
+// Load 读取使用量统计文件，文件不存在时返回空统计
+func Load(path string) (*Stats, error) {
+	stats := &Stats{Months: make(map[string]*MonthUsage)}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return stats, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取使用量统计失败: %w", err)
+	}
+	if err := json.Unmarshal(data, stats); err != nil {
+		return nil, fmt.Errorf("解析使用量统计失败: %w", err)
+	}
+	return stats, nil
+}
+
+// Recorder 将处理结果累加写入本地统计文件，不做任何网络上报
+type Recorder struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewRecorder 创建使用量记录器
+func NewRecorder(path string) *Recorder {
+	return &Recorder{path: path}
+}
+
+// Record 记录一次处理结果，nil 记录器不做任何事
+func (r *Recorder) Record(event Event) {
+	if r == nil || r.path == "" {
+		return
+	}
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stats, err := Load(r.path)
+	if err != nil {
+		utils.Warn("%v，将重新开始统计", err)
+		stats = &Stats{}
+	}
+	stats.add(event)
+
+	if err := utils.SaveJSONFile(r.path, stats); err != nil {
+		utils.Warn("保存使用量统计失败: %v", err)
+	}
+}
+
+var (
+	defaultMu       sync.RWMutex
+	defaultRecorder *Recorder
+)
+
+// SetDefault 设置全局使用量记录器，nil 表示不记录
+func SetDefault(recorder *Recorder) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultRecorder = recorder
+}
+
+// Record 使用全局记录器记录一次处理结果
+func Record(event Event) {
+	defaultMu.RLock()
+	recorder := defaultRecorder
+	defaultMu.RUnlock()
+	recorder.Record(event)
+}
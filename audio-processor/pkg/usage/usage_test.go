@@ -0,0 +1,54 @@
+package usage
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/llm"
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecorder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "usage_stats.json")
+	recorder := NewRecorder(path)
+
+	september := time.Date(2026, 9, 30, 23, 0, 0, 0, time.Local)
+	october := time.Date(2026, 10, 1, 8, 0, 0, 0, time.Local)
+	recorder.Record(Event{Time: september, Service: "bcut", AudioSeconds: 120, Success: true})
+	recorder.Record(Event{Time: october, Service: "bcut", AudioSeconds: 600, Success: true})
+	recorder.Record(Event{Time: october, Service: "kuaishou", AudioSeconds: 300, Success: false})
+	recorder.Record(Event{Time: october, Success: false}) // 音频提取失败
+
+	stats, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2026-10", "2026-09"}, stats.MonthKeys())
+
+	month := stats.Months["2026-10"]
+	assert.Equal(t, 3, month.Files)
+	assert.Equal(t, 2, month.Failures)
+	assert.Equal(t, 10.0, month.Minutes()) // 失败的文件不计入识别时长
+	assert.Equal(t, &ServiceUsage{Files: 1, Failures: 1}, month.Services["kuaishou"])
+	assert.Equal(t, 1, stats.Months["2026-09"].Services["bcut"].Files)
+}
+
+// TestLLMRecorder 测试大模型用量写入数据库
+func TestLLMRecorder(t *testing.T) {
+	db, err := store.Open(store.Path(t.TempDir()))
+	require.NoError(t, err)
+	defer db.Close()
+
+	now := time.Date(2026, 10, 18, 10, 0, 0, 0, time.Local)
+	recorder := NewLLMRecorder(db, 100)
+	for i := 0; i < 2; i++ {
+		recorder.Record(llm.UsageEvent{Time: now, Job: "a.mp4", Provider: "openai", Model: "m",
+			Usage: llm.Usage{Requests: 1, PromptTokens: 50, CompletionTokens: 10}})
+	}
+	assert.Equal(t, "2026-10-18", recorder.warnedDay, "超出预算后当天只警告一次")
+
+	total, err := db.LLMTokensOn(now)
+	require.NoError(t, err)
+	assert.Equal(t, 120, total)
+}
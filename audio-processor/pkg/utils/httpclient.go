@@ -0,0 +1,212 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// HTTPClientOptions 共享HTTP客户端的超时、重试、连接池与代理设置
+type HTTPClientOptions struct {
+	Timeout             time.Duration // 单次请求的超时（包括读取响应体），0 表示不限制，由 ctx 控制
+	MaxRetries          int           // 网络错误、429 与 5xx 响应的最大重试次数
+	BaseDelay           time.Duration // 首次重试前的等待时间，之后按指数增长并加入随机抖动
+	MaxDelay            time.Duration // 单次等待时间的上限
+	MaxIdleConnsPerHost int           // 每个主机保留的空闲连接数
+	Proxy               *url.URL      // 代理地址（http、https 或 socks5），nil 时使用环境变量 HTTP_PROXY/HTTPS_PROXY/NO_PROXY
+}
+
+// DefaultHTTPClientOptions 返回默认设置
+func DefaultHTTPClientOptions() HTTPClientOptions {
+	return HTTPClientOptions{
+		Timeout:             3 * time.Minute,
+		MaxRetries:          3,
+		BaseDelay:           time.Second,
+		MaxDelay:            30 * time.Second,
+		MaxIdleConnsPerHost: 8,
+	}
+}
+
+// HTTPClient 带指数退避重试的HTTP客户端，同一主机的连接复用
+type HTTPClient struct {
+	client  *http.Client
+	options HTTPClientOptions
+}
+
+// NewHTTPClient 按设置创建客户端
+func NewHTTPClient(options HTTPClientOptions) *HTTPClient {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if options.MaxIdleConnsPerHost > 0 {
+		transport.MaxIdleConnsPerHost = options.MaxIdleConnsPerHost
+	}
+	if options.Proxy != nil {
+		transport.Proxy = http.ProxyURL(options.Proxy)
+	}
+	return &HTTPClient{
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   options.Timeout,
+		},
+		options: options,
+	}
+}
+
+// WithTimeout 返回单次请求超时为 timeout 的客户端，与原客户端共享连接池
+func (c *HTTPClient) WithTimeout(timeout time.Duration) *HTTPClient {
+	options := c.options
+	options.Timeout = timeout
+	return &HTTPClient{
+		client: &http.Client{
+			Transport: c.client.Transport,
+			Timeout:   timeout,
+		},
+		options: options,
+	}
+}
+
+// Options 返回客户端的设置
+func (c *HTTPClient) Options() HTTPClientOptions {
+	return c.options
+}
+
+// Do 发送请求，遇到网络错误、429 或 5xx 响应时按指数退避重试，ctx 取消时立即返回。
+// 请求体无法重放（未设置 GetBody）时不重试；重试次数用完后返回最后一次的响应或错误，
+// 调用方仍需检查状态码
+func (c *HTTPClient) Do(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	for attempt := 0; ; attempt++ {
+		attemptReq := req
+		if attempt > 0 {
+			var err error
+			if attemptReq, err = rewindRequest(req); err != nil {
+				return nil, err
+			}
+		}
+
+		resp, err := c.client.Do(attemptReq)
+		reason, retryAfter := retryReason(ctx, resp, err)
+		if reason == "" || attempt >= c.options.MaxRetries || !canRewind(req) {
+			return resp, err
+		}
+
+		delay := c.backoff(attempt)
+		if retryAfter > delay {
+			delay = retryAfter
+			if c.options.MaxDelay > 0 && delay > c.options.MaxDelay {
+				delay = c.options.MaxDelay
+			}
+		}
+		if resp != nil {
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+			resp.Body.Close()
+		}
+		Warn("请求 %s %s 失败 (尝试 %d/%d): %s，%.1f 秒后重试",
+			req.Method, req.URL.Host, attempt+1, c.options.MaxRetries+1, reason, delay.Seconds())
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// backoff 返回第 attempt 次重试前的等待时间：BaseDelay × 2^attempt，不超过 MaxDelay，
+// 再在 [1/2, 1) 倍之间随机抖动，避免多个任务同时重试
+func (c *HTTPClient) backoff(attempt int) time.Duration {
+	delay := c.options.BaseDelay
+	for i := 0; i < attempt && (c.options.MaxDelay <= 0 || delay < c.options.MaxDelay); i++ {
+		delay *= 2
+	}
+	if c.options.MaxDelay > 0 && delay > c.options.MaxDelay {
+		delay = c.options.MaxDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(delay-half)+1))
+}
+
+// retryReason 判断是否需要重试，返回原因（不需要时为空）与服务端要求的等待时间
+func retryReason(ctx context.Context, resp *http.Response, err error) (string, time.Duration) {
+	if err != nil {
+		if ctx.Err() != nil {
+			return "", 0
+		}
+		return err.Error(), 0
+	}
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests,
+		resp.StatusCode >= 500 && resp.StatusCode != http.StatusNotImplemented:
+		return fmt.Sprintf("状态码 %d", resp.StatusCode), parseRetryAfter(resp.Header.Get("Retry-After"))
+	}
+	return "", 0
+}
+
+// parseRetryAfter 解析 Retry-After 头（秒数或HTTP日期），无法解析时返回 0
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		if wait := time.Until(at); wait > 0 {
+			return wait
+		}
+	}
+	return 0
+}
+
+// canRewind 判断请求体能否在重试时重新发送
+func canRewind(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// rewindRequest 复制请求并重新获取请求体
+func rewindRequest(req *http.Request) (*http.Request, error) {
+	clone := req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, fmt.Errorf("重新读取请求体失败: %w", err)
+		}
+		clone.Body = body
+	}
+	return clone, nil
+}
+
+var (
+	defaultHTTPMu     sync.RWMutex
+	defaultHTTPClient = NewHTTPClient(DefaultHTTPClientOptions())
+)
+
+// DefaultHTTPClient 返回ASR与大模型请求共享的客户端
+func DefaultHTTPClient() *HTTPClient {
+	defaultHTTPMu.RLock()
+	defer defaultHTTPMu.RUnlock()
+	return defaultHTTPClient
+}
+
+// ConfigureHTTPClient 按配置替换共享客户端，应在创建ASR与大模型客户端之前调用
+func ConfigureHTTPClient(options HTTPClientOptions) {
+	client := NewHTTPClient(options)
+	if options.Proxy != nil {
+		Info("ASR与大模型请求使用代理: %s", options.Proxy.Redacted())
+	}
+	defaultHTTPMu.Lock()
+	previous := defaultHTTPClient
+	defaultHTTPClient = client
+	defaultHTTPMu.Unlock()
+	previous.client.CloseIdleConnections()
+}
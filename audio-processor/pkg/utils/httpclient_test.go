@@ -0,0 +1,124 @@
+package utils
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fastRetryOptions 测试用的短退避设置
+func fastRetryOptions(maxRetries int) HTTPClientOptions {
+	options := DefaultHTTPClientOptions()
+	options.MaxRetries = maxRetries
+	options.BaseDelay = time.Millisecond
+	options.MaxDelay = 5 * time.Millisecond
+	return options
+}
+
+// TestHTTPClientRetry 测试 5xx 后重试并重新发送请求体，4xx 不重试，重试次数用完后返回最后的响应
+func TestHTTPClientRetry(t *testing.T) {
+	var calls int32
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		switch {
+		case r.URL.Path == "/bad":
+			w.WriteHeader(http.StatusBadRequest)
+		case r.URL.Path == "/down" || atomic.AddInt32(&calls, 1) < 3:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			w.Write([]byte("ok"))
+		}
+	}))
+	defer server.Close()
+	client := NewHTTPClient(fastRetryOptions(3))
+
+	req, err := http.NewRequest("POST", server.URL+"/ok", strings.NewReader("payload"))
+	require.NoError(t, err)
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(t, "ok", string(body))
+	assert.Equal(t, []string{"payload", "payload", "payload"}, bodies)
+
+	bodies = nil
+	req, _ = http.NewRequest("GET", server.URL+"/bad", nil)
+	resp, err = client.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Len(t, bodies, 1)
+
+	bodies = nil
+	req, _ = http.NewRequest("GET", server.URL+"/down", nil)
+	resp, err = client.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Len(t, bodies, 4)
+}
+
+// TestHTTPClientCanceled 测试等待重试时 ctx 取消立即返回
+func TestHTTPClientCanceled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "10")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+	options := fastRetryOptions(3)
+	options.MaxDelay = time.Minute
+	client := NewHTTPClient(options)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, "GET", server.URL, nil)
+	start := time.Now()
+	_, err := client.Do(req)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
+// TestHTTPClientBackoff 测试退避时间按指数增长且不超过上限
+func TestHTTPClientBackoff(t *testing.T) {
+	client := NewHTTPClient(HTTPClientOptions{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second})
+	for attempt, max := range []time.Duration{100, 200, 400, 800, 1000, 1000} {
+		max *= time.Millisecond
+		delay := client.backoff(attempt)
+		assert.GreaterOrEqual(t, delay, max/2)
+		assert.LessOrEqual(t, delay, max)
+	}
+
+	assert.Equal(t, 3*time.Second, parseRetryAfter("3"))
+	assert.Zero(t, parseRetryAfter("soon"))
+}
+
+// TestHTTPClientProxy 测试设置代理后请求经由代理发送
+func TestHTTPClientProxy(t *testing.T) {
+	var proxied string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = r.URL.String()
+		w.Write([]byte("via proxy"))
+	}))
+	defer proxy.Close()
+
+	options := fastRetryOptions(0)
+	options.Proxy, _ = url.Parse(proxy.URL)
+	req, _ := http.NewRequest("GET", "http://asr.example.invalid/task?id=1", nil)
+	resp, err := NewHTTPClient(options).Do(req)
+	require.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(t, "via proxy", string(body))
+	assert.Equal(t, "http://asr.example.invalid/task?id=1", proxied)
+}
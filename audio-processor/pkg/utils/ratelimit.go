@@ -0,0 +1,53 @@
+package utils
+
+import "time"
+
+// RateLimiter 令牌桶限流器，容量为每分钟请求数，令牌按分钟匀速补充。
+// 时间由调用方传入，便于按等待之后的时间预先扣除令牌；不支持并发调用，调用方需持有锁
+type RateLimiter struct {
+	capacity float64
+	tokens   float64
+	rate     float64 // 每秒补充的令牌数
+	last     time.Time
+}
+
+// NewRateLimiter 创建每分钟最多 perMinute 次请求的限流器，初始令牌为满
+func NewRateLimiter(perMinute int, now time.Time) *RateLimiter {
+	return &RateLimiter{
+		capacity: float64(perMinute),
+		tokens:   float64(perMinute),
+		rate:     float64(perMinute) / 60,
+		last:     now,
+	}
+}
+
+// PerMinute 返回每分钟请求数
+func (l *RateLimiter) PerMinute() int {
+	return int(l.capacity)
+}
+
+// refill 按经过的时间补充令牌。last 只向后推进：预先按未来时间扣除令牌后，
+// 之后以较早的时间查询不会把这段时间再补充一次
+func (l *RateLimiter) refill(now time.Time) {
+	if elapsed := now.Sub(l.last).Seconds(); elapsed > 0 {
+		l.tokens = min(l.tokens+elapsed*l.rate, l.capacity)
+		l.last = now
+	}
+}
+
+// Wait 返回获得下一个令牌还需等待的时间，有令牌时为0。
+// 已按未来时间扣除过令牌时，从那个时间起算
+func (l *RateLimiter) Wait(now time.Time) time.Duration {
+	l.refill(now)
+	if l.tokens >= 1 {
+		return 0
+	}
+	return l.last.Sub(now) + time.Duration((1-l.tokens)/l.rate*float64(time.Second))
+}
+
+// Take 扣除一个令牌，令牌不足时记为欠账，之后的 Wait 相应延长。
+// now 可以是 Wait 返回的等待之后的时间，此时令牌刚好补满一个
+func (l *RateLimiter) Take(now time.Time) {
+	l.refill(now)
+	l.tokens--
+}
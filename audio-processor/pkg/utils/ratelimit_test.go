@@ -0,0 +1,41 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestRateLimiterRefill 测试令牌按时间补充且不超过每分钟请求数
+func TestRateLimiterRefill(t *testing.T) {
+	now := time.Now()
+	limiter := NewRateLimiter(60, now)
+	for i := 0; i < 60; i++ {
+		limiter.Take(now)
+	}
+
+	assert.Equal(t, time.Second, limiter.Wait(now))
+	assert.Equal(t, time.Duration(0), limiter.Wait(now.Add(time.Second)))
+
+	// 令牌不会超过每分钟请求数
+	limiter.Wait(now.Add(time.Hour))
+	assert.Equal(t, float64(60), limiter.tokens)
+}
+
+// TestRateLimiterTakeAhead 测试按等待之后的时间扣除令牌后，较早时间的查询不会重复补充
+func TestRateLimiterTakeAhead(t *testing.T) {
+	now := time.Now()
+	limiter := NewRateLimiter(60, now)
+	for i := 0; i < 60; i++ {
+		limiter.Take(now)
+	}
+
+	wait := limiter.Wait(now)
+	assert.Equal(t, time.Second, wait)
+	limiter.Take(now.Add(wait))
+
+	// 下一个令牌要在已预留的令牌之后再等 1 秒
+	assert.Equal(t, 2*time.Second, limiter.Wait(now))
+	assert.Equal(t, time.Second, limiter.Wait(now.Add(time.Second)))
+}
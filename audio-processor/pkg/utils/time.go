@@ -3,6 +3,8 @@ package utils
 import (
 	"fmt"
 	"math"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -18,6 +20,27 @@ func FormatTime(seconds float64) string {
 	return fmt.Sprintf("%02d:%02d", m, s)
 }
 
+// ParseTimestamp 解析秒数（如 "90"、"90.5"）或 mm:ss、hh:mm:ss 格式的时间点，返回秒数
+func ParseTimestamp(value string) (float64, error) {
+	parts := strings.Split(strings.TrimSpace(value), ":")
+	if len(parts) > 3 {
+		return 0, fmt.Errorf("无效的时间格式: %q", value)
+	}
+	var seconds float64
+	for i, part := range parts {
+		number, err := strconv.ParseFloat(part, 64)
+		if err != nil || number < 0 || math.IsInf(number, 0) || math.IsNaN(number) {
+			return 0, fmt.Errorf("无效的时间格式: %q", value)
+		}
+		// 只有最后一段（秒）可以带小数，分钟与秒不能超过 59
+		if i < len(parts)-1 && number != math.Trunc(number) || i > 0 && number >= 60 {
+			return 0, fmt.Errorf("无效的时间格式: %q", value)
+		}
+		seconds = seconds*60 + number
+	}
+	return seconds, nil
+}
+
 // FormatTimeDuration 格式化时间长度为易读格式
 func FormatTimeDuration(seconds float64) string {
 	h := int(seconds / 3600)
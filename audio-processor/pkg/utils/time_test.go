@@ -0,0 +1,27 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestParseTimestamp 测试解析秒数与 hh:mm:ss 格式的时间点
+func TestParseTimestamp(t *testing.T) {
+	for value, want := range map[string]float64{
+		"90":         90,
+		"90.5":       90.5,
+		"01:30":      90,
+		"1:02:03.5":  3723.5,
+		" 00:00:10 ": 10,
+	} {
+		got, err := ParseTimestamp(value)
+		assert.NoError(t, err, value)
+		assert.Equal(t, want, got, value)
+	}
+
+	for _, value := range []string{"", "abc", "-5", "1:60", "1.5:00", "1:2:3:4"} {
+		_, err := ParseTimestamp(value)
+		assert.Error(t, err, value)
+	}
+}
@@ -0,0 +1,229 @@
+package webauth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/models"
+)
+
+// 认证方式
+const (
+	ModeNone   = ""       // 不认证，所有请求使用公共目录
+	ModeAPIKey = "apikey" // 请求携带配置中的API密钥
+	ModeJWT    = "jwt"    // 请求携带 HS256 签名的 JWT
+)
+
+// UsersDirName 启用认证后各用户目录所在的子目录名
+const UsersDirName = "users"
+
+// ErrUnauthorized 请求未携带有效的凭据
+var ErrUnauthorized = errors.New("未认证或凭据无效")
+
+type userKey struct{}
+
+// WithUser 返回携带用户名的上下文
+func WithUser(ctx context.Context, user string) context.Context {
+	return context.WithValue(ctx, userKey{}, user)
+}
+
+// User 返回请求的用户名，未启用认证时为空
+func User(ctx context.Context) string {
+	user, _ := ctx.Value(userKey{}).(string)
+	return user
+}
+
+// UserDir 返回用户在 base 下的目录，未启用认证（user 为空）时返回 base
+func UserDir(base, user string) string {
+	if user == "" {
+		return base
+	}
+	return filepath.Join(base, UsersDirName, safeName(user))
+}
+
+// safeName 将用户名转换为安全的目录名：保留字母、数字、横线、下划线与非开头的点，其他字符按字节转为 %XX
+func safeName(user string) string {
+	var b strings.Builder
+	for i := 0; i < len(user); i++ {
+		c := user[i]
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '-', c == '_':
+			b.WriteByte(c)
+		case c == '.' && i > 0:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02x", c)
+		}
+	}
+	return b.String()
+}
+
+// Authenticator 校验请求凭据并把用户名写入请求上下文
+type Authenticator struct {
+	mode      string
+	keys      map[string]string // API密钥 -> 用户名
+	secret    []byte
+	userClaim string
+	now       func() time.Time
+}
+
+// FromConfig 根据配置创建认证器，未启用认证时返回 nil
+func FromConfig(config *models.Config) (*Authenticator, error) {
+	if config == nil || config.WebAuthMode == ModeNone {
+		return nil, nil
+	}
+	a := &Authenticator{mode: config.WebAuthMode, userClaim: config.WebJWTUserClaim, now: time.Now}
+	switch config.WebAuthMode {
+	case ModeAPIKey:
+		if len(config.WebAPIKeys) == 0 {
+			return nil, fmt.Errorf("apikey 认证需要配置 web_api_keys")
+		}
+		a.keys = config.WebAPIKeys
+	case ModeJWT:
+		secret := config.WebJWTKey()
+		if secret == "" {
+			return nil, fmt.Errorf("jwt 认证需要配置 web_jwt_secret 或环境变量 WEB_JWT_SECRET")
+		}
+		a.secret = []byte(secret)
+		if a.userClaim == "" {
+			a.userClaim = "sub"
+		}
+	default:
+		return nil, fmt.Errorf("不支持的认证方式: %s", config.WebAuthMode)
+	}
+	return a, nil
+}
+
+// Middleware 要求请求携带有效凭据，未通过时返回 401。
+// 凭据可以放在 Authorization: Bearer <token>、X-API-Key 请求头，
+// 或查询参数 token 中（EventSource 无法设置请求头）。a 为 nil 时不做校验
+func (a *Authenticator) Middleware(next http.Handler) http.Handler {
+	if a == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, err := a.Authenticate(r)
+		if err != nil {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="asr"`)
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(WithUser(r.Context(), user)))
+	})
+}
+
+// Authenticate 校验请求凭据，返回用户名
+func (a *Authenticator) Authenticate(r *http.Request) (string, error) {
+	token := requestToken(r)
+	if token == "" {
+		return "", ErrUnauthorized
+	}
+	if a.mode == ModeAPIKey {
+		return a.checkAPIKey(token)
+	}
+	return a.checkJWT(token)
+}
+
+// requestToken 从请求头或查询参数中取出凭据
+func requestToken(r *http.Request) string {
+	if auth := r.Header.Get("Authorization"); len(auth) > 7 && strings.EqualFold(auth[:7], "Bearer ") {
+		return strings.TrimSpace(auth[7:])
+	}
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		return key
+	}
+	return r.URL.Query().Get("token")
+}
+
+// checkAPIKey 以固定时间比较全部密钥，避免通过响应时间猜测密钥
+func (a *Authenticator) checkAPIKey(token string) (string, error) {
+	var user string
+	for key, name := range a.keys {
+		if subtle.ConstantTimeCompare([]byte(key), []byte(token)) == 1 {
+			user = name
+		}
+	}
+	if user == "" {
+		return "", ErrUnauthorized
+	}
+	return user, nil
+}
+
+// checkJWT 校验 HS256 签名与 exp、nbf，返回用户名字段
+func (a *Authenticator) checkJWT(token string) (string, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return "", ErrUnauthorized
+	}
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil || header.Alg != "HS256" {
+		return "", ErrUnauthorized
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", ErrUnauthorized
+	}
+	mac := hmac.New(sha256.New, a.secret)
+	mac.Write([]byte(parts[0] + "." + parts[1]))
+	if !hmac.Equal(signature, mac.Sum(nil)) {
+		return "", ErrUnauthorized
+	}
+
+	var claims map[string]interface{}
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return "", ErrUnauthorized
+	}
+	now := float64(a.now().Unix())
+	if exp, ok := claims["exp"].(float64); ok && now >= exp {
+		return "", fmt.Errorf("凭据已过期")
+	}
+	if nbf, ok := claims["nbf"].(float64); ok && now < nbf {
+		return "", ErrUnauthorized
+	}
+	user, _ := claims[a.userClaim].(string)
+	if user == "" {
+		return "", fmt.Errorf("凭据中缺少用户名字段 %s", a.userClaim)
+	}
+	return user, nil
+}
+
+// decodeSegment 解码 JWT 的 base64url JSON 段
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// PerUser 返回按请求用户分发的处理器，每个用户的处理器在首次请求时由 build 创建并缓存
+func PerUser(build func(user string) http.Handler) http.Handler {
+	var mu sync.Mutex
+	handlers := make(map[string]http.Handler)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user := User(r.Context())
+		mu.Lock()
+		handler, exists := handlers[user]
+		if !exists {
+			handler = build(user)
+			handlers[user] = handler
+		}
+		mu.Unlock()
+		handler.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,142 @@
+package webauth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ccp-p/asr-media-cli/audio-processor/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// signJWT 生成 HS256 签名的 JWT
+func signJWT(t *testing.T, secret string, claims map[string]interface{}) string {
+	t.Helper()
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+	data, err := json.Marshal(claims)
+	require.NoError(t, err)
+	payload := base64.RawURLEncoding.EncodeToString(data)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(header + "." + payload))
+	return header + "." + payload + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// serve 经过认证中间件请求，返回状态码与处理器看到的用户名
+func serve(a *Authenticator, r *http.Request) (int, string) {
+	var user string
+	handler := a.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user = User(r.Context())
+	}))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, r)
+	return rec.Code, user
+}
+
+func TestAPIKey(t *testing.T) {
+	config := models.NewDefaultConfig()
+	config.WebAuthMode = ModeAPIKey
+	config.WebAPIKeys = map[string]string{"key-alice": "alice", "key-bob": "bob"}
+	a, err := FromConfig(config)
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/jobs", nil)
+	req.Header.Set("X-API-Key", "key-alice")
+	code, user := serve(a, req)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "alice", user)
+
+	req = httptest.NewRequest(http.MethodGet, "/api/jobs", nil)
+	req.Header.Set("Authorization", "Bearer key-bob")
+	_, user = serve(a, req)
+	assert.Equal(t, "bob", user)
+
+	// EventSource 通过查询参数携带
+	_, user = serve(a, httptest.NewRequest(http.MethodGet, "/api/jobs/1/events?token=key-bob", nil))
+	assert.Equal(t, "bob", user)
+
+	code, _ = serve(a, httptest.NewRequest(http.MethodGet, "/api/jobs", nil))
+	assert.Equal(t, http.StatusUnauthorized, code)
+	req = httptest.NewRequest(http.MethodGet, "/api/jobs", nil)
+	req.Header.Set("X-API-Key", "key-eve")
+	code, _ = serve(a, req)
+	assert.Equal(t, http.StatusUnauthorized, code)
+}
+
+func TestJWT(t *testing.T) {
+	config := models.NewDefaultConfig()
+	config.WebAuthMode = ModeJWT
+	config.WebJWTSecret = "secret"
+	a, err := FromConfig(config)
+	require.NoError(t, err)
+	now := time.Unix(1700000000, 0)
+	a.now = func() time.Time { return now }
+
+	request := func(token string) *http.Request {
+		req := httptest.NewRequest(http.MethodGet, "/api/jobs", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		return req
+	}
+
+	code, user := serve(a, request(signJWT(t, "secret", map[string]interface{}{"sub": "alice", "exp": now.Unix() + 60})))
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "alice", user)
+
+	code, _ = serve(a, request(signJWT(t, "secret", map[string]interface{}{"sub": "alice", "exp": now.Unix() - 1})))
+	assert.Equal(t, http.StatusUnauthorized, code, "已过期")
+	code, _ = serve(a, request(signJWT(t, "other", map[string]interface{}{"sub": "alice"})))
+	assert.Equal(t, http.StatusUnauthorized, code, "签名不匹配")
+	code, _ = serve(a, request(signJWT(t, "secret", map[string]interface{}{"name": "alice"})))
+	assert.Equal(t, http.StatusUnauthorized, code, "缺少用户名字段")
+
+	none := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none"}`)) + "." +
+		base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"alice"}`)) + "."
+	code, _ = serve(a, request(none))
+	assert.Equal(t, http.StatusUnauthorized, code, "不接受未签名的令牌")
+
+	config.WebJWTSecret = ""
+	t.Setenv("WEB_JWT_SECRET", "")
+	_, err = FromConfig(config)
+	assert.Error(t, err, "缺少签名密钥")
+}
+
+func TestDisabled(t *testing.T) {
+	a, err := FromConfig(models.NewDefaultConfig())
+	require.NoError(t, err)
+	assert.Nil(t, a)
+	code, user := serve(a, httptest.NewRequest(http.MethodGet, "/api/jobs", nil))
+	assert.Equal(t, http.StatusOK, code)
+	assert.Empty(t, user)
+}
+
+func TestUserDir(t *testing.T) {
+	base := filepath.Join("data", "output")
+	assert.Equal(t, base, UserDir(base, ""))
+	assert.Equal(t, filepath.Join(base, "users", "alice"), UserDir(base, "alice"))
+	// 用户名不能跳出用户目录
+	assert.Equal(t, filepath.Join(base, "users", "%2e.%2f.."), UserDir(base, "../.."))
+	assert.Equal(t, filepath.Join(base, "users", "bob%40example.com"), UserDir(base, "bob@example.com"))
+}
+
+func TestPerUser(t *testing.T) {
+	built := 0
+	handler := PerUser(func(user string) http.Handler {
+		built++
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("hello " + user))
+		})
+	})
+	for i := 0; i < 2; i++ {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil).WithContext(WithUser(context.Background(), "alice")))
+		assert.Equal(t, "hello alice", rec.Body.String())
+	}
+	assert.Equal(t, 1, built, "每个用户只创建一次")
+}
@@ -0,0 +1,152 @@
+package ytdlp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// 默认配置
+const (
+	DefaultProgram = "yt-dlp"         // 可执行文件
+	DefaultFormat  = "bestaudio/best" // 优先只下载音频流
+)
+
+// 输出文件名模板：标题截断到 80 字节，附带视频ID避免重名
+const outputTemplate = "%(title).80B [%(id)s].%(ext)s"
+
+// 下载进度行，如 "[download]  42.3% of 10.00MiB at 1.00MiB/s ETA 00:05"
+var progressPattern = regexp.MustCompile(`^\[download\]\s+([\d.]+)%`)
+
+// Downloader 调用 yt-dlp 从视频网站（YouTube、哔哩哔哩、抖音等）下载音频
+type Downloader struct {
+	Program  string                                // yt-dlp 可执行文件
+	Format   string                                // 格式选择（-f）
+	Args     []string                              // 额外参数，如 --cookies cookies.txt
+	Progress func(percent float64, message string) // 下载进度回调，可为空
+}
+
+// New 创建下载器，program、format 为空时使用默认值
+func New(program, format string, args []string) *Downloader {
+	if program == "" {
+		program = DefaultProgram
+	}
+	if format == "" {
+		format = DefaultFormat
+	}
+	return &Downloader{Program: program, Format: format, Args: args}
+}
+
+// ValidateURL 检查链接是否为 http(s) 地址
+func ValidateURL(rawURL string) error {
+	parsed, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || parsed.Host == "" || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return fmt.Errorf("无效的链接: %s", rawURL)
+	}
+	return nil
+}
+
+// Available 检查 yt-dlp 是否可用
+func (d *Downloader) Available() bool {
+	_, err := exec.LookPath(d.Program)
+	return err == nil
+}
+
+// Download 下载链接中的音频到 dir，返回下载的文件路径。
+// dir 应为空目录，yt-dlp 没有打印文件路径时取目录中唯一的媒体文件
+func (d *Downloader) Download(ctx context.Context, rawURL, dir string) (string, error) {
+	if err := ValidateURL(rawURL); err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("创建下载目录失败: %w", err)
+	}
+
+	args := []string{
+		"--no-playlist", "--newline", "--progress",
+		"-f", d.Format,
+		"-o", filepath.Join(dir, outputTemplate),
+		"--print", "after_move:filepath",
+	}
+	args = append(args, d.Args...)
+	args = append(args, "--", strings.TrimSpace(rawURL))
+
+	cmd := exec.CommandContext(ctx, d.Program, args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return "", err
+	}
+	if err := cmd.Start(); err != nil {
+		return "", fmt.Errorf("启动 yt-dlp 失败: %w", err)
+	}
+	printed := d.readOutput(stdout)
+	if err := cmd.Wait(); err != nil {
+		return "", fmt.Errorf("yt-dlp 下载失败: %w, %s", err, lastLine(stderr.String()))
+	}
+
+	if printed != "" {
+		if _, err := os.Stat(printed); err == nil {
+			return printed, nil
+		}
+	}
+	return findDownloaded(dir)
+}
+
+// readOutput 读取 yt-dlp 标准输出，回调下载进度，返回打印的文件路径
+func (d *Downloader) readOutput(r io.Reader) string {
+	var printed string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if match := progressPattern.FindStringSubmatch(line); match != nil {
+			if percent, err := strconv.ParseFloat(match[1], 64); err == nil && d.Progress != nil {
+				d.Progress(percent, strings.TrimSpace(strings.TrimPrefix(line, "[download]")))
+			}
+			continue
+		}
+		if !strings.HasPrefix(line, "[") {
+			printed = line
+		}
+	}
+	return printed
+}
+
+// findDownloaded 返回目录中唯一的已完成文件
+func findDownloaded(dir string) (string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", fmt.Errorf("读取下载目录失败: %w", err)
+	}
+	var files []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasSuffix(name, ".part") || strings.HasSuffix(name, ".ytdl") {
+			continue
+		}
+		files = append(files, filepath.Join(dir, name))
+	}
+	if len(files) != 1 {
+		return "", fmt.Errorf("无法确定下载的文件，目录中有 %d 个文件", len(files))
+	}
+	return files[0], nil
+}
+
+// lastLine 返回输出的最后一行非空内容，通常是 yt-dlp 的错误信息
+func lastLine(output string) string {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
@@ -0,0 +1,63 @@
+package ytdlp
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeProgram 写入模拟 yt-dlp 的脚本：打印进度，在 -o 指定的目录写入文件并打印路径
+func fakeProgram(t *testing.T, script string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("需要 sh")
+	}
+	path := filepath.Join(t.TempDir(), "yt-dlp")
+	require.NoError(t, os.WriteFile(path, []byte("#!/bin/sh\n"+script), 0755))
+	return path
+}
+
+const downloadScript = `
+while [ "$1" != "-o" ]; do shift; done
+dir=$(dirname "$2")
+echo "[youtube] abc: Downloading webpage"
+echo "[download]  50.0% of 1.00MiB"
+echo "[download] 100.0% of 1.00MiB"
+echo audio > "$dir/talk [abc].m4a"
+`
+
+func TestDownload(t *testing.T) {
+	d := New(fakeProgram(t, downloadScript+`echo "$dir/talk [abc].m4a"`), "", nil)
+	var percents []float64
+	d.Progress = func(percent float64, message string) { percents = append(percents, percent) }
+
+	dir := filepath.Join(t.TempDir(), "download")
+	path, err := d.Download(context.Background(), "https://www.youtube.com/watch?v=abc", dir)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "talk [abc].m4a"), path)
+	assert.Equal(t, []float64{50, 100}, percents)
+}
+
+func TestDownloadFallback(t *testing.T) {
+	// 没有打印路径时取目录中唯一的文件
+	d := New(fakeProgram(t, downloadScript+`touch "$dir/talk [abc].webm.part"`), "", nil)
+	dir := t.TempDir()
+	path, err := d.Download(context.Background(), "https://www.bilibili.com/video/BV1xx", dir)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "talk [abc].m4a"), path)
+}
+
+func TestDownloadError(t *testing.T) {
+	d := New(fakeProgram(t, "echo 'ERROR: Unsupported URL' >&2\nexit 1\n"), "", nil)
+	_, err := d.Download(context.Background(), "https://example.com/page", t.TempDir())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Unsupported URL")
+
+	_, err = d.Download(context.Background(), "file:///etc/passwd", t.TempDir())
+	assert.Error(t, err)
+}
@@ -42,20 +42,20 @@ if not exist "%~dp0\%CONFIG_FILE%" (
 )
 
 :: 确定可执行文件路径
-set EXE_PATH="%~dp0\audio-processor\cmd\audioproc\audioproc.exe"
+set EXE_PATH="%~dp0\audio-processor\cmd\asr\asr.exe"
 if not exist %EXE_PATH% (
-  set EXE_PATH="%~dp0\audioproc.exe"
+  set EXE_PATH="%~dp0\asr.exe"
 )
 if not exist %EXE_PATH% (
   echo %YELLOW%查找可执行文件...%RESET%
-  for /r "%~dp0" %%i in (audioproc.exe) do (
+  for /r "%~dp0" %%i in (asr.exe) do (
     if exist "%%i" (
       set EXE_PATH="%%i"
       goto found_exe
     )
   )
   
-  echo %RED%错误: 找不到audioproc.exe可执行文件%RESET%
+  echo %RED%错误: 找不到asr.exe可执行文件%RESET%
   echo 请确保已编译音频处理程序，或将此批处理文件放在正确位置
   pause
   exit /b 1
@@ -96,7 +96,7 @@ type "%~dp0\%CONFIG_FILE%" | findstr "MediaFolder"
 echo.
 echo 按Ctrl+C结束程序
 echo.
-%EXE_PATH% --config "%~dp0\%CONFIG_FILE%" --log-level %LOG_LEVEL% --log-file "%~dp0\%LOG_FILE%"
+%EXE_PATH% watch --config "%~dp0\%CONFIG_FILE%" --log-level %LOG_LEVEL% --log-file "%~dp0\%LOG_FILE%"
 echo.
 echo %GREEN%程序已退出%RESET%
 pause
@@ -119,7 +119,7 @@ echo   "WatchMode": false,>> "%~dp0\temp_config.json"
 move /y "%~dp0\temp_config.json" "%~dp0\%CONFIG_FILE%" >nul
 
 :: 处理文件
-%EXE_PATH% --config "%~dp0\%CONFIG_FILE%" --log-level %LOG_LEVEL% --log-file "%~dp0\%LOG_FILE%"
+%EXE_PATH% process --config "%~dp0\%CONFIG_FILE%" --log-level %LOG_LEVEL% --log-file "%~dp0\%LOG_FILE%"
 
 :: 恢复配置
 move /y "%~dp0\%CONFIG_FILE%.bak" "%~dp0\%CONFIG_FILE%" >nul
@@ -35,20 +35,20 @@ if not exist "%~dp0\%CONFIG_FILE%" (
 )
 
 :: 确定可执行文件路径
-set EXE_PATH="%~dp0\audio-processor\cmd\audioproc\audioproc.exe"
+set EXE_PATH="%~dp0\audio-processor\cmd\asr\asr.exe"
 if not exist %EXE_PATH% (
-  set EXE_PATH="%~dp0\audioproc.exe"
+  set EXE_PATH="%~dp0\asr.exe"
 )
 if not exist %EXE_PATH% (
   echo 查找可执行文件...
-  for /r "%~dp0" %%i in (audioproc.exe) do (
+  for /r "%~dp0" %%i in (asr.exe) do (
     if exist "%%i" (
       set EXE_PATH="%%i"
       goto found_exe
     )
   )
   
-  echo 错误: 找不到audioproc.exe可执行文件
+  echo 错误: 找不到asr.exe可执行文件
   echo 请确保已编译音频处理程序，或将此批处理文件放在正确位置
   pause
   exit /b 1
@@ -67,7 +67,7 @@ echo 按Ctrl+C可终止程序
 echo.
 
 :: 启动程序
-%EXE_PATH% --config "%~dp0\%CONFIG_FILE%" --log-level %LOG_LEVEL% --log-file "%~dp0\%LOG_FILE%"
+%EXE_PATH% watch --config "%~dp0\%CONFIG_FILE%" --log-level %LOG_LEVEL% --log-file "%~dp0\%LOG_FILE%"
 
 echo.
 echo 程序已退出